@@ -2,7 +2,11 @@ package main
 
 import (
 	"crypto/rand"
+	"encoding/json"
 	"fmt"
+	"io"
+	"runtime"
+	"sort"
 	"sync"
 	"time"
 
@@ -16,8 +20,129 @@ import (
 	"github.com/luxfi/threshold/protocols/lss"
 )
 
-func benchmarkKeygen(protocolName string, iterations int) error {
-	fmt.Printf("\n=== Keygen Benchmark ===\n")
+// Output formats of bench
+const (
+	benchFormatText = "text"
+	benchFormatJSON = "json"
+)
+
+// benchResult holds the measurements of one benchmark case, as written by bench --output json.
+// Durations are in nanoseconds, and allocations are counted for the whole process.
+type benchResult struct {
+	Protocol    string `json:"protocol"`
+	Operation   string `json:"operation"`
+	Case        string `json:"case"`
+	Parties     int    `json:"parties"`
+	Threshold   int    `json:"threshold"`
+	Iterations  int    `json:"iterations"`
+	Mean        int64  `json:"mean_ns"`
+	Median      int64  `json:"median_ns"`
+	P95         int64  `json:"p95_ns"`
+	Min         int64  `json:"min_ns"`
+	Max         int64  `json:"max_ns"`
+	AllocsPerOp uint64 `json:"allocs_per_op"`
+	BytesPerOp  uint64 `json:"bytes_per_op"`
+}
+
+// measure runs op iterations times, and fills in the timings and allocations of result.
+func measure(result benchResult, iterations int, op func() error) (benchResult, error) {
+	if iterations < 1 {
+		return result, fmt.Errorf("--iterations must be at least 1")
+	}
+	times := make([]time.Duration, 0, iterations)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	for i := 0; i < iterations; i++ {
+		start := time.Now()
+		if err := op(); err != nil {
+			return result, err
+		}
+		times = append(times, time.Since(start))
+	}
+	runtime.ReadMemStats(&after)
+
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	var total time.Duration
+	for _, d := range times {
+		total += d
+	}
+	n := len(times)
+	median := times[n/2]
+	if n%2 == 0 {
+		median = (times[n/2-1] + times[n/2]) / 2
+	}
+	// nearest rank
+	p95 := times[(95*n+99)/100-1]
+
+	result.Iterations = n
+	result.Mean = int64(total / time.Duration(n))
+	result.Median = int64(median)
+	result.P95 = int64(p95)
+	result.Min = int64(times[0])
+	result.Max = int64(times[n-1])
+	result.AllocsPerOp = (after.Mallocs - before.Mallocs) / uint64(n)
+	result.BytesPerOp = (after.TotalAlloc - before.TotalAlloc) / uint64(n)
+	return result, nil
+}
+
+// printBenchResult prints the timings of result as the text output of bench.
+func printBenchResult(w io.Writer, result benchResult) {
+	fmt.Fprintf(w, "  Average: %v\n", time.Duration(result.Mean))
+	fmt.Fprintf(w, "  Median:  %v\n", time.Duration(result.Median))
+	fmt.Fprintf(w, "  P95:     %v\n", time.Duration(result.P95))
+	fmt.Fprintf(w, "  Min:     %v\n", time.Duration(result.Min))
+	fmt.Fprintf(w, "  Max:     %v\n", time.Duration(result.Max))
+	fmt.Fprintf(w, "  Allocs:  %d per op, %d bytes per op\n", result.AllocsPerOp, result.BytesPerOp)
+}
+
+// runBenchmarks runs the benchmarks of operation for protocolName, writing their progress to w,
+// and returns their results.
+func runBenchmarks(w io.Writer, protocolName, operation string, iterations int) ([]benchResult, error) {
+	switch operation {
+	case "keygen":
+		return benchmarkKeygen(w, protocolName, iterations)
+	case "sign":
+		return benchmarkSign(w, protocolName, iterations)
+	case "reshare":
+		if protocolName != "lss" {
+			return nil, fmt.Errorf("reshare benchmark only available for LSS protocol")
+		}
+		return benchmarkReshare(w, iterations)
+	case "all":
+		results, err := benchmarkKeygen(w, protocolName, iterations)
+		if err != nil {
+			return nil, err
+		}
+		sign, err := benchmarkSign(w, protocolName, iterations)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, sign...)
+		if protocolName == "lss" {
+			reshare, err := benchmarkReshare(w, iterations)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, reshare...)
+		}
+		return results, nil
+	default:
+		return nil, fmt.Errorf("unknown operation: %s", operation)
+	}
+}
+
+// writeBenchResults writes results as a JSON array, for CI to track regressions.
+func writeBenchResults(w io.Writer, results []benchResult) error {
+	if results == nil {
+		results = []benchResult{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
+
+func benchmarkKeygen(w io.Writer, protocolName string, iterations int) ([]benchResult, error) {
+	fmt.Fprintf(w, "\n=== Keygen Benchmark ===\n")
 
 	testCases := []struct {
 		name      string
@@ -29,55 +154,45 @@ func benchmarkKeygen(protocolName string, iterations int) error {
 		{"7-of-11", 11, 7},
 	}
 
+	results := make([]benchResult, 0, len(testCases))
 	for _, tc := range testCases {
-		fmt.Printf("\nTesting %s:\n", tc.name)
-
-		var totalTime time.Duration
-		var minTime = time.Hour
-		var maxTime time.Duration
-
-		for i := 0; i < iterations; i++ {
-			start := time.Now()
-
+		fmt.Fprintf(w, "\nTesting %s:\n", tc.name)
+
+		result, err := measure(benchResult{
+			Protocol:  protocolName,
+			Operation: "keygen",
+			Case:      tc.name,
+			Parties:   tc.n,
+			Threshold: tc.threshold,
+		}, iterations, func() error {
 			if err := runSingleKeygen(protocolName, tc.n, tc.threshold); err != nil {
 				return fmt.Errorf("keygen failed: %w", err)
 			}
-
-			elapsed := time.Since(start)
-			totalTime += elapsed
-
-			if elapsed < minTime {
-				minTime = elapsed
-			}
-			if elapsed > maxTime {
-				maxTime = elapsed
-			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
 		}
-
-		avgTime := totalTime / time.Duration(iterations)
-
-		fmt.Printf("  Average: %v\n", avgTime)
-		fmt.Printf("  Min:     %v\n", minTime)
-		fmt.Printf("  Max:     %v\n", maxTime)
-		fmt.Printf("  Total:   %v\n", totalTime)
+		printBenchResult(w, result)
+		results = append(results, result)
 	}
 
-	return nil
+	return results, nil
 }
 
-func benchmarkSign(protocolName string, iterations int) error {
-	fmt.Printf("\n=== Sign Benchmark ===\n")
+func benchmarkSign(w io.Writer, protocolName string, iterations int) ([]benchResult, error) {
+	fmt.Fprintf(w, "\n=== Sign Benchmark ===\n")
 
 	// Setup phase
 	n := 5
 	threshold := 3
 
-	fmt.Printf("Setting up %d-of-%d configuration...\n", threshold, n)
+	fmt.Fprintf(w, "Setting up %d-of-%d configuration...\n", threshold, n)
 
 	// First generate keys
 	configs, err := setupBenchmarkConfigs(protocolName, n, threshold)
 	if err != nil {
-		return fmt.Errorf("setup failed: %w", err)
+		return nil, fmt.Errorf("setup failed: %w", err)
 	}
 
 	// Run signing benchmarks
@@ -89,57 +204,46 @@ func benchmarkSign(protocolName string, iterations int) error {
 		{"all signers", n},
 	}
 
+	results := make([]benchResult, 0, len(testCases))
 	for _, tc := range testCases {
-		fmt.Printf("\nTesting with %s:\n", tc.name)
-
-		var totalTime time.Duration
-		var minTime = time.Hour
-		var maxTime time.Duration
-
-		for i := 0; i < iterations; i++ {
+		fmt.Fprintf(w, "\nTesting with %s:\n", tc.name)
+
+		result, err := measure(benchResult{
+			Protocol:  protocolName,
+			Operation: "sign",
+			Case:      tc.name,
+			Parties:   tc.signers,
+			Threshold: threshold,
+		}, iterations, func() error {
 			message := make([]byte, 32)
 			rand.Read(message)
-
-			start := time.Now()
-
 			if err := runSingleSign(protocolName, configs[:tc.signers], message); err != nil {
 				return fmt.Errorf("signing failed: %w", err)
 			}
-
-			elapsed := time.Since(start)
-			totalTime += elapsed
-
-			if elapsed < minTime {
-				minTime = elapsed
-			}
-			if elapsed > maxTime {
-				maxTime = elapsed
-			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
 		}
-
-		avgTime := totalTime / time.Duration(iterations)
-
-		fmt.Printf("  Average: %v\n", avgTime)
-		fmt.Printf("  Min:     %v\n", minTime)
-		fmt.Printf("  Max:     %v\n", maxTime)
-		fmt.Printf("  Total:   %v\n", totalTime)
+		printBenchResult(w, result)
+		results = append(results, result)
 	}
 
-	return nil
+	return results, nil
 }
 
-func benchmarkReshare(iterations int) error {
-	fmt.Printf("\n=== Reshare Benchmark (LSS only) ===\n")
+func benchmarkReshare(w io.Writer, iterations int) ([]benchResult, error) {
+	fmt.Fprintf(w, "\n=== Reshare Benchmark (LSS only) ===\n")
 
 	// Setup initial configuration
 	initialN := 5
 	initialThreshold := 3
 
-	fmt.Printf("Setting up initial %d-of-%d configuration...\n", initialThreshold, initialN)
+	fmt.Fprintf(w, "Setting up initial %d-of-%d configuration...\n", initialThreshold, initialN)
 
 	configs, err := setupBenchmarkConfigs("lss", initialN, initialThreshold)
 	if err != nil {
-		return fmt.Errorf("setup failed: %w", err)
+		return nil, fmt.Errorf("setup failed: %w", err)
 	}
 
 	testCases := []struct {
@@ -154,46 +258,35 @@ func benchmarkReshare(iterations int) error {
 		{"add 1 remove 1", 3, 1, 1},
 	}
 
+	results := make([]benchResult, 0, len(testCases))
 	for _, tc := range testCases {
-		fmt.Printf("\nTesting %s:\n", tc.name)
-
-		var totalTime time.Duration
-		var minTime = time.Hour
-		var maxTime time.Duration
-
-		for i := 0; i < iterations; i++ {
+		fmt.Fprintf(w, "\nTesting %s:\n", tc.name)
+
+		result, err := measure(benchResult{
+			Protocol:  "lss",
+			Operation: "reshare",
+			Case:      tc.name,
+			Parties:   initialN + tc.addParties - tc.removeParties,
+			Threshold: tc.newThreshold,
+		}, iterations, func() error {
 			// Clone configs for this iteration
 			iterConfigs := make([]*lss.Config, len(configs))
 			for j, c := range configs {
 				iterConfigs[j] = c.(*lss.Config)
 			}
-
-			start := time.Now()
-
 			if err := runSingleReshare(iterConfigs, tc.newThreshold, tc.addParties, tc.removeParties); err != nil {
 				return fmt.Errorf("reshare failed: %w", err)
 			}
-
-			elapsed := time.Since(start)
-			totalTime += elapsed
-
-			if elapsed < minTime {
-				minTime = elapsed
-			}
-			if elapsed > maxTime {
-				maxTime = elapsed
-			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
 		}
-
-		avgTime := totalTime / time.Duration(iterations)
-
-		fmt.Printf("  Average: %v\n", avgTime)
-		fmt.Printf("  Min:     %v\n", minTime)
-		fmt.Printf("  Max:     %v\n", maxTime)
-		fmt.Printf("  Total:   %v\n", totalTime)
+		printBenchResult(w, result)
+		results = append(results, result)
 	}
 
-	return nil
+	return results, nil
 }
 
 // Helper functions
@@ -295,8 +388,14 @@ func runSingleSign(protocolName string, configs []interface{}, message []byte) e
 	switch protocolName {
 	case "lss":
 		partyIDs = make([]party.ID, len(configs))
+		lssConfigs := make([]*lss.Config, len(configs))
 		for i, c := range configs {
-			partyIDs[i] = c.(*lss.Config).ID
+			lssConfigs[i] = c.(*lss.Config)
+			partyIDs[i] = lssConfigs[i].ID
+		}
+		// the configs of all signers are at hand, so a mix of generations is rejected before any round
+		if err := lss.CheckGenerations(lssConfigs); err != nil {
+			return err
 		}
 	case "cmp":
 		partyIDs = make([]party.ID, len(configs))
@@ -311,6 +410,10 @@ func runSingleSign(protocolName string, configs []interface{}, message []byte) e
 	}
 
 	network := test.NewNetwork(partyIDs)
+	sessionID, err := protocol.NewSessionID()
+	if err != nil {
+		return err
+	}
 
 	var wg sync.WaitGroup
 	wg.Add(len(configs))
@@ -325,13 +428,13 @@ func runSingleSign(protocolName string, configs []interface{}, message []byte) e
 			switch protocolName {
 			case "lss":
 				c := cfg.(*lss.Config)
-				h, err = protocol.NewMultiHandler(lss.Sign(c, partyIDs, message, pl), nil)
+				h, err = protocol.NewMultiHandler(lss.Sign(c, partyIDs, message, pl), sessionID)
 			case "cmp":
 				c := cfg.(*cmp.Config)
-				h, err = protocol.NewMultiHandler(cmp.Sign(c, partyIDs, message, pl), nil)
+				h, err = protocol.NewMultiHandler(cmp.Sign(c, partyIDs, message, pl), sessionID)
 			case "frost":
 				c := cfg.(*frost.Config)
-				h, err = protocol.NewMultiHandler(frost.Sign(c, partyIDs, message), nil)
+				h, err = protocol.NewMultiHandler(frost.Sign(c, partyIDs, message), sessionID)
 			}
 
 			if err != nil {
@@ -362,12 +465,19 @@ func runSingleReshare(configs []*lss.Config, newThreshold, addParties, removePar
 		newPartyIDs[i] = party.ID(fmt.Sprintf("new-%d", i))
 	}
 
+	// The new group keeps the remaining parties, and every old party deals its share
+	finalParties := make([]party.ID, 0, len(remainingConfigs)+len(newPartyIDs))
+	for _, c := range remainingConfigs {
+		finalParties = append(finalParties, c.ID)
+	}
+	finalParties = append(finalParties, newPartyIDs...)
+
 	// All parties involved in resharing
-	allParties := make([]party.ID, len(remainingConfigs)+len(newPartyIDs))
-	for i, c := range remainingConfigs {
-		allParties[i] = c.ID
+	allParties := make([]party.ID, 0, len(configs)+len(newPartyIDs))
+	for _, c := range configs {
+		allParties = append(allParties, c.ID)
 	}
-	copy(allParties[len(remainingConfigs):], newPartyIDs)
+	allParties = append(allParties, newPartyIDs...)
 
 	network := test.NewNetwork(allParties)
 
@@ -375,11 +485,11 @@ func runSingleReshare(configs []*lss.Config, newThreshold, addParties, removePar
 	wg.Add(len(allParties))
 
 	// Existing parties reshare
-	for _, config := range remainingConfigs {
+	for _, config := range configs {
 		go func(c *lss.Config) {
 			defer wg.Done()
 
-			h, err := protocol.NewMultiHandler(lss.Reshare(c, newPartyIDs, newThreshold, pl), nil)
+			h, err := protocol.NewMultiHandler(lss.Reshare(c, finalParties, newThreshold, pl), nil)
 			if err != nil {
 				return
 			}
@@ -393,11 +503,7 @@ func runSingleReshare(configs []*lss.Config, newThreshold, addParties, removePar
 		go func(id party.ID) {
 			defer wg.Done()
 
-			emptyConfig := lss.EmptyConfig(configs[0].Group)
-			emptyConfig.ID = id
-			emptyConfig.Generation = configs[0].Generation
-
-			h, err := protocol.NewMultiHandler(lss.Reshare(emptyConfig, newPartyIDs, newThreshold, pl), nil)
+			h, err := protocol.NewMultiHandler(lss.Reshare(lss.JoinConfig(configs[0], id), finalParties, newThreshold, pl), nil)
 			if err != nil {
 				return
 			}
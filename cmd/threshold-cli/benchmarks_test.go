@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBenchJSON(t *testing.T) {
+	results, err := runBenchmarks(io.Discard, "frost", "all", 2)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, writeBenchResults(&out, results))
+	var decoded []map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	// 3 keygen cases and 2 sign cases
+	require.Len(t, decoded, 5)
+	for _, r := range decoded {
+		for _, field := range []string{"protocol", "operation", "case", "parties", "threshold", "iterations",
+			"mean_ns", "median_ns", "p95_ns", "min_ns", "max_ns", "allocs_per_op", "bytes_per_op"} {
+			assert.Contains(t, r, field)
+		}
+		assert.Equal(t, "frost", r["protocol"])
+		assert.EqualValues(t, 2, r["iterations"])
+		assert.Positive(t, r["mean_ns"])
+		assert.LessOrEqual(t, r["min_ns"], r["p95_ns"])
+	}
+	assert.Equal(t, "keygen", decoded[0]["operation"])
+	assert.EqualValues(t, 5, decoded[0]["parties"])
+	assert.Equal(t, "sign", decoded[4]["operation"])
+
+	_, err = runBenchmarks(io.Discard, "frost", "reshare", 1)
+	assert.Error(t, err)
+	_, err = runBenchmarks(io.Discard, "frost", "keygen", 0)
+	assert.Error(t, err)
+}
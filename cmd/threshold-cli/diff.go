@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/protocols/cmp"
+	"github.com/luxfi/threshold/protocols/frost"
+	"github.com/luxfi/threshold/protocols/lss"
+	"github.com/spf13/cobra"
+)
+
+// configDiff is what changed between two configs of a key.
+type configDiff struct {
+	Added, Removed               party.IDSlice
+	OldParties, NewParties       int
+	OldThreshold, NewThreshold   int
+	OldGeneration, NewGeneration uint64
+	OldKeyID, NewKeyID           string
+}
+
+// loadReshareKey returns the state of a JSON config of protocol, like loadPublicKey.
+func loadReshareKey(data []byte, protocol string, group curve.Curve) (*reshareKey, error) {
+	switch protocol {
+	case "lss":
+		config := lss.EmptyConfig(group)
+		if err := json.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal LSS config: %w", err)
+		}
+		return lssReshareKey(config)
+	case "cmp":
+		config := cmp.EmptyConfig(group)
+		if err := json.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal CMP config: %w", err)
+		}
+		return cmpReshareKey(config), nil
+	case "frost":
+		config := frost.EmptyConfig(group)
+		if err := json.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal FROST config: %w", err)
+		}
+		return frostReshareKey(config), nil
+	default:
+		return nil, fmt.Errorf("unknown protocol: %s", protocol)
+	}
+}
+
+// diffKeys compares the states of two configs.
+func diffKeys(oldKey, newKey *reshareKey) *configDiff {
+	d := &configDiff{
+		OldParties:    len(oldKey.Parties),
+		NewParties:    len(newKey.Parties),
+		OldThreshold:  oldKey.Threshold,
+		NewThreshold:  newKey.Threshold,
+		OldGeneration: oldKey.Generation,
+		NewGeneration: newKey.Generation,
+		OldKeyID:      curve.KeyID(oldKey.PublicKey),
+		NewKeyID:      curve.KeyID(newKey.PublicKey),
+	}
+	for _, id := range newKey.Parties {
+		if !oldKey.Parties.Contains(id) {
+			d.Added = append(d.Added, id)
+		}
+	}
+	for _, id := range oldKey.Parties {
+		if !newKey.Parties.Contains(id) {
+			d.Removed = append(d.Removed, id)
+		}
+	}
+	return d
+}
+
+// writeConfigDiff prints d for review.
+func writeConfigDiff(w io.Writer, d *configDiff) {
+	if d.OldKeyID == d.NewKeyID {
+		fmt.Fprintf(w, "Key ID:     %s (unchanged)\n", d.NewKeyID)
+	} else {
+		fmt.Fprintf(w, "Key ID:     %s -> %s (CHANGED)\n", d.OldKeyID, d.NewKeyID)
+	}
+	fmt.Fprintf(w, "Generation: %d -> %d (%+d)\n", d.OldGeneration, d.NewGeneration, int64(d.NewGeneration)-int64(d.OldGeneration))
+	fmt.Fprintf(w, "Threshold:  %d -> %d (%+d)\n", d.OldThreshold, d.NewThreshold, d.NewThreshold-d.OldThreshold)
+	fmt.Fprintf(w, "Parties:    %d -> %d\n", d.OldParties, d.NewParties)
+	fmt.Fprintf(w, "Added:      %s\n", formatIDs(d.Added))
+	fmt.Fprintf(w, "Removed:    %s\n", formatIDs(d.Removed))
+}
+
+// formatIDs lists ids, or none.
+func formatIDs(ids party.IDSlice) string {
+	if len(ids) == 0 {
+		return "none"
+	}
+	return ids.String()
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	group, err := getCurve(curveType)
+	if err != nil {
+		return err
+	}
+	if err := checkCurveProtocol(group, protocolName); err != nil {
+		return err
+	}
+	keys := make([]*reshareKey, len(args))
+	for i, path := range args {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read config: %w", err)
+		}
+		if keys[i], err = loadReshareKey(data, protocolName, group); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	writeConfigDiff(os.Stdout, diffKeys(keys[0], keys[1]))
+	return nil
+}
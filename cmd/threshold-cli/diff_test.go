@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+
+	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/pool"
+	"github.com/luxfi/threshold/protocols/cmp"
+	"github.com/luxfi/threshold/protocols/lss"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDiff diffs a 3-of-5 CMP config against its reshare to 3-of-7.
+func TestDiff(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	oldConfigs, partyIDs := test.GenerateConfig(curve.Secp256k1{}, 5, 2, rand.Reader, pl)
+	spec := &reshareSpec{Threshold: 2}
+	for _, id := range partyIDs {
+		spec.Parties = append(spec.Parties, string(id))
+	}
+	spec.Parties = append(spec.Parties, "f", "g")
+	plan, err := spec.planKey(cmpReshareKey(oldConfigs[partyIDs[0]]))
+	require.NoError(t, err)
+	newConfigs, err := lss.DynamicReshareCMP(map[party.ID]*cmp.Config{
+		partyIDs[0]: oldConfigs[partyIDs[0]],
+		partyIDs[1]: oldConfigs[partyIDs[1]],
+		partyIDs[2]: oldConfigs[partyIDs[2]],
+	}, plan.Parties, plan.Threshold, pl)
+	require.NoError(t, err)
+
+	load := func(config *cmp.Config) *reshareKey {
+		data, err := json.Marshal(config)
+		require.NoError(t, err)
+		key, err := loadReshareKey(data, "cmp", curve.Secp256k1{})
+		require.NoError(t, err)
+		return key
+	}
+	oldKey, newKey := load(oldConfigs[partyIDs[0]]), load(newConfigs[partyIDs[0]])
+
+	d := diffKeys(oldKey, newKey)
+	assert.Equal(t, party.IDSlice{"f", "g"}, d.Added)
+	assert.Empty(t, d.Removed)
+	assert.Equal(t, d.OldKeyID, d.NewKeyID, "resharing must preserve the key")
+
+	var out bytes.Buffer
+	writeConfigDiff(&out, d)
+	assert.Contains(t, out.String(), d.NewKeyID+" (unchanged)")
+	assert.Contains(t, out.String(), "Generation: 0 -> 1 (+1)")
+	assert.Contains(t, out.String(), "Threshold:  2 -> 2 (+0)")
+	assert.Contains(t, out.String(), "Parties:    5 -> 7")
+	assert.Contains(t, out.String(), "Added:      f, g")
+	assert.Contains(t, out.String(), "Removed:    none")
+
+	// the reverse diff reports the parties as removed
+	d = diffKeys(newKey, oldKey)
+	assert.Equal(t, party.IDSlice{"f", "g"}, d.Removed)
+	assert.Empty(t, d.Added)
+}
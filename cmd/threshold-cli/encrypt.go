@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+)
+
+// passphraseEnv is the environment variable holding the passphrase of encrypted configs,
+// which is prompted for when it is not set.
+const passphraseEnv = "THRESHOLD_PASSPHRASE"
+
+const (
+	encryptionAES256GCM = "aes-256-gcm"
+	kdfScrypt           = "scrypt"
+)
+
+// Parameters of scrypt for new files, as recommended for interactive logins in 2017, costing about 100ms.
+// Files record their own parameters, so these can be raised without breaking older files.
+const (
+	scryptN       = 1 << 15
+	scryptR       = 8
+	scryptP       = 1
+	scryptSaltLen = 16
+)
+
+// Bounds of the scrypt parameters read from a file, so that a crafted file can't make deriveKey allocate
+// gigabytes before the GCM tag rejects it. scrypt takes 128·N·r bytes, and 128·r·p more.
+const (
+	scryptMaxN      = 1 << 20
+	scryptMaxRP     = 1 << 30
+	scryptMaxMemory = 1 << 30
+)
+
+// errWrongPassphrase is returned when an encrypted config doesn't decrypt, which is most likely because of the passphrase.
+var errWrongPassphrase = errors.New("wrong passphrase, or corrupted file")
+
+// encryptedFile is a config encrypted with a key derived from a passphrase.
+// It records everything but the passphrase needed to decrypt it.
+type encryptedFile struct {
+	// Encryption is the cipher of Ciphertext, encryptionAES256GCM
+	Encryption string       `json:"encryption"`
+	KDF        scryptParams `json:"kdf"`
+	Nonce      []byte       `json:"nonce"`
+	// Ciphertext is the sealed JSON config, followed by the GCM tag
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// scryptParams are the parameters of the scrypt key derivation of an encryptedFile.
+type scryptParams struct {
+	// Name is kdfScrypt
+	Name string `json:"name"`
+	Salt []byte `json:"salt"`
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+}
+
+// deriveKey returns the AES-256 key derived from passphrase with the parameters of p.
+func (p scryptParams) deriveKey(passphrase []byte) ([]byte, error) {
+	if p.Name != kdfScrypt {
+		return nil, fmt.Errorf("unsupported key derivation %q", p.Name)
+	}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	key, err := scrypt.Key(passphrase, p.Salt, p.N, p.R, p.P, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive the key: %w", err)
+	}
+	return key, nil
+}
+
+// validate checks that the parameters are within the bounds deriveKey accepts.
+func (p scryptParams) validate() error {
+	if p.N <= 1 || p.N > scryptMaxN || p.N&(p.N-1) != 0 {
+		return fmt.Errorf("scrypt N %d must be a power of 2 of at most %d", p.N, scryptMaxN)
+	}
+	if p.R <= 0 || p.P <= 0 || p.R >= scryptMaxRP/p.P {
+		return fmt.Errorf("scrypt r %d and p %d must be positive, with r·p below %d", p.R, p.P, scryptMaxRP)
+	}
+	if uint64(128*p.N)*uint64(p.R) > scryptMaxMemory {
+		return fmt.Errorf("scrypt N %d and r %d need more than %d bytes", p.N, p.R, scryptMaxMemory)
+	}
+	return nil
+}
+
+// encryptConfig seals the config data under passphrase, with a fresh salt and nonce.
+func encryptConfig(data, passphrase []byte) ([]byte, error) {
+	kdf := scryptParams{Name: kdfScrypt, Salt: make([]byte, scryptSaltLen), N: scryptN, R: scryptR, P: scryptP}
+	if _, err := io.ReadFull(rand.Reader, kdf.Salt); err != nil {
+		return nil, fmt.Errorf("failed to sample the salt: %w", err)
+	}
+	key, err := kdf.deriveKey(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to sample the nonce: %w", err)
+	}
+	return json.MarshalIndent(&encryptedFile{
+		Encryption: encryptionAES256GCM,
+		KDF:        kdf,
+		Nonce:      nonce,
+		Ciphertext: aead.Seal(nil, nonce, data, nil),
+	}, "", "  ")
+}
+
+// decryptConfig opens an encrypted config, and returns errWrongPassphrase if it doesn't authenticate.
+func decryptConfig(data, passphrase []byte) ([]byte, error) {
+	var file encryptedFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal encrypted config: %w", err)
+	}
+	if file.Encryption != encryptionAES256GCM {
+		return nil, fmt.Errorf("unsupported encryption %q", file.Encryption)
+	}
+	key, err := file.KDF.deriveKey(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(file.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce length %d", len(file.Nonce))
+	}
+	plaintext, err := aead.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		return nil, errWrongPassphrase
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// isEncrypted reports whether data is an encrypted config, rather than a plain one.
+func isEncrypted(data []byte) bool {
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return false
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return false
+	}
+	_, ok := fields["encryption"]
+	return ok
+}
+
+// readPassphrase returns the passphrase from passphraseEnv, or otherwise prompts for it on the terminal,
+// twice if confirm is set, so that a typo doesn't lock the share away.
+// The passphrase isn't echoed on a terminal; piped input is read a line at a time.
+func readPassphrase(confirm bool) ([]byte, error) {
+	if passphrase, ok := os.LookupEnv(passphraseEnv); ok {
+		if passphrase == "" {
+			return nil, fmt.Errorf("%s is empty", passphraseEnv)
+		}
+		return []byte(passphrase), nil
+	}
+	in := bufio.NewReader(os.Stdin)
+	prompt := func(prompt string) (string, error) { return promptLine(in, prompt) }
+	if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+		prompt = func(prompt string) (string, error) { return promptHidden(fd, prompt) }
+	}
+	passphrase, err := prompt("Passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	if passphrase == "" {
+		return nil, errors.New("empty passphrase")
+	}
+	if confirm {
+		again, err := prompt("Repeat passphrase: ")
+		if err != nil {
+			return nil, err
+		}
+		if again != passphrase {
+			return nil, errors.New("the passphrases don't match")
+		}
+	}
+	return []byte(passphrase), nil
+}
+
+// promptHidden reads a line from the terminal fd without echoing it, so that it stays out of the scrollback.
+func promptHidden(fd int, prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	line, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the passphrase: %w", err)
+	}
+	return string(line), nil
+}
+
+// promptLine reads a line of piped input.
+func promptLine(in *bufio.Reader, prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	line, err := in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("failed to read the passphrase: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readConfigFile reads the config at path, decrypting it if decrypt is set.
+// A config which is encrypted is an error without decrypt, and a plain one with it.
+func readConfigFile(path string, decrypt bool) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	switch encrypted := isEncrypted(data); {
+	case encrypted && !decrypt:
+		return nil, fmt.Errorf("config %s is encrypted, use --decrypt", path)
+	case !encrypted && decrypt:
+		return nil, fmt.Errorf("config %s is not encrypted", path)
+	case !encrypted:
+		return data, nil
+	}
+	passphrase, err := readPassphrase(false)
+	if err != nil {
+		return nil, err
+	}
+	data, err = decryptConfig(data, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt config %s: %w", path, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/protocols/lss"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptConfig(t *testing.T) {
+	c, _ := testLSSConfig(t, 3, 2)
+	data, err := json.MarshalIndent(c, "", "  ")
+	require.NoError(t, err)
+
+	encrypted, err := encryptConfig(data, []byte("correct horse battery staple"))
+	require.NoError(t, err)
+	assert.True(t, isEncrypted(encrypted))
+	assert.False(t, isEncrypted(data))
+	share, err := json.Marshal(c.ECDSA)
+	require.NoError(t, err)
+	assert.NotContains(t, string(encrypted), string(share))
+
+	// the file records how to derive the key
+	var file encryptedFile
+	require.NoError(t, json.Unmarshal(encrypted, &file))
+	assert.Equal(t, encryptionAES256GCM, file.Encryption)
+	assert.Equal(t, kdfScrypt, file.KDF.Name)
+	assert.Len(t, file.KDF.Salt, scryptSaltLen)
+	assert.Equal(t, scryptN, file.KDF.N)
+
+	path := filepath.Join(t.TempDir(), "lss-a.json")
+	require.NoError(t, os.WriteFile(path, encrypted, 0600))
+
+	t.Setenv(passphraseEnv, "correct horse battery staple")
+	decrypted, err := readConfigFile(path, true)
+	require.NoError(t, err)
+	reloaded := lss.EmptyConfig(curve.Secp256k1{})
+	require.NoError(t, json.Unmarshal(decrypted, reloaded))
+	assert.True(t, c.ECDSA.Equal(reloaded.ECDSA))
+	assert.Equal(t, c.Generation, reloaded.Generation)
+
+	_, err = readConfigFile(path, false)
+	assert.ErrorContains(t, err, "--decrypt")
+
+	t.Setenv(passphraseEnv, "wrong horse battery staple")
+	_, err = readConfigFile(path, true)
+	assert.ErrorIs(t, err, errWrongPassphrase)
+}
+
+func TestDecryptConfigKDFBounds(t *testing.T) {
+	encrypted, err := encryptConfig([]byte(`{"id": "a"}`), []byte("passphrase"))
+	require.NoError(t, err)
+	var file encryptedFile
+	require.NoError(t, json.Unmarshal(encrypted, &file))
+
+	for name, kdf := range map[string]func(p *scryptParams){
+		"huge N":     func(p *scryptParams) { p.N = 1 << 40 },
+		"N not 2^k":  func(p *scryptParams) { p.N = 3 << 10 },
+		"huge r·p":   func(p *scryptParams) { p.R, p.P = 1<<15, 1<<15 },
+		"huge N·r":   func(p *scryptParams) { p.N, p.R = 1<<20, 1<<10 },
+		"negative p": func(p *scryptParams) { p.P = -1 },
+	} {
+		crafted := file
+		kdf(&crafted.KDF)
+		data, err := json.Marshal(&crafted)
+		require.NoError(t, err)
+		_, err = decryptConfig(data, []byte("passphrase"))
+		assert.ErrorContains(t, err, "scrypt", name)
+	}
+
+	plaintext, err := decryptConfig(encrypted, []byte("passphrase"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id": "a"}`, string(plaintext))
+}
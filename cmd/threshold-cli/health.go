@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/luxfi/threshold/protocols/cmp"
+	"github.com/spf13/cobra"
+)
+
+// Output formats of health
+const (
+	healthFormatText = "text"
+	healthFormatJSON = "json"
+)
+
+// loadSessionLog reads a signing session log: one JSON encoded cmp.SigningEvent per line.
+// Empty lines are skipped.
+func loadSessionLog(r io.Reader) ([]cmp.SigningEvent, error) {
+	var events []cmp.SigningEvent
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		var e cmp.SigningEvent
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, fmt.Errorf("session log line %d: %w", line, err)
+		}
+		if e.Party == "" {
+			return nil, fmt.Errorf("session log line %d: missing party", line)
+		}
+		events = append(events, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read session log: %w", err)
+	}
+	return events, nil
+}
+
+// writeHealthReport prints report as a table, or as JSON for dashboards.
+func writeHealthReport(w io.Writer, report *cmp.HealthReport, format string) error {
+	switch format {
+	case healthFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case healthFormatText:
+		lastSeen := func(t time.Time) string {
+			if t.IsZero() {
+				return "never"
+			}
+			return t.Format(time.RFC3339)
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "PARTY\tFAILURES\tRESPONSIVE\tLAST SEEN\tRESPONSE TIME\tLAST FAILURE")
+		for _, h := range report.Parties {
+			fmt.Fprintf(tw, "%s\t%d\t%t\t%s\t%s\t%s\n", h.ID, h.Failures, h.Responsive, lastSeen(h.LastSeen), h.ResponseTime, lastSeen(h.LastFailure))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "\n%d of %d parties responsive, %d needed to sign\n", report.Responsive, len(report.Parties), report.Threshold+1)
+		if report.QuorumAtRisk {
+			fmt.Fprintln(w, "WARNING: one more failure breaks quorum")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q, expected %s or %s", format, healthFormatText, healthFormatJSON)
+	}
+}
+
+func runHealth(cmd *cobra.Command, args []string) error {
+	logFile, _ := cmd.Flags().GetString("log")
+	format, _ := cmd.Flags().GetString("format")
+
+	if protocolName != "cmp" {
+		return fmt.Errorf("health reports are only available for CMP keys")
+	}
+	group, err := getCurve(curveType)
+	if err != nil {
+		return err
+	}
+	if err := checkCurveProtocol(group, protocolName); err != nil {
+		return err
+	}
+	data, err := os.ReadFile(inputFile)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	config := cmp.EmptyConfig(group)
+	if err := json.Unmarshal(data, config); err != nil {
+		return fmt.Errorf("failed to unmarshal CMP config: %w", err)
+	}
+
+	f, err := os.Open(logFile)
+	if err != nil {
+		return fmt.Errorf("failed to open session log: %w", err)
+	}
+	defer f.Close()
+	events, err := loadSessionLog(f)
+	if err != nil {
+		return err
+	}
+
+	coordinator := cmp.NewFaultTolerantCoordinator(config, nil)
+	for _, e := range events {
+		if err := coordinator.Record(e); err != nil {
+			return err
+		}
+	}
+	return writeHealthReport(os.Stdout, coordinator.GetHealthReport(), format)
+}
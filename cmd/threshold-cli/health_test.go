@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/protocols/cmp"
+	"github.com/luxfi/threshold/protocols/cmp/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthReportFromLog(t *testing.T) {
+	// a recent log, so that b's failures are within the recovery timeout
+	now := time.Now().UTC().Truncate(time.Second)
+	log := fmt.Sprintf(`{"party":"a","time":%q,"responded":true,"response_time":5000000}
+
+{"party":"b","time":%q,"responded":false}
+{"party":"b","time":%q,"responded":false}
+`, now.Add(-3*time.Second).Format(time.RFC3339), now.Add(-3*time.Second).Format(time.RFC3339), now.Add(-2*time.Second).Format(time.RFC3339))
+	events, err := loadSessionLog(strings.NewReader(log))
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	c := &cmp.Config{Threshold: 1, Public: map[party.ID]*config.Public{"a": {}, "b": {}, "c": {}}}
+	coordinator := cmp.NewFaultTolerantCoordinator(c, nil)
+	for _, e := range events {
+		require.NoError(t, coordinator.Record(e))
+	}
+	report := coordinator.GetHealthReport()
+
+	var out bytes.Buffer
+	require.NoError(t, writeHealthReport(&out, report, healthFormatJSON))
+	var decoded cmp.HealthReport
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	require.Len(t, decoded.Parties, 3)
+	assert.Equal(t, 2, decoded.Parties[1].Failures)
+	assert.False(t, decoded.Parties[1].Responsive)
+	assert.True(t, decoded.QuorumAtRisk)
+
+	out.Reset()
+	require.NoError(t, writeHealthReport(&out, report, healthFormatText))
+	assert.Contains(t, out.String(), "2 of 3 parties responsive")
+	assert.Contains(t, out.String(), "never")
+
+	assert.Error(t, writeHealthReport(&out, report, "yaml"))
+	_, err = loadSessionLog(strings.NewReader(`{"time":"2024-01-01T00:00:01Z"}`))
+	assert.ErrorContains(t, err, "line 1")
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportStaleGeneration(t *testing.T) {
+	current, _ := testLSSConfig(t, 3, 2)
+	current.Generation = 3
+
+	exported := current.Copy()
+	exported.Generation = 1
+
+	// a gen-1 config imported into a gen-3 context is rejected by default
+	err := checkGeneration(exported, current, false)
+	assert.ErrorIs(t, err, errStaleConfig)
+	assert.ErrorContains(t, err, "--allow-stale")
+
+	assert.NoError(t, checkGeneration(exported, current, true))
+	assert.NoError(t, checkGeneration(current.Copy(), current, false))
+
+	// a config from the future can't be explained by staleness
+	exported.Generation = 4
+	assert.Error(t, checkGeneration(exported, current, true))
+
+	other, _ := testLSSConfig(t, 3, 2)
+	other.Generation = current.Generation
+	assert.Error(t, checkGeneration(other, current, true), "different keys must never be accepted")
+}
+
+func TestImportLSSConfigStale(t *testing.T) {
+	current, _ := testLSSConfig(t, 3, 2)
+	current.ChainKey = []byte("chain key")
+	current.RID = []byte("rid")
+	current.Generation = 3
+	exported := current.Copy()
+	exported.Generation = 1
+
+	dir := t.TempDir()
+	currentFile := filepath.Join(dir, "current.json")
+	data, err := json.Marshal(current)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(currentFile, data, 0600))
+
+	data, err = json.Marshal(exported)
+	require.NoError(t, err)
+	imported, err := importLSSConfig(data, "json", current.Group)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, imported.Generation)
+
+	assert.ErrorIs(t, checkLSSImportGeneration(imported, currentFile, false), errStaleConfig)
+	assert.NoError(t, checkLSSImportGeneration(imported, currentFile, true))
+}
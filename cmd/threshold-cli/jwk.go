@@ -0,0 +1,101 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/luxfi/threshold/pkg/math/curve"
+)
+
+// jsonWebKey is a JSON Web Key (RFC 7517) holding the group public key of a config,
+// and optionally the secret share of the party.
+//
+// secp256k1 and P-256 keys are EC keys (RFC 7518, RFC 8812), Ed25519 keys are OKP keys (RFC 8037).
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y,omitempty"`
+	// D is the party's share of the private key, not the private key itself.
+	D   string `json:"d,omitempty"`
+	Use string `json:"use"`
+	Kid string `json:"kid"`
+}
+
+var b64 = base64.RawURLEncoding
+
+// exportToJWK encodes public as a JWK. If share is not nil, it is included as the d parameter.
+func exportToJWK(public curve.Point, share curve.Scalar) ([]byte, error) {
+	key, err := newJSONWebKey(public)
+	if err != nil {
+		return nil, err
+	}
+	if share != nil {
+		if key.Kty != "EC" {
+			// RFC 8037 defines d as the seed the secret scalar is hashed from, which a share doesn't have
+			return nil, fmt.Errorf("a secret share can't be encoded in an %s JWK", key.Crv)
+		}
+		d, err := share.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		key.D = b64.EncodeToString(d)
+	}
+	return json.MarshalIndent(key, "", "  ")
+}
+
+// newJSONWebKey returns the public JWK of public, with the key ID set to its RFC 7638 thumbprint.
+func newJSONWebKey(public curve.Point) (*jsonWebKey, error) {
+	if public.IsIdentity() {
+		return nil, errors.New("public key is the identity")
+	}
+	key := &jsonWebKey{Use: "sig"}
+	switch p := public.(type) {
+	case *curve.Secp256k1Point:
+		data, err := p.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		pk, err := secp256k1.ParsePubKey(data)
+		if err != nil {
+			return nil, err
+		}
+		uncompressed := pk.SerializeUncompressed()
+		key.Kty, key.Crv = "EC", "secp256k1"
+		key.X = b64.EncodeToString(uncompressed[1:33])
+		key.Y = b64.EncodeToString(uncompressed[33:])
+	case *curve.P256Point:
+		x, y := p.Coordinates()
+		key.Kty, key.Crv = "EC", "P-256"
+		key.X = b64.EncodeToString(x.FillBytes(make([]byte, 32)))
+		key.Y = b64.EncodeToString(y.FillBytes(make([]byte, 32)))
+	case *curve.Ed25519Point:
+		data, err := p.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		key.Kty, key.Crv = "OKP", "Ed25519"
+		key.X = b64.EncodeToString(data)
+	default:
+		return nil, fmt.Errorf("JWK export is not supported on curve %s", public.Curve().Name())
+	}
+	key.Kid = key.thumbprint()
+	return key, nil
+}
+
+// thumbprint computes the RFC 7638 thumbprint of k: the SHA-256 hash of its required public members,
+// in lexicographic order and without whitespace.
+func (k *jsonWebKey) thumbprint() string {
+	var members string
+	if k.Kty == "EC" {
+		members = fmt.Sprintf(`{"crv":%q,"kty":%q,"x":%q,"y":%q}`, k.Crv, k.Kty, k.X, k.Y)
+	} else {
+		members = fmt.Sprintf(`{"crv":%q,"kty":%q,"x":%q}`, k.Crv, k.Kty, k.X)
+	}
+	hash := sha256.Sum256([]byte(members))
+	return b64.EncodeToString(hash[:])
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/math/sample"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeJWK parses data as a generic JSON object, the way a JWKS consumer would.
+func decodeJWK(t *testing.T, data []byte) map[string]string {
+	var key map[string]string
+	require.NoError(t, json.Unmarshal(data, &key))
+	return key
+}
+
+func b64Decode(t *testing.T, s string) []byte {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	require.NoError(t, err, "JWK members must be unpadded base64url")
+	return data
+}
+
+// requireThumbprint checks that the kid is the RFC 7638 thumbprint of the key.
+func requireThumbprint(t *testing.T, key map[string]string, members string) {
+	hash := sha256.Sum256([]byte(members))
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(hash[:]), key["kid"])
+}
+
+func TestJWKSecp256k1(t *testing.T) {
+	group := curve.Secp256k1{}
+	share := sample.Scalar(rand.Reader, group)
+	public := share.ActOnBase()
+
+	data, err := exportToJWK(public, nil)
+	require.NoError(t, err)
+	key := decodeJWK(t, data)
+	assert.Equal(t, "EC", key["kty"])
+	assert.Equal(t, "secp256k1", key["crv"])
+	assert.NotContains(t, key, "d", "the secret share must only be exported on request")
+
+	x, y := b64Decode(t, key["x"]), b64Decode(t, key["y"])
+	require.Len(t, x, 32)
+	require.Len(t, y, 32)
+	pk, err := secp256k1.ParsePubKey(append(append([]byte{4}, x...), y...))
+	require.NoError(t, err, "coordinates must lie on secp256k1")
+	compressed, err := public.MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, compressed, pk.SerializeCompressed())
+	requireThumbprint(t, key, `{"crv":"secp256k1","kty":"EC","x":"`+key["x"]+`","y":"`+key["y"]+`"}`)
+
+	data, err = exportToJWK(public, share)
+	require.NoError(t, err)
+	key = decodeJWK(t, data)
+	d := group.NewScalar()
+	require.NoError(t, d.UnmarshalBinary(b64Decode(t, key["d"])))
+	assert.True(t, d.ActOnBase().Equal(public))
+
+	// the kid only depends on the public key
+	other, err := exportToJWK(sample.Scalar(rand.Reader, group).ActOnBase(), nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, key["kid"], decodeJWK(t, other)["kid"])
+	again, err := exportToJWK(public, nil)
+	require.NoError(t, err)
+	assert.Equal(t, key["kid"], decodeJWK(t, again)["kid"])
+}
+
+func TestJWKP256(t *testing.T) {
+	share := sample.Scalar(rand.Reader, curve.P256{})
+	data, err := exportToJWK(share.ActOnBase(), share)
+	require.NoError(t, err)
+	key := decodeJWK(t, data)
+	assert.Equal(t, "EC", key["kty"])
+	assert.Equal(t, "P-256", key["crv"])
+
+	d := b64Decode(t, key["d"])
+	private, err := ecdh.P256().NewPrivateKey(d)
+	require.NoError(t, err)
+	uncompressed := append(append([]byte{4}, b64Decode(t, key["x"])...), b64Decode(t, key["y"])...)
+	assert.Equal(t, uncompressed, private.PublicKey().Bytes(), "x and y must be the coordinates of d·G")
+	requireThumbprint(t, key, `{"crv":"P-256","kty":"EC","x":"`+key["x"]+`","y":"`+key["y"]+`"}`)
+}
+
+func TestJWKEd25519(t *testing.T) {
+	share := sample.Scalar(rand.Reader, curve.Ed25519{})
+	public := share.ActOnBase()
+
+	data, err := exportToJWK(public, nil)
+	require.NoError(t, err)
+	key := decodeJWK(t, data)
+	assert.Equal(t, "OKP", key["kty"])
+	assert.Equal(t, "Ed25519", key["crv"])
+	assert.NotContains(t, key, "y")
+	x := b64Decode(t, key["x"])
+	require.Len(t, x, ed25519.PublicKeySize)
+	encoded, err := public.MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, encoded, x)
+	requireThumbprint(t, key, `{"crv":"Ed25519","kty":"OKP","x":"`+key["x"]+`"}`)
+
+	_, err = exportToJWK(public, share)
+	assert.Error(t, err, "an Ed25519 share is not an RFC 8037 private key")
+}
+
+func TestJWKExportConfig(t *testing.T) {
+	c, publicKey := testLSSConfig(t, 3, 2)
+
+	data, err := exportLSSConfig(c, "jwk")
+	require.NoError(t, err)
+	key := decodeJWK(t, data)
+	assert.NotContains(t, key, "d")
+
+	includePrivate = true
+	defer func() { includePrivate = false }()
+	data, err = exportLSSConfig(c, "jwk")
+	require.NoError(t, err)
+	private := decodeJWK(t, data)
+	assert.Equal(t, key["kid"], private["kid"])
+	d, err := c.ECDSA.MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, d, b64Decode(t, private["d"]), "d must be the party's share")
+
+	pk, err := secp256k1.ParsePubKey(append(append([]byte{4}, b64Decode(t, key["x"])...), b64Decode(t, key["y"])...))
+	require.NoError(t, err)
+	assert.Equal(t, publicKey, hex.EncodeToString(pk.SerializeCompressed()), "the JWK must hold the group public key")
+}
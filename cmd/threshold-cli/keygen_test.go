@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/protocol"
+	"github.com/luxfi/threshold/protocols/lss"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeygenCustomPartyIDs(t *testing.T) {
+	names := []string{"aws-us-east-1", "gcp-europe-west1", "azure-eastus"}
+	ids, err := resolvePartyIDs(names, 0, "gcp-europe-west1")
+	require.NoError(t, err)
+	require.Len(t, ids, 3)
+
+	network := test.NewNetwork(ids)
+	configs := make([]*lss.Config, len(ids))
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id party.ID) {
+			defer wg.Done()
+			c, err := runLSSKeygen(curve.Secp256k1{}, id, ids, 2, nil, network.Transport(id))
+			require.NoError(t, err)
+			configs[i] = c
+		}(i, id)
+	}
+	wg.Wait()
+	for i, c := range configs {
+		assert.Equal(t, party.ID(names[i]), c.ID)
+		recorded := make([]string, 0, len(names))
+		for _, id := range c.PartyIDs() {
+			recorded = append(recorded, string(id))
+		}
+		assert.ElementsMatch(t, names, recorded)
+	}
+
+	_, err = resolvePartyIDs([]string{"a", "b", "a"}, 0, "a")
+	assert.ErrorContains(t, err, "listed twice")
+	_, err = resolvePartyIDs(names, 0, "party-1")
+	assert.ErrorContains(t, err, "not in party list")
+	_, err = resolvePartyIDs(names, 4, "")
+	assert.ErrorContains(t, err, "lists 3 parties")
+	_, err = resolvePartyIDs([]string{"a", ""}, 0, "")
+	assert.ErrorContains(t, err, "empty party ID")
+	_, err = resolvePartyIDs(nil, 0, "")
+	assert.ErrorContains(t, err, "is required")
+
+	defaults, err := resolvePartyIDs(nil, 3, "party-3")
+	require.NoError(t, err)
+	assert.Equal(t, keygenPartyIDs(3), defaults)
+}
+
+func TestDryRunKeygen(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, dryRunKeygen(&out, "secp256k1", "lss", "party-2", keygenPartyIDs(3), 2))
+	assert.Contains(t, out.String(), "lss keygen on secp256k1")
+	assert.Contains(t, out.String(), "party-1, party-2, party-3")
+	assert.Contains(t, out.String(), "Rounds:    4")
+
+	out.Reset()
+	require.NoError(t, dryRunKeygen(&out, "ed25519", "frost", "party-1", keygenPartyIDs(3), 1))
+	assert.Contains(t, out.String(), "Rounds:    5")
+
+	tests := []struct {
+		name                    string
+		curve, protocol, selfID string
+		parties, threshold      int
+		err                     string
+	}{
+		{"ed25519 lss", "ed25519", "lss", "party-1", 3, 2, "only supported with --protocol frost"},
+		{"ed25519 cmp", "ed25519", "cmp", "party-1", 3, 1, "only supported with --protocol frost"},
+		{"threshold too high", "secp256k1", "lss", "party-1", 3, 5, "threshold 5 exceeds parties 3"},
+		{"cmp threshold of all parties", "secp256k1", "cmp", "party-1", 3, 3, "exceeds parties 3"},
+		{"unknown self", "secp256k1", "frost", "party-9", 3, 1, "self party-9 is not among the parties"},
+		{"unknown curve", "secp384r1", "lss", "party-1", 3, 2, "unknown curve"},
+		{"unknown protocol", "secp256k1", "gg18", "party-1", 3, 2, "unknown protocol"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out.Reset()
+			err := dryRunKeygen(&out, tt.curve, tt.protocol, tt.selfID, keygenPartyIDs(tt.parties), tt.threshold)
+			assert.ErrorContains(t, err, tt.err)
+			assert.Empty(t, out.String(), "no plan is printed for invalid parameters")
+		})
+	}
+}
+
+func TestKeygenAbortReason(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	self, cheater := partyIDs[0], partyIDs[2]
+	start := func(id party.ID) *protocol.MultiHandler {
+		h, err := protocol.NewMultiHandler(lss.Keygen(curve.Secp256k1{}, id, partyIDs, 2, nil), nil)
+		require.NoError(t, err)
+		return h
+	}
+
+	// the cheater's first message can't be decoded
+	msg := <-start(cheater).Listen()
+	msg.Data = []byte("garbage")
+	h := start(self)
+	h.Accept(msg)
+
+	_, err := runHandler(h, test.NewNetwork(partyIDs).Transport(self), "keygen", time.Second)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "keygen aborted in round 1, "+string(cheater)+" identified as faulty: ")
+}
+
+func TestKeygenStalled(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	self, stalled := partyIDs[0], partyIDs[2]
+	network := test.NewNetwork(partyIDs)
+	// the stalled party never gets past its round 1 broadcast
+	network.SetFilter(func(from, _ party.ID, msg *protocol.Message) bool {
+		return from != stalled || msg.RoundNumber <= 1
+	})
+	handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+	for _, id := range partyIDs {
+		h, err := protocol.NewMultiHandler(lss.Keygen(curve.Secp256k1{}, id, partyIDs, 2, nil), nil)
+		require.NoError(t, err)
+		handlers[id] = h
+		if id != self {
+			go test.HandlerLoop(id, h, network)
+		}
+	}
+
+	var logs bytes.Buffer
+	var err error
+	logger, err = newLogger(&logs, "warn", "json")
+	require.NoError(t, err)
+	defer func() { logger = nil }()
+
+	_, err = runHandler(handlers[self], network.Transport(self), "keygen", 200*time.Millisecond)
+	assert.EqualError(t, err, "keygen stalled in round 2, missing "+string(stalled))
+	for _, h := range handlers {
+		h.Stop()
+	}
+	assert.Contains(t, logs.String(), `"msg":"protocol aborted"`)
+	assert.Contains(t, logs.String(), `"round":2`)
+	assert.Contains(t, logs.String(), `"missing":["`+string(stalled)+`"]`)
+
+	_, err = newLogger(&logs, "loud", "json")
+	assert.Error(t, err)
+	_, err = newLogger(&logs, "debug", "xml")
+	assert.Error(t, err)
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/spf13/cobra"
+)
+
+// keyListing is a row of keys list: the summary of a config file, or the reason it was skipped.
+type keyListing struct {
+	File       string
+	Protocol   string
+	Curve      string
+	KeyID      string
+	Threshold  int
+	Parties    int
+	Generation uint64
+	// Skipped is why the file isn't summarized, if it isn't
+	Skipped string
+}
+
+// detectConfigProtocol returns the protocol of a JSON config from the fields only its configs have.
+func detectConfigProtocol(data []byte) (string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return "", errors.New("not a JSON object")
+	}
+	switch {
+	case fields["encryption"] != nil:
+		return "", errors.New("encrypted")
+	case fields["verification_shares"] != nil:
+		return "frost", nil
+	case fields["paillier_p"] != nil:
+		return "cmp", nil
+	case fields["public"] != nil && fields["ecdsa"] != nil:
+		return "lss", nil
+	default:
+		return "", errors.New("not a config")
+	}
+}
+
+// listKey summarizes the config in data, on group, or on ed25519 for a FROST config which isn't on group.
+// Configs don't record their curve, but ed25519 points can't be mistaken for SEC 1 ones.
+func listKey(data []byte, group curve.Curve) (*keyListing, error) {
+	protocol, err := detectConfigProtocol(data)
+	if err != nil {
+		return nil, err
+	}
+	groups := []curve.Curve{group}
+	if _, ok := group.(curve.Ed25519); !ok && protocol == "frost" {
+		groups = append(groups, curve.Ed25519{})
+	}
+	for _, g := range groups {
+		if checkCurveProtocol(g, protocol) != nil {
+			continue
+		}
+		var key *reshareKey
+		if key, err = loadReshareKey(data, protocol, g); err != nil {
+			continue
+		}
+		return &keyListing{
+			Protocol:   protocol,
+			Curve:      g.Name(),
+			KeyID:      curve.KeyID(key.PublicKey),
+			Threshold:  key.Threshold,
+			Parties:    len(key.Parties),
+			Generation: key.Generation,
+		}, nil
+	}
+	if err == nil {
+		err = fmt.Errorf("%s config on %s", protocol, group.Name())
+	}
+	return nil, err
+}
+
+// listKeys summarizes the JSON files of dir, sorted by name. Files which aren't plain configs are listed as skipped,
+// so that an unreadable config doesn't go unnoticed.
+func listKeys(dir string, group curve.Curve) ([]keyListing, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config directory: %w", err)
+	}
+	var listings []keyListing
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err == nil {
+			var listing *keyListing
+			if listing, err = listKey(data, group); err == nil {
+				listing.File = entry.Name()
+				listings = append(listings, *listing)
+				continue
+			}
+		}
+		listings = append(listings, keyListing{File: entry.Name(), Skipped: err.Error()})
+	}
+	sort.SliceStable(listings, func(i, j int) bool { return listings[i].File < listings[j].File })
+	return listings, nil
+}
+
+// writeKeyList prints the configs of listings as a table, followed by the files which were skipped.
+func writeKeyList(w io.Writer, listings []keyListing) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FILE\tPROTOCOL\tCURVE\tKEY ID\tTHRESHOLD\tPARTIES\tGENERATION")
+	var skipped []keyListing
+	for _, l := range listings {
+		if l.Skipped != "" {
+			skipped = append(skipped, l)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%d\t%d\n", l.File, l.Protocol, l.Curve, l.KeyID, l.Threshold, l.Parties, l.Generation)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if len(skipped) > 0 {
+		fmt.Fprintf(w, "\nSkipped %d files:\n", len(skipped))
+		for _, l := range skipped {
+			fmt.Fprintf(w, "  %s: %s\n", l.File, l.Skipped)
+		}
+	}
+	return nil
+}
+
+func runKeysList(cmd *cobra.Command, args []string) error {
+	group, err := getCurve(curveType)
+	if err != nil {
+		return err
+	}
+	listings, err := listKeys(configDir, group)
+	if err != nil {
+		return err
+	}
+	return writeKeyList(os.Stdout, listings)
+}
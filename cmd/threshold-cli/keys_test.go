@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/pool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeysList(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	dir := t.TempDir()
+	write := func(name string, data []byte) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0600))
+	}
+	lssConfig, _ := testLSSConfig(t, 3, 2)
+	lssData, err := json.Marshal(lssConfig)
+	require.NoError(t, err)
+	write("lss-a.json", lssData)
+	cmpConfigs, partyIDs := test.GenerateConfig(curve.Secp256k1{}, 4, 1, rand.Reader, pl)
+	cmpConfig := cmpConfigs[partyIDs[0]]
+	cmpData, err := json.Marshal(cmpConfig)
+	require.NoError(t, err)
+	write("cmp-a.json", cmpData)
+
+	encrypted, err := encryptConfig(lssData, []byte("passphrase"))
+	require.NoError(t, err)
+	write("lss-b.json", encrypted)
+	write("notes.json", []byte(`{"owner": "ops"}`))
+	write("README", []byte("not listed"))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "backup.json"), 0700))
+
+	listings, err := listKeys(dir, curve.Secp256k1{})
+	require.NoError(t, err)
+	require.Len(t, listings, 4)
+	assert.Equal(t, keyListing{
+		File: "cmp-a.json", Protocol: "cmp", Curve: "secp256k1", KeyID: cmpConfig.KeyID(),
+		Threshold: 1, Parties: 4, Generation: cmpConfig.Generation,
+	}, listings[0])
+	assert.Equal(t, keyListing{
+		File: "lss-a.json", Protocol: "lss", Curve: "secp256k1", KeyID: lssConfig.KeyID(),
+		Threshold: 2, Parties: 3, Generation: lssConfig.Generation,
+	}, listings[1])
+	assert.Equal(t, keyListing{File: "lss-b.json", Skipped: "encrypted"}, listings[2])
+	assert.Equal(t, keyListing{File: "notes.json", Skipped: "not a config"}, listings[3])
+
+	var out bytes.Buffer
+	require.NoError(t, writeKeyList(&out, listings))
+	assert.Contains(t, out.String(), "cmp-a.json")
+	assert.Contains(t, out.String(), lssConfig.KeyID())
+	assert.Contains(t, out.String(), "Skipped 2 files")
+
+	_, err = listKeys(filepath.Join(dir, "missing"), curve.Secp256k1{})
+	assert.Error(t, err)
+}
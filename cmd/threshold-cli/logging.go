@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// logger receives the diagnostics of the protocol handlers, see --log-level and --log-format.
+var logger *slog.Logger
+
+// newLogger returns a logger writing records of level and above to w, as text or as one JSON object per line.
+func newLogger(w io.Writer, level, format string) (*slog.Logger, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("unknown log level %q: use debug, info, warn or error", level)
+	}
+	options := &slog.HandlerOptions{Level: l}
+	switch strings.ToLower(format) {
+	case "text":
+		return slog.New(slog.NewTextHandler(w, options)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, options)), nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q: use text or json", format)
+	}
+}
+
+// setupLogger creates the logger of the command from --log-level and --log-format, writing to stderr
+// so that it doesn't mix with the output of the command.
+func setupLogger(cmd *cobra.Command, _ []string) error {
+	var err error
+	logger, err = newLogger(cmd.ErrOrStderr(), logLevel, logFormat)
+	return err
+}
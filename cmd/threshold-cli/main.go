@@ -1,17 +1,23 @@
 package main
 
 import (
+	"bytes"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
-	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/pkg/ecdsa"
 	"github.com/luxfi/threshold/pkg/math/curve"
 	"github.com/luxfi/threshold/pkg/party"
 	"github.com/luxfi/threshold/pkg/pool"
+	"github.com/luxfi/threshold/pkg/protocol"
 	"github.com/luxfi/threshold/protocols/cmp"
 	"github.com/luxfi/threshold/protocols/frost"
 	"github.com/luxfi/threshold/protocols/lss"
@@ -24,7 +30,11 @@ var (
 	protocolName string
 	curveType    string
 	networkAddr  string
+	topologyFile string
 	verbose      bool
+	roundTimeout time.Duration
+	logLevel     string
+	logFormat    string
 
 	// Protocol options
 	threshold  int
@@ -32,6 +42,11 @@ var (
 	partyID    string
 	outputFile string
 	inputFile  string
+	// partyIDs are the IDs given with --party-ids, in place of party-1 ... party-N
+	partyIDs []string
+
+	// Export options
+	includePrivate bool
 
 	// Root command
 	rootCmd = &cobra.Command{
@@ -39,6 +54,7 @@ var (
 		Short: "CLI tool for threshold signature protocols",
 		Long: `A comprehensive CLI tool for testing and using threshold signature protocols
 including LSS-MPC, CGG21 (CMP), and FROST protocols.`,
+		PersistentPreRunE: setupLogger,
 	}
 
 	// Subcommands
@@ -59,15 +75,18 @@ including LSS-MPC, CGG21 (CMP), and FROST protocols.`,
 	reshareCmd = &cobra.Command{
 		Use:   "reshare",
 		Short: "Reshare keys with new parties",
-		Long:  `Dynamically reshare keys to add/remove parties or change threshold`,
-		RunE:  runReshare,
+		Long: `Dynamically reshare LSS, CMP and FROST keys to add/remove parties or change threshold, each party running reshare with its own config.
+Parties joining the group run reshare --join with the group file of the key, written by export --format group.`,
+		RunE: runReshare,
 	}
 
 	verifyCmd = &cobra.Command{
 		Use:   "verify",
 		Short: "Verify a signature",
-		Long:  `Verify a threshold signature against a public key and message`,
-		RunE:  runVerify,
+		Long: `Verify a threshold signature against a public key and message.
+Signature files written by sign record their scheme and curve, so --protocol and --curve
+are only needed for signature files without that header.`,
+		RunE: runVerify,
 	}
 
 	benchCmd = &cobra.Command{
@@ -105,6 +124,92 @@ including LSS-MPC, CGG21 (CMP), and FROST protocols.`,
 		RunE:  runImport,
 	}
 
+	sigConvertCmd = &cobra.Command{
+		Use:   "sig-convert",
+		Short: "Convert signatures between encodings",
+		Long:  `Convert ECDSA signatures between DER, raw 64-byte (r || s) and recoverable 65-byte (r || s || v) encodings`,
+		RunE:  runSigConvert,
+	}
+
+	presignCmd = &cobra.Command{
+		Use:   "presign",
+		Short: "Manage CMP presignatures",
+		Long: `Compute CMP presignatures ahead of signing and append them to a pool file.
+Each one is later consumed by sign --presig, which only runs the online round.`,
+		RunE: runPresign,
+	}
+
+	presignVerifyCmd = &cobra.Command{
+		Use:   "verify",
+		Short: "Verify a pool of presignatures",
+		Long:  `Check that every presignature in a pool is well-formed and consistent with the public key, and report how many are usable`,
+		RunE:  runPresignVerify,
+	}
+
+	schemaCmd = &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON schema of a file type",
+		Long:  `Print the JSON schema of the config, signature or presignature pool files of a protocol`,
+		RunE:  runSchema,
+	}
+
+	relayCmd = &cobra.Command{
+		Use:   "relay",
+		Short: "Run a message relay for distributed mode",
+		Long:  `Route protocol messages between parties running on different hosts. Each party runs keygen, sign, presign or reshare with --network set to the relay's address`,
+		RunE:  runRelay,
+	}
+
+	validateFileCmd = &cobra.Command{
+		Use:   "validate-file",
+		Short: "Check a file against its JSON schema",
+		Long:  `Check that a config, signature or presignature pool file matches its JSON schema, reporting the first problem found`,
+		RunE:  runValidateFile,
+	}
+
+	pubkeyCmd = &cobra.Command{
+		Use:   "pubkey",
+		Short: "Print the group public key of a config",
+		Long:  `Print the group public key of a config as hex, compressed SEC 1, an Ethereum address, or a Bitcoin P2WPKH (ECDSA) or P2TR (FROST) address`,
+		RunE:  runPubkey,
+	}
+
+	verifyConfigCmd = &cobra.Command{
+		Use:   "verify-config",
+		Short: "Check that a config holds shares of a known public key",
+		Long:  `Check that the shares of a config interpolate to its public key, and that this key is the expected one, for instance a published deposit key, before trusting an imported config`,
+		RunE:  runVerifyConfig,
+	}
+
+	healthCmd = &cobra.Command{
+		Use:   "health",
+		Short: "Report the health of the signers of a key",
+		Long:  `Replay a signing session log against a CMP config, and print the failure count, responsiveness and last-seen time of every party`,
+		RunE:  runHealth,
+	}
+
+	diffCmd = &cobra.Command{
+		Use:   "diff <old-config> <new-config>",
+		Short: "Compare two configs of a key",
+		Long:  `Print the parties added and removed, and the changes of threshold, generation and public key between two configs, for instance before and after a reshare`,
+		Args:  cobra.ExactArgs(2),
+		RunE:  runDiff,
+	}
+
+	keysCmd = &cobra.Command{
+		Use:   "keys",
+		Short: "Manage the configs of the config directory",
+	}
+
+	keysListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "Summarize the configs of the config directory",
+		Long: `Print the protocol, curve, key ID, threshold, party count and generation of every config in --config-dir.
+Files which can't be read as a config, including encrypted ones, are listed apart with the reason.
+Configs don't record their curve: they are read on --curve, and FROST configs on ed25519 too.`,
+		RunE: runKeysList,
+	}
+
 	infoCmd = &cobra.Command{
 		Use:   "info",
 		Short: "Display protocol information",
@@ -118,16 +223,23 @@ func init() {
 	rootCmd.PersistentFlags().StringVarP(&configDir, "config-dir", "d", "./threshold-data", "Configuration directory")
 	rootCmd.PersistentFlags().StringVarP(&protocolName, "protocol", "p", "lss", "Protocol to use: lss, cmp, frost")
 	rootCmd.PersistentFlags().StringVarP(&curveType, "curve", "c", "secp256k1", "Elliptic curve: secp256k1, p256, ed25519")
-	rootCmd.PersistentFlags().StringVarP(&networkAddr, "network", "n", "", "Network address for distributed mode")
+	rootCmd.PersistentFlags().StringVarP(&networkAddr, "network", "n", "", "Relay address for distributed mode (see the relay command)")
+	rootCmd.PersistentFlags().StringVar(&topologyFile, "topology", "", "Topology file with the gRPC address of every party taking part (the signers when signing), for distributed mode without a relay")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Verbose output")
+	rootCmd.PersistentFlags().DurationVar(&roundTimeout, "round-timeout", 30*time.Second, "Time after which a protocol making no progress in a round is aborted")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "warn", "Level of the protocol logs written to stderr: debug, info, warn, error")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "Format of the protocol logs: text, or json for one record per line with party, protocol and round fields")
 
 	// Keygen flags
 	keygenCmd.Flags().IntVarP(&threshold, "threshold", "t", 0, "Threshold value (required)")
-	keygenCmd.Flags().IntVarP(&parties, "parties", "N", 0, "Total number of parties (required)")
+	keygenCmd.Flags().IntVarP(&parties, "parties", "N", 0, "Total number of parties, named party-1 ... party-N (required without --party-ids)")
+	keygenCmd.Flags().StringSliceVar(&partyIDs, "party-ids", nil, "Comma-separated IDs of all parties, such as aws-us-east-1,gcp-europe-west1, in place of --parties")
 	keygenCmd.Flags().StringVarP(&partyID, "id", "i", "", "Party ID (required)")
 	keygenCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file for config")
+	keygenCmd.Flags().String("resume", "", "State file saving the progress of keygen after each round, encrypted under a passphrase read from "+passphraseEnv+" or prompted for: if keygen is interrupted, running it again with the same file continues where it stopped (lss and frost)")
+	keygenCmd.Flags().Bool("dry-run", false, "Only validate the parameters and print the plan of the run, without any network")
+	keygenCmd.Flags().Bool("encrypt", false, "Encrypt the config with AES-256-GCM under a passphrase, read from "+passphraseEnv+" or prompted for")
 	_ = keygenCmd.MarkFlagRequired("threshold")
-	_ = keygenCmd.MarkFlagRequired("parties")
 	_ = keygenCmd.MarkFlagRequired("id")
 
 	// Sign flags
@@ -136,6 +248,13 @@ func init() {
 	signCmd.Flags().StringSliceP("signers", "s", nil, "List of signer IDs")
 	signCmd.Flags().String("message", "", "Message to sign (hex encoded)")
 	signCmd.Flags().String("message-file", "", "File containing message to sign")
+	signCmd.Flags().String("messages-file", "", "File listing hex encoded messages to sign, one per line or as a JSON array; the output holds a signature per message")
+	signCmd.Flags().String("hash", "", "Message hash: sha256, keccak256, sha256d (double SHA-256), none (the message is a 32 byte digest); by default ECDSA signs the SHA-256 hash and FROST the message itself")
+	signCmd.Flags().String("taproot-merkle-root", "", "Sign a taproot key path spend with a FROST secp256k1 key, for the output key committing to this merkle root (hex, empty for no script tree)")
+	signCmd.Flags().String("format", signFormatJSON, "Signature output format: json, ethereum (65 byte r||s||v as hex), bitcoin-der (low-s DER as hex); the last two require secp256k1 ECDSA")
+	signCmd.Flags().Bool("decrypt", false, "Decrypt the config, encrypted by keygen --encrypt, with the passphrase from "+passphraseEnv+" or prompted for")
+	signCmd.Flags().String("presig", "", "Presignature pool file: sign with its first presignature, which is removed from the pool (cmp only)")
+	signCmd.Flags().String("session-id", "", "Session ID shared by all signers (hex); if omitted, a fresh one is printed, with which the other signers join over --network or --topology")
 	_ = signCmd.MarkFlagRequired("input")
 
 	// Reshare flags
@@ -144,6 +263,8 @@ func init() {
 	reshareCmd.Flags().IntVar(&threshold, "new-threshold", 0, "New threshold value")
 	reshareCmd.Flags().StringSlice("add-parties", nil, "Parties to add")
 	reshareCmd.Flags().StringSlice("remove-parties", nil, "Parties to remove")
+	reshareCmd.Flags().String("spec", "", "JSON file declaring the final party list and threshold")
+	reshareCmd.Flags().String("join", "", "Join the group as this new party: --input is then the group file written by export --format group")
 	reshareCmd.MarkFlagRequired("input")
 
 	// Verify flags
@@ -151,6 +272,8 @@ func init() {
 	verifyCmd.Flags().String("public-key", "", "Public key file (required)")
 	verifyCmd.Flags().String("message", "", "Message (hex encoded)")
 	verifyCmd.Flags().String("message-file", "", "File containing message")
+	verifyCmd.Flags().String("hash", "", "Message hash the signature was created with, as in sign")
+	verifyCmd.Flags().Bool("require-low-s", false, "Reject ECDSA signatures with a high s value, as Bitcoin and Ethereum do")
 	verifyCmd.MarkFlagRequired("signature")
 	verifyCmd.MarkFlagRequired("public-key")
 
@@ -158,6 +281,7 @@ func init() {
 	benchCmd.Flags().Int("iterations", 10, "Number of benchmark iterations")
 	benchCmd.Flags().String("operation", "all", "Operation to benchmark: keygen, sign, reshare, all")
 	benchCmd.Flags().Bool("profile", false, "Enable CPU profiling")
+	benchCmd.Flags().String("output", benchFormatText, "Output format: text, or json for CI regression tracking")
 
 	// Test flags
 	testCmd.Flags().String("suite", "all", "Test suite to run: functional, security, property, fuzz, all")
@@ -165,24 +289,85 @@ func init() {
 	testCmd.Flags().Duration("timeout", 0, "Test timeout (0 = no timeout)")
 
 	// Simulate flags
-	simulateCmd.Flags().String("scenario", "", "Scenario to simulate: byzantine, network-failure, etc.")
+	simulateCmd.Flags().String("scenario", "", "Scenario to simulate: byzantine, network-failure, concurrent-signing, large-scale, message-loss (CSV of the success rate at loss rates from 0 to 50%)")
 	simulateCmd.Flags().Int("rounds", 100, "Number of simulation rounds")
 	simulateCmd.Flags().Float64("failure-rate", 0.1, "Failure rate for fault simulation")
+	simulateCmd.Flags().Duration("timeout", 10*time.Second, "Time after which a run of the message-loss scenario fails")
 
 	// Export/Import flags
 	exportCmd.Flags().StringVarP(&inputFile, "input", "i", "", "Input config file (required)")
-	exportCmd.Flags().String("format", "pem", "Export format: pem, jwk, der")
+	exportCmd.Flags().String("format", "pem", "Export format: pem, der, jwk, json, cbor, or group for the public data with which new parties join a reshare")
 	exportCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file")
+	exportCmd.Flags().BoolVar(&includePrivate, "include-private", false, "Include the secret share in JWK output")
 	exportCmd.MarkFlagRequired("input")
 
 	importCmd.Flags().StringVarP(&inputFile, "input", "i", "", "Input file (required)")
-	importCmd.Flags().String("format", "pem", "Import format: pem, jwk, der")
+	importCmd.Flags().String("format", "pem", "Import format: pem, der, json, cbor")
 	importCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output config file")
+	importCmd.Flags().String("current", "", "Current config of the same key, used to detect stale imports (LSS only)")
+	importCmd.Flags().Bool("allow-stale", false, "Import the config even if it is older than the current generation")
 	importCmd.MarkFlagRequired("input")
 
+	// Signature conversion flags
+	sigConvertCmd.Flags().String("in", "", "Input signature file (required)")
+	sigConvertCmd.Flags().String("out", "", "Output file (default: stdout)")
+	sigConvertCmd.Flags().String("from", "der", "Input encoding: der, raw64, raw65")
+	sigConvertCmd.Flags().String("to", "raw64", "Output encoding: der, raw64, raw65")
+	sigConvertCmd.Flags().String("pubkey", "", "Public key file, needed to compute the recovery id for raw65")
+	sigConvertCmd.Flags().String("digest", "", "Signed 32-byte digest (hex encoded), needed to compute the recovery id for raw65")
+	_ = sigConvertCmd.MarkFlagRequired("in")
+
+	// Presign flags
+	presignCmd.Flags().StringVarP(&inputFile, "input", "i", "", "Input config file (required)")
+	presignCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Presignature pool file, appended to if it exists (default: presignatures.json)")
+	presignCmd.Flags().StringSliceP("signers", "s", nil, "List of signer IDs")
+	presignCmd.Flags().Int("count", 1, "Number of presignatures to compute")
+	presignCmd.Flags().String("session-id", "", "Session ID shared by all signers (hex); if omitted, a fresh one is printed, with which the other signers join over --network or --topology")
+	_ = presignCmd.MarkFlagRequired("input")
+	presignVerifyCmd.Flags().String("pool", "", "Presignature pool file (required)")
+	presignVerifyCmd.Flags().String("public-key", "", "Public key file (required)")
+	_ = presignVerifyCmd.MarkFlagRequired("pool")
+	_ = presignVerifyCmd.MarkFlagRequired("public-key")
+	presignCmd.AddCommand(presignVerifyCmd)
+
+	// Schema flags
+	schemaCmd.Flags().String("type", "config", "File type: config, signature, presignature-pool")
+	schemaCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file (default: stdout)")
+
+	// Validate-file flags
+	validateFileCmd.Flags().StringVarP(&inputFile, "input", "i", "", "Input file (required)")
+	validateFileCmd.Flags().String("type", "config", "File type: config, signature, presignature-pool")
+	_ = validateFileCmd.MarkFlagRequired("input")
+
+	// Pubkey flags
+	pubkeyCmd.Flags().StringVarP(&inputFile, "input", "i", "", "Input config file (required)")
+	pubkeyCmd.Flags().String("format", pubkeyFormatAll, "Encoding: all, hex, sec1, ethereum, p2wpkh (LSS and CMP), p2tr (FROST)")
+	_ = pubkeyCmd.MarkFlagRequired("input")
+
+	// Verify-config flags
+	verifyConfigCmd.Flags().StringVarP(&inputFile, "input", "i", "", "Input config file (required)")
+	verifyConfigCmd.Flags().String("expect-pubkey", "", "Expected public key, hex: SEC 1, ed25519, or x-only on secp256k1 (required)")
+	_ = verifyConfigCmd.MarkFlagRequired("input")
+	_ = verifyConfigCmd.MarkFlagRequired("expect-pubkey")
+
+	// Health flags
+	healthCmd.Flags().StringVarP(&inputFile, "input", "i", "", "Input config file (required)")
+	healthCmd.Flags().String("log", "", "Signing session log, one JSON event per line (required)")
+	healthCmd.Flags().String("format", healthFormatText, "Output format: text, json")
+	_ = healthCmd.MarkFlagRequired("input")
+	_ = healthCmd.MarkFlagRequired("log")
+
+	// Relay flags
+	relayCmd.Flags().String("listen", ":9000", "Address to listen on")
+	relayCmd.Flags().IntVarP(&parties, "parties", "N", 0, "Total number of parties, named party-1 ... party-N (required without --party-ids)")
+	relayCmd.Flags().StringSliceVar(&partyIDs, "party-ids", nil, "Comma-separated IDs of all parties, as given to keygen")
+
+	keysCmd.AddCommand(keysListCmd)
+
 	// Add subcommands
 	rootCmd.AddCommand(keygenCmd, signCmd, reshareCmd, verifyCmd, benchCmd,
-		testCmd, simulateCmd, exportCmd, importCmd, infoCmd)
+		testCmd, simulateCmd, exportCmd, importCmd, sigConvertCmd, presignCmd,
+		schemaCmd, validateFileCmd, pubkeyCmd, verifyConfigCmd, relayCmd, healthCmd, diffCmd, keysCmd, infoCmd)
 }
 
 func main() {
@@ -193,6 +378,32 @@ func main() {
 }
 
 func runKeygen(cmd *cobra.Command, args []string) error {
+	ids, err := resolvePartyIDs(partyIDs, parties, partyID)
+	if err != nil {
+		return err
+	}
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		return dryRunKeygen(os.Stdout, curveType, protocolName, partyID, ids, threshold)
+	}
+
+	// Ask for the passphrase before the protocol runs, rather than after
+	var passphrase []byte
+	if encrypt, _ := cmd.Flags().GetBool("encrypt"); encrypt {
+		if passphrase, err = readPassphrase(true); err != nil {
+			return err
+		}
+	}
+	// The resume state is encrypted with the passphrase of the config, or its own one without --encrypt,
+	// which is confirmed when the state file is created
+	resumeFile, _ := cmd.Flags().GetString("resume")
+	resumePassphrase := passphrase
+	if resumeFile != "" && resumePassphrase == nil {
+		_, err := os.Stat(resumeFile)
+		if resumePassphrase, err = readPassphrase(os.IsNotExist(err)); err != nil {
+			return err
+		}
+	}
+
 	// Create config directory
 	if err := os.MkdirAll(configDir, 0755); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
@@ -203,37 +414,26 @@ func runKeygen(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-
-	// Create party IDs
-	partyIDs := make([]party.ID, parties)
-	for i := 0; i < parties; i++ {
-		partyIDs[i] = party.ID(fmt.Sprintf("party-%d", i+1))
+	if err := checkCurveProtocol(group, protocolName); err != nil {
+		return err
 	}
 
 	// Find our index
+	partyIDs := ids
 	var ourIndex int
-	found := false
 	for i, id := range partyIDs {
 		if string(id) == partyID {
 			ourIndex = i
-			found = true
 			break
 		}
 	}
-	if !found {
-		return fmt.Errorf("party ID %s not in party list", partyID)
-	}
 
 	// Setup network
-	var network *test.Network
-	if networkAddr == "" {
-		// Local simulation mode
-		network = test.NewNetwork(partyIDs)
-		fmt.Println("Running in local simulation mode...")
-	} else {
-		// Distributed mode
-		return fmt.Errorf("distributed mode not yet implemented")
+	transport, closeTransport, err := openTransport(cmd.OutOrStdout(), partyIDs[ourIndex], partyIDs)
+	if err != nil {
+		return err
 	}
+	defer closeTransport()
 
 	// Run protocol
 	pl := pool.NewPool(0)
@@ -241,13 +441,15 @@ func runKeygen(cmd *cobra.Command, args []string) error {
 
 	var config interface{}
 
-	switch protocolName {
-	case "lss":
-		config, err = runLSSKeygen(group, partyIDs[ourIndex], partyIDs, threshold, pl, network)
-	case "cmp":
-		config, err = runCMPKeygen(group, partyIDs[ourIndex], partyIDs, threshold, pl, network)
-	case "frost":
-		config, err = runFROSTKeygen(group, partyIDs[ourIndex], partyIDs, threshold, pl, network)
+	switch {
+	case resumeFile != "":
+		config, err = runResumableKeygen(resumeFile, resumePassphrase, protocolName, group, partyIDs[ourIndex], partyIDs, threshold, pl, transport)
+	case protocolName == "lss":
+		config, err = runLSSKeygen(group, partyIDs[ourIndex], partyIDs, threshold, pl, transport)
+	case protocolName == "cmp":
+		config, err = runCMPKeygen(group, partyIDs[ourIndex], partyIDs, threshold, pl, transport)
+	case protocolName == "frost":
+		config, err = runFROSTKeygen(group, partyIDs[ourIndex], partyIDs, threshold, pl, transport)
 	default:
 		return fmt.Errorf("unknown protocol: %s", protocolName)
 	}
@@ -265,6 +467,11 @@ func runKeygen(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
+	if passphrase != nil {
+		if data, err = encryptConfig(data, passphrase); err != nil {
+			return fmt.Errorf("failed to encrypt config: %w", err)
+		}
+	}
 
 	if err := os.WriteFile(outputFile, data, 0600); err != nil {
 		return fmt.Errorf("failed to write config: %w", err)
@@ -297,31 +504,129 @@ func runKeygen(cmd *cobra.Command, args []string) error {
 			}
 		}
 	}
+	if k, ok := config.(keyIdentifier); ok {
+		fmt.Printf("Key ID: %s\n", k.KeyID())
+	}
 
 	return nil
 }
 
+// keyIdentifier is implemented by the configs of all protocols, see curve.KeyID.
+type keyIdentifier interface {
+	KeyID() string
+}
+
+// dryRunKeygen checks the keygen parameters without any network, by creating the first round of the
+// protocol, and writes the plan of the run to w.
+func dryRunKeygen(w io.Writer, curveName, protocolName, selfID string, partyIDs []party.ID, threshold int) error {
+	group, err := getCurve(curveName)
+	if err != nil {
+		return err
+	}
+	if err := checkCurveProtocol(group, protocolName); err != nil {
+		return err
+	}
+	var start protocol.StartFunc
+	switch protocolName {
+	case "lss":
+		start = lss.Keygen(group, party.ID(selfID), partyIDs, threshold, nil)
+	case "cmp":
+		start = cmp.Keygen(group, party.ID(selfID), partyIDs, threshold, nil)
+	case "frost":
+		start = frost.Keygen(group, party.ID(selfID), partyIDs, threshold)
+	default:
+		return fmt.Errorf("unknown protocol: %s", protocolName)
+	}
+	r, err := start(nil)
+	if err != nil {
+		return fmt.Errorf("invalid keygen parameters: %w", err)
+	}
+
+	ids := make([]string, len(partyIDs))
+	for i, id := range partyIDs {
+		ids[i] = string(id)
+	}
+	fmt.Fprintf(w, "Protocol:  %s keygen on %s\n", protocolName, group.Name())
+	fmt.Fprintf(w, "Threshold: %d of %d parties\n", threshold, len(partyIDs))
+	fmt.Fprintf(w, "Parties:   %s\n", strings.Join(ids, ", "))
+	fmt.Fprintf(w, "Self:      %s\n", selfID)
+	fmt.Fprintf(w, "Rounds:    %d\n", r.FinalRoundNumber())
+	fmt.Fprintln(w, "Dry run: the parameters are valid, no network was used.")
+	return nil
+}
+
 func runSign(cmd *cobra.Command, args []string) error {
 	// Load config
-	configData, err := os.ReadFile(inputFile)
+	decrypt, _ := cmd.Flags().GetBool("decrypt")
+	configData, err := readConfigFile(inputFile, decrypt)
 	if err != nil {
-		return fmt.Errorf("failed to read config: %w", err)
+		return err
+	}
+	if err := validateFile(configData, "config", protocolName); err != nil {
+		return fmt.Errorf("invalid config %s: %w", inputFile, err)
 	}
 
-	// Get message
-	var message []byte
-	if msgFile, _ := cmd.Flags().GetString("message-file"); msgFile != "" {
-		message, err = os.ReadFile(msgFile)
+	// Get the message, or the batch of messages
+	var messages [][]byte
+	messagesFile, _ := cmd.Flags().GetString("messages-file")
+	batch := messagesFile != ""
+	if batch {
+		if cmd.Flags().Changed("message") || cmd.Flags().Changed("message-file") {
+			return fmt.Errorf("--messages-file can't be combined with --message or --message-file")
+		}
+		data, err := os.ReadFile(messagesFile)
+		if err != nil {
+			return fmt.Errorf("failed to read messages file: %w", err)
+		}
+		if messages, err = parseMessages(data); err != nil {
+			return fmt.Errorf("invalid messages file %s: %w", messagesFile, err)
+		}
+	} else if msgFile, _ := cmd.Flags().GetString("message-file"); msgFile != "" {
+		message, err := os.ReadFile(msgFile)
 		if err != nil {
 			return fmt.Errorf("failed to read message file: %w", err)
 		}
+		messages = [][]byte{message}
 	} else if msgHex, _ := cmd.Flags().GetString("message"); msgHex != "" {
-		message, err = hex.DecodeString(msgHex)
+		message, err := hex.DecodeString(msgHex)
 		if err != nil {
 			return fmt.Errorf("failed to decode message: %w", err)
 		}
+		messages = [][]byte{message}
 	} else {
-		return fmt.Errorf("either --message or --message-file must be specified")
+		return fmt.Errorf("either --message, --message-file or --messages-file must be specified")
+	}
+
+	hashName, _ := cmd.Flags().GetString("hash")
+	digests := make([][]byte, len(messages))
+	for i, message := range messages {
+		if digests[i], err = messageDigest(message, hashName, protocolName); err != nil {
+			if batch {
+				return fmt.Errorf("message %d: %w", i, err)
+			}
+			return err
+		}
+	}
+	digest := digests[0]
+
+	taprootMerkleRoot, _ := cmd.Flags().GetString("taproot-merkle-root")
+	if cmd.Flags().Changed("taproot-merkle-root") && protocolName != "frost" {
+		return fmt.Errorf("--taproot-merkle-root requires the frost protocol")
+	}
+
+	presigFile, _ := cmd.Flags().GetString("presig")
+	if presigFile != "" && protocolName != "cmp" {
+		return fmt.Errorf("--presig requires the cmp protocol")
+	}
+	if batch && (presigFile != "" || cmd.Flags().Changed("taproot-merkle-root")) {
+		return fmt.Errorf("--messages-file can't be combined with --presig or --taproot-merkle-root")
+	}
+
+	format, _ := cmd.Flags().GetString("format")
+	switch format {
+	case signFormatJSON, signFormatEthereum, signFormatBitcoinDER:
+	default:
+		return fmt.Errorf("unknown signature format: %s", format)
 	}
 
 	// Get signers
@@ -331,39 +636,120 @@ func runSign(cmd *cobra.Command, args []string) error {
 		signers[i] = party.ID(s)
 	}
 
+	sessionID, err := readSessionID(cmd, cmd.OutOrStdout())
+	if err != nil {
+		return err
+	}
+
 	// Setup network
 	pl := pool.NewPool(0)
 	defer pl.TearDown()
 
-	var signature interface{}
+	// signatures[i] is the signature of messages[i]
+	var signatures []interface{}
+
+	group, err := getCurve(curveType)
+	if err != nil {
+		return err
+	}
+	if err := checkCurveProtocol(group, protocolName); err != nil {
+		return err
+	}
 
 	switch protocolName {
 	case "lss":
-		var config lss.Config
-		if err := json.Unmarshal(configData, &config); err != nil {
+		config := lss.EmptyConfig(group)
+		if err := json.Unmarshal(configData, config); err != nil {
 			return fmt.Errorf("failed to unmarshal LSS config: %w", err)
 		}
 
-		network := test.NewNetwork(signers)
-		signature, err = runLSSSign(&config, signers, message, pl, network)
+		transport, closeTransport, openErr := openTransport(cmd.OutOrStdout(), config.ID, signers)
+		if openErr != nil {
+			return openErr
+		}
+		defer closeTransport()
+
+		// one message after the other, over the same transport
+		for i, digest := range digests {
+			signature, signErr := runLSSSign(config, signers, digest, pl, executionSessionID(sessionID, i), transport)
+			if err = signErr; err != nil {
+				break
+			}
+			signatures = append(signatures, signature)
+		}
 
 	case "cmp":
-		var config cmp.Config
-		if err := json.Unmarshal(configData, &config); err != nil {
+		config := cmp.EmptyConfig(group)
+		if err := json.Unmarshal(configData, config); err != nil {
 			return fmt.Errorf("failed to unmarshal CMP config: %w", err)
 		}
 
-		network := test.NewNetwork(signers)
-		signature, err = runCMPSign(&config, signers, message, pl, network)
+		if presigFile != "" {
+			// only the online round runs, among the signers of the presignature
+			presig, presigErr := takePresignature(presigFile, group)
+			if presigErr != nil {
+				return presigErr
+			}
+			transport, closeTransport, openErr := openTransport(cmd.OutOrStdout(), config.ID, presig.SignerIDs())
+			if openErr != nil {
+				return openErr
+			}
+			defer closeTransport()
+			signature, signErr := runCMPPresignOnline(config, presig, digest, pl, sessionID, transport)
+			signatures, err = []interface{}{signature}, signErr
+			break
+		}
+		transport, closeTransport, openErr := openTransport(cmd.OutOrStdout(), config.ID, signers)
+		if openErr != nil {
+			return openErr
+		}
+		defer closeTransport()
+		if batch {
+			// presignatures for all messages are computed together, then all messages are signed together
+			batchSignatures, signErr := runCMPSignBatch(config, signers, digests, pl, sessionID, transport)
+			for _, signature := range batchSignatures {
+				signatures = append(signatures, signature)
+			}
+			err = signErr
+			break
+		}
+		signature, signErr := runCMPSign(config, signers, digest, pl, sessionID, transport)
+		signatures, err = []interface{}{signature}, signErr
 
 	case "frost":
-		var config frost.Config
-		if err := json.Unmarshal(configData, &config); err != nil {
+		config := frost.EmptyConfig(group)
+		if err := json.Unmarshal(configData, config); err != nil {
 			return fmt.Errorf("failed to unmarshal FROST config: %w", err)
 		}
 
-		network := test.NewNetwork(signers)
-		signature, err = runFROSTSign(&config, signers, message, pl, network)
+		transport, closeTransport, openErr := openTransport(cmd.OutOrStdout(), config.ID, signers)
+		if openErr != nil {
+			return openErr
+		}
+		defer closeTransport()
+
+		if cmd.Flags().Changed("taproot-merkle-root") {
+			var merkleRoot []byte
+			if merkleRoot, err = hex.DecodeString(taprootMerkleRoot); err != nil {
+				return fmt.Errorf("failed to decode taproot merkle root: %w", err)
+			}
+			outputKey, keyErr := frost.TaprootOutputKey(config.PublicKey, merkleRoot)
+			if keyErr != nil {
+				return keyErr
+			}
+			fmt.Printf("Taproot output key: %s\n", hex.EncodeToString(outputKey))
+			signature, signErr := runFROSTTaprootSign(config, signers, digest, merkleRoot, sessionID, transport)
+			signatures, err = []interface{}{signature}, signErr
+			break
+		}
+		// one message after the other, over the same transport
+		for i, digest := range digests {
+			signature, signErr := runFROSTSign(config, signers, digest, hashName != "", executionSessionID(sessionID, i), transport)
+			if err = signErr; err != nil {
+				break
+			}
+			signatures = append(signatures, signature)
+		}
 
 	default:
 		return fmt.Errorf("unknown protocol: %s", protocolName)
@@ -375,22 +761,147 @@ func runSign(cmd *cobra.Command, args []string) error {
 
 	// Save signature
 	if outputFile == "" {
-		outputFile = "signature.json"
+		outputFile = "signature"
+		if batch {
+			outputFile = "signatures"
+		}
+		if format == signFormatJSON {
+			outputFile += ".json"
+		} else {
+			outputFile += ".hex"
+		}
 	}
 
-	sigData, err := json.MarshalIndent(signature, "", "  ")
+	var sigData []byte
+	if batch {
+		sigData, err = encodeSignOutputs(signatures, format)
+	} else {
+		sigData, err = encodeSignOutput(signatures[0], format)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to marshal signature: %w", err)
+		return err
 	}
 
 	if err := os.WriteFile(outputFile, sigData, 0644); err != nil {
 		return fmt.Errorf("failed to write signature: %w", err)
 	}
 
+	if batch {
+		fmt.Printf("%d signatures created and saved to: %s\n", len(signatures), outputFile)
+		return nil
+	}
 	fmt.Printf("Signature created and saved to: %s\n", outputFile)
 	return nil
 }
 
+// parseMessages decodes the hex encoded messages of a messages file,
+// which is either a JSON array of strings or holds one message per line.
+func parseMessages(data []byte) ([][]byte, error) {
+	var encoded []string
+	if trimmed := bytes.TrimSpace(data); bytes.HasPrefix(trimmed, []byte("[")) {
+		if err := json.Unmarshal(trimmed, &encoded); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal messages: %w", err)
+		}
+	} else {
+		for _, line := range strings.Split(string(trimmed), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				encoded = append(encoded, line)
+			}
+		}
+	}
+	if len(encoded) == 0 {
+		return nil, errors.New("no messages")
+	}
+
+	messages := make([][]byte, len(encoded))
+	for i, s := range encoded {
+		message, err := hex.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("message %d: %w", i, err)
+		}
+		messages[i] = message
+	}
+	return messages, nil
+}
+
+// Output formats of the sign command
+const (
+	signFormatJSON       = "json"
+	signFormatEthereum   = "ethereum"
+	signFormatBitcoinDER = "bitcoin-der"
+)
+
+// encodeSignOutput encodes the result of a signing protocol according to format.
+// In JSON, the signature is preceded by a header recording its scheme and curve, if it has one.
+// encodeSignOutputs encodes the results of signing a batch of messages according to format:
+// as a JSON array, or with one signature per line.
+func encodeSignOutputs(signatures []interface{}, format string) ([]byte, error) {
+	if format == signFormatJSON {
+		files := make([]interface{}, len(signatures))
+		for i, signature := range signatures {
+			file, err := newSignatureFile(signature)
+			if err != nil {
+				return nil, err
+			}
+			files[i] = signature
+			if file != nil {
+				files[i] = file
+			}
+		}
+		data, err := json.MarshalIndent(files, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal signatures: %w", err)
+		}
+		return data, nil
+	}
+	var out []byte
+	for _, signature := range signatures {
+		line, err := encodeSignOutput(signature, format)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, line...)
+	}
+	return out, nil
+}
+
+func encodeSignOutput(signature interface{}, format string) ([]byte, error) {
+	if format == signFormatJSON {
+		file, err := newSignatureFile(signature)
+		if err != nil {
+			return nil, err
+		}
+		if file != nil {
+			signature = file
+		}
+		data, err := json.MarshalIndent(signature, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal signature: %w", err)
+		}
+		return data, nil
+	}
+	sig, ok := signature.(*ecdsa.Signature)
+	if !ok {
+		return nil, fmt.Errorf("--format %s requires an ECDSA signature, got %T", format, signature)
+	}
+	var (
+		data []byte
+		err  error
+	)
+	switch format {
+	case signFormatEthereum:
+		data, err = sig.EthereumRSV()
+	case signFormatBitcoinDER:
+		data, err = sig.BitcoinDER()
+	default:
+		return nil, fmt.Errorf("unknown signature format: %s", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode signature: %w", err)
+	}
+	return []byte(hex.EncodeToString(data) + "\n"), nil
+}
+
 func runReshare(cmd *cobra.Command, args []string) error {
 	// Load current config
 	configData, err := os.ReadFile(inputFile)
@@ -399,60 +910,183 @@ func runReshare(cmd *cobra.Command, args []string) error {
 	}
 
 	// Get parameters
+	specFile, _ := cmd.Flags().GetString("spec")
 	addParties, _ := cmd.Flags().GetStringSlice("add-parties")
 	removeParties, _ := cmd.Flags().GetStringSlice("remove-parties")
+	thresholdSet := cmd.Flags().Changed("new-threshold")
 
-	if threshold == 0 && len(addParties) == 0 && len(removeParties) == 0 {
-		return fmt.Errorf("must specify new threshold, parties to add, or parties to remove")
+	if specFile != "" {
+		if thresholdSet || len(addParties) != 0 || len(removeParties) != 0 {
+			return fmt.Errorf("--spec cannot be combined with --new-threshold, --add-parties or --remove-parties")
+		}
+	} else if !thresholdSet && len(addParties) == 0 && len(removeParties) == 0 {
+		return fmt.Errorf("must specify a spec, new threshold, parties to add, or parties to remove")
 	}
+	joinID, _ := cmd.Flags().GetString("join")
 
-	// Currently only LSS supports resharing
-	if protocolName != "lss" {
-		return fmt.Errorf("resharing is currently only supported for LSS protocol")
+	group, err := getCurve(curveType)
+	if err != nil {
+		return err
+	}
+	if err := checkCurveProtocol(group, protocolName); err != nil {
+		return err
 	}
 
-	var config lss.Config
-	if err := json.Unmarshal(configData, &config); err != nil {
-		return fmt.Errorf("failed to unmarshal config: %w", err)
+	// Express the flags as a spec of the final state, so that both are validated the same way
+	newSpec := func(key *reshareKey) (*reshareSpec, error) {
+		if specFile != "" {
+			return loadReshareSpec(specFile)
+		}
+		spec, err := membershipSpec(key, addParties, removeParties)
+		if err != nil {
+			return nil, err
+		}
+		if thresholdSet {
+			spec.Threshold = threshold
+		}
+		return spec, nil
+	}
+	planReshare := func(key *reshareKey) (*resharePlan, error) {
+		spec, err := newSpec(key)
+		if err != nil {
+			return nil, err
+		}
+		plan, err := spec.planKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if verbose {
+			fmt.Printf("Adding parties: %v\n", plan.Add)
+			fmt.Printf("Removing parties: %v\n", plan.Remove)
+			fmt.Printf("Threshold: %d -> %d\n", key.Threshold, plan.Threshold)
+		}
+		return plan, nil
+	}
+
+	// Each party reshares its own config over the network. CMP and FROST keys are reshared with lss.Reshare
+	// on the LSS view of the config, see cmpLSSConfig, and a party joining the group starts from its public data.
+	var config *lss.Config
+	if joinID != "" {
+		var g reshareGroup
+		if err := json.Unmarshal(configData, &g); err != nil {
+			return fmt.Errorf("failed to unmarshal group file: %w", err)
+		}
+		if g.Protocol != protocolName {
+			return fmt.Errorf("the group file is of a %s key, not %s", g.Protocol, protocolName)
+		}
+		if config, err = g.joinConfig(group, party.ID(joinID)); err != nil {
+			return fmt.Errorf("invalid group file: %w", err)
+		}
+	} else {
+		switch protocolName {
+		case "lss":
+			config = lss.EmptyConfig(group)
+			if err := json.Unmarshal(configData, config); err != nil {
+				return fmt.Errorf("failed to unmarshal config: %w", err)
+			}
+		case "cmp":
+			c := cmp.EmptyConfig(group)
+			if err := json.Unmarshal(configData, c); err != nil {
+				return fmt.Errorf("failed to unmarshal config: %w", err)
+			}
+			config = cmpLSSConfig(c)
+		case "frost":
+			c := frost.EmptyConfig(group)
+			if err := json.Unmarshal(configData, c); err != nil {
+				return fmt.Errorf("failed to unmarshal config: %w", err)
+			}
+			config = frostLSSConfig(c)
+		default:
+			return fmt.Errorf("unknown protocol: %s", protocolName)
+		}
+	}
+	key, err := lssReshareKey(config)
+	if err != nil {
+		return err
+	}
+	if protocolName != "lss" {
+		// threshold + 1 parties sign a CMP or FROST key
+		key.Threshold--
+		key.MinThreshold = 0
+	}
+	plan, err := planReshare(key)
+	if err != nil {
+		return err
+	}
+	if joinID != "" && !slices.Contains(plan.Add, config.ID) {
+		return fmt.Errorf("%s joins the group, but the reshare doesn't add it", config.ID)
 	}
 
 	// Setup network
 	pl := pool.NewPool(0)
 	defer pl.TearDown()
 
-	// Convert string IDs to party.ID
-	newPartyIDs := make([]party.ID, len(addParties))
-	for i, p := range addParties {
-		newPartyIDs[i] = party.ID(p)
+	// Connect to all old and new parties
+	allParties := append(config.PartyIDs(), plan.Add...)
+	transport, closeTransport, err := openTransport(cmd.OutOrStdout(), config.ID, allParties)
+	if err != nil {
+		return err
 	}
+	defer closeTransport()
 
-	// Create network with all parties
-	allParties := append(config.PartyIDs(), newPartyIDs...)
-	network := test.NewNetwork(allParties)
-
-	// Run resharing
-	newConfig, err := runLSSReshare(&config, threshold, newPartyIDs, pl, network)
-	if err != nil {
-		return fmt.Errorf("resharing failed: %w", err)
+	// Run resharing. A party leaving the group gets no config.
+	var newConfig interface{}
+	var keyID string
+	switch protocolName {
+	case "lss":
+		reshared, err := runLSSReshare(config, plan.Threshold, plan.Parties, pl, transport)
+		if err != nil {
+			return fmt.Errorf("resharing failed: %w", err)
+		}
+		if reshared.ECDSA != nil {
+			newConfig, keyID = reshared, reshared.KeyID()
+		}
+	case "cmp":
+		reshared, err := runCMPReshare(config, plan, pl, transport)
+		if err != nil {
+			return fmt.Errorf("resharing failed: %w", err)
+		}
+		if reshared != nil {
+			newConfig, keyID = reshared, reshared.KeyID()
+		}
+	case "frost":
+		reshared, err := runFROSTReshare(config, plan, pl, transport)
+		if err != nil {
+			return fmt.Errorf("resharing failed: %w", err)
+		}
+		if reshared != nil {
+			newConfig, keyID = reshared, curve.KeyID(reshared.PublicKey)
+		}
+	}
+	// the old share is superseded, and must not outlive the reshare in memory
+	config.Zeroize()
+	if newConfig == nil {
+		fmt.Printf("Resharing complete. %s left the group and keeps no share.\n", config.ID)
+		return nil
 	}
 
 	// Save new config
 	if outputFile == "" {
 		outputFile = filepath.Join(configDir, fmt.Sprintf("%s-%s-reshared.json", protocolName, config.ID))
 	}
+	if err := writeConfig(outputFile, newConfig); err != nil {
+		return err
+	}
+	fmt.Printf("Resharing complete. New config saved to: %s\n", outputFile)
+	fmt.Printf("New threshold: %d, Total parties: %d\n", plan.Threshold, len(plan.Parties))
+	fmt.Printf("Key ID: %s\n", keyID)
+	return nil
+}
 
-	data, err := json.MarshalIndent(newConfig, "", "  ")
+// writeConfig saves a config as JSON, readable only by the owner since it contains a secret share.
+func writeConfig(file string, config interface{}) error {
+	data, err := json.MarshalIndent(config, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
-
-	if err := os.WriteFile(outputFile, data, 0600); err != nil {
+	if err := os.WriteFile(file, data, 0600); err != nil {
 		return fmt.Errorf("failed to write config: %w", err)
 	}
-
-	fmt.Printf("Resharing complete. New config saved to: %s\n", outputFile)
-	fmt.Printf("New threshold: %d, Total parties: %d\n", newConfig.Threshold, len(newConfig.PartyIDs()))
-
 	return nil
 }
 
@@ -464,6 +1098,32 @@ func runVerify(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to read signature: %w", err)
 	}
 
+	// The header of the signature file tells how to verify it, and otherwise --protocol and --curve do
+	protocol, group := protocolName, curve.Curve(nil)
+	header, err := readSignatureFile(sigData)
+	if err != nil {
+		return fmt.Errorf("invalid signature %s: %w", sigFile, err)
+	}
+	if header != nil {
+		if protocol, err = header.protocol(); err != nil {
+			return fmt.Errorf("invalid signature %s: %w", sigFile, err)
+		}
+		if cmd.Flags().Changed("protocol") && schemeOf(protocolName) != header.Scheme {
+			return fmt.Errorf("signature %s is a %s signature, which --protocol %s doesn't produce", sigFile, header.Scheme, protocolName)
+		}
+		if group, err = getCurve(header.Curve); err != nil {
+			return fmt.Errorf("invalid signature %s: %w", sigFile, err)
+		}
+	} else if group, err = getCurve(curveType); err != nil {
+		return err
+	}
+	if err := validateFile(sigData, "signature", protocol); err != nil {
+		return fmt.Errorf("invalid signature %s: %w", sigFile, err)
+	}
+	if header != nil {
+		sigData = header.Signature
+	}
+
 	// Load public key
 	pkFile, _ := cmd.Flags().GetString("public-key")
 	pkData, err := os.ReadFile(pkFile)
@@ -487,17 +1147,30 @@ func runVerify(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("either --message or --message-file must be specified")
 	}
 
+	requireLowS, _ := cmd.Flags().GetBool("require-low-s")
+	hashName, _ := cmd.Flags().GetString("hash")
+	digest, err := messageDigest(message, hashName, protocol)
+	if err != nil {
+		return err
+	}
+
 	// Verify based on protocol
 	valid := false
-	switch protocolName {
+	switch protocol {
 	case "lss", "cmp":
 		// ECDSA verification
-		valid, err = verifyECDSA(sigData, pkData, message)
+		valid, err = verifyECDSA(sigData, pkData, digest, group, requireLowS)
 	case "frost":
-		// Schnorr verification
-		valid, err = verifySchnorr(sigData, pkData, message)
+		if requireLowS {
+			return fmt.Errorf("--require-low-s only applies to ECDSA signatures")
+		}
+		// Schnorr verification, on the curve of the header if there is one
+		if header == nil {
+			group = nil
+		}
+		valid, err = verifySchnorr(sigData, pkData, digest, group)
 	default:
-		return fmt.Errorf("unknown protocol: %s", protocolName)
+		return fmt.Errorf("unknown protocol: %s", protocol)
 	}
 
 	if err != nil {
@@ -517,42 +1190,34 @@ func runBenchmark(cmd *cobra.Command, args []string) error {
 	iterations, _ := cmd.Flags().GetInt("iterations")
 	operation, _ := cmd.Flags().GetString("operation")
 	enableProfile, _ := cmd.Flags().GetBool("profile")
+	format, _ := cmd.Flags().GetString("output")
+
+	// the JSON results are the only output, so that they can be piped
+	var w io.Writer
+	switch format {
+	case benchFormatText:
+		w = os.Stdout
+	case benchFormatJSON:
+		w = io.Discard
+	default:
+		return fmt.Errorf("unknown output %q, expected %s or %s", format, benchFormatText, benchFormatJSON)
+	}
 
-	fmt.Printf("Running %s benchmarks for %s protocol...\n", operation, protocolName)
-	fmt.Printf("Iterations: %d\n", iterations)
+	fmt.Fprintf(w, "Running %s benchmarks for %s protocol...\n", operation, protocolName)
+	fmt.Fprintf(w, "Iterations: %d\n", iterations)
 
 	if enableProfile {
 		// Setup CPU profiling
-		fmt.Println("CPU profiling enabled")
+		fmt.Fprintln(w, "CPU profiling enabled")
 	}
 
-	// Run benchmarks based on operation
-	switch operation {
-	case "keygen":
-		return benchmarkKeygen(protocolName, iterations)
-	case "sign":
-		return benchmarkSign(protocolName, iterations)
-	case "reshare":
-		if protocolName != "lss" {
-			return fmt.Errorf("reshare benchmark only available for LSS protocol")
-		}
-		return benchmarkReshare(iterations)
-	case "all":
-		if err := benchmarkKeygen(protocolName, iterations); err != nil {
-			return err
-		}
-		if err := benchmarkSign(protocolName, iterations); err != nil {
-			return err
-		}
-		if protocolName == "lss" {
-			if err := benchmarkReshare(iterations); err != nil {
-				return err
-			}
-		}
-	default:
-		return fmt.Errorf("unknown operation: %s", operation)
+	results, err := runBenchmarks(w, protocolName, operation, iterations)
+	if err != nil {
+		return err
+	}
+	if format == benchFormatJSON {
+		return writeBenchResults(os.Stdout, results)
 	}
-
 	return nil
 }
 
@@ -604,6 +1269,13 @@ func runSimulation(cmd *cobra.Command, args []string) error {
 	rounds, _ := cmd.Flags().GetInt("rounds")
 	failureRate, _ := cmd.Flags().GetFloat64("failure-rate")
 
+	// the sweep writes CSV to stdout, so nothing else is printed there
+	if scenario == "message-loss" {
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		_, err := simulateMessageLoss(os.Stdout, protocolName, rounds, messageLossRates, timeout)
+		return err
+	}
+
 	fmt.Printf("Running %s simulation for %s protocol...\n", scenario, protocolName)
 	fmt.Printf("Rounds: %d, Failure rate: %.2f%%\n", rounds, failureRate*100)
 
@@ -615,7 +1287,8 @@ func runSimulation(cmd *cobra.Command, args []string) error {
 	case "concurrent-signing":
 		return simulateConcurrentSigning(protocolName, rounds)
 	case "large-scale":
-		return simulateLargeScale(protocolName, rounds)
+		_, err := simulateLargeScale(os.Stdout, protocolName, rounds, largeScaleSizes)
+		return err
 	default:
 		return fmt.Errorf("unknown scenario: %s", scenario)
 	}
@@ -630,27 +1303,35 @@ func runExport(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to read config: %w", err)
 	}
 
+	group, err := getCurve(curveType)
+	if err != nil {
+		return err
+	}
+	if err := checkCurveProtocol(group, protocolName); err != nil {
+		return err
+	}
+
 	var exported []byte
 
 	switch protocolName {
 	case "lss":
-		var config lss.Config
-		if err := json.Unmarshal(configData, &config); err != nil {
+		config := lss.EmptyConfig(group)
+		if err := json.Unmarshal(configData, config); err != nil {
 			return fmt.Errorf("failed to unmarshal config: %w", err)
 		}
-		exported, err = exportLSSConfig(&config, format)
+		exported, err = exportLSSConfig(config, format)
 	case "cmp":
-		var config cmp.Config
-		if err := json.Unmarshal(configData, &config); err != nil {
+		config := cmp.EmptyConfig(group)
+		if err := json.Unmarshal(configData, config); err != nil {
 			return fmt.Errorf("failed to unmarshal config: %w", err)
 		}
-		exported, err = exportCMPConfig(&config, format)
+		exported, err = exportCMPConfig(config, format)
 	case "frost":
-		var config frost.Config
-		if err := json.Unmarshal(configData, &config); err != nil {
+		config := frost.EmptyConfig(group)
+		if err := json.Unmarshal(configData, config); err != nil {
 			return fmt.Errorf("failed to unmarshal config: %w", err)
 		}
-		exported, err = exportFROSTConfig(&config, format)
+		exported, err = exportFROSTConfig(config, format)
 	default:
 		return fmt.Errorf("unknown protocol: %s", protocolName)
 	}
@@ -664,7 +1345,8 @@ func runExport(cmd *cobra.Command, args []string) error {
 		outputFile = fmt.Sprintf("exported.%s", format)
 	}
 
-	if err := os.WriteFile(outputFile, exported, 0644); err != nil {
+	// exports contain the secret share, unless they are JWKs without --include-private
+	if err := os.WriteFile(outputFile, exported, 0600); err != nil {
 		return fmt.Errorf("failed to write exported data: %w", err)
 	}
 
@@ -674,6 +1356,8 @@ func runExport(cmd *cobra.Command, args []string) error {
 
 func runImport(cmd *cobra.Command, args []string) error {
 	format, _ := cmd.Flags().GetString("format")
+	currentFile, _ := cmd.Flags().GetString("current")
+	allowStale, _ := cmd.Flags().GetBool("allow-stale")
 
 	// Read input file
 	data, err := os.ReadFile(inputFile)
@@ -683,13 +1367,25 @@ func runImport(cmd *cobra.Command, args []string) error {
 
 	var config interface{}
 
+	group, err := getCurve(curveType)
+	if err != nil {
+		return err
+	}
+	if err = checkCurveProtocol(group, protocolName); err != nil {
+		return err
+	}
+
 	switch protocolName {
 	case "lss":
-		config, err = importLSSConfig(data, format)
+		var lssConfig *lss.Config
+		if lssConfig, err = importLSSConfig(data, format, group); err == nil && currentFile != "" {
+			err = checkLSSImportGeneration(lssConfig, currentFile, allowStale)
+		}
+		config = lssConfig
 	case "cmp":
-		config, err = importCMPConfig(data, format)
+		config, err = importCMPConfig(data, format, group)
 	case "frost":
-		config, err = importFROSTConfig(data, format)
+		config, err = importFROSTConfig(data, format, group)
 	default:
 		return fmt.Errorf("unknown protocol: %s", protocolName)
 	}
@@ -697,6 +1393,9 @@ func runImport(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("import failed: %w", err)
 	}
+	if currentFile != "" && protocolName != "lss" {
+		return fmt.Errorf("--current is only supported for the LSS protocol")
+	}
 
 	// Save config
 	if outputFile == "" {
@@ -754,11 +1453,20 @@ func getCurve(curveType string) (curve.Curve, error) {
 	switch strings.ToLower(curveType) {
 	case "secp256k1":
 		return curve.Secp256k1{}, nil
-	case "p256":
-		return nil, fmt.Errorf("p256 not yet supported")
+	case "p256", "p-256":
+		return curve.P256{}, nil
 	case "ed25519":
-		return nil, fmt.Errorf("ed25519 not yet supported")
+		return curve.Ed25519{}, nil
 	default:
 		return nil, fmt.Errorf("unknown curve: %s", curveType)
 	}
 }
+
+// checkCurveProtocol rejects curves the protocol cannot sign with.
+// CMP and LSS produce ECDSA signatures, which are not defined over Ed25519.
+func checkCurveProtocol(group curve.Curve, protocol string) error {
+	if _, ok := group.(curve.Ed25519); ok && protocol != "frost" {
+		return fmt.Errorf("curve ed25519 is only supported with --protocol frost, not %s", protocol)
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/luxfi/threshold/pkg/ecdsa"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/pool"
+	"github.com/luxfi/threshold/protocols/cmp"
+	"github.com/spf13/cobra"
+)
+
+// presignaturePool is the on-disk format of a pool of CMP presignatures.
+type presignaturePool struct {
+	// Presignatures holds each presignature's binary encoding
+	Presignatures [][]byte `json:"presignatures"`
+}
+
+// readPresignaturePool reads the pool in path, which is empty if the file does not exist.
+func readPresignaturePool(path string) (*presignaturePool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &presignaturePool{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read presignature pool: %w", err)
+	}
+	if err := validateFile(data, "presignature-pool", "cmp"); err != nil {
+		return nil, fmt.Errorf("invalid presignature pool %s: %w", path, err)
+	}
+	var pool presignaturePool
+	if err := json.Unmarshal(data, &pool); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal presignature pool: %w", err)
+	}
+	return &pool, nil
+}
+
+func writePresignaturePool(path string, pool *presignaturePool) error {
+	data, err := json.MarshalIndent(pool, "", "  ")
+	if err != nil {
+		return err
+	}
+	// presignatures hold secret nonce shares
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write presignature pool: %w", err)
+	}
+	return nil
+}
+
+// savePresignatures appends presigs to the pool in path, creating it if needed.
+func savePresignatures(path string, presigs []*ecdsa.PreSignature) error {
+	pool, err := readPresignaturePool(path)
+	if err != nil {
+		return err
+	}
+	for _, presig := range presigs {
+		data, err := presig.MarshalBinary()
+		if err != nil {
+			return fmt.Errorf("failed to marshal presignature: %w", err)
+		}
+		pool.Presignatures = append(pool.Presignatures, data)
+	}
+	return writePresignaturePool(path, pool)
+}
+
+// takePresignature removes the first presignature from the pool in path and returns it.
+//
+// The pool is rewritten before the presignature is used, so that it is never used twice,
+// even if signing fails or the process is interrupted: reusing a presignature for two messages leaks the key.
+func takePresignature(path string, group curve.Curve) (*ecdsa.PreSignature, error) {
+	pool, err := readPresignaturePool(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(pool.Presignatures) == 0 {
+		return nil, fmt.Errorf("presignature pool %s is empty", path)
+	}
+	presig := ecdsa.EmptyPreSignature(group)
+	if err := presig.UnmarshalBinary(pool.Presignatures[0]); err != nil {
+		return nil, fmt.Errorf("failed to decode presignature: %w", err)
+	}
+	pool.Presignatures = pool.Presignatures[1:]
+	if err := writePresignaturePool(path, pool); err != nil {
+		return nil, err
+	}
+	return presig, nil
+}
+
+// publicKeyConfig provides the public key of a signing key to ecdsa.PreSignature.Verify.
+type publicKeyConfig struct {
+	publicKey curve.Point
+}
+
+func (c publicKeyConfig) PublicPoint() curve.Point {
+	return c.publicKey
+}
+
+// presignatureReport is the outcome of verifying a pool of presignatures.
+type presignatureReport struct {
+	Total  int
+	Usable int
+	// Invalid maps the index of each unusable presignature to the reason it was rejected
+	Invalid map[int]error
+}
+
+// verifyPresignaturePool decodes and verifies every presignature in pool against the given key.
+func verifyPresignaturePool(pool *presignaturePool, group curve.Curve, config ecdsa.KeyConfig) *presignatureReport {
+	report := &presignatureReport{
+		Total:   len(pool.Presignatures),
+		Invalid: make(map[int]error),
+	}
+	for i, data := range pool.Presignatures {
+		presig := ecdsa.EmptyPreSignature(group)
+		if err := presig.UnmarshalBinary(data); err != nil {
+			report.Invalid[i] = err
+			continue
+		}
+		if err := presig.Verify(config); err != nil {
+			report.Invalid[i] = err
+			continue
+		}
+		report.Usable++
+	}
+	return report
+}
+
+func runPresignVerify(cmd *cobra.Command, args []string) error {
+	poolFile, _ := cmd.Flags().GetString("pool")
+	pkFile, _ := cmd.Flags().GetString("public-key")
+
+	group, err := getCurve(curveType)
+	if err != nil {
+		return err
+	}
+	publicKey, err := readPublicKey(pkFile, group)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(poolFile)
+	if err != nil {
+		return fmt.Errorf("failed to read presignature pool: %w", err)
+	}
+	if err := validateFile(data, "presignature-pool", protocolName); err != nil {
+		return fmt.Errorf("invalid presignature pool %s: %w", poolFile, err)
+	}
+	var pool presignaturePool
+	if err := json.Unmarshal(data, &pool); err != nil {
+		return fmt.Errorf("failed to unmarshal presignature pool: %w", err)
+	}
+
+	report := verifyPresignaturePool(&pool, group, publicKeyConfig{publicKey})
+	for i := 0; i < report.Total; i++ {
+		if err, ok := report.Invalid[i]; ok {
+			fmt.Printf("Presignature %d: invalid: %v\n", i, err)
+		} else if verbose {
+			fmt.Printf("Presignature %d: ok\n", i)
+		}
+	}
+	fmt.Printf("%d of %d presignatures usable\n", report.Usable, report.Total)
+
+	if report.Usable < report.Total {
+		return fmt.Errorf("%d presignatures are invalid", report.Total-report.Usable)
+	}
+	return nil
+}
+
+func runPresign(cmd *cobra.Command, args []string) error {
+	if protocolName != "cmp" {
+		return fmt.Errorf("presignatures are only supported by the cmp protocol")
+	}
+	count, _ := cmd.Flags().GetInt("count")
+	if count < 1 {
+		return fmt.Errorf("--count must be positive")
+	}
+
+	group, err := getCurve(curveType)
+	if err != nil {
+		return err
+	}
+	configData, err := os.ReadFile(inputFile)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	if err := validateFile(configData, "config", protocolName); err != nil {
+		return fmt.Errorf("invalid config %s: %w", inputFile, err)
+	}
+	config := cmp.EmptyConfig(group)
+	if err := json.Unmarshal(configData, config); err != nil {
+		return fmt.Errorf("failed to unmarshal CMP config: %w", err)
+	}
+
+	signerStrs, _ := cmd.Flags().GetStringSlice("signers")
+	signers := make([]party.ID, len(signerStrs))
+	for i, s := range signerStrs {
+		signers[i] = party.ID(s)
+	}
+
+	sessionID, err := readSessionID(cmd, cmd.OutOrStdout())
+	if err != nil {
+		return err
+	}
+
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	transport, closeTransport, err := openTransport(cmd.OutOrStdout(), config.ID, signers)
+	if err != nil {
+		return err
+	}
+	defer closeTransport()
+
+	// one presignature after the other, over the same transport
+	presigs := make([]*ecdsa.PreSignature, 0, count)
+	for i := 0; i < count; i++ {
+		presig, err := runCMPPresign(config, signers, pl, executionSessionID(sessionID, i), transport)
+		if err != nil {
+			return fmt.Errorf("presigning failed: %w", err)
+		}
+		presigs = append(presigs, presig)
+	}
+
+	if outputFile == "" {
+		outputFile = "presignatures.json"
+	}
+	if err := savePresignatures(outputFile, presigs); err != nil {
+		return err
+	}
+	fmt.Printf("%d presignatures saved to: %s\n", count, outputFile)
+	return nil
+}
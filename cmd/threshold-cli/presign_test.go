@@ -0,0 +1,150 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"path/filepath"
+	"testing"
+
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/internal/types"
+	"github.com/luxfi/threshold/pkg/ecdsa"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/math/sample"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/pool"
+	"github.com/luxfi/threshold/protocols/cmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPresignature returns the first party's share of a presignature for the key x⋅G,
+// computed by a trusted dealer.
+func testPresignature(t *testing.T, x curve.Scalar, partyIDs []party.ID) *ecdsa.PreSignature {
+	group := x.Curve()
+	k := sample.Scalar(rand.Reader, group)
+	kInv := group.NewScalar().Set(k).Invert()
+	R := kInv.ActOnBase()
+	chi := group.NewScalar().Set(x).Mul(k)
+
+	// additive shares of k and χ
+	kShares := make(map[party.ID]curve.Scalar, len(partyIDs))
+	chiShares := make(map[party.ID]curve.Scalar, len(partyIDs))
+	kLast, chiLast := group.NewScalar().Set(k), group.NewScalar().Set(chi)
+	for _, id := range partyIDs[1:] {
+		kShares[id] = sample.Scalar(rand.Reader, group)
+		chiShares[id] = sample.Scalar(rand.Reader, group)
+		kLast.Sub(kShares[id])
+		chiLast.Sub(chiShares[id])
+	}
+	kShares[partyIDs[0]], chiShares[partyIDs[0]] = kLast, chiLast
+
+	RBar := make(map[party.ID]curve.Point, len(partyIDs))
+	S := make(map[party.ID]curve.Point, len(partyIDs))
+	for _, id := range partyIDs {
+		RBar[id] = kShares[id].Act(R)
+		S[id] = chiShares[id].Act(R)
+	}
+	id, err := types.NewRID(rand.Reader)
+	require.NoError(t, err)
+	return &ecdsa.PreSignature{
+		ID:       id,
+		R:        R,
+		RBar:     party.NewPointMap(RBar),
+		S:        party.NewPointMap(S),
+		KShare:   kShares[partyIDs[0]],
+		ChiShare: chiShares[partyIDs[0]],
+	}
+}
+
+func TestVerifyPresignaturePool(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(3)
+	x := sample.Scalar(rand.Reader, group)
+	config := publicKeyConfig{x.ActOnBase()}
+
+	var pool presignaturePool
+	for i := 0; i < 4; i++ {
+		presig := testPresignature(t, x, partyIDs)
+		if i == 2 {
+			// tamper with our nonce share
+			presig.KShare = group.NewScalar().Set(presig.KShare).Add(group.NewScalar().Set(x))
+		}
+		data, err := presig.MarshalBinary()
+		require.NoError(t, err)
+		pool.Presignatures = append(pool.Presignatures, data)
+	}
+	pool.Presignatures = append(pool.Presignatures, []byte("corrupted"))
+
+	report := verifyPresignaturePool(&pool, group, config)
+	assert.Equal(t, 5, report.Total)
+	assert.Equal(t, 3, report.Usable)
+	require.Len(t, report.Invalid, 2)
+	assert.Contains(t, report.Invalid, 2, "tampered presignature should be flagged")
+	assert.Contains(t, report.Invalid, 4, "undecodable presignature should be flagged")
+
+	// none are usable for another key
+	other := publicKeyConfig{sample.Scalar(rand.Reader, group).ActOnBase()}
+	assert.Equal(t, 0, verifyPresignaturePool(&pool, group, other).Usable)
+}
+
+// TestPresignThenResume saves CMP presignatures to disk, and signs with them after reloading
+// the pools as a restarted process would.
+func TestPresignThenResume(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	group := curve.Secp256k1{}
+	configs, partyIDs := test.GenerateConfig(group, 3, 1, rand.Reader, pl)
+	signers := []party.ID{partyIDs[0], partyIDs[2]}
+	dir := t.TempDir()
+	poolFile := func(id party.ID) string { return filepath.Join(dir, string(id)+".json") }
+
+	// two presignatures per signer, saved in two batches
+	for i := 0; i < 2; i++ {
+		rounds := make([]round.Session, 0, len(signers))
+		for _, id := range signers {
+			r, err := cmp.Presign(configs[id], signers, pl)(nil)
+			require.NoError(t, err)
+			rounds = append(rounds, r)
+		}
+		require.NoError(t, test.RunRounds(rounds, nil))
+		for j, id := range signers {
+			presig := rounds[j].(*round.Output).Result.(*ecdsa.PreSignature)
+			require.NoError(t, savePresignatures(poolFile(id), []*ecdsa.PreSignature{presig}))
+		}
+	}
+
+	publicKey := configs[partyIDs[0]].PublicPoint()
+	for i := 0; i < 2; i++ {
+		hash := sha256.Sum256([]byte{byte(i)})
+		rounds := make([]round.Session, 0, len(signers))
+		for _, id := range signers {
+			// nothing but the config and the pool file survive the restart
+			data, err := configs[id].MarshalBinary()
+			require.NoError(t, err)
+			c := cmp.EmptyConfig(group)
+			require.NoError(t, c.UnmarshalBinary(data))
+
+			presig, err := takePresignature(poolFile(id), group)
+			require.NoError(t, err)
+			assert.Equal(t, party.NewIDSlice(signers), presig.SignerIDs())
+			r, err := cmp.PresignOnline(c, presig, hash[:], pl)(nil)
+			require.NoError(t, err)
+			rounds = append(rounds, r)
+		}
+		require.NoError(t, test.RunRounds(rounds, nil))
+		for _, r := range rounds {
+			sig := r.(*round.Output).Result.(*ecdsa.Signature)
+			assert.True(t, sig.Verify(publicKey, hash[:]))
+		}
+	}
+
+	// every presignature was consumed
+	_, err := takePresignature(poolFile(signers[0]), group)
+	assert.ErrorContains(t, err, "empty")
+	remaining, err := readPresignaturePool(poolFile(signers[1]))
+	require.NoError(t, err)
+	assert.Empty(t, remaining.Presignatures)
+}
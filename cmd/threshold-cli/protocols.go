@@ -1,80 +1,101 @@
 package main
 
 import (
+	"context"
+	"crypto/ed25519"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
-	"github.com/luxfi/threshold/internal/test"
+	"filippo.io/nistec"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/luxfi/threshold/pkg/ecdsa"
 	"github.com/luxfi/threshold/pkg/math/curve"
 	"github.com/luxfi/threshold/pkg/party"
 	"github.com/luxfi/threshold/pkg/pool"
 	"github.com/luxfi/threshold/pkg/protocol"
+	"github.com/luxfi/threshold/pkg/taproot"
 	"github.com/luxfi/threshold/protocols/cmp"
 	"github.com/luxfi/threshold/protocols/frost"
 	"github.com/luxfi/threshold/protocols/lss"
+	"golang.org/x/crypto/sha3"
 )
 
 // LSS Protocol implementations
 
-func runLSSKeygen(group curve.Curve, selfID party.ID, partyIDs []party.ID, threshold int, pl *pool.Pool, network *test.Network) (*lss.Config, error) {
+// runHandler drives h over transport until the protocol finishes, which is either an in-process network
+// or a connection to a relay in distributed mode.
+// The protocol is aborted once a round makes no progress for roundTimeout, and operation names it in the error.
+func runHandler(h *protocol.MultiHandler, transport protocol.Transport, operation string, roundTimeout time.Duration) (interface{}, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	h.SetLogger(logger)
+	// a stalled handler is aborted, which closes its Listen channel and ends protocol.Run
+	go func() { _, _ = h.ResultWithRoundTimeout(ctx, roundTimeout) }()
+	result, err := protocol.Run(ctx, h, transport)
+	var stalled *protocol.ErrStalled
+	if errors.As(err, &stalled) {
+		return nil, fmt.Errorf("%s stalled in round %d, missing %s", operation, stalled.Round, party.IDSlice(stalled.Missing))
+	}
+	var aborted *protocol.ErrAborted
+	if errors.As(err, &aborted) {
+		return nil, abortError(operation, aborted)
+	}
+	return result, err
+}
+
+// abortError explains why operation was aborted: the round, the parties identified as faulty, and the round's error,
+// as in "keygen aborted in round 3, party-2 identified as faulty: invalid commitment".
+func abortError(operation string, aborted *protocol.ErrAborted) error {
+	msg := operation + " aborted"
+	if aborted.Round != 0 {
+		msg += fmt.Sprintf(" in round %d", aborted.Round)
+	}
+	if len(aborted.Culprits) > 0 {
+		msg += fmt.Sprintf(", %s identified as faulty", party.IDSlice(aborted.Culprits))
+	}
+	if aborted.Reason == nil {
+		return errors.New(msg)
+	}
+	return fmt.Errorf("%s: %w", msg, aborted.Reason)
+}
+
+func runLSSKeygen(group curve.Curve, selfID party.ID, partyIDs []party.ID, threshold int, pl *pool.Pool, transport protocol.Transport) (*lss.Config, error) {
 	h, err := protocol.NewMultiHandler(lss.Keygen(group, selfID, partyIDs, threshold, pl), nil)
 	if err != nil {
 		return nil, err
 	}
-
-	// Run protocol in goroutine
-	done := make(chan error)
-	go func() {
-		test.HandlerLoop(selfID, h, network)
-		done <- nil
-	}()
-
-	// Wait for completion or timeout
-	select {
-	case <-done:
-		result, err := h.Result()
-		if err != nil {
-			return nil, err
-		}
-		return result.(*lss.Config), nil
-	case <-time.After(30 * time.Second):
-		return nil, fmt.Errorf("keygen timeout")
+	result, err := runHandler(h, transport, "keygen", roundTimeout)
+	if err != nil {
+		return nil, err
 	}
+	return result.(*lss.Config), nil
 }
 
-func runLSSSign(config *lss.Config, signers []party.ID, message []byte, pl *pool.Pool, network *test.Network) (*ecdsa.Signature, error) {
-	// Hash the message
-	hash := sha256.Sum256(message)
-
-	h, err := protocol.NewMultiHandler(lss.Sign(config, signers, hash[:], pl), nil)
+func runLSSSign(config *lss.Config, signers []party.ID, digest []byte, pl *pool.Pool, sessionID []byte, transport protocol.Transport) (*ecdsa.Signature, error) {
+	// the digest was computed by messageDigest, as chosen with --hash, and is signed as is
+	if len(digest) != 32 {
+		return nil, fmt.Errorf("ECDSA signs a 32 byte digest, got %d bytes", len(digest))
+	}
+	h, err := protocol.NewMultiHandler(lss.SignDigest(config, signers, [32]byte(digest), pl), sessionID)
 	if err != nil {
 		return nil, err
 	}
 
-	done := make(chan error)
-	go func() {
-		test.HandlerLoop(config.ID, h, network)
-		done <- nil
-	}()
-
-	select {
-	case <-done:
-		result, err := h.Result()
-		if err != nil {
-			return nil, err
-		}
-		return result.(*ecdsa.Signature), nil
-	case <-time.After(30 * time.Second):
-		return nil, fmt.Errorf("signing timeout")
+	result, err := runHandler(h, transport, "signing", roundTimeout)
+	if err != nil {
+		return nil, err
 	}
+	return result.(*ecdsa.Signature), nil
 }
 
-func runLSSReshare(config *lss.Config, newThreshold int, newParties []party.ID, pl *pool.Pool, network *test.Network) (*lss.Config, error) {
+func runLSSReshare(config *lss.Config, newThreshold int, newParties []party.ID, pl *pool.Pool, transport protocol.Transport) (*lss.Config, error) {
 	if newThreshold == 0 {
 		newThreshold = config.Threshold
 	}
@@ -84,212 +105,415 @@ func runLSSReshare(config *lss.Config, newThreshold int, newParties []party.ID,
 		return nil, err
 	}
 
-	done := make(chan error)
-	go func() {
-		test.HandlerLoop(config.ID, h, network)
-		done <- nil
-	}()
-
-	select {
-	case <-done:
-		result, err := h.Result()
-		if err != nil {
-			return nil, err
-		}
-		return result.(*lss.Config), nil
-	case <-time.After(60 * time.Second):
-		return nil, fmt.Errorf("reshare timeout")
+	result, err := runHandler(h, transport, "reshare", roundTimeout)
+	if err != nil {
+		return nil, err
 	}
+	return result.(*lss.Config), nil
 }
 
 // CMP Protocol implementations
 
-func runCMPKeygen(group curve.Curve, selfID party.ID, partyIDs []party.ID, threshold int, pl *pool.Pool, network *test.Network) (*cmp.Config, error) {
+func runCMPKeygen(group curve.Curve, selfID party.ID, partyIDs []party.ID, threshold int, pl *pool.Pool, transport protocol.Transport) (*cmp.Config, error) {
 	h, err := protocol.NewMultiHandler(cmp.Keygen(group, selfID, partyIDs, threshold, pl), nil)
 	if err != nil {
 		return nil, err
 	}
+	result, err := runHandler(h, transport, "keygen", roundTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return result.(*cmp.Config), nil
+}
 
-	done := make(chan error)
-	go func() {
-		test.HandlerLoop(selfID, h, network)
-		done <- nil
-	}()
-
-	select {
-	case <-done:
-		result, err := h.Result()
-		if err != nil {
-			return nil, err
-		}
-		return result.(*cmp.Config), nil
-	case <-time.After(30 * time.Second):
-		return nil, fmt.Errorf("keygen timeout")
+func runCMPSign(config *cmp.Config, signers []party.ID, digest []byte, pl *pool.Pool, sessionID []byte, transport protocol.Transport) (*ecdsa.Signature, error) {
+	// the digest was computed by messageDigest, as chosen with --hash, and is signed as is
+	if len(digest) != 32 {
+		return nil, fmt.Errorf("ECDSA signs a 32 byte digest, got %d bytes", len(digest))
 	}
+	h, err := protocol.NewMultiHandler(cmp.SignDigest(config, signers, [32]byte(digest), pl), sessionID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := runHandler(h, transport, "signing", roundTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return result.(*ecdsa.Signature), nil
 }
 
-func runCMPSign(config *cmp.Config, signers []party.ID, message []byte, pl *pool.Pool, network *test.Network) (*ecdsa.Signature, error) {
-	// For CMP, we need to run presign first
-	h, err := protocol.NewMultiHandler(cmp.Presign(config, signers, pl), nil)
+// runCMPSignBatch signs every digest, with a batch of presignatures computed in a single protocol execution,
+// and then a single online execution signing all digests.
+func runCMPSignBatch(config *cmp.Config, signers []party.ID, digests [][]byte, pl *pool.Pool, sessionID []byte, transport protocol.Transport) ([]*ecdsa.Signature, error) {
+	h, err := protocol.NewMultiHandler(cmp.PresignBatch(config, signers, len(digests), pl), sessionID)
 	if err != nil {
 		return nil, err
 	}
+	result, err := runHandler(h, transport, "presign", roundTimeout)
+	if err != nil {
+		return nil, err
+	}
+	presigs := result.([]*ecdsa.PreSignature)
 
-	done := make(chan error)
-	go func() {
-		test.HandlerLoop(config.ID, h, network)
-		done <- nil
-	}()
+	h, err = protocol.NewMultiHandler(cmp.SignBatchOnline(config, presigs, digests, pl), sessionID)
+	if err != nil {
+		return nil, err
+	}
+	result, err = runHandler(h, transport, "signing", roundTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*ecdsa.Signature), nil
+}
 
-	var presignResult *ecdsa.PreSignature
-	select {
-	case <-done:
-		result, err := h.Result()
-		if err != nil {
-			return nil, err
-		}
-		presignResult = result.(*ecdsa.PreSignature)
-	case <-time.After(30 * time.Second):
-		return nil, fmt.Errorf("presign timeout")
+func runCMPPresign(config *cmp.Config, signers []party.ID, pl *pool.Pool, sessionID []byte, transport protocol.Transport) (*ecdsa.PreSignature, error) {
+	h, err := protocol.NewMultiHandler(cmp.Presign(config, signers, pl), sessionID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := runHandler(h, transport, "presign", roundTimeout)
+	if err != nil {
+		return nil, err
 	}
+	return result.(*ecdsa.PreSignature), nil
+}
 
-	// Now run actual signing
-	hash := sha256.Sum256(message)
-	h, err = protocol.NewMultiHandler(cmp.PresignOnline(config, presignResult, hash[:], pl), nil)
+// runCMPPresignOnline signs digest with a presignature computed earlier, among the parties that computed it.
+func runCMPPresignOnline(config *cmp.Config, presig *ecdsa.PreSignature, digest []byte, pl *pool.Pool, sessionID []byte, transport protocol.Transport) (*ecdsa.Signature, error) {
+	h, err := protocol.NewMultiHandler(cmp.PresignOnline(config, presig, digest, pl), sessionID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := runHandler(h, transport, "signing", roundTimeout)
 	if err != nil {
 		return nil, err
 	}
+	return result.(*ecdsa.Signature), nil
+}
 
-	done = make(chan error)
-	go func() {
-		test.HandlerLoop(config.ID, h, network)
-		done <- nil
-	}()
+// runCMPReshare reshares a CMP key to the parties and threshold of plan, from the LSS view of our config,
+// see cmpLSSConfig: lss.Reshare moves the shares to the new parties, and cmp.Refresh then gives every new party
+// fresh Paillier, Pedersen and ElGamal keys. Each party runs it with its own config, so that no process holds
+// more than its share. A party leaving the group gets a nil config.
+func runCMPReshare(config *lss.Config, plan *resharePlan, pl *pool.Pool, transport protocol.Transport) (*cmp.Config, error) {
+	reshared, err := runLSSReshare(config, plan.Threshold+1, plan.Parties, pl, transport)
+	if err != nil {
+		return nil, err
+	}
+	if reshared.ECDSA == nil {
+		return nil, nil
+	}
 
-	select {
-	case <-done:
-		result, err := h.Result()
-		if err != nil {
-			return nil, err
-		}
-		return result.(*ecdsa.Signature), nil
-	case <-time.After(30 * time.Second):
-		return nil, fmt.Errorf("signing timeout")
+	h, err := protocol.NewMultiHandler(cmp.Refresh(cmpFromLSSConfig(reshared, plan.Threshold), pl), nil)
+	if err != nil {
+		return nil, err
 	}
+	result, err := runHandler(h, transport, "refresh", roundTimeout)
+	if err != nil {
+		return nil, err
+	}
+	// the refresh completes the reshare, which started a new generation
+	refreshed := result.(*cmp.Config)
+	refreshed.Refreshes = 0
+	return refreshed, nil
 }
 
 // FROST Protocol implementations
 
-func runFROSTKeygen(group curve.Curve, selfID party.ID, partyIDs []party.ID, threshold int, pl *pool.Pool, network *test.Network) (*frost.Config, error) {
+func runFROSTKeygen(group curve.Curve, selfID party.ID, partyIDs []party.ID, threshold int, pl *pool.Pool, transport protocol.Transport) (*frost.Config, error) {
 	h, err := protocol.NewMultiHandler(frost.Keygen(group, selfID, partyIDs, threshold), nil)
 	if err != nil {
 		return nil, err
 	}
+	result, err := runHandler(h, transport, "keygen", roundTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return result.(*frost.Config), nil
+}
 
-	done := make(chan error)
-	go func() {
-		test.HandlerLoop(selfID, h, network)
-		done <- nil
-	}()
-
-	select {
-	case <-done:
-		result, err := h.Result()
-		if err != nil {
-			return nil, err
+// runFROSTSign signs message, which is a digest computed by messageDigest if prehashed, as chosen with --hash.
+func runFROSTSign(config *frost.Config, signers []party.ID, message []byte, prehashed bool, sessionID []byte, transport protocol.Transport) (*frost.Signature, error) {
+	start := frost.Sign(config, signers, message)
+	if prehashed {
+		if len(message) != 32 {
+			return nil, fmt.Errorf("a FROST digest must be 32 bytes, got %d bytes", len(message))
 		}
-		return result.(*frost.Config), nil
-	case <-time.After(30 * time.Second):
-		return nil, fmt.Errorf("keygen timeout")
+		start = frost.SignDigest(config, signers, [32]byte(message))
+	}
+	h, err := protocol.NewMultiHandler(start, sessionID)
+	if err != nil {
+		return nil, err
 	}
+
+	result, err := runHandler(h, transport, "signing", roundTimeout)
+	if err != nil {
+		return nil, err
+	}
+	signature := result.(frost.Signature)
+	return &signature, nil
 }
 
-func runFROSTSign(config *frost.Config, signers []party.ID, message []byte, pl *pool.Pool, network *test.Network) (*frost.Signature, error) {
-	h, err := protocol.NewMultiHandler(frost.Sign(config, signers, message), nil)
+// runFROSTTaprootSign signs a BIP-341 key path spend for the output key of config's public key and merkleRoot.
+func runFROSTTaprootSign(config *frost.Config, signers []party.ID, message, merkleRoot, sessionID []byte, transport protocol.Transport) (taproot.Signature, error) {
+	h, err := protocol.NewMultiHandler(frost.SignTaprootKeySpend(config, signers, message, merkleRoot), sessionID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := runHandler(h, transport, "signing", roundTimeout)
 	if err != nil {
 		return nil, err
 	}
+	return result.(taproot.Signature), nil
+}
 
-	done := make(chan error)
-	go func() {
-		test.HandlerLoop(config.ID, h, network)
-		done <- nil
-	}()
+// runFROSTReshare reshares a FROST key to the parties and threshold of plan with lss.Reshare,
+// from the LSS view of our config, see frostLSSConfig. A party leaving the group gets a nil config.
+func runFROSTReshare(config *lss.Config, plan *resharePlan, pl *pool.Pool, transport protocol.Transport) (*frost.Config, error) {
+	reshared, err := runLSSReshare(config, plan.Threshold+1, plan.Parties, pl, transport)
+	if err != nil {
+		return nil, err
+	}
+	if reshared.ECDSA == nil {
+		return nil, nil
+	}
+	return frostFromLSSConfig(reshared, plan.Threshold)
+}
 
-	select {
-	case <-done:
-		result, err := h.Result()
-		if err != nil {
-			return nil, err
+// Message hashing
+
+// Message hashes of sign and verify
+const (
+	// hashSHA256 is SHA-256, the default for ECDSA
+	hashSHA256 = "sha256"
+	// hashKeccak256 is the original Keccak-256 used by Ethereum, not SHA3-256
+	hashKeccak256 = "keccak256"
+	// hashSHA256d is SHA-256 applied twice, as in Bitcoin
+	hashSHA256d = "sha256d"
+	// hashNone signs the input as is, which must then be a 32 byte digest
+	hashNone = "none"
+)
+
+// messageDigest returns what protocol signs for message, hashed with hashName.
+//
+// An empty hashName selects the default of protocol: ECDSA signs the SHA-256 hash of message,
+// and FROST signs message itself, since Schnorr signatures hash it with the nonce anyway.
+func messageDigest(message []byte, hashName, protocol string) ([]byte, error) {
+	if hashName == "" {
+		if protocol == "frost" {
+			return message, nil
 		}
-		return result.(*frost.Signature), nil
-	case <-time.After(30 * time.Second):
-		return nil, fmt.Errorf("signing timeout")
+		hashName = hashSHA256
+	}
+	switch hashName {
+	case hashSHA256:
+		digest := sha256.Sum256(message)
+		return digest[:], nil
+	case hashKeccak256:
+		h := sha3.NewLegacyKeccak256()
+		_, _ = h.Write(message)
+		return h.Sum(nil), nil
+	case hashSHA256d:
+		first := sha256.Sum256(message)
+		digest := sha256.Sum256(first[:])
+		return digest[:], nil
+	case hashNone:
+		if len(message) != 32 {
+			return nil, fmt.Errorf("with --hash none the message must be a 32 byte digest, got %d bytes", len(message))
+		}
+		return message, nil
+	default:
+		return nil, fmt.Errorf("unknown hash %q, expected one of %s, %s, %s, %s", hashName, hashSHA256, hashKeccak256, hashSHA256d, hashNone)
 	}
 }
 
 // Verification functions
 
-func verifyECDSA(sigData, pkData, message []byte) (bool, error) {
-	var sig ecdsa.Signature
+// verifyECDSA verifies an ECDSA signature of digest.
+// The curve of the public key is detected, preferring preferred if the key is valid on several curves.
+// With requireLowS, a signature whose s is in the upper half of the order is rejected,
+// as Bitcoin and Ethereum do.
+func verifyECDSA(sigData, pkData, digest []byte, preferred curve.Curve, requireLowS bool) (bool, error) {
+	// Parse public key (hex encoded SEC 1 point)
+	pkBytes, err := hex.DecodeString(strings.TrimSpace(string(pkData)))
+	if err != nil {
+		return false, fmt.Errorf("failed to decode public key: %w", err)
+	}
+	publicKey, err := decodePublicKey(pkBytes, preferred)
+	if err != nil {
+		return false, err
+	}
+
+	sig := ecdsa.EmptySignature(publicKey.Curve())
 	if err := json.Unmarshal(sigData, &sig); err != nil {
 		return false, fmt.Errorf("failed to unmarshal signature: %w", err)
 	}
 
-	// Parse public key (assuming hex encoded X coordinate)
-	pkHex := string(pkData)
-	pkBytes, err := hex.DecodeString(pkHex)
+	if requireLowS && !sig.IsLowS() {
+		return false, errors.New("signature has a high s value, which is malleable")
+	}
+
+	return sig.Verify(publicKey, digest), nil
+}
+
+// ecdsaCurves are the curves an ECDSA public key is looked up on, when its curve isn't known.
+var ecdsaCurves = []curve.Curve{curve.Secp256k1{}, curve.P256{}}
+
+// decodePublicKey decodes a SEC 1 encoded ECDSA public key, detecting its curve.
+//
+// The curve of an uncompressed key is the one it lies on.
+// A compressed key only holds an x coordinate, which is valid on several curves about
+// a quarter of the time; in that case the preferred curve is used.
+func decodePublicKey(data []byte, preferred curve.Curve) (curve.Point, error) {
+	var matches []curve.Point
+	for _, group := range ecdsaCurves {
+		if p, err := decodePoint(group, data); err == nil {
+			matches = append(matches, p)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, errors.New("public key is not a point on any supported curve")
+	case 1:
+		return matches[0], nil
+	}
+	for _, p := range matches {
+		if p.Curve().Name() == preferred.Name() {
+			return p, nil
+		}
+	}
+	return matches[0], nil
+}
+
+// decodePoint decodes a compressed or uncompressed SEC 1 encoding of a point on group.
+func decodePoint(group curve.Curve, data []byte) (curve.Point, error) {
+	if len(data) == 65 && data[0] == 4 {
+		switch group.(type) {
+		case curve.Secp256k1:
+			pk, err := secp256k1.ParsePubKey(data)
+			if err != nil {
+				return nil, err
+			}
+			data = pk.SerializeCompressed()
+		case curve.P256:
+			point, err := nistec.NewP256Point().SetBytes(data)
+			if err != nil {
+				return nil, errors.New("point not on P-256")
+			}
+			data = point.BytesCompressed()
+		}
+	}
+	p := group.NewPoint()
+	if err := p.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	if p.IsIdentity() {
+		return nil, errors.New("public key is the identity")
+	}
+	return p, nil
+}
+
+// verifySchnorr verifies a FROST signature of message on group,
+// which is detected from the length of the public key if it is nil.
+//
+// On secp256k1, 64 byte signatures are BIP-340 signatures from taproot signing, which verify against
+// either the 32 byte x-only public key, as in taproot outputs, or a compressed SEC 1 point.
+func verifySchnorr(sigData, pkData, message []byte, group curve.Curve) (bool, error) {
+	pkBytes, err := hex.DecodeString(strings.TrimSpace(string(pkData)))
 	if err != nil {
 		return false, fmt.Errorf("failed to decode public key: %w", err)
 	}
 
-	// Reconstruct public key point
-	group := curve.Secp256k1{}
-	publicKey := group.NewPoint()
-	if err := publicKey.UnmarshalBinary(pkBytes); err != nil {
-		return false, fmt.Errorf("failed to unmarshal public key: %w", err)
+	// Ed25519 keys are 32 bytes, whereas secp256k1 keys are compressed SEC 1 points
+	if group == nil {
+		group = curve.Secp256k1{}
+		if len(pkBytes) == ed25519.PublicKeySize {
+			group = curve.Ed25519{}
+		}
 	}
 
-	// Hash message and verify
-	hash := sha256.Sum256(message)
-	return sig.Verify(publicKey, hash[:]), nil
-}
+	if _, ok := group.(curve.Secp256k1); ok {
+		if sig, ok := decodeTaprootSignature(sigData); ok {
+			return verifyTaproot(sig, pkBytes, message)
+		}
+		if len(pkBytes) == taproot.PublicKeyLength {
+			return false, errors.New("x-only public keys only verify taproot signatures")
+		}
+	}
 
-func verifySchnorr(sigData, pkData, message []byte) (bool, error) {
-	var sig frost.Signature
+	sig := frost.EmptySignature(group)
 	if err := json.Unmarshal(sigData, &sig); err != nil {
 		return false, fmt.Errorf("failed to unmarshal signature: %w", err)
 	}
 
-	// Parse public key
-	pkHex := string(pkData)
-	pkBytes, err := hex.DecodeString(pkHex)
-	if err != nil {
-		return false, fmt.Errorf("failed to decode public key: %w", err)
+	if _, ok := group.(curve.Ed25519); ok {
+		if len(pkBytes) != ed25519.PublicKeySize {
+			return false, fmt.Errorf("ed25519 public key must be %d bytes, got %d", ed25519.PublicKeySize, len(pkBytes))
+		}
+		// The signature is a plain RFC 8032 signature, with its SHA-512 challenge
+		sigBytes, err := sig.MarshalBinary()
+		if err != nil {
+			return false, err
+		}
+		return ed25519.Verify(ed25519.PublicKey(pkBytes), message, sigBytes), nil
 	}
 
-	// Reconstruct public key point
-	group := curve.Secp256k1{}
-	publicKey := group.NewPoint()
-	if err := publicKey.UnmarshalBinary(pkBytes); err != nil {
+	publicKey, err := decodePoint(group, pkBytes)
+	if err != nil {
 		return false, fmt.Errorf("failed to unmarshal public key: %w", err)
 	}
-
 	return sig.Verify(publicKey, message), nil
 }
 
+// decodeTaprootSignature returns the BIP-340 signature in sigData, which is hex encoded in a JSON string
+// like FROST signatures, and reports whether sigData holds one.
+func decodeTaprootSignature(sigData []byte) (taproot.Signature, bool) {
+	var encoded string
+	if err := json.Unmarshal(sigData, &encoded); err != nil {
+		return nil, false
+	}
+	sig, err := hex.DecodeString(encoded)
+	if err != nil || len(sig) != taproot.SignatureLen {
+		return nil, false
+	}
+	return sig, true
+}
+
+// verifyTaproot verifies a BIP-340 signature of message by pkBytes,
+// which is either a 32 byte x-only key or a compressed secp256k1 point.
+func verifyTaproot(sig taproot.Signature, pkBytes, message []byte) (bool, error) {
+	if len(pkBytes) != taproot.PublicKeyLength {
+		publicKey, err := decodePoint(curve.Secp256k1{}, pkBytes)
+		if err != nil {
+			return false, fmt.Errorf("failed to unmarshal public key: %w", err)
+		}
+		pkBytes, _ = taproot.XOnlyFromPoint(publicKey)
+	}
+	return taproot.PublicKey(pkBytes).Verify(sig, message), nil
+}
+
 // Export functions
 
 func exportLSSConfig(config *lss.Config, format string) ([]byte, error) {
 	switch format {
 	case "json":
 		return json.MarshalIndent(config, "", "  ")
-	case "pem":
-		// Export as PEM format
-		return exportToPEM("LSS PRIVATE KEY", config)
-	case "der":
-		// Export as DER format
-		return exportToDER(config)
+	case "cbor":
+		return config.MarshalBinary()
+	case "pem", "der":
+		share, err := lssShare(config)
+		if err != nil {
+			return nil, err
+		}
+		return encodeShare(share, format)
+	case "jwk":
+		public, err := config.PublicKey()
+		if err != nil {
+			return nil, err
+		}
+		return exportToJWK(public, jwkShare(config.ECDSA))
+	case "group":
+		return exportGroup("lss", config)
 	default:
 		return nil, fmt.Errorf("unsupported export format: %s", format)
 	}
@@ -299,10 +523,18 @@ func exportCMPConfig(config *cmp.Config, format string) ([]byte, error) {
 	switch format {
 	case "json":
 		return json.MarshalIndent(config, "", "  ")
-	case "pem":
-		return exportToPEM("CMP PRIVATE KEY", config)
-	case "der":
-		return exportToDER(config)
+	case "cbor":
+		return config.MarshalBinary()
+	case "pem", "der":
+		share, err := cmpShare(config)
+		if err != nil {
+			return nil, err
+		}
+		return encodeShare(share, format)
+	case "jwk":
+		return exportToJWK(config.PublicPoint(), jwkShare(config.ECDSA))
+	case "group":
+		return exportGroup("cmp", cmpLSSConfig(config))
 	default:
 		return nil, fmt.Errorf("unsupported export format: %s", format)
 	}
@@ -312,127 +544,171 @@ func exportFROSTConfig(config *frost.Config, format string) ([]byte, error) {
 	switch format {
 	case "json":
 		return json.MarshalIndent(config, "", "  ")
-	case "pem":
-		return exportToPEM("FROST PRIVATE KEY", config)
-	case "der":
-		return exportToDER(config)
+	case "cbor":
+		return config.MarshalBinary()
+	case "pem", "der":
+		share, err := frostShare(config)
+		if err != nil {
+			return nil, err
+		}
+		return encodeShare(share, format)
+	case "jwk":
+		return exportToJWK(config.PublicKey, jwkShare(config.PrivateShare))
+	case "group":
+		return exportGroup("frost", frostLSSConfig(config))
 	default:
 		return nil, fmt.Errorf("unsupported export format: %s", format)
 	}
 }
 
+// exportGroup returns the group file of the LSS view of a config of protocol, with which new parties
+// join a reshare, see reshareGroup.
+func exportGroup(protocol string, config *lss.Config) ([]byte, error) {
+	g, err := newReshareGroup(protocol, config)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(g, "", "  ")
+}
+
+// jwkShare returns the secret share to include in an exported JWK, which is nil unless --include-private was passed.
+func jwkShare(share curve.Scalar) curve.Scalar {
+	if !includePrivate {
+		return nil
+	}
+	return share
+}
+
 // Import functions
 
-func importLSSConfig(data []byte, format string) (*lss.Config, error) {
-	var config lss.Config
+func importLSSConfig(data []byte, format string, group curve.Curve) (*lss.Config, error) {
+	config := lss.EmptyConfig(group)
 
 	switch format {
 	case "json":
-		if err := json.Unmarshal(data, &config); err != nil {
+		if err := json.Unmarshal(data, config); err != nil {
 			return nil, err
 		}
-	case "pem":
-		if err := importFromPEM(data, &config); err != nil {
+	case "cbor":
+		if err := config.UnmarshalBinary(data); err != nil {
 			return nil, err
 		}
-	case "der":
-		if err := importFromDER(data, &config); err != nil {
+	case "pem", "der":
+		share, err := decodeShare(data, format)
+		if err != nil {
 			return nil, err
 		}
+		imported, err := lssConfigFromShare(share)
+		if err != nil {
+			return nil, err
+		}
+		if imported.Group.Name() != group.Name() {
+			return nil, fmt.Errorf("share is on curve %s, not %s", imported.Group.Name(), group.Name())
+		}
+		return imported, nil
 	default:
 		return nil, fmt.Errorf("unsupported import format: %s", format)
 	}
 
-	return &config, nil
+	// exports of a single party may leave out the public shares of the others
+	if err := config.RecomputePublicShares(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// errStaleConfig is returned when importing a config from an older generation than the live key.
+var errStaleConfig = errors.New("config is stale")
+
+// checkLSSImportGeneration compares an imported config against the current config of the same key,
+// loaded from currentFile.
+func checkLSSImportGeneration(imported *lss.Config, currentFile string, allowStale bool) error {
+	data, err := os.ReadFile(currentFile)
+	if err != nil {
+		return fmt.Errorf("failed to read current config: %w", err)
+	}
+	current := lss.EmptyConfig(imported.Group)
+	if err := json.Unmarshal(data, current); err != nil {
+		return fmt.Errorf("failed to unmarshal current config: %w", err)
+	}
+	return checkGeneration(imported, current, allowStale)
+}
+
+// checkGeneration returns an error if imported doesn't belong to the same key as current,
+// or if it is from an older generation and allowStale is false.
+//
+// Shares from different generations lie on different polynomials,
+// so a stale share can't be combined with the current signers.
+func checkGeneration(imported, current *lss.Config, allowStale bool) error {
+	importedKey, err := imported.PublicKey()
+	if err != nil {
+		return fmt.Errorf("imported config: %w", err)
+	}
+	currentKey, err := current.PublicKey()
+	if err != nil {
+		return fmt.Errorf("current config: %w", err)
+	}
+	if !importedKey.Equal(currentKey) {
+		return errors.New("imported config belongs to a different public key than the current config")
+	}
+
+	switch {
+	case imported.Generation > current.Generation:
+		return fmt.Errorf("imported config is at generation %d, ahead of the current generation %d", imported.Generation, current.Generation)
+	case imported.Generation < current.Generation && !allowStale:
+		return fmt.Errorf("%w: exported at generation %d, but the key is at generation %d; "+
+			"run recover-share or reshare to obtain a current share, or pass --allow-stale to import it anyway",
+			errStaleConfig, imported.Generation, current.Generation)
+	}
+	return nil
 }
 
-func importCMPConfig(data []byte, format string) (*cmp.Config, error) {
-	var config cmp.Config
+func importCMPConfig(data []byte, format string, group curve.Curve) (*cmp.Config, error) {
+	config := cmp.EmptyConfig(group)
 
 	switch format {
 	case "json":
-		if err := json.Unmarshal(data, &config); err != nil {
+		if err := json.Unmarshal(data, config); err != nil {
 			return nil, err
 		}
-	case "pem":
-		if err := importFromPEM(data, &config); err != nil {
+	case "cbor":
+		if err := config.UnmarshalBinary(data); err != nil {
 			return nil, err
 		}
-	case "der":
-		if err := importFromDER(data, &config); err != nil {
+	case "pem", "der":
+		share, err := decodeShare(data, format)
+		if err != nil {
 			return nil, err
 		}
+		return cmpConfigFromShare(share)
 	default:
 		return nil, fmt.Errorf("unsupported import format: %s", format)
 	}
 
-	return &config, nil
+	return config, nil
 }
 
-func importFROSTConfig(data []byte, format string) (*frost.Config, error) {
-	var config frost.Config
+func importFROSTConfig(data []byte, format string, group curve.Curve) (*frost.Config, error) {
+	config := frost.EmptyConfig(group)
 
 	switch format {
 	case "json":
-		if err := json.Unmarshal(data, &config); err != nil {
+		if err := json.Unmarshal(data, config); err != nil {
 			return nil, err
 		}
-	case "pem":
-		if err := importFromPEM(data, &config); err != nil {
+	case "cbor":
+		if err := config.UnmarshalBinary(data); err != nil {
 			return nil, err
 		}
-	case "der":
-		if err := importFromDER(data, &config); err != nil {
+	case "pem", "der":
+		share, err := decodeShare(data, format)
+		if err != nil {
 			return nil, err
 		}
+		return frostConfigFromShare(share)
 	default:
 		return nil, fmt.Errorf("unsupported import format: %s", format)
 	}
 
-	return &config, nil
-}
-
-// Helper functions for import/export
-
-func exportToPEM(keyType string, config interface{}) ([]byte, error) {
-	// This is a simplified implementation
-	// In production, you'd use proper PEM encoding
-	data, err := json.Marshal(config)
-	if err != nil {
-		return nil, err
-	}
-
-	pem := fmt.Sprintf("-----BEGIN %s-----\n", keyType)
-	pem += hex.EncodeToString(data)
-	pem += fmt.Sprintf("\n-----END %s-----\n", keyType)
-
-	return []byte(pem), nil
-}
-
-func exportToDER(config interface{}) ([]byte, error) {
-	// This is a simplified implementation
-	// In production, you'd use proper DER/ASN.1 encoding
-	return json.Marshal(config)
-}
-
-func importFromPEM(data []byte, config interface{}) error {
-	// This is a simplified implementation
-	// Extract hex data between BEGIN and END markers
-	str := string(data)
-	start := strings.Index(str, "-----") + 5
-	start = strings.Index(str[start:], "\n") + start + 1
-	end := strings.LastIndex(str, "-----")
-
-	hexData := strings.TrimSpace(str[start:end])
-	jsonData, err := hex.DecodeString(hexData)
-	if err != nil {
-		return err
-	}
-
-	return json.Unmarshal(jsonData, config)
-}
-
-func importFromDER(data []byte, config interface{}) error {
-	// This is a simplified implementation
-	return json.Unmarshal(data, config)
+	return config, nil
 }
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"filippo.io/nistec"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/luxfi/threshold/pkg/ecdsa"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/protocols/cmp"
+	"github.com/luxfi/threshold/protocols/frost"
+	"github.com/luxfi/threshold/protocols/lss"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ripemd160"
+	"golang.org/x/crypto/sha3"
+)
+
+// Public key encodings printed by pubkey
+const (
+	// pubkeyFormatAll prints every encoding that applies to the key
+	pubkeyFormatAll = "all"
+	// pubkeyFormatHex is the uncompressed SEC 1 point, or the RFC 8032 encoding on ed25519
+	pubkeyFormatHex = "hex"
+	// pubkeyFormatSEC1 is the 33 byte compressed SEC 1 point
+	pubkeyFormatSEC1 = "sec1"
+	// pubkeyFormatEthereum is the EIP-55 checksummed Ethereum address
+	pubkeyFormatEthereum = "ethereum"
+	// pubkeyFormatP2WPKH is the Bitcoin pay-to-witness-public-key-hash address, for ECDSA keys
+	pubkeyFormatP2WPKH = "p2wpkh"
+	// pubkeyFormatP2TR is the Bitcoin BIP-86 taproot address, for FROST keys
+	pubkeyFormatP2TR = "p2tr"
+)
+
+// pubkeyFormats lists the encodings of pubkey in the order they are printed.
+var pubkeyFormats = []string{pubkeyFormatHex, pubkeyFormatSEC1, pubkeyFormatEthereum, pubkeyFormatP2WPKH, pubkeyFormatP2TR}
+
+// bitcoinHRP is the human readable part of Bitcoin mainnet segwit addresses.
+const bitcoinHRP = "bc"
+
+// loadPublicKey returns the group public key of a JSON config of protocol.
+func loadPublicKey(data []byte, protocol string, group curve.Curve) (curve.Point, error) {
+	switch protocol {
+	case "lss":
+		config := lss.EmptyConfig(group)
+		if err := json.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal LSS config: %w", err)
+		}
+		return config.PublicKey()
+	case "cmp":
+		config := cmp.EmptyConfig(group)
+		if err := json.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal CMP config: %w", err)
+		}
+		return config.PublicPoint(), nil
+	case "frost":
+		config := frost.EmptyConfig(group)
+		if err := json.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal FROST config: %w", err)
+		}
+		return config.PublicKey, nil
+	default:
+		return nil, fmt.Errorf("unknown protocol: %s", protocol)
+	}
+}
+
+// pubkeyFormatsFor returns the encodings that apply to a key of protocol over group.
+//
+// Bitcoin addresses depend on the signature scheme: ECDSA keys (LSS, CMP) spend P2WPKH outputs,
+// and Schnorr keys (FROST) spend P2TR outputs.
+func pubkeyFormatsFor(group curve.Curve, protocol string) []string {
+	switch group.(type) {
+	case curve.Secp256k1:
+		if protocol == "frost" {
+			return []string{pubkeyFormatHex, pubkeyFormatSEC1, pubkeyFormatEthereum, pubkeyFormatP2TR}
+		}
+		return []string{pubkeyFormatHex, pubkeyFormatSEC1, pubkeyFormatEthereum, pubkeyFormatP2WPKH}
+	case curve.P256:
+		return []string{pubkeyFormatHex, pubkeyFormatSEC1}
+	default:
+		return []string{pubkeyFormatHex}
+	}
+}
+
+// encodePublicKey encodes publicKey, the group key of protocol, in format.
+func encodePublicKey(publicKey curve.Point, protocol, format string) (string, error) {
+	group := publicKey.Curve()
+	if !slices.Contains(pubkeyFormats, format) {
+		return "", fmt.Errorf("unknown public key format %q, expected %s or one of %s", format, pubkeyFormatAll, strings.Join(pubkeyFormats, ", "))
+	}
+	if !slices.Contains(pubkeyFormatsFor(group, protocol), format) {
+		return "", fmt.Errorf("format %s does not apply to %s keys on %s", format, protocol, group.Name())
+	}
+
+	compressed, err := publicKey.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	switch format {
+	case pubkeyFormatHex:
+		uncompressed, err := uncompressedSEC1(publicKey)
+		if err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(uncompressed), nil
+	case pubkeyFormatSEC1:
+		return hex.EncodeToString(compressed), nil
+	case pubkeyFormatEthereum:
+		address, err := ecdsa.EthereumAddress(publicKey)
+		if err != nil {
+			return "", err
+		}
+		return checksumAddress(address), nil
+	case pubkeyFormatP2WPKH:
+		sha := sha256.Sum256(compressed)
+		h := ripemd160.New()
+		_, _ = h.Write(sha[:])
+		return segwitAddress(bitcoinHRP, 0, h.Sum(nil))
+	default: // pubkeyFormatP2TR
+		outputKey, err := frost.TaprootOutputKey(publicKey, nil)
+		if err != nil {
+			return "", err
+		}
+		return segwitAddress(bitcoinHRP, 1, outputKey)
+	}
+}
+
+// uncompressedSEC1 returns the uncompressed SEC 1 encoding of p, or its only encoding on ed25519.
+func uncompressedSEC1(p curve.Point) ([]byte, error) {
+	compressed, err := p.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	switch p.Curve().(type) {
+	case curve.Secp256k1:
+		pk, err := secp256k1.ParsePubKey(compressed)
+		if err != nil {
+			return nil, err
+		}
+		return pk.SerializeUncompressed(), nil
+	case curve.P256:
+		point, err := nistec.NewP256Point().SetBytes(compressed)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s point", p.Curve().Name())
+		}
+		return point.Bytes(), nil
+	default:
+		return compressed, nil
+	}
+}
+
+// checksumAddress returns the EIP-55 mixed case encoding of an Ethereum address.
+func checksumAddress(address [20]byte) string {
+	lower := hex.EncodeToString(address[:])
+	h := sha3.NewLegacyKeccak256()
+	_, _ = h.Write([]byte(lower))
+	digest := h.Sum(nil)
+
+	out := []byte(lower)
+	for i, c := range out {
+		// a letter is upper case if the matching nibble of the hash is at least 8
+		nibble := digest[i/2] >> 4
+		if i%2 == 1 {
+			nibble = digest[i/2] & 0x0f
+		}
+		if c >= 'a' && nibble >= 8 {
+			out[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(out)
+}
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// bech32Polymod computes the BCH checksum of BIP-173 over values.
+func bech32Polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+// segwitAddress encodes a witness program as in BIP-173, using the bech32m checksum of BIP-350 for
+// witness versions above 0.
+func segwitAddress(hrp string, version byte, program []byte) (string, error) {
+	if version > 16 || len(program) < 2 || len(program) > 40 {
+		return "", fmt.Errorf("invalid witness program of version %d and length %d", version, len(program))
+	}
+
+	// regroup the program from 8 to 5 bit values
+	data := []byte{version}
+	var acc, bits uint
+	for _, b := range program {
+		acc = acc<<8 | uint(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			data = append(data, byte(acc>>bits)&31)
+		}
+	}
+	if bits > 0 {
+		data = append(data, byte(acc<<(5-bits))&31)
+	}
+
+	constant := uint32(1)
+	if version > 0 {
+		constant = 0x2bc830a3
+	}
+	values := make([]byte, 0, 2*len(hrp)+1+len(data)+6)
+	for _, c := range []byte(hrp) {
+		values = append(values, c>>5)
+	}
+	values = append(values, 0)
+	for _, c := range []byte(hrp) {
+		values = append(values, c&31)
+	}
+	values = append(values, data...)
+	values = append(values, make([]byte, 6)...)
+	checksum := bech32Polymod(values) ^ constant
+
+	var sb strings.Builder
+	sb.WriteString(hrp)
+	sb.WriteByte('1')
+	for _, d := range data {
+		sb.WriteByte(bech32Charset[d])
+	}
+	for i := 0; i < 6; i++ {
+		sb.WriteByte(bech32Charset[(checksum>>(5*(5-i)))&31])
+	}
+	return sb.String(), nil
+}
+
+func runPubkey(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+
+	data, err := os.ReadFile(inputFile)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	group, err := getCurve(curveType)
+	if err != nil {
+		return err
+	}
+	if err := checkCurveProtocol(group, protocolName); err != nil {
+		return err
+	}
+	publicKey, err := loadPublicKey(data, protocolName, group)
+	if err != nil {
+		return err
+	}
+
+	if format != pubkeyFormatAll {
+		encoded, err := encodePublicKey(publicKey, protocolName, format)
+		if err != nil {
+			return err
+		}
+		fmt.Println(encoded)
+		return nil
+	}
+	for _, f := range pubkeyFormatsFor(group, protocolName) {
+		encoded, err := encodePublicKey(publicKey, protocolName, f)
+		if err != nil {
+			return fmt.Errorf("%s: %w", f, err)
+		}
+		fmt.Printf("%-9s %s\n", f+":", encoded)
+	}
+	return nil
+}
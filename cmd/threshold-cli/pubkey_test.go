@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustPoint(t *testing.T, group curve.Curve, h string) curve.Point {
+	data, err := hex.DecodeString(h)
+	require.NoError(t, err)
+	p, err := decodePoint(group, data)
+	require.NoError(t, err)
+	return p
+}
+
+func TestEncodePublicKey(t *testing.T) {
+	// the public key of the secret key 1
+	G := mustPoint(t, curve.Secp256k1{}, "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798")
+
+	sec1, err := encodePublicKey(G, "lss", pubkeyFormatSEC1)
+	require.NoError(t, err)
+	compressed, err := hex.DecodeString(sec1)
+	require.NoError(t, err)
+	assert.Len(t, compressed, 33)
+
+	uncompressed, err := encodePublicKey(G, "lss", pubkeyFormatHex)
+	require.NoError(t, err)
+	assert.Equal(t, "04"+sec1[2:], uncompressed[:66])
+	assert.Len(t, uncompressed, 130)
+
+	address, err := encodePublicKey(G, "cmp", pubkeyFormatEthereum)
+	require.NoError(t, err)
+	assert.Equal(t, "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf", address)
+	raw, err := hex.DecodeString(address[2:])
+	require.NoError(t, err)
+	assert.Len(t, raw, 20)
+
+	// BIP-173
+	p2wpkh, err := encodePublicKey(G, "lss", pubkeyFormatP2WPKH)
+	require.NoError(t, err)
+	assert.Equal(t, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", p2wpkh)
+
+	// BIP-86, first receiving address of the test vector account
+	internal := mustPoint(t, curve.Secp256k1{}, "02cc8a4bc64d897bddc5fbc2f670f7a8ba0b386779106cf1223c6fc5d7cd6fc115")
+	p2tr, err := encodePublicKey(internal, "frost", pubkeyFormatP2TR)
+	require.NoError(t, err)
+	assert.Equal(t, "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr", p2tr)
+
+	_, err = encodePublicKey(G, "frost", pubkeyFormatP2WPKH)
+	assert.Error(t, err, "FROST keys sign Schnorr, so they have no P2WPKH address")
+	_, err = encodePublicKey(G, "lss", pubkeyFormatP2TR)
+	assert.Error(t, err, "ECDSA keys have no taproot address")
+	_, err = encodePublicKey(curve.P256{}.NewBasePoint(), "lss", pubkeyFormatEthereum)
+	assert.Error(t, err)
+	_, err = encodePublicKey(G, "lss", "base58")
+	assert.ErrorContains(t, err, "unknown public key format")
+}
+
+func TestLoadPublicKey(t *testing.T) {
+	c, _ := testLSSConfig(t, 3, 2)
+	data, err := json.Marshal(c)
+	require.NoError(t, err)
+
+	publicKey, err := loadPublicKey(data, "lss", c.Group)
+	require.NoError(t, err)
+	want, err := c.PublicKey()
+	require.NoError(t, err)
+	assert.True(t, want.Equal(publicKey))
+
+	for _, format := range pubkeyFormatsFor(c.Group, "lss") {
+		encoded, err := encodePublicKey(publicKey, "lss", format)
+		require.NoError(t, err, format)
+		assert.NotEmpty(t, strings.TrimSpace(encoded))
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/pkg/network"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/protocol"
+	grpctransport "github.com/luxfi/threshold/pkg/transport/grpc"
+	"github.com/spf13/cobra"
+)
+
+// dialTimeout bounds how long a party waits for the relay to accept its connection.
+const dialTimeout = 10 * time.Second
+
+// keygenPartyIDs returns the IDs party-1 ... party-n used by keygen.
+func keygenPartyIDs(n int) []party.ID {
+	partyIDs := make([]party.ID, n)
+	for i := 0; i < n; i++ {
+		partyIDs[i] = party.ID(fmt.Sprintf("party-%d", i+1))
+	}
+	return partyIDs
+}
+
+// resolvePartyIDs returns the parties of a run: the IDs given with --party-ids, verbatim and in their order,
+// or party-1 ... party-n without them. With both, n must be their number.
+// The IDs must be unique and non-empty, and self must be one of them unless it is empty.
+func resolvePartyIDs(ids []string, n int, self string) ([]party.ID, error) {
+	if len(ids) == 0 {
+		if n <= 0 {
+			return nil, errors.New("--parties or --party-ids is required")
+		}
+		ids = make([]string, n)
+		for i, id := range keygenPartyIDs(n) {
+			ids[i] = string(id)
+		}
+	} else if n != 0 && n != len(ids) {
+		return nil, fmt.Errorf("--parties is %d, but --party-ids lists %d parties", n, len(ids))
+	}
+
+	partyIDs := make([]party.ID, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for i, id := range ids {
+		if id == "" {
+			return nil, errors.New("--party-ids has an empty party ID")
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("party ID %s is listed twice", id)
+		}
+		seen[id] = true
+		partyIDs[i] = party.ID(id)
+	}
+	if self != "" && !seen[self] {
+		return nil, fmt.Errorf("party ID %s not in party list %s", self, strings.Join(ids, ","))
+	}
+	return partyIDs, nil
+}
+
+// dialRelay connects to the relay at addr as the party id.
+func dialRelay(addr string, id party.ID) (*network.Client, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+	client, err := network.Dial(ctx, addr, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to relay %s: %w", addr, err)
+	}
+	return client, nil
+}
+
+// serveTopology starts the gRPC transport of the party id, among the parties of the topology file at path,
+// which must be exactly partyIDs.
+func serveTopology(path string, id party.ID, partyIDs []party.ID) (*grpctransport.Transport, error) {
+	topology, err := grpctransport.LoadTopology(path)
+	if err != nil {
+		return nil, err
+	}
+	if ids := topology.PartyIDs(); len(ids) != len(partyIDs) || !ids.Contains(partyIDs...) {
+		return nil, fmt.Errorf("topology %s must list the parties %v", path, partyIDs)
+	}
+	transport, err := grpctransport.New(id, topology, nil)
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", topology.Parties[id])
+	if err != nil {
+		transport.Close()
+		return nil, fmt.Errorf("failed to listen on %s: %w", topology.Parties[id], err)
+	}
+	go func() { _ = transport.Serve(listener) }()
+	return transport, nil
+}
+
+// openTransport returns the transport of the party self among partyIDs, as chosen with --topology and --network:
+// the gRPC transport of the topology, which must list exactly partyIDs, a connection to the relay,
+// or without either, an in-process network for a local simulation. It tells which on w.
+// The transport can carry several protocols one after the other, and is closed by the returned function.
+func openTransport(w io.Writer, self party.ID, partyIDs []party.ID) (protocol.Transport, func(), error) {
+	switch {
+	case topologyFile != "" && networkAddr != "":
+		return nil, nil, errors.New("--topology and --network are mutually exclusive")
+	case topologyFile != "":
+		// Distributed mode: every party serves gRPC and connects to the others
+		t, err := serveTopology(topologyFile, self, partyIDs)
+		if err != nil {
+			return nil, nil, err
+		}
+		fmt.Fprintf(w, "Serving gRPC transport as %s\n", self)
+		return t, t.Close, nil
+	case networkAddr == "":
+		// Local simulation mode
+		fmt.Fprintln(w, "Running in local simulation mode...")
+		return test.NewNetwork(partyIDs).Transport(self), func() {}, nil
+	default:
+		// Distributed mode: every party connects to the same relay
+		client, err := dialRelay(networkAddr, self)
+		if err != nil {
+			return nil, nil, err
+		}
+		fmt.Fprintf(w, "Connected to relay %s as %s\n", networkAddr, self)
+		return client.Transport(partyIDs), func() { _ = client.Close() }, nil
+	}
+}
+
+func runRelay(cmd *cobra.Command, args []string) error {
+	listen, _ := cmd.Flags().GetString("listen")
+	ids, err := resolvePartyIDs(partyIDs, parties, "")
+	if err != nil {
+		return err
+	}
+	if len(ids) < 2 {
+		return fmt.Errorf("the relay needs at least 2 parties")
+	}
+
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", listen, err)
+	}
+	relay := network.NewRelay(listener, ids)
+	defer relay.Close()
+
+	fmt.Printf("Relay listening on %s for %d parties\n", relay.Addr(), len(ids))
+	return relay.Serve()
+}
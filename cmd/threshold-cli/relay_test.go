@@ -0,0 +1,135 @@
+package main
+
+import (
+	"crypto/sha256"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/luxfi/threshold/pkg/ecdsa"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/network"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/pool"
+	"github.com/luxfi/threshold/pkg/protocol"
+	"github.com/luxfi/threshold/protocols/lss"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDistributedKeygen runs a 3 party keygen where every party reaches the others
+// through a relay on loopback, as in distributed mode.
+func TestDistributedKeygen(t *testing.T) {
+	partyIDs := keygenPartyIDs(3)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	relay := network.NewRelay(listener, partyIDs)
+	go func() { _ = relay.Serve() }()
+	defer relay.Close()
+
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	configs := make(chan *lss.Config, len(partyIDs))
+	var wg sync.WaitGroup
+	for _, id := range partyIDs {
+		wg.Add(1)
+		go func(id party.ID) {
+			defer wg.Done()
+			client, err := dialRelay(relay.Addr().String(), id)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer client.Close()
+			c, err := runLSSKeygen(curve.Secp256k1{}, id, partyIDs, 2, pl, client.Transport(partyIDs))
+			assert.NoError(t, err)
+			configs <- c
+		}(id)
+	}
+	wg.Wait()
+	close(configs)
+
+	require.Len(t, configs, len(partyIDs))
+	var publicKey curve.Point
+	for c := range configs {
+		require.NotNil(t, c)
+		require.NoError(t, c.Validate())
+		pk, err := c.PublicKey()
+		require.NoError(t, err)
+		if publicKey == nil {
+			publicKey = pk
+		}
+		assert.True(t, publicKey.Equal(pk))
+	}
+
+	// a party outside the keygen is refused by the relay
+	client, err := dialRelay(relay.Addr().String(), "party-4")
+	require.NoError(t, err)
+	defer client.Close()
+	_, ok := <-client.Next("party-4")
+	assert.False(t, ok)
+}
+
+// TestDistributedSign signs with 2 of 3 parties, which reach each other through a relay
+// as openTransport connects them with --network.
+func TestDistributedSign(t *testing.T) {
+	partyIDs := keygenPartyIDs(3)
+	configs := lss.RunKeygen(t, curve.Secp256k1{}, partyIDs, 2)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	relay := network.NewRelay(listener, partyIDs)
+	go func() { _ = relay.Serve() }()
+	defer relay.Close()
+
+	networkAddr = relay.Addr().String()
+	defer func() { networkAddr = "" }()
+
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	signers := partyIDs[:2]
+	digest := sha256.Sum256([]byte("distributed"))
+	sessionID, err := protocol.NewSessionID()
+	require.NoError(t, err)
+	signatures := make(chan *ecdsa.Signature, len(signers))
+	var wg sync.WaitGroup
+	for _, id := range signers {
+		wg.Add(1)
+		go func(id party.ID) {
+			defer wg.Done()
+			transport, closeTransport, err := openTransport(io.Discard, id, signers)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer closeTransport()
+			sig, err := runLSSSign(configs[id], signers, digest[:], pl, sessionID, transport)
+			assert.NoError(t, err)
+			signatures <- sig
+		}(id)
+	}
+	wg.Wait()
+	close(signatures)
+
+	publicKey, err := configs[partyIDs[0]].PublicKey()
+	require.NoError(t, err)
+	require.Len(t, signatures, len(signers))
+	for sig := range signatures {
+		require.NotNil(t, sig)
+		assert.True(t, sig.Verify(publicKey, digest[:]))
+	}
+}
+
+func TestServeTopologyParties(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "topology.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"parties": {"party-1": "127.0.0.1:0", "party-2": "127.0.0.1:0"}}`), 0644))
+
+	_, err := serveTopology(path, "party-1", keygenPartyIDs(3))
+	assert.ErrorContains(t, err, "must list the parties")
+
+	transport, err := serveTopology(path, "party-1", keygenPartyIDs(2))
+	require.NoError(t, err)
+	transport.Close()
+}
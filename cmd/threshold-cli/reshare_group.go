@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/protocols/cmp"
+	cmpconfig "github.com/luxfi/threshold/protocols/cmp/config"
+	"github.com/luxfi/threshold/protocols/frost"
+	"github.com/luxfi/threshold/protocols/lss"
+	lssconfig "github.com/luxfi/threshold/protocols/lss/config"
+)
+
+// CMP and FROST keys are reshared with lss.Reshare, on the LSS view of their configs: the same shares,
+// with the LSS threshold counting the threshold + 1 shares needed to sign.
+
+// cmpLSSConfig returns the LSS view of a CMP config. It shares the secret of c.
+func cmpLSSConfig(c *cmp.Config) *lss.Config {
+	public := make(map[party.ID]*lssconfig.Public, len(c.Public))
+	for id, p := range c.Public {
+		public[id] = &lssconfig.Public{ECDSA: p.ECDSA}
+	}
+	return &lss.Config{
+		ID:         c.ID,
+		Group:      c.Group,
+		Threshold:  c.Threshold + 1,
+		Generation: c.Generation,
+		ECDSA:      c.ECDSA,
+		Public:     public,
+		ChainKey:   append([]byte(nil), c.ChainKey...),
+		RID:        append([]byte(nil), c.RID...),
+	}
+}
+
+// frostLSSConfig returns the LSS view of a FROST config. It shares the secret of c.
+func frostLSSConfig(c *frost.Config) *lss.Config {
+	public := make(map[party.ID]*lssconfig.Public, len(c.VerificationShares.Points))
+	for id, point := range c.VerificationShares.Points {
+		public[id] = &lssconfig.Public{ECDSA: point}
+	}
+	return &lss.Config{
+		ID:         c.ID,
+		Group:      c.Curve(),
+		Threshold:  c.Threshold + 1,
+		Generation: c.Generation,
+		ECDSA:      c.PrivateShare,
+		Public:     public,
+		ChainKey:   frostChainKey(c.ChainKey, c.PublicKey),
+		RID:        publicKeyTag("frost rid", c.PublicKey),
+	}
+}
+
+// frostChainKey returns chainKey, or if it is empty, as in the configs of FROST keygen, the chain key the LSS view
+// of a config of publicKey uses in its place, since lss.Reshare keeps a chain key. frostFromLSSConfig drops it again.
+func frostChainKey(chainKey []byte, publicKey curve.Point) []byte {
+	if len(chainKey) > 0 {
+		return append([]byte(nil), chainKey...)
+	}
+	return publicKeyTag("frost chain key", publicKey)
+}
+
+// publicKeyTag derives a value labelled label from publicKey, on which all the parties of the key agree.
+func publicKeyTag(label string, publicKey curve.Point) []byte {
+	data, err := publicKey.MarshalBinary()
+	if err != nil {
+		return nil
+	}
+	h := sha256.Sum256(append([]byte("threshold-cli "+label), data...))
+	return h[:]
+}
+
+// cmpFromLSSConfig returns the CMP config of threshold with the shares of c. It has no Paillier,
+// Pedersen or ElGamal keys, which cmp.Refresh generates.
+func cmpFromLSSConfig(c *lss.Config, threshold int) *cmp.Config {
+	public := make(map[party.ID]*cmpconfig.Public, len(c.Public))
+	for id, p := range c.Public {
+		public[id] = &cmpconfig.Public{ECDSA: p.ECDSA}
+	}
+	return &cmp.Config{
+		Group:      c.Group,
+		ID:         c.ID,
+		Threshold:  threshold,
+		ECDSA:      c.ECDSA,
+		RID:        append([]byte(nil), c.RID...),
+		ChainKey:   append([]byte(nil), c.ChainKey...),
+		Generation: c.Generation,
+		Public:     public,
+	}
+}
+
+// frostFromLSSConfig returns the FROST config of threshold with the shares of c.
+func frostFromLSSConfig(c *lss.Config, threshold int) (*frost.Config, error) {
+	publicKey, err := c.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	shares := make(map[party.ID]curve.Point, len(c.Public))
+	for id, p := range c.Public {
+		shares[id] = p.ECDSA
+	}
+	var chainKey []byte
+	if !bytes.Equal(c.ChainKey, frostChainKey(nil, publicKey)) {
+		chainKey = append(chainKey, c.ChainKey...)
+	}
+	return &frost.Config{
+		ID:                 c.ID,
+		Threshold:          threshold,
+		PrivateShare:       c.ECDSA,
+		PublicKey:          publicKey,
+		ChainKey:           chainKey,
+		VerificationShares: party.NewPointMap(shares),
+		Generation:         c.Generation,
+	}, nil
+}
+
+// reshareGroup is the public data of a key, from which a new party joins the group in a reshare.
+// It holds no share, so that any member can hand it to the parties being added.
+// It is written by export --format group, and read by reshare --join.
+type reshareGroup struct {
+	// Protocol is the protocol of the key: lss, cmp or frost
+	Protocol string `json:"protocol"`
+	// Threshold is the threshold of the key, as set in keygen for Protocol
+	Threshold int `json:"threshold"`
+	// Generation is the generation of the key
+	Generation uint64 `json:"generation"`
+	// Public maps every party to its hex encoded public share
+	Public map[party.ID]string `json:"public"`
+	// ChainKey and RID are those of the configs, if the protocol has them
+	ChainKey []byte `json:"chain_key,omitempty"`
+	RID      []byte `json:"rid,omitempty"`
+}
+
+// newReshareGroup returns the public data of the LSS view c of a config of protocol.
+func newReshareGroup(protocol string, c *lss.Config) (*reshareGroup, error) {
+	if len(c.Weights.Copy()) > 0 {
+		return nil, errors.New("a weighted key has no group file: its parties join with lss.ReshareWeighted")
+	}
+	g := &reshareGroup{
+		Protocol:   protocol,
+		Threshold:  c.Threshold,
+		Generation: c.Generation,
+		Public:     make(map[party.ID]string, len(c.Public)),
+		ChainKey:   c.ChainKey,
+		RID:        c.RID,
+	}
+	if protocol != "lss" {
+		g.Threshold--
+	}
+	for id, p := range c.Public {
+		data, err := p.ECDSA.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("public share of %s: %w", id, err)
+		}
+		g.Public[id] = hex.EncodeToString(data)
+	}
+	return g, nil
+}
+
+// joinConfig returns the LSS view of the config with which id joins the group over group, without a share.
+// The public shares are checked to lie on a polynomial of the degree the threshold sets.
+func (g *reshareGroup) joinConfig(group curve.Curve, id party.ID) (*lss.Config, error) {
+	c := lss.EmptyConfig(group)
+	c.ID = id
+	c.Threshold = g.Threshold
+	if g.Protocol != "lss" {
+		c.Threshold++
+	}
+	c.Generation = g.Generation
+	c.ChainKey = g.ChainKey
+	c.RID = g.RID
+	if _, ok := g.Public[id]; ok {
+		return nil, fmt.Errorf("%s is already a party of the group", id)
+	}
+	for j, data := range g.Public {
+		b, err := hex.DecodeString(data)
+		if err != nil {
+			return nil, fmt.Errorf("public share of %s: %w", j, err)
+		}
+		point := group.NewPoint()
+		if err := point.UnmarshalBinary(b); err != nil {
+			return nil, fmt.Errorf("public share of %s: %w", j, err)
+		}
+		c.Public[j] = &lssconfig.Public{ECDSA: point}
+	}
+	if len(c.Public) < c.Threshold {
+		return nil, fmt.Errorf("%d public shares can't hold a key of threshold %d", len(c.Public), g.Threshold)
+	}
+	for j := range c.Public {
+		if err := c.VerifyPublicShare(j); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/protocols/cmp"
+	"github.com/luxfi/threshold/protocols/frost"
+	"github.com/luxfi/threshold/protocols/lss"
+)
+
+// errReshareNoOp is returned when a spec describes the state the config is already in.
+var errReshareNoOp = errors.New("reshare spec matches the current config: nothing to do")
+
+// reshareSpec declares the desired final state of a resharing.
+//
+// Instead of listing parties to add and remove, the spec gives the complete party list and
+// threshold after resharing. PublicKey and Generation pin the spec to a specific key, so that
+// it cannot be applied to the wrong config, or to one that has already been reshared.
+type reshareSpec struct {
+	// Parties is the full list of parties after resharing
+	Parties []string `json:"parties"`
+	// Threshold is the threshold after resharing
+	Threshold int `json:"threshold"`
+	// PublicKey is the hex encoded, compressed public key the spec applies to (optional)
+	PublicKey string `json:"public_key,omitempty"`
+	// Generation is the config generation the spec applies to (optional)
+	Generation *uint64 `json:"generation,omitempty"`
+}
+
+// resharePlan is the difference between a config and a reshareSpec.
+type resharePlan struct {
+	Parties   party.IDSlice
+	Threshold int
+	Add       []party.ID
+	Remove    []party.ID
+}
+
+func loadReshareSpec(path string) (*reshareSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reshare spec: %w", err)
+	}
+	var spec reshareSpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal reshare spec: %w", err)
+	}
+	return &spec, nil
+}
+
+// membershipSpec returns the spec of --add-parties and --remove-parties applied to key, keeping its threshold.
+// Parties added must not be in key already, and parties removed must be, as checked by lss.MembershipChange.
+func membershipSpec(key *reshareKey, add, remove []string) (*reshareSpec, error) {
+	toIDs := func(ids []string) []party.ID {
+		partyIDs := make([]party.ID, len(ids))
+		for i, id := range ids {
+			partyIDs[i] = party.ID(id)
+		}
+		return partyIDs
+	}
+	parties, err := lss.MembershipChange(key.Parties, toIDs(add), toIDs(remove))
+	if err != nil {
+		return nil, fmt.Errorf("reshare: %w", err)
+	}
+	spec := &reshareSpec{Threshold: key.Threshold}
+	for _, id := range parties {
+		spec.Parties = append(spec.Parties, string(id))
+	}
+	return spec, nil
+}
+
+// reshareKey is the state of a key that a reshareSpec is checked against.
+type reshareKey struct {
+	PublicKey  curve.Point
+	Generation uint64
+	Parties    party.IDSlice
+	Threshold  int
+	// MinThreshold is the smallest valid threshold: 1 for LSS, where the threshold is the number of signers,
+	// and 0 for CMP and FROST, where threshold + 1 parties sign.
+	MinThreshold int
+}
+
+func lssReshareKey(config *lss.Config) (*reshareKey, error) {
+	publicKey, err := config.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	return &reshareKey{
+		PublicKey:    publicKey,
+		Generation:   config.Generation,
+		Parties:      party.NewIDSlice(config.PartyIDs()),
+		Threshold:    config.Threshold,
+		MinThreshold: 1,
+	}, nil
+}
+
+func cmpReshareKey(config *cmp.Config) *reshareKey {
+	return &reshareKey{
+		PublicKey:  config.PublicPoint(),
+		Generation: config.Generation,
+		Parties:    config.PartyIDs(),
+		Threshold:  config.Threshold,
+	}
+}
+
+func frostReshareKey(config *frost.Config) *reshareKey {
+	ids := make([]party.ID, 0, len(config.VerificationShares.Points))
+	for id := range config.VerificationShares.Points {
+		ids = append(ids, id)
+	}
+	return &reshareKey{
+		PublicKey:  config.PublicKey,
+		Generation: config.Generation,
+		Parties:    party.NewIDSlice(ids),
+		Threshold:  config.Threshold,
+	}
+}
+
+// plan validates the spec against an LSS config and computes which parties must be added or removed.
+//
+// errReshareNoOp is returned if the spec wouldn't change anything.
+func (s *reshareSpec) plan(config *lss.Config) (*resharePlan, error) {
+	key, err := lssReshareKey(config)
+	if err != nil {
+		return nil, fmt.Errorf("reshare spec: %w", err)
+	}
+	return s.planKey(key)
+}
+
+// planKey validates the spec against key and computes which parties must be added or removed.
+//
+// errReshareNoOp is returned if the spec wouldn't change anything.
+func (s *reshareSpec) planKey(key *reshareKey) (*resharePlan, error) {
+	if len(s.Parties) == 0 {
+		return nil, errors.New("reshare spec: no parties")
+	}
+	// threshold + 1 - MinThreshold parties are needed to sign
+	if s.Threshold < key.MinThreshold || s.Threshold+1-key.MinThreshold > len(s.Parties) {
+		return nil, fmt.Errorf("reshare spec: invalid threshold %d for %d parties", s.Threshold, len(s.Parties))
+	}
+
+	if s.Generation != nil && *s.Generation != key.Generation {
+		return nil, fmt.Errorf("reshare spec: written for generation %d, but config is at generation %d", *s.Generation, key.Generation)
+	}
+	if s.PublicKey != "" {
+		pkBytes, err := key.PublicKey.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("reshare spec: %w", err)
+		}
+		if !strings.EqualFold(strings.TrimPrefix(s.PublicKey, "0x"), hex.EncodeToString(pkBytes)) {
+			return nil, errors.New("reshare spec: public key does not match the config")
+		}
+	}
+
+	ids := make([]party.ID, 0, len(s.Parties))
+	for _, p := range s.Parties {
+		if p == "" {
+			return nil, errors.New("reshare spec: empty party ID")
+		}
+		ids = append(ids, party.ID(p))
+	}
+	parties := party.NewIDSlice(ids)
+	if !parties.Valid() {
+		return nil, errors.New("reshare spec: duplicate party IDs")
+	}
+
+	p := &resharePlan{
+		Parties:   parties,
+		Threshold: s.Threshold,
+	}
+	for _, id := range parties {
+		if !key.Parties.Contains(id) {
+			p.Add = append(p.Add, id)
+		}
+	}
+	for _, id := range key.Parties {
+		if !parties.Contains(id) {
+			p.Remove = append(p.Remove, id)
+		}
+	}
+
+	if len(p.Add) == 0 && len(p.Remove) == 0 && p.Threshold == key.Threshold {
+		return nil, errReshareNoOp
+	}
+	return p, nil
+}
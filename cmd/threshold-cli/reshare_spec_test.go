@@ -0,0 +1,120 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/math/polynomial"
+	"github.com/luxfi/threshold/pkg/math/sample"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/protocols/lss"
+	"github.com/luxfi/threshold/protocols/lss/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testLSSConfig returns the config of the first of n parties sharing a random key with the given threshold.
+func testLSSConfig(t *testing.T, n, threshold int) (*lss.Config, string) {
+	group := curve.Secp256k1{}
+	secret := sample.Scalar(rand.Reader, group)
+	f := polynomial.NewPolynomial(group, threshold-1, secret)
+
+	partyIDs := test.PartyIDs(n)
+	public := make(map[party.ID]*config.Public, n)
+	for _, id := range partyIDs {
+		public[id] = &config.Public{ECDSA: f.Evaluate(id.Scalar(group)).ActOnBase()}
+	}
+	c := &lss.Config{
+		ID:         partyIDs[0],
+		Group:      group,
+		Threshold:  threshold,
+		Generation: 3,
+		ECDSA:      f.Evaluate(partyIDs[0].Scalar(group)),
+		Public:     public,
+	}
+
+	pkBytes, err := secret.ActOnBase().MarshalBinary()
+	require.NoError(t, err)
+	return c, hex.EncodeToString(pkBytes)
+}
+
+func TestReshareSpecPlan(t *testing.T) {
+	c, publicKey := testLSSConfig(t, 3, 2)
+	generation := c.Generation
+
+	spec := &reshareSpec{
+		Parties:    []string{"d", "b", "a", "c"},
+		Threshold:  3,
+		PublicKey:  publicKey,
+		Generation: &generation,
+	}
+	p, err := spec.plan(c)
+	require.NoError(t, err)
+	assert.Equal(t, party.IDSlice{"a", "b", "c", "d"}, p.Parties)
+	assert.Equal(t, []party.ID{"d"}, p.Add)
+	assert.Empty(t, p.Remove)
+	assert.Equal(t, 3, p.Threshold)
+
+	spec = &reshareSpec{Parties: []string{"a", "c", "e"}, Threshold: 2}
+	p, err = spec.plan(c)
+	require.NoError(t, err)
+	assert.Equal(t, []party.ID{"e"}, p.Add)
+	assert.Equal(t, []party.ID{"b"}, p.Remove)
+}
+
+func TestReshareSpecNoOp(t *testing.T) {
+	c, publicKey := testLSSConfig(t, 3, 2)
+
+	spec := &reshareSpec{Parties: []string{"c", "a", "b"}, Threshold: 2, PublicKey: publicKey}
+	_, err := spec.plan(c)
+	assert.ErrorIs(t, err, errReshareNoOp)
+
+	// changing only the threshold is not a no-op
+	spec.Threshold = 3
+	_, err = spec.plan(c)
+	assert.NoError(t, err)
+}
+
+func TestReshareSpecInvalid(t *testing.T) {
+	c, publicKey := testLSSConfig(t, 3, 2)
+	_, otherPublicKey := testLSSConfig(t, 3, 2)
+	staleGeneration := c.Generation - 1
+
+	tests := map[string]*reshareSpec{
+		"no parties":         {Threshold: 1},
+		"threshold too high": {Parties: []string{"a", "b"}, Threshold: 3},
+		"zero threshold":     {Parties: []string{"a", "b"}},
+		"duplicate party":    {Parties: []string{"a", "b", "a"}, Threshold: 2},
+		"empty party":        {Parties: []string{"a", ""}, Threshold: 1},
+		"wrong public key":   {Parties: []string{"a", "b"}, Threshold: 2, PublicKey: otherPublicKey},
+		"stale generation":   {Parties: []string{"a", "b"}, Threshold: 2, PublicKey: publicKey, Generation: &staleGeneration},
+	}
+	for name, spec := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := spec.plan(c)
+			assert.Error(t, err)
+			assert.NotErrorIs(t, err, errReshareNoOp)
+		})
+	}
+}
+
+func TestMembershipSpec(t *testing.T) {
+	c, _ := testLSSConfig(t, 3, 2)
+	key, err := lssReshareKey(c)
+	require.NoError(t, err)
+
+	spec, err := membershipSpec(key, []string{"d"}, []string{"b"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "c", "d"}, spec.Parties)
+	assert.Equal(t, 2, spec.Threshold)
+
+	_, err = membershipSpec(key, []string{"a"}, nil)
+	assert.ErrorContains(t, err, "added party a is already a member")
+	_, err = membershipSpec(key, nil, []string{"z"})
+	assert.ErrorContains(t, err, "removed party z is not a member")
+	_, err = membershipSpec(key, []string{"d", "d"}, nil)
+	assert.ErrorContains(t, err, "added more than once")
+}
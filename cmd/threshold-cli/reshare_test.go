@@ -0,0 +1,255 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/pkg/ecdsa"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/pool"
+	"github.com/luxfi/threshold/protocols/cmp"
+	"github.com/luxfi/threshold/protocols/frost"
+	"github.com/luxfi/threshold/protocols/frost/keygen"
+	"github.com/luxfi/threshold/protocols/lss"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReshareCMP reshares a 3-of-5 CMP key to 3-of-7, each party over the network with its own config,
+// and signs with a set of old and new parties.
+func TestReshareCMP(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	group := curve.Secp256k1{}
+	oldConfigs, partyIDs := test.GenerateConfig(group, 5, 2, rand.Reader, pl)
+	publicKey := oldConfigs[partyIDs[0]].PublicPoint()
+
+	spec := &reshareSpec{Threshold: 2}
+	for _, id := range partyIDs {
+		spec.Parties = append(spec.Parties, string(id))
+	}
+	spec.Parties = append(spec.Parties, "f", "g")
+	plan, err := spec.planKey(cmpReshareKey(oldConfigs[partyIDs[0]]))
+	require.NoError(t, err)
+	assert.Equal(t, []party.ID{"f", "g"}, plan.Add)
+
+	// the new parties only get the public data of the group
+	configs := make(map[party.ID]*lss.Config, len(plan.Parties))
+	for _, id := range partyIDs {
+		configs[id] = cmpLSSConfig(oldConfigs[id])
+	}
+	for _, id := range plan.Add {
+		configs[id] = joinGroup(t, "cmp", configs[partyIDs[0]], id)
+	}
+
+	// the seven parties share this machine, which makes the rounds of the refresh slower than the default timeout
+	defer func(timeout time.Duration) { roundTimeout = timeout }(roundTimeout)
+	roundTimeout = 10 * time.Minute
+
+	newConfigs := make(map[party.ID]*cmp.Config, len(plan.Parties))
+	var mu sync.Mutex
+	runReshareParties(configs, func(id party.ID, transport *test.Network) {
+		c, err := runCMPReshare(configs[id], plan, pl, transport.Transport(id))
+		assert.NoError(t, err)
+		mu.Lock()
+		newConfigs[id] = c
+		mu.Unlock()
+	})
+	require.Len(t, newConfigs, 7)
+	for id, c := range newConfigs {
+		require.NotNil(t, c, id)
+		assert.Equal(t, id, c.ID)
+		assert.Equal(t, 2, c.Threshold)
+		assert.EqualValues(t, 1, c.Generation)
+		assert.True(t, publicKey.Equal(c.PublicPoint()), "resharing must preserve the public key")
+		assert.True(t, c.ECDSA.ActOnBase().Equal(c.Public[id].ECDSA))
+
+		// the config survives a round trip through the binary encoding, which validates it
+		data, err := c.MarshalBinary()
+		require.NoError(t, err)
+		decoded := cmp.EmptyConfig(group)
+		require.NoError(t, decoded.UnmarshalBinary(data))
+		assert.EqualValues(t, 1, decoded.Generation)
+	}
+	assert.False(t, oldConfigs[partyIDs[1]].ECDSA.Equal(newConfigs[partyIDs[1]].ECDSA), "shares must be refreshed")
+
+	// signing through the handler stalls on CMP, so the rounds are driven directly
+	signers := []party.ID{partyIDs[1], "f", "g"}
+	hash := sha256.Sum256([]byte("reshared"))
+	rounds := make([]round.Session, 0, len(signers))
+	for _, id := range signers {
+		r, err := cmp.Sign(newConfigs[id], signers, hash[:], pl)(nil)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+	require.NoError(t, test.RunRounds(rounds, nil))
+	for _, r := range rounds {
+		sig := r.(*round.Output).Result.(*ecdsa.Signature)
+		assert.True(t, sig.Verify(publicKey, hash[:]))
+	}
+}
+
+// TestReshareFROST reshares a 2-of-4 FROST key to 3-of-5, removing one party and adding two.
+func TestReshareFROST(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(4)
+	rounds := make([]round.Session, 0, len(partyIDs))
+	for _, id := range partyIDs {
+		r, err := keygen.StartKeygenCommon(false, group, partyIDs, 1, id, nil, nil, nil)(nil)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+	require.NoError(t, test.RunRounds(rounds, nil))
+	configs := make(map[party.ID]*lss.Config, len(rounds))
+	for _, r := range rounds {
+		c := r.(*round.Output).Result.(*keygen.Config)
+		configs[c.ID] = frostLSSConfig(c)
+	}
+	publicKey, err := configs[partyIDs[0]].PublicKey()
+	require.NoError(t, err)
+
+	spec := &reshareSpec{Threshold: 2, Parties: []string{string(partyIDs[0]), string(partyIDs[1]), string(partyIDs[2]), "x", "y"}}
+	key, err := lssReshareKey(configs[partyIDs[0]])
+	require.NoError(t, err)
+	key.Threshold--
+	key.MinThreshold = 0
+	plan, err := spec.planKey(key)
+	require.NoError(t, err)
+	assert.Equal(t, []party.ID{partyIDs[3]}, plan.Remove)
+	for _, id := range plan.Add {
+		configs[id] = joinGroup(t, "frost", configs[partyIDs[0]], id)
+	}
+
+	newConfigs := make(map[party.ID]*frost.Config, len(configs))
+	var mu sync.Mutex
+	runReshareParties(configs, func(id party.ID, transport *test.Network) {
+		c, err := runFROSTReshare(configs[id], plan, pl, transport.Transport(id))
+		assert.NoError(t, err)
+		mu.Lock()
+		newConfigs[id] = c
+		mu.Unlock()
+	})
+	assert.Nil(t, newConfigs[partyIDs[3]], "a party leaving the group keeps no share")
+	delete(newConfigs, partyIDs[3])
+	require.Len(t, newConfigs, 5)
+	for id, c := range newConfigs {
+		require.NotNil(t, c, id)
+		assert.Equal(t, 2, c.Threshold)
+		assert.True(t, publicKey.Equal(c.PublicKey))
+		assert.EqualValues(t, 1, c.Generation)
+		assert.True(t, c.PrivateShare.ActOnBase().Equal(c.VerificationShares.Points[id]))
+		assert.Empty(t, c.ChainKey, "the chain key of the LSS view is dropped")
+	}
+
+	message := []byte("reshared")
+	signers := []party.ID{partyIDs[0], "x", "y"}
+	rounds = rounds[:0]
+	for _, id := range signers {
+		r, err := frost.Sign(newConfigs[id], signers, message)(nil)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+	require.NoError(t, test.RunRounds(rounds, nil))
+	for _, r := range rounds {
+		sig := r.(*round.Output).Result.(frost.Signature)
+		assert.True(t, sig.Verify(publicKey, message))
+	}
+}
+
+// joinGroup returns the config with which id joins the group of member, through a group file.
+func joinGroup(t *testing.T, protocol string, member *lss.Config, id party.ID) *lss.Config {
+	data, err := exportGroup(protocol, member)
+	require.NoError(t, err)
+	var g reshareGroup
+	require.NoError(t, json.Unmarshal(data, &g))
+	c, err := g.joinConfig(member.Group, id)
+	require.NoError(t, err)
+	assert.Nil(t, c.ECDSA)
+	return c
+}
+
+// runReshareParties runs reshare for every party of configs at once, over one network.
+func runReshareParties(configs map[party.ID]*lss.Config, reshare func(id party.ID, network *test.Network)) {
+	ids := make([]party.ID, 0, len(configs))
+	for id := range configs {
+		ids = append(ids, id)
+	}
+	network := test.NewNetwork(party.NewIDSlice(ids))
+	var wg sync.WaitGroup
+	for _, id := range ids {
+		wg.Add(1)
+		go func(id party.ID) {
+			defer wg.Done()
+			reshare(id, network)
+		}(id)
+	}
+	wg.Wait()
+}
+
+// TestReshareJoin checks the group file a new party joins a reshare with.
+func TestReshareJoin(t *testing.T) {
+	c, _ := testLSSConfig(t, 3, 2)
+	data, err := exportLSSConfig(c, "group")
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "ecdsa", "a group file holds no share")
+
+	var g reshareGroup
+	require.NoError(t, json.Unmarshal(data, &g))
+	_, err = g.joinConfig(c.Group, c.ID)
+	assert.ErrorContains(t, err, "already a party")
+
+	// a public share off the polynomial of the others is rejected
+	for id := range g.Public {
+		if id != c.ID {
+			g.Public[id] = g.Public[c.ID]
+			break
+		}
+	}
+	_, err = g.joinConfig(c.Group, "new")
+	assert.Error(t, err)
+
+	input := filepath.Join(t.TempDir(), "group.json")
+	require.NoError(t, os.WriteFile(input, data, 0600))
+	t.Run("protocol", func(t *testing.T) {
+		err := runReshareCommand(t, "--protocol", "cmp", "--input", input, "--join", "new", "--add-parties", "new")
+		assert.ErrorContains(t, err, "not cmp")
+	})
+	t.Run("not added", func(t *testing.T) {
+		err := runReshareCommand(t, "--protocol", "lss", "--input", input, "--join", "new", "--new-threshold", "1")
+		assert.ErrorContains(t, err, "doesn't add it")
+	})
+}
+
+// runReshareCommand runs the reshare command with args, and resets its flags afterwards.
+func runReshareCommand(t *testing.T, args ...string) error {
+	t.Cleanup(func() {
+		reset := func(f *pflag.Flag) {
+			// the default of a slice flag prints as "[]", which Set would take as an element
+			if v, ok := f.Value.(pflag.SliceValue); ok {
+				_ = v.Replace(nil)
+			} else {
+				_ = f.Value.Set(f.DefValue)
+			}
+			f.Changed = false
+		}
+		reshareCmd.Flags().VisitAll(reset)
+		rootCmd.PersistentFlags().VisitAll(reset)
+	})
+	rootCmd.SetArgs(append([]string{"reshare"}, args...))
+	rootCmd.SilenceUsage = true
+	return rootCmd.Execute()
+}
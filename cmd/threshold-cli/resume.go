@@ -0,0 +1,139 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/pkg/hash"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/pool"
+	"github.com/luxfi/threshold/pkg/protocol"
+	"github.com/luxfi/threshold/protocols/frost"
+	"github.com/luxfi/threshold/protocols/lss"
+)
+
+// resumeState is the state of a keygen run with --resume, from which a restarted party continues.
+// It is saved encrypted under a passphrase, like the configs of keygen --encrypt,
+// since the seed determines our secret share, and the snapshot holds the shares sent to us.
+type resumeState struct {
+	// Seed is the source of all randomness of the run, so that the rounds replayed on restore
+	// send the same messages as before the restart
+	Seed []byte `json:"seed"`
+	// Snapshot is the handler's snapshot at the start of the last round reached, empty before round 2
+	Snapshot []byte `json:"snapshot,omitempty"`
+}
+
+// readResumeState decrypts the state in path with passphrase,
+// and starts a new one with a fresh seed if the file does not exist.
+func readResumeState(path string, passphrase []byte) (*resumeState, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		state := &resumeState{Seed: make([]byte, 32)}
+		if _, err := io.ReadFull(rand.Reader, state.Seed); err != nil {
+			return nil, fmt.Errorf("failed to sample seed: %w", err)
+		}
+		return state, writeResumeState(path, state, passphrase)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resume state: %w", err)
+	}
+	data, err = decryptConfig(data, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt resume state %s: %w", path, err)
+	}
+	var state resumeState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal resume state %s: %w", path, err)
+	}
+	if len(state.Seed) == 0 {
+		return nil, fmt.Errorf("resume state %s has no seed", path)
+	}
+	return &state, nil
+}
+
+// randomness returns the stream of randomness derived from the seed, the same on each call.
+func (s *resumeState) randomness() io.Reader {
+	return hash.New(&hash.BytesWithDomain{TheDomain: "Keygen Resume Seed", Bytes: s.Seed}).Digest()
+}
+
+// writeResumeState saves state in path, encrypted under passphrase.
+func writeResumeState(path string, state *resumeState, passphrase []byte) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if data, err = encryptConfig(data, passphrase); err != nil {
+		return fmt.Errorf("failed to encrypt resume state: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write resume state: %w", err)
+	}
+	return nil
+}
+
+// runResumableKeygen runs keygen like runLSSKeygen and runFROSTKeygen, saving the progress in the file path
+// encrypted under passphrase, and continues from the last round reached if the file holds the state of an interrupted run.
+// The file is removed once keygen completes.
+//
+// Only LSS and FROST keygen can be resumed, since they draw all their randomness from one reader:
+// the rounds are rebuilt by replaying them with the same randomness.
+func runResumableKeygen(path string, passphrase []byte, protocolName string, group curve.Curve, selfID party.ID, partyIDs []party.ID, threshold int, pl *pool.Pool, transport protocol.Transport) (interface{}, error) {
+	state, err := readResumeState(path, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	randomness := state.randomness()
+
+	var start protocol.StartFunc
+	switch protocolName {
+	case "lss":
+		start = lss.KeygenWithRand(group, selfID, partyIDs, threshold, pl, randomness)
+	case "frost":
+		start = frost.KeygenWithRand(group, selfID, partyIDs, threshold, randomness)
+	default:
+		return nil, fmt.Errorf("--resume is not supported for %s keygen", protocolName)
+	}
+
+	var h *protocol.MultiHandler
+	if len(state.Snapshot) == 0 {
+		h, err = protocol.NewMultiHandler(start, nil)
+	} else {
+		h, err = protocol.RestoreMultiHandler(start, state.Snapshot)
+		if err == nil {
+			fmt.Printf("Resuming keygen from round %d\n", h.RoundNumber())
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// callbacks are delivered one at a time, so they may share state
+	h.OnRoundChange(func(number round.Number) {
+		snapshot, err := h.Snapshot()
+		if err != nil {
+			// the execution finished meanwhile
+			return
+		}
+		state.Snapshot = snapshot
+		if err := writeResumeState(path, state, passphrase); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: keygen can't be resumed from round %d: %v\n", number, err)
+		}
+	})
+
+	result, err := runHandler(h, transport, "keygen", roundTimeout)
+	if err != nil {
+		return nil, err
+	}
+	h.OnRoundChange(nil)
+	if err := os.Remove(path); err != nil {
+		return nil, fmt.Errorf("failed to remove resume state: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/protocol"
+	"github.com/luxfi/threshold/protocols/frost"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestResumeKeygen restarts a party from the state file written before it crashed in round 2 of FROST keygen.
+// The file is encrypted, and doesn't reveal the seed.
+func TestResumeKeygen(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(3)
+	network := test.NewNetwork(partyIDs)
+	path := filepath.Join(t.TempDir(), "resume.json")
+	passphrase := []byte("resume passphrase")
+
+	state, err := readResumeState(path, passphrase)
+	require.NoError(t, err)
+	require.FileExists(t, path, "the seed is saved before keygen starts")
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, isEncrypted(data))
+	assert.NotContains(t, string(data), base64.StdEncoding.EncodeToString(state.Seed))
+	_, err = readResumeState(path, []byte("wrong"))
+	assert.ErrorIs(t, err, errWrongPassphrase)
+
+	handlers := make([]*protocol.MultiHandler, len(partyIDs))
+	for i, id := range partyIDs {
+		start := frost.Keygen(group, id, partyIDs, 1)
+		if i == 0 {
+			start = frost.KeygenWithRand(group, id, partyIDs, 1, state.randomness())
+		}
+		handlers[i], err = protocol.NewMultiHandler(start, nil)
+		require.NoError(t, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var wg sync.WaitGroup
+	for i, h := range handlers {
+		wg.Add(1)
+		go func(h *protocol.MultiHandler) {
+			defer wg.Done()
+			assert.NoError(t, protocol.RunUntil(ctx, h, network.Transport(partyIDs[i]), 2))
+		}(h)
+	}
+	wg.Wait()
+
+	// party 0 crashes after saving its snapshot
+	state.Snapshot, err = handlers[0].Snapshot()
+	require.NoError(t, err)
+	require.NoError(t, writeResumeState(path, state, passphrase))
+
+	for i, h := range handlers[1:] {
+		wg.Add(1)
+		go func(h *protocol.MultiHandler) {
+			defer wg.Done()
+			assert.NoError(t, protocol.RunUntil(ctx, h, network.Transport(partyIDs[i+1]), 100))
+		}(h)
+	}
+	result, err := runResumableKeygen(path, passphrase, "frost", group, partyIDs[0], partyIDs, 1, nil, network.Transport(partyIDs[0]))
+	require.NoError(t, err)
+	wg.Wait()
+
+	other, err := handlers[1].Result()
+	require.NoError(t, err)
+	assert.True(t, result.(*frost.Config).PublicKey.Equal(other.(*frost.Config).PublicKey))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "the state file is removed once keygen completes")
+
+	_, err = runResumableKeygen(path, passphrase, "cmp", group, partyIDs[0], partyIDs, 1, nil, network.Transport(partyIDs[0]))
+	assert.ErrorContains(t, err, "not supported")
+}
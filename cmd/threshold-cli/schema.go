@@ -0,0 +1,339 @@
+package main
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/luxfi/threshold/pkg/ecdsa"
+	cmpconfig "github.com/luxfi/threshold/protocols/cmp/config"
+	"github.com/luxfi/threshold/protocols/frost"
+	"github.com/luxfi/threshold/protocols/frost/keygen"
+	lssconfig "github.com/luxfi/threshold/protocols/lss/config"
+	"github.com/spf13/cobra"
+)
+
+// schemaDialect is the JSON Schema version of the generated schemas.
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// fileTypes are the kinds of JSON files the CLI reads and writes, which have a schema.
+var fileTypes = []string{"config", "signature", "presignature-pool"}
+
+// jsonSchema is the subset of JSON Schema needed to describe the documents produced by encoding/json.
+type jsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Type                 jsonTypes              `json:"type,omitempty"`
+	ContentEncoding      string                 `json:"contentEncoding,omitempty"`
+	Minimum              *int                   `json:"minimum,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+}
+
+// jsonTypes lists the types a value may have. It is encoded as a single string if there is only one.
+type jsonTypes []string
+
+func (t jsonTypes) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// schemaType returns the Go type a file of the given type is decoded into for protocol.
+func schemaType(fileType, protocol string) (reflect.Type, error) {
+	switch fileType {
+	case "config":
+		switch protocol {
+		case "lss":
+			return lssconfig.JSONType(), nil
+		case "cmp":
+			return cmpconfig.JSONType(), nil
+		case "frost":
+			return keygen.JSONType(), nil
+		}
+	case "signature":
+		switch protocol {
+		case "lss", "cmp":
+			return ecdsa.SignatureJSONType(), nil
+		case "frost":
+			return reflect.TypeOf(frost.Signature{}), nil
+		}
+	case "presignature-pool":
+		return reflect.TypeOf(presignaturePool{}), nil
+	default:
+		return nil, fmt.Errorf("unknown file type %q, expected one of %s", fileType, strings.Join(fileTypes, ", "))
+	}
+	return nil, fmt.Errorf("unknown protocol: %s", protocol)
+}
+
+// generateSchema returns the schema of a file of the given type for protocol.
+func generateSchema(fileType, protocol string) (*jsonSchema, error) {
+	t, err := schemaType(fileType, protocol)
+	if err != nil {
+		return nil, err
+	}
+	s := schemaFor(t)
+	if fileType == "signature" {
+		// the signature follows its header
+		signature := s
+		s = schemaFor(reflect.TypeOf(signatureFile{}))
+		s.Properties["signature"] = signature
+	}
+	s.Schema = schemaDialect
+	if fileType == "presignature-pool" {
+		s.Title = fileType
+	} else {
+		s.Title = protocol + " " + fileType
+	}
+	return s, nil
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// schemaFor derives the schema of the JSON encoding of t, following the rules of encoding/json.
+// Fields without omitempty are always written, so they are required.
+func schemaFor(t reflect.Type) *jsonSchema {
+	if t.Kind() == reflect.Ptr {
+		s := schemaFor(t.Elem())
+		if len(s.Type) > 0 {
+			s.Type = append(s.Type, "null")
+		}
+		return s
+	}
+	// types with their own encoding can't be described further
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		return &jsonSchema{}
+	}
+	if t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		return &jsonSchema{Type: jsonTypes{"string"}}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &jsonSchema{Type: jsonTypes{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &jsonSchema{Type: jsonTypes{"integer"}}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		zero := 0
+		return &jsonSchema{Type: jsonTypes{"integer"}, Minimum: &zero}
+	case reflect.Float32, reflect.Float64:
+		return &jsonSchema{Type: jsonTypes{"number"}}
+	case reflect.String:
+		return &jsonSchema{Type: jsonTypes{"string"}}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &jsonSchema{Type: jsonTypes{"string", "null"}, ContentEncoding: "base64"}
+		}
+		return &jsonSchema{Type: jsonTypes{"array", "null"}, Items: schemaFor(t.Elem())}
+	case reflect.Array:
+		return &jsonSchema{Type: jsonTypes{"array"}, Items: schemaFor(t.Elem())}
+	case reflect.Map:
+		return &jsonSchema{Type: jsonTypes{"object", "null"}, AdditionalProperties: schemaFor(t.Elem())}
+	case reflect.Struct:
+		s := &jsonSchema{Type: jsonTypes{"object"}, Properties: map[string]*jsonSchema{}}
+		addFields(s, t)
+		return s
+	default:
+		// interfaces may hold anything
+		return &jsonSchema{}
+	}
+}
+
+// addFields adds the fields of the struct type t to s, including those promoted from embedded structs.
+func addFields(s *jsonSchema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addFields(s, ft)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = schemaFor(f.Type)
+		if !strings.Contains(opts, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// validateJSON checks that data is a JSON document matching s.
+func validateJSON(s *jsonSchema, data []byte) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	if d.More() {
+		return fmt.Errorf("invalid JSON: unexpected data after the document")
+	}
+	return s.validate(v, "")
+}
+
+// validate checks v, the value at path in the document, against s.
+func (s *jsonSchema) validate(v interface{}, path string) error {
+	where := "document"
+	if path != "" {
+		where = "field " + path
+	}
+
+	if len(s.Type) > 0 {
+		actual := jsonTypeOf(v)
+		ok := false
+		for _, expected := range s.Type {
+			if expected == actual || (expected == "number" && actual == "integer") {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return fmt.Errorf("%s: expected %s, got %s", where, strings.Join(s.Type, " or "), actual)
+		}
+	}
+
+	switch v := v.(type) {
+	case json.Number:
+		if s.Minimum != nil {
+			if n, err := v.Float64(); err == nil && n < float64(*s.Minimum) {
+				return fmt.Errorf("%s: %s is less than %d", where, v, *s.Minimum)
+			}
+		}
+	case string:
+		if s.ContentEncoding == "base64" {
+			if _, err := base64.StdEncoding.DecodeString(v); err != nil {
+				return fmt.Errorf("%s: invalid base64: %w", where, err)
+			}
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				if err := s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				return fmt.Errorf("missing field %s", joinPath(path, name))
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			sub, ok := s.Properties[key]
+			if !ok {
+				sub = s.AdditionalProperties
+			}
+			if sub == nil {
+				continue
+			}
+			if err := sub.validate(v[key], joinPath(path, key)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func jsonTypeOf(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if strings.ContainsAny(v.String(), ".eE") {
+			return "number"
+		}
+		return "integer"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// validateFile checks data, the contents of a file of the given type for protocol, against its schema.
+func validateFile(data []byte, fileType, protocol string) error {
+	s, err := generateSchema(fileType, protocol)
+	if err != nil {
+		return err
+	}
+	if fileType == "signature" && !hasSignatureHeader(data) {
+		// a signature without a header, as written before signature files had one
+		s = s.Properties["signature"]
+	}
+	return validateJSON(s, data)
+}
+
+func runSchema(cmd *cobra.Command, args []string) error {
+	fileType, _ := cmd.Flags().GetString("type")
+	s, err := generateSchema(fileType, protocolName)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if outputFile == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(outputFile, data, 0644)
+}
+
+func runValidateFile(cmd *cobra.Command, args []string) error {
+	fileType, _ := cmd.Flags().GetString("type")
+	data, err := os.ReadFile(inputFile)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", inputFile, err)
+	}
+	if err := validateFile(data, fileType, protocolName); err != nil {
+		return fmt.Errorf("%s: %w", inputFile, err)
+	}
+	fmt.Printf("%s is a valid %s file\n", inputFile, fileType)
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/luxfi/threshold/pkg/ecdsa"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSchemasUpToDate checks that the published schemas match the Go types.
+// Regenerate them with: threshold-cli schema -p <protocol> --type <type> -o schemas/<protocol>-<type>.schema.json
+func TestSchemasUpToDate(t *testing.T) {
+	files := map[string][2]string{
+		"presignature-pool.schema.json": {"presignature-pool", ""},
+	}
+	for _, protocol := range []string{"lss", "cmp", "frost"} {
+		for _, fileType := range []string{"config", "signature"} {
+			files[protocol+"-"+fileType+".schema.json"] = [2]string{fileType, protocol}
+		}
+	}
+
+	for name, args := range files {
+		s, err := generateSchema(args[0], args[1])
+		require.NoError(t, err)
+		expected, err := json.MarshalIndent(s, "", "  ")
+		require.NoError(t, err)
+		actual, err := os.ReadFile(filepath.Join("schemas", name))
+		require.NoError(t, err)
+		assert.Equal(t, string(expected)+"\n", string(actual), "schemas/%s is out of date", name)
+	}
+}
+
+func TestValidateConfigFile(t *testing.T) {
+	c, _ := testLSSConfig(t, 3, 2)
+	data, err := json.Marshal(c)
+	require.NoError(t, err)
+	require.NoError(t, validateFile(data, "config", "lss"))
+
+	malformed := func(modify func(doc map[string]interface{})) []byte {
+		var doc map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &doc))
+		modify(doc)
+		out, err := json.Marshal(doc)
+		require.NoError(t, err)
+		return out
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		err  string
+	}{
+		{"missing field", malformed(func(doc map[string]interface{}) { delete(doc, "public") }), "missing field public"},
+		{"wrong type", malformed(func(doc map[string]interface{}) { doc["threshold"] = "2" }), "field threshold: expected integer, got string"},
+		{"fractional threshold", malformed(func(doc map[string]interface{}) { doc["threshold"] = 1.5 }), "field threshold: expected integer, got number"},
+		{"negative generation", malformed(func(doc map[string]interface{}) { doc["generation"] = -1 }), "field generation: -1 is less than 0"},
+		{"nested field", malformed(func(doc map[string]interface{}) {
+			doc["public"].(map[string]interface{})["a"] = map[string]interface{}{}
+		}), "missing field public.a.ecdsa"},
+		{"not an object", []byte(`[1, 2]`), "document: expected object, got array"},
+		{"invalid JSON", []byte(`{"id": `), "invalid JSON"},
+		{"trailing data", append(append([]byte{}, data...), []byte(`{}`)...), "unexpected data after the document"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateFile(tt.data, "config", "lss")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+
+	_, err = generateSchema("config", "unknown")
+	assert.Error(t, err)
+	_, err = generateSchema("key", "lss")
+	assert.Error(t, err)
+}
+
+func TestValidateSignatureAndPoolFiles(t *testing.T) {
+	group := curve.Secp256k1{}
+	sig, err := json.Marshal(ecdsa.EmptySignature(group))
+	require.NoError(t, err)
+	assert.NoError(t, validateFile(sig, "signature", "cmp"))
+	assert.ErrorContains(t, validateFile([]byte(`{"R": {}}`), "signature", "cmp"), "missing field S")
+
+	assert.NoError(t, validateFile([]byte(`{"presignatures": ["AAEC", "AwQF"]}`), "presignature-pool", ""))
+	assert.NoError(t, validateFile([]byte(`{"presignatures": null}`), "presignature-pool", ""))
+	assert.ErrorContains(t, validateFile([]byte(`{"presignatures": ["not base64!"]}`), "presignature-pool", ""),
+		"field presignatures[0]: invalid base64")
+	assert.ErrorContains(t, validateFile([]byte(`{"presignatures": "AAEC"}`), "presignature-pool", ""),
+		"field presignatures: expected array or null, got string")
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/luxfi/threshold/pkg/protocol"
+	"github.com/spf13/cobra"
+)
+
+// readSessionID returns the session ID given with --session-id, which binds the executions of a command to one
+// session, so that the messages of another signing, even of the same digest, are rejected.
+//
+// All parties must use the same session ID. Without the flag, a fresh one is drawn and written to w,
+// to be given to the other parties along with the signers and the message: the protocol waits for them
+// on the transport of openTransport, which holds their messages until they join.
+func readSessionID(cmd *cobra.Command, w io.Writer) ([]byte, error) {
+	encoded, _ := cmd.Flags().GetString("session-id")
+	if encoded == "" {
+		sessionID, err := protocol.NewSessionID()
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(w, "Session ID: %x (give it to the other parties with --session-id)\n", sessionID)
+		return sessionID, nil
+	}
+	sessionID, err := hex.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode session ID: %w", err)
+	}
+	if len(sessionID) != protocol.SessionIDSize {
+		return nil, fmt.Errorf("session ID must be %d bytes, got %d", protocol.SessionIDSize, len(sessionID))
+	}
+	return sessionID, nil
+}
+
+// executionSessionID returns the session ID of the i-th execution of a command run with sessionID,
+// so that the executions signing each message of a batch, or computing each presignature, are told apart.
+func executionSessionID(sessionID []byte, i int) []byte {
+	return binary.BigEndian.AppendUint32(bytes.Clone(sessionID), uint32(i))
+}
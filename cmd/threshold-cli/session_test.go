@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/luxfi/threshold/pkg/protocol"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sessionCommand(t *testing.T, sessionID string) *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("session-id", "", "")
+	if sessionID != "" {
+		require.NoError(t, cmd.Flags().Set("session-id", sessionID))
+	}
+	return cmd
+}
+
+func TestReadSessionID(t *testing.T) {
+	// without the flag, a fresh session ID is printed for the other parties
+	var out bytes.Buffer
+	fresh, err := readSessionID(sessionCommand(t, ""), &out)
+	require.NoError(t, err)
+	assert.Len(t, fresh, protocol.SessionIDSize)
+	assert.Contains(t, out.String(), hex.EncodeToString(fresh))
+	other, err := readSessionID(sessionCommand(t, ""), &out)
+	require.NoError(t, err)
+	assert.NotEqual(t, fresh, other)
+
+	out.Reset()
+	given, err := readSessionID(sessionCommand(t, hex.EncodeToString(fresh)), &out)
+	require.NoError(t, err)
+	assert.Equal(t, fresh, given)
+	assert.Empty(t, out.String())
+
+	_, err = readSessionID(sessionCommand(t, "not hex"), &out)
+	assert.Error(t, err)
+	_, err = readSessionID(sessionCommand(t, strings.Repeat("ab", protocol.SessionIDSize-1)), &out)
+	assert.ErrorContains(t, err, "session ID must be 32 bytes")
+
+	// each execution of a command has its own session ID
+	assert.NotEqual(t, executionSessionID(fresh, 0), executionSessionID(fresh, 1))
+	assert.Equal(t, executionSessionID(fresh, 1), executionSessionID(fresh, 1))
+	assert.Equal(t, fresh, given, "the session ID is not modified")
+}
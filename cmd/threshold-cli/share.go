@@ -0,0 +1,486 @@
+package main
+
+import (
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/cronokirby/saferith"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/paillier"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/pedersen"
+	"github.com/luxfi/threshold/protocols/cmp"
+	cmpconfig "github.com/luxfi/threshold/protocols/cmp/config"
+	"github.com/luxfi/threshold/protocols/frost"
+	"github.com/luxfi/threshold/protocols/lss"
+	lssconfig "github.com/luxfi/threshold/protocols/lss/config"
+)
+
+// sharePEMType is the label of PEM blocks holding a thresholdShare.
+// The block contains a secret share, and must be protected like a private key.
+const sharePEMType = "THRESHOLD SHARE"
+
+// shareVersion is the version of the thresholdShare structure written by export.
+const shareVersion = 1
+
+// Object identifiers of the supported curves, as used in X.509 and PKCS #8.
+var (
+	oidSecp256k1 = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+	oidP256      = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+	oidEd25519   = asn1.ObjectIdentifier{1, 3, 101, 112}
+)
+
+// thresholdShare is the ASN.1 structure of an exported key share:
+//
+//	ThresholdShare ::= SEQUENCE {
+//	    version      INTEGER,
+//	    protocol     UTF8String,            -- "lss", "cmp" or "frost"
+//	    partyID      UTF8String,
+//	    threshold    INTEGER,
+//	    generation   INTEGER,
+//	    curve        OBJECT IDENTIFIER,
+//	    secretShare  OCTET STRING,          -- big-endian scalar
+//	    publicKey    OCTET STRING,          -- encoded point
+//	    publicShares SEQUENCE OF PublicShare,
+//	    chainKey     OCTET STRING,
+//	    rid          OCTET STRING,
+//	    extra        [0] EXPLICIT OCTET STRING OPTIONAL
+//	}
+//
+//	PublicShare ::= SEQUENCE {
+//	    partyID UTF8String,
+//	    point   OCTET STRING
+//	}
+//
+// extra holds the DER encoding of the protocol specific data that doesn't fit the common fields,
+// see lssExtra and cmpExtra.
+type thresholdShare struct {
+	Version      int
+	Protocol     string `asn1:"utf8"`
+	PartyID      string `asn1:"utf8"`
+	Threshold    int
+	Generation   int64
+	Curve        asn1.ObjectIdentifier
+	SecretShare  []byte
+	PublicKey    []byte
+	PublicShares []publicShare
+	ChainKey     []byte
+	RID          []byte
+	Extra        []byte `asn1:"optional,explicit,tag:0"`
+}
+
+type publicShare struct {
+	PartyID string `asn1:"utf8"`
+	Point   []byte
+}
+
+// lssExtra is the protocol specific data of an LSS share:
+//
+//	LSSExtra ::= SEQUENCE {
+//	    rollbackFrom INTEGER
+//	}
+type lssExtra struct {
+	RollbackFrom int64
+}
+
+// cmpExtra is the protocol specific data of a CMP share:
+//
+//	CMPExtra ::= SEQUENCE {
+//	    elGamal   OCTET STRING,      -- secret ElGamal scalar
+//	    paillierP INTEGER,
+//	    paillierQ INTEGER,
+//	    parties   SEQUENCE OF CMPPublic
+//	}
+//
+//	CMPPublic ::= SEQUENCE {
+//	    partyID UTF8String,
+//	    elGamal OCTET STRING,
+//	    n       INTEGER,             -- Paillier and Pedersen modulus
+//	    s       INTEGER,
+//	    t       INTEGER
+//	}
+type cmpExtra struct {
+	ElGamal   []byte
+	PaillierP *big.Int
+	PaillierQ *big.Int
+	Parties   []cmpPublic
+}
+
+type cmpPublic struct {
+	PartyID string `asn1:"utf8"`
+	ElGamal []byte
+	N       *big.Int
+	S       *big.Int
+	T       *big.Int
+}
+
+func curveOID(group curve.Curve) (asn1.ObjectIdentifier, error) {
+	switch group.(type) {
+	case curve.Secp256k1:
+		return oidSecp256k1, nil
+	case curve.P256:
+		return oidP256, nil
+	case curve.Ed25519:
+		return oidEd25519, nil
+	default:
+		return nil, fmt.Errorf("no object identifier for curve %s", group.Name())
+	}
+}
+
+func curveFromOID(oid asn1.ObjectIdentifier) (curve.Curve, error) {
+	switch {
+	case oid.Equal(oidSecp256k1):
+		return curve.Secp256k1{}, nil
+	case oid.Equal(oidP256):
+		return curve.P256{}, nil
+	case oid.Equal(oidEd25519):
+		return curve.Ed25519{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported curve %s", oid)
+	}
+}
+
+// newShare fills in the fields common to every protocol.
+func newShare(protocol string, id party.ID, threshold int, generation uint64, secret curve.Scalar, public curve.Point, shares map[party.ID]curve.Point, chainKey, rid []byte) (*thresholdShare, error) {
+	oid, err := curveOID(secret.Curve())
+	if err != nil {
+		return nil, err
+	}
+	secretBytes, err := secret.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("secret share: %w", err)
+	}
+	publicBytes, err := public.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("public key: %w", err)
+	}
+	s := &thresholdShare{
+		Version:     shareVersion,
+		Protocol:    protocol,
+		PartyID:     string(id),
+		Threshold:   threshold,
+		Generation:  int64(generation),
+		Curve:       oid,
+		SecretShare: secretBytes,
+		PublicKey:   publicBytes,
+		ChainKey:    chainKey,
+		RID:         rid,
+	}
+	// parties are written in order, so that the encoding is deterministic
+	ids := make([]party.ID, 0, len(shares))
+	for j := range shares {
+		ids = append(ids, j)
+	}
+	for _, j := range party.NewIDSlice(ids) {
+		point, err := shares[j].MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("public share of %s: %w", j, err)
+		}
+		s.PublicShares = append(s.PublicShares, publicShare{PartyID: string(j), Point: point})
+	}
+	return s, nil
+}
+
+// decode checks the common fields of s, and decodes them over the curve of the share.
+func (s *thresholdShare) decode(protocol string) (group curve.Curve, secret curve.Scalar, public curve.Point, shares map[party.ID]curve.Point, err error) {
+	if s.Version != shareVersion {
+		return nil, nil, nil, nil, fmt.Errorf("unsupported share version %d", s.Version)
+	}
+	if s.Protocol != protocol {
+		return nil, nil, nil, nil, fmt.Errorf("share is for protocol %s, not %s", s.Protocol, protocol)
+	}
+	if s.Generation < 0 || s.Threshold < 0 {
+		return nil, nil, nil, nil, errors.New("negative threshold or generation")
+	}
+	// an empty OCTET STRING decodes as an empty slice, configs use nil for absent values
+	if len(s.ChainKey) == 0 {
+		s.ChainKey = nil
+	}
+	if len(s.RID) == 0 {
+		s.RID = nil
+	}
+	if group, err = curveFromOID(s.Curve); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	secret = group.NewScalar()
+	if err = secret.UnmarshalBinary(s.SecretShare); err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("secret share: %w", err)
+	}
+	public = group.NewPoint()
+	if err = public.UnmarshalBinary(s.PublicKey); err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("public key: %w", err)
+	}
+	shares = make(map[party.ID]curve.Point, len(s.PublicShares))
+	for _, ps := range s.PublicShares {
+		id := party.ID(ps.PartyID)
+		if _, ok := shares[id]; ok {
+			return nil, nil, nil, nil, fmt.Errorf("duplicate public share for %s", id)
+		}
+		point := group.NewPoint()
+		if err = point.UnmarshalBinary(ps.Point); err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("public share of %s: %w", id, err)
+		}
+		shares[id] = point
+	}
+	if _, ok := shares[party.ID(s.PartyID)]; !ok {
+		return nil, nil, nil, nil, fmt.Errorf("no public share for party %s", s.PartyID)
+	}
+	return group, secret, public, shares, nil
+}
+
+func lssShare(config *lss.Config) (*thresholdShare, error) {
+	public, err := config.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	shares := make(map[party.ID]curve.Point, len(config.Public))
+	for id, p := range config.Public {
+		shares[id] = p.ECDSA
+	}
+	s, err := newShare("lss", config.ID, config.Threshold, config.Generation, config.ECDSA, public, shares, config.ChainKey, config.RID)
+	if err != nil {
+		return nil, err
+	}
+	if s.Extra, err = asn1.Marshal(lssExtra{RollbackFrom: int64(config.RollbackFrom)}); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func lssConfigFromShare(s *thresholdShare) (*lss.Config, error) {
+	group, secret, _, shares, err := s.decode("lss")
+	if err != nil {
+		return nil, err
+	}
+	var extra lssExtra
+	if err = unmarshalDER(s.Extra, &extra); err != nil {
+		return nil, fmt.Errorf("lss data: %w", err)
+	}
+	if extra.RollbackFrom < 0 {
+		return nil, errors.New("lss data: negative rollback generation")
+	}
+	config := lss.EmptyConfig(group)
+	config.ID = party.ID(s.PartyID)
+	config.Threshold = s.Threshold
+	config.Generation = uint64(s.Generation)
+	config.RollbackFrom = uint64(extra.RollbackFrom)
+	config.ECDSA = secret
+	config.ChainKey = s.ChainKey
+	config.RID = s.RID
+	for id, point := range shares {
+		config.Public[id] = &lssconfig.Public{ECDSA: point}
+	}
+	return config, nil
+}
+
+func cmpShare(config *cmp.Config) (*thresholdShare, error) {
+	shares := make(map[party.ID]curve.Point, len(config.Public))
+	for id, p := range config.Public {
+		shares[id] = p.ECDSA
+	}
+	s, err := newShare("cmp", config.ID, config.Threshold, 0, config.ECDSA, config.PublicPoint(), shares, config.ChainKey, config.RID)
+	if err != nil {
+		return nil, err
+	}
+	elGamal, err := config.ElGamal.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	extra := cmpExtra{
+		ElGamal:   elGamal,
+		PaillierP: config.Paillier.P().Big(),
+		PaillierQ: config.Paillier.Q().Big(),
+	}
+	for _, id := range config.PartyIDs() {
+		p := config.Public[id]
+		point, err := p.ElGamal.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		extra.Parties = append(extra.Parties, cmpPublic{
+			PartyID: string(id),
+			ElGamal: point,
+			N:       p.Pedersen.N().Big(),
+			S:       p.Pedersen.S().Big(),
+			T:       p.Pedersen.T().Big(),
+		})
+	}
+	if s.Extra, err = asn1.Marshal(extra); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// cmpConfigFromShare rebuilds a CMP config, validating the auxiliary parameters as cmp.Config.UnmarshalBinary does.
+func cmpConfigFromShare(s *thresholdShare) (*cmp.Config, error) {
+	group, secret, _, shares, err := s.decode("cmp")
+	if err != nil {
+		return nil, err
+	}
+	var extra cmpExtra
+	if err = unmarshalDER(s.Extra, &extra); err != nil {
+		return nil, fmt.Errorf("cmp data: %w", err)
+	}
+	elGamal := group.NewScalar()
+	if err = elGamal.UnmarshalBinary(extra.ElGamal); err != nil {
+		return nil, fmt.Errorf("cmp data: ElGamal secret: %w", err)
+	}
+	if secret.IsZero() || elGamal.IsZero() {
+		return nil, errors.New("cmp data: ECDSA or ElGamal secret key is zero")
+	}
+	P, Q := natFromBig(extra.PaillierP), natFromBig(extra.PaillierQ)
+	if err = paillier.ValidatePrime(P); err != nil {
+		return nil, fmt.Errorf("cmp data: prime P: %w", err)
+	}
+	if err = paillier.ValidatePrime(Q); err != nil {
+		return nil, fmt.Errorf("cmp data: prime Q: %w", err)
+	}
+	paillierSecret := paillier.NewSecretKeyFromPrimes(P, Q)
+
+	selfID := party.ID(s.PartyID)
+	public := make(map[party.ID]*cmpconfig.Public, len(extra.Parties))
+	for _, pm := range extra.Parties {
+		id := party.ID(pm.PartyID)
+		if _, ok := public[id]; ok {
+			return nil, fmt.Errorf("cmp data: party %s: duplicate entry", id)
+		}
+		ecdsaPoint, ok := shares[id]
+		if !ok {
+			return nil, fmt.Errorf("cmp data: party %s has no public share", id)
+		}
+		S, T := natFromBig(pm.S), natFromBig(pm.T)
+		if id == selfID {
+			public[id] = &cmpconfig.Public{
+				ECDSA:    secret.ActOnBase(),
+				ElGamal:  elGamal.ActOnBase(),
+				Paillier: paillierSecret.PublicKey,
+				Pedersen: pedersen.New(paillierSecret.Modulus(), S, T),
+			}
+			continue
+		}
+		elGamalPoint := group.NewPoint()
+		if err = elGamalPoint.UnmarshalBinary(pm.ElGamal); err != nil {
+			return nil, fmt.Errorf("cmp data: party %s: %w", id, err)
+		}
+		N := saferith.ModulusFromNat(natFromBig(pm.N))
+		if err = paillier.ValidateN(N); err != nil {
+			return nil, fmt.Errorf("cmp data: party %s: %w", id, err)
+		}
+		if err = pedersen.ValidateParameters(N, S, T); err != nil {
+			return nil, fmt.Errorf("cmp data: party %s: %w", id, err)
+		}
+		if ecdsaPoint.IsIdentity() || elGamalPoint.IsIdentity() {
+			return nil, fmt.Errorf("cmp data: party %s: ECDSA or ElGamal public key is identity", id)
+		}
+		paillierPublic := paillier.NewPublicKey(N)
+		public[id] = &cmpconfig.Public{
+			ECDSA:    ecdsaPoint,
+			ElGamal:  elGamalPoint,
+			Paillier: paillierPublic,
+			Pedersen: pedersen.New(paillierPublic.Modulus(), S, T),
+		}
+	}
+	if len(public) != len(shares) {
+		return nil, errors.New("cmp data: parties don't match the public shares")
+	}
+	if !cmpconfig.ValidThreshold(s.Threshold, len(public)) {
+		return nil, fmt.Errorf("cmp data: threshold %d is invalid", s.Threshold)
+	}
+	if _, ok := public[selfID]; !ok {
+		return nil, errors.New("cmp data: no public data for this party")
+	}
+
+	config := &cmp.Config{
+		Group:     group,
+		ID:        selfID,
+		Threshold: s.Threshold,
+		ECDSA:     secret,
+		ElGamal:   elGamal,
+		Paillier:  paillierSecret,
+		RID:       s.RID,
+		ChainKey:  s.ChainKey,
+		Public:    public,
+	}
+	if err := cmp.VerifyConfig(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func frostShare(config *frost.Config) (*thresholdShare, error) {
+	return newShare("frost", config.ID, config.Threshold, 0, config.PrivateShare, config.PublicKey,
+		config.VerificationShares.Points, config.ChainKey, nil)
+}
+
+func frostConfigFromShare(s *thresholdShare) (*frost.Config, error) {
+	_, secret, public, shares, err := s.decode("frost")
+	if err != nil {
+		return nil, err
+	}
+	config := &frost.Config{
+		ID:                 party.ID(s.PartyID),
+		Threshold:          s.Threshold,
+		PrivateShare:       secret,
+		PublicKey:          public,
+		ChainKey:           s.ChainKey,
+		VerificationShares: party.NewPointMap(shares),
+	}
+	if err := frost.VerifyConfig(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// unmarshalDER decodes data into v, rejecting trailing bytes.
+func unmarshalDER(data []byte, v interface{}) error {
+	rest, err := asn1.Unmarshal(data, v)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return errors.New("unexpected data after the structure")
+	}
+	return nil
+}
+
+func natFromBig(x *big.Int) *saferith.Nat {
+	if x == nil || x.Sign() < 0 {
+		return new(saferith.Nat)
+	}
+	return new(saferith.Nat).SetBig(x, x.BitLen())
+}
+
+// encodeShare encodes a share as DER, or as a PEM block labelled sharePEMType holding the same DER bytes.
+func encodeShare(s *thresholdShare, format string) ([]byte, error) {
+	der, err := asn1.Marshal(*s)
+	if err != nil {
+		return nil, err
+	}
+	if format == "der" {
+		return der, nil
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: sharePEMType, Bytes: der}), nil
+}
+
+// decodeShare decodes a share encoded by encodeShare in the given format.
+// For PEM, only the first block is read, and it must be labelled sharePEMType.
+func decodeShare(data []byte, format string) (*thresholdShare, error) {
+	if format == "pem" {
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, errors.New("no PEM block found")
+		}
+		if block.Type != sharePEMType {
+			return nil, fmt.Errorf("unexpected PEM block %q, expected %q", block.Type, sharePEMType)
+		}
+		data = block.Bytes
+	}
+	s := new(thresholdShare)
+	if err := unmarshalDER(data, s); err != nil {
+		return nil, fmt.Errorf("invalid threshold share: %w", err)
+	}
+	return s, nil
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/pem"
+	"testing"
+
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/pool"
+	"github.com/luxfi/threshold/protocols/frost"
+	"github.com/luxfi/threshold/protocols/frost/keygen"
+	"github.com/luxfi/threshold/protocols/frost/sign"
+	"github.com/luxfi/threshold/protocols/lss"
+	lssconfig "github.com/luxfi/threshold/protocols/lss/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// requireShareBlock checks that data is a single PEM block with the share label.
+func requireShareBlock(t *testing.T, data []byte) {
+	block, rest := pem.Decode(data)
+	require.NotNil(t, block, "export must produce a PEM block")
+	assert.Equal(t, "THRESHOLD SHARE", block.Type)
+	assert.Empty(t, rest)
+	assert.Equal(t, byte(0x30), block.Bytes[0], "the block must hold a DER SEQUENCE")
+}
+
+func TestSharePEMLSS(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(4)
+	configs := lss.RunKeygen(t, group, partyIDs, 3)
+
+	imported := make(map[party.ID]*lssconfig.Config, len(configs))
+	for id, c := range configs {
+		c.Generation = 2
+		c.RollbackFrom = 5
+		data, err := exportLSSConfig(c, "pem")
+		require.NoError(t, err)
+		requireShareBlock(t, data)
+
+		got, err := importLSSConfig(data, "pem", group)
+		require.NoError(t, err)
+		assert.Equal(t, c.ID, got.ID)
+		assert.Equal(t, c.Threshold, got.Threshold)
+		assert.Equal(t, c.Generation, got.Generation)
+		assert.Equal(t, c.RollbackFrom, got.RollbackFrom)
+		assert.Equal(t, c.ChainKey, got.ChainKey)
+		assert.Equal(t, c.RID, got.RID)
+		assert.True(t, c.ECDSA.Equal(got.ECDSA))
+		require.Len(t, got.Public, len(c.Public))
+		for j, p := range c.Public {
+			assert.True(t, p.ECDSA.Equal(got.Public[j].ECDSA))
+		}
+		imported[id] = got
+	}
+
+	// the imported shares still sign for the original key
+	publicKey, err := configs[partyIDs[0]].PublicKey()
+	require.NoError(t, err)
+	hash := sha256.Sum256([]byte("exported share"))
+	sig := lss.RunSign(t, imported, partyIDs[1:], hash[:])
+	assert.True(t, lss.VerifySignature(sig, publicKey, hash[:]))
+
+	_, err = importLSSConfig(must(exportLSSConfig(configs[partyIDs[0]], "pem")), "pem", curve.P256{})
+	assert.Error(t, err, "a share must not be imported on another curve")
+}
+
+func TestSharePEMCMP(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	configs, partyIDs := test.GenerateConfig(curve.Secp256k1{}, 3, 1, rand.Reader, pl)
+	for _, id := range partyIDs {
+		c := configs[id]
+		data, err := exportCMPConfig(c, "pem")
+		require.NoError(t, err)
+		requireShareBlock(t, data)
+
+		got, err := importCMPConfig(data, "pem", curve.Secp256k1{})
+		require.NoError(t, err)
+		// compare the minimal ASN.1 encodings, since the binary encoding of a Paillier or Pedersen
+		// value depends on its announced size, which is lost when it starts with a zero byte
+		have, err := exportCMPConfig(got, "pem")
+		require.NoError(t, err)
+		assert.Equal(t, data, have, "imported config differs from the exported one")
+		assert.True(t, c.PublicPoint().Equal(got.PublicPoint()))
+	}
+}
+
+func TestSharePEMFROST(t *testing.T) {
+	group := curve.Ed25519{}
+	partyIDs := test.PartyIDs(4)
+	threshold := 2
+
+	rounds := make([]round.Session, 0, len(partyIDs))
+	for _, id := range partyIDs {
+		r, err := keygen.StartKeygenCommon(false, group, partyIDs, threshold, id, nil, nil, nil)(nil)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+	require.NoError(t, test.RunRounds(rounds, nil))
+
+	imported := make(map[party.ID]*frost.Config, len(partyIDs))
+	for _, r := range rounds {
+		c := r.(*round.Output).Result.(*keygen.Config)
+		data, err := exportFROSTConfig(c, "pem")
+		require.NoError(t, err)
+		requireShareBlock(t, data)
+
+		got, err := importFROSTConfig(data, "pem", group)
+		require.NoError(t, err)
+		assert.Equal(t, c.ID, got.ID)
+		assert.Equal(t, c.Threshold, got.Threshold)
+		assert.Equal(t, c.ChainKey, got.ChainKey)
+		assert.True(t, c.PrivateShare.Equal(got.PrivateShare))
+		assert.True(t, c.PublicKey.Equal(got.PublicKey))
+		for j, p := range c.VerificationShares.Points {
+			assert.True(t, p.Equal(got.VerificationShares.Points[j]))
+		}
+		imported[c.ID] = got
+	}
+
+	message := []byte("exported share")
+	signers := partyIDs[1:]
+	rounds = rounds[:0]
+	for _, id := range signers {
+		r, err := sign.StartSignCommon(false, imported[id], signers, message)(nil)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+	require.NoError(t, test.RunRounds(rounds, nil))
+	for _, r := range rounds {
+		sig := r.(*round.Output).Result.(frost.Signature)
+		assert.True(t, sig.Verify(imported[partyIDs[0]].PublicKey, message))
+	}
+}
+
+func TestSharePEMRejects(t *testing.T) {
+	c, _ := testLSSConfig(t, 3, 2)
+	data, err := exportLSSConfig(c, "pem")
+	require.NoError(t, err)
+
+	// the protocol is part of the share
+	_, err = importFROSTConfig(data, "pem", c.Group)
+	assert.ErrorContains(t, err, "protocol lss")
+
+	block, _ := pem.Decode(data)
+	block.Type = "PRIVATE KEY"
+	_, err = importLSSConfig(pem.EncodeToMemory(block), "pem", c.Group)
+	assert.Error(t, err, "only THRESHOLD SHARE blocks are accepted")
+
+	block.Type = "THRESHOLD SHARE"
+	block.Bytes = append(block.Bytes, 0)
+	_, err = importLSSConfig(pem.EncodeToMemory(block), "pem", c.Group)
+	assert.Error(t, err, "trailing data must be rejected")
+}
+
+func must(data []byte, err error) []byte {
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+func TestShareDER(t *testing.T) {
+	c, _ := testLSSConfig(t, 3, 2)
+	c.ChainKey = []byte("chain key")
+	der, err := exportLSSConfig(c, "der")
+	require.NoError(t, err)
+
+	// walk the structure the way asn1parse would: a SEQUENCE of primitive fields
+	var outer asn1.RawValue
+	rest, err := asn1.Unmarshal(der, &outer)
+	require.NoError(t, err)
+	assert.Empty(t, rest)
+	assert.Equal(t, byte(0x30), der[0], "a share must be a DER SEQUENCE")
+	assert.Equal(t, asn1.TagSequence, outer.Tag)
+	assert.True(t, outer.IsCompound)
+
+	expected := []struct {
+		class, tag int
+	}{
+		{asn1.ClassUniversal, asn1.TagInteger},     // version
+		{asn1.ClassUniversal, asn1.TagUTF8String},  // protocol
+		{asn1.ClassUniversal, asn1.TagUTF8String},  // partyID
+		{asn1.ClassUniversal, asn1.TagInteger},     // threshold
+		{asn1.ClassUniversal, asn1.TagInteger},     // generation
+		{asn1.ClassUniversal, asn1.TagOID},         // curve
+		{asn1.ClassUniversal, asn1.TagOctetString}, // secretShare
+		{asn1.ClassUniversal, asn1.TagOctetString}, // publicKey
+		{asn1.ClassUniversal, asn1.TagSequence},    // publicShares
+		{asn1.ClassUniversal, asn1.TagOctetString}, // chainKey
+		{asn1.ClassUniversal, asn1.TagOctetString}, // rid
+		{asn1.ClassContextSpecific, 0},             // extra
+	}
+	fields := outer.Bytes
+	for i, e := range expected {
+		var field asn1.RawValue
+		fields, err = asn1.Unmarshal(fields, &field)
+		require.NoError(t, err, "field %d", i)
+		assert.Equal(t, e.class, field.Class, "class of field %d", i)
+		assert.Equal(t, e.tag, field.Tag, "tag of field %d", i)
+		if i == 5 {
+			var oid asn1.ObjectIdentifier
+			_, err = asn1.Unmarshal(field.FullBytes, &oid)
+			require.NoError(t, err)
+			assert.Equal(t, "1.3.132.0.10", oid.String())
+		}
+	}
+	assert.Empty(t, fields)
+
+	// the PEM encoding is the same DER in a THRESHOLD SHARE block
+	data, err := exportLSSConfig(c, "pem")
+	require.NoError(t, err)
+	block, _ := pem.Decode(data)
+	require.NotNil(t, block)
+	assert.Equal(t, der, block.Bytes)
+
+	imported, err := importLSSConfig(der, "der", c.Group)
+	require.NoError(t, err)
+	assert.True(t, c.ECDSA.Equal(imported.ECDSA))
+	assert.Equal(t, c.Generation, imported.Generation)
+	assert.Equal(t, c.ChainKey, imported.ChainKey)
+
+	_, err = importLSSConfig(data, "der", c.Group)
+	assert.Error(t, err, "PEM is not DER")
+}
+
+func TestShareCBOR(t *testing.T) {
+	c, _ := testLSSConfig(t, 3, 2)
+	c.ChainKey, c.RID = []byte("chain key"), []byte("rid")
+	c.Generation = 3
+	data, err := exportLSSConfig(c, "cbor")
+	require.NoError(t, err)
+
+	got, err := importLSSConfig(data, "cbor", c.Group)
+	require.NoError(t, err)
+	assert.Equal(t, c.Generation, got.Generation)
+	assert.True(t, c.ECDSA.Equal(got.ECDSA))
+	assert.Equal(t, data, must(exportLSSConfig(got, "cbor")), "an imported config must export to the same bytes")
+
+	_, err = importFROSTConfig(data, "cbor", c.Group)
+	assert.Error(t, err, "an LSS config is not a FROST config")
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/asn1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/luxfi/threshold/pkg/ecdsa"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/spf13/cobra"
+)
+
+// Signature encodings understood by sig-convert
+const (
+	// sigFormatDER is an ASN.1 SEQUENCE { r INTEGER, s INTEGER }
+	sigFormatDER = "der"
+	// sigFormatRaw64 is r || s, each as 32 big-endian bytes
+	sigFormatRaw64 = "raw64"
+	// sigFormatRaw65 is r || s || v, where v is the recovery id
+	sigFormatRaw65 = "raw65"
+)
+
+// rawSignature holds the components of an ECDSA signature independently of any encoding.
+type rawSignature struct {
+	R, S [32]byte
+	// V is the recovery id (0 or 1), or -1 when the encoding doesn't carry it.
+	V int
+}
+
+type derSignature struct {
+	R, S *big.Int
+}
+
+// parseSignature decodes data according to format.
+func parseSignature(data []byte, format string) (*rawSignature, error) {
+	sig := &rawSignature{V: -1}
+	switch format {
+	case sigFormatDER:
+		var der derSignature
+		rest, err := asn1.Unmarshal(data, &der)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DER signature: %w", err)
+		}
+		if len(rest) != 0 {
+			return nil, fmt.Errorf("invalid DER signature: %d trailing bytes", len(rest))
+		}
+		for _, v := range []*big.Int{der.R, der.S} {
+			if v.Sign() <= 0 || v.BitLen() > 256 {
+				return nil, errors.New("invalid DER signature: r and s must be positive 256-bit integers")
+			}
+		}
+		der.R.FillBytes(sig.R[:])
+		der.S.FillBytes(sig.S[:])
+	case sigFormatRaw64:
+		if len(data) != 64 {
+			return nil, fmt.Errorf("raw64 signature must be 64 bytes, got %d", len(data))
+		}
+		copy(sig.R[:], data[:32])
+		copy(sig.S[:], data[32:])
+	case sigFormatRaw65:
+		if len(data) != 65 {
+			return nil, fmt.Errorf("raw65 signature must be 65 bytes, got %d", len(data))
+		}
+		copy(sig.R[:], data[:32])
+		copy(sig.S[:], data[32:64])
+		// accept both the raw recovery id and the legacy Ethereum offset of 27
+		switch v := data[64]; v {
+		case 0, 1:
+			sig.V = int(v)
+		case 27, 28:
+			sig.V = int(v - 27)
+		default:
+			return nil, fmt.Errorf("invalid recovery id %d", v)
+		}
+	default:
+		return nil, fmt.Errorf("unknown signature format: %s", format)
+	}
+	return sig, nil
+}
+
+// encodeSignature encodes sig according to format.
+//
+// Encoding to raw65 requires the recovery id, which is computed from publicKey and digest
+// when the source encoding didn't carry it.
+func encodeSignature(sig *rawSignature, format string, publicKey curve.Point, digest []byte) ([]byte, error) {
+	switch format {
+	case sigFormatDER:
+		return asn1.Marshal(derSignature{
+			R: new(big.Int).SetBytes(sig.R[:]),
+			S: new(big.Int).SetBytes(sig.S[:]),
+		})
+	case sigFormatRaw64:
+		out := make([]byte, 0, 64)
+		out = append(out, sig.R[:]...)
+		return append(out, sig.S[:]...), nil
+	case sigFormatRaw65:
+		v := sig.V
+		if v < 0 {
+			if publicKey == nil || digest == nil {
+				return nil, errors.New("converting to raw65 requires the recovery id: provide --pubkey and --digest to compute it")
+			}
+			var err error
+			if v, err = recoveryID(sig, publicKey, digest); err != nil {
+				return nil, err
+			}
+		}
+		out := make([]byte, 0, 65)
+		out = append(out, sig.R[:]...)
+		out = append(out, sig.S[:]...)
+		return append(out, byte(v)), nil
+	default:
+		return nil, fmt.Errorf("unknown signature format: %s", format)
+	}
+}
+
+// recoveryID finds the parity of the nonce point R for which sig verifies under publicKey and digest.
+func recoveryID(sig *rawSignature, publicKey curve.Point, digest []byte) (int, error) {
+	group := curve.Secp256k1{}
+	s := group.NewScalar()
+	if err := s.UnmarshalBinary(sig.S[:]); err != nil {
+		return 0, fmt.Errorf("invalid s: %w", err)
+	}
+	for v := 0; v < 2; v++ {
+		R := group.NewPoint()
+		if err := R.UnmarshalBinary(append([]byte{0x02 + byte(v)}, sig.R[:]...)); err != nil {
+			return 0, fmt.Errorf("r is not the x coordinate of a curve point: %w", err)
+		}
+		if (ecdsa.Signature{R: R, S: s}).Verify(publicKey, digest) {
+			return v, nil
+		}
+	}
+	return 0, errors.New("signature does not verify under the given public key and digest")
+}
+
+// decodeHexOrBinary returns the hex decoding of data if data is a hex string, and data itself otherwise.
+func decodeHexOrBinary(data []byte) []byte {
+	trimmed := strings.TrimPrefix(strings.TrimSpace(string(data)), "0x")
+	if decoded, err := hex.DecodeString(trimmed); err == nil && len(decoded) > 0 {
+		return decoded
+	}
+	return data
+}
+
+// readPublicKey reads a hex encoded SEC 1 public key on group.
+func readPublicKey(path string, group curve.Curve) (curve.Point, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key: %w", err)
+	}
+	pkHex := strings.Trim(strings.TrimSpace(string(data)), `"`)
+	pkBytes, err := hex.DecodeString(pkHex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode public key: %w", err)
+	}
+	publicKey, err := decodePoint(group, pkBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s public key: %w", group.Name(), err)
+	}
+	return publicKey, nil
+}
+
+func runSigConvert(cmd *cobra.Command, args []string) error {
+	in, _ := cmd.Flags().GetString("in")
+	out, _ := cmd.Flags().GetString("out")
+	from, _ := cmd.Flags().GetString("from")
+	to, _ := cmd.Flags().GetString("to")
+	pkFile, _ := cmd.Flags().GetString("pubkey")
+	digestHex, _ := cmd.Flags().GetString("digest")
+
+	data, err := os.ReadFile(in)
+	if err != nil {
+		return fmt.Errorf("failed to read signature: %w", err)
+	}
+	sig, err := parseSignature(decodeHexOrBinary(data), from)
+	if err != nil {
+		return err
+	}
+
+	var publicKey curve.Point
+	if pkFile != "" {
+		if publicKey, err = readPublicKey(pkFile, curve.Secp256k1{}); err != nil {
+			return err
+		}
+	}
+	var digest []byte
+	if digestHex != "" {
+		if digest, err = hex.DecodeString(digestHex); err != nil {
+			return fmt.Errorf("failed to decode digest: %w", err)
+		}
+	}
+
+	converted, err := encodeSignature(sig, to, publicKey, digest)
+	if err != nil {
+		return err
+	}
+
+	encoded := hex.EncodeToString(converted)
+	if out == "" {
+		fmt.Println(encoded)
+		return nil
+	}
+	if err := os.WriteFile(out, []byte(encoded+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write signature: %w", err)
+	}
+	fmt.Printf("Signature converted from %s to %s and saved to: %s\n", from, to, out)
+	return nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/luxfi/threshold/pkg/ecdsa"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/math/sample"
+	"github.com/luxfi/threshold/protocols/frost"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSignature signs digest with a fresh key, returning the signature in raw64 form and the public key.
+func testSignature(t *testing.T, digest []byte) ([]byte, curve.Point) {
+	group := curve.Secp256k1{}
+	x := sample.Scalar(rand.Reader, group)
+	k := sample.Scalar(rand.Reader, group)
+	R := k.ActOnBase()
+
+	// s = k⁻¹(m + r x)
+	r := R.XScalar()
+	s := group.NewScalar().Set(r).Mul(x).Add(curve.FromHash(group, digest))
+	s.Mul(group.NewScalar().Set(k).Invert())
+
+	rBytes, err := r.MarshalBinary()
+	require.NoError(t, err)
+	sBytes, err := s.MarshalBinary()
+	require.NoError(t, err)
+	return append(rBytes, sBytes...), x.ActOnBase()
+}
+
+func TestSigConvertRoundTrip(t *testing.T) {
+	digest := sha256.Sum256([]byte("hello"))
+	raw64, publicKey := testSignature(t, digest[:])
+
+	sig, err := parseSignature(raw64, sigFormatRaw64)
+	require.NoError(t, err)
+
+	der, err := encodeSignature(sig, sigFormatDER, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, byte(0x30), der[0], "DER signature should be a SEQUENCE")
+
+	fromDER, err := parseSignature(der, sigFormatDER)
+	require.NoError(t, err)
+	back, err := encodeSignature(fromDER, sigFormatRaw64, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, raw64, back)
+
+	raw65, err := encodeSignature(fromDER, sigFormatRaw65, publicKey, digest[:])
+	require.NoError(t, err)
+	require.Len(t, raw65, 65)
+	assert.Equal(t, raw64, raw65[:64])
+
+	// the recovery id must select the R point which makes the signature verify
+	fromRaw65, err := parseSignature(raw65, sigFormatRaw65)
+	require.NoError(t, err)
+	v, err := recoveryID(fromRaw65, publicKey, digest[:])
+	require.NoError(t, err)
+	assert.Equal(t, fromRaw65.V, v)
+
+	// raw65 → raw64 → raw65 keeps the recovery id without needing the public key again
+	der2, err := encodeSignature(fromRaw65, sigFormatDER, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, der, der2)
+	raw65Again, err := encodeSignature(fromRaw65, sigFormatRaw65, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, raw65, raw65Again)
+}
+
+func TestSigConvertErrors(t *testing.T) {
+	digest := sha256.Sum256([]byte("hello"))
+	raw64, publicKey := testSignature(t, digest[:])
+
+	sig, err := parseSignature(raw64, sigFormatRaw64)
+	require.NoError(t, err)
+
+	_, err = encodeSignature(sig, sigFormatRaw65, nil, nil)
+	assert.ErrorContains(t, err, "--pubkey")
+
+	_, otherKey := testSignature(t, digest[:])
+	_, err = encodeSignature(sig, sigFormatRaw65, otherKey, digest[:])
+	assert.Error(t, err, "wrong public key should not yield a recovery id")
+	_, err = encodeSignature(sig, sigFormatRaw65, publicKey, make([]byte, 32))
+	assert.Error(t, err, "wrong digest should not yield a recovery id")
+
+	_, err = parseSignature(raw64[:63], sigFormatRaw64)
+	assert.Error(t, err)
+	_, err = parseSignature(append(raw64, 5), sigFormatRaw65)
+	assert.Error(t, err, "invalid recovery id")
+	_, err = parseSignature(raw64, sigFormatDER)
+	assert.Error(t, err)
+	_, err = parseSignature(raw64, "pem")
+	assert.Error(t, err)
+}
+
+func TestDecodeHexOrBinary(t *testing.T) {
+	assert.Equal(t, []byte{0xde, 0xad}, decodeHexOrBinary([]byte("0xdead\n")))
+	binary := []byte{0x30, 0x06, 0x02, 0x01, 0x01, 0x02, 0x01, 0x01}
+	assert.Equal(t, binary, decodeHexOrBinary(binary))
+}
+
+func TestEncodeSignOutput(t *testing.T) {
+	group := curve.Secp256k1{}
+	digest := sha256.Sum256([]byte("hello"))
+	x := sample.Scalar(rand.Reader, group)
+	k := sample.Scalar(rand.Reader, group)
+	R := k.ActOnBase()
+	s := group.NewScalar().Set(R.XScalar()).Mul(x).Add(curve.FromHash(group, digest[:]))
+	s.Mul(group.NewScalar().Set(k).Invert())
+	sig := &ecdsa.Signature{R: R, S: s}
+
+	out, err := encodeSignOutput(sig, signFormatEthereum)
+	require.NoError(t, err)
+	rsv, err := hex.DecodeString(strings.TrimSpace(string(out)))
+	require.NoError(t, err)
+	require.Len(t, rsv, 65)
+
+	address, err := ecdsa.EthereumAddress(x.ActOnBase())
+	require.NoError(t, err)
+	recovered, err := ecdsa.RecoverEthereumAddress(digest[:], rsv)
+	require.NoError(t, err)
+	assert.Equal(t, address, recovered)
+
+	_, err = encodeSignOutput(&frost.Signature{}, signFormatEthereum)
+	assert.Error(t, err, "Schnorr signatures have no Ethereum encoding")
+
+	out, err = encodeSignOutput(sig, signFormatBitcoinDER)
+	require.NoError(t, err)
+	der, err := hex.DecodeString(strings.TrimSpace(string(out)))
+	require.NoError(t, err)
+	parsed, err := parseSignature(der, sigFormatDER)
+	require.NoError(t, err)
+	assert.Equal(t, rsv[:32], parsed.R[:])
+	assert.Equal(t, rsv[32:64], parsed.S[:], "both encodings use low s")
+}
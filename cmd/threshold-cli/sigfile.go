@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/luxfi/threshold/pkg/ecdsa"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/taproot"
+	"github.com/luxfi/threshold/protocols/frost"
+)
+
+// Signature schemes recorded in the header of a signature file
+const (
+	schemeECDSA   = "ecdsa"
+	schemeSchnorr = "schnorr"
+)
+
+// signatureFile is a JSON signature file, whose header records how to verify the signature,
+// so that verify needs neither --protocol nor --curve.
+type signatureFile struct {
+	// Scheme is schemeECDSA or schemeSchnorr
+	Scheme string `json:"scheme"`
+	// Curve is the name of the curve, as accepted by --curve
+	Curve string `json:"curve"`
+	// Signature is the JSON encoding of the signature, as written before signatures had a header
+	Signature json.RawMessage `json:"signature"`
+}
+
+// newSignatureFile wraps signature with its header, and returns nil for signatures without one.
+//
+// Taproot signatures are Schnorr signatures on secp256k1 too, written in hex like FROST signatures,
+// and verify tells them apart by their 64 bytes, against FROST's 65.
+func newSignatureFile(signature interface{}) (*signatureFile, error) {
+	var scheme string
+	var group curve.Curve
+	switch sig := signature.(type) {
+	case taproot.Signature:
+		data, err := json.Marshal(hex.EncodeToString(sig))
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal signature: %w", err)
+		}
+		return &signatureFile{Scheme: schemeSchnorr, Curve: curve.Secp256k1{}.Name(), Signature: data}, nil
+	case *ecdsa.Signature:
+		scheme, group = schemeECDSA, sig.R.Curve()
+	case *frost.Signature:
+		scheme, group = schemeSchnorr, sig.R.Curve()
+	case frost.Signature:
+		scheme, group = schemeSchnorr, sig.R.Curve()
+	default:
+		return nil, nil
+	}
+	data, err := json.Marshal(signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal signature: %w", err)
+	}
+	return &signatureFile{Scheme: scheme, Curve: group.Name(), Signature: data}, nil
+}
+
+// readSignatureFile decodes the header of a signature file, and returns nil if data is a bare signature.
+func readSignatureFile(data []byte) (*signatureFile, error) {
+	if !hasSignatureHeader(data) {
+		return nil, nil
+	}
+	var file signatureFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal signature file: %w", err)
+	}
+	return &file, nil
+}
+
+// hasSignatureHeader reports whether data is a signature file with a header.
+// A bare ECDSA signature is an object too, but has no scheme.
+func hasSignatureHeader(data []byte) bool {
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return false
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return false
+	}
+	_, ok := fields["scheme"]
+	return ok
+}
+
+// protocol returns a protocol producing signatures of the scheme of the file,
+// which selects the signature schema and the default message hash.
+func (f *signatureFile) protocol() (string, error) {
+	switch f.Scheme {
+	case schemeECDSA:
+		return "cmp", nil
+	case schemeSchnorr:
+		return "frost", nil
+	default:
+		return "", fmt.Errorf("unknown signature scheme %q", f.Scheme)
+	}
+}
+
+// schemeOf returns the scheme of the signatures protocol produces.
+func schemeOf(protocol string) string {
+	if protocol == "frost" {
+		return schemeSchnorr
+	}
+	return schemeECDSA
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/pkg/ecdsa"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/pool"
+	"github.com/luxfi/threshold/pkg/protocol"
+	"github.com/luxfi/threshold/protocols/frost"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMessages(t *testing.T) {
+	expected := [][]byte{{0xde, 0xad}, {0xbe, 0xef}, {}}
+
+	messages, err := parseMessages([]byte("dead\n\n  beef \r\n"))
+	require.NoError(t, err)
+	assert.Equal(t, expected[:2], messages)
+
+	messages, err = parseMessages([]byte(` ["dead", "beef", ""]`))
+	require.NoError(t, err)
+	assert.Equal(t, expected, messages)
+
+	_, err = parseMessages([]byte("\n \n"))
+	assert.ErrorContains(t, err, "no messages")
+	_, err = parseMessages([]byte("dead\nnot hex"))
+	assert.ErrorContains(t, err, "message 1")
+	_, err = parseMessages([]byte(`["dead", 1]`))
+	assert.Error(t, err)
+}
+
+func TestSignMessagesFileCMP(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	group := curve.Secp256k1{}
+	configs, partyIDs := test.GenerateConfig(group, 3, 1, rand.Reader, pl)
+	signers := []party.ID{partyIDs[0], partyIDs[2]}
+
+	lines := make([]string, 5)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("%x", fmt.Sprintf("transaction %d", i))
+	}
+	messages, err := parseMessages([]byte(strings.Join(lines, "\n")))
+	require.NoError(t, err)
+	digests := make([][]byte, len(messages))
+	for i, message := range messages {
+		digests[i], err = messageDigest(message, "", "cmp")
+		require.NoError(t, err)
+	}
+
+	sessionID, err := protocol.NewSessionID()
+	require.NoError(t, err)
+	network := test.NewNetwork(signers)
+	results := make(map[party.ID][]*ecdsa.Signature, len(signers))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, id := range signers {
+		wg.Add(1)
+		go func(id party.ID) {
+			defer wg.Done()
+			signatures, err := runCMPSignBatch(configs[id], signers, digests, pl, sessionID, network.Transport(id))
+			assert.NoError(t, err)
+			mu.Lock()
+			results[id] = signatures
+			mu.Unlock()
+		}(id)
+	}
+	wg.Wait()
+
+	publicKey := configs[partyIDs[0]].PublicPoint()
+	for _, id := range signers {
+		require.Len(t, results[id], len(messages))
+		for i, sig := range results[id] {
+			hash := sha256.Sum256([]byte(fmt.Sprintf("transaction %d", i)))
+			assert.True(t, sig.Verify(publicKey, hash[:]), "signature %d of %s", i, id)
+		}
+	}
+
+	pkData := []byte(hex.EncodeToString(must(publicKey.MarshalBinary())))
+	// the output is an array of signature files, in the order of the messages
+	signatures := make([]interface{}, len(messages))
+	for i, sig := range results[signers[0]] {
+		signatures[i] = sig
+	}
+	data, err := encodeSignOutputs(signatures, signFormatJSON)
+	require.NoError(t, err)
+	var files []json.RawMessage
+	require.NoError(t, json.Unmarshal(data, &files))
+	require.Len(t, files, len(messages))
+	for i, file := range files {
+		require.NoError(t, validateFile(file, "signature", "cmp"))
+		header, err := readSignatureFile(file)
+		require.NoError(t, err)
+		valid, err := verifyECDSA(header.Signature, pkData, digests[i], group, false)
+		require.NoError(t, err)
+		assert.True(t, valid)
+	}
+
+	data, err = encodeSignOutputs(signatures, signFormatEthereum)
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(string(data)), "\n"), len(messages))
+}
+
+// TestSignDigestCLI signs a Keccak-256 digest with CMP and FROST, as sign --hash keccak256 does,
+// and checks that the digest is signed without hashing it again.
+func TestSignDigestCLI(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	group := curve.Secp256k1{}
+	digest, err := messageDigest([]byte("hello"), hashKeccak256, "cmp")
+	require.NoError(t, err)
+
+	t.Run("cmp", func(t *testing.T) {
+		configs, partyIDs := test.GenerateConfig(group, 3, 1, rand.Reader, pl)
+		signers := partyIDs[:2]
+		sessionID, err := protocol.NewSessionID()
+		require.NoError(t, err)
+		network := test.NewNetwork(signers)
+		signatures := make([]*ecdsa.Signature, len(signers))
+		var wg sync.WaitGroup
+		for i, id := range signers {
+			wg.Add(1)
+			go func(i int, id party.ID) {
+				defer wg.Done()
+				var err error
+				signatures[i], err = runCMPSign(configs[id], signers, digest, pl, sessionID, network.Transport(id))
+				assert.NoError(t, err)
+			}(i, id)
+		}
+		wg.Wait()
+		for _, sig := range signatures {
+			require.NotNil(t, sig)
+			assert.True(t, sig.Verify(configs[partyIDs[0]].PublicPoint(), digest))
+		}
+
+		_, err = runCMPSign(configs[partyIDs[0]], signers, digest[:31], pl, sessionID, network.Transport(partyIDs[0]))
+		assert.ErrorContains(t, err, "32 byte digest")
+	})
+
+	t.Run("frost", func(t *testing.T) {
+		partyIDs := test.PartyIDs(3)
+		network := test.NewNetwork(partyIDs)
+		configs := make([]*frost.Config, len(partyIDs))
+		var wg sync.WaitGroup
+		for i, id := range partyIDs {
+			wg.Add(1)
+			go func(i int, id party.ID) {
+				defer wg.Done()
+				var err error
+				configs[i], err = runFROSTKeygen(group, id, partyIDs, 1, pl, network.Transport(id))
+				assert.NoError(t, err)
+			}(i, id)
+		}
+		wg.Wait()
+
+		signers := partyIDs[:2]
+		sessionID, err := protocol.NewSessionID()
+		require.NoError(t, err)
+		network = test.NewNetwork(signers)
+		signatures := make([]*frost.Signature, len(signers))
+		for i, id := range signers {
+			wg.Add(1)
+			go func(i int, id party.ID) {
+				defer wg.Done()
+				var err error
+				signatures[i], err = runFROSTSign(configs[i], signers, digest, true, sessionID, network.Transport(id))
+				assert.NoError(t, err)
+			}(i, id)
+		}
+		wg.Wait()
+		for _, sig := range signatures {
+			require.NotNil(t, sig)
+			assert.True(t, sig.Verify(configs[0].PublicKey, digest))
+		}
+
+		_, err = runFROSTSign(configs[0], signers, []byte("hello"), true, sessionID, network.Transport(signers[0]))
+		assert.ErrorContains(t, err, "32 bytes")
+	})
+}
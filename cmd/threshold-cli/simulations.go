@@ -1,12 +1,17 @@
 package main
 
 import (
+	"context"
 	"crypto/rand"
+	"encoding/csv"
 	"fmt"
+	"io"
 	"math"
 	mathrand "math/rand"
+	"strconv"
 	"sync"
 	"sync/atomic"
+	"text/tabwriter"
 	"time"
 
 	"runtime"
@@ -198,66 +203,268 @@ func simulateConcurrentSigning(protocolName string, rounds int) error {
 	return nil
 }
 
-func simulateLargeScale(protocolName string, rounds int) error {
-	fmt.Printf("\n=== Large Scale Simulation ===\n")
-	fmt.Printf("Protocol: %s\n", protocolName)
-	fmt.Printf("Rounds: %d\n", rounds)
+// largeScaleSizes are the party counts and thresholds swept by the large-scale scenario.
+var largeScaleSizes = []struct{ n, threshold int }{
+	{11, 6},
+	{15, 8},
+	{21, 11},
+	{31, 16},
+	{51, 26},
+}
 
-	testCases := []struct {
-		n         int
-		threshold int
-	}{
-		{11, 6},
-		{15, 8},
-		{21, 11},
-		{31, 16},
-		{51, 26},
+// memorySampleInterval is how often the heap is sampled while an operation runs, to find its peak.
+const memorySampleInterval = 5 * time.Millisecond
+
+// memoryUsage is the memory used by the runs of one operation.
+type memoryUsage struct {
+	// PeakHeap is the largest heap seen during any run, in bytes.
+	PeakHeap uint64
+	// Allocs and Bytes are the heap allocations of all runs, in objects and in bytes.
+	Allocs uint64
+	Bytes  uint64
+	Runs   uint64
+}
+
+// AllocsPerRun is the number of heap objects allocated by a run, on average.
+func (m memoryUsage) AllocsPerRun() uint64 {
+	if m.Runs == 0 {
+		return 0
 	}
+	return m.Allocs / m.Runs
+}
 
-	for _, tc := range testCases {
-		fmt.Printf("\n\nTesting %d-of-%d configuration...\n", tc.threshold, tc.n)
+// BytesPerRun is the number of heap bytes allocated by a run, on average.
+func (m memoryUsage) BytesPerRun() uint64 {
+	if m.Runs == 0 {
+		return 0
+	}
+	return m.Bytes / m.Runs
+}
+
+// String implements fmt.Stringer.
+func (m memoryUsage) String() string {
+	return fmt.Sprintf("peak heap %s, %d allocs (%s) per run", formatMB(m.PeakHeap), m.AllocsPerRun(), formatMB(m.BytesPerRun()))
+}
 
-		// Memory usage before
-		var m1 runtime.MemStats
-		runtime.ReadMemStats(&m1)
+func formatMB(bytes uint64) string {
+	return fmt.Sprintf("%.1f MB", float64(bytes)/(1<<20))
+}
 
-		// Run simulation
-		totalTime := time.Duration(0)
-		successCount := 0
+// measureMemory runs op and adds its allocations and peak heap to usage.
+// The heap is collected first, so that the peak isn't inflated by the garbage of earlier runs.
+func measureMemory(usage *memoryUsage, op func() error) error {
+	runtime.GC()
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
 
+	done := make(chan struct{})
+	sampled := make(chan uint64)
+	go func() {
+		ticker := time.NewTicker(memorySampleInterval)
+		defer ticker.Stop()
+		var peak uint64
+		var m runtime.MemStats
+		for {
+			select {
+			case <-done:
+				sampled <- peak
+				return
+			case <-ticker.C:
+				runtime.ReadMemStats(&m)
+				peak = max(peak, m.HeapAlloc)
+			}
+		}
+	}()
+	err := op()
+	close(done)
+	peak := <-sampled
+
+	var after runtime.MemStats
+	runtime.ReadMemStats(&after)
+	usage.PeakHeap = max(usage.PeakHeap, before.HeapAlloc, peak, after.HeapAlloc)
+	usage.Allocs += after.Mallocs - before.Mallocs
+	usage.Bytes += after.TotalAlloc - before.TotalAlloc
+	usage.Runs++
+	return err
+}
+
+// largeScaleResult is the outcome of the runs of one party count in the large-scale scenario.
+type largeScaleResult struct {
+	Parties   int
+	Threshold int
+	Runs      int
+	Successes int
+	// Time is the total time of the runs, keygen and signing included.
+	Time   time.Duration
+	Keygen memoryUsage
+	Sign   memoryUsage
+}
+
+// simulateLargeScale runs rounds keygens and signs for each size, and writes the time and memory they took to w,
+// keygen and signing apart, ending with a table comparing the sizes.
+func simulateLargeScale(w io.Writer, protocolName string, rounds int, sizes []struct{ n, threshold int }) ([]largeScaleResult, error) {
+	fmt.Fprintf(w, "\n=== Large Scale Simulation ===\n")
+	fmt.Fprintf(w, "Protocol: %s\n", protocolName)
+	fmt.Fprintf(w, "Rounds: %d\n", rounds)
+	if rounds < 1 {
+		return nil, fmt.Errorf("--rounds must be at least 1")
+	}
+
+	results := make([]largeScaleResult, 0, len(sizes))
+	for _, tc := range sizes {
+		fmt.Fprintf(w, "\n\nTesting %d-of-%d configuration...\n", tc.threshold, tc.n)
+
+		result := largeScaleResult{Parties: tc.n, Threshold: tc.threshold, Runs: rounds}
 		for round := 0; round < rounds; round++ {
 			start := time.Now()
 
-			err := runLargeScaleRound(protocolName, tc.n, tc.threshold)
+			err := runLargeScaleRound(protocolName, tc.n, tc.threshold, &result.Keygen, &result.Sign)
 
-			elapsed := time.Since(start)
-			totalTime += elapsed
+			result.Time += time.Since(start)
 
 			if err == nil {
-				successCount++
+				result.Successes++
 			}
 
 			if round%5 == 0 {
-				fmt.Printf("\r  Progress: %d/%d (%.2f%% success)",
-					round, rounds, float64(successCount)/float64(round+1)*100)
+				fmt.Fprintf(w, "\r  Progress: %d/%d (%.2f%% success)",
+					round, rounds, float64(result.Successes)/float64(round+1)*100)
 			}
 		}
 
-		// Memory usage after
-		var m2 runtime.MemStats
-		runtime.ReadMemStats(&m2)
+		avgTime := result.Time / time.Duration(rounds)
 
-		avgTime := totalTime / time.Duration(rounds)
-		memUsed := (m2.Alloc - m1.Alloc) / 1024 / 1024 // MB
+		fmt.Fprintf(w, "\n  === Results for %d-of-%d ===\n", tc.threshold, tc.n)
+		fmt.Fprintf(w, "  Success rate: %.2f%%\n", float64(result.Successes)/float64(rounds)*100)
+		fmt.Fprintf(w, "  Average time: %v\n", avgTime)
+		fmt.Fprintf(w, "  Keygen memory: %s\n", result.Keygen)
+		fmt.Fprintf(w, "  Sign memory: %s\n", result.Sign)
+		fmt.Fprintf(w, "  Time per party: %v\n", avgTime/time.Duration(tc.n))
+		results = append(results, result)
+	}
 
-		fmt.Printf("\n  === Results for %d-of-%d ===\n", tc.threshold, tc.n)
-		fmt.Printf("  Success rate: %.2f%%\n", float64(successCount)/float64(rounds)*100)
-		fmt.Printf("  Average time: %v\n", avgTime)
-		fmt.Printf("  Memory used: ~%d MB\n", memUsed)
-		fmt.Printf("  Time per party: %v\n", avgTime/time.Duration(tc.n))
+	fmt.Fprintf(w, "\n=== Memory by party count (%s) ===\n", protocolName)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PARTIES\tTHRESHOLD\tKEYGEN PEAK\tKEYGEN ALLOCS\tKEYGEN ALLOC BYTES\tSIGN PEAK\tSIGN ALLOCS\tSIGN ALLOC BYTES")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%d\t%s\t%s\t%d\t%s\n", r.Parties, r.Threshold,
+			formatMB(r.Keygen.PeakHeap), r.Keygen.AllocsPerRun(), formatMB(r.Keygen.BytesPerRun()),
+			formatMB(r.Sign.PeakHeap), r.Sign.AllocsPerRun(), formatMB(r.Sign.BytesPerRun()))
+	}
+	if err := tw.Flush(); err != nil {
+		return nil, err
 	}
+	return results, nil
+}
 
-	return nil
+// messageLossRates are the rates swept by the message-loss scenario.
+var messageLossRates = []float64{0, 0.1, 0.2, 0.3, 0.4, 0.5}
+
+// lossResult is the outcome of the runs of one operation at one message-loss rate.
+type lossResult struct {
+	Operation string
+	Rate      float64
+	Runs      int
+	Successes int
+}
+
+// SuccessRate is the fraction of the runs in which every party finished the protocol.
+func (r lossResult) SuccessRate() float64 {
+	if r.Runs == 0 {
+		return 0
+	}
+	return float64(r.Successes) / float64(r.Runs)
+}
+
+// simulateMessageLoss runs rounds keygens and signs at each loss rate, over a network dropping each message
+// for each recipient with that probability, and writes the success rate of every operation and rate to w as CSV.
+//
+// A run fails if any party fails to finish within timeout.
+func simulateMessageLoss(w io.Writer, protocolName string, rounds int, rates []float64, timeout time.Duration) ([]lossResult, error) {
+	n := 5
+	threshold := 3
+
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	partyIDs := test.PartyIDs(n)
+	group := curve.Secp256k1{}
+
+	// the signing key is generated once, over a reliable network
+	configs, err := setupSimulationConfigs(protocolName, n, threshold, pl, test.NewNetwork(partyIDs), group)
+	if err != nil {
+		return nil, fmt.Errorf("setup failed: %w", err)
+	}
+	message := make([]byte, 32)
+	if _, err := rand.Read(message); err != nil {
+		return nil, err
+	}
+
+	operations := []struct {
+		name  string
+		start func(i int, id party.ID) protocol.StartFunc
+	}{
+		{"keygen", func(_ int, id party.ID) protocol.StartFunc {
+			switch protocolName {
+			case "lss":
+				return lss.Keygen(group, id, partyIDs, threshold, pl)
+			case "cmp":
+				return cmp.Keygen(group, id, partyIDs, threshold, pl)
+			default:
+				return frost.Keygen(group, id, partyIDs, threshold)
+			}
+		}},
+		{"sign", func(i int, _ party.ID) protocol.StartFunc {
+			switch protocolName {
+			case "lss":
+				return lss.Sign(configs[i].(*lss.Config), partyIDs, message, pl)
+			case "cmp":
+				return cmp.Sign(configs[i].(*cmp.Config), partyIDs, message, pl)
+			default:
+				return frost.Sign(configs[i].(*frost.Config), partyIDs, message)
+			}
+		}},
+	}
+
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"protocol", "operation", "loss_rate", "runs", "successes", "success_rate"}); err != nil {
+		return nil, err
+	}
+
+	var results []lossResult
+	for _, op := range operations {
+		for _, rate := range rates {
+			result := lossResult{Operation: op.name, Rate: rate, Runs: rounds}
+			for round := 0; round < rounds; round++ {
+				network := test.NewNetworkWithOptions(partyIDs, test.Options{DropProbability: rate})
+				ok, err := runLossyRound(partyIDs, op.start, network, timeout)
+				if err != nil {
+					return nil, fmt.Errorf("simulation error: %w", err)
+				}
+				if ok {
+					result.Successes++
+				}
+			}
+			results = append(results, result)
+
+			if err := out.Write([]string{
+				protocolName,
+				result.Operation,
+				strconv.FormatFloat(result.Rate, 'f', 2, 64),
+				strconv.Itoa(result.Runs),
+				strconv.Itoa(result.Successes),
+				strconv.FormatFloat(result.SuccessRate(), 'f', 4, 64),
+			}); err != nil {
+				return nil, err
+			}
+			// flush each line, so that long sweeps can be followed
+			out.Flush()
+			if err := out.Error(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return results, nil
 }
 
 // Helper functions
@@ -305,15 +512,9 @@ func runNetworkFailureRound(protocolName string, n, threshold int, failureRate f
 
 	partyIDs := test.PartyIDs(n)
 
-	// Create unreliable network
-	unreliableNetwork := &UnreliableNetwork{
-		Network:     test.NewNetwork(partyIDs),
-		FailureRate: failureRate,
-	}
-
 	group := curve.Secp256k1{}
 
-	configs, err := setupSimulationConfigs(protocolName, n, threshold, pl, unreliableNetwork.Network, group)
+	configs, err := setupSimulationConfigs(protocolName, n, threshold, pl, test.NewNetwork(partyIDs), group)
 	if err != nil {
 		return "failure", err
 	}
@@ -323,6 +524,16 @@ func runNetworkFailureRound(protocolName string, n, threshold int, failureRate f
 	if _, err := rand.Read(message); err != nil {
 		return "failure", err
 	}
+	sessionID, err := protocol.NewSessionID()
+	if err != nil {
+		return "failure", err
+	}
+
+	// Sign over an unreliable network, which drops and delays messages
+	unreliableNetwork := test.NewNetworkWithOptions(partyIDs, test.Options{
+		MaxDelay:        100 * time.Millisecond,
+		DropProbability: failureRate,
+	})
 
 	successCount := 0
 	var wg sync.WaitGroup
@@ -332,7 +543,7 @@ func runNetworkFailureRound(protocolName string, n, threshold int, failureRate f
 		go func(idx int, cfg interface{}) {
 			defer wg.Done()
 
-			err := attemptSignWithConfig(protocolName, cfg, partyIDs, message, pl, unreliableNetwork.Network)
+			err := attemptSignWithConfig(protocolName, cfg, partyIDs, message, sessionID, pl, unreliableNetwork)
 			if err == nil {
 				successCount++
 			}
@@ -350,7 +561,8 @@ func runNetworkFailureRound(protocolName string, n, threshold int, failureRate f
 	return "failure", nil
 }
 
-func runLargeScaleRound(protocolName string, n, threshold int) error {
+// runLargeScaleRound runs a keygen and a sign with threshold + 1 signers, adding the memory they used to keygen and sign.
+func runLargeScaleRound(protocolName string, n, threshold int, keygen, sign *memoryUsage) error {
 	pl := pool.NewPool(0)
 	defer pl.TearDown()
 
@@ -359,7 +571,12 @@ func runLargeScaleRound(protocolName string, n, threshold int) error {
 	group := curve.Secp256k1{}
 
 	// Keygen
-	configs, err := setupSimulationConfigs(protocolName, n, threshold, pl, network, group)
+	var configs []interface{}
+	err := measureMemory(keygen, func() error {
+		var err error
+		configs, err = setupSimulationConfigs(protocolName, n, threshold, pl, network, group)
+		return err
+	})
 	if err != nil {
 		return err
 	}
@@ -370,7 +587,46 @@ func runLargeScaleRound(protocolName string, n, threshold int) error {
 		return err
 	}
 
-	return runSingleSign(protocolName, configs[:threshold], message)
+	// a sign session of any of the protocols needs threshold + 1 parties
+	return measureMemory(sign, func() error {
+		return runSingleSign(protocolName, configs[:threshold+1], message)
+	})
+}
+
+// runLossyRound runs the protocol started by start for every party over network,
+// and reports whether all parties finished within timeout.
+func runLossyRound(partyIDs []party.ID, start func(i int, id party.ID) protocol.StartFunc, network *test.Network, timeout time.Duration) (bool, error) {
+	sessionID, err := protocol.NewSessionID()
+	if err != nil {
+		return false, err
+	}
+	handlers := make([]*protocol.MultiHandler, len(partyIDs))
+	for i, id := range partyIDs {
+		h, err := protocol.NewMultiHandler(start(i, id), sessionID)
+		if err != nil {
+			return false, err
+		}
+		handlers[i] = h
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var failures int64
+	var wg sync.WaitGroup
+	wg.Add(len(partyIDs))
+	for i, id := range partyIDs {
+		go func(id party.ID, h *protocol.MultiHandler) {
+			defer wg.Done()
+			go test.HandlerLoop(id, h, network)
+			// on timeout, the handler is aborted, which lets its loop finish
+			if _, err := h.ResultWithContext(ctx); err != nil {
+				atomic.AddInt64(&failures, 1)
+			}
+		}(id, handlers[i])
+	}
+	wg.Wait()
+	return failures == 0, nil
 }
 
 func setupSimulationConfigs(protocolName string, n, threshold int, pl *pool.Pool, network *test.Network, group curve.Curve) ([]interface{}, error) {
@@ -426,7 +682,7 @@ func setupSimulationConfigs(protocolName string, n, threshold int, pl *pool.Pool
 	return configs, nil
 }
 
-func attemptSignWithConfig(protocolName string, config interface{}, partyIDs []party.ID, message []byte, pl *pool.Pool, network *test.Network) error {
+func attemptSignWithConfig(protocolName string, config interface{}, partyIDs []party.ID, message, sessionID []byte, pl *pool.Pool, network *test.Network) error {
 	var h protocol.Handler
 	var err error
 	var id party.ID
@@ -435,15 +691,15 @@ func attemptSignWithConfig(protocolName string, config interface{}, partyIDs []p
 	case "lss":
 		c := config.(*lss.Config)
 		id = c.ID
-		h, err = protocol.NewMultiHandler(lss.Sign(c, partyIDs, message, pl), nil)
+		h, err = protocol.NewMultiHandler(lss.Sign(c, partyIDs, message, pl), sessionID)
 	case "cmp":
 		c := config.(*cmp.Config)
 		id = c.ID
-		h, err = protocol.NewMultiHandler(cmp.Sign(c, partyIDs, message, pl), nil)
+		h, err = protocol.NewMultiHandler(cmp.Sign(c, partyIDs, message, pl), sessionID)
 	case "frost":
 		c := config.(*frost.Config)
 		id = c.ID
-		h, err = protocol.NewMultiHandler(frost.Sign(c, partyIDs, message), nil)
+		h, err = protocol.NewMultiHandler(frost.Sign(c, partyIDs, message), sessionID)
 	}
 
 	if err != nil {
@@ -483,21 +739,3 @@ func (b *ByzantineNetwork) Send(msg *protocol.Message) {
 	}
 	b.Network.Send(msg)
 }
-
-type UnreliableNetwork struct {
-	*test.Network
-	FailureRate float64
-}
-
-func (u *UnreliableNetwork) Send(msg *protocol.Message) {
-	// Randomly drop messages
-	if mathrand.Float64() < u.FailureRate {
-		return
-	}
-
-	// Add random delay
-	delay := time.Duration(mathrand.Intn(100)) * time.Millisecond
-	time.Sleep(delay)
-
-	u.Network.Send(msg)
-}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulateMessageLoss(t *testing.T) {
+	var out bytes.Buffer
+	results, err := simulateMessageLoss(&out, "frost", 2, []float64{0, 0.5}, time.Second)
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+
+	for _, operation := range []string{"keygen", "sign"} {
+		rates := make(map[float64]float64)
+		for _, r := range results {
+			if r.Operation == operation {
+				rates[r.Rate] = r.SuccessRate()
+			}
+		}
+		assert.Equal(t, 1.0, rates[0], "%s must succeed without message loss", operation)
+		assert.Less(t, rates[0.5], rates[0], "%s must fail more often when messages are lost", operation)
+	}
+
+	records, err := csv.NewReader(&out).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 5)
+	assert.Equal(t, []string{"protocol", "operation", "loss_rate", "runs", "successes", "success_rate"}, records[0])
+	assert.Equal(t, []string{"frost", "keygen", "0.00", "2", "2", "1.0000"}, records[1])
+}
+
+func TestSimulateLargeScaleMemory(t *testing.T) {
+	var out bytes.Buffer
+	results, err := simulateLargeScale(&out, "frost", 2, []struct{ n, threshold int }{{3, 1}, {5, 2}})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	for _, r := range results {
+		assert.Equal(t, 2, r.Successes, "%d parties", r.Parties)
+		for name, usage := range map[string]memoryUsage{"keygen": r.Keygen, "sign": r.Sign} {
+			assert.EqualValues(t, 2, usage.Runs, "%s with %d parties", name, r.Parties)
+			assert.Positive(t, usage.PeakHeap, "%s with %d parties", name, r.Parties)
+			assert.Positive(t, usage.AllocsPerRun(), "%s with %d parties", name, r.Parties)
+			assert.Positive(t, usage.BytesPerRun(), "%s with %d parties", name, r.Parties)
+		}
+	}
+	// more parties allocate more, in both operations
+	assert.Greater(t, results[1].Keygen.BytesPerRun(), results[0].Keygen.BytesPerRun())
+	assert.Greater(t, results[1].Sign.AllocsPerRun(), results[0].Sign.AllocsPerRun())
+	assert.Contains(t, out.String(), "Keygen memory: peak heap")
+	assert.Contains(t, out.String(), "KEYGEN PEAK")
+
+	_, err = simulateLargeScale(&out, "frost", 0, largeScaleSizes)
+	assert.Error(t, err)
+}
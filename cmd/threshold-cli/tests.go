@@ -248,6 +248,10 @@ func testBasicSignature(protocolName string) error {
 	// Sign
 	message := []byte("test message")
 	signers := partyIDs[:threshold]
+	sessionID, err := protocol.NewSessionID()
+	if err != nil {
+		return err
+	}
 
 	wg.Add(threshold)
 	signatures := make([]interface{}, threshold)
@@ -263,13 +267,13 @@ func testBasicSignature(protocolName string) error {
 			switch protocolName {
 			case "lss":
 				c := configs[i].(*lss.Config)
-				h, err = protocol.NewMultiHandler(lss.Sign(c, signers, message, pl), nil)
+				h, err = protocol.NewMultiHandler(lss.Sign(c, signers, message, pl), sessionID)
 			case "cmp":
 				c := configs[i].(*cmp.Config)
-				h, err = protocol.NewMultiHandler(cmp.Sign(c, signers, message, pl), nil)
+				h, err = protocol.NewMultiHandler(cmp.Sign(c, signers, message, pl), sessionID)
 			case "frost":
 				c := configs[i].(*frost.Config)
-				h, err = protocol.NewMultiHandler(frost.Sign(c, signers, message), nil)
+				h, err = protocol.NewMultiHandler(frost.Sign(c, signers, message), sessionID)
 			}
 
 			if err != nil {
@@ -523,6 +527,10 @@ func performSign(protocolName string, configs []interface{}, signers []party.ID,
 
 	results := make([]interface{}, len(configs))
 	errors := make([]error, len(configs))
+	sessionID, err := protocol.NewSessionID()
+	if err != nil {
+		return nil, err
+	}
 
 	for i, config := range configs {
 		i := i
@@ -535,13 +543,13 @@ func performSign(protocolName string, configs []interface{}, signers []party.ID,
 			switch protocolName {
 			case "lss":
 				c := cfg.(*lss.Config)
-				h, err = protocol.NewMultiHandler(lss.Sign(c, signers, message, pl), nil)
+				h, err = protocol.NewMultiHandler(lss.Sign(c, signers, message, pl), sessionID)
 			case "cmp":
 				c := cfg.(*cmp.Config)
-				h, err = protocol.NewMultiHandler(cmp.Sign(c, signers, message, pl), nil)
+				h, err = protocol.NewMultiHandler(cmp.Sign(c, signers, message, pl), sessionID)
 			case "frost":
 				c := cfg.(*frost.Config)
-				h, err = protocol.NewMultiHandler(frost.Sign(c, signers, message), nil)
+				h, err = protocol.NewMultiHandler(frost.Sign(c, signers, message), sessionID)
 			}
 
 			if err != nil {
@@ -0,0 +1,394 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/luxfi/threshold/internal/rfc6979"
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/pkg/ecdsa"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/math/sample"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/pool"
+	"github.com/luxfi/threshold/pkg/taproot"
+	"github.com/luxfi/threshold/protocols/cmp"
+	"github.com/luxfi/threshold/protocols/frost"
+	"github.com/luxfi/threshold/protocols/frost/keygen"
+	"github.com/luxfi/threshold/protocols/frost/sign"
+	"github.com/luxfi/threshold/protocols/lss"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLSSKeygenP256(t *testing.T) {
+	group, err := getCurve("p256")
+	require.NoError(t, err)
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	partyIDs := test.PartyIDs(3)
+	network := test.NewNetwork(partyIDs)
+	configs := make(chan *lss.Config, len(partyIDs))
+	var wg sync.WaitGroup
+	for _, id := range partyIDs {
+		wg.Add(1)
+		go func(id party.ID) {
+			defer wg.Done()
+			c, err := runLSSKeygen(group, id, partyIDs, 2, pl, network.Transport(id))
+			assert.NoError(t, err)
+			configs <- c
+		}(id)
+	}
+	wg.Wait()
+	close(configs)
+
+	var publicKey curve.Point
+	for c := range configs {
+		require.NotNil(t, c)
+		require.NoError(t, c.Validate())
+		pk, err := c.PublicKey()
+		require.NoError(t, err)
+		assert.Equal(t, "P-256", pk.Curve().Name())
+		if publicKey == nil {
+			publicKey = pk
+		}
+		assert.True(t, publicKey.Equal(pk))
+	}
+}
+
+func TestDecodePublicKey(t *testing.T) {
+	for _, group := range ecdsaCurves {
+		// find a key whose x coordinate is only valid on group
+		var key curve.Point
+		var compressed []byte
+		for n := 0; n != 1; {
+			key = sample.Scalar(rand.Reader, group).ActOnBase()
+			var err error
+			compressed, err = key.MarshalBinary()
+			require.NoError(t, err)
+			n = 0
+			for _, other := range ecdsaCurves {
+				if _, err := decodePoint(other, compressed); err == nil {
+					n++
+				}
+			}
+		}
+
+		// detected from the compressed key regardless of the preferred curve
+		for _, preferred := range ecdsaCurves {
+			decoded, err := decodePublicKey(compressed, preferred)
+			require.NoError(t, err)
+			assert.Equal(t, group.Name(), decoded.Curve().Name())
+			assert.True(t, key.Equal(decoded))
+		}
+	}
+
+	// an uncompressed key always identifies its curve
+	key := sample.Scalar(rand.Reader, curve.P256{}).ActOnBase()
+	std, err := curve.StdPublicKey(key)
+	require.NoError(t, err)
+	ecdhKey, err := std.ECDH()
+	require.NoError(t, err)
+	decoded, err := decodePublicKey(ecdhKey.Bytes(), curve.Secp256k1{})
+	require.NoError(t, err)
+	assert.True(t, key.Equal(decoded))
+
+	_, err = decodePublicKey(make([]byte, 33), curve.Secp256k1{})
+	assert.Error(t, err)
+}
+
+func TestVerifySchnorrEd25519(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	message := []byte("hello")
+	sigData, err := json.Marshal(hex.EncodeToString(ed25519.Sign(private, message)))
+	require.NoError(t, err)
+	pkData := []byte(hex.EncodeToString(public))
+
+	valid, err := verifySchnorr(sigData, pkData, message, nil)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	valid, err = verifySchnorr(sigData, pkData, []byte("goodbye"), nil)
+	require.NoError(t, err)
+	assert.False(t, valid)
+}
+
+func TestCheckCurveProtocol(t *testing.T) {
+	assert.NoError(t, checkCurveProtocol(curve.Ed25519{}, "frost"))
+	assert.NoError(t, checkCurveProtocol(curve.Secp256k1{}, "cmp"))
+	for _, protocol := range []string{"cmp", "lss"} {
+		assert.ErrorContains(t, checkCurveProtocol(curve.Ed25519{}, protocol), "only supported with --protocol frost")
+	}
+}
+
+func TestVerifyECDSARequireLowS(t *testing.T) {
+	group := curve.Secp256k1{}
+	x := sample.Scalar(rand.Reader, group)
+	pkData := []byte(hex.EncodeToString(must(x.ActOnBase().MarshalBinary())))
+	message := []byte("hello")
+	hash := sha256.Sum256(message)
+
+	sig, err := rfc6979.Sign(x, hash[:])
+	require.NoError(t, err)
+	sig.Normalize()
+	highS := ecdsa.Signature{R: sig.R.Negate(), S: group.NewScalar().Set(sig.S).Negate()}
+	require.False(t, highS.IsLowS())
+
+	lowData, err := json.Marshal(sig)
+	require.NoError(t, err)
+	highData, err := json.Marshal(highS)
+	require.NoError(t, err)
+
+	for _, requireLowS := range []bool{false, true} {
+		valid, err := verifyECDSA(lowData, pkData, hash[:], curve.Secp256k1{}, requireLowS)
+		require.NoError(t, err)
+		assert.True(t, valid)
+	}
+	valid, err := verifyECDSA(highData, pkData, hash[:], curve.Secp256k1{}, false)
+	require.NoError(t, err)
+	assert.True(t, valid, "high s signatures are valid ECDSA signatures")
+	_, err = verifyECDSA(highData, pkData, hash[:], curve.Secp256k1{}, true)
+	assert.ErrorContains(t, err, "high s")
+}
+
+func TestMessageDigest(t *testing.T) {
+	message := []byte("hello")
+	sha := sha256.Sum256(message)
+	shad := sha256.Sum256(sha[:])
+	want := map[string]string{
+		hashSHA256:    hex.EncodeToString(sha[:]),
+		hashKeccak256: "1c8aff950685c2ed4bc3174f3472287b56d9517b9c948127319a09a7a36deac8",
+		hashSHA256d:   hex.EncodeToString(shad[:]),
+	}
+	for hashName, digest := range want {
+		got, err := messageDigest(message, hashName, "lss")
+		require.NoError(t, err)
+		assert.Equal(t, digest, hex.EncodeToString(got), hashName)
+	}
+
+	got, err := messageDigest(message, "", "cmp")
+	require.NoError(t, err)
+	assert.Equal(t, sha[:], got, "ECDSA signs the SHA-256 hash by default")
+	got, err = messageDigest(message, "", "frost")
+	require.NoError(t, err)
+	assert.Equal(t, message, got, "FROST signs the message by default")
+
+	got, err = messageDigest(sha[:], hashNone, "lss")
+	require.NoError(t, err)
+	assert.Equal(t, sha[:], got)
+	_, err = messageDigest(message, hashNone, "lss")
+	assert.ErrorContains(t, err, "32 byte digest")
+	_, err = messageDigest(message, "md5", "lss")
+	assert.ErrorContains(t, err, "unknown hash")
+}
+
+func TestSignVerifyHashes(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(3)
+	configs := lss.RunKeygen(t, group, partyIDs, 2)
+	publicKey, err := configs[partyIDs[0]].PublicKey()
+	require.NoError(t, err)
+	pkData := []byte(hex.EncodeToString(must(publicKey.MarshalBinary())))
+	message := []byte("pay 1 coin")
+
+	for _, hashName := range []string{hashSHA256, hashKeccak256, hashSHA256d} {
+		digest, err := messageDigest(message, hashName, "lss")
+		require.NoError(t, err)
+		sigData, err := json.Marshal(lss.RunSign(t, configs, partyIDs[:2], digest))
+		require.NoError(t, err)
+
+		for _, verifyHash := range []string{hashSHA256, hashKeccak256, hashSHA256d} {
+			verifyDigest, err := messageDigest(message, verifyHash, "lss")
+			require.NoError(t, err)
+			valid, err := verifyECDSA(sigData, pkData, verifyDigest, curve.Secp256k1{}, false)
+			require.NoError(t, err)
+			assert.Equal(t, hashName == verifyHash, valid, "signed with %s, verified with %s", hashName, verifyHash)
+		}
+
+		// the digest itself verifies with --hash none
+		valid, err := verifyECDSA(sigData, pkData, must(messageDigest(digest, hashNone, "lss")), curve.Secp256k1{}, false)
+		require.NoError(t, err)
+		assert.True(t, valid)
+	}
+}
+
+// runVerifyCommand runs the verify command with args, and resets its flags afterwards.
+func runVerifyCommand(t *testing.T, args ...string) error {
+	t.Cleanup(func() {
+		reset := func(f *pflag.Flag) {
+			_ = f.Value.Set(f.DefValue)
+			f.Changed = false
+		}
+		verifyCmd.Flags().VisitAll(reset)
+		rootCmd.PersistentFlags().VisitAll(reset)
+	})
+	rootCmd.SetArgs(append([]string{"verify"}, args...))
+	rootCmd.SilenceUsage = true
+	return rootCmd.Execute()
+}
+
+// writeSignatureFiles writes signature, with its header unless bare, and the hex encoded public key to dir.
+func writeSignatureFiles(t *testing.T, dir string, signature interface{}, publicKey curve.Point, bare bool) (sigFile, pkFile string) {
+	var sigData []byte
+	var err error
+	if bare {
+		sigData, err = json.Marshal(signature)
+	} else {
+		sigData, err = encodeSignOutput(signature, signFormatJSON)
+	}
+	require.NoError(t, err)
+	sigFile = filepath.Join(dir, "signature.json")
+	require.NoError(t, os.WriteFile(sigFile, sigData, 0644))
+	pkFile = filepath.Join(dir, "public.hex")
+	require.NoError(t, os.WriteFile(pkFile, []byte(hex.EncodeToString(must(publicKey.MarshalBinary()))), 0644))
+	return sigFile, pkFile
+}
+
+func TestVerifyDetectsFROSTEd25519(t *testing.T) {
+	group := curve.Ed25519{}
+	partyIDs := test.PartyIDs(3)
+	rounds := make([]round.Session, 0, len(partyIDs))
+	for _, id := range partyIDs {
+		r, err := keygen.StartKeygenCommon(false, group, partyIDs, 1, id, nil, nil, nil)(nil)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+	require.NoError(t, test.RunRounds(rounds, nil))
+	configs := make(map[party.ID]*frost.Config, len(partyIDs))
+	for _, r := range rounds {
+		c := r.(*round.Output).Result.(*keygen.Config)
+		configs[c.ID] = c
+	}
+
+	message := []byte("detect the curve")
+	signers := partyIDs[:2]
+	rounds = rounds[:0]
+	for _, id := range signers {
+		r, err := sign.StartSignCommon(false, configs[id], signers, message)(nil)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+	require.NoError(t, test.RunRounds(rounds, nil))
+	sig := rounds[0].(*round.Output).Result.(frost.Signature)
+
+	publicKey := configs[partyIDs[0]].PublicKey
+	sigFile, pkFile := writeSignatureFiles(t, t.TempDir(), &sig, publicKey, false)
+	messageHex := hex.EncodeToString(message)
+	// --protocol defaults to lss, and --curve to secp256k1
+	require.NoError(t, runVerifyCommand(t, "--signature", sigFile, "--public-key", pkFile, "--message", messageHex))
+	assert.Error(t, runVerifyCommand(t, "--signature", sigFile, "--public-key", pkFile, "--message", "00"))
+	assert.ErrorContains(t, runVerifyCommand(t, "-p", "cmp", "--signature", sigFile, "--public-key", pkFile, "--message", messageHex),
+		"schnorr signature")
+
+	// without a header, --protocol is needed
+	sigFile, pkFile = writeSignatureFiles(t, t.TempDir(), &sig, publicKey, true)
+	assert.Error(t, runVerifyCommand(t, "--signature", sigFile, "--public-key", pkFile, "--message", messageHex))
+	require.NoError(t, runVerifyCommand(t, "-p", "frost", "--signature", sigFile, "--public-key", pkFile, "--message", messageHex))
+}
+
+func TestVerifyTaprootXOnly(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(3)
+	rounds := make([]round.Session, 0, len(partyIDs))
+	for _, id := range partyIDs {
+		r, err := keygen.StartKeygenCommon(false, group, partyIDs, 1, id, nil, nil, nil)(nil)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+	require.NoError(t, test.RunRounds(rounds, nil))
+	configs := make(map[party.ID]*frost.Config, len(partyIDs))
+	for _, r := range rounds {
+		c := r.(*round.Output).Result.(*keygen.Config)
+		configs[c.ID] = c
+	}
+
+	message := []byte("taproot key path spend")
+	digest := sha256.Sum256(message)
+	signers := partyIDs[:2]
+	rounds = rounds[:0]
+	for _, id := range signers {
+		r, err := frost.SignTaprootKeySpend(configs[id], signers, digest[:], nil)(nil)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+	require.NoError(t, test.RunRounds(rounds, nil))
+	sig := rounds[0].(*round.Output).Result.(taproot.Signature)
+
+	outputKey, err := frost.TaprootOutputKey(configs[partyIDs[0]].PublicKey, nil)
+	require.NoError(t, err)
+	require.Len(t, outputKey, taproot.PublicKeyLength)
+	file, err := newSignatureFile(sig)
+	require.NoError(t, err)
+	pkData := []byte(hex.EncodeToString(outputKey))
+
+	valid, err := verifySchnorr(file.Signature, pkData, digest[:], group)
+	require.NoError(t, err)
+	assert.True(t, valid)
+	valid, err = verifySchnorr(file.Signature, pkData, make([]byte, 32), group)
+	require.NoError(t, err)
+	assert.False(t, valid)
+
+	// the internal key doesn't verify spends of the output key
+	internal, err := configs[partyIDs[0]].PublicKey.MarshalBinary()
+	require.NoError(t, err)
+	valid, err = verifySchnorr(file.Signature, []byte(hex.EncodeToString(internal)), digest[:], group)
+	require.NoError(t, err)
+	assert.False(t, valid)
+
+	// the header tells verify the curve, so that the x-only key isn't taken for an ed25519 key
+	dir := t.TempDir()
+	sigData, err := encodeSignOutput(sig, signFormatJSON)
+	require.NoError(t, err)
+	sigFile := filepath.Join(dir, "signature.json")
+	require.NoError(t, os.WriteFile(sigFile, sigData, 0644))
+	pkFile := filepath.Join(dir, "output.hex")
+	require.NoError(t, os.WriteFile(pkFile, pkData, 0644))
+	messageHex := hex.EncodeToString(message)
+	require.NoError(t, runVerifyCommand(t, "--signature", sigFile, "--public-key", pkFile, "--message", messageHex, "--hash", hashSHA256))
+	assert.Error(t, runVerifyCommand(t, "--signature", sigFile, "--public-key", pkFile, "--message", "00", "--hash", hashSHA256))
+}
+
+func TestVerifyDetectsCMPSecp256k1(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	group := curve.Secp256k1{}
+	configs, partyIDs := test.GenerateConfig(group, 3, 1, rand.Reader, pl)
+	message := []byte("detect the scheme")
+	digest := sha256.Sum256(message)
+	signers := partyIDs[:2]
+	rounds := make([]round.Session, 0, len(signers))
+	for _, id := range signers {
+		r, err := cmp.Sign(configs[id], signers, digest[:], pl)(nil)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+	require.NoError(t, test.RunRounds(rounds, nil))
+	sig := rounds[0].(*round.Output).Result.(*ecdsa.Signature)
+
+	publicKey := configs[partyIDs[0]].PublicPoint()
+	sigFile, pkFile := writeSignatureFiles(t, t.TempDir(), sig, publicKey, false)
+	messageHex := hex.EncodeToString(message)
+	// the header overrides --curve, and the default hash of ECDSA applies
+	require.NoError(t, runVerifyCommand(t, "-c", "ed25519", "--signature", sigFile, "--public-key", pkFile, "--message", messageHex))
+	require.NoError(t, runVerifyCommand(t, "-p", "lss", "--signature", sigFile, "--public-key", pkFile, "--message", messageHex))
+	assert.Error(t, runVerifyCommand(t, "--signature", sigFile, "--public-key", pkFile, "--message", "00"))
+
+	data, err := os.ReadFile(sigFile)
+	require.NoError(t, err)
+	require.NoError(t, validateFile(data, "signature", "cmp"))
+	header, err := readSignatureFile(data)
+	require.NoError(t, err)
+	assert.Equal(t, &signatureFile{Scheme: schemeECDSA, Curve: "secp256k1", Signature: header.Signature}, header)
+}
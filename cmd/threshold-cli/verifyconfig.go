@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/math/polynomial"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/taproot"
+	"github.com/luxfi/threshold/protocols/cmp"
+	"github.com/luxfi/threshold/protocols/frost"
+	"github.com/luxfi/threshold/protocols/lss"
+	"github.com/spf13/cobra"
+)
+
+// configShares is what verify-config checks of a config: the public key it claims,
+// the public shares of all parties, which lie on a polynomial of degree Degree, and the secret shares of this party.
+type configShares struct {
+	PublicKey curve.Point
+	Public    map[party.ID]curve.Point
+	Secret    map[party.ID]curve.Scalar
+	Degree    int
+}
+
+// loadConfigShares returns the shares of a JSON config of protocol, like loadPublicKey.
+func loadConfigShares(data []byte, protocol string, group curve.Curve) (*configShares, error) {
+	switch protocol {
+	case "lss":
+		config := lss.EmptyConfig(group)
+		if err := json.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal LSS config: %w", err)
+		}
+		publicKey, err := config.PublicKey()
+		if err != nil {
+			return nil, err
+		}
+		public := make(map[party.ID]curve.Point, len(config.Public))
+		for id, p := range config.Public {
+			if p == nil || p.ECDSA == nil {
+				return nil, fmt.Errorf("missing public share for %s", id)
+			}
+			public[id] = p.ECDSA
+		}
+		// LSS threshold is the number of signers
+		return &configShares{PublicKey: publicKey, Public: public, Secret: config.Shares(), Degree: config.Threshold - 1}, nil
+	case "cmp":
+		config := cmp.EmptyConfig(group)
+		if err := json.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal CMP config: %w", err)
+		}
+		public := make(map[party.ID]curve.Point, len(config.Public))
+		for id, p := range config.Public {
+			if p == nil || p.ECDSA == nil {
+				return nil, fmt.Errorf("missing public share for %s", id)
+			}
+			public[id] = p.ECDSA
+		}
+		secret := map[party.ID]curve.Scalar{config.ID: config.ECDSA}
+		return &configShares{PublicKey: config.PublicPoint(), Public: public, Secret: secret, Degree: config.Threshold}, nil
+	case "frost":
+		config := frost.EmptyConfig(group)
+		if err := json.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal FROST config: %w", err)
+		}
+		if config.PublicKey == nil || config.VerificationShares == nil {
+			return nil, errors.New("FROST config is incomplete")
+		}
+		secret := map[party.ID]curve.Scalar{config.ID: config.PrivateShare}
+		return &configShares{PublicKey: config.PublicKey, Public: config.VerificationShares.Points, Secret: secret, Degree: config.Threshold}, nil
+	default:
+		return nil, fmt.Errorf("unknown protocol: %s", protocol)
+	}
+}
+
+// verifyConfigKey checks that a config holds shares of the expected public key:
+// the secret shares of this party match their public shares, the public shares of all parties
+// interpolate to the public key the config claims, and that key is expected.
+//
+// expected is a compressed or uncompressed SEC 1 point, the RFC 8032 encoding on ed25519,
+// or a 32 byte x-only key on secp256k1, as in taproot outputs.
+func verifyConfigKey(s *configShares, group curve.Curve, expected []byte) error {
+	for id, share := range s.Secret {
+		public, ok := s.Public[id]
+		if share == nil || !ok {
+			return fmt.Errorf("missing share of %s", id)
+		}
+		if !share.ActOnBase().Equal(public) {
+			return fmt.Errorf("secret share of %s doesn't match its public share", id)
+		}
+	}
+	interpolated, err := polynomial.InterpolatePoints(group, s.Public, s.Degree)
+	if err != nil {
+		return fmt.Errorf("public shares: %w", err)
+	}
+	if !interpolated.Equal(s.PublicKey) {
+		return errors.New("public shares don't interpolate to the public key of the config")
+	}
+
+	if _, ok := group.(curve.Secp256k1); ok && len(expected) == taproot.PublicKeyLength {
+		xOnly, _ := taproot.XOnlyFromPoint(s.PublicKey)
+		if !bytes.Equal(xOnly, expected) {
+			return fmt.Errorf("public key %x doesn't match the expected x-only key %x", xOnly, expected)
+		}
+		return nil
+	}
+	expectedKey, err := decodePoint(group, expected)
+	if err != nil {
+		return fmt.Errorf("failed to decode expected public key: %w", err)
+	}
+	if !s.PublicKey.Equal(expectedKey) {
+		return fmt.Errorf("public key %s doesn't match the expected key %s", curve.KeyID(s.PublicKey), curve.KeyID(expectedKey))
+	}
+	return nil
+}
+
+func runVerifyConfig(cmd *cobra.Command, args []string) error {
+	expectHex, _ := cmd.Flags().GetString("expect-pubkey")
+	expected, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(expectHex), "0x"))
+	if err != nil {
+		return fmt.Errorf("failed to decode expected public key: %w", err)
+	}
+
+	data, err := os.ReadFile(inputFile)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	group, err := getCurve(curveType)
+	if err != nil {
+		return err
+	}
+	if err := checkCurveProtocol(group, protocolName); err != nil {
+		return err
+	}
+	shares, err := loadConfigShares(data, protocolName, group)
+	if err != nil {
+		return err
+	}
+	if err := verifyConfigKey(shares, group, expected); err != nil {
+		return fmt.Errorf("%s: %w", inputFile, err)
+	}
+	fmt.Printf("%s holds a share of %s\n", inputFile, expectHex)
+	return nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyConfigKey(t *testing.T) {
+	c, publicKey := testLSSConfig(t, 3, 2)
+	_, otherKey := testLSSConfig(t, 3, 2)
+	data, err := json.Marshal(c)
+	require.NoError(t, err)
+
+	verify := func(data []byte, key string) error {
+		shares, err := loadConfigShares(data, "lss", c.Group)
+		require.NoError(t, err)
+		expected, err := hex.DecodeString(key)
+		require.NoError(t, err)
+		return verifyConfigKey(shares, c.Group, expected)
+	}
+	assert.NoError(t, verify(data, publicKey))
+	// the x-only encoding of the key
+	assert.NoError(t, verify(data, publicKey[2:]))
+	assert.ErrorContains(t, verify(data, otherKey), "doesn't match the expected key")
+	assert.ErrorContains(t, verify(data, otherKey[2:]), "doesn't match the expected x-only key")
+
+	// a config whose public shares of two parties were swapped
+	ids := c.PartyIDs()
+	c.Public[ids[1]].ECDSA, c.Public[ids[2]].ECDSA = c.Public[ids[2]].ECDSA, c.Public[ids[1]].ECDSA
+	data, err = json.Marshal(c)
+	require.NoError(t, err)
+	assert.ErrorContains(t, verify(data, publicKey), "not on the polynomial")
+
+	// a config with the share of another key
+	other, _ := testLSSConfig(t, 3, 2)
+	c.Public[ids[1]].ECDSA, c.Public[ids[2]].ECDSA = c.Public[ids[2]].ECDSA, c.Public[ids[1]].ECDSA
+	c.ECDSA = other.ECDSA
+	data, err = json.Marshal(c)
+	require.NoError(t, err)
+	assert.ErrorContains(t, verify(data, publicKey), "doesn't match its public share")
+}
@@ -59,8 +59,8 @@ func CMPRefresh(c *cmp.Config, n *test.Network, pl *pool.Pool) (*cmp.Config, err
 	return r.(*cmp.Config), nil
 }
 
-func CMPSign(c *cmp.Config, m []byte, signers party.IDSlice, n *test.Network, pl *pool.Pool) error {
-	h, err := protocol.NewMultiHandler(cmp.Sign(c, signers, m, pl), nil)
+func CMPSign(c *cmp.Config, m []byte, signers party.IDSlice, sessionID []byte, n *test.Network, pl *pool.Pool) error {
+	h, err := protocol.NewMultiHandler(cmp.Sign(c, signers, m, pl), sessionID)
 	if err != nil {
 		return err
 	}
@@ -77,8 +77,8 @@ func CMPSign(c *cmp.Config, m []byte, signers party.IDSlice, n *test.Network, pl
 	return nil
 }
 
-func CMPPreSign(c *cmp.Config, signers party.IDSlice, n *test.Network, pl *pool.Pool) (*ecdsa.PreSignature, error) {
-	h, err := protocol.NewMultiHandler(cmp.Presign(c, signers, pl), nil)
+func CMPPreSign(c *cmp.Config, signers party.IDSlice, sessionID []byte, n *test.Network, pl *pool.Pool) (*ecdsa.PreSignature, error) {
+	h, err := protocol.NewMultiHandler(cmp.Presign(c, signers, pl), sessionID)
 	if err != nil {
 		return nil, err
 	}
@@ -97,8 +97,8 @@ func CMPPreSign(c *cmp.Config, signers party.IDSlice, n *test.Network, pl *pool.
 	return preSignature, nil
 }
 
-func CMPPreSignOnline(c *cmp.Config, preSignature *ecdsa.PreSignature, m []byte, n *test.Network, pl *pool.Pool) error {
-	h, err := protocol.NewMultiHandler(cmp.PresignOnline(c, preSignature, m, pl), nil)
+func CMPPreSignOnline(c *cmp.Config, preSignature *ecdsa.PreSignature, m []byte, sessionID []byte, n *test.Network, pl *pool.Pool) error {
+	h, err := protocol.NewMultiHandler(cmp.PresignOnline(c, preSignature, m, pl), sessionID)
 	if err != nil {
 		return err
 	}
@@ -129,8 +129,8 @@ func FrostKeygen(id party.ID, ids party.IDSlice, threshold int, n *test.Network)
 	return r.(*frost.Config), nil
 }
 
-func FrostSign(c *frost.Config, id party.ID, m []byte, signers party.IDSlice, n *test.Network) error {
-	h, err := protocol.NewMultiHandler(frost.Sign(c, signers, m), nil)
+func FrostSign(c *frost.Config, id party.ID, m []byte, signers party.IDSlice, sessionID []byte, n *test.Network) error {
+	h, err := protocol.NewMultiHandler(frost.Sign(c, signers, m), sessionID)
 	if err != nil {
 		return err
 	}
@@ -160,8 +160,8 @@ func FrostKeygenTaproot(id party.ID, ids party.IDSlice, threshold int, n *test.N
 
 	return r.(*frost.TaprootConfig), nil
 }
-func FrostSignTaproot(c *frost.TaprootConfig, id party.ID, m []byte, signers party.IDSlice, n *test.Network) error {
-	h, err := protocol.NewMultiHandler(frost.SignTaproot(c, signers, m), nil)
+func FrostSignTaproot(c *frost.TaprootConfig, id party.ID, m []byte, signers party.IDSlice, sessionID []byte, n *test.Network) error {
+	h, err := protocol.NewMultiHandler(frost.SignTaproot(c, signers, m), sessionID)
 	if err != nil {
 		return err
 	}
@@ -178,7 +178,7 @@ func FrostSignTaproot(c *frost.TaprootConfig, id party.ID, m []byte, signers par
 	return nil
 }
 
-func All(id party.ID, ids party.IDSlice, threshold int, message []byte, n *test.Network, wg *sync.WaitGroup, pl *pool.Pool) error {
+func All(id party.ID, ids party.IDSlice, threshold int, message, sessionID []byte, n *test.Network, wg *sync.WaitGroup, pl *pool.Pool) error {
 	defer wg.Done()
 
 	// XOR
@@ -218,31 +218,31 @@ func All(id party.ID, ids party.IDSlice, threshold int, message []byte, n *test.
 	}
 
 	// CMP SIGN
-	err = CMPSign(refreshConfig, message, signers, n, pl)
+	err = CMPSign(refreshConfig, message, signers, sessionID, n, pl)
 	if err != nil {
 		return err
 	}
 
 	// CMP PRESIGN
-	preSignature, err := CMPPreSign(refreshConfig, signers, n, pl)
+	preSignature, err := CMPPreSign(refreshConfig, signers, sessionID, n, pl)
 	if err != nil {
 		return err
 	}
 
 	// CMP PRESIGN ONLINE
-	err = CMPPreSignOnline(refreshConfig, preSignature, message, n, pl)
+	err = CMPPreSignOnline(refreshConfig, preSignature, message, sessionID, n, pl)
 	if err != nil {
 		return err
 	}
 
 	// FROST SIGN
-	err = FrostSign(frostResult, id, message, signers, n)
+	err = FrostSign(frostResult, id, message, signers, sessionID, n)
 	if err != nil {
 		return err
 	}
 
 	// FROST SIGN TAPROOT
-	err = FrostSignTaproot(frostResultTaproot, id, message, signers, n)
+	err = FrostSignTaproot(frostResultTaproot, id, message, signers, sessionID, n)
 	if err != nil {
 		return err
 	}
@@ -255,6 +255,12 @@ func main() {
 	ids := party.IDSlice{"a", "b", "c", "d", "e", "f"}
 	threshold := 4
 	messageToSign := []byte("hello")
+	// the signing protocols require a session ID, on which all parties agree
+	sessionID, err := protocol.NewSessionID()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 
 	net := test.NewNetwork(ids)
 
@@ -264,7 +270,7 @@ func main() {
 		go func(id party.ID) {
 			pl := pool.NewPool(0)
 			defer pl.TearDown()
-			if err := All(id, ids, threshold, messageToSign, net, &wg, pl); err != nil {
+			if err := All(id, ids, threshold, messageToSign, sessionID, net, &wg, pl); err != nil {
 				fmt.Println(err)
 			}
 		}(id)
@@ -0,0 +1,100 @@
+// Package rfc6979 signs with the deterministic nonces of RFC 6979, for test vectors.
+//
+// It lives under internal so that only this module's tests reach it: threshold protocols
+// must never derive their nonces this way.
+package rfc6979
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+
+	"github.com/cronokirby/saferith"
+	"github.com/luxfi/threshold/pkg/ecdsa"
+	"github.com/luxfi/threshold/pkg/math/curve"
+)
+
+// Sign creates an ECDSA signature of hash with the secret key x,
+// using a nonce derived from x and hash as in RFC 6979, with HMAC-SHA256.
+//
+// Signing the same hash with the same key always gives the same signature, which
+// makes it useful for reproducible test vectors.
+// It must only be used with a complete secret key held by a single party:
+// threshold protocols must never derive their nonces this way.
+//
+// See: https://www.rfc-editor.org/rfc/rfc6979
+func Sign(x curve.Scalar, hash []byte) (*ecdsa.Signature, error) {
+	if x.IsZero() {
+		return nil, errors.New("rfc6979: secret key is zero")
+	}
+	group := x.Curve()
+	k := nonce(x, hash)
+	R := k.ActOnBase()
+	r := R.XScalar()
+
+	// s = k⁻¹(m + r x)
+	m := curve.FromHash(group, hash)
+	s := group.NewScalar().Set(r).Mul(x).Add(m)
+	s.Mul(k.Invert())
+	if r.IsZero() || s.IsZero() {
+		return nil, errors.New("rfc6979: deterministic nonce gives an invalid signature")
+	}
+	return &ecdsa.Signature{R: R, S: s}, nil
+}
+
+// nonce returns the nonce k of section 3.2 of RFC 6979 for the key x and hash.
+func nonce(x curve.Scalar, hash []byte) curve.Scalar {
+	group := x.Curve()
+	q := group.Order().Big()
+	qlen := q.BitLen()
+	rolen := (qlen + 7) / 8
+
+	// bits2int keeps the leftmost qlen bits of b
+	bits2int := func(b []byte) *big.Int {
+		v := new(big.Int).SetBytes(b)
+		if excess := len(b)*8 - qlen; excess > 0 {
+			v.Rsh(v, uint(excess))
+		}
+		return v
+	}
+	int2octets := func(v *big.Int) []byte {
+		return v.FillBytes(make([]byte, rolen))
+	}
+	mac := func(key []byte, data ...[]byte) []byte {
+		h := hmac.New(sha256.New, key)
+		for _, d := range data {
+			_, _ = h.Write(d)
+		}
+		return h.Sum(nil)
+	}
+
+	xBytes, _ := x.MarshalBinary()
+	xOctets := int2octets(new(big.Int).SetBytes(xBytes))
+	h1 := bits2int(hash)
+	h1.Mod(h1, q)
+	hOctets := int2octets(h1)
+
+	V := make([]byte, sha256.Size)
+	for i := range V {
+		V[i] = 0x01
+	}
+	K := make([]byte, sha256.Size)
+	K = mac(K, V, []byte{0x00}, xOctets, hOctets)
+	V = mac(K, V)
+	K = mac(K, V, []byte{0x01}, xOctets, hOctets)
+	V = mac(K, V)
+	for {
+		var T []byte
+		for len(T)*8 < qlen {
+			V = mac(K, V)
+			T = append(T, V...)
+		}
+		k := bits2int(T)
+		if k.Sign() > 0 && k.Cmp(q) < 0 {
+			return group.NewScalar().SetNat(new(saferith.Nat).SetBytes(int2octets(k)))
+		}
+		K = mac(K, V, []byte{0x00})
+		V = mac(K, V)
+	}
+}
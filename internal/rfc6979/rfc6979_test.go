@@ -0,0 +1,82 @@
+package rfc6979
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/math/sample"
+)
+
+func mustScalar(t *testing.T, group curve.Curve, h string) curve.Scalar {
+	data, err := hex.DecodeString(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := group.NewScalar()
+	if err := s.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// TestSignP256 checks the test vector of RFC 6979, A.2.5, with SHA-256 and the message "sample".
+func TestSignP256(t *testing.T) {
+	group := curve.P256{}
+	x := mustScalar(t, group, "c9afa9d845ba75166b5c215767b1d6934e50c3db36e89b127b8a622b120f6721")
+	hash := sha256.Sum256([]byte("sample"))
+
+	k := nonce(x, hash[:])
+	if want := mustScalar(t, group, "a6e3c57dd01abe90086538398355dd4c3b17aa873382b0f24d6129493d8aad60"); !k.Equal(want) {
+		t.Fatal("wrong nonce")
+	}
+	sig, err := Sign(x, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := mustScalar(t, group, "efd48b2aacb6a8fd1140dd9cd45e81d69d2c877b56aaf991c34d0ea84eaf3716"); !sig.R.XScalar().Equal(want) {
+		t.Error("wrong r")
+	}
+	if want := mustScalar(t, group, "f7cb1c942d657c41d436c7a1b6e29f65f3e900dbb9aff4064dc4ab2f843acda8"); !sig.S.Equal(want) {
+		t.Error("wrong s")
+	}
+	if !sig.Verify(x.ActOnBase(), hash[:]) {
+		t.Error("signature does not verify")
+	}
+}
+
+func TestSignSecp256k1(t *testing.T) {
+	group := curve.Secp256k1{}
+	for i := 0; i < 16; i++ {
+		x := sample.Scalar(rand.Reader, group)
+		hash := make([]byte, 32)
+		_, _ = rand.Read(hash)
+
+		// compare the nonce with an independent implementation
+		xBytes, _ := x.MarshalBinary()
+		want := secp256k1.NonceRFC6979(xBytes, hash, nil, nil, 0).Bytes()
+		got, _ := nonce(x, hash).MarshalBinary()
+		if !bytes.Equal(got, want[:]) {
+			t.Fatalf("nonce %x, want %x", got, want)
+		}
+
+		sig1, err := Sign(x, hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig2, err := Sign(x, hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sig1.R.Equal(sig2.R) || !sig1.S.Equal(sig2.S) {
+			t.Error("signatures of the same hash differ")
+		}
+		if !sig1.Verify(x.ActOnBase(), hash) {
+			t.Error("signature does not verify")
+		}
+	}
+}
@@ -34,6 +34,30 @@ type Helper struct {
 	mtx sync.Mutex
 }
 
+// ValidateParties returns an error naming the problem if partyIDs is empty, holds an ID more than
+// once or an invalid one, see party.ID.Valid, or does not contain selfID.
+//
+// Protocols call it before starting, so that bad arguments are reported with the offending ID.
+func ValidateParties(selfID party.ID, partyIDs []party.ID) error {
+	if len(partyIDs) == 0 {
+		return errors.New("no parties given")
+	}
+	seen := make(map[party.ID]bool, len(partyIDs))
+	for _, id := range partyIDs {
+		if !id.Valid() {
+			return fmt.Errorf("party ID %q is invalid: it must not be empty, start with a zero byte, or encode 2^252 or more", string(id))
+		}
+		if seen[id] {
+			return fmt.Errorf("party %s is listed more than once", id)
+		}
+		seen[id] = true
+	}
+	if !seen[selfID] {
+		return fmt.Errorf("self %s is not among the parties %v", selfID, partyIDs)
+	}
+	return nil
+}
+
 // NewSession creates a new *Helper which can be embedded in the first Round,
 // so that the full struct implements Session.
 // `sessionID` is an optional byte slice that can be provided by the user.
@@ -205,6 +229,9 @@ func (h *Helper) FinalRoundNumber() Number { return h.info.FinalRoundNumber }
 // SSID the unique identifier for this protocol execution.
 func (h *Helper) SSID() []byte { return h.ssid }
 
+// RequireSessionID reports whether the protocol must be given a session ID, see Info.RequireSessionID.
+func (h *Helper) RequireSessionID() bool { return h.info.RequireSessionID }
+
 // SelfID is this party's ID.
 func (h *Helper) SelfID() party.ID { return h.info.SelfID }
 
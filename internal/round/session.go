@@ -19,6 +19,9 @@ type Info struct {
 	Threshold int
 	// Group returns the group used for this protocol execution.
 	Group curve.Curve
+	// RequireSessionID is set by protocols whose executions are only told apart by their session ID, such as signing,
+	// so that protocol.NewMultiHandler refuses to run them without one.
+	RequireSessionID bool
 }
 
 // Session represents the current execution of a round-based protocol.
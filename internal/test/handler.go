@@ -1,6 +1,12 @@
 package test
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+
 	"github.com/luxfi/threshold/pkg/party"
 	"github.com/luxfi/threshold/pkg/protocol"
 )
@@ -25,3 +31,175 @@ func HandlerLoop(id party.ID, h protocol.Handler, network *Network) {
 		}
 	}
 }
+
+// HandlerLoops is like HandlerLoop, for several executions run concurrently by the same party over one network.
+// Each incoming message is given to the first handler that can accept it, so executions are told apart
+// by their SSID, and must therefore have different session IDs if their parameters are the same.
+func HandlerLoops(id party.ID, handlers []protocol.Handler, network *Network) {
+	var wg sync.WaitGroup
+	for _, h := range handlers {
+		wg.Add(1)
+		go func(h protocol.Handler) {
+			defer wg.Done()
+			for msg := range h.Listen() {
+				go network.Send(msg)
+			}
+		}(h)
+	}
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+
+	incoming := network.Next(id)
+	for {
+		select {
+		case <-finished:
+			<-network.Done(id)
+			return
+		case msg := <-incoming:
+			for _, h := range handlers {
+				if h.CanAccept(msg) {
+					h.Accept(msg)
+					break
+				}
+			}
+		}
+	}
+}
+
+// RunSessions runs len(sessionIDs) executions of the parties concurrently over one network, the i-th with
+// the session ID sessionIDs[i] and the protocol start(i, id) for party id, and returns the handlers of each execution.
+func RunSessions(partyIDs []party.ID, sessionIDs [][]byte, start func(i int, id party.ID) protocol.StartFunc) ([]map[party.ID]protocol.Handler, error) {
+	sessions := make([]map[party.ID]protocol.Handler, len(sessionIDs))
+	handlers := make(map[party.ID][]protocol.Handler, len(partyIDs))
+	for i, sessionID := range sessionIDs {
+		sessions[i] = make(map[party.ID]protocol.Handler, len(partyIDs))
+		for _, id := range partyIDs {
+			h, err := protocol.NewMultiHandler(start(i, id), sessionID)
+			if err != nil {
+				return nil, err
+			}
+			sessions[i][id] = h
+			handlers[id] = append(handlers[id], h)
+		}
+	}
+
+	network := NewNetwork(partyIDs)
+	var wg sync.WaitGroup
+	for _, id := range partyIDs {
+		wg.Add(1)
+		go func(id party.ID) {
+			defer wg.Done()
+			HandlerLoops(id, handlers[id], network)
+		}(id)
+	}
+	wg.Wait()
+	return sessions, nil
+}
+
+// RunProtocol runs the protocol start(id) of every party over a network with the session ID sessionID,
+// and returns the result of each party, or the error of the first one, in the order of partyIDs, which failed.
+func RunProtocol(partyIDs []party.ID, sessionID []byte, start func(id party.ID) protocol.StartFunc) (map[party.ID]interface{}, error) {
+	sessions, err := RunSessions(partyIDs, [][]byte{sessionID}, func(_ int, id party.ID) protocol.StartFunc {
+		return start(id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[party.ID]interface{}, len(partyIDs))
+	for _, id := range partyIDs {
+		if results[id], err = sessions[0][id].Result(); err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+	}
+	return results, nil
+}
+
+// RunTransports is like RunProtocol, with every party running protocol.Run over its own transport(id),
+// such as a connection to a relay or a gRPC stream, rather than over a Network.
+func RunTransports(ctx context.Context, partyIDs []party.ID, sessionID []byte, transport func(id party.ID) protocol.Transport, start func(id party.ID) protocol.StartFunc) (map[party.ID]interface{}, error) {
+	handlers := make(map[party.ID]protocol.Handler, len(partyIDs))
+	for _, id := range partyIDs {
+		h, err := protocol.NewMultiHandler(start(id), sessionID)
+		if err != nil {
+			return nil, err
+		}
+		handlers[id] = h
+	}
+
+	results := make(map[party.ID]interface{}, len(partyIDs))
+	errs := make(map[party.ID]error, len(partyIDs))
+	var mtx sync.Mutex
+	var wg sync.WaitGroup
+	for _, id := range partyIDs {
+		wg.Add(1)
+		go func(id party.ID) {
+			defer wg.Done()
+			result, err := protocol.Run(ctx, handlers[id], transport(id))
+			mtx.Lock()
+			results[id], errs[id] = result, err
+			mtx.Unlock()
+		}(id)
+	}
+	wg.Wait()
+	for _, id := range partyIDs {
+		if errs[id] != nil {
+			return nil, fmt.Errorf("%s: %w", id, errs[id])
+		}
+	}
+	return results, nil
+}
+
+// RunSigningSessions runs the given number of signings by the parties concurrently over one network, half of them
+// of the same message so that only their session IDs tell them apart. sign starts the signing of the message by
+// party id, and verify checks a result for the message and returns its encoding. It fails unless every party of a
+// signing returns the same valid signature, and no two signings return the same one.
+func RunSigningSessions(partyIDs []party.ID, sessions int, sign func(message []byte, id party.ID) protocol.StartFunc, verify func(message []byte, result interface{}) ([]byte, error)) error {
+	messages := make([][]byte, sessions)
+	sessionIDs := make([][]byte, sessions)
+	for i := range messages {
+		message := sha256.Sum256([]byte("same message"))
+		if i%2 == 1 {
+			message = sha256.Sum256([]byte(fmt.Sprintf("message %d", i)))
+		}
+		messages[i] = message[:]
+		var err error
+		if sessionIDs[i], err = protocol.NewSessionID(); err != nil {
+			return err
+		}
+	}
+
+	handlers, err := RunSessions(partyIDs, sessionIDs, func(i int, id party.ID) protocol.StartFunc {
+		return sign(messages[i], id)
+	})
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]int, sessions)
+	for i, message := range messages {
+		var first []byte
+		for _, id := range partyIDs {
+			result, err := handlers[i][id].Result()
+			if err != nil {
+				return fmt.Errorf("session %d of %s: %w", i, id, err)
+			}
+			data, err := verify(message, result)
+			if err != nil {
+				return fmt.Errorf("session %d of %s: %w", i, id, err)
+			}
+			if first == nil {
+				first = data
+			} else if !bytes.Equal(first, data) {
+				return fmt.Errorf("the signers of session %d disagree", i)
+			}
+		}
+		if j, ok := seen[string(first)]; ok {
+			return fmt.Errorf("sessions %d and %d produced the same signature", j, i)
+		}
+		seen[string(first)] = i
+	}
+	return nil
+}
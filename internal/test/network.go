@@ -1,7 +1,9 @@
 package test
 
 import (
+	mathrand "math/rand"
 	"sync"
+	"time"
 
 	"github.com/luxfi/threshold/pkg/party"
 	"github.com/luxfi/threshold/pkg/protocol"
@@ -15,9 +17,27 @@ type Network struct {
 	listenChannels   map[party.ID]chan *protocol.Message
 	done             chan struct{}
 	closedListenChan chan *protocol.Message
+	filter           func(from, to party.ID, msg *protocol.Message) bool
+	options          *Options
+	rand             *mathrand.Rand
+	held             map[party.ID][]*protocol.Message
 	mtx              sync.Mutex
 }
 
+// Options configure the faults injected by a Network created with NewNetworkWithOptions.
+type Options struct {
+	// MinDelay and MaxDelay bound the random delay after which each message is delivered.
+	MinDelay, MaxDelay time.Duration
+	// ReorderProbability is the probability that a message is held back, and delivered with the
+	// other messages held back for the same party in a random order.
+	ReorderProbability float64
+	// DropProbability is the probability that a message is dropped for a given recipient.
+	DropProbability float64
+}
+
+// reorderWindow is the longest time a message is held back for reordering.
+const reorderWindow = 10 * time.Millisecond
+
 func NewNetwork(parties party.IDSlice) *Network {
 	closed := make(chan *protocol.Message)
 	close(closed)
@@ -29,6 +49,18 @@ func NewNetwork(parties party.IDSlice) *Network {
 	return c
 }
 
+// NewNetworkWithOptions creates a Network which delays, reorders and drops messages at random, as set by options.
+//
+// Faults are drawn independently for every recipient of a message, so that a broadcast may reach
+// the parties in different orders.
+func NewNetworkWithOptions(parties party.IDSlice, options Options) *Network {
+	n := NewNetwork(parties)
+	n.options = &options
+	n.rand = mathrand.New(mathrand.NewSource(time.Now().UnixNano()))
+	n.held = make(map[party.ID][]*protocol.Message)
+	return n
+}
+
 func (n *Network) init() {
 	N := len(n.parties)
 	for _, id := range n.parties {
@@ -50,16 +82,87 @@ func (n *Network) Next(id party.ID) <-chan *protocol.Message {
 	return c
 }
 
+// SetFilter sets a function called for each recipient of a message sent on the network.
+// The message is dropped for that recipient if f returns false, which simulates lost messages and silent parties.
+// f is called with the network locked, so it must not use the network itself. A nil f delivers all messages.
+func (n *Network) SetFilter(f func(from, to party.ID, msg *protocol.Message) bool) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	n.filter = f
+}
+
 func (n *Network) Send(msg *protocol.Message) {
 	n.mtx.Lock()
 	defer n.mtx.Unlock()
+	// messages sent before any party listens are kept for them
+	if len(n.listenChannels) == 0 {
+		n.init()
+	}
 	for id, c := range n.listenChannels {
 		if msg.IsFor(id) && c != nil {
+			if n.filter != nil && !n.filter(msg.From, id, msg) {
+				continue
+			}
+			if n.options != nil {
+				n.schedule(id, msg)
+				continue
+			}
 			n.listenChannels[id] <- msg
 		}
 	}
 }
 
+// schedule delivers msg to id after injecting the faults set by n.options.
+// It must be called with n.mtx held.
+func (n *Network) schedule(id party.ID, msg *protocol.Message) {
+	o := n.options
+	if n.rand.Float64() < o.DropProbability {
+		return
+	}
+	delay := o.MinDelay
+	if o.MaxDelay > o.MinDelay {
+		delay += time.Duration(n.rand.Int63n(int64(o.MaxDelay - o.MinDelay)))
+	}
+	reorder := n.rand.Float64() < o.ReorderProbability
+	time.AfterFunc(delay, func() {
+		n.mtx.Lock()
+		defer n.mtx.Unlock()
+		if !reorder {
+			n.deliver(id, msg)
+			n.flush(id)
+			return
+		}
+		if len(n.held[id]) == 0 {
+			// release the held messages even if no other message comes for id
+			time.AfterFunc(reorderWindow, func() {
+				n.mtx.Lock()
+				defer n.mtx.Unlock()
+				n.flush(id)
+			})
+		}
+		n.held[id] = append(n.held[id], msg)
+	})
+}
+
+// flush delivers the messages held back for id in a random order.
+// It must be called with n.mtx held.
+func (n *Network) flush(id party.ID) {
+	held := n.held[id]
+	delete(n.held, id)
+	n.rand.Shuffle(len(held), func(i, j int) { held[i], held[j] = held[j], held[i] })
+	for _, msg := range held {
+		n.deliver(id, msg)
+	}
+}
+
+// deliver sends msg to id, unless id is already done.
+// It must be called with n.mtx held.
+func (n *Network) deliver(id party.ID, msg *protocol.Message) {
+	if c, ok := n.listenChannels[id]; ok {
+		c <- msg
+	}
+}
+
 func (n *Network) Done(id party.ID) chan struct{} {
 	n.mtx.Lock()
 	defer n.mtx.Unlock()
@@ -78,3 +181,38 @@ func (n *Network) Quit(id party.ID) {
 	defer n.mtx.Unlock()
 	n.parties = n.parties.Remove(id)
 }
+
+// Transport returns the connection of party id to the network, to run its handler with protocol.Run.
+func (n *Network) Transport(id party.ID) protocol.Transport {
+	return &transport{network: n, id: id}
+}
+
+// transport is a party's view of a Network.
+type transport struct {
+	network *Network
+	id      party.ID
+	started bool
+}
+
+// Send doesn't wait for the network, as in HandlerLoop, since delivering a message waits for room
+// in the channels of its recipients.
+func (t *transport) Send(msg *protocol.Message) {
+	go t.network.Send(msg)
+}
+
+// Receive is called by protocol.Run at the start of each protocol. From the second one on, it first waits
+// for all parties to be done with the previous protocol, as HandlerLoop does when a protocol ends,
+// so that the messages of the next protocol don't reach parties still running the previous one.
+func (t *transport) Receive() <-chan *protocol.Message {
+	if t.started {
+		<-t.network.Done(t.id)
+	}
+	t.started = true
+	return t.network.Next(t.id)
+}
+
+func (t *transport) Parties() []party.ID {
+	t.network.mtx.Lock()
+	defer t.network.mtx.Unlock()
+	return append([]party.ID(nil), t.network.parties...)
+}
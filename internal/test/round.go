@@ -22,6 +22,16 @@ type Rule interface {
 	ModifyContent(rNext round.Session, to party.ID, content round.Content)
 }
 
+// RunRounds calls Rounds until the rounds are done, and returns the first error.
+func RunRounds(rounds []round.Session, rule Rule) error {
+	for {
+		err, done := Rounds(rounds, rule)
+		if err != nil || done {
+			return err
+		}
+	}
+}
+
 func Rounds(rounds []round.Session, rule Rule) (error, bool) {
 	var (
 		err       error
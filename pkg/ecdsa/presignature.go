@@ -3,6 +3,7 @@ package ecdsa
 import (
 	"errors"
 	"fmt"
+	"sync/atomic"
 
 	"github.com/luxfi/threshold/internal/types"
 	"github.com/luxfi/threshold/pkg/math/curve"
@@ -22,6 +23,43 @@ type PreSignature struct {
 	KShare curve.Scalar
 	// ChiShare = χᵢ
 	ChiShare curve.Scalar
+	// Generation is the generation of the config the presignature was computed with.
+	// χᵢ depends on the secret share, which a resharing replaces, so the presignature
+	// can only sign with a config of the same generation.
+	Generation uint64
+
+	used atomic.Bool
+}
+
+// ErrPreSignatureUsed is returned when a PreSignature is used to sign a second time.
+var ErrPreSignatureUsed = errors.New("presignature: already used")
+
+// Consume marks the PreSignature as used, and returns ErrPreSignatureUsed if it already was.
+//
+// A PreSignature holds the nonce k of a signature, so it must sign at most one message:
+// two signatures with the same nonce reveal the secret key.
+func (sig *PreSignature) Consume() error {
+	if !sig.used.CompareAndSwap(false, true) {
+		return ErrPreSignatureUsed
+	}
+	return nil
+}
+
+// ErrPreSignatureGeneration is returned when a PreSignature is used with a config of another generation.
+var ErrPreSignatureGeneration = errors.New("presignature: computed for another generation of the key")
+
+// CheckGeneration returns ErrPreSignatureGeneration if the PreSignature was not computed
+// with a config of the given generation, for instance before the key was reshared.
+func (sig *PreSignature) CheckGeneration(generation uint64) error {
+	if sig.Generation != generation {
+		return fmt.Errorf("%w: presignature is from generation %d, config is at generation %d", ErrPreSignatureGeneration, sig.Generation, generation)
+	}
+	return nil
+}
+
+// Used returns true if the PreSignature was already consumed by a signing session.
+func (sig *PreSignature) Used() bool {
+	return sig.used.Load()
 }
 
 // Group returns the elliptic curve group associated with this PreSignature.
@@ -110,6 +148,51 @@ func (sig *PreSignature) Validate() error {
 	return nil
 }
 
+// KeyConfig is the public information about a key needed to verify a PreSignature.
+//
+// It is implemented by the CMP Config.
+type KeyConfig interface {
+	// PublicPoint returns the public key X of the shared ECDSA key.
+	PublicPoint() curve.Point
+}
+
+// Verify checks that the PreSignature is internally consistent with the key of config,
+// so that a corrupted PreSignature can be discarded before an online signing fails.
+//
+// On top of the checks in Validate, it verifies that
+//   - ∑ⱼ R̄ⱼ = G, since R̄ⱼ = (k⁻¹kⱼ)⋅G,
+//   - ∑ⱼ Sⱼ = X, since Sⱼ = χⱼ⋅R and ∑ⱼχⱼ = k⋅x,
+//   - some signer j has R̄ⱼ = kᵢ⋅R and Sⱼ = χᵢ⋅R, so that our own shares match the committed nonce.
+func (sig *PreSignature) Verify(config KeyConfig) error {
+	if sig.R == nil || sig.RBar == nil || sig.S == nil || sig.KShare == nil || sig.ChiShare == nil {
+		return errors.New("presignature: missing fields")
+	}
+	if err := sig.Validate(); err != nil {
+		return err
+	}
+	group := sig.Group()
+
+	sumRBar, sumS := group.NewPoint(), group.NewPoint()
+	for id, RBar := range sig.RBar.Points {
+		sumRBar = sumRBar.Add(RBar)
+		sumS = sumS.Add(sig.S.Points[id])
+	}
+	if !sumRBar.Equal(group.NewBasePoint()) {
+		return errors.New("presignature: RBar shares are inconsistent with R")
+	}
+	if !sumS.Equal(config.PublicPoint()) {
+		return errors.New("presignature: S shares are inconsistent with the public key")
+	}
+
+	KR, ChiR := sig.KShare.Act(sig.R), sig.ChiShare.Act(sig.R)
+	for id, RBar := range sig.RBar.Points {
+		if RBar.Equal(KR) && sig.S.Points[id].Equal(ChiR) {
+			return nil
+		}
+	}
+	return errors.New("presignature: own shares don't match the committed nonce")
+}
+
 func (sig *PreSignature) SignerIDs() party.IDSlice {
 	ids := make([]party.ID, 0, len(sig.RBar.Points))
 	for id := range sig.RBar.Points {
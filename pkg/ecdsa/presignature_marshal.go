@@ -0,0 +1,56 @@
+package ecdsa
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/luxfi/threshold/internal/types"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+)
+
+// preSignatureMarshal is a copy of PreSignature for the purpose of cbor marshalling.
+type preSignatureMarshal struct {
+	ID               types.RID
+	R                curve.Point
+	RBar, S          *party.PointMap
+	KShare, ChiShare curve.Scalar
+	// Generation is absent from presignatures encoded before it was recorded, which are of generation 0
+	Generation uint64 `cbor:",omitempty"`
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (sig *PreSignature) MarshalBinary() ([]byte, error) {
+	return cbor.Marshal(&preSignatureMarshal{
+		ID:         sig.ID,
+		R:          sig.R,
+		RBar:       sig.RBar,
+		S:          sig.S,
+		KShare:     sig.KShare,
+		ChiShare:   sig.ChiShare,
+		Generation: sig.Generation,
+	})
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+//
+// The PreSignature must be created with EmptyPreSignature first, so that the points can be decoded.
+func (sig *PreSignature) UnmarshalBinary(data []byte) error {
+	if sig.R == nil || sig.RBar == nil || sig.S == nil || sig.KShare == nil || sig.ChiShare == nil {
+		return errors.New("presignature must be initialized using EmptyPreSignature")
+	}
+	m := &preSignatureMarshal{
+		R:        sig.R,
+		RBar:     sig.RBar,
+		S:        sig.S,
+		KShare:   sig.KShare,
+		ChiShare: sig.ChiShare,
+	}
+	if err := cbor.Unmarshal(data, m); err != nil {
+		return fmt.Errorf("presignature: %w", err)
+	}
+	sig.ID = m.ID
+	sig.Generation = m.Generation
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/internal/types"
 	"github.com/luxfi/threshold/pkg/math/curve"
 	"github.com/luxfi/threshold/pkg/math/sample"
 	"github.com/luxfi/threshold/pkg/party"
@@ -108,3 +109,75 @@ func TestPreSignature_Fail(t *testing.T) {
 		}
 	}
 }
+
+type testKeyConfig struct{ X curve.Point }
+
+func (c testKeyConfig) PublicPoint() curve.Point { return c.X }
+
+func TestPreSignature_VerifyConsistency(t *testing.T) {
+	N := 5
+	group := curve.Secp256k1{}
+	_, X, preSignatures := NewPreSignatures(group, N)
+	config := testKeyConfig{X}
+
+	for _, preSignature := range preSignatures {
+		preSignature.ID, _ = types.NewRID(mrand.New(mrand.NewSource(1)))
+		if err := preSignature.Verify(config); err != nil {
+			t.Errorf("valid presignature rejected: %v", err)
+		}
+
+		// survives a round trip through its binary encoding
+		data, err := preSignature.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded := EmptyPreSignature(group)
+		if err = decoded.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if err = decoded.Verify(config); err != nil {
+			t.Errorf("decoded presignature rejected: %v", err)
+		}
+	}
+
+	otherKey := testKeyConfig{sample.Scalar(mrand.New(mrand.NewSource(2)), group).ActOnBase()}
+	tampers := map[string]func(sig *PreSignature){
+		"R":        func(sig *PreSignature) { sig.R = sig.R.Add(group.NewBasePoint()) },
+		"KShare":   func(sig *PreSignature) { sig.KShare = group.NewScalar().Set(sig.KShare).Negate() },
+		"ChiShare": func(sig *PreSignature) { sig.ChiShare = group.NewScalar().Set(sig.ChiShare).Negate() },
+		"RBar": func(sig *PreSignature) {
+			for id, p := range sig.RBar.Points {
+				sig.RBar.Points[id] = p.Add(group.NewBasePoint())
+				return
+			}
+		},
+		"S": func(sig *PreSignature) {
+			for id, p := range sig.S.Points {
+				sig.S.Points[id] = p.Add(group.NewBasePoint())
+				return
+			}
+		},
+	}
+	for name, tamper := range tampers {
+		t.Run(name, func(t *testing.T) {
+			_, X, preSignatures := NewPreSignatures(group, N)
+			for _, preSignature := range preSignatures {
+				preSignature.ID, _ = types.NewRID(mrand.New(mrand.NewSource(1)))
+				data, _ := preSignature.MarshalBinary()
+				tampered := EmptyPreSignature(group)
+				_ = tampered.UnmarshalBinary(data)
+				tamper(tampered)
+				if err := tampered.Verify(testKeyConfig{X}); err == nil {
+					t.Error("tampered presignature accepted")
+				}
+				break
+			}
+		})
+	}
+
+	for _, preSignature := range preSignatures {
+		if err := preSignature.Verify(otherKey); err == nil {
+			t.Error("presignature accepted for a different key")
+		}
+	}
+}
@@ -1,7 +1,18 @@
 package ecdsa
 
 import (
+	"bytes"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/luxfi/threshold/pkg/math/curve"
+	"golang.org/x/crypto/sha3"
 )
 
 type Signature struct {
@@ -14,6 +25,62 @@ func EmptySignature(group curve.Curve) Signature {
 	return Signature{R: group.NewPoint(), S: group.NewScalar()}
 }
 
+// signatureJSON is the JSON encoding of a Signature, with R and S in hex.
+type signatureJSON struct {
+	R string `json:"R"`
+	S string `json:"S"`
+}
+
+// SignatureJSONType returns the type of the document Signature is encoded as by MarshalJSON,
+// so that a schema of the JSON encoding can be derived from it.
+func SignatureJSONType() reflect.Type {
+	return reflect.TypeOf(signatureJSON{})
+}
+
+// MarshalJSON implements json.Marshaler.
+func (sig Signature) MarshalJSON() ([]byte, error) {
+	if sig.R == nil || sig.S == nil {
+		return nil, errors.New("ecdsa: signature is incomplete")
+	}
+	R, err := sig.R.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	S, err := sig.S.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(signatureJSON{R: hex.EncodeToString(R), S: hex.EncodeToString(S)})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+//
+// The signature must be created with EmptySignature first, to set its group.
+func (sig *Signature) UnmarshalJSON(data []byte) error {
+	if sig.R == nil || sig.S == nil {
+		return errors.New("ecdsa: signature must be created with EmptySignature before unmarshalling")
+	}
+	var decoded signatureJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	R, err := hex.DecodeString(decoded.R)
+	if err != nil {
+		return fmt.Errorf("ecdsa: invalid R: %w", err)
+	}
+	S, err := hex.DecodeString(decoded.S)
+	if err != nil {
+		return fmt.Errorf("ecdsa: invalid S: %w", err)
+	}
+	if err := sig.R.UnmarshalBinary(R); err != nil {
+		return fmt.Errorf("ecdsa: invalid R: %w", err)
+	}
+	if err := sig.S.UnmarshalBinary(S); err != nil {
+		return fmt.Errorf("ecdsa: invalid S: %w", err)
+	}
+	return nil
+}
+
 // Verify is a custom signature format using curve data.
 func (sig Signature) Verify(X curve.Point, hash []byte) bool {
 	group := X.Curve()
@@ -32,41 +99,199 @@ func (sig Signature) Verify(X curve.Point, hash []byte) bool {
 	return R2.Equal(sig.R)
 }
 
-// get a signature in ethereum format
+// VerifyAll verifies the signatures output by each signer of one signing session, which must all be the same.
+//
+// It returns the indices of the signatures which are missing, don't verify, or differ from the first valid one,
+// so that a divergent signer stands out, and true if there are none.
+func VerifyAll(sigs []*Signature, X curve.Point, hash []byte) (bool, []int) {
+	var reference *Signature
+	var failed []int
+	for i, sig := range sigs {
+		if sig == nil || sig.R == nil || sig.S == nil || !sig.Verify(X, hash) {
+			failed = append(failed, i)
+			continue
+		}
+		if reference == nil {
+			reference = sig
+		} else if !sig.R.Equal(reference.R) || !sig.S.Equal(reference.S) {
+			failed = append(failed, i)
+		}
+	}
+	return len(sigs) > 0 && len(failed) == 0, failed
+}
+
+// IsLowS reports whether S is in the lower half of the order, as required by Bitcoin and Ethereum.
+func (sig Signature) IsLowS() bool {
+	return !sig.S.IsOverHalfOrder()
+}
+
+// Normalize replaces a signature with S in the upper half of the order by the equivalent
+// signature with low S, and reports whether it changed.
+//
+// Both signatures verify for the same key and hash, so a third party can turn one into the other;
+// normalizing removes this malleability.
+func (sig *Signature) Normalize() bool {
+	if sig.IsLowS() {
+		return false
+	}
+	// (R, s) and (-R, -s) are both valid, and share the x coordinate r
+	sig.S = sig.S.Curve().NewScalar().Set(sig.S).Negate()
+	sig.R = sig.R.Negate()
+	return true
+}
+
+// SigEthereum returns the signature in Ethereum format.
+//
+// Deprecated: use EthereumRSV.
 func (sig Signature) SigEthereum() ([]byte, error) {
-	IsOverHalfOrder := sig.S.IsOverHalfOrder() // s-values greater than secp256k1n/2 are considered invalid
+	return sig.EthereumRSV()
+}
 
-	if IsOverHalfOrder {
-		sig.S.Negate()
+// EthereumRSV returns the 65 byte signature r || s || v used by Ethereum, where v is the recovery id (0 or 1).
+//
+// s is normalized to the lower half of the order, as required by Ethereum, and v is adjusted accordingly.
+// sig itself is not modified.
+func (sig Signature) EthereumRSV() ([]byte, error) {
+	if _, ok := sig.R.Curve().(curve.Secp256k1); !ok {
+		return nil, fmt.Errorf("ecdsa: Ethereum signatures require secp256k1, got %s", sig.R.Curve().Name())
+	}
+	if sig.R.IsIdentity() || sig.S.IsZero() {
+		return nil, errors.New("ecdsa: invalid signature")
+	}
+	R, err := sig.R.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	r, err := sig.R.XScalar().MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	// the recovery id can only express x coordinates of R which are smaller than the order
+	if !bytes.Equal(r, R[1:]) {
+		return nil, errors.New("ecdsa: x coordinate of R is not a scalar")
+	}
+	v := R[0] - 2
+	s := sig.R.Curve().NewScalar().Set(sig.S)
+	if s.IsOverHalfOrder() {
+		// (R, s) and (-R, -s) are both valid, and -R has the opposite parity
+		s.Negate()
+		v ^= 1
+	}
+	sBytes, err := s.MarshalBinary()
+	if err != nil {
+		return nil, err
 	}
 
-	r, err := sig.R.MarshalBinary()
+	rsv := make([]byte, 0, 65)
+	rsv = append(rsv, r...)
+	rsv = append(rsv, sBytes...)
+	return append(rsv, v), nil
+}
+
+// BitcoinDER returns the signature as the ASN.1 DER sequence of r and s used by Bitcoin.
+//
+// s is normalized to the lower half of the order, as required by BIP-62, so the
+// encoding is canonical. sig itself is not modified.
+func (sig Signature) BitcoinDER() ([]byte, error) {
+	if _, ok := sig.R.Curve().(curve.Secp256k1); !ok {
+		return nil, fmt.Errorf("ecdsa: Bitcoin signatures require secp256k1, got %s", sig.R.Curve().Name())
+	}
+	r := sig.R.XScalar()
+	if r.IsZero() || sig.S.IsZero() {
+		return nil, errors.New("ecdsa: invalid signature")
+	}
+	s := sig.R.Curve().NewScalar().Set(sig.S)
+	if s.IsOverHalfOrder() {
+		s.Negate()
+	}
+	rBytes, err := r.MarshalBinary()
 	if err != nil {
 		return nil, err
 	}
-	s, err := sig.S.MarshalBinary()
+	sBytes, err := s.MarshalBinary()
 	if err != nil {
 		return nil, err
 	}
+	// asn1 encodes positive integers minimally, with a leading zero only when the top bit is set
+	return asn1.Marshal(struct{ R, S *big.Int }{
+		R: new(big.Int).SetBytes(rBytes),
+		S: new(big.Int).SetBytes(sBytes),
+	})
+}
+
+// RecoverEthereumPublicKey returns the secp256k1 public key for which rsv is a valid signature of hash.
+//
+// rsv is an Ethereum signature r || s || v, where v is either the recovery id (0 or 1) or the recovery id plus 27.
+// Signatures with s in the upper half of the order are rejected.
+func RecoverEthereumPublicKey(hash, rsv []byte) (curve.Point, error) {
+	if len(rsv) != 65 {
+		return nil, fmt.Errorf("ecdsa: Ethereum signature must be 65 bytes, got %d", len(rsv))
+	}
+	v := rsv[64]
+	if v >= 27 {
+		v -= 27
+	}
+	if v > 1 {
+		return nil, fmt.Errorf("ecdsa: invalid recovery id %d", rsv[64])
+	}
 
-	rs := make([]byte, 0, 65)
-	rs = append(rs, r...)
-	rs = append(rs, s...)
+	group := curve.Secp256k1{}
+	r, s := group.NewScalar(), group.NewScalar()
+	if err := r.UnmarshalBinary(rsv[:32]); err != nil {
+		return nil, fmt.Errorf("ecdsa: invalid r: %w", err)
+	}
+	if err := s.UnmarshalBinary(rsv[32:64]); err != nil {
+		return nil, fmt.Errorf("ecdsa: invalid s: %w", err)
+	}
+	if r.IsZero() || s.IsZero() {
+		return nil, errors.New("ecdsa: invalid signature")
+	}
+	if s.IsOverHalfOrder() {
+		return nil, errors.New("ecdsa: s is not normalized")
+	}
+	R := group.NewPoint()
+	if err := R.UnmarshalBinary(append([]byte{2 + v}, rsv[:32]...)); err != nil {
+		return nil, fmt.Errorf("ecdsa: invalid r: %w", err)
+	}
 
-	if IsOverHalfOrder {
-		v := rs[0] - 2 // Convert to Ethereum signature format with 'recovery id' v at the end.
-		copy(rs, rs[1:])
-		rs[64] = v ^ 1
-	} else {
-		v := rs[0] - 2
-		copy(rs, rs[1:])
-		rs[64] = v
+	// X = r⁻¹(s⋅R - m⋅G)
+	m := curve.FromHash(group, hash)
+	rInv := group.NewScalar().Set(r).Invert()
+	X := rInv.Act(s.Act(R).Sub(m.ActOnBase()))
+	if X.IsIdentity() || !(Signature{R: R, S: s}).Verify(X, hash) {
+		return nil, errors.New("ecdsa: failed to recover public key")
 	}
+	return X, nil
+}
 
-	r[0] = rs[64] + 2
-	if err := sig.R.UnmarshalBinary(r); err != nil {
-		return nil, err
+// RecoverEthereumAddress returns the address of the Ethereum account which produced the signature rsv of hash.
+//
+// See RecoverEthereumPublicKey for the accepted signatures.
+func RecoverEthereumAddress(hash, rsv []byte) ([20]byte, error) {
+	X, err := RecoverEthereumPublicKey(hash, rsv)
+	if err != nil {
+		return [20]byte{}, err
 	}
+	return EthereumAddress(X)
+}
 
-	return rs, nil
+// EthereumAddress returns the Ethereum address of the secp256k1 public key X,
+// which is the last 20 bytes of the Keccak-256 hash of its uncompressed coordinates.
+func EthereumAddress(X curve.Point) ([20]byte, error) {
+	var address [20]byte
+	if _, ok := X.Curve().(curve.Secp256k1); !ok {
+		return address, fmt.Errorf("ecdsa: Ethereum addresses require secp256k1, got %s", X.Curve().Name())
+	}
+	compressed, err := X.MarshalBinary()
+	if err != nil {
+		return address, err
+	}
+	publicKey, err := secp256k1.ParsePubKey(compressed)
+	if err != nil {
+		return address, fmt.Errorf("ecdsa: invalid public key: %w", err)
+	}
+	h := sha3.NewLegacyKeccak256()
+	_, _ = h.Write(publicKey.SerializeUncompressed()[1:])
+	copy(address[:], h.Sum(nil)[12:])
+	return address, nil
 }
@@ -1,9 +1,17 @@
 package ecdsa
 
 import (
+	"bytes"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
 	"testing"
 
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	dcrdecdsa "github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+
 	"github.com/luxfi/threshold/pkg/math/curve"
 	"github.com/luxfi/threshold/pkg/math/sample"
 )
@@ -59,3 +67,238 @@ func TestSignature_Verify_Zero(t *testing.T) {
 		t.Error("zero R/S signature should not verify")
 	}
 }
+
+func TestSignature_EthereumRSV(t *testing.T) {
+	group := curve.Secp256k1{}
+
+	// private key 1 controls the well known address 0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf
+	one := make([]byte, 32)
+	one[31] = 1
+	x := group.NewScalar()
+	if err := x.UnmarshalBinary(one); err != nil {
+		t.Fatal(err)
+	}
+	want, _ := hex.DecodeString("7e5f4552091a69125d5dfcb7b8c2659029395bdf")
+	address, err := EthereumAddress(x.ActOnBase())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(address[:], want) {
+		t.Fatalf("address %x, want %x", address, want)
+	}
+
+	hash := sha256.Sum256([]byte("hello"))
+
+	// a signature produced by an independent implementation
+	compact := dcrdecdsa.SignCompact(secp256k1.PrivKeyFromBytes(one), hash[:], false)
+	rsv := append(append([]byte{}, compact[1:]...), compact[0])
+	recovered, err := RecoverEthereumAddress(hash[:], rsv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recovered != address {
+		t.Errorf("recovered %x, want %x", recovered, address)
+	}
+
+	for i := 0; i < 16; i++ {
+		sig := NewSignature(x, hash[:], nil)
+		// both (R, s) and (-R, -s) must yield the same normalized signature
+		for _, s := range []curve.Scalar{sig.S, group.NewScalar().Set(sig.S).Negate()} {
+			R := sig.R
+			if !s.Equal(sig.S) {
+				R = sig.R.Negate()
+			}
+			S := group.NewScalar().Set(s)
+			rsv, err := Signature{R: R, S: s}.EthereumRSV()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !s.Equal(S) {
+				t.Fatal("EthereumRSV modified the signature")
+			}
+			if len(rsv) != 65 || rsv[64] > 1 {
+				t.Fatalf("invalid signature %x", rsv)
+			}
+			publicKey, _, err := dcrdecdsa.RecoverCompact(append([]byte{27 + rsv[64]}, rsv[:64]...), hash[:])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(publicKey.SerializeCompressed(), mustMarshal(t, x.ActOnBase())) {
+				t.Error("independent recovery returned the wrong key")
+			}
+			recovered, err := RecoverEthereumAddress(hash[:], rsv)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if recovered != address {
+				t.Errorf("recovered %x, want %x", recovered, address)
+			}
+		}
+	}
+
+	other := sha256.Sum256([]byte("other"))
+	if recovered, err := RecoverEthereumAddress(other[:], rsv); err == nil && recovered == address {
+		t.Error("recovered the signer from a different message")
+	}
+	if _, err := RecoverEthereumAddress(hash[:], rsv[:64]); err == nil {
+		t.Error("a 64 byte signature must be rejected")
+	}
+	highS := append([]byte{}, rsv...)
+	s := group.NewScalar()
+	_ = s.UnmarshalBinary(highS[32:64])
+	sBytes, _ := s.Negate().MarshalBinary()
+	copy(highS[32:64], sBytes)
+	if _, err := RecoverEthereumAddress(hash[:], highS); err == nil {
+		t.Error("a signature with high s must be rejected")
+	}
+
+	if _, err := NewSignature(sample.Scalar(rand.Reader, curve.P256{}), hash[:], nil).EthereumRSV(); err == nil {
+		t.Error("only secp256k1 signatures can be encoded for Ethereum")
+	}
+}
+
+func mustMarshal(t *testing.T, p curve.Point) []byte {
+	data, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestSignature_BitcoinDER(t *testing.T) {
+	group := curve.Secp256k1{}
+
+	key, _ := hex.DecodeString("e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35")
+	x := group.NewScalar()
+	if err := x.UnmarshalBinary(key); err != nil {
+		t.Fatal(err)
+	}
+	publicKey := x.ActOnBase()
+	hash := sha256.Sum256([]byte("hello"))
+
+	for i := 0; i < 32; i++ {
+		sig := NewSignature(x, hash[:], nil)
+		// the high s variant of each signature must be normalized to the same encoding
+		highS := Signature{R: sig.R, S: group.NewScalar().Set(sig.S)}
+		if !highS.S.IsOverHalfOrder() {
+			highS = Signature{R: sig.R.Negate(), S: highS.S.Negate()}
+		}
+		S := group.NewScalar().Set(highS.S)
+
+		der, err := highS.BitcoinDER()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !highS.S.Equal(S) {
+			t.Fatal("BitcoinDER modified the signature")
+		}
+
+		// compare with the canonical encoding of an independent implementation
+		var r, s secp256k1.ModNScalar
+		rBytes, _ := sig.R.XScalar().MarshalBinary()
+		sBytes, _ := sig.S.MarshalBinary()
+		r.SetByteSlice(rBytes)
+		s.SetByteSlice(sBytes)
+		want := dcrdecdsa.NewSignature(&r, &s).Serialize()
+		if !bytes.Equal(der, want) {
+			t.Fatalf("DER %x, want %x", der, want)
+		}
+
+		parsed, err := dcrdecdsa.ParseDERSignature(der)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pk, err := secp256k1.ParsePubKey(mustMarshal(t, publicKey))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !parsed.Verify(hash[:], pk) {
+			t.Error("DER signature does not verify")
+		}
+	}
+
+	if _, err := NewSignature(sample.Scalar(rand.Reader, curve.P256{}), hash[:], nil).BitcoinDER(); err == nil {
+		t.Error("only secp256k1 signatures can be encoded for Bitcoin")
+	}
+}
+
+func TestSignature_Normalize(t *testing.T) {
+	group := curve.Secp256k1{}
+	x := sample.Scalar(rand.Reader, group)
+	X := x.ActOnBase()
+	m := []byte("hello")
+
+	for i := 0; i < 16; i++ {
+		sig := NewSignature(x, m, nil)
+		wasLow := sig.IsLowS()
+		S := sig.S
+
+		if changed := sig.Normalize(); changed == wasLow {
+			t.Fatalf("Normalize reported %v for low s %v", changed, wasLow)
+		}
+		if !sig.IsLowS() {
+			t.Fatal("s is not low after Normalize")
+		}
+		if !sig.Verify(X, m) {
+			t.Fatal("normalized signature does not verify")
+		}
+		if !wasLow && !S.IsOverHalfOrder() {
+			t.Fatal("Normalize modified s in place")
+		}
+		if sig.Normalize() {
+			t.Fatal("a normalized signature changed again")
+		}
+	}
+}
+
+func TestSignature_JSON(t *testing.T) {
+	group := curve.Secp256k1{}
+	x := sample.Scalar(rand.Reader, group)
+	sig := NewSignature(x, []byte("hello"), nil)
+
+	data, err := json.Marshal(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := EmptySignature(group)
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.R.Equal(sig.R) || !decoded.S.Equal(sig.S) {
+		t.Error("signature changed in a JSON round trip")
+	}
+	if !decoded.Verify(x.ActOnBase(), []byte("hello")) {
+		t.Error("decoded signature does not verify")
+	}
+
+	var empty Signature
+	if err := json.Unmarshal(data, &empty); err == nil {
+		t.Error("unmarshalling without a group must fail")
+	}
+}
+
+func TestVerifyAll(t *testing.T) {
+	group := curve.Secp256k1{}
+	m := []byte("verify all")
+	x := sample.Scalar(rand.Reader, group)
+	X := x.ActOnBase()
+	sig := NewSignature(x, m, nil)
+
+	if ok, failed := VerifyAll([]*Signature{sig, sig, sig}, X, m); !ok || len(failed) != 0 {
+		t.Errorf("identical valid signatures: got %v, %v", ok, failed)
+	}
+
+	corrupted := &Signature{R: sig.R, S: group.NewScalar().Set(sig.S).Negate()}
+	if ok, failed := VerifyAll([]*Signature{sig, corrupted, sig, nil}, X, m); ok || !slices.Equal(failed, []int{1, 3}) {
+		t.Errorf("corrupted and missing signatures: got %v, %v", ok, failed)
+	}
+
+	// a different valid signature betrays a signer which diverged from the session
+	if ok, failed := VerifyAll([]*Signature{sig, NewSignature(x, m, nil)}, X, m); ok || !slices.Equal(failed, []int{1}) {
+		t.Errorf("divergent signature: got %v, %v", ok, failed)
+	}
+
+	if ok, _ := VerifyAll(nil, X, m); ok {
+		t.Error("no signatures should not verify")
+	}
+}
@@ -70,10 +70,15 @@ func (d Decommitment) Validate() error {
 // Commit creates a commitment to data, and returns a commitment hash, and a decommitment string such that
 // commitment = h(data, decommitment).
 func (hash *Hash) Commit(data ...interface{}) (Commitment, Decommitment, error) {
+	return hash.CommitWithRand(rand.Reader, data...)
+}
+
+// CommitWithRand is like Commit, but samples the decommitment from rand.
+func (hash *Hash) CommitWithRand(rand io.Reader, data ...interface{}) (Commitment, Decommitment, error) {
 	var err error
 	decommitment := Decommitment(make([]byte, params.SecBytes))
 
-	if _, err = rand.Read(decommitment); err != nil {
+	if _, err = io.ReadFull(rand, decommitment); err != nil {
 		return nil, nil, fmt.Errorf("hash.Commit: failed to generate decommitment: %w", err)
 	}
 
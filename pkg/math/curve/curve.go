@@ -1,7 +1,9 @@
 package curve
 
 import (
+	"crypto/sha256"
 	"encoding"
+	"encoding/hex"
 
 	"github.com/cronokirby/saferith"
 )
@@ -33,6 +35,12 @@ type Curve interface {
 	SafeScalarBytes() int
 	// Order returns a Modulus holding order of this group.
 	Order() *saferith.Modulus
+	// DecompressPoint decodes a point in the compressed encoding of public keys of this curve:
+	// the 33 byte SEC 1 encoding with a 0x02 or 0x03 prefix for secp256k1 and P-256, and the
+	// RFC 8032 encoding for ed25519.
+	//
+	// Unlike Point.UnmarshalBinary, it rejects the identity, which is not a valid public key.
+	DecompressPoint(data []byte) (Point, error)
 }
 
 // Scalar represents a number modulo the order of some Elliptic Curve group.
@@ -77,6 +85,10 @@ type Scalar interface {
 	// While this can be accomplished through the Equal method, IsZero may
 	// be implemented more efficiently.
 	IsZero() bool
+	// Zero mutates this Scalar, overwriting its value, and the memory holding it, with 0.
+	//
+	// This should be called on secret Scalars once they are no longer needed.
+	Zero() Scalar
 	// Set mutates this Scalar, replacing its value with another.
 	Set(Scalar) Scalar
 	// SetNat mutates this Scalar, replacing it with the value of a number.
@@ -173,3 +185,17 @@ func FromHash(group Curve, h []byte) Scalar {
 	}
 	return group.NewScalar().SetNat(s)
 }
+
+// KeyID returns a short fingerprint of the public key p: the first 8 bytes of the SHA-256
+// hash of its binary encoding, which is the compressed point on the Weierstrass curves, in hex.
+//
+// It identifies a key independently of how the config holding it is encoded,
+// and stays the same across resharings, which don't change the public key.
+func KeyID(p Point) string {
+	data, err := p.MarshalBinary()
+	if err != nil {
+		return ""
+	}
+	digest := sha256.Sum256(data)
+	return hex.EncodeToString(digest[:8])
+}
@@ -0,0 +1,183 @@
+package curve_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/math/sample"
+)
+
+func TestDecompressPoint(t *testing.T) {
+	// the generator and 2⋅G of each curve, as compressed public keys
+	known := []struct {
+		group       curve.Curve
+		base, base2 string
+	}{
+		{curve.Secp256k1{},
+			"0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+			"02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5"},
+		{curve.P256{},
+			"036b17d1f2e12c4247f8bce6e563a440f277037d812deb33a0f4a13945d898c296",
+			"037cf27b188d034f7e8a52380304b51ac3c08969e277f21b35a60b48fc47669978"},
+		{curve.Ed25519{},
+			"5866666666666666666666666666666666666666666666666666666666666666",
+			"c9a3f86aae465f0e56513864510f3997561fa2c9e85ea21dc2292309f3cd6022"},
+	}
+	for _, tt := range known {
+		t.Run(tt.group.Name(), func(t *testing.T) {
+			G := tt.group.NewBasePoint()
+			for _, c := range []struct {
+				encoded  string
+				expected curve.Point
+			}{{tt.base, G}, {tt.base2, G.Add(G)}} {
+				data, _ := hex.DecodeString(c.encoded)
+				P, err := tt.group.DecompressPoint(data)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !P.Equal(c.expected) {
+					t.Fatalf("%s decompressed to the wrong point", c.encoded)
+				}
+			}
+
+			for i := 0; i < 10; i++ {
+				A := sample.Scalar(rand.Reader, tt.group).ActOnBase()
+				data, err := A.MarshalBinary()
+				if err != nil {
+					t.Fatal(err)
+				}
+				decompressed, err := tt.group.DecompressPoint(data)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !decompressed.Equal(A) {
+					t.Fatal("decompression differs from UnmarshalBinary")
+				}
+			}
+
+			identity, err := tt.group.NewPoint().MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err = tt.group.DecompressPoint(identity); err == nil {
+				t.Fatal("decompressed the identity")
+			}
+			if _, err = tt.group.DecompressPoint(nil); err == nil {
+				t.Fatal("decompressed an empty encoding")
+			}
+		})
+	}
+
+	// uncompressed and unknown prefixes
+	uncompressed, _ := hex.DecodeString("0479be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798" +
+		"483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8")
+	unknownPrefix, _ := hex.DecodeString("0579be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798")
+	for _, data := range [][]byte{uncompressed, unknownPrefix} {
+		if _, err := (curve.Secp256k1{}).DecompressPoint(data); err == nil {
+			t.Fatalf("decompressed %x", data)
+		}
+		if _, err := (curve.P256{}).DecompressPoint(data); err == nil {
+			t.Fatalf("decompressed %x", data)
+		}
+	}
+}
+
+func TestSecp256k1LiftX(t *testing.T) {
+	group := curve.Secp256k1{}
+	G := group.NewBasePoint().(*curve.Secp256k1Point)
+
+	even, err := group.LiftX(G.XBytes(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	odd, err := group.LiftX(G.XBytes(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the generator has an even y coordinate
+	if !even.Equal(G) || !even.HasEvenY() {
+		t.Fatal("lifting x with an even y should give the generator")
+	}
+	if !odd.Equal(G.Negate()) || odd.HasEvenY() {
+		t.Fatal("lifting x with an odd y should give the negated generator")
+	}
+
+	// x coordinates from the BIP-340 test vectors: not on the curve, and not below the field size
+	for _, x := range []string{
+		"eefdea4cdb677750a420fee807eacf21eb9898ae79b9768766e4faa04a2d4a34",
+		"fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc30",
+	} {
+		data, _ := hex.DecodeString(x)
+		if _, err := group.LiftX(data, false); err == nil {
+			t.Fatalf("lifted the invalid x coordinate %s", x)
+		}
+		if _, err := group.DecompressPoint(append([]byte{2}, data...)); err == nil {
+			t.Fatalf("decompressed the invalid x coordinate %s", x)
+		}
+	}
+	if _, err := group.LiftX(G.XBytes()[1:], false); err == nil {
+		t.Fatal("lifted a short x coordinate")
+	}
+}
+
+func TestScalarZero(t *testing.T) {
+	for _, group := range []curve.Curve{curve.Secp256k1{}, curve.P256{}, curve.Ed25519{}} {
+		s := sample.Scalar(rand.Reader, group)
+		former := group.NewScalar().Set(s)
+		if s.Zero() != s {
+			t.Errorf("%s: Zero must return its receiver", group.Name())
+		}
+		if s.Equal(former) || !s.IsZero() {
+			t.Errorf("%s: a zeroized scalar must be 0", group.Name())
+		}
+		data, err := s.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, b := range data {
+			if b != 0 {
+				t.Errorf("%s: a zeroized scalar still holds %x", group.Name(), data)
+				break
+			}
+		}
+		// the scalar can be used again
+		if !s.Set(former).Equal(former) {
+			t.Errorf("%s: a zeroized scalar can't be set again", group.Name())
+		}
+	}
+}
+
+func TestStdPublicKey(t *testing.T) {
+	hash := sha256.Sum256([]byte("standard library"))
+	for _, group := range []curve.Curve{curve.Secp256k1{}, curve.P256{}} {
+		x := sample.Scalar(rand.Reader, group)
+		public, err := curve.StdPublicKey(x.ActOnBase())
+		if err != nil {
+			t.Fatal(err)
+		}
+		d, err := x.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// a signature of crypto/ecdsa with the secret verifies under the converted key
+		sig, err := ecdsa.SignASN1(rand.Reader, &ecdsa.PrivateKey{PublicKey: *public, D: new(big.Int).SetBytes(d)}, hash[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ecdsa.VerifyASN1(public, hash[:], sig) {
+			t.Errorf("converted key of %s doesn't verify", group.Name())
+		}
+	}
+
+	if _, err := curve.StdPublicKey(curve.Secp256k1{}.NewPoint()); err == nil {
+		t.Error("the identity has no crypto/ecdsa key")
+	}
+	if _, err := curve.StdPublicKey(curve.Ed25519{}.NewBasePoint()); err == nil {
+		t.Error("ed25519 has no crypto/ecdsa key")
+	}
+}
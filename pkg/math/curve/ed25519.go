@@ -0,0 +1,330 @@
+package curve
+
+import (
+	"errors"
+	"fmt"
+
+	"filippo.io/edwards25519"
+	"github.com/cronokirby/saferith"
+)
+
+var ed25519OrderNat, _ = new(saferith.Nat).SetHex("1000000000000000000000000000000014DEF9DEA2F79CD65812631A5CF5D3ED")
+var ed25519Order = saferith.ModulusFromNat(ed25519OrderNat)
+var ed25519HalfOrderNat = new(saferith.Nat).Rsh(ed25519OrderNat, 1, 253)
+
+// ed25519MinusOne is ℓ − 1, where ℓ is the order, which is 0 as a scalar.
+var ed25519MinusOne = edwards25519.NewScalar().Subtract(edwards25519.NewScalar(), ed25519One())
+
+func ed25519One() *edwards25519.Scalar {
+	one := make([]byte, 32)
+	one[0] = 1
+	s, err := edwards25519.NewScalar().SetCanonicalBytes(one)
+	if err != nil {
+		panic(fmt.Sprintf("ed25519: %v", err))
+	}
+	return s
+}
+
+// Ed25519 is the prime order subgroup of edwards25519, which Ed25519 signatures (RFC 8032) are computed in.
+//
+// It can be used for Schnorr signatures, such as FROST, but not for ECDSA.
+//
+// Points and scalars are those of filippo.io/edwards25519, whose arithmetic is constant time.
+type Ed25519 struct{}
+
+func (Ed25519) NewPoint() Point {
+	return new(Ed25519Point)
+}
+
+func (Ed25519) NewBasePoint() Point {
+	return &Ed25519Point{point: edwards25519.NewGeneratorPoint()}
+}
+
+func (Ed25519) NewScalar() Scalar {
+	return newEd25519Scalar()
+}
+
+func (Ed25519) ScalarBits() int {
+	return 253
+}
+
+// SafeScalarBytes is 64, since the order is only slightly larger than 2²⁵²,
+// so that reducing 32 random bytes would be noticeably biased.
+func (Ed25519) SafeScalarBytes() int {
+	return 64
+}
+
+func (Ed25519) Order() *saferith.Modulus {
+	return ed25519Order
+}
+
+// DecompressPoint implements Curve.
+func (Ed25519) DecompressPoint(data []byte) (Point, error) {
+	p := new(Ed25519Point)
+	if err := p.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	if p.IsIdentity() {
+		return nil, errors.New("ed25519.DecompressPoint: point is the identity")
+	}
+	return p, nil
+}
+
+func (Ed25519) Name() string {
+	return "ed25519"
+}
+
+// Ed25519Scalar is a scalar modulo the order of Ed25519.
+// The zero value is 0.
+type Ed25519Scalar struct {
+	value edwards25519.Scalar
+}
+
+func newEd25519Scalar() *Ed25519Scalar {
+	return new(Ed25519Scalar)
+}
+
+func ed25519CastScalar(generic Scalar) *Ed25519Scalar {
+	out, ok := generic.(*Ed25519Scalar)
+	if !ok {
+		panic(fmt.Sprintf("failed to convert to ed25519Scalar: %v", generic))
+	}
+	return out
+}
+
+func (*Ed25519Scalar) Curve() Curve {
+	return Ed25519{}
+}
+
+// MarshalBinary encodes the scalar as 32 big-endian bytes, like the scalars of other curves.
+// Use BytesLE for the little-endian encoding of RFC 8032.
+func (s *Ed25519Scalar) MarshalBinary() ([]byte, error) {
+	return reverseBytes(s.value.Bytes()), nil
+}
+
+func (s *Ed25519Scalar) UnmarshalBinary(data []byte) error {
+	if len(data) != 32 {
+		return fmt.Errorf("invalid length for ed25519 scalar: %d", len(data))
+	}
+	if _, err := s.value.SetCanonicalBytes(reverseBytes(data)); err != nil {
+		return errors.New("invalid bytes for ed25519 scalar")
+	}
+	return nil
+}
+
+// BytesLE returns the 32 byte little-endian encoding of the scalar used by RFC 8032.
+func (s *Ed25519Scalar) BytesLE() []byte {
+	return s.value.Bytes()
+}
+
+// SetBytesLE sets the scalar to the canonical little-endian encoding data, as used by RFC 8032.
+func (s *Ed25519Scalar) SetBytesLE(data []byte) error {
+	if len(data) != 32 {
+		return fmt.Errorf("invalid length for ed25519 scalar: %d", len(data))
+	}
+	return s.UnmarshalBinary(reverseBytes(data))
+}
+
+// SetUniformBytesLE sets the scalar to the little-endian integer data reduced modulo the order,
+// as RFC 8032 does with the output of SHA-512.
+func (s *Ed25519Scalar) SetUniformBytesLE(data []byte) *Ed25519Scalar {
+	if len(data) == 64 {
+		if _, err := s.value.SetUniformBytes(data); err != nil {
+			panic(fmt.Sprintf("ed25519: %v", err))
+		}
+		return s
+	}
+	s.SetNat(new(saferith.Nat).SetBytes(reverseBytes(data)))
+	return s
+}
+
+func reverseBytes(data []byte) []byte {
+	out := make([]byte, len(data))
+	for i, b := range data {
+		out[len(data)-1-i] = b
+	}
+	return out
+}
+
+func (s *Ed25519Scalar) Add(that Scalar) Scalar {
+	other := ed25519CastScalar(that)
+
+	s.value.Add(&s.value, &other.value)
+	return s
+}
+
+func (s *Ed25519Scalar) Sub(that Scalar) Scalar {
+	other := ed25519CastScalar(that)
+
+	s.value.Subtract(&s.value, &other.value)
+	return s
+}
+
+func (s *Ed25519Scalar) Mul(that Scalar) Scalar {
+	other := ed25519CastScalar(that)
+
+	s.value.Multiply(&s.value, &other.value)
+	return s
+}
+
+func (s *Ed25519Scalar) Invert() Scalar {
+	s.value.Invert(&s.value)
+	return s
+}
+
+func (s *Ed25519Scalar) Negate() Scalar {
+	s.value.Negate(&s.value)
+	return s
+}
+
+func (s *Ed25519Scalar) IsOverHalfOrder() bool {
+	var value saferith.Nat
+	value.SetBytes(reverseBytes(s.value.Bytes()))
+	gt, _, _ := value.Cmp(ed25519HalfOrderNat)
+	return gt == 1
+}
+
+func (s *Ed25519Scalar) Equal(that Scalar) bool {
+	other := ed25519CastScalar(that)
+
+	return s.value.Equal(&other.value) == 1
+}
+
+func (s *Ed25519Scalar) IsZero() bool {
+	return s.value.Equal(edwards25519.NewScalar()) == 1
+}
+
+func (s *Ed25519Scalar) Zero() Scalar {
+	s.value.Set(edwards25519.NewScalar())
+	return s
+}
+
+func (s *Ed25519Scalar) Set(that Scalar) Scalar {
+	other := ed25519CastScalar(that)
+
+	s.value.Set(&other.value)
+	return s
+}
+
+func (s *Ed25519Scalar) SetNat(x *saferith.Nat) Scalar {
+	reduced := new(saferith.Nat).Mod(x, ed25519Order)
+	if _, err := s.value.SetCanonicalBytes(reverseBytes(reduced.FillBytes(make([]byte, 32)))); err != nil {
+		panic(fmt.Sprintf("ed25519: %v", err))
+	}
+	return s
+}
+
+func (s *Ed25519Scalar) Act(that Point) Point {
+	other := ed25519CastPoint(that)
+	if other.IsIdentity() {
+		return new(Ed25519Point)
+	}
+	return newEd25519Point(edwards25519.NewIdentityPoint().ScalarMult(&s.value, other.point))
+}
+
+func (s *Ed25519Scalar) ActOnBase() Point {
+	return newEd25519Point(edwards25519.NewIdentityPoint().ScalarBaseMult(&s.value))
+}
+
+// Ed25519Point is a point of the prime order subgroup of edwards25519.
+// The zero value is the identity.
+type Ed25519Point struct {
+	// point is nil for the identity
+	point *edwards25519.Point
+}
+
+// newEd25519Point returns point, which may be the identity.
+func newEd25519Point(point *edwards25519.Point) *Ed25519Point {
+	if point.Equal(edwards25519.NewIdentityPoint()) == 1 {
+		return new(Ed25519Point)
+	}
+	return &Ed25519Point{point: point}
+}
+
+func ed25519CastPoint(generic Point) *Ed25519Point {
+	out, ok := generic.(*Ed25519Point)
+	if !ok {
+		panic(fmt.Sprintf("failed to convert to ed25519Point: %v", generic))
+	}
+	return out
+}
+
+func (*Ed25519Point) Curve() Curve {
+	return Ed25519{}
+}
+
+// MarshalBinary returns the 32 byte encoding of RFC 8032:
+// y in little-endian, with the top bit holding the parity of x.
+func (p *Ed25519Point) MarshalBinary() ([]byte, error) {
+	if p.IsIdentity() {
+		return edwards25519.NewIdentityPoint().Bytes(), nil
+	}
+	return p.point.Bytes(), nil
+}
+
+// UnmarshalBinary decodes the encoding of RFC 8032,
+// and rejects points outside the prime order subgroup.
+func (p *Ed25519Point) UnmarshalBinary(data []byte) error {
+	if len(data) != 32 {
+		return fmt.Errorf("invalid length for ed25519Point: %d", len(data))
+	}
+	point, err := edwards25519.NewIdentityPoint().SetBytes(data)
+	if err != nil {
+		return errors.New("ed25519Point.UnmarshalBinary: invalid point encoding")
+	}
+	// the point is in the subgroup if and only if (ℓ − 1)⋅P + P is the identity
+	check := edwards25519.NewIdentityPoint().ScalarMult(ed25519MinusOne, point)
+	if check.Add(check, point).Equal(edwards25519.NewIdentityPoint()) != 1 {
+		return errors.New("ed25519Point.UnmarshalBinary: point is not in the prime order subgroup")
+	}
+	p.point = newEd25519Point(point).point
+	return nil
+}
+
+func (p *Ed25519Point) Add(that Point) Point {
+	other := ed25519CastPoint(that)
+
+	if p.IsIdentity() {
+		return other.clone()
+	}
+	if other.IsIdentity() {
+		return p.clone()
+	}
+	return newEd25519Point(edwards25519.NewIdentityPoint().Add(p.point, other.point))
+}
+
+func (p *Ed25519Point) Sub(that Point) Point {
+	return p.Add(that.Negate())
+}
+
+func (p *Ed25519Point) Negate() Point {
+	if p.IsIdentity() {
+		return new(Ed25519Point)
+	}
+	return &Ed25519Point{point: edwards25519.NewIdentityPoint().Negate(p.point)}
+}
+
+func (p *Ed25519Point) Equal(that Point) bool {
+	other := ed25519CastPoint(that)
+
+	if p.IsIdentity() || other.IsIdentity() {
+		return p.IsIdentity() && other.IsIdentity()
+	}
+	return p.point.Equal(other.point) == 1
+}
+
+func (p *Ed25519Point) IsIdentity() bool {
+	return p == nil || p.point == nil
+}
+
+// XScalar returns nil, since Ed25519 isn't used for ECDSA.
+func (p *Ed25519Point) XScalar() Scalar {
+	return nil
+}
+
+func (p *Ed25519Point) clone() *Ed25519Point {
+	if p.IsIdentity() {
+		return new(Ed25519Point)
+	}
+	return &Ed25519Point{point: edwards25519.NewIdentityPoint().Set(p.point)}
+}
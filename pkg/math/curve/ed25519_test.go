@@ -0,0 +1,120 @@
+package curve_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/hex"
+	"testing"
+
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/math/sample"
+)
+
+// TestEd25519RFC8032 computes TEST 1 of section 7.1 of RFC 8032 with the group operations.
+func TestEd25519RFC8032(t *testing.T) {
+	seed, _ := hex.DecodeString("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	expectedPublic, _ := hex.DecodeString("d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a")
+	expectedSig, _ := hex.DecodeString("e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e06522490155" +
+		"5fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b")
+	var message []byte
+
+	h := sha512.Sum512(seed)
+	h[0] &= 248
+	h[31] &= 127
+	h[31] |= 64
+	a := new(curve.Ed25519Scalar).SetUniformBytesLE(h[:32])
+	A := a.ActOnBase()
+	public, err := A.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(public) != hex.EncodeToString(expectedPublic) {
+		t.Fatalf("public key %x, expected %x", public, expectedPublic)
+	}
+
+	rHash := sha512.Sum512(append(h[32:], message...))
+	r := new(curve.Ed25519Scalar).SetUniformBytesLE(rHash[:])
+	R, err := r.ActOnBase().MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	kHash := sha512.New()
+	kHash.Write(R)
+	kHash.Write(public)
+	kHash.Write(message)
+	k := new(curve.Ed25519Scalar).SetUniformBytesLE(kHash.Sum(nil))
+	S := curve.Ed25519{}.NewScalar().Set(k).Mul(a).Add(r).(*curve.Ed25519Scalar)
+
+	sig := append(R, S.BytesLE()...)
+	if hex.EncodeToString(sig) != hex.EncodeToString(expectedSig) {
+		t.Fatalf("signature %x, expected %x", sig, expectedSig)
+	}
+
+	// decoding the public key gives back the same point
+	decoded := curve.Ed25519{}.NewPoint()
+	if err = decoded.UnmarshalBinary(expectedPublic); err != nil || !decoded.Equal(A) {
+		t.Fatal("failed to decode the public key")
+	}
+	S2 := new(curve.Ed25519Scalar)
+	if err = S2.SetBytesLE(expectedSig[32:]); err != nil || !S2.Equal(S) {
+		t.Fatal("failed to decode S")
+	}
+}
+
+func TestEd25519Points(t *testing.T) {
+	group := curve.Ed25519{}
+	for i := 0; i < 5; i++ {
+		a, b := sample.Scalar(rand.Reader, group), sample.Scalar(rand.Reader, group)
+		A, B := a.ActOnBase(), b.ActOnBase()
+
+		if !group.NewScalar().Set(a).Add(b).ActOnBase().Equal(A.Add(B)) {
+			t.Fatal("addition is inconsistent with scalar multiplication")
+		}
+		if !a.Act(B).Equal(group.NewScalar().Set(a).Mul(b).ActOnBase()) {
+			t.Fatal("Act is inconsistent with ActOnBase")
+		}
+		if !A.Sub(A).IsIdentity() || !A.Add(group.NewPoint()).Equal(A) {
+			t.Fatal("identity is not neutral")
+		}
+
+		data, err := A.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		A2 := group.NewPoint()
+		if err = A2.UnmarshalBinary(data); err != nil || !A2.Equal(A) {
+			t.Fatal("marshalling round trip failed")
+		}
+
+		// matches the public key derived by crypto/ed25519 from a seed
+		seed := make([]byte, ed25519.SeedSize)
+		_, _ = rand.Read(seed)
+		h := sha512.Sum512(seed)
+		h[0] &= 248
+		h[31] &= 127
+		h[31] |= 64
+		pk, err := new(curve.Ed25519Scalar).SetUniformBytesLE(h[:32]).ActOnBase().MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(pk) != string(ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey)) {
+			t.Fatal("public key differs from crypto/ed25519")
+		}
+	}
+
+	identity, err := group.NewPoint().MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := group.NewBasePoint()
+	if err = decoded.UnmarshalBinary(identity); err != nil || !decoded.IsIdentity() {
+		t.Fatal("identity marshalling round trip failed")
+	}
+
+	// the point of order 2, (0, −1), is rejected
+	order2, _ := hex.DecodeString("ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f")
+	if err = group.NewPoint().UnmarshalBinary(order2); err == nil {
+		t.Fatal("decoded a point outside the prime order subgroup")
+	}
+}
@@ -0,0 +1,322 @@
+package curve
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"filippo.io/nistec"
+	"github.com/cronokirby/saferith"
+)
+
+// P256 is the NIST P-256 curve, also known as secp256r1 or prime256v1.
+//
+// Points are those of filippo.io/nistec, whose arithmetic is constant time, like the scalar arithmetic of saferith.
+type P256 struct{}
+
+func (P256) NewPoint() Point {
+	return new(P256Point)
+}
+
+func (P256) NewBasePoint() Point {
+	return &P256Point{point: nistec.NewP256Point().SetGenerator()}
+}
+
+func (P256) NewScalar() Scalar {
+	return newP256Scalar()
+}
+
+func (P256) ScalarBits() int {
+	return 256
+}
+
+func (P256) SafeScalarBytes() int {
+	return 32
+}
+
+var p256OrderNat = new(saferith.Nat).SetBig(elliptic.P256().Params().N, 256)
+var p256Order = saferith.ModulusFromNat(p256OrderNat)
+var p256HalfOrderNat = new(saferith.Nat).Rsh(p256OrderNat, 1, 256)
+
+func (P256) Order() *saferith.Modulus {
+	return p256Order
+}
+
+// DecompressPoint implements Curve.
+func (P256) DecompressPoint(data []byte) (Point, error) {
+	if len(data) != 33 || (data[0] != 2 && data[0] != 3) {
+		return nil, errors.New("p256.DecompressPoint: not a compressed SEC 1 point")
+	}
+	point, err := nistec.NewP256Point().SetBytes(data)
+	if err != nil {
+		return nil, errors.New("p256.DecompressPoint: x coordinate not on curve")
+	}
+	return &P256Point{point: point}, nil
+}
+
+func (P256) Name() string {
+	return "P-256"
+}
+
+type P256Scalar struct {
+	value saferith.Nat
+}
+
+func newP256Scalar() *P256Scalar {
+	out := new(P256Scalar)
+	out.value.SetUint64(0).Resize(256)
+	return out
+}
+
+func p256CastScalar(generic Scalar) *P256Scalar {
+	out, ok := generic.(*P256Scalar)
+	if !ok {
+		panic(fmt.Sprintf("failed to convert to p256Scalar: %v", generic))
+	}
+	return out
+}
+
+func (*P256Scalar) Curve() Curve {
+	return P256{}
+}
+
+func (s *P256Scalar) MarshalBinary() ([]byte, error) {
+	return s.value.FillBytes(make([]byte, 32)), nil
+}
+
+func (s *P256Scalar) UnmarshalBinary(data []byte) error {
+	if len(data) != 32 {
+		return fmt.Errorf("invalid length for p256 scalar: %d", len(data))
+	}
+	var value saferith.Nat
+	value.SetBytes(data)
+	if _, _, lt := value.CmpMod(p256Order); lt != 1 {
+		return errors.New("invalid bytes for p256 scalar")
+	}
+	s.value.Mod(&value, p256Order)
+	return nil
+}
+
+func (s *P256Scalar) Add(that Scalar) Scalar {
+	other := p256CastScalar(that)
+
+	s.value.ModAdd(&s.value, &other.value, p256Order)
+	return s
+}
+
+func (s *P256Scalar) Sub(that Scalar) Scalar {
+	other := p256CastScalar(that)
+
+	s.value.ModSub(&s.value, &other.value, p256Order)
+	return s
+}
+
+func (s *P256Scalar) Mul(that Scalar) Scalar {
+	other := p256CastScalar(that)
+
+	s.value.ModMul(&s.value, &other.value, p256Order)
+	return s
+}
+
+func (s *P256Scalar) Invert() Scalar {
+	s.value.ModInverse(&s.value, p256Order)
+	return s
+}
+
+func (s *P256Scalar) Negate() Scalar {
+	s.value.ModNeg(&s.value, p256Order)
+	return s
+}
+
+func (s *P256Scalar) IsOverHalfOrder() bool {
+	gt, _, _ := s.value.Cmp(p256HalfOrderNat)
+	return gt == 1
+}
+
+func (s *P256Scalar) Equal(that Scalar) bool {
+	other := p256CastScalar(that)
+
+	return s.value.Eq(&other.value) == 1
+}
+
+func (s *P256Scalar) IsZero() bool {
+	return s.value.EqZero() == 1
+}
+
+func (s *P256Scalar) Zero() Scalar {
+	zeroNat(&s.value)
+	return s
+}
+
+func (s *P256Scalar) Set(that Scalar) Scalar {
+	other := p256CastScalar(that)
+
+	s.value.SetNat(&other.value)
+	return s
+}
+
+func (s *P256Scalar) SetNat(x *saferith.Nat) Scalar {
+	s.value.Mod(x, p256Order)
+	return s
+}
+
+func (s *P256Scalar) Act(that Point) Point {
+	other := p256CastPoint(that)
+	if other.IsIdentity() {
+		return new(P256Point)
+	}
+	point, err := nistec.NewP256Point().ScalarMult(other.point, s.bytes())
+	if err != nil {
+		panic(fmt.Sprintf("p256: scalar multiplication: %v", err))
+	}
+	return newP256Point(point)
+}
+
+func (s *P256Scalar) ActOnBase() Point {
+	point, err := nistec.NewP256Point().ScalarBaseMult(s.bytes())
+	if err != nil {
+		panic(fmt.Sprintf("p256: scalar multiplication: %v", err))
+	}
+	return newP256Point(point)
+}
+
+func (s *P256Scalar) bytes() []byte {
+	return s.value.FillBytes(make([]byte, 32))
+}
+
+// p256Identity is the SEC 1 encoding of the identity by nistec.
+var p256Identity = []byte{0}
+
+// P256Point is a point on P-256.
+// The zero value is the identity.
+type P256Point struct {
+	// point is nil for the identity
+	point *nistec.P256Point
+}
+
+// newP256Point returns point, which may be the identity.
+func newP256Point(point *nistec.P256Point) *P256Point {
+	if bytes.Equal(point.Bytes(), p256Identity) {
+		return new(P256Point)
+	}
+	return &P256Point{point: point}
+}
+
+func p256CastPoint(generic Point) *P256Point {
+	out, ok := generic.(*P256Point)
+	if !ok {
+		panic(fmt.Sprintf("failed to convert to p256Point: %v", generic))
+	}
+	return out
+}
+
+func (*P256Point) Curve() Curve {
+	return P256{}
+}
+
+// MarshalBinary encodes the point in SEC 1 compressed form.
+// The identity is encoded as 33 zero bytes.
+func (p *P256Point) MarshalBinary() ([]byte, error) {
+	if p.IsIdentity() {
+		return make([]byte, 33), nil
+	}
+	return p.point.BytesCompressed(), nil
+}
+
+func (p *P256Point) UnmarshalBinary(data []byte) error {
+	if len(data) != 33 {
+		return fmt.Errorf("invalid length for p256Point: %d", len(data))
+	}
+	if data[0] == 0 {
+		for _, b := range data[1:] {
+			if b != 0 {
+				return errors.New("p256Point.UnmarshalBinary: invalid identity encoding")
+			}
+		}
+		p.point = nil
+		return nil
+	}
+	point, err := nistec.NewP256Point().SetBytes(data)
+	if err != nil {
+		return errors.New("p256Point.UnmarshalBinary: invalid point")
+	}
+	p.point = point
+	return nil
+}
+
+func (p *P256Point) Add(that Point) Point {
+	other := p256CastPoint(that)
+
+	if p.IsIdentity() {
+		return other.clone()
+	}
+	if other.IsIdentity() {
+		return p.clone()
+	}
+	return newP256Point(nistec.NewP256Point().Add(p.point, other.point))
+}
+
+func (p *P256Point) Sub(that Point) Point {
+	return p.Add(that.Negate())
+}
+
+// Negate returns -p, which has the same x coordinate as p, and the other y coordinate.
+// In compressed form, only the parity of y changes.
+func (p *P256Point) Negate() Point {
+	if p.IsIdentity() {
+		return new(P256Point)
+	}
+	data := p.point.BytesCompressed()
+	data[0] ^= 1
+	point, err := nistec.NewP256Point().SetBytes(data)
+	if err != nil {
+		panic(fmt.Sprintf("p256: negation: %v", err))
+	}
+	return &P256Point{point: point}
+}
+
+func (p *P256Point) Equal(that Point) bool {
+	other := p256CastPoint(that)
+
+	if p.IsIdentity() || other.IsIdentity() {
+		return p.IsIdentity() && other.IsIdentity()
+	}
+	return bytes.Equal(p.point.Bytes(), other.point.Bytes())
+}
+
+func (p *P256Point) IsIdentity() bool {
+	return p == nil || p.point == nil
+}
+
+func (p *P256Point) XScalar() Scalar {
+	out := newP256Scalar()
+	if p.IsIdentity() {
+		return out
+	}
+	x, err := p.point.BytesX()
+	if err != nil {
+		return out
+	}
+	out.value.Mod(new(saferith.Nat).SetBytes(x), p256Order)
+	return out
+}
+
+// Coordinates returns the affine coordinates of the point, or nil for the identity.
+// This allows the point to be used with crypto/ecdsa.
+func (p *P256Point) Coordinates() (x, y *big.Int) {
+	if p.IsIdentity() {
+		return nil, nil
+	}
+	// the uncompressed encoding holds X then Y
+	data := p.point.Bytes()
+	return new(big.Int).SetBytes(data[1:33]), new(big.Int).SetBytes(data[33:])
+}
+
+func (p *P256Point) clone() *P256Point {
+	if p.IsIdentity() {
+		return new(P256Point)
+	}
+	return &P256Point{point: nistec.NewP256Point().Set(p.point)}
+}
@@ -0,0 +1,154 @@
+package curve_test
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/cronokirby/saferith"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/math/sample"
+)
+
+func TestP256ScalarArithmetic(t *testing.T) {
+	group := curve.P256{}
+	N := group.Order().Big()
+	toBig := func(s curve.Scalar) *big.Int {
+		data, err := s.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return new(big.Int).SetBytes(data)
+	}
+	mod := func(x *big.Int) *big.Int { return x.Mod(x, N) }
+
+	for i := 0; i < 20; i++ {
+		a, b := sample.Scalar(rand.Reader, group), sample.Scalar(rand.Reader, group)
+		A, B := toBig(a), toBig(b)
+
+		if toBig(group.NewScalar().Set(a).Add(b)).Cmp(mod(new(big.Int).Add(A, B))) != 0 {
+			t.Fatal("wrong sum")
+		}
+		if toBig(group.NewScalar().Set(a).Sub(b)).Cmp(mod(new(big.Int).Sub(A, B))) != 0 {
+			t.Fatal("wrong difference")
+		}
+		if toBig(group.NewScalar().Set(a).Mul(b)).Cmp(mod(new(big.Int).Mul(A, B))) != 0 {
+			t.Fatal("wrong product")
+		}
+		if toBig(group.NewScalar().Set(a).Negate()).Cmp(mod(new(big.Int).Neg(A))) != 0 {
+			t.Fatal("wrong negation")
+		}
+		if toBig(group.NewScalar().Set(a).Invert()).Cmp(new(big.Int).ModInverse(A, N)) != 0 {
+			t.Fatal("wrong inverse")
+		}
+		half := new(big.Int).Rsh(N, 1)
+		if a.IsOverHalfOrder() != (A.Cmp(half) > 0) {
+			t.Fatal("wrong IsOverHalfOrder")
+		}
+		if !group.NewScalar().Set(a).Sub(a).IsZero() {
+			t.Fatal("a - a is not zero")
+		}
+		if !group.NewScalar().Set(a).Equal(a) || a.Equal(b) {
+			t.Fatal("wrong equality")
+		}
+	}
+
+	// SetNat reduces modulo the order
+	s := group.NewScalar().SetNat(new(saferith.Nat).SetBig(new(big.Int).Add(N, big.NewInt(5)), 512))
+	if toBig(s).Cmp(big.NewInt(5)) != 0 {
+		t.Fatal("SetNat didn't reduce")
+	}
+
+	// scalars at or above the order are rejected
+	if err := group.NewScalar().UnmarshalBinary(N.FillBytes(make([]byte, 32))); err == nil {
+		t.Fatal("unmarshalled the order as a scalar")
+	}
+}
+
+func TestP256Points(t *testing.T) {
+	group := curve.P256{}
+
+	for i := 0; i < 10; i++ {
+		a, b := sample.Scalar(rand.Reader, group), sample.Scalar(rand.Reader, group)
+		A, B := a.ActOnBase(), b.ActOnBase()
+
+		// (a + b)⋅G = a⋅G + b⋅G
+		if !group.NewScalar().Set(a).Add(b).ActOnBase().Equal(A.Add(B)) {
+			t.Fatal("addition is inconsistent with scalar multiplication")
+		}
+		// a⋅(b⋅G) = (ab)⋅G
+		if !a.Act(B).Equal(group.NewScalar().Set(a).Mul(b).ActOnBase()) {
+			t.Fatal("Act is inconsistent with ActOnBase")
+		}
+		// doubling
+		if !A.Add(A).Equal(group.NewScalar().Set(a).Add(a).ActOnBase()) {
+			t.Fatal("wrong doubling")
+		}
+		if !A.Sub(A).IsIdentity() || !A.Add(A.Negate()).IsIdentity() {
+			t.Fatal("A - A is not the identity")
+		}
+		if !A.Add(group.NewPoint()).Equal(A) || !group.NewPoint().Add(A).Equal(A) {
+			t.Fatal("identity is not neutral")
+		}
+
+		data, err := A.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		A2 := group.NewPoint()
+		if err = A2.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if !A2.Equal(A) {
+			t.Fatal("marshalling round trip failed")
+		}
+	}
+
+	if !group.NewScalar().ActOnBase().IsIdentity() {
+		t.Fatal("0⋅G is not the identity")
+	}
+	data, err := group.NewPoint().MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	identity := group.NewBasePoint()
+	if err = identity.UnmarshalBinary(data); err != nil || !identity.IsIdentity() {
+		t.Fatal("identity marshalling round trip failed")
+	}
+
+	bad := make([]byte, 33)
+	bad[0] = 2
+	for i := range bad[1:] {
+		bad[1+i] = 0xff
+	}
+	if err = group.NewPoint().UnmarshalBinary(bad); err == nil {
+		t.Fatal("unmarshalled an invalid point")
+	}
+}
+
+// TestP256MatchesStdlib checks scalar multiplication against crypto/ecdh.
+func TestP256MatchesStdlib(t *testing.T) {
+	group := curve.P256{}
+	for i := 0; i < 10; i++ {
+		x := sample.Scalar(rand.Reader, group)
+		if x.IsZero() {
+			continue
+		}
+		xBytes, err := x.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := ecdh.P256().NewPrivateKey(xBytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the uncompressed encoding holds X then Y
+		expected := key.PublicKey().Bytes()
+		X, Y := x.ActOnBase().(*curve.P256Point).Coordinates()
+		actual := append([]byte{4}, append(X.FillBytes(make([]byte, 32)), Y.FillBytes(make([]byte, 32))...)...)
+		if string(actual) != string(expected) {
+			t.Fatal("public key differs from crypto/ecdh")
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package curve
+
+import (
+	"sync"
+
+	"github.com/cronokirby/saferith"
+)
+
+// ScalarPool holds temporary Scalars of a single curve for reuse, reducing allocations in hot loops.
+//
+// Scalars are reset to 0 when they are returned, so that secret values don't outlive their use.
+// Only Scalars which are no longer referenced should be returned to the pool.
+//
+// Points are not pooled, since they are immutable, and every operation returns a new Point.
+type ScalarPool struct {
+	pool sync.Pool
+}
+
+// NewScalarPool returns an empty pool of Scalars for the given group.
+func NewScalarPool(group Curve) *ScalarPool {
+	return &ScalarPool{
+		pool: sync.Pool{New: func() interface{} { return group.NewScalar() }},
+	}
+}
+
+// Get returns a Scalar with the value 0.
+func (p *ScalarPool) Get() Scalar {
+	return p.pool.Get().(Scalar)
+}
+
+// Put zeroes s and returns it to the pool.
+func (p *ScalarPool) Put(s Scalar) {
+	if s == nil {
+		return
+	}
+	s.Zero()
+	p.pool.Put(s)
+}
+
+// scalarPools maps a curve's name to its *ScalarPool.
+var scalarPools sync.Map
+
+func scalarPool(group Curve) *ScalarPool {
+	if p, ok := scalarPools.Load(group.Name()); ok {
+		return p.(*ScalarPool)
+	}
+	p, _ := scalarPools.LoadOrStore(group.Name(), NewScalarPool(group))
+	return p.(*ScalarPool)
+}
+
+// GetScalar returns a temporary Scalar with the value 0 from a pool shared by all users of group.
+// It should be given back with PutScalar once it is no longer used.
+func GetScalar(group Curve) Scalar {
+	return scalarPool(group).Get()
+}
+
+// PutScalar zeroes s and returns it to the shared pool of its curve.
+func PutScalar(s Scalar) {
+	if s == nil {
+		return
+	}
+	scalarPool(s.Curve()).Put(s)
+}
+
+// zeroNat overwrites every limb of n with 0, keeping its announced length.
+func zeroNat(n *saferith.Nat) {
+	bits := n.AnnouncedLen()
+	n.SetBytes(make([]byte, (bits+7)/8)).Resize(bits)
+}
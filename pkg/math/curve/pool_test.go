@@ -0,0 +1,32 @@
+package curve_test
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/math/sample"
+)
+
+func TestScalarPoolZeroes(t *testing.T) {
+	group := curve.Secp256k1{}
+	p := curve.NewScalarPool(group)
+	for i := 0; i < 100; i++ {
+		s := p.Get()
+		if !s.IsZero() {
+			t.Fatal("pool returned a non-zero scalar")
+		}
+		s.Set(sample.Scalar(rand.Reader, group))
+		p.Put(s)
+		if !s.IsZero() {
+			t.Fatal("scalar was not zeroed when returned to the pool")
+		}
+	}
+
+	s := curve.GetScalar(group)
+	s.Set(sample.Scalar(rand.Reader, group))
+	curve.PutScalar(s)
+	if !s.IsZero() {
+		t.Fatal("scalar was not zeroed when returned to the shared pool")
+	}
+}
@@ -51,18 +51,37 @@ func (Secp256k1) Order() *saferith.Modulus {
 	return secp256k1Order
 }
 
-func (Secp256k1) LiftX(data []byte) (*Secp256k1Point, error) {
+// LiftX returns the point whose x coordinate is x, given as 32 big endian bytes,
+// with the odd y coordinate if odd is set, and the even one otherwise.
+//
+// With an even y coordinate, this is the lift_x function of BIP-340, which decodes x-only public keys.
+func (Secp256k1) LiftX(x []byte, odd bool) (*Secp256k1Point, error) {
+	if len(x) != 32 {
+		return nil, fmt.Errorf("secp256k1.LiftX: invalid length for x coordinate: %d", len(x))
+	}
 	out := new(Secp256k1Point)
 	out.value.Z.SetInt(1)
-	if out.value.X.SetByteSlice(data) {
-		return nil, fmt.Errorf("secp256k1Point.UnmarshalBinary: x coordinate out of range")
+	if out.value.X.SetByteSlice(x) {
+		return nil, errors.New("secp256k1.LiftX: x coordinate out of range")
 	}
-	if !secp256k1.DecompressY(&out.value.X, false, &out.value.Y) {
-		return nil, fmt.Errorf("secp256k1Point.UnmarshalBinary: x coordinate not on curve")
+	if !secp256k1.DecompressY(&out.value.X, odd, &out.value.Y) {
+		return nil, errors.New("secp256k1.LiftX: x coordinate not on curve")
 	}
 	return out, nil
 }
 
+// DecompressPoint implements Curve.
+func (c Secp256k1) DecompressPoint(data []byte) (Point, error) {
+	if len(data) != 33 || (data[0] != 2 && data[0] != 3) {
+		return nil, errors.New("secp256k1.DecompressPoint: not a compressed SEC 1 point")
+	}
+	p, err := c.LiftX(data[1:], data[0] == 3)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
 func (Secp256k1) Name() string {
 	return "secp256k1"
 }
@@ -149,6 +168,11 @@ func (s *Secp256k1Scalar) IsZero() bool {
 	return s.value.IsZero()
 }
 
+func (s *Secp256k1Scalar) Zero() Scalar {
+	s.value.Zero()
+	return s
+}
+
 func (s *Secp256k1Scalar) Set(that Scalar) Scalar {
 	other := secp256k1CastScalar(that)
 
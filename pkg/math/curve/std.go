@@ -0,0 +1,38 @@
+package curve
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// StdPublicKey returns public as an *ecdsa.PublicKey of the standard library,
+// so that it can be handed to code written against crypto/ecdsa.
+//
+// P-256 keys are on elliptic.P256(), and secp256k1 keys on secp256k1.S256() of decred,
+// which crypto/ecdsa supports through its generic, slower code.
+func StdPublicKey(public Point) (*ecdsa.PublicKey, error) {
+	if public.IsIdentity() {
+		return nil, errors.New("curve: public key is the identity")
+	}
+	switch p := public.(type) {
+	case *Secp256k1Point:
+		data, err := p.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		pk, err := secp256k1.ParsePubKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("curve: %w", err)
+		}
+		return pk.ToECDSA(), nil
+	case *P256Point:
+		x, y := p.Coordinates()
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("curve: no crypto/ecdsa key on %s", public.Curve().Name())
+	}
+}
@@ -1,14 +1,62 @@
 package polynomial
 
 import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+
 	"github.com/cronokirby/saferith"
 	"github.com/luxfi/threshold/pkg/math/curve"
 	"github.com/luxfi/threshold/pkg/party"
 )
 
+// lagrangeCacheSize bounds the number of interpolation domains whose coefficients are kept by Lagrange.
+const lagrangeCacheSize = 64
+
+// lagrangeCache holds the coefficients computed by Lagrange, by curve and interpolation domain.
+// When it is full, it is emptied.
+var lagrangeCache = struct {
+	sync.Mutex
+	coefficients map[string]map[party.ID]curve.Scalar
+}{coefficients: map[string]map[party.ID]curve.Scalar{}}
+
+// lagrangeCacheKey identifies the interpolation domain on group, whatever the order of its parties.
+func lagrangeCacheKey(group curve.Curve, interpolationDomain []party.ID) string {
+	sorted := party.NewIDSlice(interpolationDomain)
+	key := append(make([]byte, 0, 64), group.Name()...)
+	for _, id := range sorted {
+		// each ID is prefixed by its length, so that IDs can't run into each other
+		key = binary.AppendUvarint(key, uint64(len(id)))
+		key = append(key, id...)
+	}
+	return string(key)
+}
+
 // Lagrange returns the Lagrange coefficients at 0 for all parties in the interpolation domain.
+//
+// The coefficients of the last domains are cached, since the same signers often interpolate again,
+// for instance to sign several messages. The cache is shared by all callers, and safe for concurrent use.
+// The returned scalars are copies, which the caller may modify.
 func Lagrange(group curve.Curve, interpolationDomain []party.ID) map[party.ID]curve.Scalar {
-	return LagrangeFor(group, interpolationDomain, interpolationDomain...)
+	key := lagrangeCacheKey(group, interpolationDomain)
+	lagrangeCache.Lock()
+	cached, ok := lagrangeCache.coefficients[key]
+	lagrangeCache.Unlock()
+	if !ok {
+		cached = LagrangeFor(group, interpolationDomain, interpolationDomain...)
+		lagrangeCache.Lock()
+		if len(lagrangeCache.coefficients) >= lagrangeCacheSize {
+			lagrangeCache.coefficients = make(map[string]map[party.ID]curve.Scalar, lagrangeCacheSize)
+		}
+		lagrangeCache.coefficients[key] = cached
+		lagrangeCache.Unlock()
+	}
+
+	coefficients := make(map[party.ID]curve.Scalar, len(cached))
+	for id, c := range cached {
+		coefficients[id] = group.NewScalar().Set(c)
+	}
+	return coefficients
 }
 
 // LagrangeFor returns the Lagrange coefficients at 0 for all parties in the given subset.
@@ -16,16 +64,150 @@ func LagrangeFor(group curve.Curve, interpolationDomain []party.ID, subset ...pa
 	// numerator = x₀ * … * xₖ
 	scalars, numerator := getScalarsAndNumerator(group, interpolationDomain)
 
+	tmp := curve.GetScalar(group)
+	defer curve.PutScalar(tmp)
+
 	coefficients := make(map[party.ID]curve.Scalar, len(subset))
 	for _, j := range subset {
-		coefficients[j] = lagrange(group, scalars, numerator, j)
+		coefficients[j] = lagrange(group, scalars, numerator, tmp, j)
 	}
 	return coefficients
 }
 
 // LagrangeSingle returns the lagrange coefficient at 0 of the party with index j.
 func LagrangeSingle(group curve.Curve, interpolationDomain []party.ID, j party.ID) curve.Scalar {
-	return LagrangeFor(group, interpolationDomain, j)[j]
+	return LagrangeCoefficient(group, interpolationDomain, j)
+}
+
+// LagrangeCoefficient returns the Lagrange coefficient at 0 of target, which must be one of the signers,
+// without computing those of the other signers.
+//
+//	        ∏ xᵢ
+//	lⱼ(0) = -------------, for i ≠ j among the signers.
+//	        ∏ (xᵢ - xⱼ)
+func LagrangeCoefficient(group curve.Curve, signers []party.ID, target party.ID) curve.Scalar {
+	xJ := target.Scalar(group)
+	numerator := group.NewScalar().SetNat(new(saferith.Nat).SetUint64(1))
+	denominator := group.NewScalar().SetNat(new(saferith.Nat).SetUint64(1))
+	for _, i := range signers {
+		if i == target {
+			continue
+		}
+		xI := i.Scalar(group)
+		numerator.Mul(xI)
+		// xᵢ - xⱼ
+		denominator.Mul(xI.Sub(xJ))
+	}
+	return denominator.Invert().Mul(numerator)
+}
+
+// InterpolatePoints checks that the points lie on a polynomial of degree at most `degree`,
+// in the exponent, and returns the constant term of that polynomial.
+//
+// The constant is interpolated from the first degree+1 parties. Each remaining party j is then
+// swapped in for the first of those; the interpolation only gives the same constant if the point of j
+// lies on the same polynomial.
+func InterpolatePoints(group curve.Curve, points map[party.ID]curve.Point, degree int) (curve.Point, error) {
+	ids := make([]party.ID, 0, len(points))
+	for id := range points {
+		ids = append(ids, id)
+	}
+	sorted := party.NewIDSlice(ids)
+	if degree < 0 || len(sorted) <= degree {
+		return nil, fmt.Errorf("polynomial: %d points can't determine a polynomial of degree %d", len(sorted), degree)
+	}
+
+	interpolate := func(domain []party.ID) curve.Point {
+		lagrange := Lagrange(group, domain)
+		sum := group.NewPoint()
+		for _, id := range domain {
+			sum = sum.Add(lagrange[id].Act(points[id]))
+		}
+		return sum
+	}
+	base := sorted[:degree+1]
+	constant := interpolate(base)
+	domain := make([]party.ID, degree+1)
+	copy(domain, base[1:])
+	for _, j := range sorted[degree+1:] {
+		domain[degree] = j
+		if !interpolate(domain).Equal(constant) {
+			return nil, fmt.Errorf("polynomial: point of %s is not on the polynomial of degree %d", j, degree)
+		}
+	}
+	return constant, nil
+}
+
+// InterpolatePointAt checks that the points lie on a polynomial of degree at most `degree`, in the exponent,
+// like InterpolatePoints, and returns the value of that polynomial at the scalar of id.
+func InterpolatePointAt(group curve.Curve, points map[party.ID]curve.Point, degree int, id party.ID) (curve.Point, error) {
+	if _, err := InterpolatePoints(group, points, degree); err != nil {
+		return nil, err
+	}
+	ids := make([]party.ID, 0, len(points))
+	for j := range points {
+		ids = append(ids, j)
+	}
+	domain := party.NewIDSlice(ids)[:degree+1]
+
+	// lⱼ(x) = ∏ₖ (x - xₖ)/(xⱼ - xₖ), for k ≠ j in the domain
+	x := id.Scalar(group)
+	sum := group.NewPoint()
+	for _, j := range domain {
+		xJ := j.Scalar(group)
+		numerator := group.NewScalar().SetNat(new(saferith.Nat).SetUint64(1))
+		denominator := group.NewScalar().SetNat(new(saferith.Nat).SetUint64(1))
+		for _, k := range domain {
+			if k == j {
+				continue
+			}
+			xK := k.Scalar(group)
+			numerator.Mul(group.NewScalar().Set(x).Sub(xK))
+			denominator.Mul(group.NewScalar().Set(xJ).Sub(xK))
+		}
+		sum = sum.Add(numerator.Mul(denominator.Invert()).Act(points[j]))
+	}
+	return sum, nil
+}
+
+// VerifyPoint checks that the point of id lies on the polynomial of degree at most `degree`, in the exponent,
+// on which the points of the other parties lie. If constant is not nil, it must be the constant of that polynomial.
+//
+// When the points don't all lie on one polynomial, the point of id is rejected only if the other points
+// lie on one without it. If removing the point of another party is what makes them agree, that point is the wrong one,
+// and the point of id is accepted. Telling the wrong point apart needs at least degree+2 points,
+// and more than one wrong point can't be attributed.
+func VerifyPoint(group curve.Curve, points map[party.ID]curve.Point, degree int, constant curve.Point, id party.ID) error {
+	if _, ok := points[id]; !ok {
+		return fmt.Errorf("polynomial: no point for %s", id)
+	}
+	// consistent reports whether the points except those of skip lie on the polynomial
+	consistent := func(skip party.ID) bool {
+		subset := make(map[party.ID]curve.Point, len(points))
+		for j, p := range points {
+			if j != skip {
+				subset[j] = p
+			}
+		}
+		c, err := InterpolatePoints(group, subset, degree)
+		return err == nil && (constant == nil || c.Equal(constant))
+	}
+
+	if consistent("") {
+		return nil
+	}
+	if len(points) < degree+2 {
+		return fmt.Errorf("polynomial: %d points can't tell which is not on the polynomial of degree %d", len(points), degree)
+	}
+	if consistent(id) {
+		return fmt.Errorf("polynomial: point of %s is not on the polynomial of degree %d", id, degree)
+	}
+	for j := range points {
+		if j != id && consistent(j) {
+			return nil
+		}
+	}
+	return fmt.Errorf("polynomial: several points are not on the polynomial of degree %d, so the point of %s can't be verified", degree, id)
 }
 
 // getScalarsAndNumerator returns the Scalars associated to the list of party.IDs.
@@ -42,7 +224,7 @@ func getScalarsAndNumerator(group curve.Curve, interpolationDomain []party.ID) (
 }
 
 // lagrange returns the Lagrange coefficient lⱼ(0), for j in the interpolation domain.
-// The numerator is provided beforehand for efficiency reasons.
+// The numerator is provided beforehand for efficiency reasons, and tmp is used as scratch space.
 //
 // The following formulas are taken from
 // https://en.wikipedia.org/wiki/Lagrange_polynomial
@@ -52,16 +234,13 @@ func getScalarsAndNumerator(group curve.Curve, interpolationDomain []party.ID) (
 // lⱼ(0) =	--------------------------------------------------
 //
 //	xⱼ⋅(x₀ - xⱼ)⋅⋅⋅(xⱼ₋₁ - xⱼ)⋅(xⱼ₊₁ - xⱼ)⋅⋅⋅(xₖ - xⱼ).
-func lagrange(group curve.Curve, interpolationDomain map[party.ID]curve.Scalar, numerator curve.Scalar, j party.ID) curve.Scalar {
+func lagrange(group curve.Curve, interpolationDomain map[party.ID]curve.Scalar, numerator, tmp curve.Scalar, j party.ID) curve.Scalar {
 	xJ := interpolationDomain[j]
-	tmp := group.NewScalar()
 
 	// denominator = xⱼ⋅(xⱼ - x₀)⋅⋅⋅(xⱼ₋₁ - xⱼ)⋅(xⱼ₊₁ - xⱼ)⋅⋅⋅(xₖ - xⱼ)
-	denominator := group.NewScalar().SetNat(new(saferith.Nat).SetUint64(1))
+	denominator := group.NewScalar().Set(xJ)
 	for i, xI := range interpolationDomain {
 		if i == j {
-			// lⱼ *= xⱼ
-			denominator.Mul(xJ)
 			continue
 		}
 		// tmp = xᵢ - xⱼ
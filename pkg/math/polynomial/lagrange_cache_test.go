@@ -0,0 +1,83 @@
+package polynomial
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cacheTestIDs returns n party IDs. internal/test can't be imported here, since it imports this package.
+func cacheTestIDs(n int) []party.ID {
+	ids := make([]party.ID, n)
+	for i := range ids {
+		ids[i] = party.ID(fmt.Sprintf("party-%d", i))
+	}
+	return ids
+}
+
+func cachedDomains() int {
+	lagrangeCache.Lock()
+	defer lagrangeCache.Unlock()
+	return len(lagrangeCache.coefficients)
+}
+
+func TestLagrangeCacheEviction(t *testing.T) {
+	group := curve.Secp256k1{}
+	ids := cacheTestIDs(lagrangeCacheSize + 2)
+	lagrangeCache.Lock()
+	lagrangeCache.coefficients = map[string]map[party.ID]curve.Scalar{}
+	lagrangeCache.Unlock()
+
+	for i := 0; i < lagrangeCacheSize; i++ {
+		Lagrange(group, ids[i:i+2])
+		require.Equal(t, i+1, cachedDomains())
+	}
+	// a domain already cached doesn't grow the cache
+	Lagrange(group, ids[:2])
+	assert.Equal(t, lagrangeCacheSize, cachedDomains())
+
+	// one more domain empties the full cache first
+	domain := ids[lagrangeCacheSize : lagrangeCacheSize+2]
+	coefficients := Lagrange(group, domain)
+	assert.Equal(t, 1, cachedDomains())
+	want := LagrangeFor(group, domain, domain...)
+	for _, id := range domain {
+		assert.True(t, want[id].Equal(coefficients[id]), "coefficient of %s", id)
+	}
+}
+
+// TestLagrangeConcurrent interpolates over more domains than the cache holds from several goroutines,
+// which modify the coefficients they get.
+func TestLagrangeConcurrent(t *testing.T) {
+	group := curve.Secp256k1{}
+	ids := cacheTestIDs(lagrangeCacheSize + 8)
+	want := make([]map[party.ID]curve.Scalar, len(ids)-3)
+	for i := range want {
+		want[i] = LagrangeFor(group, ids[i:i+3], ids[i:i+3]...)
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for k := 0; k < 2*len(want); k++ {
+				i := (g + k) % len(want)
+				coefficients := Lagrange(group, ids[i:i+3])
+				for id, c := range want[i] {
+					if !assert.True(t, c.Equal(coefficients[id]), "coefficient of %s", id) {
+						return
+					}
+					coefficients[id].Add(c)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	assert.LessOrEqual(t, cachedDomains(), lagrangeCacheSize)
+}
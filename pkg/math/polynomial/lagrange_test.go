@@ -1,13 +1,17 @@
 package polynomial_test
 
 import (
+	"crypto/rand"
 	"testing"
 
 	"github.com/cronokirby/saferith"
 	"github.com/luxfi/threshold/internal/test"
 	"github.com/luxfi/threshold/pkg/math/curve"
 	"github.com/luxfi/threshold/pkg/math/polynomial"
+	"github.com/luxfi/threshold/pkg/math/sample"
+	"github.com/luxfi/threshold/pkg/party"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestLagrange(t *testing.T) {
@@ -29,3 +33,192 @@ func TestLagrange(t *testing.T) {
 	assert.True(t, sumEven.Equal(one))
 	assert.True(t, sumOdd.Equal(one))
 }
+
+func TestLagrangeDeterministic(t *testing.T) {
+	group := curve.Secp256k1{}
+	ids := []party.ID{"party-10", "party-2", "b", "aa", "party-1"}
+
+	// the same parties, collected in another order
+	reversed := make([]party.ID, len(ids))
+	for i, id := range ids {
+		reversed[len(ids)-1-i] = id
+	}
+	party.SortIDs(ids)
+	party.SortIDs(reversed)
+	assert.Equal(t, ids, reversed)
+
+	first := polynomial.Lagrange(group, ids[:3])
+	second := polynomial.Lagrange(group, reversed[:3])
+	require.Len(t, second, len(first))
+	for id, c := range first {
+		require.Contains(t, second, id)
+		assert.True(t, c.Equal(second[id]), "coefficient of %s", id)
+	}
+}
+
+func TestInterpolatePoints(t *testing.T) {
+	group := curve.Secp256k1{}
+	ids := test.PartyIDs(6)
+	secret := sample.Scalar(rand.Reader, group)
+	f := polynomial.NewPolynomial(group, 3, secret)
+	points := make(map[party.ID]curve.Point, len(ids))
+	for _, id := range ids {
+		points[id] = f.Evaluate(id.Scalar(group)).ActOnBase()
+	}
+
+	constant, err := polynomial.InterpolatePoints(group, points, 3)
+	require.NoError(t, err)
+	assert.True(t, constant.Equal(secret.ActOnBase()))
+	// a polynomial of degree 3 also has degree at most 4
+	_, err = polynomial.InterpolatePoints(group, points, 4)
+	assert.NoError(t, err)
+	_, err = polynomial.InterpolatePoints(group, points, 2)
+	assert.Error(t, err)
+	_, err = polynomial.InterpolatePoints(group, points, 6)
+	assert.Error(t, err, "6 points don't determine a polynomial of degree 6")
+
+	for _, id := range ids {
+		original := points[id]
+		points[id] = original.Add(group.NewBasePoint())
+		_, err = polynomial.InterpolatePoints(group, points, 3)
+		assert.Error(t, err, "point of %s was changed", id)
+		points[id] = original
+	}
+}
+
+func TestInterpolatePointAt(t *testing.T) {
+	group := curve.Secp256k1{}
+	ids := test.PartyIDs(6)
+	f := polynomial.NewPolynomial(group, 3, sample.Scalar(rand.Reader, group))
+	points := make(map[party.ID]curve.Point, len(ids))
+	for _, id := range ids[:5] {
+		points[id] = f.Evaluate(id.Scalar(group)).ActOnBase()
+	}
+
+	missing := ids[5]
+	point, err := polynomial.InterpolatePointAt(group, points, 3, missing)
+	require.NoError(t, err)
+	assert.True(t, point.Equal(f.Evaluate(missing.Scalar(group)).ActOnBase()))
+
+	points[ids[0]] = points[ids[0]].Add(group.NewBasePoint())
+	_, err = polynomial.InterpolatePointAt(group, points, 3, missing)
+	assert.Error(t, err)
+}
+
+func TestLagrangeCoefficient(t *testing.T) {
+	for _, group := range []curve.Curve{curve.Secp256k1{}, curve.Ed25519{}} {
+		ids := []party.ID{"party-10", "party-2", "b", "aa", "party-1"}
+		coefficients := polynomial.LagrangeFor(group, ids, ids...)
+		for _, id := range ids {
+			assert.True(t, polynomial.LagrangeCoefficient(group, ids, id).Equal(coefficients[id]), "coefficient of %s on %s", id, group.Name())
+		}
+	}
+}
+
+func TestLagrangeCached(t *testing.T) {
+	group := curve.Secp256k1{}
+	ids := test.PartyIDs(5)
+	want := polynomial.LagrangeFor(group, ids, ids...)
+
+	first := polynomial.Lagrange(group, ids)
+	// the cached coefficients are unaffected by changes to the returned ones
+	for _, c := range first {
+		c.Add(c)
+	}
+	reversed := []party.ID{ids[4], ids[3], ids[2], ids[1], ids[0]}
+	for _, domain := range [][]party.ID{ids, reversed} {
+		second := polynomial.Lagrange(group, domain)
+		require.Len(t, second, len(want))
+		for id, c := range want {
+			assert.True(t, c.Equal(second[id]), "coefficient of %s", id)
+		}
+	}
+
+	// another domain, or the same one on another curve, isn't served from the cache
+	assert.False(t, polynomial.Lagrange(group, ids[:4])[ids[0]].Equal(want[ids[0]]))
+	other := curve.Ed25519{}
+	assert.True(t, polynomial.Lagrange(other, ids)[ids[0]].Equal(polynomial.LagrangeCoefficient(other, ids, ids[0])))
+}
+
+func BenchmarkLagrange(b *testing.B) {
+	group := curve.Secp256k1{}
+	ids := test.PartyIDs(51)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		polynomial.Lagrange(group, ids)
+	}
+}
+
+// BenchmarkLagrangeUncached computes all coefficients each time, as Lagrange does for a new domain.
+func BenchmarkLagrangeUncached(b *testing.B) {
+	group := curve.Secp256k1{}
+	ids := test.PartyIDs(51)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		polynomial.LagrangeFor(group, ids, ids...)
+	}
+}
+
+// BenchmarkLagrangeCoefficient compares the coefficient of one party with the map holding only it.
+func BenchmarkLagrangeCoefficient(b *testing.B) {
+	group := curve.Secp256k1{}
+	ids := test.PartyIDs(51)
+	b.Run("single", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			polynomial.LagrangeCoefficient(group, ids, ids[0])
+		}
+	})
+	b.Run("map", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = polynomial.LagrangeFor(group, ids, ids[0])[ids[0]]
+		}
+	})
+}
+
+func TestVerifyPoint(t *testing.T) {
+	group := curve.Secp256k1{}
+	ids := test.PartyIDs(6)
+	secret := sample.Scalar(rand.Reader, group)
+	f := polynomial.NewPolynomial(group, 3, secret)
+	points := make(map[party.ID]curve.Point, len(ids))
+	for _, id := range ids {
+		points[id] = f.Evaluate(id.Scalar(group)).ActOnBase()
+	}
+	publicKey := secret.ActOnBase()
+
+	for _, id := range ids {
+		assert.NoError(t, polynomial.VerifyPoint(group, points, 3, nil, id))
+		assert.NoError(t, polynomial.VerifyPoint(group, points, 3, publicKey, id))
+	}
+	assert.Error(t, polynomial.VerifyPoint(group, points, 3, group.NewBasePoint(), ids[0]), "wrong constant")
+	assert.ErrorContains(t, polynomial.VerifyPoint(group, points, 3, nil, "z"), "no point")
+
+	// a single changed point is blamed on its party only
+	bad := ids[2]
+	original := points[bad]
+	points[bad] = original.Add(group.NewBasePoint())
+	for _, id := range ids {
+		for _, constant := range []curve.Point{nil, publicKey} {
+			err := polynomial.VerifyPoint(group, points, 3, constant, id)
+			if id == bad {
+				assert.ErrorContains(t, err, "point of "+string(bad)+" is not on the polynomial")
+			} else {
+				assert.NoError(t, err, "party %s", id)
+			}
+		}
+	}
+
+	// two changed points can't be told apart from the others
+	points[ids[4]] = points[ids[4]].Add(group.NewBasePoint())
+	assert.ErrorContains(t, polynomial.VerifyPoint(group, points, 3, nil, ids[0]), "several points")
+	points[ids[4]] = f.Evaluate(ids[4].Scalar(group)).ActOnBase()
+
+	// with degree+1 points, any of them could be the changed one
+	delete(points, ids[5])
+	delete(points, ids[4])
+	assert.ErrorContains(t, polynomial.VerifyPoint(group, points, 3, publicKey, ids[0]), "can't tell")
+}
@@ -2,6 +2,7 @@ package polynomial
 
 import (
 	"crypto/rand"
+	"io"
 
 	"github.com/luxfi/threshold/pkg/math/curve"
 	"github.com/luxfi/threshold/pkg/math/sample"
@@ -16,6 +17,11 @@ type Polynomial struct {
 // NewPolynomial generates a Polynomial f(X) = secret + a₁⋅X + … + aₜ⋅Xᵗ,
 // with coefficients in ℤₚ, and degree t.
 func NewPolynomial(group curve.Curve, degree int, constant curve.Scalar) *Polynomial {
+	return NewPolynomialWithRand(group, degree, constant, rand.Reader)
+}
+
+// NewPolynomialWithRand is like NewPolynomial, but samples the coefficients from rand.
+func NewPolynomialWithRand(group curve.Curve, degree int, constant curve.Scalar, rand io.Reader) *Polynomial {
 	polynomial := &Polynomial{
 		group:        group,
 		coefficients: make([]curve.Scalar, degree+1),
@@ -28,7 +34,7 @@ func NewPolynomial(group curve.Curve, degree int, constant curve.Scalar) *Polyno
 	polynomial.coefficients[0] = constant
 
 	for i := 1; i <= degree; i++ {
-		polynomial.coefficients[i] = sample.Scalar(rand.Reader, group)
+		polynomial.coefficients[i] = sample.Scalar(rand, group)
 	}
 
 	return polynomial
@@ -0,0 +1,153 @@
+package network
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/protocol"
+)
+
+// Client is a party's connection to a Relay.
+//
+// Transport returns it as a protocol.Transport, to run protocols over it.
+type Client struct {
+	id          party.ID
+	conn        net.Conn
+	incoming    chan *protocol.Message
+	closed      chan *protocol.Message
+	compression Compression
+	err         error
+	mtx         sync.Mutex
+}
+
+// Dial connects to the relay at addr and registers as the party id.
+func Dial(ctx context.Context, addr string, id party.ID) (*Client, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if err = writeFrame(conn, []byte(id)); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	closed := make(chan *protocol.Message)
+	close(closed)
+	c := &Client{
+		id:       id,
+		conn:     conn,
+		incoming: make(chan *protocol.Message, 64),
+		closed:   closed,
+	}
+	go c.readLoop()
+	return c, nil
+}
+
+// Send sends msg through the relay.
+//
+// If msg.Broadcast is set, or msg.To is empty, the relay delivers the message to all other parties,
+// and otherwise only to msg.To.
+// If sending fails, the connection is closed and the error is returned by Err.
+func (c *Client) Send(msg *protocol.Message) {
+	c.mtx.Lock()
+	compression := c.compression
+	c.mtx.Unlock()
+
+	data, err := msg.MarshalBinary()
+	if err == nil {
+		data, err = encodeFrame(compression, data)
+	}
+	if err == nil {
+		c.mtx.Lock()
+		err = writeFrame(c.conn, data)
+		c.mtx.Unlock()
+	}
+	if err != nil {
+		c.fail(err)
+	}
+}
+
+// SetCompression sets how messages sent from now on are compressed.
+// The default is CompressionNone.
+//
+// Received messages are decompressed according to whichever codec their sender used,
+// so parties of the same session don't need to agree on a setting.
+func (c *Client) SetCompression(compression Compression) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.compression = compression
+}
+
+// Next returns a channel with the messages received by id.
+// The channel is closed when the connection to the relay ends.
+func (c *Client) Next(id party.ID) <-chan *protocol.Message {
+	if id != c.id {
+		return c.closed
+	}
+	return c.incoming
+}
+
+// Transport returns the connection as the protocol.Transport of its party, for a protocol among parties,
+// which must be parties of the relay.
+// Since the relay doesn't tell its parties, they are given here, so that protocol.Run can check its messages.
+func (c *Client) Transport(parties []party.ID) protocol.Transport {
+	return &clientTransport{Client: c, parties: append([]party.ID(nil), parties...)}
+}
+
+type clientTransport struct {
+	*Client
+	parties []party.ID
+}
+
+func (t *clientTransport) Receive() <-chan *protocol.Message {
+	return t.incoming
+}
+
+func (t *clientTransport) Parties() []party.ID {
+	return t.parties
+}
+
+// Err returns the error which caused the connection to fail, if any.
+func (c *Client) Err() error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.err
+}
+
+// Close closes the connection to the relay.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Client) readLoop() {
+	defer close(c.incoming)
+	for {
+		frame, err := readFrame(c.conn)
+		if err != nil {
+			return
+		}
+		data, err := decodeFrame(frame)
+		if err != nil {
+			c.fail(err)
+			return
+		}
+		msg := &protocol.Message{}
+		if err = msg.UnmarshalBinary(data); err != nil {
+			c.fail(err)
+			return
+		}
+		c.incoming <- msg
+	}
+}
+
+func (c *Client) fail(err error) {
+	c.mtx.Lock()
+	if c.err == nil {
+		c.err = err
+	}
+	c.mtx.Unlock()
+	_ = c.conn.Close()
+}
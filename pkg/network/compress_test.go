@@ -0,0 +1,185 @@
+package network
+
+import (
+	"bytes"
+	mrand "math/rand"
+	"sync"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/pool"
+	"github.com/luxfi/threshold/pkg/protocol"
+	"github.com/luxfi/threshold/protocols/cmp/presign"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeFrame(t *testing.T) {
+	large := bytes.Repeat([]byte("compressible "), 100)
+	small := []byte{1, 2, 3}
+
+	for _, c := range []Compression{CompressionNone, CompressionGzip} {
+		for _, data := range [][]byte{large, small, {}} {
+			frame, err := encodeFrame(c, data)
+			require.NoError(t, err)
+			decoded, err := decodeFrame(frame)
+			require.NoError(t, err, c)
+			assert.Equal(t, data, decoded, c)
+		}
+	}
+
+	frame, err := encodeFrame(CompressionGzip, large)
+	require.NoError(t, err)
+	assert.Equal(t, byte(CompressionGzip), frame[0])
+	assert.Less(t, len(frame), len(large))
+
+	// gzip is skipped when it doesn't help
+	frame, err = encodeFrame(CompressionGzip, small)
+	require.NoError(t, err)
+	assert.Equal(t, byte(CompressionNone), frame[0])
+
+	_, err = encodeFrame(Compression(42), large)
+	assert.Error(t, err)
+	_, err = decodeFrame([]byte{42, 1, 2})
+	assert.Error(t, err)
+	_, err = decodeFrame(nil)
+	assert.Error(t, err)
+	_, err = decodeFrame([]byte{byte(CompressionGzip), 1, 2, 3})
+	assert.Error(t, err)
+}
+
+func TestParseCompression(t *testing.T) {
+	for _, c := range []Compression{CompressionNone, CompressionGzip} {
+		parsed, err := ParseCompression(c.String())
+		require.NoError(t, err)
+		assert.Equal(t, c, parsed)
+	}
+	_, err := ParseCompression("zstd")
+	assert.Error(t, err)
+}
+
+// TestRelayMixedCompression checks that parties using different compression settings interoperate,
+// and that broadcasts still arrive identically at every party.
+func TestRelayMixedCompression(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	a, b, c := partyIDs[0], partyIDs[1], partyIDs[2]
+	relay := startRelay(t, partyIDs)
+	clients := dialAll(t, relay, partyIDs)
+	clients[a].SetCompression(CompressionGzip)
+
+	broadcast := &protocol.Message{
+		SSID:                  []byte("ssid"),
+		From:                  a,
+		Protocol:              "test",
+		RoundNumber:           1,
+		Data:                  bytes.Repeat([]byte("proof"), 1000),
+		Broadcast:             true,
+		BroadcastVerification: []byte("verification"),
+	}
+	clients[a].Send(broadcast)
+	require.NoError(t, clients[a].Err())
+	for _, id := range []party.ID{b, c} {
+		msg := receive(t, clients[id], id)
+		assert.Equal(t, broadcast.Data, msg.Data)
+		assert.Equal(t, broadcast.Hash(), msg.Hash())
+	}
+
+	reply := &protocol.Message{SSID: []byte("ssid"), From: b, To: a, Protocol: "test", RoundNumber: 1, Data: []byte("ok")}
+	clients[b].Send(reply)
+	require.NoError(t, clients[b].Err())
+	msg := receive(t, clients[a], a)
+	assert.Equal(t, reply.Hash(), msg.Hash())
+}
+
+// captureRule records every message sent during a protocol run by test.Rounds.
+type captureRule struct {
+	mtx      sync.Mutex
+	messages []*protocol.Message
+	err      error
+}
+
+func (r *captureRule) ModifyBefore(round.Session) {}
+func (r *captureRule) ModifyAfter(round.Session)  {}
+func (r *captureRule) ModifyContent(rNext round.Session, to party.ID, content round.Content) {
+	data, err := cbor.Marshal(content)
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if err != nil {
+		r.err = err
+		return
+	}
+	r.messages = append(r.messages, &protocol.Message{
+		SSID:        rNext.SSID(),
+		From:        rNext.SelfID(),
+		To:          to,
+		Protocol:    rNext.ProtocolID(),
+		RoundNumber: content.RoundNumber(),
+		Data:        data,
+		Broadcast:   to == "",
+	})
+}
+
+// cmpPresignMessages returns the messages sent during a 4-of-7 CMP presignature.
+func cmpPresignMessages(b *testing.B) [][]byte {
+	group := curve.Secp256k1{}
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	configs, partyIDs := test.GenerateConfig(group, 7, 3, mrand.New(mrand.NewSource(1)), pl)
+	signers := partyIDs[:4]
+	rounds := make([]round.Session, 0, len(signers))
+	for _, id := range signers {
+		r, err := presign.StartPresign(configs[id], signers, nil, pl)(nil)
+		require.NoError(b, err)
+		rounds = append(rounds, r)
+	}
+
+	rule := &captureRule{}
+	for {
+		err, done := test.Rounds(rounds, rule)
+		require.NoError(b, err)
+		if done {
+			break
+		}
+	}
+	require.NoError(b, rule.err)
+
+	messages := make([][]byte, 0, len(rule.messages))
+	for _, msg := range rule.messages {
+		data, err := msg.MarshalBinary()
+		require.NoError(b, err)
+		messages = append(messages, data)
+	}
+	return messages
+}
+
+// BenchmarkCompression measures the bytes on the wire and the CPU time spent encoding and decoding
+// all messages of a 4-of-7 CMP presignature.
+func BenchmarkCompression(b *testing.B) {
+	messages := cmpPresignMessages(b)
+	for _, c := range []Compression{CompressionNone, CompressionGzip} {
+		b.Run(c.String(), func(b *testing.B) {
+			b.ReportAllocs()
+			var wire int
+			for i := 0; i < b.N; i++ {
+				wire = 0
+				for _, data := range messages {
+					frame, err := encodeFrame(c, data)
+					if err != nil {
+						b.Fatal(err)
+					}
+					if _, err = decodeFrame(frame); err != nil {
+						b.Fatal(err)
+					}
+					wire += len(frame)
+				}
+			}
+			b.ReportMetric(float64(wire), "wire-bytes/op")
+			b.ReportMetric(float64(len(messages)), "messages/op")
+		})
+	}
+}
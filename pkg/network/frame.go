@@ -0,0 +1,48 @@
+// Package network carries protocol messages between parties over TCP.
+//
+// Parties connect to a Relay, which routes each message according to its headers:
+// point-to-point messages are delivered only to msg.To,
+// and broadcast messages are delivered to every other party.
+// Messages may be compressed by their sender, see Compression.
+package network
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// maxFrameSize bounds the size of a single frame, so that a peer cannot make us allocate arbitrary amounts of memory.
+const maxFrameSize = 1 << 24
+
+// writeFrame writes data prefixed by its length as a 4 byte big-endian integer.
+func writeFrame(w io.Writer, data []byte) error {
+	if len(data) > maxFrameSize {
+		return fmt.Errorf("network: frame of %d bytes exceeds maximum of %d", len(data), maxFrameSize)
+	}
+	var header [4]byte
+	binary.BigEndian.PutUint32(header[:], uint32(len(data)))
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// readFrame reads a frame written by writeFrame.
+func readFrame(r io.Reader) ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if size > maxFrameSize {
+		return nil, errors.New("network: frame exceeds maximum size")
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
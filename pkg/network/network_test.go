@@ -0,0 +1,167 @@
+package network
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/protocol"
+	"github.com/luxfi/threshold/protocols/lss/config"
+	"github.com/luxfi/threshold/protocols/lss/keygen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startRelay(t *testing.T, partyIDs []party.ID) *Relay {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	relay := NewRelay(listener, partyIDs)
+	go func() { _ = relay.Serve() }()
+	t.Cleanup(func() { _ = relay.Close() })
+	return relay
+}
+
+func dialAll(t *testing.T, relay *Relay, partyIDs []party.ID) map[party.ID]*Client {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	clients := make(map[party.ID]*Client, len(partyIDs))
+	for _, id := range partyIDs {
+		c, err := Dial(ctx, relay.Addr().String(), id)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = c.Close() })
+		clients[id] = c
+	}
+	return clients
+}
+
+func receive(t *testing.T, c *Client, id party.ID) *protocol.Message {
+	select {
+	case msg, ok := <-c.Next(id):
+		require.True(t, ok, "connection of %s closed", id)
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatalf("%s received no message", id)
+		return nil
+	}
+}
+
+func TestRelayRouting(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	a, b, c := partyIDs[0], partyIDs[1], partyIDs[2]
+	relay := startRelay(t, partyIDs)
+	clients := dialAll(t, relay, partyIDs)
+
+	p2p := &protocol.Message{
+		SSID:        []byte("ssid"),
+		From:        a,
+		To:          b,
+		Protocol:    "test",
+		RoundNumber: 1,
+		Data:        []byte("for b only"),
+	}
+	broadcast := &protocol.Message{
+		SSID:                  []byte("ssid"),
+		From:                  a,
+		Protocol:              "test",
+		RoundNumber:           2,
+		Data:                  []byte("for everyone"),
+		Broadcast:             true,
+		BroadcastVerification: []byte("verification"),
+	}
+	clients[a].Send(p2p)
+	clients[a].Send(broadcast)
+	require.NoError(t, clients[a].Err())
+
+	expected, err := broadcast.MarshalBinary()
+	require.NoError(t, err)
+
+	// b gets both messages in order
+	msg := receive(t, clients[b], b)
+	assert.Equal(t, p2p.Data, msg.Data)
+	assert.Equal(t, p2p.Hash(), msg.Hash())
+
+	// every other party receives identical bytes for the broadcast, and c doesn't see the p2p message
+	for _, id := range []party.ID{b, c} {
+		msg := receive(t, clients[id], id)
+		actual, err := msg.MarshalBinary()
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual, "broadcast received by %s differs", id)
+		assert.Equal(t, broadcast.Hash(), msg.Hash())
+	}
+
+	// the sender doesn't receive its own broadcast
+	select {
+	case msg := <-clients[a].Next(a):
+		t.Fatalf("sender received %v", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestRelayRejectsSpoofedSender(t *testing.T) {
+	partyIDs := test.PartyIDs(2)
+	a, b := partyIDs[0], partyIDs[1]
+	relay := startRelay(t, partyIDs)
+	clients := dialAll(t, relay, partyIDs)
+
+	clients[a].Send(&protocol.Message{From: b, To: b, Protocol: "test", RoundNumber: 1, Data: []byte("spoofed")})
+
+	// the relay drops a's connection instead of delivering the message
+	select {
+	case msg, ok := <-clients[a].Next(a):
+		assert.False(t, ok, "unexpected message %v", msg)
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection of spoofing party was not closed")
+	}
+	select {
+	case msg := <-clients[b].Next(b):
+		t.Fatalf("spoofed message delivered: %v", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestRelayKeygen checks that broadcast hash verification succeeds when the broadcast round
+// of a keygen goes through the relay, with each party driven by Run.
+func TestRelayKeygen(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(3)
+	relay := startRelay(t, partyIDs)
+	clients := dialAll(t, relay, partyIDs)
+
+	results := make(chan *config.Config, len(partyIDs))
+	errs := make(chan error, len(partyIDs))
+	for _, id := range partyIDs {
+		h, err := protocol.NewMultiHandler(keygen.Start(id, partyIDs, 2, group, nil), nil)
+		require.NoError(t, err)
+		go func(h *protocol.MultiHandler, c *Client) {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			r, err := Run(ctx, h, c)
+			if err != nil {
+				errs <- err
+				return
+			}
+			results <- r.(*config.Config)
+		}(h, clients[id])
+	}
+
+	var publicKey curve.Point
+	for range partyIDs {
+		select {
+		case err := <-errs:
+			t.Fatalf("keygen failed: %v", err)
+		case c := <-results:
+			pk, err := c.PublicKey()
+			require.NoError(t, err)
+			if publicKey == nil {
+				publicKey = pk
+			}
+			assert.True(t, publicKey.Equal(pk))
+		case <-time.After(30 * time.Second):
+			t.Fatal("keygen over the relay timed out")
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/protocol"
+)
+
+// Relay routes protocol messages between a fixed set of parties connected over TCP.
+//
+// A broadcast message is forwarded as the exact frame received from the sender to every other party,
+// so that all parties hash identical bytes when verifying the broadcast round.
+// Messages for parties that haven't connected yet are queued until they do.
+type Relay struct {
+	listener net.Listener
+	parties  party.IDSlice
+	peers    map[party.ID]*peer
+	conns    map[net.Conn]struct{}
+	wg       sync.WaitGroup
+	mtx      sync.Mutex
+	closed   bool
+}
+
+// peer is the relay's side of a party's connection.
+type peer struct {
+	id    party.ID
+	conn  net.Conn
+	queue [][]byte
+	done  bool
+	cond  *sync.Cond
+	mtx   sync.Mutex
+}
+
+// NewRelay returns a Relay accepting connections on listener for the given parties.
+func NewRelay(listener net.Listener, parties []party.ID) *Relay {
+	ids := party.NewIDSlice(parties)
+	peers := make(map[party.ID]*peer, len(ids))
+	for _, id := range ids {
+		p := &peer{id: id}
+		p.cond = sync.NewCond(&p.mtx)
+		peers[id] = p
+	}
+	return &Relay{
+		listener: listener,
+		parties:  ids,
+		peers:    peers,
+		conns:    make(map[net.Conn]struct{}),
+	}
+}
+
+// Addr returns the address the relay is listening on.
+func (r *Relay) Addr() net.Addr {
+	return r.listener.Addr()
+}
+
+// Serve accepts connections until the relay is closed.
+func (r *Relay) Serve() error {
+	for {
+		conn, err := r.listener.Accept()
+		if err != nil {
+			r.mtx.Lock()
+			closed := r.closed
+			r.mtx.Unlock()
+			if closed {
+				return nil
+			}
+			return fmt.Errorf("network: accept: %w", err)
+		}
+		r.mtx.Lock()
+		if r.closed {
+			r.mtx.Unlock()
+			_ = conn.Close()
+			return nil
+		}
+		r.conns[conn] = struct{}{}
+		r.mtx.Unlock()
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			_ = r.handle(conn)
+		}()
+	}
+}
+
+// Close stops the relay and closes all connections.
+func (r *Relay) Close() error {
+	r.mtx.Lock()
+	if r.closed {
+		r.mtx.Unlock()
+		return nil
+	}
+	r.closed = true
+	err := r.listener.Close()
+	for _, p := range r.peers {
+		p.close()
+	}
+	for conn := range r.conns {
+		_ = conn.Close()
+	}
+	r.mtx.Unlock()
+	r.wg.Wait()
+	return err
+}
+
+// handle registers the party on the other end of conn, and then routes its messages.
+func (r *Relay) handle(conn net.Conn) error {
+	defer func() {
+		r.mtx.Lock()
+		delete(r.conns, conn)
+		r.mtx.Unlock()
+		_ = conn.Close()
+	}()
+
+	data, err := readFrame(conn)
+	if err != nil {
+		return err
+	}
+	p, err := r.register(party.ID(data), conn)
+	if err != nil {
+		return err
+	}
+	defer p.close()
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		p.writeLoop()
+	}()
+
+	for {
+		frame, err := readFrame(conn)
+		if err != nil {
+			return err
+		}
+		if err = r.route(p.id, frame); err != nil {
+			return err
+		}
+	}
+}
+
+func (r *Relay) register(id party.ID, conn net.Conn) (*peer, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if r.closed {
+		return nil, errors.New("network: relay closed")
+	}
+	p, ok := r.peers[id]
+	if !ok {
+		return nil, fmt.Errorf("network: unknown party %s", id)
+	}
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if p.conn != nil || p.done {
+		return nil, fmt.Errorf("network: party %s already connected", id)
+	}
+	p.conn = conn
+	return p, nil
+}
+
+// route forwards frame, sent by from, to its recipients.
+// The frame is forwarded as is, in whichever compression the sender chose.
+func (r *Relay) route(from party.ID, frame []byte) error {
+	data, err := decodeFrame(frame)
+	if err != nil {
+		return fmt.Errorf("network: invalid frame from %s: %w", from, err)
+	}
+	var msg protocol.Message
+	if err := msg.UnmarshalBinary(data); err != nil {
+		return fmt.Errorf("network: invalid message from %s: %w", from, err)
+	}
+	// a party may only send messages in its own name
+	if msg.From != from {
+		return fmt.Errorf("network: party %s sent a message from %s", from, msg.From)
+	}
+
+	// broadcast messages, and messages without a recipient, go to everyone else
+	if msg.Broadcast || msg.To == "" {
+		for _, id := range r.parties {
+			if id != from {
+				r.peers[id].enqueue(frame)
+			}
+		}
+		return nil
+	}
+
+	if p, ok := r.peers[msg.To]; ok && msg.To != from {
+		p.enqueue(frame)
+	}
+	return nil
+}
+
+func (p *peer) enqueue(frame []byte) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if p.done {
+		return
+	}
+	p.queue = append(p.queue, frame)
+	p.cond.Signal()
+}
+
+// writeLoop writes queued frames to the connection, in the order they were enqueued.
+func (p *peer) writeLoop() {
+	for {
+		p.mtx.Lock()
+		for len(p.queue) == 0 && !p.done {
+			p.cond.Wait()
+		}
+		if p.done {
+			p.mtx.Unlock()
+			return
+		}
+		frames := p.queue
+		p.queue = nil
+		conn := p.conn
+		p.mtx.Unlock()
+
+		for _, frame := range frames {
+			if err := writeFrame(conn, frame); err != nil {
+				p.close()
+				return
+			}
+		}
+	}
+}
+
+func (p *peer) close() {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if p.done {
+		return
+	}
+	p.done = true
+	if p.conn != nil {
+		_ = p.conn.Close()
+	}
+	p.cond.Broadcast()
+}
@@ -0,0 +1,45 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/luxfi/threshold/pkg/protocol"
+)
+
+// Run drives h over c until the protocol finishes, and returns the result of h.
+//
+// Outgoing messages are sent in the order h produces them, and incoming messages are passed to h.Accept.
+// If ctx is done first, h is stopped and ctx.Err() is returned.
+// The connection is left open, so that c can be used for another protocol.
+func Run(ctx context.Context, h protocol.Handler, c *Client) (interface{}, error) {
+	out := h.Listen()
+	in := c.Next(c.id)
+	for {
+		select {
+		case <-ctx.Done():
+			h.Stop()
+			return nil, ctx.Err()
+		case msg, ok := <-out:
+			if !ok {
+				return h.Result()
+			}
+			c.Send(msg)
+			if err := c.Err(); err != nil {
+				h.Stop()
+				return nil, fmt.Errorf("network: send: %w", err)
+			}
+		case msg, ok := <-in:
+			if !ok {
+				h.Stop()
+				err := c.Err()
+				if err == nil {
+					err = errors.New("connection closed")
+				}
+				return nil, fmt.Errorf("network: relay connection lost: %w", err)
+			}
+			h.Accept(msg)
+		}
+	}
+}
@@ -15,9 +15,27 @@ import (
 // to have a comparable type, but using more than 32 bytes will lead to inconsistencies
 // because of how we use this ID numerically later.
 //
-// This ID is used as an interpolation point of a polynomial sharing of the secret key.
+// This ID is used as an interpolation point of a polynomial sharing of the secret key,
+// so that two IDs mapping to the same scalar cannot be told apart: see Valid.
 type ID string
 
+// maxIDBits bounds the integer encoded by a valid ID below the order of every group,
+// the smallest being 2²⁵² + … for Ed25519, so that Scalar is injective on valid IDs.
+const maxIDBits = 252
+
+// Valid returns true if id encodes a non-zero integer below 2²⁵², without leading zero bytes.
+//
+// Scalar maps distinct valid IDs to distinct non-zero scalars in every group. An ID with leading
+// zero bytes, such as "\x00z", has the scalar of the ID without them, and an ID of 32 bytes or more
+// may be congruent to another one modulo the order of the group.
+func (id ID) Valid() bool {
+	if id == "" || id[0] == 0 {
+		return false
+	}
+	const maxLen = (maxIDBits + 7) / 8
+	return len(id) < maxLen || (len(id) == maxLen && id[0]>>(maxIDBits%8) == 0)
+}
+
 // Scalar converts this ID into a scalar.
 //
 // All of the IDs of our participants form a polynomial sharing of the secret
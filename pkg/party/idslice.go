@@ -1,12 +1,19 @@
 package party
 
 import (
+	"cmp"
 	"encoding/binary"
 	"io"
 	"sort"
 	"strings"
 )
 
+// IDSlice is a slice of IDs, which is valid if sorted in canonical order without duplicates.
+//
+// The canonical order is that of the integers encoded big-endian by the IDs, which ID.Scalar maps into the group.
+// It does not depend on the platform or on how the IDs were collected, so that every party iterates over
+// the same IDs in the same order. IDs only differing by leading zero bytes encode the same integer,
+// and are ordered by their bytes, but such IDs are not valid, see ID.Valid.
 type IDSlice []ID
 
 // NewIDSlice returns a sorted slice from partyIDs.
@@ -16,6 +23,23 @@ func NewIDSlice(partyIDs []ID) IDSlice {
 	return ids
 }
 
+// SortIDs sorts ids in place in the canonical order of IDSlice.
+func SortIDs(ids []ID) {
+	IDSlice(ids).sort()
+}
+
+// compareIDs returns -1, 0 or +1 if a comes before, is equal to, or comes after b in the canonical order.
+func compareIDs(a, b ID) int {
+	x, y := strings.TrimLeft(string(a), "\x00"), strings.TrimLeft(string(b), "\x00")
+	if len(x) != len(y) {
+		return cmp.Compare(len(x), len(y))
+	}
+	if c := strings.Compare(x, y); c != 0 {
+		return c
+	}
+	return strings.Compare(string(a), string(b))
+}
+
 // Contains returns true if partyIDs contains id.
 // Assumes that the IDSlice is valid.
 func (partyIDs IDSlice) Contains(ids ...ID) bool {
@@ -27,11 +51,17 @@ func (partyIDs IDSlice) Contains(ids ...ID) bool {
 	return true
 }
 
-// Valid returns true if the IDSlice is sorted and does not contain any duplicates.
+// Valid returns true if the IDSlice is sorted and does not contain any duplicates,
+// and if each of its IDs is valid, so that they map to distinct scalars.
 func (partyIDs IDSlice) Valid() bool {
 	n := len(partyIDs)
+	for _, id := range partyIDs {
+		if !id.Valid() {
+			return false
+		}
+	}
 	for i := 1; i < n; i++ {
-		if partyIDs[i-1] >= partyIDs[i] {
+		if compareIDs(partyIDs[i-1], partyIDs[i]) >= 0 {
 			return false
 		}
 	}
@@ -58,15 +88,15 @@ func (partyIDs IDSlice) Remove(id ID) IDSlice {
 
 // Len Less and Swap implement sort.Interface.
 func (partyIDs IDSlice) Len() int           { return len(partyIDs) }
-func (partyIDs IDSlice) Less(i, j int) bool { return partyIDs[i] < partyIDs[j] }
+func (partyIDs IDSlice) Less(i, j int) bool { return compareIDs(partyIDs[i], partyIDs[j]) < 0 }
 func (partyIDs IDSlice) Swap(i, j int)      { partyIDs[i], partyIDs[j] = partyIDs[j], partyIDs[i] }
 
 // sort is a convenience method: x.Sort() calls Sort(x).
 func (partyIDs IDSlice) sort() { sort.Sort(partyIDs) }
 
-// search returns the result of applying SearchStrings to the receiver and x.
+// search returns the index of x in the receiver, found by binary search in canonical order.
 func (partyIDs IDSlice) search(x ID) (int, bool) {
-	index := sort.Search(len(partyIDs), func(i int) bool { return partyIDs[i] >= x })
+	index := sort.Search(len(partyIDs), func(i int) bool { return compareIDs(partyIDs[i], x) >= 0 })
 	if index >= 0 && index < len(partyIDs) && partyIDs[index] == x {
 		return index, true
 	}
@@ -0,0 +1,50 @@
+package party_test
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortIDs(t *testing.T) {
+	want := []party.ID{"b", "\x00z", "z", "aa", "party-2", "party-10"}
+
+	for i := 0; i < 20; i++ {
+		ids := make([]party.ID, len(want))
+		copy(ids, want)
+		rand.Shuffle(len(ids), func(i, j int) { ids[i], ids[j] = ids[j], ids[i] })
+
+		party.SortIDs(ids)
+		assert.Equal(t, want, ids)
+		sorted := party.NewIDSlice(ids)
+		assert.Equal(t, party.IDSlice(want), sorted)
+	}
+
+	sorted := party.NewIDSlice(want)
+	for _, id := range want {
+		assert.True(t, sorted.Contains(id), id)
+	}
+	assert.False(t, sorted.Contains("party-3"))
+	assert.False(t, party.IDSlice{"aa", "b"}.Valid(), "IDs are ordered as integers, not as strings")
+	assert.False(t, party.IDSlice{"b", "b"}.Valid())
+}
+
+func TestValidIDs(t *testing.T) {
+	// "\x00z" and "z" encode the same integer, and so the same scalar
+	assert.Equal(t, party.ID("z").Scalar(curve.Secp256k1{}), party.ID("\x00z").Scalar(curve.Secp256k1{}))
+	assert.True(t, party.IDSlice{"z"}.Valid())
+	assert.False(t, party.NewIDSlice([]party.ID{"z", "\x00z"}).Valid())
+	assert.False(t, party.IDSlice{"\x00z"}.Valid())
+	assert.True(t, party.NewIDSlice([]party.ID{"b", "z", "aa", "party-2", "party-10"}).Valid())
+
+	assert.False(t, party.ID("").Valid())
+	assert.True(t, party.ID(strings.Repeat("\x0f", 32)).Valid())
+	// 32 bytes and more may be congruent to another ID modulo the order of the group
+	assert.False(t, party.ID(strings.Repeat("a", 32)).Valid())
+	assert.False(t, party.ID("\x10"+strings.Repeat("\x00", 31)).Valid())
+	assert.False(t, party.ID(strings.Repeat("\x01", 33)).Valid())
+}
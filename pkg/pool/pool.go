@@ -1,6 +1,7 @@
 package pool
 
 import (
+	"context"
 	"io"
 	"runtime"
 	"sync"
@@ -38,6 +39,8 @@ type command struct {
 	ctr *int64
 	// This channel is used to signal that the counter was modified
 	ctrChanged chan<- struct{}
+	// jobs is marked done once the worker has finished this command
+	jobs *sync.WaitGroup
 	// This is the index we evaluate our function at, when not searching
 	i int
 	f func(int) interface{}
@@ -77,6 +80,7 @@ func worker(commands <-chan command) {
 			atomic.AddInt64(c.ctr, -1)
 			c.ctrChanged <- struct{}{}
 		}
+		c.jobs.Done()
 	}
 }
 
@@ -97,6 +101,9 @@ type Pool struct {
 	commands chan command
 	// This holds the number of workers we've created
 	workerCount int
+	// jobs counts the calls to Search and Parallelize in progress, and the commands workers haven't finished,
+	// so that TearDownWait can wait for them.
+	jobs sync.WaitGroup
 }
 
 // NewPool creates a new pool, with a certain number of workers.
@@ -119,13 +126,49 @@ func NewPool(count int) *Pool {
 	return &p
 }
 
+// NewSequentialPool creates a pool without workers, which runs all work on the calling goroutine.
+//
+// It behaves like a nil *Pool, for targets without threads such as WebAssembly,
+// where the results are the same as with a pool of workers, only slower.
+func NewSequentialPool() *Pool {
+	return &Pool{}
+}
+
+// sequential returns true if p runs all work on the calling goroutine.
+func (p *Pool) sequential() bool {
+	return p == nil || p.workerCount == 0
+}
+
 // TearDown cleanly tears down a pool, closing channels, etc.
 func (p *Pool) TearDown() {
-	if p != nil {
+	if !p.sequential() {
 		close(p.commands)
 	}
 }
 
+// TearDownWait waits for the work submitted to the pool to finish, and then tears it down.
+// This includes candidates still being tried by workers after Search returned.
+//
+// If ctx is done first, ctx.Err() is returned and the pool is left running.
+// No work may be submitted to the pool once TearDownWait is called.
+func (p *Pool) TearDownWait(ctx context.Context) error {
+	if p.sequential() {
+		return nil
+	}
+	idle := make(chan struct{})
+	go func() {
+		p.jobs.Wait()
+		close(idle)
+	}()
+	select {
+	case <-idle:
+		p.TearDown()
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // Search queries the function f, until count successes are found.
 //
 // f is supposed to try a single candidate, returning nil if that candidate isn't
@@ -133,29 +176,37 @@ func (p *Pool) TearDown() {
 //
 // The result will be an array containing the first count successes.
 func (p *Pool) Search(count int, f func() interface{}) []interface{} {
-	if p == nil {
+	if p.sequential() {
 		return searchAlone(f, count)
 	}
 
+	p.jobs.Add(1)
+	defer p.jobs.Done()
+
 	results := make([]interface{}, count)
 
 	ctr := int64(count)
-	ctrChanged := make(chan struct{})
+	// Each worker may find one more result after the last one we wait for, and signal it after we returned,
+	// so there is room for all signals, and workers never block on them.
+	ctrChanged := make(chan struct{}, count+p.workerCount)
 	mu := &sync.Mutex{}
 	cmd := command{
 		search:     true,
 		ctr:        &ctr,
 		ctrChanged: ctrChanged,
+		jobs:       &p.jobs,
 		f:          func(_ int) interface{} { return f() },
 		results:    results,
 		mu:         mu,
 	}
 	cmdI := 0
 	for cmdI < p.workerCount {
+		p.jobs.Add(1)
 		select {
 		case p.commands <- cmd:
 			cmdI++
 		case <-ctrChanged:
+			p.jobs.Done()
 		}
 	}
 	for atomic.LoadInt64(&ctr) > 0 {
@@ -169,14 +220,19 @@ func (p *Pool) Search(count int, f func() interface{}) []interface{} {
 //
 // The result will be a slice containing [f(0), f(1), ..., f(count - 1)].
 func (p *Pool) Parallelize(count int, f func(int) interface{}) []interface{} {
-	if p == nil {
+	if p.sequential() {
 		return parallelizeAlone(f, count)
 	}
 
+	p.jobs.Add(1)
+	defer p.jobs.Done()
+
 	results := make([]interface{}, count)
 
 	ctr := int64(count)
-	ctrChanged := make(chan struct{})
+	// Workers signal after updating the counter, so we may return before reading the last signals:
+	// there is room for all of them, so that workers never block on them.
+	ctrChanged := make(chan struct{}, count)
 	cmdI := 0
 	for cmdI < count {
 		cmd := command{
@@ -184,16 +240,19 @@ func (p *Pool) Parallelize(count int, f func(int) interface{}) []interface{} {
 			i:          cmdI,
 			ctr:        &ctr,
 			ctrChanged: ctrChanged,
+			jobs:       &p.jobs,
 			f:          f,
 			results:    results,
 		}
 		// We won't be able to send all the commands without blocking, so we make
 		// sure to interleave picking off the results of workers to free them up
 		// to receive our commands
+		p.jobs.Add(1)
 		select {
 		case p.commands <- cmd:
 			cmdI++
 		case <-ctrChanged:
+			p.jobs.Done()
 		}
 	}
 	for atomic.LoadInt64(&ctr) > 0 {
@@ -0,0 +1,85 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	mathrand "math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// work returns modular exponentiations of values drawn from a fixed seed.
+func work(count int) func(int) interface{} {
+	source := mathrand.New(mathrand.NewSource(1))
+	modulus := new(big.Int).Lsh(big.NewInt(1), 521)
+	modulus.Sub(modulus, big.NewInt(1))
+	bases := make([]*big.Int, count)
+	for i := range bases {
+		bases[i] = new(big.Int).Rand(source, modulus)
+	}
+	return func(i int) interface{} {
+		return new(big.Int).Exp(bases[i], modulus, modulus)
+	}
+}
+
+func TestSequentialPool(t *testing.T) {
+	const count = 64
+	sequential := NewSequentialPool()
+	defer sequential.TearDown()
+	parallel := NewPool(0)
+	defer parallel.TearDown()
+
+	want := parallel.Parallelize(count, work(count))
+	got := sequential.Parallelize(count, work(count))
+	for i := range want {
+		if got[i].(*big.Int).Cmp(want[i].(*big.Int)) != 0 {
+			t.Fatalf("result %d differs from the parallel pool", i)
+		}
+	}
+
+	calls := 0
+	results := sequential.Search(3, func() interface{} {
+		calls++
+		if calls%2 == 0 {
+			return calls
+		}
+		return nil
+	})
+	if len(results) != 3 || results[0] != 2 || results[1] != 4 || results[2] != 6 {
+		t.Errorf("unexpected search results %v", results)
+	}
+}
+
+func TestTearDownWait(t *testing.T) {
+	pl := NewPool(2)
+	var finished atomic.Int32
+	started := make(chan struct{})
+	var once sync.Once
+	go pl.Parallelize(4, func(i int) interface{} {
+		once.Do(func() { close(started) })
+		time.Sleep(50 * time.Millisecond)
+		finished.Add(1)
+		return i
+	})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if err := pl.TearDownWait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline to expire first, got %v", err)
+	}
+
+	if err := pl.TearDownWait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := finished.Load(); n != 4 {
+		t.Fatalf("TearDownWait returned after %d of 4 jobs", n)
+	}
+
+	if err := NewSequentialPool().TearDownWait(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
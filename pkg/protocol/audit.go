@@ -0,0 +1,222 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/luxfi/threshold/pkg/hash"
+)
+
+// maxAuditRecordSize bounds the size of a record of an audit log, as the frames of the relay in pkg/network.
+const maxAuditRecordSize = 1 << 24
+
+// auditRecord is the encoding of an entry of an audit log, see MultiHandler.SetAuditSink.
+type auditRecord struct {
+	Outbound bool
+	// Message is the binary encoding of the message.
+	Message []byte
+	// Chain is the hash of the previous record's Chain, Outbound and Message.
+	Chain []byte
+}
+
+// AuditEntry is a message recorded in an audit log.
+type AuditEntry struct {
+	// Outbound is true for the messages we sent, and false for the ones we received.
+	Outbound bool
+	Message  *Message
+}
+
+// auditLog writes the records of an audit log to its sink.
+type auditLog struct {
+	w     io.Writer
+	chain []byte
+}
+
+// chainHash returns the Chain of a record following one whose Chain is previous.
+func chainHash(previous []byte, outbound bool, data []byte) []byte {
+	var direction byte
+	if outbound {
+		direction = 1
+	}
+	return hash.New(
+		hash.BytesWithDomain{TheDomain: "AuditChain", Bytes: previous},
+		hash.BytesWithDomain{TheDomain: "AuditDirection", Bytes: []byte{direction}},
+		hash.BytesWithDomain{TheDomain: "AuditMessage", Bytes: data},
+	).Sum()
+}
+
+// write appends msg to the log, as a record prefixed by its length as a 4 byte big endian integer.
+func (l *auditLog) write(msg *Message, outbound bool) error {
+	data, err := msg.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	chain := chainHash(l.chain, outbound, data)
+	record, err := cbor.Marshal(&auditRecord{Outbound: outbound, Message: data, Chain: chain})
+	if err != nil {
+		return err
+	}
+	frame := binary.BigEndian.AppendUint32(make([]byte, 0, 4+len(record)), uint32(len(record)))
+	if _, err = l.w.Write(append(frame, record...)); err != nil {
+		return err
+	}
+	l.chain = chain
+	return nil
+}
+
+// SetAuditSink makes the handler write every message it sends and every message it accepts to w,
+// so that the session can be reviewed offline, for instance after an incident.
+//
+// Each message is written as a record in CBOR, prefixed by its length.
+// The records form a hash chain: each one holds the hash of the previous one along with its own message,
+// so that ReadAuditLog detects a record which was altered, removed or reordered.
+// The chain isn't keyed, so the hash of the last record, returned by ReadAuditLog, must be kept apart from the log
+// to detect a log rewritten as a whole.
+//
+// The log holds the messages sent to us, which may include shares of secrets, so it must be kept as safely as a config.
+// If w fails, the protocol is aborted, so that no session goes unrecorded.
+//
+// It must be called before reading from Listen and before the first call to Accept, so that the log holds the whole session.
+// A nil w disables the log.
+func (h *MultiHandler) SetAuditSink(w io.Writer) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if w == nil {
+		h.audit = nil
+		return
+	}
+	h.audit = &auditLog{w: w}
+	if h.err != nil || h.result != nil {
+		return
+	}
+	// the messages of the first round were sent when the handler was created, and wait in out until they are read
+	for n := len(h.out); n > 0; n-- {
+		msg := <-h.out
+		if !h.audited(msg, true) {
+			return
+		}
+		h.out <- msg
+	}
+}
+
+// audited writes msg to the audit log, if any. It aborts the protocol and returns false if the log fails.
+func (h *MultiHandler) audited(msg *Message, outbound bool) bool {
+	if h.audit == nil {
+		return true
+	}
+	if err := h.audit.write(msg, outbound); err != nil {
+		// the abort message isn't recorded either
+		h.audit = nil
+		if h.err == nil && h.result == nil {
+			h.abort(fmt.Errorf("protocol: failed to write audit log: %w", err), h.currentRound.SelfID())
+		}
+		return false
+	}
+	return true
+}
+
+// ReadAuditLog reads an audit log written by a handler, see MultiHandler.SetAuditSink, and checks its hash chain.
+// It returns the messages of the log in order, and the hash of its last record.
+func ReadAuditLog(r io.Reader) ([]AuditEntry, []byte, error) {
+	var entries []AuditEntry
+	var chain []byte
+	var header [4]byte
+	for i := 0; ; i++ {
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			if errors.Is(err, io.EOF) {
+				return entries, chain, nil
+			}
+			return nil, nil, fmt.Errorf("protocol: audit log: record %d: %w", i, err)
+		}
+		size := binary.BigEndian.Uint32(header[:])
+		if size > maxAuditRecordSize {
+			return nil, nil, fmt.Errorf("protocol: audit log: record %d is too large", i)
+		}
+		data := make([]byte, size)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, nil, fmt.Errorf("protocol: audit log: record %d: %w", i, err)
+		}
+		var record auditRecord
+		if err := cbor.Unmarshal(data, &record); err != nil {
+			return nil, nil, fmt.Errorf("protocol: audit log: record %d: %w", i, err)
+		}
+		chain = chainHash(chain, record.Outbound, record.Message)
+		if !bytes.Equal(chain, record.Chain) {
+			return nil, nil, fmt.Errorf("protocol: audit log: record %d breaks the hash chain", i)
+		}
+		msg := &Message{}
+		if err := msg.UnmarshalBinary(record.Message); err != nil {
+			return nil, nil, fmt.Errorf("protocol: audit log: record %d: %w", i, err)
+		}
+		entries = append(entries, AuditEntry{Outbound: record.Outbound, Message: msg})
+	}
+}
+
+// ReplayFromLog reproduces the result of a session recorded by MultiHandler.SetAuditSink, without a network:
+// the messages the party received are accepted by a new handler, created with create and sessionID, in the order of the log.
+// This lets an auditor confirm that a past session produced the claimed result.
+//
+// create must be the StartFunc of the logged handler and draw the same randomness, since the messages the new handler
+// sends are checked against the ones the party sent. Only the keygens taking a randomness reader, such as
+// lss.KeygenWithRand and frost.KeygenWithRand, can do so: the signing protocols draw their nonces from crypto/rand,
+// so the logs of signing sessions can be read with ReadAuditLog but not replayed.
+// sessionID must be the one the logged handler was created with, since it is bound to every message.
+// An error is returned if the log is altered, if the messages don't match, or if the log doesn't complete the protocol.
+func ReplayFromLog(create StartFunc, sessionID []byte, log io.Reader) (interface{}, error) {
+	entries, _, err := ReadAuditLog(log)
+	if err != nil {
+		return nil, err
+	}
+	h, err := newMultiHandler(create, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	// nothing is sent while replaying, but the messages not in the log still go to out
+	go func(out <-chan *Message) {
+		for range out {
+		}
+	}(h.out)
+	defer h.Stop()
+
+	h.replay = map[string][]byte{}
+	for _, e := range entries {
+		if !e.Outbound || e.Message.RoundNumber == 0 {
+			continue
+		}
+		h.replay[sentKey(e.Message)] = e.Message.Hash()
+		if e.Message.Echo {
+			h.echo = true
+		}
+	}
+	h.finalize()
+	for _, e := range entries {
+		if !e.Outbound {
+			h.accept(e.Message)
+		}
+	}
+	logged := h.replay
+	h.replay = nil
+
+	if h.replayErr != nil {
+		return nil, h.replayErr
+	}
+	for key := range h.sentHashes {
+		if _, ok := logged[key]; !ok {
+			return nil, fmt.Errorf("protocol: the replay sent a message which isn't in the log: %s", key)
+		}
+	}
+	for key := range logged {
+		if _, ok := h.sentHashes[key]; !ok {
+			return nil, fmt.Errorf("protocol: the replay didn't send the logged message %s", key)
+		}
+	}
+	result, err := h.Result()
+	if err != nil {
+		return nil, fmt.Errorf("protocol: the log doesn't replay to a result: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,156 @@
+package protocol_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	mrand "math/rand"
+	"sync"
+	"testing"
+
+	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/protocol"
+	"github.com/luxfi/threshold/protocols/lss/config"
+	"github.com/luxfi/threshold/protocols/lss/keygen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// auditedKeygen runs an LSS keygen between 3 parties for sessionID with an audit sink each, and returns the StartFunc
+// of each party, their logs and their configs.
+func auditedKeygen(t *testing.T, sessionID []byte) (func(i int) protocol.StartFunc, []bytes.Buffer, []*config.Config) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(3)
+	network := test.NewNetwork(partyIDs)
+	start := func(i int) protocol.StartFunc {
+		return keygen.StartWithRand(partyIDs[i], partyIDs, 2, group, nil, mrand.New(mrand.NewSource(int64(i))))
+	}
+
+	logs := make([]bytes.Buffer, len(partyIDs))
+	results := make([]*config.Config, len(partyIDs))
+	var wg sync.WaitGroup
+	for i := range partyIDs {
+		h, err := protocol.NewMultiHandler(start(i), sessionID)
+		require.NoError(t, err)
+		h.SetAuditSink(&logs[i])
+		wg.Add(1)
+		go func(i int, h *protocol.MultiHandler) {
+			defer wg.Done()
+			test.HandlerLoop(partyIDs[i], h, network)
+			result, err := h.Result()
+			require.NoError(t, err)
+			results[i] = result.(*config.Config)
+		}(i, h)
+	}
+	wg.Wait()
+	return start, logs, results
+}
+
+// TestAuditLog records a keygen, replays the messages received by a party into a new handler,
+// and checks that tampering with the log breaks its hash chain.
+func TestAuditLog(t *testing.T) {
+	start, logs, results := auditedKeygen(t, nil)
+	self := results[0].ID
+
+	data := logs[0].Bytes()
+	entries, head, err := protocol.ReadAuditLog(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Len(t, head, 64)
+	var sent, received int
+	for _, e := range entries {
+		if e.Outbound {
+			assert.Equal(t, self, e.Message.From)
+			sent++
+		} else {
+			assert.NotEqual(t, self, e.Message.From)
+			received++
+		}
+	}
+	assert.Positive(t, sent)
+	assert.Positive(t, received)
+
+	// the messages received replay to the same config
+	h, err := protocol.NewMultiHandler(start(0), nil)
+	require.NoError(t, err)
+	for _, e := range entries {
+		if !e.Outbound {
+			h.Accept(e.Message)
+		}
+	}
+	result, err := h.Result()
+	require.NoError(t, err)
+	replayed := result.(*config.Config)
+	assert.True(t, results[0].ECDSA.Equal(replayed.ECDSA))
+	want, err := results[0].PublicKey()
+	require.NoError(t, err)
+	got, err := replayed.PublicKey()
+	require.NoError(t, err)
+	assert.True(t, want.Equal(got))
+
+	// a byte altered in the first message
+	tampered := bytes.Clone(data)
+	tampered[40] ^= 1
+	_, _, err = protocol.ReadAuditLog(bytes.NewReader(tampered))
+	assert.ErrorContains(t, err, "record 0 breaks the hash chain")
+
+	// the first record removed
+	first := 4 + binary.BigEndian.Uint32(data)
+	_, _, err = protocol.ReadAuditLog(bytes.NewReader(data[first:]))
+	assert.ErrorContains(t, err, "record 0 breaks the hash chain")
+
+	// a truncated log
+	_, _, err = protocol.ReadAuditLog(bytes.NewReader(data[:len(data)-1]))
+	assert.Error(t, err)
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestAuditSinkFailure(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	h, err := protocol.NewMultiHandler(keygen.Start(partyIDs[0], partyIDs, 2, curve.Secp256k1{}, nil), nil)
+	require.NoError(t, err)
+	h.SetAuditSink(failingWriter{})
+	_, err = h.Result()
+	assert.ErrorContains(t, err, "disk full")
+}
+
+func TestReplayFromLog(t *testing.T) {
+	sessionID := []byte("audited session")
+	start, logs, results := auditedKeygen(t, sessionID)
+	data := logs[0].Bytes()
+
+	result, err := protocol.ReplayFromLog(start(0), sessionID, bytes.NewReader(data))
+	require.NoError(t, err)
+	want, err := results[0].MarshalBinary()
+	require.NoError(t, err)
+	got, err := result.(*config.Config).MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, want, got, "the replay must give the identical config")
+
+	// the log of another party
+	_, err = protocol.ReplayFromLog(start(0), sessionID, bytes.NewReader(logs[1].Bytes()))
+	assert.Error(t, err)
+	// another session ID
+	_, err = protocol.ReplayFromLog(start(0), nil, bytes.NewReader(data))
+	assert.Error(t, err)
+	// other randomness
+	other := keygen.StartWithRand(results[0].ID, results[0].PartyIDs(), 2, curve.Secp256k1{}, nil, mrand.New(mrand.NewSource(42)))
+	_, err = protocol.ReplayFromLog(other, sessionID, bytes.NewReader(data))
+	assert.ErrorContains(t, err, "randomness")
+
+	// a log cut after its first records, which is still a valid hash chain
+	entries, _, err := protocol.ReadAuditLog(bytes.NewReader(data))
+	require.NoError(t, err)
+	var cut []byte
+	for rest, n := data, 0; n < len(entries)/2; n++ {
+		size := 4 + binary.BigEndian.Uint32(rest)
+		cut, rest = append(cut, rest[:size]...), rest[size:]
+	}
+	_, err = protocol.ReplayFromLog(start(0), sessionID, bytes.NewReader(cut))
+	assert.ErrorContains(t, err, "doesn't replay to a result")
+}
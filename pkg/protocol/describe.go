@@ -0,0 +1,87 @@
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/pkg/party"
+)
+
+// RoundInfo describes the messages of a round of a protocol, which carry its number in Message.RoundNumber.
+type RoundInfo struct {
+	Number round.Number
+	// Broadcast is set if every party broadcasts a message for the round.
+	Broadcast bool
+	// P2P is set if every party sends a point-to-point message to each other party for the round.
+	P2P bool
+}
+
+// Describe returns the schedule of a protocol: the kind of messages of each round, in order, starting with round 1,
+// which has no messages since the parties send their first messages at the end of it.
+// This lets transports and progress displays know the flow of messages ahead of an execution.
+//
+// Rounds which only run when a party misbehaves, such as the complaint round of FROST keygen, are left out:
+// the schedule is that of an execution in which all parties follow the protocol.
+//
+// The rounds of a protocol after the second only exist once the previous ones have received the messages of
+// the other parties, so Describe runs an execution of the protocol in memory, between the parties in partyIDs,
+// with create(id) as the StartFunc of party id. This costs as much as an execution, which for CMP keygen includes
+// generating Paillier keys.
+func Describe(partyIDs []party.ID, create func(id party.ID) StartFunc) ([]RoundInfo, error) {
+	if len(partyIDs) == 0 {
+		return nil, errors.New("protocol: no parties to describe the protocol with")
+	}
+	// the signing protocols require a session ID, which the others bind to as well
+	sessionID, err := NewSessionID()
+	if err != nil {
+		return nil, err
+	}
+	handlers := make(map[party.ID]*MultiHandler, len(partyIDs))
+	for _, id := range partyIDs {
+		h, err := NewMultiHandler(create(id), sessionID)
+		if err != nil {
+			return nil, err
+		}
+		handlers[id] = h
+	}
+
+	var wg sync.WaitGroup
+	for _, h := range handlers {
+		wg.Add(1)
+		go func(h *MultiHandler) {
+			defer wg.Done()
+			for msg := range h.Listen() {
+				for id, other := range handlers {
+					if msg.IsFor(id) {
+						other.Accept(msg)
+					}
+				}
+			}
+		}(h)
+	}
+	wg.Wait()
+
+	h := handlers[partyIDs[0]]
+	if _, err := h.Result(); err != nil {
+		return nil, fmt.Errorf("protocol: failed to run the protocol: %w", err)
+	}
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	final := h.currentRound.FinalRoundNumber()
+	rounds := make([]RoundInfo, 0, final)
+	for number := round.Number(1); number <= final; number++ {
+		r, ok := h.rounds[number]
+		if !ok {
+			continue
+		}
+		_, broadcast := r.(round.BroadcastRound)
+		rounds = append(rounds, RoundInfo{
+			Number:    number,
+			Broadcast: broadcast,
+			P2P:       expectsNormalMessage(r),
+		})
+	}
+	return rounds, nil
+}
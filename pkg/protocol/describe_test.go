@@ -0,0 +1,32 @@
+package protocol_test
+
+import (
+	"testing"
+
+	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/protocol"
+	"github.com/luxfi/threshold/protocols/frost"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribe(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	rounds, err := protocol.Describe(partyIDs, func(id party.ID) protocol.StartFunc {
+		return frost.Keygen(curve.Secp256k1{}, id, partyIDs, 1)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []protocol.RoundInfo{
+		{Number: 1},
+		{Number: 2, Broadcast: true},
+		{Number: 3, Broadcast: true, P2P: true},
+		{Number: 4, Broadcast: true},
+	}, rounds, "round 5 only resolves complaints")
+
+	_, err = protocol.Describe(partyIDs, func(id party.ID) protocol.StartFunc {
+		return frost.Keygen(curve.Secp256k1{}, id, partyIDs, 3)
+	})
+	assert.Error(t, err, "the protocol can't be started with an invalid threshold")
+}
@@ -1,11 +1,60 @@
 package protocol
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
+	"github.com/luxfi/threshold/internal/round"
 	"github.com/luxfi/threshold/pkg/party"
 )
 
+// ErrNotFinished is returned by Result while the protocol is still running.
+var ErrNotFinished = errors.New("protocol: not finished")
+
+// ErrAborted describes a protocol that was aborted because a party misbehaved or failed,
+// and can be recovered with errors.As from the error of a handler's Result.
+//
+// Protocols canceled by their context do not match it: their error wraps the context error instead,
+// so that errors.Is(err, context.DeadlineExceeded) tells timeouts apart.
+type ErrAborted struct {
+	// Culprits are the parties identified as faulty, and is empty if none could be identified.
+	Culprits []party.ID
+	// Round is the round in which the protocol was aborted, and 0 if it is unknown.
+	Round round.Number
+	// Reason is the error which caused the abort.
+	Reason error
+}
+
+// Error implements error.
+func (e *ErrAborted) Error() string {
+	msg := "protocol: aborted"
+	if e.Round != 0 {
+		msg += fmt.Sprintf(" in round %d", e.Round)
+	}
+	if len(e.Culprits) > 0 {
+		msg += fmt.Sprintf(", faulty parties: %s", party.IDSlice(e.Culprits))
+	}
+	if e.Reason != nil {
+		msg += ": " + e.Reason.Error()
+	}
+	return msg
+}
+
+// ErrStalled is the reason of a protocol aborted by ResultWithRoundTimeout because a round made no progress,
+// and can be recovered with errors.As from the error of a handler's Result.
+type ErrStalled struct {
+	// Round is the round in which the protocol stalled.
+	Round round.Number
+	// Missing are the parties whose messages for the round didn't arrive.
+	Missing []party.ID
+}
+
+// Error implements error.
+func (e *ErrStalled) Error() string {
+	return fmt.Sprintf("protocol: stalled in round %d, missing %s", e.Round, party.IDSlice(e.Missing))
+}
+
 // Error is a custom error for protocols which contains information about the responsible round in which it occurred,
 // and the party responsible.
 type Error struct {
@@ -13,6 +62,8 @@ type Error struct {
 	Culprits []party.ID
 	// Err is the underlying error.
 	Err error
+	// Round is the round in which the error occurred, and 0 if it is unknown.
+	Round round.Number
 }
 
 // Error implement error.
@@ -27,3 +78,13 @@ func (e Error) Error() string {
 func (e Error) Unwrap() error {
 	return e.Err
 }
+
+// As lets errors.As recover an *ErrAborted from e, unless e wraps the error of a canceled context.
+func (e Error) As(target interface{}) bool {
+	aborted, ok := target.(**ErrAborted)
+	if !ok || errors.Is(e.Err, context.Canceled) || errors.Is(e.Err, context.DeadlineExceeded) {
+		return false
+	}
+	*aborted = &ErrAborted{Culprits: e.Culprits, Round: e.Round, Reason: e.Err}
+	return true
+}
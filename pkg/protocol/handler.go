@@ -2,9 +2,14 @@ package protocol
 
 import (
 	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
 	"errors"
 	"fmt"
+	"log/slog"
 	"sync"
+	"time"
 
 	"github.com/fxamacker/cbor/v2"
 	"github.com/luxfi/threshold/internal/round"
@@ -19,6 +24,28 @@ import (
 // An optional sessionID can be provided, which should unique among all protocol executions.
 type StartFunc func(sessionID []byte) (round.Session, error)
 
+// contentEncoding encodes the content of the messages we send deterministically, sorting map keys,
+// so that a round replayed by RestoreMultiHandler sends the same bytes as before.
+var contentEncoding, _ = cbor.CoreDetEncOptions().EncMode()
+
+// SessionIDSize is the length of the session IDs returned by NewSessionID.
+const SessionIDSize = 32
+
+// NewSessionID returns a fresh random session ID, to be given to the handlers of all parties of one execution.
+//
+// The SSID of an execution is derived from the protocol, its parties and the session ID.
+// Without a session ID, two executions with the same parameters, such as two signatures with the same
+// config, share their SSID, and the messages recorded in one of them are accepted in the other.
+// Each party cannot draw its own random ID, since they all need the same one: it must be chosen by
+// one of them or a coordinator, and distributed along with the other parameters of the execution.
+func NewSessionID() ([]byte, error) {
+	sessionID := make([]byte, SessionIDSize)
+	if _, err := rand.Read(sessionID); err != nil {
+		return nil, fmt.Errorf("protocol: failed to sample session ID: %w", err)
+	}
+	return sessionID, nil
+}
+
 // Handler represents some kind of handler for a protocol.
 type Handler interface {
 	// Result should return the result of running the protocol, or an error
@@ -36,6 +63,8 @@ type Handler interface {
 // MultiHandler represents an execution of a given protocol.
 // It provides a simple interface for the user to receive/deliver protocol messages.
 type MultiHandler struct {
+	// sessionID is the session ID the handler was created with, kept for Snapshot.
+	sessionID       []byte
 	currentRound    round.Session
 	rounds          map[round.Number]round.Session
 	err             *Error
@@ -43,27 +72,151 @@ type MultiHandler struct {
 	messages        map[round.Number]map[party.ID]*Message
 	broadcast       map[round.Number]map[party.ID]*Message
 	broadcastHashes map[round.Number][]byte
-	out             chan *Message
-	mtx             sync.Mutex
+	// sent records the rounds for which we have sent our own messages.
+	sent map[round.Number]bool
+	// sentHashes holds the hash of every message we sent, including echoes, by sentKey.
+	sentHashes map[string][]byte
+	// replay is set while RestoreMultiHandler replays a snapshot, and holds the hashes of the messages
+	// sent before it, which are checked against the replayed ones instead of being sent again.
+	replay    map[string][]byte
+	replayErr error
+	// echo is set by EnableEchoBroadcast, and echoes holds the echo messages of each broadcast round.
+	echo   bool
+	echoes map[round.Number]map[party.ID]*Message
+	// identityKey signs our messages, and identities verifies the messages of each party,
+	// see NewAuthenticatedMultiHandler.
+	identityKey ed25519.PrivateKey
+	identities  map[party.ID]ed25519.PublicKey
+	out         chan *Message
+	// done is closed once the protocol has finished, successfully or not.
+	done chan struct{}
+	// advanced is closed and replaced each time the handler reaches a new round, see ResultWithRoundTimeout.
+	advanced chan struct{}
+	// stopAt is a breakpoint round, set by RunUntil. When non-zero, the handler
+	// keeps storing messages but will not finalize rounds numbered stopAt or higher.
+	stopAt round.Number
+	// incoming is the channel of the transport RunUntil receives from, kept across its calls
+	incoming <-chan *Message
+	// notifier delivers round changes to the callback set by OnRoundChange.
+	notifier roundNotifier
+	// metrics counts the messages sent and received in each round, see Metrics.
+	metrics map[round.Number]*RoundMetrics
+	// audit records the messages sent and accepted, see SetAuditSink.
+	audit *auditLog
+	// log receives the diagnostics of the handler, see SetLogger.
+	log *slog.Logger
+	mtx sync.Mutex
+}
+
+// roundNotifier calls a callback with each new round number, in order, outside of the handler's mutex.
+//
+// Round changes are queued while the handler is locked, and delivered once it has been unlocked.
+// Only one goroutine delivers at a time, so the callback is never called concurrently,
+// and a callback which calls back into the handler only adds to the queue.
+type roundNotifier struct {
+	callback   func(round.Number)
+	queue      []round.Number
+	delivering bool
+	mtx        sync.Mutex
+}
+
+// push queues a round change, if a callback is set.
+func (n *roundNotifier) push(number round.Number) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	if n.callback != nil {
+		n.queue = append(n.queue, number)
+	}
+}
+
+// deliver calls the callback with the queued round changes, unless another goroutine is already doing so.
+func (n *roundNotifier) deliver() {
+	n.mtx.Lock()
+	if n.delivering {
+		n.mtx.Unlock()
+		return
+	}
+	n.delivering = true
+	for len(n.queue) > 0 {
+		number, callback := n.queue[0], n.callback
+		n.queue = n.queue[1:]
+		n.mtx.Unlock()
+		callback(number)
+		n.mtx.Lock()
+	}
+	n.delivering = false
+	n.mtx.Unlock()
 }
 
 // NewMultiHandler expects a StartFunc for the desired protocol. It returns a handler that the user can interact with.
+//
+// sessionID binds the execution to a nonce shared by all parties, see NewSessionID.
+// It may be nil when executions are otherwise distinguished, such as a keygen drawing fresh randomness,
+// but signing executions must have one, since two signatures of the same digest with the same config
+// would otherwise share their SSID: NewMultiHandler returns an error for the signing protocols without one.
 func NewMultiHandler(create StartFunc, sessionID []byte) (*MultiHandler, error) {
+	h, err := newMultiHandler(create, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	h.finalize()
+	return h, nil
+}
+
+// NewAuthenticatedMultiHandler is like NewMultiHandler, but every message is signed with the Ed25519 identityKey
+// of this party, and messages received are only accepted with a valid signature by the identity of their sender.
+//
+// identities must hold the identity public key of every party of the protocol, including ours.
+// A message with an invalid signature aborts the protocol with its claimed sender as culprit,
+// so the transport should be authenticated too if the parties must not be framed by a relay.
+func NewAuthenticatedMultiHandler(create StartFunc, sessionID []byte, identityKey ed25519.PrivateKey, identities map[party.ID]ed25519.PublicKey) (*MultiHandler, error) {
+	h, err := newMultiHandler(create, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(identityKey) != ed25519.PrivateKeySize {
+		return nil, errors.New("protocol: invalid identity key")
+	}
+	for _, id := range h.currentRound.PartyIDs() {
+		if len(identities[id]) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("protocol: missing identity of party %s", id)
+		}
+	}
+	if !identityKey.Public().(ed25519.PublicKey).Equal(identities[h.currentRound.SelfID()]) {
+		return nil, errors.New("protocol: identity key does not match our identity")
+	}
+	h.identityKey, h.identities = identityKey, identities
+	h.finalize()
+	return h, nil
+}
+
+// newMultiHandler creates the handler without sending its first messages.
+func newMultiHandler(create StartFunc, sessionID []byte) (*MultiHandler, error) {
 	r, err := create(sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("protocol: failed to create round: %w", err)
 	}
+	if s, ok := r.(interface{ RequireSessionID() bool }); ok && s.RequireSessionID() && len(sessionID) == 0 {
+		return nil, fmt.Errorf("protocol: %s requires a session ID, see NewSessionID", r.ProtocolID())
+	}
 	h := &MultiHandler{
+		sessionID:       sessionID,
 		currentRound:    r,
 		rounds:          map[round.Number]round.Session{r.Number(): r},
-		messages:        make(map[round.Number]map[party.ID]*Message),
-		broadcast:       make(map[round.Number]map[party.ID]*Message),
+		messages:        newQueue(r.OtherPartyIDs(), r.FinalRoundNumber()),
+		broadcast:       newQueue(r.PartyIDs(), r.FinalRoundNumber()),
 		broadcastHashes: map[round.Number][]byte{},
+		sent:            map[round.Number]bool{},
+		sentHashes:      map[string][]byte{},
+		echoes:          map[round.Number]map[party.ID]*Message{},
+		metrics:         map[round.Number]*RoundMetrics{},
 		out:             make(chan *Message, 2*r.N()),
+		done:            make(chan struct{}),
+		advanced:        make(chan struct{}),
+		log:             discardLogger,
 	}
 	// Initialize storage for the first round
 	h.initRoundStorage(r)
-	h.finalizeInitial()
 	return h, nil
 }
 
@@ -77,7 +230,102 @@ func (h *MultiHandler) Result() (interface{}, error) {
 	if h.err != nil {
 		return nil, *h.err
 	}
-	return nil, errors.New("protocol: not finished")
+	return nil, ErrNotFinished
+}
+
+// ResultWithContext waits until the protocol finishes and returns its result, like Result.
+//
+// If ctx is done first, the protocol is aborted: the channel returned by Listen is closed,
+// the other parties are alerted, and the returned error, also given by Result from then on, wraps ctx.Err().
+func (h *MultiHandler) ResultWithContext(ctx context.Context) (interface{}, error) {
+	select {
+	case <-h.done:
+	case <-ctx.Done():
+		h.mtx.Lock()
+		if h.err == nil && h.result == nil {
+			h.abort(fmt.Errorf("protocol: canceled: %w", ctx.Err()), h.currentRound.SelfID())
+		}
+		h.mtx.Unlock()
+	}
+	return h.Result()
+}
+
+// ResultWithRoundTimeout waits until the protocol finishes and returns its result, like ResultWithContext,
+// but also aborts the protocol if it stays in the same round for longer than roundTimeout.
+//
+// The deadline starts over with each round, so that a long protocol between many parties is not cut short
+// as long as it makes progress. A stalled protocol is aborted with the parties whose messages are missing
+// as culprits, and its error wraps an *ErrStalled.
+func (h *MultiHandler) ResultWithRoundTimeout(ctx context.Context, roundTimeout time.Duration) (interface{}, error) {
+	timer := time.NewTimer(roundTimeout)
+	defer timer.Stop()
+	for {
+		h.mtx.Lock()
+		advanced := h.advanced
+		h.mtx.Unlock()
+
+		select {
+		case <-h.done:
+			return h.Result()
+		case <-ctx.Done():
+			return h.ResultWithContext(ctx)
+		case <-advanced:
+			timer.Reset(roundTimeout)
+		case <-timer.C:
+			number, missing := h.PendingParties()
+			h.mtx.Lock()
+			// the round may have ended meanwhile, in which case it gets a new deadline
+			if h.err == nil && h.result == nil && h.currentRound.Number() == number {
+				h.abort(&ErrStalled{Round: number, Missing: missing}, missing...)
+				h.mtx.Unlock()
+				return h.Result()
+			}
+			h.mtx.Unlock()
+			timer.Reset(roundTimeout)
+		}
+	}
+}
+
+// OnRoundChange sets a callback which is called with the new round number each time the handler advances
+// to the next round of the protocol. The end of the protocol is not reported, see ResultWithContext for that.
+// Rounds reached before the callback is set are not reported.
+//
+// The callback is called in round order, after the handler's lock has been released,
+// so it may safely call methods of the handler such as RoundNumber.
+func (h *MultiHandler) OnRoundChange(cb func(round.Number)) {
+	h.notifier.mtx.Lock()
+	defer h.notifier.mtx.Unlock()
+	h.notifier.callback = cb
+	if cb == nil {
+		h.notifier.queue = nil
+	}
+}
+
+// EnableEchoBroadcast makes the handler check that all parties received the same broadcast messages,
+// for transports which do not provide reliable broadcast.
+//
+// At the end of each broadcast round, every party sends the hashes of the broadcasts it received to the
+// other parties, and only advances once the echoes of all parties match its own view.
+// If they differ, the protocol is aborted. A party reporting a different broadcast from us is the culprit.
+// Otherwise, either the sender of the broadcast or the echoing party lied, and without identity keys
+// both are reported as culprits, since nothing tells them apart.
+// With identity keys, see NewAuthenticatedMultiHandler, echoes also carry the signed broadcasts they report:
+// a broadcast signed by its sender which differs from ours proves that the sender equivocated, and it is the
+// only culprit, while the echoing party is if it can't show such a broadcast.
+//
+// Echoing adds one message per party to each broadcast round. All parties must enable it,
+// before the first call to Accept.
+func (h *MultiHandler) EnableEchoBroadcast() {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.echo = true
+}
+
+// echoContent is the content of an echo message, the hash of the broadcast received from each party.
+type echoContent struct {
+	Hashes map[party.ID][]byte
+	// Broadcasts holds the signed broadcasts the hashes are of, when the parties have identity keys.
+	Broadcasts map[party.ID]*Message `cbor:",omitempty"`
 }
 
 // Listen returns a channel with outgoing messages that must be sent to other parties.
@@ -90,7 +338,24 @@ func (h *MultiHandler) Listen() <-chan *Message {
 }
 
 // CanAccept returns true if the message is designated for this protocol protocol execution.
+// Messages for a later round than the current one are accepted, and kept until the handler reaches their round.
 func (h *MultiHandler) CanAccept(msg *Message) bool {
+	if !h.matches(msg) {
+		return false
+	}
+
+	// Check if message is for a round we've already passed
+	// msg.RoundNumber < r.Number() means the message is for an earlier round
+	// We reject it unless it's round 0 (abort message)
+	if h.stale(msg) {
+		return false
+	}
+
+	return true
+}
+
+// matches returns true if the message belongs to this protocol execution, regardless of the current round.
+func (h *MultiHandler) matches(msg *Message) bool {
 	r := h.currentRound
 	if msg == nil {
 		return false
@@ -117,33 +382,61 @@ func (h *MultiHandler) CanAccept(msg *Message) bool {
 		return false
 	}
 
-	// check if message for unexpected round
-	if msg.RoundNumber > r.FinalRoundNumber() {
+	// echoes are only sent for broadcast rounds, and are not broadcast themselves
+	if msg.Echo && (msg.Broadcast || msg.RoundNumber == 0) {
 		return false
 	}
 
-	// Check if message is for a round we've already passed
-	// msg.RoundNumber < r.Number() means the message is for an earlier round
-	// We reject it unless it's round 0 (abort message)
-	if msg.RoundNumber < r.Number() && msg.RoundNumber > 0 {
-		// This is the condition that's likely failing
-		// If we're in round 2 and receive a round 1 message, we reject it
+	// check if message for unexpected round
+	if msg.RoundNumber > r.FinalRoundNumber() {
 		return false
 	}
 
 	return true
 }
 
+// stale returns true if msg is for a round we have already finalized.
+func (h *MultiHandler) stale(msg *Message) bool {
+	return msg.RoundNumber > 0 && msg.RoundNumber < h.currentRound.Number()
+}
+
 // Accept tries to process the given message. If an abort occurs, the channel returned by Listen() is closed,
 // and an error is returned by Result().
 //
+// Accept is idempotent, so that messages can be delivered more than once: a message we already have,
+// even for a round we have finalized, is ignored. A different message from the same sender,
+// for the same round, aborts the protocol with the sender as culprit.
+//
 // This function may be called concurrently from different threads but may block until all previous calls have finished.
 func (h *MultiHandler) Accept(msg *Message) {
 	h.mtx.Lock()
-	defer h.mtx.Unlock()
+	h.accept(msg)
+	h.mtx.Unlock()
+	h.notifier.deliver()
+}
+
+func (h *MultiHandler) accept(msg *Message) {
+	// exit early if the message is bad, or if we are already done.
+	// Messages for the rounds we have finalized are only compared with the ones we used.
+	if !h.matches(msg) || h.err != nil || h.result != nil {
+		return
+	}
+	if !h.audited(msg, false) {
+		return
+	}
+
+	// check the sender before anything else, since aborts and duplicates could be forged too
+	if h.identities != nil && !ed25519.Verify(h.identities[msg.From], msg.Hash(), msg.Signature) {
+		h.abort(fmt.Errorf("invalid signature on message from %s", msg.From), msg.From)
+		return
+	}
 
-	// exit early if the message is bad, or if we are already done
-	if !h.CanAccept(msg) || h.err != nil || h.result != nil || h.duplicate(msg) {
+	duplicate, err := h.duplicate(msg)
+	if err != nil {
+		h.abort(err, msg.From)
+		return
+	}
+	if duplicate || h.stale(msg) {
 		return
 	}
 
@@ -153,6 +446,21 @@ func (h *MultiHandler) Accept(msg *Message) {
 		return
 	}
 
+	if msg.Echo {
+		if !h.echo {
+			return
+		}
+		if h.echoes[msg.RoundNumber] == nil {
+			h.echoes[msg.RoundNumber] = make(map[party.ID]*Message, len(h.currentRound.PartyIDs()))
+		}
+		h.echoes[msg.RoundNumber][msg.From] = msg
+		if h.currentRound.Number() == msg.RoundNumber {
+			h.finalize()
+		}
+		return
+	}
+
+	h.record(msg, false)
 	h.store(msg)
 	if h.currentRound.Number() != msg.RoundNumber {
 		return
@@ -237,132 +545,32 @@ func (h *MultiHandler) verifyMessage(msg *Message) error {
 	return nil
 }
 
-// finalizeInitial is called during handler initialization to generate initial messages
-// without advancing the round
-func (h *MultiHandler) finalizeInitial() {
-	// For round 1 broadcast rounds, generate the initial broadcast but don't advance yet
-	if h.currentRound.Number() != 1 {
-		return
-	}
-	
-	if _, ok := h.currentRound.(round.BroadcastRound); !ok {
+func (h *MultiHandler) finalize() {
+	// we are paused at a breakpoint, see RunUntil
+	if h.stopAt != 0 && h.currentRound.Number() >= h.stopAt {
 		return
 	}
-	
-	// Special handling: generate broadcast but stay in round 1
-	out := make(chan *round.Message, h.currentRound.N()+1)
-	r, err := h.currentRound.Finalize(out)
-	close(out)
-	
-	if err != nil {
-		h.abort(err, h.currentRound.SelfID())
+
+	if !h.sendOwnMessages() {
 		return
 	}
-	
-	// Save the next round for later but don't advance to it yet
-	if r != nil && r.Number() > h.currentRound.Number() {
-		h.rounds[r.Number()] = r
-		// Pre-initialize storage for round 2 so it's ready when we advance
-		h.initRoundStorage(r)
-	}
-	
-	// Forward messages
-	for roundMsg := range out {
-		data, err := cbor.Marshal(roundMsg.Content)
-		if err != nil {
-			panic(fmt.Errorf("failed to marshal round message: %w", err))
-		}
-		msg := &Message{
-			SSID:                  h.currentRound.SSID(),
-			From:                  h.currentRound.SelfID(),
-			To:                    roundMsg.To,
-			Protocol:              h.currentRound.ProtocolID(),
-			RoundNumber:           roundMsg.Content.RoundNumber(),
-			Data:                  data,
-			Broadcast:             roundMsg.Broadcast,
-			BroadcastVerification: nil,
-		}
-		if msg.Broadcast {
-			// Store our own broadcast for hash calculation
-			h.store(msg)
-			// Verify it was stored
-			if h.broadcast[msg.RoundNumber] != nil && h.broadcast[msg.RoundNumber][msg.From] == nil {
-				// Storage failed - this is a problem
-				// For debugging: try to understand why
-			}
-		}
-		h.out <- msg
-	}
-	// Stay in round 1 to accept other broadcasts
-}
 
-func (h *MultiHandler) finalize() {
-	// Special case: Round 2 needs to send shares immediately without waiting
-	if h.currentRound.Number() == 2 && expectsNormalMessage(h.currentRound) {
-		// Check if we've already sent shares (to avoid double-sending)
-		// If round 3 exists, we've already sent shares
-		if _, ok := h.rounds[3]; !ok {
-			// fmt.Printf("Handler %s: Round 2 - sending shares immediately\n", h.currentRound.SelfID())
-			// Call Finalize to generate and send share messages
-			out2 := make(chan *round.Message, h.currentRound.N()+1)
-			nextRound, err := h.currentRound.Finalize(out2)
-			close(out2)
-			
-			if err != nil {
-				h.abort(err, h.currentRound.SelfID())
-				return
-			}
-			
-			// Send the share messages
-			for roundMsg := range out2 {
-				data, err := cbor.Marshal(roundMsg.Content)
-				if err != nil {
-					panic(fmt.Errorf("failed to marshal round message: %w", err))
-				}
-				msg := &Message{
-					SSID:                  h.currentRound.SSID(),
-					From:                  h.currentRound.SelfID(),
-					To:                    roundMsg.To,
-					Protocol:              h.currentRound.ProtocolID(),
-					RoundNumber:           roundMsg.Content.RoundNumber(),
-					Data:                  data,
-					Broadcast:             roundMsg.Broadcast,
-					BroadcastVerification: h.broadcastHashes[h.currentRound.Number()-1],
-				}
-				h.out <- msg
-			}
-			
-			// Save and advance to round 3
-			if nextRound != nil {
-				h.rounds[nextRound.Number()] = nextRound
-				// Don't advance yet - we'll do that when we receive all shares
-			}
-			// Return here to avoid sending shares again in the normal flow
-			return
-		}
-	}
-	
 	// only finalize if we have received all messages
 	if !h.receivedAll() {
-		// fmt.Printf("finalize: Not all messages received for round %d (handler %s)\n", h.currentRound.Number(), h.currentRound.SelfID())
+		h.logWaiting()
 		return
 	}
-	// fmt.Printf("finalize: All messages received for round %d (handler %s), advancing...\n", h.currentRound.Number(), h.currentRound.SelfID())
-	if !h.checkBroadcastHash() {
-		h.abort(errors.New("broadcast verification failed"))
+	if culprit, ok := h.checkBroadcastHash(); !ok {
+		h.abort(fmt.Errorf("round %d: broadcast verification of the message of %s failed", h.currentRound.Number(), culprit), culprit)
+		return
+	}
+	if !h.checkEchoes() {
 		return
 	}
 
-	// Check if we've already finalized this round 
-	nextRoundNumber := h.currentRound.Number() + 1
-	if existingRound, ok := h.rounds[nextRoundNumber]; ok {
-		// We've already finalized this round, just advance to the next
-		h.currentRound = existingRound
-		// Initialize storage for the next round
-		h.initRoundStorage(existingRound)
-		
-		// Process any queued messages for the new round
-		h.processQueuedMessages()
+	// the next round was already created when we sent our own messages for this one
+	if r, ok := h.rounds[h.currentRound.Number()+1]; ok {
+		h.advance(r)
 		return
 	}
 
@@ -376,10 +584,60 @@ func (h *MultiHandler) finalize() {
 		h.abort(err, h.currentRound.SelfID())
 		return
 	}
+	h.send(out)
 
-	// forward messages with the correct header.
+	// if we get a round with the same number, we can safely assume that we got the same one.
+	if _, ok := h.rounds[r.Number()]; ok {
+		return
+	}
+	h.advance(r)
+}
+
+// sendOwnMessages sends our messages for the current round, if it expects messages of its own number
+// but we have not sent ours yet.
+//
+// Most rounds receive the messages sent when finalizing the previous one. Some rounds however send
+// the messages they receive themselves: such a round is finalized early once we reach it, to send our
+// part, and the round it returns is kept until the messages of all other parties have arrived.
+// If the early finalization sent no message for the current round, the round does not expect any,
+// and we advance right away.
+//
+// It returns false if the protocol was aborted, or if we advanced to another round.
+func (h *MultiHandler) sendOwnMessages() bool {
+	r := h.currentRound
+	number := r.Number()
+	if h.sent[number] || !expectsMessages(r) {
+		return true
+	}
+
+	out := make(chan *round.Message, r.N()+1)
+	next, err := r.Finalize(out)
+	close(out)
+	if err != nil || next == nil {
+		h.abort(err, r.SelfID())
+		return false
+	}
+	h.send(out)
+
+	if next.Number() <= number {
+		h.sent[number] = true
+		return true
+	}
+	if !h.sent[number] {
+		h.advance(next)
+		return false
+	}
+	h.rounds[next.Number()] = next
+	h.initRoundStorage(next)
+	return true
+}
+
+// send forwards the messages created by a round with the correct header, and records which rounds we have sent
+// messages for. Our own broadcast messages are stored, since they are part of the broadcast hash.
+func (h *MultiHandler) send(out <-chan *round.Message) {
+	r := h.currentRound
 	for roundMsg := range out {
-		data, err := cbor.Marshal(roundMsg.Content)
+		data, err := contentEncoding.Marshal(roundMsg.Content)
 		if err != nil {
 			panic(fmt.Errorf("failed to marshal round message: %w", err))
 		}
@@ -391,79 +649,52 @@ func (h *MultiHandler) finalize() {
 			RoundNumber:           roundMsg.Content.RoundNumber(),
 			Data:                  data,
 			Broadcast:             roundMsg.Broadcast,
-			BroadcastVerification: h.broadcastHashes[r.Number()-1],
+			BroadcastVerification: h.broadcastHashes[roundMsg.Content.RoundNumber()-1],
 		}
 		if msg.Broadcast {
 			h.store(msg)
 		}
-		h.out <- msg
+		h.sent[msg.RoundNumber] = true
+		h.sign(msg)
+		h.record(msg, true)
+		h.emit(msg)
 	}
+}
 
-	roundNumber := r.Number()
-	// if we get a round with the same number, we can safely assume that we got the same one.
-	if _, ok := h.rounds[roundNumber]; ok {
+// advance makes r the current round, and replays the messages which were received for it before we got there.
+func (h *MultiHandler) advance(r round.Session) {
+	// an abort is reported in the round which produced it, so that one stays current
+	if R, ok := r.(*round.Abort); ok {
+		h.abort(R.Err, R.Culprits...)
 		return
 	}
+
+	roundNumber := r.Number()
 	h.rounds[roundNumber] = r
 	h.currentRound = r
-	// fmt.Printf("Handler %s: Advanced to round %d\n", r.SelfID(), r.Number())
-	// Initialize storage for the new round
 	h.initRoundStorage(r)
+	h.logAdvance(r)
 
 	// either we get the current round, the next one, or one of the two final ones
 	switch R := r.(type) {
-	// An abort happened
-	case *round.Abort:
-		h.abort(R.Err, R.Culprits...)
-		return
 	// We have the result
 	case *round.Output:
 		h.result = R.Result
 		h.abort(nil)
 		return
 	default:
+		h.notifier.push(roundNumber)
+		close(h.advanced)
+		h.advanced = make(chan struct{})
 	}
 
 	if _, ok := r.(round.BroadcastRound); ok {
 		// handle queued broadcast messages, which will then check the subsequent normal message
-		for id, m := range h.broadcast[roundNumber] {
-			if m == nil || id == r.SelfID() {
-				continue
-			}
-			// if false, we aborted and so we return
-			if err = h.verifyBroadcastMessage(m); err != nil {
-				h.abort(err, m.From)
-				return
-			}
-		}
-	} else {
-		// handle simple queued messages
-		for _, m := range h.messages[roundNumber] {
+		for _, id := range r.OtherPartyIDs() {
+			m := h.broadcast[roundNumber][id]
 			if m == nil {
 				continue
 			}
-			// if false, we aborted and so we return
-			if err = h.verifyMessage(m); err != nil {
-				h.abort(err, m.From)
-				return
-			}
-		}
-	}
-
-	// we only do this if the current round has changed
-	h.finalize()
-}
-
-func (h *MultiHandler) processQueuedMessages() {
-	roundNumber := h.currentRound.Number()
-	
-	if _, ok := h.currentRound.(round.BroadcastRound); ok {
-		// handle queued broadcast messages
-		for id, m := range h.broadcast[roundNumber] {
-			if m == nil || id == h.currentRound.SelfID() {
-				continue
-			}
-			// if false, we aborted and so we return
 			if err := h.verifyBroadcastMessage(m); err != nil {
 				h.abort(err, m.From)
 				return
@@ -471,19 +702,19 @@ func (h *MultiHandler) processQueuedMessages() {
 		}
 	} else {
 		// handle simple queued messages
-		for _, m := range h.messages[roundNumber] {
+		for _, id := range r.OtherPartyIDs() {
+			m := h.messages[roundNumber][id]
 			if m == nil {
 				continue
 			}
-			// if false, we aborted and so we return
 			if err := h.verifyMessage(m); err != nil {
 				h.abort(err, m.From)
 				return
 			}
 		}
 	}
-	
-	// Continue processing if needed
+
+	// we only do this if the current round has changed
 	h.finalize()
 }
 
@@ -492,24 +723,39 @@ func (h *MultiHandler) abort(err error, culprits ...party.ID) {
 		h.err = &Error{
 			Culprits: culprits,
 			Err:      err,
+			Round:    h.currentRound.Number(),
 		}
-		select {
-		case h.out <- &Message{
+		h.logAbort(h.err)
+		msg := &Message{
 			SSID:     h.currentRound.SSID(),
 			From:     h.currentRound.SelfID(),
 			Protocol: h.currentRound.ProtocolID(),
 			Data:     []byte(h.err.Error()),
-		}:
+		}
+		h.sign(msg)
+		h.audited(msg, true)
+		select {
+		case h.out <- msg:
 		default:
 		}
 
 	}
 	close(h.out)
+	close(h.done)
+}
+
+// sign sets the signature of an outgoing message, if we have an identity key.
+func (h *MultiHandler) sign(msg *Message) {
+	if h.identityKey != nil {
+		msg.Signature = ed25519.Sign(h.identityKey, msg.Hash())
+	}
 }
 
 // Stop cancels the current execution of the protocol, and alerts the other users.
 func (h *MultiHandler) Stop() {
-	if h.err != nil || h.result != nil {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if h.err == nil && h.result == nil {
 		h.abort(errors.New("aborted by user"), h.currentRound.SelfID())
 	}
 }
@@ -518,30 +764,29 @@ func expectsNormalMessage(r round.Session) bool {
 	return r.MessageContent() != nil
 }
 
+// expectsMessages returns true if r receives any message, broadcast or not.
+func expectsMessages(r round.Session) bool {
+	_, ok := r.(round.BroadcastRound)
+	return ok || expectsNormalMessage(r)
+}
+
 func (h *MultiHandler) receivedAll() bool {
 	r := h.currentRound
 	number := r.Number()
 	// check all broadcast messages
 	if _, ok := r.(round.BroadcastRound); ok {
-		// fmt.Printf("receivedAll: Round %d IS a BroadcastRound\n", number)
 		// Only check broadcasts if this round actually broadcasts
 		if h.broadcast[number] == nil {
 			// No broadcast storage means we haven't initialized it yet
 			// This should not happen if initRoundStorage was called
 			return false
 		}
-		
+
 		// Normal case: check for all broadcasts
 		// We need all broadcasts including our own for the hash
 		for _, id := range r.PartyIDs() {
 			msg := h.broadcast[number][id]
 			if msg == nil {
-				// Debug: Print which party's broadcast is missing
-				if id == r.SelfID() {
-					// Our own broadcast is missing - this shouldn't happen
-					// fmt.Printf("WARNING: Handler %s missing OWN broadcast for round %d\n", r.SelfID(), number)
-				}
-				// fmt.Printf("Handler %s missing broadcast from %s for round %d\n", r.SelfID(), id, number)
 				return false
 			}
 		}
@@ -575,21 +820,37 @@ func (h *MultiHandler) receivedAll() bool {
 	return true
 }
 
-func (h *MultiHandler) duplicate(msg *Message) bool {
+// duplicate returns true if we already have a message from the sender of msg, of the same kind, for its round,
+// or if we expect none. It returns an error if the message we have is different from msg.
+func (h *MultiHandler) duplicate(msg *Message) (bool, error) {
 	if msg.RoundNumber == 0 {
-		return false
+		return false, nil
 	}
 	var q map[party.ID]*Message
-	if msg.Broadcast {
+	switch {
+	case msg.Echo:
+		// echoes are stored as they arrive
+		q = h.echoes[msg.RoundNumber]
+		if q == nil {
+			return false, nil
+		}
+	case msg.Broadcast:
 		q = h.broadcast[msg.RoundNumber]
-	} else {
+	default:
 		q = h.messages[msg.RoundNumber]
 	}
 	// technically, we already received the nil message since it is not expected :)
 	if q == nil {
-		return true
+		return true, nil
+	}
+	previous := q[msg.From]
+	if previous == nil {
+		return false, nil
+	}
+	if !bytes.Equal(previous.Hash(), msg.Hash()) {
+		return true, fmt.Errorf("round %d: %s sent two different messages", msg.RoundNumber, msg.From)
 	}
-	return q[msg.From] != nil
+	return true, nil
 }
 
 func (h *MultiHandler) store(msg *Message) {
@@ -601,16 +862,14 @@ func (h *MultiHandler) store(msg *Message) {
 	}
 	if q == nil {
 		// Storage not initialized for this round
-		// fmt.Printf("store: Storage not initialized for round %d (broadcast=%v)\n", msg.RoundNumber, msg.Broadcast)
+		h.logRound(slog.LevelDebug, "message not expected", slog.String("from", string(msg.From)), slog.Int("message_round", int(msg.RoundNumber)))
 		return
 	}
 	if q[msg.From] != nil {
 		// Already have a message from this sender
-		// fmt.Printf("store: Already have message from %s for round %d\n", msg.From, msg.RoundNumber)
 		return
 	}
 	q[msg.From] = msg
-	// fmt.Printf("store: Stored message from %s for round %d (broadcast=%v)\n", msg.From, msg.RoundNumber, msg.Broadcast)
 }
 
 // getRoundMessage attempts to unmarshal a raw Message for round `r` in a round.Message.
@@ -643,27 +902,126 @@ func getRoundMessage(msg *Message, r round.Session) (round.Message, error) {
 }
 
 // checkBroadcastHash is run after receivedAll() and checks whether all provided verification hashes are correct.
-func (h *MultiHandler) checkBroadcastHash() bool {
-	number := h.currentRound.Number()
+// It returns the sender of the first message of the current round with a wrong hash.
+func (h *MultiHandler) checkBroadcastHash() (party.ID, bool) {
+	r := h.currentRound
+	number := r.Number()
 	// check BroadcastVerification
 	previousHash := h.broadcastHashes[number-1]
 	if previousHash == nil {
+		return "", true
+	}
+
+	if expectsNormalMessage(r) {
+		for _, id := range r.OtherPartyIDs() {
+			if msg := h.messages[number][id]; msg != nil && !bytes.Equal(previousHash, msg.BroadcastVerification) {
+				return id, false
+			}
+		}
+	}
+	if _, ok := r.(round.BroadcastRound); ok {
+		for _, id := range r.PartyIDs() {
+			if msg := h.broadcast[number][id]; msg != nil && !bytes.Equal(previousHash, msg.BroadcastVerification) {
+				return id, false
+			}
+		}
+	}
+	return "", true
+}
+
+// checkEchoes is run after receivedAll() when echo broadcast is enabled, and checks whether all parties
+// received the same broadcast messages in the current round.
+//
+// It sends our own echo the first time it is called for a round, and returns false until the echoes of all
+// other parties have arrived, or if the protocol was aborted because of a mismatch.
+func (h *MultiHandler) checkEchoes() bool {
+	r := h.currentRound
+	if _, ok := r.(round.BroadcastRound); !ok || !h.echo {
 		return true
 	}
+	number, self := r.Number(), r.SelfID()
 
-	for _, msg := range h.messages[number] {
-		if msg != nil && !bytes.Equal(previousHash, msg.BroadcastVerification) {
+	hashes := make(map[party.ID][]byte, r.N())
+	for _, id := range r.PartyIDs() {
+		hashes[id] = h.broadcast[number][id].Hash()
+	}
+
+	if h.echoes[number] == nil {
+		h.echoes[number] = make(map[party.ID]*Message, r.N())
+	}
+	if h.echoes[number][self] == nil {
+		content := &echoContent{Hashes: hashes}
+		if h.identities != nil {
+			content.Broadcasts = make(map[party.ID]*Message, r.N())
+			for _, id := range r.OtherPartyIDs() {
+				content.Broadcasts[id] = h.broadcast[number][id]
+			}
+		}
+		data, err := cbor.Marshal(content)
+		if err != nil {
+			panic(fmt.Errorf("failed to marshal echo message: %w", err))
+		}
+		msg := &Message{
+			SSID:        r.SSID(),
+			From:        self,
+			Protocol:    r.ProtocolID(),
+			RoundNumber: number,
+			Data:        data,
+			Echo:        true,
+		}
+		h.echoes[number][self] = msg
+		h.sign(msg)
+		h.emit(msg)
+	}
+
+	for _, id := range r.OtherPartyIDs() {
+		if h.echoes[number][id] == nil {
 			return false
 		}
 	}
-	for _, msg := range h.broadcast[number] {
-		if msg != nil && !bytes.Equal(previousHash, msg.BroadcastVerification) {
+
+	for _, id := range r.OtherPartyIDs() {
+		var echo echoContent
+		if err := cbor.Unmarshal(h.echoes[number][id].Data, &echo); err != nil {
+			h.abort(fmt.Errorf("round %d: failed to unmarshal echo: %w", number, err), id)
+			return false
+		}
+		for _, j := range r.PartyIDs() {
+			if bytes.Equal(hashes[j], echo.Hashes[j]) {
+				continue
+			}
+			switch {
+			case j == self || j == id:
+				// we know what we broadcast, and a party's own broadcast is what it echoes
+				h.abort(fmt.Errorf("round %d: %s received a different broadcast from %s", number, id, j), id)
+			case h.identities == nil:
+				h.abort(fmt.Errorf("round %d: %s received a different broadcast from %s", number, id, j), j, id)
+			case h.equivocated(j, echo.Broadcasts[j], echo.Hashes[j]):
+				h.abort(fmt.Errorf("round %d: %s broadcast two different messages", number, j), j)
+			default:
+				h.abort(fmt.Errorf("round %d: %s reported a broadcast from %s which %s didn't sign", number, id, j, j), id)
+			}
 			return false
 		}
 	}
 	return true
 }
 
+// equivocated returns true if msg is a broadcast of the current round signed by its sender j, other than ours,
+// with the hash reported by an echo.
+func (h *MultiHandler) equivocated(j party.ID, msg *Message, reported []byte) bool {
+	r := h.currentRound
+	if msg == nil || msg.From != j || !msg.Broadcast || msg.Echo || msg.RoundNumber != r.Number() ||
+		msg.Protocol != r.ProtocolID() || !bytes.Equal(msg.SSID, r.SSID()) {
+		return false
+	}
+	digest := msg.Hash()
+	return bytes.Equal(digest, reported) && ed25519.Verify(h.identities[j], digest, msg.Signature)
+}
+
+// newQueue returns the message queue of a protocol with the given number of rounds, with a slot for every sender
+// in every round. Messages for a round the handler hasn't reached yet are kept in their slot, and processed once
+// the handler gets to their round, so a party running ahead doesn't have to send them again.
 func newQueue(senders []party.ID, rounds round.Number) map[round.Number]map[party.ID]*Message {
 	n := len(senders)
 	q := make(map[round.Number]map[party.ID]*Message, rounds)
@@ -681,11 +1039,12 @@ func (h *MultiHandler) String() string {
 	return fmt.Sprintf("party: %s, protocol: %s", h.currentRound.SelfID(), h.currentRound.ProtocolID())
 }
 
-// initRoundStorage initializes message storage for a specific round based on its requirements
+// initRoundStorage keeps message storage for a specific round only for the kinds of messages it expects.
+// The queues of newQueue have slots for both kinds in every round, since the kinds of a round are only known once
+// it is created, so the messages of the other kind which arrived early are dropped here.
 func (h *MultiHandler) initRoundStorage(r round.Session) {
 	number := r.Number()
-	
-	// Initialize broadcast storage only if this is a broadcast round
+
 	if _, ok := r.(round.BroadcastRound); ok {
 		if h.broadcast[number] == nil {
 			h.broadcast[number] = make(map[party.ID]*Message, r.N())
@@ -693,9 +1052,11 @@ func (h *MultiHandler) initRoundStorage(r round.Session) {
 				h.broadcast[number][id] = nil
 			}
 		}
+	} else {
+		h.dropUnexpected(h.broadcast[number])
+		delete(h.broadcast, number)
 	}
-	
-	// Initialize message storage only if this round expects messages
+
 	if expectsNormalMessage(r) {
 		if h.messages[number] == nil {
 			h.messages[number] = make(map[party.ID]*Message, r.N()-1)
@@ -703,5 +1064,17 @@ func (h *MultiHandler) initRoundStorage(r round.Session) {
 				h.messages[number][id] = nil
 			}
 		}
+	} else {
+		h.dropUnexpected(h.messages[number])
+		delete(h.messages, number)
+	}
+}
+
+// dropUnexpected logs the messages in q, which were queued for a round that doesn't expect them.
+func (h *MultiHandler) dropUnexpected(q map[party.ID]*Message) {
+	for id, msg := range q {
+		if msg != nil {
+			h.logRound(slog.LevelDebug, "message not expected", slog.String("from", string(id)), slog.Int("message_round", int(msg.RoundNumber)))
+		}
 	}
 }
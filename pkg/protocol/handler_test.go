@@ -0,0 +1,705 @@
+package protocol_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/pkg/hash"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/protocol"
+	"github.com/luxfi/threshold/protocols/frost"
+	"github.com/luxfi/threshold/protocols/lss"
+	"github.com/luxfi/threshold/protocols/lss/config"
+	"github.com/luxfi/threshold/protocols/lss/keygen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultWithContextCancel(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(3)
+	network := test.NewNetwork(partyIDs)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// bring every party to round 2, so that the keygen is left unfinished
+	handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+	var wg sync.WaitGroup
+	for _, id := range partyIDs {
+		h, err := protocol.NewMultiHandler(keygen.Start(id, partyIDs, 2, group, nil), nil)
+		require.NoError(t, err)
+		handlers[id] = h
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, protocol.RunUntil(ctx, h, network.Transport(id), 2))
+		}()
+	}
+	wg.Wait()
+
+	h := handlers[partyIDs[0]]
+	cancelCtx, cancelKeygen := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancelKeygen)
+	result, err := h.ResultWithContext(cancelCtx)
+	assert.Nil(t, result)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.Canceled), "unexpected error %v", err)
+	_, err = h.Result()
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	// the only message left is the abort alerting the other parties, after which Listen is closed
+	for msg := range h.Listen() {
+		assert.EqualValues(t, 0, msg.RoundNumber, "unexpected message for round %d", msg.RoundNumber)
+	}
+
+	// messages arriving later don't restart the protocol
+	for _, msg := range handlers[partyIDs[1]].StoredMessages(1) {
+		h.Accept(msg)
+	}
+	_, ok := <-h.Listen()
+	assert.False(t, ok, "handler produced a message after being canceled")
+}
+
+func TestResultWithContextFinished(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(3)
+	network := test.NewNetwork(partyIDs)
+
+	handlers := make([]*protocol.MultiHandler, 0, len(partyIDs))
+	var wg sync.WaitGroup
+	for _, id := range partyIDs {
+		h, err := protocol.NewMultiHandler(keygen.Start(id, partyIDs, 2, group, nil), nil)
+		require.NoError(t, err)
+		handlers = append(handlers, h)
+		wg.Add(1)
+		go func(id party.ID) {
+			defer wg.Done()
+			test.HandlerLoop(id, h, network)
+		}(id)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for _, h := range handlers {
+		result, err := h.ResultWithContext(ctx)
+		require.NoError(t, err)
+		assert.IsType(t, &config.Config{}, result)
+	}
+	wg.Wait()
+}
+
+func TestStop(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	h, err := protocol.NewMultiHandler(keygen.Start(partyIDs[0], partyIDs, 2, curve.Secp256k1{}, nil), nil)
+	require.NoError(t, err)
+
+	h.Stop()
+	_, err = h.Result()
+	assert.ErrorContains(t, err, "aborted by user")
+	for range h.Listen() {
+	}
+	// stopping again is harmless
+	h.Stop()
+}
+
+func TestErrAborted(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(3)
+	cheater := partyIDs[2]
+
+	handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+	for _, id := range partyIDs {
+		h, err := protocol.NewMultiHandler(keygen.Start(id, partyIDs, 2, group, nil), nil)
+		require.NoError(t, err)
+		handlers[id] = h
+	}
+	_, err := handlers[partyIDs[0]].Result()
+	assert.ErrorIs(t, err, protocol.ErrNotFinished)
+
+	// the cheater sends messages that cannot be decoded
+	deliverAll(handlers, func(msg *protocol.Message, _ party.ID) bool {
+		if msg.From == cheater && msg.RoundNumber > 0 {
+			msg.Data = []byte("garbage")
+		}
+		return false
+	})
+	for _, id := range partyIDs[:2] {
+		_, err := handlers[id].Result()
+		var aborted *protocol.ErrAborted
+		require.ErrorAs(t, err, &aborted, "party %s", id)
+		assert.Equal(t, []party.ID{cheater}, aborted.Culprits, "party %s: %v", id, err)
+		assert.NotZero(t, aborted.Round, "party %s", id)
+		require.Error(t, aborted.Reason, "party %s", id)
+		assert.Contains(t, aborted.Error(), fmt.Sprintf("aborted in round %d", aborted.Round))
+	}
+
+	// timeouts are not reported as aborts
+	h, err := protocol.NewMultiHandler(keygen.Start(partyIDs[0], partyIDs, 2, group, nil), nil)
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err = h.ResultWithContext(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	var aborted *protocol.ErrAborted
+	assert.False(t, errors.As(err, &aborted))
+}
+
+func TestOnRoundChange(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(4)
+	network := test.NewNetwork(partyIDs)
+
+	rounds := make(map[party.ID][]round.Number, len(partyIDs))
+	var mtx sync.Mutex
+	var wg sync.WaitGroup
+	for _, id := range partyIDs {
+		h, err := protocol.NewMultiHandler(keygen.Start(id, partyIDs, 2, group, nil), nil)
+		require.NoError(t, err)
+		h.OnRoundChange(func(number round.Number) {
+			// calling back into the handler must not deadlock
+			_ = h.RoundNumber()
+			mtx.Lock()
+			rounds[id] = append(rounds[id], number)
+			mtx.Unlock()
+		})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			test.HandlerLoop(id, h, network)
+			_, err := h.Result()
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	for _, id := range partyIDs {
+		numbers := rounds[id]
+		require.NotEmpty(t, numbers, "no round change reported for %s", id)
+		assert.EqualValues(t, 2, numbers[0], "first round change of %s", id)
+		for i := 1; i < len(numbers); i++ {
+			assert.Equal(t, numbers[i-1]+1, numbers[i], "rounds of %s out of order: %v", id, numbers)
+		}
+	}
+}
+
+func TestEchoBroadcast(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(4)
+	network := test.NewNetwork(partyIDs)
+
+	handlers := make([]*protocol.MultiHandler, 0, len(partyIDs))
+	var wg sync.WaitGroup
+	for _, id := range partyIDs {
+		h, err := protocol.NewMultiHandler(keygen.Start(id, partyIDs, 2, group, nil), nil)
+		require.NoError(t, err)
+		h.EnableEchoBroadcast()
+		handlers = append(handlers, h)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			test.HandlerLoop(id, h, network)
+		}()
+	}
+	wg.Wait()
+
+	for _, h := range handlers {
+		result, err := h.Result()
+		require.NoError(t, err)
+		assert.IsType(t, &config.Config{}, result)
+	}
+}
+
+func TestEchoBroadcastEquivocation(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(4)
+	cheater := partyIDs[0]
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		honest := runEquivocation(t, partyIDs, func(id party.ID) *protocol.MultiHandler {
+			h, err := protocol.NewMultiHandler(keygen.Start(id, partyIDs, 2, group, nil), nil)
+			require.NoError(t, err)
+			return h
+		})
+		// a party whose echo disagrees with the others may be the liar, so it is reported along with the cheater
+		for id, h := range honest {
+			_, err := h.Result()
+			var protocolErr protocol.Error
+			require.ErrorAs(t, err, &protocolErr, "party %s", id)
+			assert.Contains(t, protocolErr.Culprits, cheater, "party %s: %v", id, err)
+			assert.ErrorContains(t, err, "different broadcast")
+		}
+	})
+
+	t.Run("Authenticated", func(t *testing.T) {
+		keys, identities := identityKeys(t, partyIDs)
+		honest := runEquivocation(t, partyIDs, func(id party.ID) *protocol.MultiHandler {
+			h, err := protocol.NewAuthenticatedMultiHandler(keygen.Start(id, partyIDs, 2, group, nil), nil, keys[id], identities)
+			require.NoError(t, err)
+			return h
+		})
+		// the signed broadcasts in the echoes prove that the cheater sent two of them
+		for id, h := range honest {
+			_, err := h.Result()
+			var protocolErr protocol.Error
+			require.ErrorAs(t, err, &protocolErr, "party %s", id)
+			assert.Equal(t, []party.ID{cheater}, protocolErr.Culprits, "party %s: %v", id, err)
+		}
+		_, err := honest[partyIDs[1]].Result()
+		assert.ErrorContains(t, err, "broadcast two different messages")
+	})
+}
+
+// runEquivocation runs a keygen with echo broadcast among partyIDs, in which the first party runs two handlers,
+// and sends the round 1 broadcast of the second one to the last party only. It returns the handlers of the other parties.
+func runEquivocation(t *testing.T, partyIDs []party.ID, newHandler func(id party.ID) *protocol.MultiHandler) map[party.ID]*protocol.MultiHandler {
+	cheater := partyIDs[0]
+	newEchoHandler := func(id party.ID) *protocol.MultiHandler {
+		h := newHandler(id)
+		h.EnableEchoBroadcast()
+		return h
+	}
+	cheaterA, cheaterB := newEchoHandler(cheater), newEchoHandler(cheater)
+	honest := make(map[party.ID]*protocol.MultiHandler, len(partyIDs)-1)
+	for _, id := range partyIDs[1:] {
+		honest[id] = newEchoHandler(id)
+	}
+	equivocated := partyIDs[len(partyIDs)-1]
+
+	deliver := func(msg *protocol.Message, to party.ID) {
+		if h, ok := honest[to]; ok {
+			h.Accept(msg)
+		} else {
+			cheaterA.Accept(msg)
+		}
+	}
+	for progress := true; progress; {
+		progress = false
+		for _, from := range partyIDs {
+			sources := []*protocol.MultiHandler{honest[from]}
+			if from == cheater {
+				sources = []*protocol.MultiHandler{cheaterA, cheaterB}
+			}
+			for _, h := range sources {
+				for drained := false; !drained; {
+					select {
+					case msg, ok := <-h.Listen():
+						if !ok {
+							drained = true
+							break
+						}
+						progress = true
+						// drop aborts, so that each party has to detect the equivocation on its own
+						if msg.RoundNumber == 0 {
+							continue
+						}
+						for _, to := range partyIDs {
+							if to == from || !msg.IsFor(to) {
+								continue
+							}
+							if from == cheater && msg.Broadcast && msg.RoundNumber == 1 {
+								// the equivocated party gets the broadcast of the second handler, the others the first
+								if (h == cheaterB) != (to == equivocated) {
+									continue
+								}
+							} else if h == cheaterB {
+								continue
+							}
+							deliver(msg, to)
+						}
+					default:
+						drained = true
+					}
+				}
+			}
+		}
+	}
+
+	return honest
+}
+
+// identityKeys returns an identity key pair for each party.
+func identityKeys(t *testing.T, partyIDs []party.ID) (map[party.ID]ed25519.PrivateKey, map[party.ID]ed25519.PublicKey) {
+	keys := make(map[party.ID]ed25519.PrivateKey, len(partyIDs))
+	identities := make(map[party.ID]ed25519.PublicKey, len(partyIDs))
+	for _, id := range partyIDs {
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		keys[id], identities[id] = private, public
+	}
+	return keys, identities
+}
+
+// deliverAll delivers the messages sent by handlers until none is left, except for those for which hold returns true,
+// which are returned in the order they were sent.
+func deliverAll(handlers map[party.ID]*protocol.MultiHandler, hold func(msg *protocol.Message, to party.ID) bool) []*protocol.Message {
+	var held []*protocol.Message
+	for progress := true; progress; {
+		progress = false
+		for from, h := range handlers {
+			for drained := false; !drained; {
+				select {
+				case msg, ok := <-h.Listen():
+					if !ok {
+						drained = true
+						break
+					}
+					progress = true
+					for to, other := range handlers {
+						if to == from || !msg.IsFor(to) {
+							continue
+						}
+						if hold(msg, to) {
+							held = append(held, msg)
+							continue
+						}
+						other.Accept(msg)
+					}
+				default:
+					drained = true
+				}
+			}
+		}
+	}
+	return held
+}
+
+func TestFutureRoundMessages(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(4)
+	late := partyIDs[0]
+
+	handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+	for _, id := range partyIDs {
+		h, err := protocol.NewMultiHandler(frost.Keygen(group, id, partyIDs, 2), nil)
+		require.NoError(t, err)
+		handlers[id] = h
+	}
+
+	// the other parties finish round 2 without the late party receiving anything
+	held := deliverAll(handlers, func(_ *protocol.Message, to party.ID) bool { return to == late })
+	h := handlers[late]
+	require.EqualValues(t, 2, h.RoundNumber())
+
+	// all round 3 messages arrive before the round 2 broadcasts
+	var round2 []*protocol.Message
+	for _, msg := range held {
+		if msg.RoundNumber == 2 {
+			round2 = append(round2, msg)
+			continue
+		}
+		require.EqualValues(t, 3, msg.RoundNumber)
+		require.True(t, h.CanAccept(msg), "a message of a future round must be kept")
+		h.Accept(msg)
+	}
+	require.Len(t, round2, len(partyIDs)-1)
+	assert.EqualValues(t, 2, h.RoundNumber(), "advanced before receiving the round 2 broadcasts")
+	for _, msg := range round2 {
+		h.Accept(msg)
+	}
+	deliverAll(handlers, func(*protocol.Message, party.ID) bool { return false })
+
+	var publicKey curve.Point
+	for id, h := range handlers {
+		result, err := h.Result()
+		require.NoError(t, err, "party %s", id)
+		c := result.(*frost.Config)
+		if publicKey == nil {
+			publicKey = c.PublicKey
+		}
+		assert.True(t, publicKey.Equal(c.PublicKey), "party %s has a different public key", id)
+	}
+}
+
+func TestRedelivery(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(4)
+
+	handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+	for _, id := range partyIDs {
+		h, err := protocol.NewMultiHandler(frost.Keygen(group, id, partyIDs, 2), nil)
+		require.NoError(t, err)
+		h.EnableEchoBroadcast()
+		handlers[id] = h
+	}
+
+	// every message is delivered twice, followed by all the messages delivered to the same party before it,
+	// which by then are mostly for rounds it has finalized
+	delivered := make(map[party.ID][]*protocol.Message, len(partyIDs))
+	deliverAll(handlers, func(msg *protocol.Message, to party.ID) bool {
+		assert.NotZero(t, msg.RoundNumber, "aborted: %s", msg.Data)
+		h := handlers[to]
+		h.Accept(msg)
+		h.Accept(msg)
+		for _, previous := range delivered[to] {
+			h.Accept(previous)
+		}
+		delivered[to] = append(delivered[to], msg)
+		return false
+	})
+
+	var publicKey curve.Point
+	for id, h := range handlers {
+		result, err := h.Result()
+		require.NoError(t, err, "party %s", id)
+		c := result.(*frost.Config)
+		if publicKey == nil {
+			publicKey = c.PublicKey
+		}
+		assert.True(t, publicKey.Equal(c.PublicKey), "party %s has a different public key", id)
+	}
+}
+
+func TestRedeliveryConflict(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(3)
+	victim := partyIDs[0]
+
+	handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+	for _, id := range partyIDs {
+		h, err := protocol.NewMultiHandler(frost.Keygen(group, id, partyIDs, 1), nil)
+		require.NoError(t, err)
+		handlers[id] = h
+	}
+	held := deliverAll(handlers, func(_ *protocol.Message, to party.ID) bool { return to == victim })
+	require.NotEmpty(t, held)
+
+	// the same message is delivered again with other content
+	msg := held[0]
+	conflicting := *msg
+	conflicting.Data = append([]byte{0}, msg.Data...)
+	h := handlers[victim]
+	h.Accept(msg)
+	h.Accept(msg)
+	h.Accept(&conflicting)
+
+	_, err := h.Result()
+	var protocolErr protocol.Error
+	require.ErrorAs(t, err, &protocolErr)
+	assert.Equal(t, []party.ID{msg.From}, protocolErr.Culprits)
+	assert.ErrorContains(t, err, "sent two different messages")
+}
+
+func TestBroadcastVerificationCulprit(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(3)
+	victim, cheater := partyIDs[0], partyIDs[1]
+
+	handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+	for _, id := range partyIDs {
+		h, err := protocol.NewMultiHandler(frost.Keygen(group, id, partyIDs, 1), nil)
+		require.NoError(t, err)
+		handlers[id] = h
+	}
+	held := deliverAll(handlers, func(msg *protocol.Message, to party.ID) bool {
+		return to == victim && msg.From == cheater && msg.BroadcastVerification != nil
+	})
+	require.NotEmpty(t, held)
+
+	// the cheater claims to have received other broadcasts in the previous round
+	h := handlers[victim]
+	for _, msg := range held {
+		tampered := *msg
+		tampered.BroadcastVerification = append([]byte{}, msg.BroadcastVerification...)
+		tampered.BroadcastVerification[0] ^= 1
+		h.Accept(&tampered)
+	}
+
+	_, err := h.Result()
+	var protocolErr protocol.Error
+	require.ErrorAs(t, err, &protocolErr)
+	assert.Equal(t, []party.ID{cheater}, protocolErr.Culprits)
+	assert.ErrorContains(t, err, "broadcast verification of the message of "+string(cheater))
+}
+
+func TestAuthenticatedMultiHandler(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(3)
+	keys, identities := identityKeys(t, partyIDs)
+	newHandlers := func() map[party.ID]*protocol.MultiHandler {
+		handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+		for _, id := range partyIDs {
+			h, err := protocol.NewAuthenticatedMultiHandler(keygen.Start(id, partyIDs, 2, group, nil), nil, keys[id], identities)
+			require.NoError(t, err)
+			handlers[id] = h
+		}
+		return handlers
+	}
+
+	t.Run("Honest", func(t *testing.T) {
+		handlers := newHandlers()
+		deliverAll(handlers, func(*protocol.Message, party.ID) bool { return false })
+		for id, h := range handlers {
+			_, err := h.Result()
+			assert.NoError(t, err, "party %s", id)
+		}
+	})
+
+	t.Run("Echo", func(t *testing.T) {
+		handlers := newHandlers()
+		for _, h := range handlers {
+			h.EnableEchoBroadcast()
+		}
+		deliverAll(handlers, func(*protocol.Message, party.ID) bool { return false })
+		for id, h := range handlers {
+			_, err := h.Result()
+			assert.NoError(t, err, "party %s", id)
+		}
+	})
+
+	t.Run("EchoFraming", func(t *testing.T) {
+		handlers := newHandlers()
+		for _, h := range handlers {
+			h.EnableEchoBroadcast()
+		}
+		// the liar echoes another broadcast from the framed party than the one it received
+		liar, framed := partyIDs[1], partyIDs[2]
+		deliverAll(handlers, func(msg *protocol.Message, to party.ID) bool {
+			if !msg.Echo || msg.From != liar {
+				return false
+			}
+			var echo struct {
+				Hashes     map[party.ID][]byte
+				Broadcasts map[party.ID]*protocol.Message `cbor:",omitempty"`
+			}
+			require.NoError(t, cbor.Unmarshal(msg.Data, &echo))
+			echo.Hashes[framed] = make([]byte, len(echo.Hashes[framed]))
+			data, err := cbor.Marshal(&echo)
+			require.NoError(t, err)
+			forged := *msg
+			forged.Data = data
+			forged.Signature = ed25519.Sign(keys[liar], forged.Hash())
+			handlers[to].Accept(&forged)
+			return true
+		})
+
+		for _, id := range []party.ID{partyIDs[0], framed} {
+			_, err := handlers[id].Result()
+			var protocolErr protocol.Error
+			require.ErrorAs(t, err, &protocolErr, "party %s", id)
+			assert.Equal(t, []party.ID{liar}, protocolErr.Culprits, "party %s: %v", id, err)
+		}
+	})
+
+	t.Run("SpoofedSender", func(t *testing.T) {
+		handlers := newHandlers()
+		from, to, spoofed := partyIDs[0], partyIDs[1], partyIDs[2]
+		msg := <-handlers[from].Listen()
+		require.NotEmpty(t, msg.Signature)
+
+		forged := *msg
+		forged.From = spoofed
+		require.True(t, handlers[to].CanAccept(&forged))
+		handlers[to].Accept(&forged)
+
+		_, err := handlers[to].Result()
+		var protocolErr protocol.Error
+		require.ErrorAs(t, err, &protocolErr)
+		assert.Equal(t, []party.ID{spoofed}, protocolErr.Culprits)
+		assert.ErrorContains(t, err, "invalid signature")
+	})
+
+	t.Run("MissingIdentity", func(t *testing.T) {
+		partial := map[party.ID]ed25519.PublicKey{partyIDs[0]: identities[partyIDs[0]]}
+		_, err := protocol.NewAuthenticatedMultiHandler(keygen.Start(partyIDs[0], partyIDs, 2, group, nil), nil, keys[partyIDs[0]], partial)
+		assert.ErrorContains(t, err, "missing identity")
+		_, err = protocol.NewAuthenticatedMultiHandler(keygen.Start(partyIDs[0], partyIDs, 2, group, nil), nil, keys[partyIDs[1]], identities)
+		assert.Error(t, err)
+	})
+}
+
+// TestMessageHashEcho checks that the Echo header is only hashed for echoes.
+func TestMessageHashEcho(t *testing.T) {
+	msg := &protocol.Message{
+		SSID:        []byte("ssid"),
+		From:        "a",
+		Protocol:    "test",
+		RoundNumber: 2,
+		Data:        []byte("data"),
+		Broadcast:   true,
+	}
+	expected := hash.New(
+		hash.BytesWithDomain{TheDomain: "SSID", Bytes: msg.SSID},
+		msg.From,
+		msg.To,
+		hash.BytesWithDomain{TheDomain: "Protocol", Bytes: []byte(msg.Protocol)},
+		msg.RoundNumber,
+		hash.BytesWithDomain{TheDomain: "Content", Bytes: msg.Data},
+		hash.BytesWithDomain{TheDomain: "Broadcast", Bytes: []byte{1}},
+		hash.BytesWithDomain{TheDomain: "BroadcastVerification", Bytes: msg.BroadcastVerification},
+	).Sum()
+	assert.Equal(t, expected, msg.Hash())
+
+	echo := *msg
+	echo.Echo = true
+	assert.NotEqual(t, msg.Hash(), echo.Hash())
+}
+
+func TestSessionID(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(3)
+	newSession := func() map[party.ID]*protocol.MultiHandler {
+		sessionID, err := protocol.NewSessionID()
+		require.NoError(t, err)
+		require.Len(t, sessionID, protocol.SessionIDSize)
+		handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+		for _, id := range partyIDs {
+			h, err := protocol.NewMultiHandler(keygen.Start(id, partyIDs, 2, group, nil), sessionID)
+			require.NoError(t, err)
+			handlers[id] = h
+		}
+		return handlers
+	}
+	first, second := newSession(), newSession()
+	for _, id := range partyIDs {
+		assert.Equal(t, first[partyIDs[0]].SessionID(), first[id].SessionID())
+		assert.Equal(t, first[partyIDs[0]].SSID(), first[id].SSID())
+		assert.Equal(t, second[partyIDs[0]].SSID(), second[id].SSID())
+	}
+	require.NotEqual(t, first[partyIDs[0]].SessionID(), second[partyIDs[0]].SessionID())
+	require.NotEqual(t, first[partyIDs[0]].SSID(), second[partyIDs[0]].SSID())
+
+	// the messages of the first session are rejected by the second one
+	from, to := partyIDs[0], partyIDs[1]
+	msg := <-first[from].Listen()
+	assert.False(t, second[to].CanAccept(msg))
+	second[to].Accept(msg)
+	_, pending := second[to].PendingParties()
+	assert.Contains(t, pending, from)
+
+	deliverAll(second, func(*protocol.Message, party.ID) bool { return false })
+	for id, h := range second {
+		_, err := h.Result()
+		assert.NoError(t, err, "party %s", id)
+	}
+}
+
+func TestSigningRequiresSessionID(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(3)
+	configs := lss.RunKeygen(t, group, partyIDs, 2)
+	messageHash := make([]byte, 32)
+
+	_, err := protocol.NewMultiHandler(lss.Sign(configs[partyIDs[0]], partyIDs, messageHash, nil), nil)
+	assert.ErrorContains(t, err, "requires a session ID")
+
+	sessionID, err := protocol.NewSessionID()
+	require.NoError(t, err)
+	h, err := protocol.NewMultiHandler(lss.Sign(configs[partyIDs[0]], partyIDs, messageHash, nil), sessionID)
+	require.NoError(t, err)
+	assert.Equal(t, sessionID, h.SessionID())
+
+	// keygen draws fresh randomness, so it runs without one
+	_, err = protocol.NewMultiHandler(keygen.Start(partyIDs[0], partyIDs, 2, group, nil), nil)
+	assert.NoError(t, err)
+}
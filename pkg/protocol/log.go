@@ -0,0 +1,88 @@
+package protocol
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/pkg/party"
+)
+
+// discardLogger is the logger of a handler without SetLogger.
+var discardLogger = slog.New(slog.DiscardHandler)
+
+// SetLogger makes the handler report its progress to logger: the rounds it reaches and the parties it waits for,
+// at the debug level, the end of the protocol at the info level, and the reason of an abort at the warn level.
+//
+// Every record has the party, protocol and round fields, and records about missing messages have a missing field
+// with the parties they are expected from, so that the records of all parties of a session can be aggregated.
+//
+// It should be called before reading from Listen and before the first call to Accept, since earlier events are not logged.
+// A nil logger disables the logs.
+func (h *MultiHandler) SetLogger(logger *slog.Logger) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if logger == nil {
+		h.log = discardLogger
+		return
+	}
+	h.log = logger.With(
+		slog.String("party", string(h.currentRound.SelfID())),
+		slog.String("protocol", h.currentRound.ProtocolID()),
+	)
+}
+
+// logEnabled returns true if records of the given level are logged, so that their fields are only computed then.
+func (h *MultiHandler) logEnabled(level slog.Level) bool {
+	return h.log.Enabled(context.Background(), level)
+}
+
+// logRound logs msg at level, with the number of the current round and args.
+func (h *MultiHandler) logRound(level slog.Level, msg string, args ...any) {
+	args = append([]any{slog.Int("round", int(h.currentRound.Number()))}, args...)
+	h.log.Log(context.Background(), level, msg, args...)
+}
+
+// logWaiting logs the parties whose messages the current round still waits for.
+func (h *MultiHandler) logWaiting() {
+	if !h.logEnabled(slog.LevelDebug) {
+		return
+	}
+	_, missing := h.pendingParties()
+	h.logRound(slog.LevelDebug, "waiting for messages", slog.Any("missing", partyStrings(missing)))
+}
+
+// logAbort logs the reason of an abort, with the parties whose messages were missing if the round stalled.
+func (h *MultiHandler) logAbort(err *Error) {
+	if !h.logEnabled(slog.LevelWarn) {
+		return
+	}
+	args := []any{slog.String("error", err.Err.Error())}
+	if len(err.Culprits) > 0 {
+		args = append(args, slog.Any("culprits", partyStrings(err.Culprits)))
+	}
+	var stalled *ErrStalled
+	if errors.As(err.Err, &stalled) {
+		args = append(args, slog.Any("missing", partyStrings(stalled.Missing)))
+	}
+	h.logRound(slog.LevelWarn, "protocol aborted", args...)
+}
+
+// partyStrings converts ids to strings, which every slog.Handler encodes as a list of strings.
+func partyStrings(ids []party.ID) []string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = string(id)
+	}
+	return s
+}
+
+// logAdvance logs that the handler reached r, which is either a new round or the output of the protocol.
+func (h *MultiHandler) logAdvance(r round.Session) {
+	if _, ok := r.(*round.Output); ok {
+		h.logRound(slog.LevelInfo, "protocol finished")
+		return
+	}
+	h.logRound(slog.LevelDebug, "round started")
+}
@@ -27,6 +27,12 @@ type Message struct {
 	// BroadcastVerification is the hash of all messages broadcast by the parties,
 	// and is included in all messages in the round following a broadcast round.
 	BroadcastVerification []byte
+	// Echo indicates that Data holds the hashes of the broadcasts the sender received in this round,
+	// see MultiHandler.EnableEchoBroadcast.
+	Echo bool
+	// Signature is the Ed25519 signature of Hash by the sender's identity key,
+	// set by handlers created with NewAuthenticatedMultiHandler.
+	Signature []byte
 }
 
 // String implements fmt.Stringer.
@@ -42,14 +48,16 @@ func (m Message) IsFor(id party.ID) bool {
 	return m.To == "" || m.To == id
 }
 
-// Hash returns a 64 byte hash of the message content, including the headers.
+// Hash returns a 64 byte hash of the message content, including the headers but not the Signature.
 // Can be used to produce a signature for the message.
+//
+// The Echo header is only hashed for echoes, so that the hash of other messages is unchanged by it.
 func (m *Message) Hash() []byte {
 	var broadcast byte
 	if m.Broadcast {
 		broadcast = 1
 	}
-	h := hash.New(
+	data := []hash.WriterToWithDomain{
 		hash.BytesWithDomain{TheDomain: "SSID", Bytes: m.SSID},
 		m.From,
 		m.To,
@@ -58,8 +66,11 @@ func (m *Message) Hash() []byte {
 		hash.BytesWithDomain{TheDomain: "Content", Bytes: m.Data},
 		hash.BytesWithDomain{TheDomain: "Broadcast", Bytes: []byte{broadcast}},
 		hash.BytesWithDomain{TheDomain: "BroadcastVerification", Bytes: m.BroadcastVerification},
-	)
-	return h.Sum()
+	}
+	if m.Echo {
+		data = append(data, hash.BytesWithDomain{TheDomain: "Echo", Bytes: []byte{1}})
+	}
+	return hash.New(data...).Sum()
 }
 
 // marshallableMessage is a copy of message for the purpose of cbor marshalling.
@@ -75,6 +86,8 @@ type marshallableMessage struct {
 	Data                  []byte
 	Broadcast             bool
 	BroadcastVerification []byte
+	Echo                  bool   `cbor:",omitempty"`
+	Signature             []byte `cbor:",omitempty"`
 }
 
 func (m *Message) toMarshallable() *marshallableMessage {
@@ -87,6 +100,8 @@ func (m *Message) toMarshallable() *marshallableMessage {
 		Data:                  m.Data,
 		Broadcast:             m.Broadcast,
 		BroadcastVerification: m.BroadcastVerification,
+		Echo:                  m.Echo,
+		Signature:             m.Signature,
 	}
 }
 
@@ -107,5 +122,7 @@ func (m *Message) UnmarshalBinary(data []byte) error {
 	m.Data = deserialized.Data
 	m.Broadcast = deserialized.Broadcast
 	m.BroadcastVerification = deserialized.BroadcastVerification
+	m.Echo = deserialized.Echo
+	m.Signature = deserialized.Signature
 	return nil
 }
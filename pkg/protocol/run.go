@@ -0,0 +1,243 @@
+package protocol
+
+import (
+	"context"
+	"errors"
+
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/pkg/party"
+)
+
+// RunUntil drives h over t until the handler reaches round stopAt, and then returns
+// without finalizing that round.
+//
+// Messages for the paused round are still accepted and stored by later calls to Accept,
+// but the handler will not advance until RunUntil is called again with a later round.
+// This makes it possible to inspect a handler at a given point of the protocol,
+// using RoundNumber and StoredMessages.
+//
+// If the protocol finishes before reaching stopAt, the result of Result is returned.
+// If ctx is done before the breakpoint is reached, ctx.Err() is returned and the handler is left as is,
+// and if the channel of t is closed, an error is returned.
+// Run removes the breakpoint, and drives the handler to the end.
+//
+// Later calls for h continue the same protocol, so t.Receive is only called by the first one,
+// and the others keep receiving from the channel it returned.
+func RunUntil(ctx context.Context, h *MultiHandler, t Transport, stopAt round.Number) error {
+	h.setBreakpoint(stopAt)
+
+	out := h.Listen()
+	in := h.receiveFrom(t)
+	for {
+		// flush outgoing messages before checking whether we are paused,
+		// so that the other parties can reach the breakpoint too.
+		select {
+		case msg, ok := <-out:
+			if !ok {
+				_, err := h.Result()
+				return err
+			}
+			t.Send(msg)
+			continue
+		default:
+		}
+
+		if h.RoundNumber() >= stopAt {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-out:
+			if !ok {
+				_, err := h.Result()
+				return err
+			}
+			t.Send(msg)
+		case msg, ok := <-in:
+			if !ok {
+				return errors.New("protocol: transport closed")
+			}
+			h.Accept(msg)
+		}
+	}
+}
+
+// setBreakpoint sets the round at which the handler stops advancing, or removes the breakpoint if stopAt is 0.
+// If the handler was previously paused at an earlier breakpoint, it resumes execution.
+func (h *MultiHandler) setBreakpoint(stopAt round.Number) {
+	h.mtx.Lock()
+	resume := h.stopAt != 0 && (stopAt == 0 || h.stopAt < stopAt)
+	h.stopAt = stopAt
+	if resume && h.err == nil && h.result == nil {
+		h.finalize()
+	}
+	h.mtx.Unlock()
+	h.notifier.deliver()
+}
+
+// receiveFrom returns the channel of t.Receive, the first time it is called for h,
+// and the same channel afterwards.
+func (h *MultiHandler) receiveFrom(t Transport) <-chan *Message {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if h.incoming == nil {
+		h.incoming = t.Receive()
+	}
+	return h.incoming
+}
+
+// RoundNumber returns the number of the round the handler is currently in.
+func (h *MultiHandler) RoundNumber() round.Number {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	return h.currentRound.Number()
+}
+
+// SessionID returns the session ID given to NewMultiHandler, which may be nil.
+func (h *MultiHandler) SessionID() []byte {
+	return append([]byte(nil), h.sessionID...)
+}
+
+// SSID returns the SSID of the execution, which every message of the execution carries.
+//
+// It is derived from the session ID given to NewMultiHandler and the parameters of the protocol,
+// and is the same for all parties of the execution.
+func (h *MultiHandler) SSID() []byte {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	return h.currentRound.SSID()
+}
+
+// RoundMetrics counts the protocol messages of a round sent and received by a handler.
+// Echoes and aborts are not counted.
+type RoundMetrics struct {
+	// SentBroadcast and SentP2P count the broadcast and point-to-point messages we sent.
+	SentBroadcast, SentP2P int
+	// ReceivedBroadcast and ReceivedP2P count the messages we accepted from other parties.
+	ReceivedBroadcast, ReceivedP2P int
+	// SentBytes and ReceivedBytes are the total sizes of these messages, in their binary encoding.
+	SentBytes, ReceivedBytes int
+}
+
+// HandlerMetrics reports the messages sent and received by a handler, see MultiHandler.Metrics.
+type HandlerMetrics struct {
+	// Rounds holds the metrics of every round for which a message was sent or received.
+	Rounds map[round.Number]RoundMetrics
+	// Messages and Bytes are the totals over all rounds, of sent and received messages.
+	Messages, Bytes int
+}
+
+// Metrics returns the number of messages sent and received so far in each round, and their size.
+// This makes it possible to compare the communication cost of protocols, or of their rounds.
+func (h *MultiHandler) Metrics() HandlerMetrics {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	metrics := HandlerMetrics{Rounds: make(map[round.Number]RoundMetrics, len(h.metrics))}
+	for number, m := range h.metrics {
+		metrics.Rounds[number] = *m
+		metrics.Messages += m.SentBroadcast + m.SentP2P + m.ReceivedBroadcast + m.ReceivedP2P
+		metrics.Bytes += m.SentBytes + m.ReceivedBytes
+	}
+	return metrics
+}
+
+// record adds msg to the metrics of its round.
+func (h *MultiHandler) record(msg *Message, sent bool) {
+	m := h.metrics[msg.RoundNumber]
+	if m == nil {
+		m = &RoundMetrics{}
+		h.metrics[msg.RoundNumber] = m
+	}
+	size := 0
+	if data, err := msg.MarshalBinary(); err == nil {
+		size = len(data)
+	}
+	switch {
+	case sent && msg.Broadcast:
+		m.SentBroadcast++
+		m.SentBytes += size
+	case sent:
+		m.SentP2P++
+		m.SentBytes += size
+	case msg.Broadcast:
+		m.ReceivedBroadcast++
+		m.ReceivedBytes += size
+	default:
+		m.ReceivedP2P++
+		m.ReceivedBytes += size
+	}
+}
+
+// StoredMessages returns the messages received so far for the given round, including our own broadcast.
+// Broadcast messages come first, and messages are ordered by sender.
+func (h *MultiHandler) StoredMessages(number round.Number) []*Message {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	var msgs []*Message
+	for _, q := range []map[party.ID]*Message{h.broadcast[number], h.messages[number]} {
+		for _, id := range h.currentRound.PartyIDs() {
+			if msg := q[id]; msg != nil {
+				msgs = append(msgs, msg)
+			}
+		}
+	}
+	return msgs
+}
+
+// PendingParties returns the number of the current round, and the parties whose messages for it
+// haven't all arrived yet, sorted by ID.
+// See PendingMessages to tell missing broadcast and point-to-point messages apart.
+func (h *MultiHandler) PendingParties() (round.Number, []party.ID) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	return h.pendingParties()
+}
+
+// pendingParties is PendingParties, for a caller holding the handler's lock.
+func (h *MultiHandler) pendingParties() (round.Number, []party.ID) {
+	number, broadcast, p2p := h.pendingMessages()
+	var pending []party.ID
+	for _, id := range party.NewIDSlice(append(broadcast, p2p...)) {
+		if len(pending) == 0 || pending[len(pending)-1] != id {
+			pending = append(pending, id)
+		}
+	}
+	return number, pending
+}
+
+// PendingMessages returns the number of the current round, along with the parties whose broadcast message
+// and whose point-to-point message for it haven't arrived yet.
+// Each list is sorted, and empty if the round doesn't expect that kind of message.
+//
+// Once the protocol has finished, no message is pending.
+func (h *MultiHandler) PendingMessages() (number round.Number, broadcast, p2p []party.ID) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	return h.pendingMessages()
+}
+
+// pendingMessages is PendingMessages, for a caller holding the handler's lock.
+func (h *MultiHandler) pendingMessages() (number round.Number, broadcast, p2p []party.ID) {
+	r := h.currentRound
+	number = r.Number()
+	if h.err != nil || h.result != nil {
+		return number, nil, nil
+	}
+	if _, ok := r.(round.BroadcastRound); ok {
+		for _, id := range r.PartyIDs() {
+			if h.broadcast[number][id] == nil {
+				broadcast = append(broadcast, id)
+			}
+		}
+	}
+	if expectsNormalMessage(r) && h.messages[number] != nil {
+		for _, id := range r.OtherPartyIDs() {
+			if h.messages[number][id] == nil {
+				p2p = append(p2p, id)
+			}
+		}
+	}
+	return number, broadcast, p2p
+}
@@ -0,0 +1,389 @@
+package protocol_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/pool"
+	"github.com/luxfi/threshold/pkg/protocol"
+	"github.com/luxfi/threshold/protocols/cmp"
+	"github.com/luxfi/threshold/protocols/frost"
+	"github.com/luxfi/threshold/protocols/lss/keygen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunUntil(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(3)
+	network := test.NewNetwork(partyIDs)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+	for _, id := range partyIDs {
+		h, err := protocol.NewMultiHandler(keygen.Start(id, partyIDs, 2, group, nil), nil)
+		require.NoError(t, err)
+		handlers[id] = h
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(partyIDs))
+	for _, id := range partyIDs {
+		wg.Add(1)
+		go func(h *protocol.MultiHandler) {
+			defer wg.Done()
+			errs <- protocol.RunUntil(ctx, h, network.Transport(id), 2)
+		}(handlers[id])
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	for _, id := range partyIDs {
+		h := handlers[id]
+		assert.EqualValues(t, 2, h.RoundNumber(), "party %s should be paused at round 2", id)
+
+		_, err := h.Result()
+		assert.Error(t, err, "protocol should not have finished")
+
+		// every party's round 1 broadcast is stored, and no share has been sent yet
+		round1 := h.StoredMessages(1)
+		require.Len(t, round1, len(partyIDs))
+		for i, msg := range round1 {
+			assert.True(t, msg.Broadcast)
+			assert.Equal(t, partyIDs[i], msg.From)
+		}
+		assert.Empty(t, h.StoredMessages(2))
+	}
+
+	// Run removes the breakpoint, and finishes the protocol
+	results := make(chan error, len(partyIDs))
+	for _, id := range partyIDs {
+		wg.Add(1)
+		go func(h *protocol.MultiHandler) {
+			defer wg.Done()
+			_, err := protocol.Run(ctx, h, network.Transport(id))
+			results <- err
+		}(handlers[id])
+	}
+	wg.Wait()
+	close(results)
+	for err := range results {
+		assert.NoError(t, err)
+	}
+}
+
+// closedTransport is a Transport which can't receive anymore.
+type closedTransport struct{ parties []party.ID }
+
+func (closedTransport) Send(*protocol.Message) {}
+
+func (closedTransport) Receive() <-chan *protocol.Message {
+	in := make(chan *protocol.Message)
+	close(in)
+	return in
+}
+
+func (t closedTransport) Parties() []party.ID { return t.parties }
+
+func TestRunUntilClosedTransport(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	h, err := protocol.NewMultiHandler(keygen.Start(partyIDs[0], partyIDs, 2, curve.Secp256k1{}, nil), nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	assert.ErrorContains(t, protocol.RunUntil(ctx, h, closedTransport{partyIDs}, 2), "transport closed")
+	assert.NoError(t, ctx.Err(), "RunUntil must return as soon as the transport is closed")
+}
+
+func TestPendingParties(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(3)
+	a, b, c := partyIDs[0], partyIDs[1], partyIDs[2]
+
+	handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+	round1 := make(map[party.ID][]*protocol.Message, len(partyIDs))
+	for _, id := range partyIDs {
+		h, err := protocol.NewMultiHandler(keygen.Start(id, partyIDs, 2, group, nil), nil)
+		require.NoError(t, err)
+		handlers[id] = h
+		for len(h.Listen()) > 0 {
+			round1[id] = append(round1[id], <-h.Listen())
+		}
+	}
+	h := handlers[a]
+
+	// only our own broadcast is stored so far
+	number, pending := h.PendingParties()
+	assert.EqualValues(t, 1, number)
+	assert.Equal(t, []party.ID{b, c}, pending)
+
+	for _, msg := range round1[b] {
+		h.Accept(msg)
+	}
+	number, broadcast, p2p := h.PendingMessages()
+	assert.EqualValues(t, 1, number)
+	assert.Equal(t, []party.ID{c}, broadcast)
+	assert.Empty(t, p2p)
+	_, pending = h.PendingParties()
+	assert.Equal(t, []party.ID{c}, pending)
+
+	for _, msg := range round1[c] {
+		h.Accept(msg)
+	}
+	number, broadcast, p2p = h.PendingMessages()
+	assert.EqualValues(t, 2, number)
+	assert.Empty(t, broadcast)
+	assert.Equal(t, []party.ID{b, c}, p2p)
+
+	// b sends its shares, c stays silent
+	for _, id := range []party.ID{b, c} {
+		for _, from := range partyIDs {
+			if from != id {
+				for _, msg := range round1[from] {
+					handlers[id].Accept(msg)
+				}
+			}
+		}
+	}
+	for len(handlers[b].Listen()) > 0 {
+		if msg := <-handlers[b].Listen(); msg.IsFor(a) {
+			h.Accept(msg)
+		}
+	}
+	number, pending = h.PendingParties()
+	assert.EqualValues(t, 2, number)
+	assert.Equal(t, []party.ID{c}, pending)
+
+	// nothing is pending once the protocol is over
+	h.Stop()
+	_, pending = h.PendingParties()
+	assert.Empty(t, pending)
+}
+
+func TestSilentParty(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(3)
+	silent := partyIDs[0]
+	network := test.NewNetwork(partyIDs)
+	network.SetFilter(func(from, _ party.ID, _ *protocol.Message) bool {
+		return from != silent
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+	for _, id := range partyIDs {
+		h, err := protocol.NewMultiHandler(keygen.Start(id, partyIDs, 2, group, nil), nil)
+		require.NoError(t, err)
+		handlers[id] = h
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(partyIDs))
+	for _, id := range partyIDs {
+		wg.Add(1)
+		go func(h *protocol.MultiHandler) {
+			defer wg.Done()
+			errs <- protocol.RunUntil(ctx, h, network.Transport(id), 100)
+		}(handlers[id])
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.ErrorIs(t, err, context.DeadlineExceeded, "the protocol should wait until the deadline")
+	}
+
+	for _, id := range partyIDs {
+		if id == silent {
+			continue
+		}
+		_, err := handlers[id].Result()
+		assert.Error(t, err, "protocol should not have finished")
+		number, pending := handlers[id].PendingParties()
+		assert.EqualValues(t, 1, number, "party %s should wait in round 1", id)
+		assert.Equal(t, []party.ID{silent}, pending)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(3)
+
+	run := func(create func(id party.ID, pl *pool.Pool) protocol.StartFunc) protocol.HandlerMetrics {
+		network := test.NewNetwork(partyIDs)
+		handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+		var wg sync.WaitGroup
+		for _, id := range partyIDs {
+			// pools must not be shared between parties running concurrently
+			pl := pool.NewPool(2)
+			defer pl.TearDown()
+			h, err := protocol.NewMultiHandler(create(id, pl), nil)
+			require.NoError(t, err)
+			handlers[id] = h
+			wg.Add(1)
+			go func(id party.ID) {
+				defer wg.Done()
+				test.HandlerLoop(id, handlers[id], network)
+			}(id)
+		}
+		wg.Wait()
+		for _, id := range partyIDs {
+			_, err := handlers[id].Result()
+			require.NoError(t, err)
+		}
+		return handlers[partyIDs[0]].Metrics()
+	}
+
+	frostMetrics := run(func(id party.ID, _ *pool.Pool) protocol.StartFunc {
+		return frost.Keygen(group, id, partyIDs, 1)
+	})
+	// round 3 receives a broadcast and a share from every other party
+	r3 := frostMetrics.Rounds[3]
+	assert.Equal(t, 1, r3.SentBroadcast)
+	assert.Equal(t, len(partyIDs)-1, r3.SentP2P)
+	assert.Equal(t, len(partyIDs)-1, r3.ReceivedBroadcast)
+	assert.Equal(t, len(partyIDs)-1, r3.ReceivedP2P)
+	assert.Positive(t, r3.SentBytes)
+	assert.Positive(t, r3.ReceivedBytes)
+
+	cmpMetrics := run(func(id party.ID, pl *pool.Pool) protocol.StartFunc {
+		return cmp.Keygen(group, id, partyIDs, 1, pl)
+	})
+	assert.Less(t, frostMetrics.Bytes, cmpMetrics.Bytes, "FROST keygen should send less data than CMP keygen")
+	t.Logf("keygen with %d parties: FROST %d messages, %d bytes; CMP %d messages, %d bytes",
+		len(partyIDs), frostMetrics.Messages, frostMetrics.Bytes, cmpMetrics.Messages, cmpMetrics.Bytes)
+}
+
+func TestResultWithRoundTimeout(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(3)
+	stalled := partyIDs[2]
+	network := test.NewNetwork(partyIDs)
+	// the last party sends its round 1 broadcast, and then nothing
+	network.SetFilter(func(from, _ party.ID, msg *protocol.Message) bool {
+		return from != stalled || msg.RoundNumber <= 1
+	})
+
+	handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+	for _, id := range partyIDs {
+		h, err := protocol.NewMultiHandler(keygen.Start(id, partyIDs, 2, group, nil), nil)
+		require.NoError(t, err)
+		handlers[id] = h
+		go test.HandlerLoop(id, h, network)
+	}
+
+	_, err := handlers[partyIDs[0]].ResultWithRoundTimeout(context.Background(), 200*time.Millisecond)
+	require.Error(t, err)
+	var stall *protocol.ErrStalled
+	require.ErrorAs(t, err, &stall)
+	assert.EqualValues(t, 2, stall.Round, "round 1 completed, so the keygen stalls in round 2")
+	assert.Equal(t, []party.ID{stalled}, stall.Missing)
+	assert.ErrorContains(t, err, "stalled in round 2, missing "+string(stalled))
+
+	var aborted *protocol.ErrAborted
+	require.ErrorAs(t, err, &aborted)
+	assert.Equal(t, []party.ID{stalled}, aborted.Culprits)
+
+	for _, h := range handlers {
+		h.Stop()
+	}
+}
+
+func TestSetLogger(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(3)
+	self, stalled := partyIDs[0], partyIDs[2]
+	network := test.NewNetwork(partyIDs)
+	// the last party sends its round 1 broadcast, and then nothing
+	network.SetFilter(func(from, _ party.ID, msg *protocol.Message) bool {
+		return from != stalled || msg.RoundNumber <= 1
+	})
+
+	var logs bytes.Buffer
+	handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+	for _, id := range partyIDs {
+		h, err := protocol.NewMultiHandler(keygen.Start(id, partyIDs, 2, group, nil), nil)
+		require.NoError(t, err)
+		if id == self {
+			h.SetLogger(slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+		}
+		handlers[id] = h
+		go test.HandlerLoop(id, h, network)
+	}
+	_, err := handlers[self].ResultWithRoundTimeout(context.Background(), 200*time.Millisecond)
+	require.Error(t, err)
+	for _, h := range handlers {
+		h.Stop()
+	}
+
+	type record struct {
+		Level    string
+		Msg      string
+		Party    string
+		Protocol string
+		Round    *int
+		Missing  []string
+		Culprits []string
+	}
+	var records []record
+	for _, line := range bytes.Split(bytes.TrimSpace(logs.Bytes()), []byte("\n")) {
+		var r record
+		require.NoError(t, json.Unmarshal(line, &r), "every line is a JSON record")
+		assert.Equal(t, string(self), r.Party)
+		assert.NotEmpty(t, r.Protocol)
+		require.NotNil(t, r.Round, "every record has a round")
+		records = append(records, r)
+	}
+
+	waiting := false
+	for _, r := range records {
+		if r.Msg == "waiting for messages" && *r.Round == 2 {
+			waiting = true
+			assert.Contains(t, r.Missing, string(stalled))
+		}
+	}
+	assert.True(t, waiting, "waiting in round 2 is logged")
+
+	last := records[len(records)-1]
+	assert.Equal(t, "WARN", last.Level)
+	assert.Equal(t, "protocol aborted", last.Msg)
+	assert.Equal(t, 2, *last.Round)
+	assert.Equal(t, []string{string(stalled)}, last.Missing)
+	assert.Equal(t, []string{string(stalled)}, last.Culprits)
+}
+
+func TestResultWithRoundTimeoutProgress(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(3)
+	// every message is delayed, so that the whole keygen takes longer than a single round's deadline
+	network := test.NewNetworkWithOptions(partyIDs, test.Options{MinDelay: 100 * time.Millisecond, MaxDelay: 100 * time.Millisecond})
+
+	handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+	for _, id := range partyIDs {
+		h, err := protocol.NewMultiHandler(keygen.Start(id, partyIDs, 2, group, nil), nil)
+		require.NoError(t, err)
+		handlers[id] = h
+		go test.HandlerLoop(id, h, network)
+	}
+	start := time.Now()
+	for _, h := range handlers {
+		_, err := h.ResultWithRoundTimeout(context.Background(), 200*time.Millisecond)
+		require.NoError(t, err)
+	}
+	assert.Greater(t, time.Since(start), 200*time.Millisecond, "the deadline must start over with each round")
+}
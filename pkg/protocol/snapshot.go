@@ -0,0 +1,152 @@
+package protocol
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/pkg/party"
+)
+
+// handlerSnapshot is the encoding of a snapshot, see MultiHandler.Snapshot.
+type handlerSnapshot struct {
+	SessionID []byte
+	Echo      bool
+	// Messages are the messages received from the other parties, in their binary encoding.
+	Messages [][]byte
+	// BroadcastHashes are the hashes of the broadcasts of each completed broadcast round.
+	BroadcastHashes map[round.Number][]byte
+	// Sent holds the hash of every message we sent, by sentKey.
+	Sent map[string][]byte
+}
+
+// Snapshot returns the state of the execution, from which RestoreMultiHandler recreates the handler,
+// for instance after a crash, so that it continues where it left off instead of starting over.
+//
+// Rounds hold secrets that can't be serialized in general, so the snapshot doesn't contain them.
+// It holds the messages stored so far, the broadcast hashes, and the hashes of the messages we sent,
+// and RestoreMultiHandler rebuilds the rounds by replaying these messages.
+// The snapshot reveals the messages sent to us, which may include shares of secrets, so it must be
+// kept as safely as a config.
+func (h *MultiHandler) Snapshot() ([]byte, error) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if h.err != nil || h.result != nil {
+		return nil, errors.New("protocol: can't snapshot an execution which has finished")
+	}
+
+	s := handlerSnapshot{
+		SessionID:       h.sessionID,
+		Echo:            h.echo,
+		BroadcastHashes: h.broadcastHashes,
+		Sent:            h.sentHashes,
+	}
+	r := h.currentRound
+	for number := round.Number(1); number <= r.FinalRoundNumber(); number++ {
+		for _, q := range []map[party.ID]*Message{h.broadcast[number], h.messages[number], h.echoes[number]} {
+			for _, id := range r.OtherPartyIDs() {
+				msg := q[id]
+				if msg == nil {
+					continue
+				}
+				data, err := msg.MarshalBinary()
+				if err != nil {
+					return nil, fmt.Errorf("protocol: failed to marshal message: %w", err)
+				}
+				s.Messages = append(s.Messages, data)
+			}
+		}
+	}
+	data, err := cbor.Marshal(&s)
+	if err != nil {
+		return nil, fmt.Errorf("protocol: failed to marshal snapshot: %w", err)
+	}
+	return data, nil
+}
+
+// RestoreMultiHandler recreates a handler from a snapshot returned by MultiHandler.Snapshot.
+//
+// create must be the StartFunc of the original handler, and draw the same randomness: the rounds are
+// replayed from the start, and our messages must come out as they were sent before the snapshot,
+// since the other parties have received them. This holds for KeygenWithRand with the same seed, but not
+// for protocols using fresh randomness, whose snapshots fail to restore.
+// Messages sent before the snapshot are not sent again, only the ones of the rounds reached since then.
+//
+// The restored handler doesn't authenticate messages, even if the original one did.
+func RestoreMultiHandler(create StartFunc, snapshot []byte) (*MultiHandler, error) {
+	var s handlerSnapshot
+	if err := cbor.Unmarshal(snapshot, &s); err != nil {
+		return nil, fmt.Errorf("protocol: invalid snapshot: %w", err)
+	}
+	h, err := newMultiHandler(create, s.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	h.echo = s.Echo
+	h.replay = s.Sent
+	if h.replay == nil {
+		h.replay = map[string][]byte{}
+	}
+
+	h.finalize()
+	for _, data := range s.Messages {
+		var msg Message
+		if err := msg.UnmarshalBinary(data); err != nil {
+			return nil, fmt.Errorf("protocol: invalid message in snapshot: %w", err)
+		}
+		h.accept(&msg)
+	}
+	h.replay = nil
+
+	if h.replayErr != nil {
+		return nil, h.replayErr
+	}
+	if h.err != nil {
+		return nil, fmt.Errorf("protocol: replaying the snapshot failed: %w", *h.err)
+	}
+	for number, expected := range s.BroadcastHashes {
+		if !bytes.Equal(h.broadcastHashes[number], expected) {
+			return nil, fmt.Errorf("protocol: round %d: the broadcast hash differs from the snapshot", number)
+		}
+	}
+	for key := range s.Sent {
+		if _, ok := h.sentHashes[key]; !ok {
+			return nil, errors.New("protocol: replaying the snapshot didn't send all messages sent before it")
+		}
+	}
+	return h, nil
+}
+
+// emit hands msg to Listen, unless a restored handler already sent it before its snapshot,
+// or a handler replaying an audit log sent it in the logged session.
+func (h *MultiHandler) emit(msg *Message) {
+	key, digest := sentKey(msg), msg.Hash()
+	h.sentHashes[key] = digest
+	if h.replay != nil {
+		if expected, ok := h.replay[key]; ok {
+			if !bytes.Equal(expected, digest) && h.replayErr == nil {
+				h.replayErr = fmt.Errorf("protocol: round %d: our message differs from the one we sent originally, "+
+					"the StartFunc must draw the same randomness", msg.RoundNumber)
+			}
+			return
+		}
+	}
+	if !h.audited(msg, true) {
+		return
+	}
+	h.out <- msg
+}
+
+// sentKey identifies a message we sent by its round, kind and recipient.
+func sentKey(msg *Message) string {
+	kind := "p2p"
+	switch {
+	case msg.Echo:
+		kind = "echo"
+	case msg.Broadcast:
+		kind = "broadcast"
+	}
+	return fmt.Sprintf("%d/%s/%s", msg.RoundNumber, kind, msg.To)
+}
@@ -0,0 +1,83 @@
+package protocol_test
+
+import (
+	"context"
+	mrand "math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/protocol"
+	"github.com/luxfi/threshold/protocols/lss/config"
+	"github.com/luxfi/threshold/protocols/lss/keygen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSnapshotRestore crashes a party after round 1 of keygen, and completes keygen with the handler
+// restored from its snapshot.
+func TestSnapshotRestore(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(3)
+	network := test.NewNetwork(partyIDs)
+	start := func(i int) protocol.StartFunc {
+		return keygen.StartWithRand(partyIDs[i], partyIDs, 2, group, nil, mrand.New(mrand.NewSource(int64(i))))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	handlers := make([]*protocol.MultiHandler, len(partyIDs))
+	for i := range partyIDs {
+		h, err := protocol.NewMultiHandler(start(i), nil)
+		require.NoError(t, err)
+		handlers[i] = h
+	}
+	runAll := func(stopAt round.Number) {
+		var wg sync.WaitGroup
+		for i, h := range handlers {
+			wg.Add(1)
+			go func(h *protocol.MultiHandler) {
+				defer wg.Done()
+				assert.NoError(t, protocol.RunUntil(ctx, h, network.Transport(partyIDs[i]), stopAt))
+			}(h)
+		}
+		wg.Wait()
+	}
+	runAll(2)
+
+	snapshot, err := handlers[0].Snapshot()
+	require.NoError(t, err)
+
+	// the snapshot can only be restored with the same randomness
+	_, err = protocol.RestoreMultiHandler(keygen.StartWithRand(partyIDs[0], partyIDs, 2, group, nil, mrand.New(mrand.NewSource(42))), snapshot)
+	assert.ErrorContains(t, err, "randomness")
+	_, err = protocol.RestoreMultiHandler(start(0), snapshot[1:])
+	assert.Error(t, err)
+
+	restored, err := protocol.RestoreMultiHandler(start(0), snapshot)
+	require.NoError(t, err)
+	assert.Equal(t, handlers[0].SSID(), restored.SSID())
+	assert.Equal(t, handlers[0].StoredMessages(1), restored.StoredMessages(1))
+	handlers[0] = restored
+	runAll(100)
+
+	var publicKey curve.Point
+	for i, h := range handlers {
+		result, err := h.Result()
+		require.NoError(t, err, "party %s", partyIDs[i])
+		c := result.(*config.Config)
+		pk, err := c.PublicKey()
+		require.NoError(t, err)
+		if publicKey == nil {
+			publicKey = pk
+		}
+		assert.True(t, publicKey.Equal(pk))
+	}
+
+	_, err = restored.Snapshot()
+	assert.Error(t, err, "a finished execution has no snapshot")
+}
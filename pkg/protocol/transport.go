@@ -0,0 +1,69 @@
+package protocol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/luxfi/threshold/pkg/party"
+)
+
+// Transport is the connection of a party to the other parties of a protocol, over which Run drives a Handler.
+//
+// Unlike a Network, which is shared by all parties, a Transport belongs to a single party,
+// as a connection to a relay, a gRPC stream or a NATS subscription does.
+type Transport interface {
+	// Send delivers msg to the parties it is for, see Message.IsFor.
+	// It must not block for long, since the handler waits for it before sending its next message.
+	Send(msg *Message)
+	// Receive returns the channel on which the messages for the party arrive, and is called by Run
+	// at the start of each protocol. The channel is closed when the transport can't receive anymore.
+	Receive() <-chan *Message
+	// Parties returns the parties that can be reached over the transport, including the party itself.
+	Parties() []party.ID
+}
+
+// Run drives h over t until the protocol finishes, and returns the result of h.
+//
+// Outgoing messages are sent in the order h produces them, and incoming messages are passed to h.Accept.
+// h is stopped, and an error returned, if ctx is done first, if the receiving channel of t is closed,
+// or if h sends a message to a party which t can't reach.
+// t is left open, so that it can be used for another protocol.
+//
+// A MultiHandler paused by RunUntil is resumed, and keeps receiving from the channel RunUntil got from its transport.
+func Run(ctx context.Context, h Handler, t Transport) (interface{}, error) {
+	reachable := make(map[party.ID]bool)
+	for _, id := range t.Parties() {
+		reachable[id] = true
+	}
+	out := h.Listen()
+	var in <-chan *Message
+	if m, ok := h.(*MultiHandler); ok {
+		in = m.receiveFrom(t)
+		m.setBreakpoint(0)
+	} else {
+		in = t.Receive()
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			h.Stop()
+			return nil, ctx.Err()
+		case msg, ok := <-out:
+			if !ok {
+				return h.Result()
+			}
+			if !msg.Broadcast && msg.To != "" && !reachable[msg.To] {
+				h.Stop()
+				return nil, fmt.Errorf("protocol: %s is not reachable over the transport", msg.To)
+			}
+			t.Send(msg)
+		case msg, ok := <-in:
+			if !ok {
+				h.Stop()
+				return nil, errors.New("protocol: transport closed")
+			}
+			h.Accept(msg)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package protocol_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/protocol"
+	"github.com/luxfi/threshold/protocols/frost"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chanTransport connects a party to the others with one buffered channel per party,
+// without anything from internal/test.
+type chanTransport struct {
+	id    party.ID
+	inbox map[party.ID]chan *protocol.Message
+}
+
+func (t *chanTransport) Send(msg *protocol.Message) {
+	for id, inbox := range t.inbox {
+		if msg.IsFor(id) {
+			inbox <- msg
+		}
+	}
+}
+
+func (t *chanTransport) Receive() <-chan *protocol.Message {
+	return t.inbox[t.id]
+}
+
+func (t *chanTransport) Parties() []party.ID {
+	ids := make([]party.ID, 0, len(t.inbox))
+	for id := range t.inbox {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestRunTransport(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(3)
+	inbox := make(map[party.ID]chan *protocol.Message, len(partyIDs))
+	for _, id := range partyIDs {
+		inbox[id] = make(chan *protocol.Message, 100)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	configs := make(map[party.ID]*frost.Config, len(partyIDs))
+	var mtx sync.Mutex
+	var wg sync.WaitGroup
+	for _, id := range partyIDs {
+		wg.Add(1)
+		go func(id party.ID) {
+			defer wg.Done()
+			h, err := protocol.NewMultiHandler(frost.Keygen(group, id, partyIDs, 1), nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+			result, err := protocol.Run(ctx, h, &chanTransport{id: id, inbox: inbox})
+			if !assert.NoError(t, err) {
+				return
+			}
+			mtx.Lock()
+			configs[id] = result.(*frost.Config)
+			mtx.Unlock()
+		}(id)
+	}
+	wg.Wait()
+
+	require.Len(t, configs, len(partyIDs))
+	publicKey := configs[partyIDs[0]].PublicKey
+	for _, c := range configs {
+		assert.True(t, publicKey.Equal(c.PublicKey))
+	}
+}
+
+func TestRunUnreachable(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	h, err := protocol.NewMultiHandler(frost.Keygen(curve.Secp256k1{}, partyIDs[0], partyIDs, 1), nil)
+	require.NoError(t, err)
+
+	// the third party isn't connected, but its keygen shares are sent point-to-point
+	inbox := map[party.ID]chan *protocol.Message{
+		partyIDs[0]: make(chan *protocol.Message, 100),
+		partyIDs[1]: make(chan *protocol.Message, 100),
+	}
+	other, err := protocol.NewMultiHandler(frost.Keygen(curve.Secp256k1{}, partyIDs[1], partyIDs, 1), nil)
+	require.NoError(t, err)
+	defer other.Stop()
+	third, err := protocol.NewMultiHandler(frost.Keygen(curve.Secp256k1{}, partyIDs[2], partyIDs, 1), nil)
+	require.NoError(t, err)
+	defer third.Stop()
+	for _, msg := range []*protocol.Message{<-other.Listen(), <-third.Listen()} {
+		inbox[partyIDs[0]] <- msg
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err = protocol.Run(ctx, h, &chanTransport{id: partyIDs[0], inbox: inbox})
+	assert.EqualError(t, err, "protocol: "+string(partyIDs[2])+" is not reachable over the transport")
+}
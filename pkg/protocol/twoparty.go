@@ -50,7 +50,7 @@ func (h *TwoPartyHandler) Result() (interface{}, error) {
 	if h.err != nil {
 		return nil, h.err
 	}
-	return nil, errors.New("protocol: not finished")
+	return nil, ErrNotFinished
 }
 
 func (h *TwoPartyHandler) Listen() <-chan *Message {
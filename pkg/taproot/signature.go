@@ -36,6 +36,9 @@ const SecretKeyLength = 32
 // This is simply an array of 32 bytes.
 type SecretKey []byte
 
+// PublicKeyLength is the number of bytes in a PublicKey.
+const PublicKeyLength = 32
+
 // PublicKey represents a public key for BIP-340 signatures.
 //
 // This key allows verifying signatures produced with the corresponding secret key.
@@ -43,6 +46,23 @@ type SecretKey []byte
 // This is simply an array of 32 bytes.
 type PublicKey []byte
 
+// XOnlyFromPoint returns the 32 byte x-only public key of P, and the point with an even y coordinate
+// it stands for, which is P or its negation.
+//
+// Both are nil if P is not a secp256k1 point, or is the identity.
+//
+// See: https://github.com/bitcoin/bips/blob/master/bip-0340.mediawiki#public-key-generation
+func XOnlyFromPoint(P curve.Point) ([]byte, curve.Point) {
+	point, ok := P.(*curve.Secp256k1Point)
+	if !ok || point.IsIdentity() {
+		return nil, nil
+	}
+	if !point.HasEvenY() {
+		point = point.Negate().(*curve.Secp256k1Point)
+	}
+	return point.XBytes(), point
+}
+
 // Public calculates the public key corresponding to a given secret key.
 //
 // This will return an error if the secret key is invalid.
@@ -177,7 +197,7 @@ func (pk PublicKey) Verify(sig Signature, m []byte) bool {
 		return false
 	}
 
-	P, err := curve.Secp256k1{}.LiftX(pk)
+	P, err := curve.Secp256k1{}.LiftX(pk, false)
 	if err != nil {
 		return false
 	}
@@ -200,3 +220,24 @@ func (pk PublicKey) Verify(sig Signature, m []byte) bool {
 	}
 	return bytes.Equal(check.XBytes(), sig[:32])
 }
+
+// VerifyAll verifies the signatures output by each signer of one signing session, which must all be the same.
+//
+// It returns the indices of the signatures which don't verify, or differ from the first valid one,
+// so that a divergent signer stands out, and true if there are none.
+func (pk PublicKey) VerifyAll(sigs []Signature, m []byte) (bool, []int) {
+	var reference Signature
+	var failed []int
+	for i, sig := range sigs {
+		if !pk.Verify(sig, m) {
+			failed = append(failed, i)
+			continue
+		}
+		if reference == nil {
+			reference = sig
+		} else if !bytes.Equal(sig, reference) {
+			failed = append(failed, i)
+		}
+	}
+	return len(sigs) > 0 && len(failed) == 0, failed
+}
@@ -5,6 +5,8 @@ import (
 	"crypto/sha256"
 	"testing"
 
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/math/sample"
 	"github.com/stretchr/testify/require"
 )
 
@@ -27,3 +29,51 @@ func TestSignatureVerification(t *testing.T) {
 	}
 
 }
+
+func TestVerifyAll(t *testing.T) {
+	m := sha256.Sum256([]byte("verify all"))
+	sk, pk, err := GenKey(rand.Reader)
+	require.NoError(t, err)
+	sig, err := sk.Sign(rand.Reader, m[:])
+	require.NoError(t, err)
+
+	ok, failed := pk.VerifyAll([]Signature{sig, sig, sig}, m[:])
+	require.True(t, ok)
+	require.Empty(t, failed)
+
+	corrupted := make(Signature, len(sig))
+	copy(corrupted, sig)
+	corrupted[SignatureLen-1] ^= 1
+	// a different valid signature betrays a signer which diverged from the session
+	other, err := sk.Sign(rand.Reader, m[:])
+	require.NoError(t, err)
+	ok, failed = pk.VerifyAll([]Signature{sig, corrupted, sig, other}, m[:])
+	require.False(t, ok)
+	require.Equal(t, []int{1, 3}, failed)
+}
+
+func TestXOnlyFromPoint(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		x := sample.Scalar(rand.Reader, curve.Secp256k1{})
+		X := x.ActOnBase()
+		xOnly, P := XOnlyFromPoint(X)
+		require.Len(t, xOnly, PublicKeyLength)
+		require.True(t, P.(*curve.Secp256k1Point).HasEvenY())
+		require.True(t, P.Equal(X) || P.Equal(X.Negate()))
+
+		// the x-only key verifies signatures by the secret key of X, whatever the parity of its y coordinate
+		sk, err := x.MarshalBinary()
+		require.NoError(t, err)
+		m := sha256.Sum256([]byte("x-only"))
+		sig, err := SecretKey(sk).Sign(rand.Reader, m[:])
+		require.NoError(t, err)
+		require.True(t, PublicKey(xOnly).Verify(sig, m[:]))
+	}
+
+	xOnly, P := XOnlyFromPoint(curve.Secp256k1{}.NewPoint())
+	require.Nil(t, xOnly)
+	require.Nil(t, P)
+	xOnly, P = XOnlyFromPoint(curve.Ed25519{}.NewBasePoint())
+	require.Nil(t, xOnly)
+	require.Nil(t, P)
+}
@@ -0,0 +1,87 @@
+package grpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/luxfi/threshold/pkg/party"
+)
+
+// Topology lists the parties of a deployment, with the address each one serves the transport on.
+//
+// It is read from a JSON file such as
+//
+//	{"parties": {"alice": "10.0.0.1:9000", "bob": "10.0.0.2:9000", "carol": "10.0.0.3:9000"}}
+//
+// With TLS credentials, the certificate of each party must be issued for the host of its address,
+// or for the name given by identities, as in "identities": {"alice": "alice.example.com"}.
+type Topology struct {
+	Parties map[party.ID]string `json:"parties"`
+	// Identities gives the name the certificate of a party is checked against, in place of the host of its address.
+	Identities map[party.ID]string `json:"identities,omitempty"`
+}
+
+// LoadTopology reads the topology in the JSON file at path.
+func LoadTopology(path string) (*Topology, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("grpc: failed to read topology: %w", err)
+	}
+	var topology Topology
+	if err = json.Unmarshal(data, &topology); err != nil {
+		return nil, fmt.Errorf("grpc: failed to unmarshal topology: %w", err)
+	}
+	if err = topology.Validate(); err != nil {
+		return nil, err
+	}
+	return &topology, nil
+}
+
+// Validate checks that the topology has at least two parties, each with an address.
+func (t *Topology) Validate() error {
+	if len(t.Parties) < 2 {
+		return errors.New("grpc: topology needs at least 2 parties")
+	}
+	for id, addr := range t.Parties {
+		if id == "" {
+			return errors.New("grpc: topology has an empty party ID")
+		}
+		if addr == "" {
+			return fmt.Errorf("grpc: party %s has no address", id)
+		}
+	}
+	for id, name := range t.Identities {
+		if _, ok := t.Parties[id]; !ok {
+			return fmt.Errorf("grpc: identity of party %s, which is not in the topology", id)
+		}
+		if name == "" {
+			return fmt.Errorf("grpc: party %s has an empty identity", id)
+		}
+	}
+	return nil
+}
+
+// CertificateName returns the name the certificate of party id must be valid for:
+// its identity if it has one, and otherwise the host of its address.
+func (t *Topology) CertificateName(id party.ID) string {
+	if name, ok := t.Identities[id]; ok {
+		return name
+	}
+	addr := t.Parties[id]
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// PartyIDs returns the parties of the topology, sorted.
+func (t *Topology) PartyIDs() party.IDSlice {
+	ids := make([]party.ID, 0, len(t.Parties))
+	for id := range t.Parties {
+		ids = append(ids, id)
+	}
+	return party.NewIDSlice(ids)
+}
@@ -0,0 +1,430 @@
+// Package grpc carries protocol messages between parties over gRPC, as a protocol.Transport.
+//
+// Each party serves the transport on the address the Topology gives it, and opens a stream to every other party,
+// on which it sends the messages for that party. Parties can be started in any order:
+// the messages for a party which isn't up yet wait until it is.
+// The service is defined without generated code, and its messages are encoded by a codec of this package,
+// so that no protobuf toolchain is needed.
+package grpc
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/protocol"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/metadata"
+	grpcpeer "google.golang.org/grpc/peer"
+)
+
+// maxMessageSize bounds the size of a single message, as the frames of the relay in pkg/network.
+const maxMessageSize = 1 << 24
+
+// sendQueueSize is the number of messages which can wait for a peer before Send gives up on it.
+const sendQueueSize = 4096
+
+// maxEpochsAhead is how many protocols ahead of ours a party may send messages for.
+// Parties start their protocols at slightly different times, but never more than one apart.
+const maxEpochsAhead = 2
+
+// maxQueuedPerPeer bounds the messages a party may send for protocols we haven't started yet.
+const maxQueuedPerPeer = 1024
+
+// partyHeader is the metadata key with which a party tells who it is when opening a stream.
+// With TLS, it must match the certificate of the stream, see Topology.CertificateName.
+const partyHeader = "x-threshold-party"
+
+const streamMethod = "/threshold.Transport/Stream"
+
+// serviceDesc describes the service by hand, in place of code generated from a .proto file.
+// A single bidirectional stream carries the messages of one party to another, and nothing flows back.
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: "threshold.Transport",
+	HandlerType: (*streamHandler)(nil),
+	Streams: []grpc.StreamDesc{{
+		StreamName:    "Stream",
+		Handler:       func(srv interface{}, stream grpc.ServerStream) error { return srv.(streamHandler).serveStream(stream) },
+		ServerStreams: true,
+		ClientStreams: true,
+	}},
+}
+
+type streamHandler interface {
+	serveStream(stream grpc.ServerStream) error
+}
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+// frame is a message on a stream, along with the epoch of its sender, which is the number of protocols
+// the sender has started over its transport.
+// Messages are delivered to the protocol of the receiver with the same epoch, so that the messages
+// of a party which has moved on to the next protocol don't reach a party still running the previous one.
+type frame struct {
+	epoch uint64
+	data  []byte
+}
+
+// codec encodes a frame as its epoch, as a uvarint, followed by the binary encoding of the message.
+type codec struct{}
+
+const codecName = "threshold-frame"
+
+func (codec) Marshal(v interface{}) ([]byte, error) {
+	f, ok := v.(*frame)
+	if !ok {
+		return nil, fmt.Errorf("grpc: cannot encode %T", v)
+	}
+	return append(binary.AppendUvarint(nil, f.epoch), f.data...), nil
+}
+
+func (codec) Unmarshal(data []byte, v interface{}) error {
+	f, ok := v.(*frame)
+	if !ok {
+		return fmt.Errorf("grpc: cannot decode into %T", v)
+	}
+	epoch, n := binary.Uvarint(data)
+	if n <= 0 {
+		return errors.New("grpc: invalid frame epoch")
+	}
+	// the buffer may be reused once Unmarshal returns
+	f.epoch, f.data = epoch, append([]byte(nil), data[n:]...)
+	return nil
+}
+
+func (codec) Name() string {
+	return codecName
+}
+
+// Transport is a party's connection to the other parties of a Topology.
+//
+// Broadcast messages are fanned out to every other party, with the same encoding for all of them.
+// This doesn't make the broadcast reliable: a sender can still send different messages over different streams,
+// which MultiHandler.EnableEchoBroadcast detects.
+//
+// All parties of the topology must run the same protocols, in the same order, over their transports.
+type Transport struct {
+	self     party.ID
+	topology *Topology
+	server   *grpc.Server
+	peers    map[party.ID]*peer
+	ctx      context.Context
+	cancel   context.CancelFunc
+
+	mtx     sync.Mutex
+	cond    *sync.Cond
+	epoch   uint64
+	session *session
+	// queued holds the messages which arrived for protocols we haven't started yet, by epoch,
+	// and queuedFrom counts them by sender
+	queued     map[uint64][]*protocol.Message
+	queuedFrom map[party.ID]int
+	err        error
+	closed     bool
+}
+
+// peer is the stream on which we send messages to another party.
+type peer struct {
+	id    party.ID
+	conn  *grpc.ClientConn
+	queue chan *frame
+}
+
+// session holds the messages of the current protocol, until they are read from ch.
+type session struct {
+	ch      chan *protocol.Message
+	pending []*protocol.Message
+	stopped bool
+}
+
+// New returns the transport of party self among the parties of topology.
+// The streams to the other parties are opened in the background, once they are up.
+// Serve or ListenAndServe must be called for the other parties to reach us.
+//
+// creds secures the connections, both as a server and as a client, and should authenticate the parties
+// with mutual TLS: the streams of a party are then only accepted with a verified client certificate
+// valid for its name in the topology, see Topology.CertificateName.
+// A nil creds uses plaintext connections, on which parties are trusted to tell who they are, which only suits tests.
+func New(self party.ID, topology *Topology, creds credentials.TransportCredentials) (*Transport, error) {
+	if err := topology.Validate(); err != nil {
+		return nil, err
+	}
+	if _, ok := topology.Parties[self]; !ok {
+		return nil, fmt.Errorf("grpc: party %s is not in the topology", self)
+	}
+	if creds == nil {
+		creds = insecure.NewCredentials()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t := &Transport{
+		self:       self,
+		topology:   topology,
+		server:     grpc.NewServer(grpc.Creds(creds), grpc.MaxRecvMsgSize(maxMessageSize)),
+		peers:      make(map[party.ID]*peer, len(topology.Parties)-1),
+		ctx:        ctx,
+		cancel:     cancel,
+		queued:     make(map[uint64][]*protocol.Message),
+		queuedFrom: make(map[party.ID]int),
+	}
+	t.cond = sync.NewCond(&t.mtx)
+	t.server.RegisterService(&serviceDesc, t)
+
+	for id, addr := range topology.Parties {
+		if id == self {
+			continue
+		}
+		conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds),
+			grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(maxMessageSize)))
+		if err != nil {
+			t.Close()
+			return nil, fmt.Errorf("grpc: party %s: %w", id, err)
+		}
+		p := &peer{id: id, conn: conn, queue: make(chan *frame, sendQueueSize)}
+		t.peers[id] = p
+		go t.sendLoop(p)
+	}
+	return t, nil
+}
+
+// Serve accepts the streams of the other parties on lis, until the transport is closed.
+func (t *Transport) Serve(lis net.Listener) error {
+	return t.server.Serve(lis)
+}
+
+// ListenAndServe serves the transport on the address of our party in the topology.
+func (t *Transport) ListenAndServe() error {
+	lis, err := net.Listen("tcp", t.topology.Parties[t.self])
+	if err != nil {
+		return fmt.Errorf("grpc: %w", err)
+	}
+	return t.Serve(lis)
+}
+
+// Send implements protocol.Transport.
+// It queues msg for each of its recipients, and fails the transport if a recipient has fallen too far behind.
+func (t *Transport) Send(msg *protocol.Message) {
+	data, err := msg.MarshalBinary()
+	if err != nil {
+		t.fail(fmt.Errorf("grpc: failed to marshal message: %w", err))
+		return
+	}
+	t.mtx.Lock()
+	f := &frame{epoch: t.epoch, data: data}
+	t.mtx.Unlock()
+	for id, p := range t.peers {
+		if !msg.IsFor(id) {
+			continue
+		}
+		select {
+		case p.queue <- f:
+		default:
+			t.fail(fmt.Errorf("grpc: too many messages waiting for %s", id))
+			return
+		}
+	}
+}
+
+// Receive implements protocol.Transport.
+// Each call starts the next protocol: the messages still unread for the previous one are dropped,
+// and the messages which arrived early for the new one are delivered first.
+func (t *Transport) Receive() <-chan *protocol.Message {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	if t.session != nil {
+		t.session.stopped = true
+	}
+	t.epoch++
+	s := &session{ch: make(chan *protocol.Message), pending: t.queued[t.epoch], stopped: t.closed}
+	for _, msg := range s.pending {
+		t.queuedFrom[msg.From]--
+	}
+	delete(t.queued, t.epoch)
+	t.session = s
+	t.cond.Broadcast()
+	go t.deliverLoop(s)
+	return s.ch
+}
+
+// Parties implements protocol.Transport.
+func (t *Transport) Parties() []party.ID {
+	return t.topology.PartyIDs()
+}
+
+// Err returns the error which caused the transport to fail, if any.
+func (t *Transport) Err() error {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return t.err
+}
+
+// Close stops the server and closes the streams to the other parties.
+// The channel returned by Receive is closed.
+func (t *Transport) Close() {
+	t.mtx.Lock()
+	if t.closed {
+		t.mtx.Unlock()
+		return
+	}
+	t.closed = true
+	if t.session != nil {
+		t.session.stopped = true
+	}
+	t.cond.Broadcast()
+	t.mtx.Unlock()
+
+	t.cancel()
+	t.server.Stop()
+	for _, p := range t.peers {
+		_ = p.conn.Close()
+	}
+}
+
+// fail records err and closes the transport.
+func (t *Transport) fail(err error) {
+	t.mtx.Lock()
+	if t.err == nil && !t.closed {
+		t.err = err
+	}
+	t.mtx.Unlock()
+	t.Close()
+}
+
+// deliverLoop hands the messages of s to its reader, until s is stopped.
+// It is the only writer of s.ch, which it closes when it returns.
+func (t *Transport) deliverLoop(s *session) {
+	defer close(s.ch)
+	for {
+		t.mtx.Lock()
+		for len(s.pending) == 0 && !s.stopped {
+			t.cond.Wait()
+		}
+		if s.stopped {
+			t.mtx.Unlock()
+			return
+		}
+		msg := s.pending[0]
+		s.pending = s.pending[1:]
+		t.mtx.Unlock()
+
+		select {
+		case s.ch <- msg:
+		case <-t.ctx.Done():
+			return
+		}
+	}
+}
+
+// deliver hands msg, sent by its sender during its protocol number epoch, to our protocol with the same number.
+//
+// It returns an error if the sender is too far ahead of us, or has sent too many messages for protocols
+// we haven't started yet, so that a party cannot make us hold an unbounded number of messages.
+func (t *Transport) deliver(epoch uint64, msg *protocol.Message) error {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	switch {
+	case epoch < t.epoch:
+		// we are done with that protocol
+	case epoch > t.epoch+maxEpochsAhead:
+		return fmt.Errorf("grpc: %s sent a message for protocol %d, while we are at protocol %d", msg.From, epoch, t.epoch)
+	case epoch > t.epoch || t.session == nil:
+		if t.queuedFrom[msg.From] >= maxQueuedPerPeer {
+			return fmt.Errorf("grpc: %s sent too many messages ahead of us", msg.From)
+		}
+		t.queuedFrom[msg.From]++
+		t.queued[epoch] = append(t.queued[epoch], msg)
+	default:
+		t.session.pending = append(t.session.pending, msg)
+		t.cond.Broadcast()
+	}
+	return nil
+}
+
+// sendLoop opens the stream to p, waiting for p to be up, and sends it the messages of its queue.
+func (t *Transport) sendLoop(p *peer) {
+	ctx := metadata.AppendToOutgoingContext(t.ctx, partyHeader, string(t.self))
+	stream, err := p.conn.NewStream(ctx, &serviceDesc.Streams[0], streamMethod,
+		grpc.CallContentSubtype(codecName), grpc.WaitForReady(true))
+	if err != nil {
+		if t.ctx.Err() == nil {
+			t.fail(fmt.Errorf("grpc: failed to open stream to %s: %w", p.id, err))
+		}
+		return
+	}
+	for {
+		select {
+		case <-t.ctx.Done():
+			return
+		case f := <-p.queue:
+			if err = stream.SendMsg(f); err != nil {
+				if t.ctx.Err() == nil {
+					t.fail(fmt.Errorf("grpc: failed to send to %s: %w", p.id, err))
+				}
+				return
+			}
+		}
+	}
+}
+
+// serveStream receives the messages of another party, which must only send messages from itself.
+func (t *Transport) serveStream(stream grpc.ServerStream) error {
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	values := md.Get(partyHeader)
+	if len(values) != 1 {
+		return errors.New("grpc: missing party header")
+	}
+	from := party.ID(values[0])
+	if _, ok := t.peers[from]; !ok {
+		return fmt.Errorf("grpc: unknown party %s", from)
+	}
+	if err := t.authenticate(stream.Context(), from); err != nil {
+		return err
+	}
+	for {
+		var f frame
+		if err := stream.RecvMsg(&f); err != nil {
+			return nil
+		}
+		msg := &protocol.Message{}
+		if err := msg.UnmarshalBinary(f.data); err != nil {
+			return fmt.Errorf("grpc: invalid message from %s: %w", from, err)
+		}
+		if msg.From != from {
+			return fmt.Errorf("grpc: %s sent a message from %s", from, msg.From)
+		}
+		if err := t.deliver(f.epoch, msg); err != nil {
+			return err
+		}
+	}
+}
+
+// authenticate checks that the stream of ctx, which claims to come from party from, is secured by
+// a verified certificate of that party.
+// Without TLS, such as with the plaintext connections of a nil creds, the claim can't be checked.
+func (t *Transport) authenticate(ctx context.Context, from party.ID) error {
+	p, ok := grpcpeer.FromContext(ctx)
+	if !ok {
+		return errors.New("grpc: stream without peer")
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return nil
+	}
+	if len(info.State.VerifiedChains) == 0 || len(info.State.PeerCertificates) == 0 {
+		return fmt.Errorf("grpc: stream of %s has no verified client certificate", from)
+	}
+	name := t.topology.CertificateName(from)
+	if err := info.State.PeerCertificates[0].VerifyHostname(name); err != nil {
+		return fmt.Errorf("grpc: certificate of stream does not belong to %s: %w", from, err)
+	}
+	return nil
+}
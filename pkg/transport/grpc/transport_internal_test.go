@@ -0,0 +1,72 @@
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"testing"
+
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/credentials"
+	grpcpeer "google.golang.org/grpc/peer"
+)
+
+func testTransport(t *testing.T) *Transport {
+	topology := &Topology{
+		Parties:    map[party.ID]string{"a": "127.0.0.1:1", "b": "127.0.0.1:2", "c": "c.test:3"},
+		Identities: map[party.ID]string{"a": "a.test", "b": "b.test"},
+	}
+	transport, err := New("c", topology, nil)
+	require.NoError(t, err)
+	t.Cleanup(transport.Close)
+	return transport
+}
+
+// tlsContext returns the context of a stream secured by a certificate for name.
+func tlsContext(name string, verified bool) context.Context {
+	cert := &x509.Certificate{DNSNames: []string{name}}
+	state := tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+	if verified {
+		state.VerifiedChains = [][]*x509.Certificate{{cert}}
+	}
+	return grpcpeer.NewContext(context.Background(), &grpcpeer.Peer{AuthInfo: credentials.TLSInfo{State: state}})
+}
+
+func TestAuthenticate(t *testing.T) {
+	transport := testTransport(t)
+
+	assert.NoError(t, transport.authenticate(tlsContext("a.test", true), "a"))
+	assert.NoError(t, transport.authenticate(tlsContext("b.test", true), "b"))
+	// b claims to be a
+	assert.Error(t, transport.authenticate(tlsContext("b.test", true), "a"))
+	// the certificate of a, which wasn't verified
+	assert.Error(t, transport.authenticate(tlsContext("a.test", false), "a"))
+	// without TLS, the party header is all there is
+	plaintext := grpcpeer.NewContext(context.Background(), &grpcpeer.Peer{})
+	assert.NoError(t, transport.authenticate(plaintext, "a"))
+	assert.Error(t, transport.authenticate(context.Background(), "a"))
+
+	assert.Equal(t, "a.test", transport.topology.CertificateName("a"))
+	assert.Equal(t, "c.test", transport.topology.CertificateName("c"))
+}
+
+func TestDeliverLimits(t *testing.T) {
+	transport := testTransport(t)
+	msg := &protocol.Message{From: "a"}
+
+	assert.Error(t, transport.deliver(maxEpochsAhead+1, msg), "too far ahead")
+	for i := 0; i < maxQueuedPerPeer; i++ {
+		require.NoError(t, transport.deliver(1, msg))
+	}
+	assert.Error(t, transport.deliver(2, msg), "too many messages queued")
+	// the messages of other parties are counted apart
+	assert.NoError(t, transport.deliver(2, &protocol.Message{From: "b"}))
+
+	// starting the protocol takes the messages out of the queue
+	transport.Receive()
+	assert.NoError(t, transport.deliver(2, msg))
+	assert.NoError(t, transport.deliver(1+maxEpochsAhead, msg))
+}
@@ -0,0 +1,190 @@
+package grpc_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/protocol"
+	"github.com/luxfi/threshold/pkg/transport/grpc"
+	"github.com/luxfi/threshold/protocols/frost"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/credentials"
+)
+
+// runAll runs the protocol of every party over its transport, and returns the results.
+func runAll(t *testing.T, transports map[party.ID]*grpc.Transport, start func(id party.ID) protocol.StartFunc) map[party.ID]interface{} {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	sessionID, err := protocol.NewSessionID()
+	require.NoError(t, err)
+	partyIDs := make([]party.ID, 0, len(transports))
+	for id := range transports {
+		partyIDs = append(partyIDs, id)
+	}
+	results, err := test.RunTransports(ctx, partyIDs, sessionID, func(id party.ID) protocol.Transport {
+		return transports[id]
+	}, start)
+	require.NoError(t, err)
+	return results
+}
+
+func TestKeygenSign(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	listeners := make(map[party.ID]net.Listener, len(partyIDs))
+	topology := &grpc.Topology{Parties: make(map[party.ID]string, len(partyIDs))}
+	for _, id := range partyIDs {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		listeners[id] = lis
+		topology.Parties[id] = lis.Addr().String()
+	}
+
+	transports := make(map[party.ID]*grpc.Transport, len(partyIDs))
+	for _, id := range partyIDs {
+		transport, err := grpc.New(id, topology, nil)
+		require.NoError(t, err)
+		defer transport.Close()
+		go func(lis net.Listener) { _ = transport.Serve(lis) }(listeners[id])
+		transports[id] = transport
+	}
+	assert.Equal(t, partyIDs, party.IDSlice(transports[partyIDs[0]].Parties()))
+
+	group := curve.Secp256k1{}
+	results := runAll(t, transports, func(id party.ID) protocol.StartFunc {
+		return frost.Keygen(group, id, partyIDs, 1)
+	})
+	configs := make(map[party.ID]*frost.Config, len(results))
+	for id, result := range results {
+		configs[id] = result.(*frost.Config)
+	}
+	publicKey := configs[partyIDs[0]].PublicKey
+	for _, c := range configs {
+		require.True(t, publicKey.Equal(c.PublicKey))
+	}
+
+	// all parties sign right away, over the same streams
+	message := []byte("signed over gRPC")
+	results = runAll(t, transports, func(id party.ID) protocol.StartFunc {
+		return frost.Sign(configs[id], partyIDs, message)
+	})
+	for _, result := range results {
+		assert.True(t, result.(frost.Signature).Verify(publicKey, message))
+	}
+}
+
+// mutualTLS returns the credentials of each party, with a certificate for its name issued by a common CA.
+func mutualTLS(t *testing.T, names map[party.ID]string) map[party.ID]credentials.TransportCredentials {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "threshold test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	ca, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	creds := make(map[party.ID]credentials.TransportCredentials, len(names))
+	for id, name := range names {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(int64(len(creds) + 2)),
+			Subject:      pkix.Name{CommonName: name},
+			DNSNames:     []string{name},
+			IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+		require.NoError(t, err)
+		creds[id] = credentials.NewTLS(&tls.Config{
+			Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+			RootCAs:      roots,
+			ClientCAs:    roots,
+			ClientAuth:   tls.RequireAndVerifyClientCert,
+		})
+	}
+	return creds
+}
+
+func TestKeygenMutualTLS(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	listeners := make(map[party.ID]net.Listener, len(partyIDs))
+	topology := &grpc.Topology{
+		Parties:    make(map[party.ID]string, len(partyIDs)),
+		Identities: make(map[party.ID]string, len(partyIDs)),
+	}
+	for _, id := range partyIDs {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		listeners[id] = lis
+		topology.Parties[id] = lis.Addr().String()
+		// all parties share an IP address, so their certificates are told apart by name
+		topology.Identities[id] = string(id) + ".test"
+	}
+	creds := mutualTLS(t, topology.Identities)
+
+	transports := make(map[party.ID]*grpc.Transport, len(partyIDs))
+	for _, id := range partyIDs {
+		transport, err := grpc.New(id, topology, creds[id])
+		require.NoError(t, err)
+		defer transport.Close()
+		go func(lis net.Listener) { _ = transport.Serve(lis) }(listeners[id])
+		transports[id] = transport
+	}
+
+	results := runAll(t, transports, func(id party.ID) protocol.StartFunc {
+		return frost.Keygen(curve.Secp256k1{}, id, partyIDs, 1)
+	})
+	for _, transport := range transports {
+		assert.NoError(t, transport.Err())
+	}
+	assert.Len(t, results, len(partyIDs))
+}
+
+func TestLoadTopology(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "topology.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"parties": {"b": "127.0.0.1:9001", "a": "127.0.0.1:9000"}}`), 0644))
+	topology, err := grpc.LoadTopology(path)
+	require.NoError(t, err)
+	assert.Equal(t, party.IDSlice{"a", "b"}, topology.PartyIDs())
+	assert.Equal(t, "127.0.0.1:9001", topology.Parties["b"])
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"parties": {"a": "127.0.0.1:9000", "b": ""}}`), 0644))
+	_, err = grpc.LoadTopology(path)
+	assert.Error(t, err)
+
+	_, err = grpc.New("c", topology, nil)
+	assert.Error(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"parties": {"a": "127.0.0.1:9000", "b": "127.0.0.1:9001"}, "identities": {"c": "c.test"}}`), 0644))
+	_, err = grpc.LoadTopology(path)
+	assert.Error(t, err, "identity of a party which isn't in the topology")
+}
@@ -0,0 +1,170 @@
+// Package verify checks threshold signatures from their binary encodings.
+//
+// It only depends on the curve and hash packages, and not on the protocol, pool or network machinery,
+// so that services which only verify signatures stay small.
+package verify
+
+import (
+	"crypto/sha512"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/cronokirby/saferith"
+	"github.com/luxfi/threshold/pkg/hash"
+	"github.com/luxfi/threshold/pkg/math/curve"
+)
+
+// ErrInvalidSignature is returned when a well-formed signature doesn't verify.
+var ErrInvalidSignature = errors.New("verify: invalid signature")
+
+// VerifyECDSA checks an ECDSA signature of hash by publicKey, which is a point in the binary encoding of group,
+// the compressed SEC 1 encoding on secp256k1 and P-256.
+//
+// The signature is either 64 bytes r || s, 65 bytes r || s || v as used by Ethereum, whose recovery id is ignored,
+// or the ASN.1 DER encoding used by Bitcoin.
+func VerifyECDSA(group curve.Curve, publicKey, signature, hash []byte) error {
+	if _, ok := group.(curve.Ed25519); ok {
+		return errors.New("verify: ECDSA is not defined over ed25519")
+	}
+	X, err := decodePoint(group, publicKey)
+	if err != nil {
+		return err
+	}
+	r, s, err := decodeECDSA(group, signature)
+	if err != nil {
+		return err
+	}
+	if r.IsZero() || s.IsZero() {
+		return ErrInvalidSignature
+	}
+
+	// R = s⁻¹⋅(m⋅G + r⋅X), whose x coordinate must be r
+	m := curve.FromHash(group, hash)
+	sInv := group.NewScalar().Set(s).Invert()
+	R := sInv.Act(m.ActOnBase().Add(r.Act(X)))
+	if R.IsIdentity() || !R.XScalar().Equal(r) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// VerifySchnorr checks a FROST signature of hash by publicKey, which is a point in the binary encoding of group.
+//
+// The signature is R followed by z, as encoded by frost.Signature.MarshalBinary, and must have been produced
+// with the default ciphersuite. On ed25519, these are RFC 8032 signatures. BIP-340 signatures from taproot
+// signing are checked by taproot.PublicKey.Verify instead.
+func VerifySchnorr(group curve.Curve, publicKey, signature, hash []byte) error {
+	Y, err := decodePoint(group, publicKey)
+	if err != nil {
+		return err
+	}
+	zLen := (group.ScalarBits() + 7) / 8
+	if len(signature) <= zLen {
+		return fmt.Errorf("verify: invalid Schnorr signature length %d", len(signature))
+	}
+	R, err := decodePoint(group, signature[:len(signature)-zLen])
+	if err != nil {
+		return fmt.Errorf("verify: R: %w", err)
+	}
+	z := group.NewScalar()
+	if ed, ok := z.(*curve.Ed25519Scalar); ok {
+		err = ed.SetBytesLE(signature[len(signature)-zLen:])
+	} else {
+		err = z.UnmarshalBinary(signature[len(signature)-zLen:])
+	}
+	if err != nil {
+		return fmt.Errorf("verify: z: %w", err)
+	}
+
+	// z⋅G = R + c⋅Y
+	c, err := schnorrChallenge(group, R, Y, hash)
+	if err != nil {
+		return err
+	}
+	if !z.ActOnBase().Equal(R.Add(c.Act(Y))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func decodePoint(group curve.Curve, data []byte) (curve.Point, error) {
+	P, err := group.DecompressPoint(data)
+	if err != nil {
+		return nil, fmt.Errorf("verify: invalid point: %w", err)
+	}
+	return P, nil
+}
+
+// decodeECDSA returns r and s from a raw or DER encoded ECDSA signature.
+func decodeECDSA(group curve.Curve, signature []byte) (curve.Scalar, curve.Scalar, error) {
+	size := (group.ScalarBits() + 7) / 8
+	var rBytes, sBytes []byte
+	switch {
+	case len(signature) == 2*size || len(signature) == 2*size+1:
+		rBytes, sBytes = signature[:size], signature[size:2*size]
+	case len(signature) > 0 && signature[0] == 0x30:
+		var der struct{ R, S *big.Int }
+		rest, err := asn1.Unmarshal(signature, &der)
+		if err != nil {
+			return nil, nil, fmt.Errorf("verify: invalid DER signature: %w", err)
+		}
+		if len(rest) != 0 {
+			return nil, nil, errors.New("verify: trailing data after DER signature")
+		}
+		if der.R.Sign() < 0 || der.S.Sign() < 0 || der.R.BitLen() > 8*size || der.S.BitLen() > 8*size {
+			return nil, nil, errors.New("verify: DER signature values are out of range")
+		}
+		rBytes, sBytes = der.R.FillBytes(make([]byte, size)), der.S.FillBytes(make([]byte, size))
+	default:
+		return nil, nil, fmt.Errorf("verify: invalid ECDSA signature length %d", len(signature))
+	}
+	r, s := group.NewScalar(), group.NewScalar()
+	if err := r.UnmarshalBinary(rBytes); err != nil {
+		return nil, nil, fmt.Errorf("verify: r: %w", err)
+	}
+	if err := s.UnmarshalBinary(sBytes); err != nil {
+		return nil, nil, fmt.Errorf("verify: s: %w", err)
+	}
+	return r, s, nil
+}
+
+// messageHash mirrors the type FROST writes the message as into its challenge, so that it has the same domain.
+type messageHash []byte
+
+// WriteTo implements io.WriterTo.
+func (m messageHash) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(m)
+	return int64(n), err
+}
+
+// Domain implements hash.WriterToWithDomain.
+func (messageHash) Domain() string {
+	return "messageHash"
+}
+
+// schnorrChallenge computes the challenge c = H(R, Y, m) of FROST's default ciphersuite.
+// It must stay in sync with the challenge of protocols/frost/sign, which this package can't import.
+func schnorrChallenge(group curve.Curve, R, Y curve.Point, m []byte) (curve.Scalar, error) {
+	if _, ok := group.(curve.Ed25519); ok {
+		RBytes, _ := R.MarshalBinary()
+		YBytes, _ := Y.MarshalBinary()
+		cHash := sha512.New()
+		_, _ = cHash.Write(RBytes)
+		_, _ = cHash.Write(YBytes)
+		_, _ = cHash.Write(m)
+		return new(curve.Ed25519Scalar).SetUniformBytesLE(cHash.Sum(nil)), nil
+	}
+	cHash := hash.New()
+	if err := cHash.WriteAny(R, Y, messageHash(m)); err != nil {
+		return nil, fmt.Errorf("verify: challenge: %w", err)
+	}
+	// the same as sample.Scalar, reading the bytes from the digest
+	buffer := make([]byte, group.SafeScalarBytes())
+	if _, err := io.ReadFull(cHash.Digest(), buffer); err != nil {
+		return nil, fmt.Errorf("verify: challenge: %w", err)
+	}
+	return group.NewScalar().SetNat(new(saferith.Nat).SetBytes(buffer)), nil
+}
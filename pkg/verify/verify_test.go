@@ -0,0 +1,107 @@
+package verify_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"math/big"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/luxfi/threshold/internal/rfc6979"
+	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/math/sample"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/protocol"
+	"github.com/luxfi/threshold/pkg/verify"
+	"github.com/luxfi/threshold/protocols/frost"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyECDSA(t *testing.T) {
+	hash := sha256.Sum256([]byte("verify"))
+	for _, group := range []curve.Curve{curve.Secp256k1{}, curve.P256{}} {
+		t.Run(group.Name(), func(t *testing.T) {
+			x := sample.Scalar(rand.Reader, group)
+			publicKey, err := x.ActOnBase().MarshalBinary()
+			require.NoError(t, err)
+			sig, err := rfc6979.Sign(x, hash[:])
+			require.NoError(t, err)
+
+			r, err := sig.R.XScalar().MarshalBinary()
+			require.NoError(t, err)
+			s, err := sig.S.MarshalBinary()
+			require.NoError(t, err)
+			raw := append(r, s...)
+			der, err := asn1.Marshal(struct{ R, S *big.Int }{new(big.Int).SetBytes(r), new(big.Int).SetBytes(s)})
+			require.NoError(t, err)
+			for name, encoded := range map[string][]byte{"raw": raw, "rsv": append(raw, 0), "der": der} {
+				assert.NoError(t, verify.VerifyECDSA(group, publicKey, encoded, hash[:]), name)
+			}
+
+			other := sha256.Sum256([]byte("other"))
+			assert.ErrorIs(t, verify.VerifyECDSA(group, publicKey, raw, other[:]), verify.ErrInvalidSignature)
+			assert.Error(t, verify.VerifyECDSA(group, publicKey, raw[:40], hash[:]))
+			assert.Error(t, verify.VerifyECDSA(group, publicKey[1:], raw, hash[:]))
+		})
+	}
+}
+
+func TestVerifySchnorr(t *testing.T) {
+	hash := sha256.Sum256([]byte("verify"))
+	for _, group := range []curve.Curve{curve.Secp256k1{}, curve.Ed25519{}} {
+		t.Run(group.Name(), func(t *testing.T) {
+			partyIDs := test.PartyIDs(3)
+			sessionID, err := protocol.NewSessionID()
+			require.NoError(t, err)
+			results, err := test.RunProtocol(partyIDs, sessionID, func(id party.ID) protocol.StartFunc {
+				return frost.Keygen(group, id, partyIDs, 1)
+			})
+			require.NoError(t, err)
+			configs := make(map[party.ID]*frost.Config, len(results))
+			for id, result := range results {
+				configs[id] = result.(*frost.Config)
+			}
+			signers := partyIDs[:2]
+			sessionID, err = protocol.NewSessionID()
+			require.NoError(t, err)
+			results, err = test.RunProtocol(signers, sessionID, func(id party.ID) protocol.StartFunc {
+				return frost.Sign(configs[id], signers, hash[:])
+			})
+			require.NoError(t, err)
+			sig := results[signers[0]].(frost.Signature)
+
+			publicKey, err := configs[signers[0]].PublicKey.MarshalBinary()
+			require.NoError(t, err)
+			encoded, err := sig.MarshalBinary()
+			require.NoError(t, err)
+			assert.NoError(t, verify.VerifySchnorr(group, publicKey, encoded, hash[:]))
+			if group.Name() == (curve.Ed25519{}).Name() {
+				assert.True(t, ed25519.Verify(publicKey, hash[:], encoded))
+			}
+
+			other := sha256.Sum256([]byte("other"))
+			assert.ErrorIs(t, verify.VerifySchnorr(group, publicKey, encoded, other[:]), verify.ErrInvalidSignature)
+			assert.Error(t, verify.VerifySchnorr(group, publicKey, encoded[:10], hash[:]))
+		})
+	}
+}
+
+// TestDependencies checks that the package builds without the protocol machinery.
+func TestDependencies(t *testing.T) {
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("the go tool is needed to list the dependencies")
+	}
+	out, err := exec.Command(goTool, "list", "-deps", "github.com/luxfi/threshold/pkg/verify").Output()
+	require.NoError(t, err)
+	for _, dep := range strings.Fields(string(out)) {
+		for _, heavy := range []string{"/pkg/protocol", "/pkg/pool", "/pkg/network", "/internal/round", "/protocols/"} {
+			assert.NotContains(t, dep, "github.com/luxfi/threshold"+heavy, "verify must not depend on %s", dep)
+		}
+	}
+}
@@ -33,9 +33,14 @@ type Proof struct {
 
 // NewProof generates a Schnorr proof of knowledge of exponent for public, using the Fiat-Shamir transform.
 func NewProof(hash *hash.Hash, public curve.Point, private curve.Scalar, gen curve.Point) *Proof {
+	return NewProofWithRand(rand.Reader, hash, public, private, gen)
+}
+
+// NewProofWithRand is like NewProof, but samples the randomness of the proof from rand.
+func NewProofWithRand(rand io.Reader, hash *hash.Hash, public curve.Point, private curve.Scalar, gen curve.Point) *Proof {
 	group := private.Curve()
 
-	a := NewRandomness(rand.Reader, group, gen)
+	a := NewRandomness(rand, group, gen)
 	z := a.Prove(hash, public, private, gen)
 	return &Proof{
 		C: *a.Commitment(),
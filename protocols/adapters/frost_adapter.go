@@ -166,6 +166,7 @@ func (p *FROSTProtocolAdapter) Refresh(config protocol.ThresholdConfig) (protoco
 	for id := range frostAdapter.VerificationShares.Points {
 		participants = append(participants, id)
 	}
+	party.SortIDs(participants)
 	
 	// For now, just do a new keygen (not a true refresh)
 	group := frostAdapter.GetGroup()
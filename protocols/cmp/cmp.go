@@ -1,6 +1,8 @@
 package cmp
 
 import (
+	"fmt"
+
 	"github.com/luxfi/threshold/internal/round"
 	"github.com/luxfi/threshold/pkg/ecdsa"
 	"github.com/luxfi/threshold/pkg/math/curve"
@@ -27,12 +29,23 @@ func EmptyConfig(group curve.Curve) *Config {
 	}
 }
 
+// VerifyConfig checks that a config is consistent before it is used to sign: the threshold is valid,
+// the public shares interpolate to a single public key, and the secret keys match this party's public data.
+func VerifyConfig(config *Config) error {
+	return config.Validate()
+}
+
 // Keygen generates a new shared ECDSA key over the curve defined by `group`. After a successful execution,
 // all participants posses a unique share of this key, as well as auxiliary parameters required during signing.
 //
 // For better performance, a `pool.Pool` can be provided in order to parallelize certain steps of the protocol.
 // Returns *cmp.Config if successful.
 func Keygen(group curve.Curve, selfID party.ID, participants []party.ID, threshold int, pl *pool.Pool) protocol.StartFunc {
+	if err := validateKeygen(selfID, participants, threshold); err != nil {
+		return func([]byte) (round.Session, error) {
+			return nil, err
+		}
+	}
 	info := round.Info{
 		ProtocolID:       "cmp/keygen-threshold",
 		FinalRoundNumber: keygen.Rounds,
@@ -44,8 +57,27 @@ func Keygen(group curve.Curve, selfID party.ID, participants []party.ID, thresho
 	return keygen.Start(info, pl, nil)
 }
 
+// validateKeygen checks the arguments of Keygen, so that they fail before any round runs.
+// A key of threshold t needs t+1 signers, so t must be below the number of parties.
+func validateKeygen(selfID party.ID, participants []party.ID, threshold int) error {
+	if err := round.ValidateParties(selfID, participants); err != nil {
+		return fmt.Errorf("cmp: %w", err)
+	}
+	if threshold < 0 {
+		return fmt.Errorf("cmp: threshold %d must not be negative", threshold)
+	}
+	if threshold >= len(participants) {
+		return fmt.Errorf("cmp: threshold %d needs %d signers, which exceeds parties %d", threshold, threshold+1, len(participants))
+	}
+	return nil
+}
+
 // Refresh allows the parties to refresh all existing cryptographic keys from a previously generated Config.
 // The group's ECDSA public key remains the same, but any previous shares are rendered useless.
+// The parties and the threshold are kept, and so is the generation, while Refreshes is incremented.
+//
+// Refreshing periodically bounds the time an attacker has to steal Threshold + 1 shares:
+// shares stolen before a refresh can't be combined with shares stolen after it.
 // Returns *cmp.Config if successful.
 func Refresh(config *Config, pl *pool.Pool) protocol.StartFunc {
 	info := round.Info{
@@ -61,10 +93,19 @@ func Refresh(config *Config, pl *pool.Pool) protocol.StartFunc {
 
 // Sign generates an ECDSA signature for `messageHash` among the given `signers`.
 // Returns *ecdsa.Signature if successful.
+//
+// Concurrent signings of the same message over one network must be started with different session IDs,
+// see protocol.NewSessionID: the SSID binds the signers and the message, but nothing else sets them apart.
 func Sign(config *Config, signers []party.ID, messageHash []byte, pl *pool.Pool) protocol.StartFunc {
 	return sign.StartSign(config, signers, messageHash, pl)
 }
 
+// SignDigest is like Sign, for a digest the caller already computed with the hash its chain prescribes,
+// such as Keccak-256 for Ethereum or double SHA-256 for Bitcoin. The digest is signed as is, without hashing it again.
+func SignDigest(config *Config, signers []party.ID, digest [32]byte, pl *pool.Pool) protocol.StartFunc {
+	return Sign(config, signers, digest[:], pl)
+}
+
 // Presign generates a preprocessed signature that does not depend on the message being signed.
 // When the message becomes available, the same participants can efficiently combine their shares
 // to produce a full signature with the PresignOnline protocol.
@@ -74,6 +115,24 @@ func Presign(config *Config, signers []party.ID, pl *pool.Pool) protocol.StartFu
 	return presign.StartPresign(config, signers, nil, pl)
 }
 
+// PresignBatch generates count independent PreSignatures in a single protocol execution.
+// All messages of a round are sent together, so a batch takes as many round trips as a single Presign.
+// Returns []*ecdsa.PreSignature if successful.
+//
+// Note: each PreSignature must sign at most one message. Signing two messages with the same
+// PreSignature reuses the ECDSA nonce, which reveals the secret key.
+// PresignOnline and SignBatchOnline refuse a PreSignature that was already used.
+func PresignBatch(config *Config, signers []party.ID, count int, pl *pool.Pool) protocol.StartFunc {
+	return presign.StartPresignBatch(config, signers, count, pl)
+}
+
+// SignBatchOnline is like PresignOnline, but signs messageHashes[i] with preSignatures[i],
+// for all messages in a single protocol execution.
+// Returns []*ecdsa.Signature if successful, in the same order as messageHashes.
+func SignBatchOnline(config *Config, preSignatures []*ecdsa.PreSignature, messageHashes [][]byte, pl *pool.Pool) protocol.StartFunc {
+	return presign.StartSignBatchOnline(config, preSignatures, messageHashes, pl)
+}
+
 // PresignOnline efficiently generates an ECDSA signature for `messageHash` given a preprocessed `PreSignature`.
 // Returns *ecdsa.Signature if successful.
 func PresignOnline(config *Config, preSignature *ecdsa.PreSignature, messageHash []byte, pl *pool.Pool) protocol.StartFunc {
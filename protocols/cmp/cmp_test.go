@@ -2,10 +2,13 @@ package cmp
 
 import (
 	"crypto/rand"
+	"errors"
 	"math"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/luxfi/threshold/internal/round"
 	"github.com/luxfi/threshold/internal/test"
 	"github.com/luxfi/threshold/pkg/ecdsa"
 	"github.com/luxfi/threshold/pkg/math/curve"
@@ -14,9 +17,10 @@ import (
 	"github.com/luxfi/threshold/pkg/protocol"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
 )
 
-func do(t *testing.T, id party.ID, ids []party.ID, threshold int, message []byte, pl *pool.Pool, n *test.Network, wg *sync.WaitGroup) {
+func do(t *testing.T, id party.ID, ids []party.ID, threshold int, message, sessionID []byte, pl *pool.Pool, n *test.Network, wg *sync.WaitGroup) {
 	defer wg.Done()
 	h, err := protocol.NewMultiHandler(Keygen(curve.Secp256k1{}, id, ids, threshold, pl), nil)
 	require.NoError(t, err)
@@ -35,7 +39,7 @@ func do(t *testing.T, id party.ID, ids []party.ID, threshold int, message []byte
 	require.IsType(t, &Config{}, r)
 	c = r.(*Config)
 
-	h, err = protocol.NewMultiHandler(Sign(c, ids, message, pl), nil)
+	h, err = protocol.NewMultiHandler(Sign(c, ids, message, pl), sessionID)
 	require.NoError(t, err)
 	test.HandlerLoop(c.ID, h, n)
 
@@ -45,7 +49,7 @@ func do(t *testing.T, id party.ID, ids []party.ID, threshold int, message []byte
 	signature := signResult.(*ecdsa.Signature)
 	assert.True(t, signature.Verify(c.PublicPoint(), message))
 
-	h, err = protocol.NewMultiHandler(Presign(c, ids, pl), nil)
+	h, err = protocol.NewMultiHandler(Presign(c, ids, pl), sessionID)
 	require.NoError(t, err)
 
 	test.HandlerLoop(c.ID, h, n)
@@ -56,7 +60,7 @@ func do(t *testing.T, id party.ID, ids []party.ID, threshold int, message []byte
 	preSignature := signResult.(*ecdsa.PreSignature)
 	assert.NoError(t, preSignature.Validate())
 
-	h, err = protocol.NewMultiHandler(PresignOnline(c, preSignature, message, pl), nil)
+	h, err = protocol.NewMultiHandler(PresignOnline(c, preSignature, message, pl), sessionID)
 	require.NoError(t, err)
 	test.HandlerLoop(c.ID, h, n)
 
@@ -73,6 +77,8 @@ func TestCMP(t *testing.T) {
 	message := []byte("hello")
 
 	partyIDs := test.PartyIDs(N)
+	sessionID, err := protocol.NewSessionID()
+	require.NoError(t, err)
 
 	n := test.NewNetwork(partyIDs)
 
@@ -81,9 +87,144 @@ func TestCMP(t *testing.T) {
 	for _, id := range partyIDs {
 		pl := pool.NewPool(3)
 		defer pl.TearDown()
-		go do(t, id, partyIDs, T, message, pl, n, &wg)
+		go do(t, id, partyIDs, T, message, sessionID, pl, n, &wg)
+	}
+	wg.Wait()
+}
+
+// TestSignDigest signs a Keccak-256 digest, as Ethereum prescribes, and recovers the signer's address from it.
+func TestSignDigest(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	configs, partyIDs := test.GenerateConfig(curve.Secp256k1{}, 3, 1, rand.Reader, pl)
+
+	var digest [32]byte
+	h := sha3.NewLegacyKeccak256()
+	_, _ = h.Write([]byte("\x19Ethereum Signed Message:\n5hello"))
+	h.Sum(digest[:0])
+
+	signers := partyIDs[:2]
+	rounds := make([]round.Session, 0, len(signers))
+	for _, id := range signers {
+		r, err := SignDigest(configs[id], signers, digest, pl)(nil)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+	for {
+		err, done := test.Rounds(rounds, nil)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+	sig := rounds[0].(*round.Output).Result.(*ecdsa.Signature)
+	publicKey := configs[partyIDs[0]].PublicPoint()
+	assert.True(t, sig.Verify(publicKey, digest[:]), "the digest is signed without hashing it again")
+
+	rsv, err := sig.EthereumRSV()
+	require.NoError(t, err)
+	recovered, err := ecdsa.RecoverEthereumPublicKey(digest[:], rsv)
+	require.NoError(t, err)
+	assert.True(t, recovered.Equal(publicKey))
+}
+
+func TestConcurrentSigningSessions(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	configs, partyIDs := test.GenerateConfig(curve.Secp256k1{}, 3, 1, rand.Reader, pl)
+	publicKey := configs[partyIDs[0]].PublicPoint()
+
+	signers := partyIDs[:2]
+	err := test.RunSigningSessions(signers, 20, func(hash []byte, id party.ID) protocol.StartFunc {
+		return Sign(configs[id], signers, hash, pl)
+	}, func(hash []byte, result interface{}) ([]byte, error) {
+		sig := result.(*ecdsa.Signature)
+		if !sig.Verify(publicKey, hash) {
+			return nil, errors.New("invalid signature")
+		}
+		return sig.MarshalJSON()
+	})
+	require.NoError(t, err)
+}
+
+func TestKeygenUnreliableNetwork(t *testing.T) {
+	N := 3
+	partyIDs := test.PartyIDs(N)
+	n := test.NewNetworkWithOptions(partyIDs, test.Options{
+		MaxDelay:           10 * time.Millisecond,
+		ReorderProbability: 0.3,
+	})
+
+	var wg sync.WaitGroup
+	configs := make([]*Config, N)
+	errs := make([]error, N)
+	for i, id := range partyIDs {
+		wg.Add(1)
+		go func(i int, id party.ID) {
+			defer wg.Done()
+			pl := pool.NewPool(2)
+			defer pl.TearDown()
+			h, err := protocol.NewMultiHandler(Keygen(curve.Secp256k1{}, id, partyIDs, N-1, pl), nil)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			test.HandlerLoop(id, h, n)
+			r, err := h.Result()
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			configs[i] = r.(*Config)
+		}(i, id)
 	}
 	wg.Wait()
+
+	for i, err := range errs {
+		require.NoError(t, err, partyIDs[i])
+	}
+	for _, c := range configs[1:] {
+		assert.True(t, configs[0].PublicPoint().Equal(c.PublicPoint()), "party %s has a different public key", c.ID)
+	}
+}
+
+func TestKeygenSequentialPool(t *testing.T) {
+	N := 3
+	partyIDs := test.PartyIDs(N)
+	n := test.NewNetwork(partyIDs)
+
+	var wg sync.WaitGroup
+	configs := make([]*Config, N)
+	errs := make([]error, N)
+	for i, id := range partyIDs {
+		wg.Add(1)
+		go func(i int, id party.ID) {
+			defer wg.Done()
+			pl := pool.NewSequentialPool()
+			defer pl.TearDown()
+			h, err := protocol.NewMultiHandler(Keygen(curve.Secp256k1{}, id, partyIDs, N-1, pl), nil)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			test.HandlerLoop(id, h, n)
+			r, err := h.Result()
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			configs[i] = r.(*Config)
+		}(i, id)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		require.NoError(t, err, partyIDs[i])
+	}
+	for _, c := range configs {
+		require.NoError(t, c.Validate())
+		assert.True(t, configs[0].PublicPoint().Equal(c.PublicPoint()), "party %s has a different public key", c.ID)
+	}
 }
 
 func TestStart(t *testing.T) {
@@ -161,3 +302,89 @@ func TestStart(t *testing.T) {
 		})
 	}
 }
+
+func TestKeygenInvalidArguments(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(3)
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	tests := []struct {
+		name      string
+		selfID    party.ID
+		partyIDs  []party.ID
+		threshold int
+		err       string
+	}{
+		{"threshold above parties", partyIDs[0], partyIDs, 5, "threshold 5 needs 6 signers, which exceeds parties 3"},
+		{"threshold of all parties", partyIDs[0], partyIDs, 3, "exceeds parties 3"},
+		{"negative threshold", partyIDs[0], partyIDs, -1, "must not be negative"},
+		{"no self", "z", partyIDs, 1, "self z is not among the parties"},
+		{"duplicate party", partyIDs[0], append(partyIDs.Copy(), partyIDs[1]), 1, "party " + string(partyIDs[1]) + " is listed more than once"},
+		{"no parties", partyIDs[0], nil, 0, "no parties"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := protocol.NewMultiHandler(Keygen(group, tt.selfID, tt.partyIDs, tt.threshold, pl), nil)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestRefreshShares(t *testing.T) {
+	group := curve.Secp256k1{}
+	N, T := 3, 1
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	configs, partyIDs := test.GenerateConfig(group, N, T, rand.Reader, pl)
+	for _, c := range configs {
+		c.Generation = 2
+	}
+	publicKey := configs[partyIDs[0]].PublicPoint()
+
+	run := func(ids []party.ID, start func(id party.ID) protocol.StartFunc) map[party.ID]interface{} {
+		n := test.NewNetwork(ids)
+		sessionID, err := protocol.NewSessionID()
+		require.NoError(t, err)
+		results := make(map[party.ID]interface{}, len(ids))
+		var mtx sync.Mutex
+		var wg sync.WaitGroup
+		for _, id := range ids {
+			wg.Add(1)
+			go func(id party.ID) {
+				defer wg.Done()
+				h, err := protocol.NewMultiHandler(start(id), sessionID)
+				require.NoError(t, err)
+				test.HandlerLoop(id, h, n)
+				r, err := h.Result()
+				require.NoError(t, err, id)
+				mtx.Lock()
+				results[id] = r
+				mtx.Unlock()
+			}(id)
+		}
+		wg.Wait()
+		return results
+	}
+
+	refreshed := make(map[party.ID]*Config, N)
+	for id, r := range run(partyIDs, func(id party.ID) protocol.StartFunc { return Refresh(configs[id], pl) }) {
+		refreshed[id] = r.(*Config)
+	}
+	for id, c := range refreshed {
+		require.NoError(t, c.Validate())
+		assert.True(t, publicKey.Equal(c.PublicPoint()), "party %s has a different public key", id)
+		assert.False(t, configs[id].ECDSA.Equal(c.ECDSA), "party %s kept its share", id)
+		assert.False(t, configs[id].Public[id].ECDSA.Equal(c.Public[id].ECDSA), "party %s kept its public share", id)
+		assert.Equal(t, T, c.Threshold)
+		assert.Equal(t, partyIDs, c.PartyIDs())
+		assert.EqualValues(t, 2, c.Generation)
+		assert.EqualValues(t, 1, c.Refreshes)
+	}
+
+	message := []byte("signed after a refresh")
+	signers := partyIDs[:T+1]
+	for _, r := range run(signers, func(id party.ID) protocol.StartFunc { return Sign(refreshed[id], signers, message, pl) }) {
+		assert.True(t, r.(*ecdsa.Signature).Verify(publicKey, message))
+	}
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	stdecdsa "crypto/ecdsa"
 	"errors"
 	"fmt"
 	"io"
@@ -40,6 +41,10 @@ type Config struct {
 	RID types.RID
 	// ChainKey is the chaining key value associated with this public key
 	ChainKey types.RID
+	// Generation counts the resharings this key has gone through, starting at 0 after keygen.
+	Generation uint64
+	// Refreshes counts the refreshes of the shares of this key, which leave Generation unchanged.
+	Refreshes uint64
 	// Public maps party.ID to public. It contains all public information associated to a party.
 	Public map[party.ID]*Public
 }
@@ -70,6 +75,16 @@ func (c *Config) PublicPoint() curve.Point {
 	return sum
 }
 
+// StdPublicKey returns the public key as a *ecdsa.PublicKey of the standard library, see curve.StdPublicKey.
+func (c *Config) StdPublicKey() (*stdecdsa.PublicKey, error) {
+	return curve.StdPublicKey(c.PublicPoint())
+}
+
+// KeyID returns the fingerprint of the public key, see curve.KeyID.
+func (c *Config) KeyID() string {
+	return curve.KeyID(c.PublicPoint())
+}
+
 // PartyIDs returns a sorted slice of party IDs.
 func (c *Config) PartyIDs() party.IDSlice {
 	ids := make([]party.ID, 0, len(c.Public))
@@ -79,6 +94,24 @@ func (c *Config) PartyIDs() party.IDSlice {
 	return party.NewIDSlice(ids)
 }
 
+// VerifyPublicShare checks that the public share of id lies on the polynomial of degree Threshold
+// on which the public shares of the other parties lie, so that it is consistent with the public key.
+//
+// It only uses public data, so that anyone holding a published keyset can check it.
+func (c *Config) VerifyPublicShare(id party.ID) error {
+	shares := make(map[party.ID]curve.Point, len(c.Public))
+	for j, p := range c.Public {
+		if p == nil || p.ECDSA == nil {
+			return fmt.Errorf("config: party %s: missing public data", j)
+		}
+		shares[j] = p.ECDSA
+	}
+	if err := polynomial.VerifyPoint(c.Group, shares, c.Threshold, nil, id); err != nil {
+		return fmt.Errorf("config: public share of %s: %w", id, err)
+	}
+	return nil
+}
+
 // WriteTo implements io.WriterTo interface.
 func (c *Config) WriteTo(w io.Writer) (total int64, err error) {
 	if c == nil {
@@ -146,6 +179,11 @@ func (p *Public) WriteTo(w io.Writer) (total int64, err error) {
 		return
 	}
 
+	// a config reshared from another protocol holds the ECDSA shares only, until it is refreshed
+	if p.ElGamal == nil && p.Paillier == nil && p.Pedersen == nil {
+		return
+	}
+
 	// write ElGamal
 	data, err = p.ElGamal.MarshalBinary()
 	if err != nil {
@@ -200,6 +238,50 @@ func (c *Config) CanSign(signers party.IDSlice) bool {
 	return true
 }
 
+// Validate checks that the config is consistent: the threshold is valid for the number of parties,
+// the public ECDSA shares lie on a polynomial of degree Threshold, and this party's secret keys match
+// its public data.
+func (c *Config) Validate() error {
+	if c.Group == nil {
+		return errors.New("config: missing group")
+	}
+	if !ValidThreshold(c.Threshold, len(c.Public)) {
+		return fmt.Errorf("config: threshold %d is invalid for %d parties", c.Threshold, len(c.Public))
+	}
+	if c.ECDSA == nil || c.ElGamal == nil || c.Paillier == nil {
+		return errors.New("config: missing secret key material")
+	}
+	shares := make(map[party.ID]curve.Point, len(c.Public))
+	for id, p := range c.Public {
+		if p == nil || p.ECDSA == nil || p.ElGamal == nil || p.Paillier == nil || p.Pedersen == nil {
+			return fmt.Errorf("config: party %s: missing public data", id)
+		}
+		if p.ECDSA.IsIdentity() || p.ElGamal.IsIdentity() {
+			return fmt.Errorf("config: party %s: ECDSA or ElGamal public key is identity", id)
+		}
+		shares[id] = p.ECDSA
+	}
+
+	self, ok := c.Public[c.ID]
+	if !ok {
+		return errors.New("config: no public data for this party")
+	}
+	if !c.ECDSA.ActOnBase().Equal(self.ECDSA) {
+		return errors.New("config: ECDSA share does not match its public share")
+	}
+	if !c.ElGamal.ActOnBase().Equal(self.ElGamal) {
+		return errors.New("config: ElGamal secret does not match its public key")
+	}
+	if self.Paillier.N().Nat().Eq(c.Paillier.N().Nat()) != 1 {
+		return errors.New("config: Paillier secret key does not match its public key")
+	}
+
+	if _, err := polynomial.InterpolatePoints(c.Group, shares, c.Threshold); err != nil {
+		return fmt.Errorf("config: public shares: %w", err)
+	}
+	return nil
+}
+
 func ValidThreshold(t, n int) bool {
 	if t < 0 || t > math.MaxUint32 {
 		return false
@@ -210,6 +292,16 @@ func ValidThreshold(t, n int) bool {
 	return true
 }
 
+// Zeroize overwrites the ECDSA share of this config with 0, once it has been superseded by a reshare or a refresh,
+// so that it doesn't remain in memory. The config can't be used to sign afterwards.
+//
+// The ElGamal and Paillier secrets are left untouched, since a reshare carries them over to the new config.
+func (c *Config) Zeroize() {
+	if c.ECDSA != nil {
+		c.ECDSA.Zero()
+	}
+}
+
 // Derive adds adjust to the private key, resulting in a new key pair.
 //
 // This supports arbitrary derivation methods, including BIP32. For explicit
@@ -239,15 +331,17 @@ func (c *Config) Derive(adjust curve.Scalar, newChainKey []byte) (*Config, error
 	}
 
 	return &Config{
-		Group:     c.Group,
-		ID:        c.ID,
-		Threshold: c.Threshold,
-		ECDSA:     c.Group.NewScalar().Set(c.ECDSA).Add(adjust),
-		ElGamal:   c.ElGamal,
-		Paillier:  c.Paillier,
-		RID:       c.RID,
-		ChainKey:  newChainKey,
-		Public:    public,
+		Group:      c.Group,
+		ID:         c.ID,
+		Threshold:  c.Threshold,
+		ECDSA:      c.Group.NewScalar().Set(c.ECDSA).Add(adjust),
+		ElGamal:    c.ElGamal,
+		Paillier:   c.Paillier,
+		RID:        c.RID,
+		ChainKey:   newChainKey,
+		Generation: c.Generation,
+		Refreshes:  c.Refreshes,
+		Public:     public,
 	}, nil
 }
 
@@ -0,0 +1,143 @@
+package config_test
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+
+	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/math/sample"
+	"github.com/luxfi/threshold/pkg/pool"
+	"github.com/luxfi/threshold/protocols/cmp/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	group := curve.Secp256k1{}
+	configs, partyIDs := test.GenerateConfig(group, 4, 2, rand.Reader, pl)
+	for _, id := range partyIDs {
+		require.NoError(t, configs[id].Validate())
+	}
+
+	// the public map is shared by all configs, so every change is undone after its check
+	c := configs[partyIDs[0]]
+	other := partyIDs[3]
+	original := c.Public[other]
+	c.Public[other] = &config.Public{
+		ECDSA:    sample.Scalar(rand.Reader, group).ActOnBase(),
+		ElGamal:  original.ElGamal,
+		Paillier: original.Paillier,
+		Pedersen: original.Pedersen,
+	}
+	assert.ErrorContains(t, c.Validate(), "not on the polynomial", "a tampered public share must be rejected")
+	c.Public[other] = original
+
+	self := c.Public[c.ID]
+	c.Public[c.ID] = &config.Public{
+		ECDSA:    self.ECDSA.Add(group.NewBasePoint()),
+		ElGamal:  self.ElGamal,
+		Paillier: self.Paillier,
+		Pedersen: self.Pedersen,
+	}
+	assert.ErrorContains(t, c.Validate(), "does not match its public share")
+	c.Public[c.ID] = self
+
+	elGamal := c.ElGamal
+	c.ElGamal = sample.Scalar(rand.Reader, group)
+	assert.ErrorContains(t, c.Validate(), "ElGamal")
+	c.ElGamal = elGamal
+
+	paillier := c.Paillier
+	c.Paillier = configs[other].Paillier
+	assert.ErrorContains(t, c.Validate(), "Paillier")
+	c.Paillier = paillier
+
+	c.Threshold = len(partyIDs)
+	assert.ErrorContains(t, c.Validate(), "threshold")
+	// a lower threshold is not enough to describe the shares either
+	c.Threshold = 1
+	assert.ErrorContains(t, c.Validate(), "not on the polynomial")
+	c.Threshold = 2
+
+	require.NoError(t, c.Validate())
+}
+
+func TestConfigJSON(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	group := curve.Secp256k1{}
+	configs, partyIDs := test.GenerateConfig(group, 3, 1, rand.Reader, pl)
+	c := configs[partyIDs[0]]
+	c.Generation = 2
+	c.Refreshes = 3
+
+	data, err := json.Marshal(c)
+	require.NoError(t, err)
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	assert.EqualValues(t, config.JSONVersion, doc["version"])
+
+	decoded := config.EmptyConfig(group)
+	require.NoError(t, json.Unmarshal(data, decoded))
+	require.NoError(t, decoded.Validate())
+	binary, err := c.MarshalBinary()
+	require.NoError(t, err)
+	decodedBinary, err := decoded.MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, binary, decodedBinary, "the JSON encoding must preserve the whole config")
+	assert.EqualValues(t, 3, decoded.Refreshes)
+
+	doc["version"] = 999
+	future, err := json.Marshal(doc)
+	require.NoError(t, err)
+	assert.ErrorContains(t, json.Unmarshal(future, config.EmptyConfig(group)), "unsupported config version 999")
+
+	delete(doc, "version")
+	legacy, err := json.Marshal(doc)
+	require.NoError(t, err)
+	assert.ErrorContains(t, json.Unmarshal(legacy, config.EmptyConfig(group)), "no version")
+
+	doc["version"] = config.JSONVersion
+	doc["threshold"] = 3
+	invalid, err := json.Marshal(doc)
+	require.NoError(t, err)
+	assert.ErrorContains(t, json.Unmarshal(invalid, config.EmptyConfig(group)), "threshold")
+
+	assert.Error(t, json.Unmarshal(data, &config.Config{}), "the group must be set")
+}
+
+func TestVerifyPublicShare(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	group := curve.Secp256k1{}
+	configs, partyIDs := test.GenerateConfig(group, 5, 2, rand.Reader, pl)
+	c := configs[partyIDs[0]]
+	for _, id := range partyIDs {
+		require.NoError(t, c.VerifyPublicShare(id))
+	}
+
+	// the public map is shared by all configs, so the change is undone at the end
+	bad := partyIDs[3]
+	original := c.Public[bad]
+	c.Public[bad] = &config.Public{
+		ECDSA:    sample.Scalar(rand.Reader, group).ActOnBase(),
+		ElGamal:  original.ElGamal,
+		Paillier: original.Paillier,
+		Pedersen: original.Pedersen,
+	}
+	defer func() { c.Public[bad] = original }()
+	for _, id := range partyIDs {
+		if id == bad {
+			assert.ErrorContains(t, c.VerifyPublicShare(id), "public share of "+string(bad))
+		} else {
+			assert.NoError(t, c.VerifyPublicShare(id), "party %s", id)
+		}
+	}
+}
@@ -1,11 +1,16 @@
 package config
 
 import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
 
 	"github.com/cronokirby/saferith"
 	"github.com/fxamacker/cbor/v2"
+	"github.com/luxfi/threshold/internal/params"
 	"github.com/luxfi/threshold/internal/types"
 	"github.com/luxfi/threshold/pkg/math/curve"
 	"github.com/luxfi/threshold/pkg/paillier"
@@ -29,6 +34,8 @@ type configMarshal struct {
 	ECDSA, ElGamal curve.Scalar
 	P, Q           *saferith.Nat
 	RID, ChainKey  types.RID
+	Generation     uint64 `cbor:",omitempty"`
+	Refreshes      uint64 `cbor:",omitempty"`
 	Public         []cbor.RawMessage
 }
 
@@ -58,15 +65,17 @@ func (c *Config) MarshalBinary() ([]byte, error) {
 		ps = append(ps, data)
 	}
 	return cbor.Marshal(&configMarshal{
-		ID:        c.ID,
-		Threshold: c.Threshold,
-		ECDSA:     c.ECDSA,
-		ElGamal:   c.ElGamal,
-		P:         c.Paillier.P(),
-		Q:         c.Paillier.Q(),
-		RID:       c.RID,
-		ChainKey:  c.ChainKey,
-		Public:    ps,
+		ID:         c.ID,
+		Threshold:  c.Threshold,
+		ECDSA:      c.ECDSA,
+		ElGamal:    c.ElGamal,
+		P:          c.Paillier.P(),
+		Q:          c.Paillier.Q(),
+		RID:        c.RID,
+		ChainKey:   c.ChainKey,
+		Generation: c.Generation,
+		Refreshes:  c.Refreshes,
+		Public:     ps,
 	})
 }
 
@@ -81,7 +90,22 @@ func (c *Config) UnmarshalBinary(data []byte) error {
 	if err := cbor.Unmarshal(data, &cm); err != nil {
 		return fmt.Errorf("config: %w", err)
 	}
+	publics := make([]*publicMarshal, 0, len(cm.Public))
+	for _, data := range cm.Public {
+		p := &publicMarshal{
+			ECDSA:   c.Group.NewPoint(),
+			ElGamal: c.Group.NewPoint(),
+		}
+		if err := cbor.Unmarshal(data, p); err != nil {
+			return fmt.Errorf("config: party %s: %w", p.ID, err)
+		}
+		publics = append(publics, p)
+	}
+	return c.set(cm, publics)
+}
 
+// set replaces c with the config described by cm and publics, after validating them.
+func (c *Config) set(cm *configMarshal, publics []*publicMarshal) error {
 	// check ECDSA, ElGamal
 	if cm.ECDSA.IsZero() || cm.ElGamal.IsZero() {
 		return errors.New("config: ECDSA or ElGamal secret key is zero")
@@ -97,15 +121,8 @@ func (c *Config) UnmarshalBinary(data []byte) error {
 	paillierSecret := paillier.NewSecretKeyFromPrimes(cm.P, cm.Q)
 
 	// handle public parameters
-	ps := make(map[party.ID]*Public, len(cm.Public))
-	for _, pm := range cm.Public {
-		p := &publicMarshal{
-			ECDSA:   c.Group.NewPoint(),
-			ElGamal: c.Group.NewPoint(),
-		}
-		if err := cbor.Unmarshal(pm, p); err != nil {
-			return fmt.Errorf("config: party %s: %w", p.ID, err)
-		}
+	ps := make(map[party.ID]*Public, len(publics))
+	for _, p := range publics {
 		if _, ok := ps[p.ID]; ok {
 			return fmt.Errorf("config: party %s: duplicate entry", p.ID)
 		}
@@ -152,15 +169,222 @@ func (c *Config) UnmarshalBinary(data []byte) error {
 	}
 
 	*c = Config{
-		Group:     c.Group,
-		ID:        cm.ID,
-		Threshold: cm.Threshold,
-		ECDSA:     cm.ECDSA,
-		ElGamal:   cm.ElGamal,
-		Paillier:  paillierSecret,
-		RID:       cm.RID,
-		ChainKey:  cm.ChainKey,
-		Public:    ps,
+		Group:      c.Group,
+		ID:         cm.ID,
+		Threshold:  cm.Threshold,
+		ECDSA:      cm.ECDSA,
+		ElGamal:    cm.ElGamal,
+		Paillier:   paillierSecret,
+		RID:        cm.RID,
+		ChainKey:   cm.ChainKey,
+		Generation: cm.Generation,
+		Refreshes:  cm.Refreshes,
+		Public:     ps,
 	}
 	return nil
 }
+
+// JSONVersion is the version of the JSON encoding written by MarshalJSON.
+//
+// UnmarshalJSON upgrades documents of older versions, and rejects newer ones.
+const JSONVersion = 1
+
+// configMigrations[v] upgrades a document of version v to version v+1.
+var configMigrations = []func(data []byte) ([]byte, error){
+	// version 0 documents have no version: they were written with the default encoding of Config,
+	// before it had a JSON encoding, which drops all keys
+	0: func([]byte) ([]byte, error) {
+		return nil, errors.New("the document has no version, and holds no key material")
+	},
+}
+
+// MigrateJSON upgrades a JSON encoded Config of any supported version to JSONVersion.
+func MigrateJSON(data []byte) ([]byte, error) {
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, err
+	}
+	if header.Version < 0 || header.Version > JSONVersion {
+		return nil, fmt.Errorf("config: unsupported config version %d (supported: %d)", header.Version, JSONVersion)
+	}
+	for v := header.Version; v < JSONVersion; v++ {
+		var err error
+		if data, err = configMigrations[v](data); err != nil {
+			return nil, fmt.Errorf("config: failed to upgrade config from version %d: %w", v, err)
+		}
+	}
+	return data, nil
+}
+
+type configJSON struct {
+	Version    int                    `json:"version"`
+	ID         string                 `json:"id"`
+	Threshold  int                    `json:"threshold"`
+	Generation uint64                 `json:"generation"`
+	Refreshes  uint64                 `json:"refreshes,omitempty"`
+	ECDSA      string                 `json:"ecdsa"`      // Base64 encoded
+	ElGamal    string                 `json:"elgamal"`    // Base64 encoded
+	P          string                 `json:"paillier_p"` // Base64 encoded, big endian
+	Q          string                 `json:"paillier_q"` // Base64 encoded, big endian
+	RID        string                 `json:"rid"`        // Base64 encoded
+	ChainKey   string                 `json:"chain_key"`  // Base64 encoded
+	Public     map[string]*publicJSON `json:"public"`
+}
+
+type publicJSON struct {
+	ECDSA   string `json:"ecdsa"`   // Base64 encoded
+	ElGamal string `json:"elgamal"` // Base64 encoded
+	N       string `json:"n"`       // Base64 encoded, big endian
+	S       string `json:"s"`       // Base64 encoded, big endian
+	T       string `json:"t"`       // Base64 encoded, big endian
+}
+
+// JSONType returns the type of the document Config is encoded as by MarshalJSON,
+// so that a schema of the JSON encoding can be derived from it.
+func JSONType() reflect.Type {
+	return reflect.TypeOf(configJSON{})
+}
+
+// MarshalJSON implements json.Marshaler.
+func (c *Config) MarshalJSON() ([]byte, error) {
+	encode := func(m encoding.BinaryMarshaler) (string, error) {
+		data, err := m.MarshalBinary()
+		return base64.StdEncoding.EncodeToString(data), err
+	}
+	ecdsa, err := encode(c.ECDSA)
+	if err != nil {
+		return nil, fmt.Errorf("config: ECDSA: %w", err)
+	}
+	elGamal, err := encode(c.ElGamal)
+	if err != nil {
+		return nil, fmt.Errorf("config: ElGamal: %w", err)
+	}
+	public := make(map[string]*publicJSON, len(c.Public))
+	for id, p := range c.Public {
+		pj := &publicJSON{
+			N: base64.StdEncoding.EncodeToString(p.Pedersen.N().Bytes()),
+			S: base64.StdEncoding.EncodeToString(p.Pedersen.S().Bytes()),
+			T: base64.StdEncoding.EncodeToString(p.Pedersen.T().Bytes()),
+		}
+		if pj.ECDSA, err = encode(p.ECDSA); err != nil {
+			return nil, fmt.Errorf("config: party %s: ECDSA: %w", id, err)
+		}
+		if pj.ElGamal, err = encode(p.ElGamal); err != nil {
+			return nil, fmt.Errorf("config: party %s: ElGamal: %w", id, err)
+		}
+		public[string(id)] = pj
+	}
+	return json.Marshal(&configJSON{
+		Version:    JSONVersion,
+		ID:         string(c.ID),
+		Threshold:  c.Threshold,
+		Generation: c.Generation,
+		Refreshes:  c.Refreshes,
+		ECDSA:      ecdsa,
+		ElGamal:    elGamal,
+		P:          base64.StdEncoding.EncodeToString(c.Paillier.P().Bytes()),
+		Q:          base64.StdEncoding.EncodeToString(c.Paillier.Q().Bytes()),
+		RID:        base64.StdEncoding.EncodeToString(c.RID),
+		ChainKey:   base64.StdEncoding.EncodeToString(c.ChainKey),
+		Public:     public,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+//
+// The config must be created with EmptyConfig first, and is validated like UnmarshalBinary.
+// Documents written by older versions are upgraded with MigrateJSON.
+func (c *Config) UnmarshalJSON(data []byte) error {
+	if c.Group == nil {
+		return errors.New("config must be initialized using EmptyConfig")
+	}
+	data, err := MigrateJSON(data)
+	if err != nil {
+		return err
+	}
+	var cj configJSON
+	if err := json.Unmarshal(data, &cj); err != nil {
+		return err
+	}
+
+	decode := func(field, s string, into encoding.BinaryUnmarshaler) {
+		if err != nil {
+			return
+		}
+		var b []byte
+		if b, err = base64.StdEncoding.DecodeString(s); err == nil {
+			err = into.UnmarshalBinary(b)
+		}
+		if err != nil {
+			err = fmt.Errorf("config: %s: %w", field, err)
+		}
+	}
+	decodeNat := func(field, s string) *saferith.Nat {
+		if err != nil {
+			return nil
+		}
+		var b []byte
+		if b, err = base64.StdEncoding.DecodeString(s); err == nil && len(b) == 0 {
+			err = errors.New("missing")
+		}
+		if err != nil {
+			err = fmt.Errorf("config: %s: %w", field, err)
+			return nil
+		}
+		return new(saferith.Nat).SetBytes(b)
+	}
+	decodeBytes := func(field, s string, size int) []byte {
+		if err != nil {
+			return nil
+		}
+		var b []byte
+		if b, err = base64.StdEncoding.DecodeString(s); err == nil && len(b) != size {
+			err = fmt.Errorf("expected %d bytes, got %d", size, len(b))
+		}
+		if err != nil {
+			err = fmt.Errorf("config: %s: %w", field, err)
+		}
+		return b
+	}
+
+	cm := &configMarshal{
+		ID:         party.ID(cj.ID),
+		Threshold:  cj.Threshold,
+		ECDSA:      c.Group.NewScalar(),
+		ElGamal:    c.Group.NewScalar(),
+		Generation: cj.Generation,
+		Refreshes:  cj.Refreshes,
+	}
+	decode("ECDSA", cj.ECDSA, cm.ECDSA)
+	decode("ElGamal", cj.ElGamal, cm.ElGamal)
+	cm.P = decodeNat("Paillier P", cj.P)
+	cm.Q = decodeNat("Paillier Q", cj.Q)
+	cm.RID = decodeBytes("RID", cj.RID, params.SecBytes)
+	cm.ChainKey = decodeBytes("chain key", cj.ChainKey, params.SecBytes)
+
+	publics := make([]*publicMarshal, 0, len(cj.Public))
+	for id, pj := range cj.Public {
+		if pj == nil {
+			return fmt.Errorf("config: party %s: missing public data", id)
+		}
+		p := &publicMarshal{
+			ID:      party.ID(id),
+			ECDSA:   c.Group.NewPoint(),
+			ElGamal: c.Group.NewPoint(),
+		}
+		decode("party "+id+" ECDSA", pj.ECDSA, p.ECDSA)
+		decode("party "+id+" ElGamal", pj.ElGamal, p.ElGamal)
+		if n := decodeNat("party "+id+" N", pj.N); n != nil {
+			p.N = saferith.ModulusFromNat(n)
+		}
+		p.S = decodeNat("party "+id+" S", pj.S)
+		p.T = decodeNat("party "+id+" T", pj.T)
+		publics = append(publics, p)
+	}
+	if err != nil {
+		return err
+	}
+	return c.set(cm, publics)
+}
@@ -0,0 +1,511 @@
+package cmp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	mrand "math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/luxfi/threshold/pkg/ecdsa"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/pool"
+	"github.com/luxfi/threshold/pkg/protocol"
+)
+
+const (
+	// defaultFailureThreshold is the number of consecutive failures after which a party is unresponsive.
+	defaultFailureThreshold = 2
+	// defaultRecoveryTimeout is how long an unresponsive party is left out before it is tried again.
+	defaultRecoveryTimeout = 30 * time.Second
+	// defaultMaxRetries is the number of signing attempts made by Sign.
+	defaultMaxRetries = 3
+	// defaultAttemptTimeout is how long an attempt waits for a message before it gives up.
+	defaultAttemptTimeout = 30 * time.Second
+	// defaultRetryDelay is the delay before the second attempt.
+	defaultRetryDelay = time.Second
+)
+
+// BackoffStrategy sets how the delay between signing attempts grows.
+type BackoffStrategy int
+
+const (
+	// BackoffLinear waits RetryDelay before the second attempt, twice as long before the third, and so on.
+	BackoffLinear BackoffStrategy = iota
+	// BackoffExponential waits RetryDelay before the second attempt, and doubles the delay for every later one.
+	BackoffExponential
+)
+
+// CoordinatorOptions tune the retries and timeouts of a FaultTolerantCoordinator.
+//
+// A zero field takes its default value, so that the zero CoordinatorOptions gives the behavior of
+// NewFaultTolerantCoordinator. Low latency deployments can shorten the timeouts, and deployments
+// across regions lengthen them.
+type CoordinatorOptions struct {
+	// MaxRetries is the number of signing attempts made by Sign, 3 by default.
+	MaxRetries int
+	// FailureThreshold is the number of consecutive failures after which a party is left out, 2 by default.
+	FailureThreshold int
+	// RecoveryTimeout is how long after its last failure a party which was left out is tried again, 30s by default.
+	RecoveryTimeout time.Duration
+	// AttemptTimeout is how long an attempt waits for a message before the silent signers are failed, 30s by default.
+	AttemptTimeout time.Duration
+	// Backoff is how the delay between attempts grows, linearly by default.
+	Backoff BackoffStrategy
+	// RetryDelay is the delay before the second attempt, 1s by default.
+	RetryDelay time.Duration
+	// MaxRetryDelay caps the delay between attempts, if it is not zero.
+	MaxRetryDelay time.Duration
+	// Jitter is the largest fraction of a delay which is randomly taken off it, between 0 and 1,
+	// so that parties retrying together spread out.
+	Jitter float64
+}
+
+// withDefaults returns o with its zero fields set to their default values.
+func (o CoordinatorOptions) withDefaults() CoordinatorOptions {
+	if o.MaxRetries == 0 {
+		o.MaxRetries = defaultMaxRetries
+	}
+	if o.FailureThreshold == 0 {
+		o.FailureThreshold = defaultFailureThreshold
+	}
+	if o.RecoveryTimeout == 0 {
+		o.RecoveryTimeout = defaultRecoveryTimeout
+	}
+	if o.AttemptTimeout == 0 {
+		o.AttemptTimeout = defaultAttemptTimeout
+	}
+	if o.RetryDelay == 0 {
+		o.RetryDelay = defaultRetryDelay
+	}
+	return o
+}
+
+// validate checks that the options are in range.
+func (o CoordinatorOptions) validate() error {
+	switch {
+	case o.MaxRetries < 0, o.FailureThreshold < 0:
+		return errors.New("retries and failure threshold must not be negative")
+	case o.RecoveryTimeout < 0, o.AttemptTimeout < 0, o.RetryDelay < 0, o.MaxRetryDelay < 0:
+		return errors.New("timeouts and delays must not be negative")
+	case o.Backoff != BackoffLinear && o.Backoff != BackoffExponential:
+		return fmt.Errorf("unknown backoff strategy %d", o.Backoff)
+	case o.Jitter < 0 || o.Jitter > 1:
+		return fmt.Errorf("jitter %v must be between 0 and 1", o.Jitter)
+	}
+	return nil
+}
+
+// delay returns how long to wait before the given attempt, counted from 0.
+// The first attempt starts right away.
+func (o CoordinatorOptions) delay(attempt int) time.Duration {
+	if attempt == 0 {
+		return 0
+	}
+	d := time.Duration(attempt) * o.RetryDelay
+	if o.Backoff == BackoffExponential {
+		d = o.RetryDelay
+		for i := 1; i < attempt && (o.MaxRetryDelay == 0 || d < o.MaxRetryDelay); i++ {
+			d *= 2
+		}
+	}
+	if o.MaxRetryDelay != 0 && d > o.MaxRetryDelay {
+		d = o.MaxRetryDelay
+	}
+	if o.Jitter > 0 {
+		d -= time.Duration(o.Jitter * mrand.Float64() * float64(d))
+	}
+	return d
+}
+
+// SigningEvent is the outcome of a signing attempt for one party, as recorded in a session log.
+type SigningEvent struct {
+	// Party is the signer the event is about.
+	Party party.ID `json:"party"`
+	// Time is when the attempt ended.
+	Time time.Time `json:"time"`
+	// Responded is false if the party sent no message before the attempt timed out or aborted.
+	Responded bool `json:"responded"`
+	// ResponseTime is how long the party took to send its first message, if it responded.
+	ResponseTime time.Duration `json:"response_time,omitempty"`
+}
+
+// PartyHealth is the health of a signer, as seen by a FaultTolerantCoordinator.
+type PartyHealth struct {
+	ID party.ID `json:"id"`
+	// Failures is the number of attempts the party did not respond to.
+	Failures int `json:"failures"`
+	// ConsecutiveFailures is the number of failures since the party last responded.
+	ConsecutiveFailures int `json:"consecutive_failures"`
+	// Responsive is false while the party is left out of signing, after too many consecutive failures.
+	Responsive bool `json:"responsive"`
+	// LastSeen is when the party last responded, and zero if it never did.
+	LastSeen time.Time `json:"last_seen"`
+	// LastFailure is when the party last failed to respond, and zero if it never did.
+	LastFailure time.Time `json:"last_failure"`
+	// ResponseTime is the response time measured when the party last responded.
+	ResponseTime time.Duration `json:"response_time"`
+}
+
+// HealthReport is the health of all signers of a key, see FaultTolerantCoordinator.GetHealthReport.
+type HealthReport struct {
+	// Time is when the report was made.
+	Time time.Time `json:"time"`
+	// Threshold is the threshold of the key: Threshold+1 responsive parties are needed to sign.
+	Threshold int `json:"threshold"`
+	// Parties holds the health of every party, sorted by ID.
+	Parties []PartyHealth `json:"parties"`
+	// Responsive is the number of responsive parties.
+	Responsive int `json:"responsive"`
+	// QuorumAtRisk is true if a single failure more leaves fewer than Threshold+1 responsive parties.
+	QuorumAtRisk bool `json:"quorum_at_risk"`
+}
+
+// FaultTolerantCoordinator keeps track of the health of the signers of a key,
+// so that flaky parties can be spotted and left out before they break quorum.
+//
+// It is safe for concurrent use.
+type FaultTolerantCoordinator struct {
+	mu     sync.Mutex
+	config *Config
+	pool   *pool.Pool
+	// health maps every party of the key to its health.
+	health map[party.ID]*PartyHealth
+	// options are the retries and timeouts, with their defaults set.
+	options CoordinatorOptions
+	// pending holds the messages received from parties of the key for other attempts than the running one,
+	// since the other parties may move on to the next attempt before us, at most maxPendingPerParty of each.
+	pending []*protocol.Message
+	// now returns the current time, and sleep waits between attempts. They are replaced in tests.
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+// NewFaultTolerantCoordinator returns a coordinator for the signers of c, all of them healthy,
+// with the default options.
+func NewFaultTolerantCoordinator(c *Config, pl *pool.Pool) *FaultTolerantCoordinator {
+	coordinator, _ := NewFaultTolerantCoordinatorWithOptions(c, pl, CoordinatorOptions{})
+	return coordinator
+}
+
+// NewFaultTolerantCoordinatorWithOptions is like NewFaultTolerantCoordinator, with retries and timeouts
+// set by options.
+func NewFaultTolerantCoordinatorWithOptions(c *Config, pl *pool.Pool, options CoordinatorOptions) (*FaultTolerantCoordinator, error) {
+	if err := options.validate(); err != nil {
+		return nil, fmt.Errorf("cmp: %w", err)
+	}
+	health := make(map[party.ID]*PartyHealth, len(c.Public))
+	for _, id := range c.PartyIDs() {
+		health[id] = &PartyHealth{ID: id}
+	}
+	return &FaultTolerantCoordinator{
+		config:  c,
+		pool:    pl,
+		health:  health,
+		options: options.withDefaults(),
+		now:     time.Now,
+		sleep:   time.Sleep,
+	}, nil
+}
+
+// Record updates the health of a party with the outcome of a signing attempt.
+func (c *FaultTolerantCoordinator) Record(e SigningEvent) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	h, ok := c.health[e.Party]
+	if !ok {
+		return fmt.Errorf("party %s is not a signer of the key", e.Party)
+	}
+	if e.Responded {
+		h.ConsecutiveFailures = 0
+		if e.Time.After(h.LastSeen) {
+			h.LastSeen = e.Time
+			h.ResponseTime = e.ResponseTime
+		}
+		return nil
+	}
+	h.Failures++
+	h.ConsecutiveFailures++
+	if e.Time.After(h.LastFailure) {
+		h.LastFailure = e.Time
+	}
+	return nil
+}
+
+// responsive returns true if h is not left out of signing at time now.
+//
+// A party is left out after FailureThreshold consecutive failures, until RecoveryTimeout
+// has passed since its last failure.
+func (c *FaultTolerantCoordinator) responsive(h *PartyHealth, now time.Time) bool {
+	return h.ConsecutiveFailures < c.options.FailureThreshold || now.Sub(h.LastFailure) >= c.options.RecoveryTimeout
+}
+
+// GetHealthReport returns the current health of every signer.
+func (c *FaultTolerantCoordinator) GetHealthReport() *HealthReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	report := &HealthReport{
+		Time:      now,
+		Threshold: c.config.Threshold,
+		Parties:   make([]PartyHealth, 0, len(c.health)),
+	}
+	for _, id := range c.config.PartyIDs() {
+		h := *c.health[id]
+		h.Responsive = c.responsive(&h, now)
+		if h.Responsive {
+			report.Responsive++
+		}
+		report.Parties = append(report.Parties, h)
+	}
+	report.QuorumAtRisk = report.Responsive <= c.config.Threshold+1
+	return report
+}
+
+// responsiveSigners returns the parties which are currently responsive, sorted by ID,
+// or an error if there are fewer than Threshold+1 of them.
+func (c *FaultTolerantCoordinator) responsiveSigners() (party.IDSlice, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	var signers party.IDSlice
+	for _, id := range c.config.PartyIDs() {
+		if c.responsive(c.health[id], now) {
+			signers = append(signers, id)
+		}
+	}
+	if len(signers) < c.config.Threshold+1 {
+		return nil, fmt.Errorf("only %d responsive parties, %d needed to sign", len(signers), c.config.Threshold+1)
+	}
+	if !signers.Contains(c.config.ID) {
+		return nil, fmt.Errorf("party %s is not responsive itself", c.config.ID)
+	}
+	return signers, nil
+}
+
+// healthier returns true if a ranks before b when picking signers.
+//
+// Parties are ranked by their consecutive failures, then by whether they failed within the
+// recovery timeout, their total failures, and their last response time, fastest first.
+// Parties which never responded rank as if they took the whole attempt timeout,
+// and ties are broken by ID, so that the ranking is deterministic.
+func (c *FaultTolerantCoordinator) healthier(a, b *PartyHealth, now time.Time) bool {
+	recent := func(h *PartyHealth) bool {
+		return !h.LastFailure.IsZero() && now.Sub(h.LastFailure) < c.options.RecoveryTimeout
+	}
+	responseTime := func(h *PartyHealth) time.Duration {
+		if h.LastSeen.IsZero() {
+			return c.options.AttemptTimeout
+		}
+		return h.ResponseTime
+	}
+	switch {
+	case a.ConsecutiveFailures != b.ConsecutiveFailures:
+		return a.ConsecutiveFailures < b.ConsecutiveFailures
+	case recent(a) != recent(b):
+		return !recent(a)
+	case a.Failures != b.Failures:
+		return a.Failures < b.Failures
+	case responseTime(a) != responseTime(b):
+		return responseTime(a) < responseTime(b)
+	default:
+		return a.ID < b.ID
+	}
+}
+
+// SelectSigners returns the Threshold+1 healthiest responsive parties, sorted by ID,
+// so that a service picking the signers of a request prefers the fastest and most reliable ones.
+func (c *FaultTolerantCoordinator) SelectSigners() (party.IDSlice, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	var ranked []*PartyHealth
+	for _, id := range c.config.PartyIDs() {
+		if h := c.health[id]; c.responsive(h, now) {
+			ranked = append(ranked, h)
+		}
+	}
+	needed := c.config.Threshold + 1
+	if len(ranked) < needed {
+		return nil, fmt.Errorf("only %d responsive parties, %d needed to sign", len(ranked), needed)
+	}
+	sort.Slice(ranked, func(i, j int) bool { return c.healthier(ranked[i], ranked[j], now) })
+	signers := make([]party.ID, 0, needed)
+	for _, h := range ranked[:needed] {
+		signers = append(signers, h.ID)
+	}
+	return party.NewIDSlice(signers), nil
+}
+
+// Sign signs messageHash with the responsive parties, running the protocol over t,
+// and retries without the parties which did not respond.
+// The attempts are one protocol for t, whose Receive is called once.
+//
+// Every responsive party takes part in an attempt, so all parties running Sign with the same
+// sessionID see the same failures, and agree on the signers of the next attempt.
+// Signing with the healthiest parties only, as picked by SelectSigners, takes a service
+// choosing the signers for all parties, since response times are measured by each party.
+// A party which sends no message before the attempt times out is recorded as failed,
+// and left out of the next attempts once it failed too often.
+func (c *FaultTolerantCoordinator) Sign(t protocol.Transport, sessionID, messageHash []byte) (*ecdsa.Signature, error) {
+	defer func() { c.pending = nil }()
+	in := t.Receive()
+
+	var err error
+	for attempt := 0; attempt < c.options.MaxRetries; attempt++ {
+		c.sleep(c.options.delay(attempt))
+
+		var signers party.IDSlice
+		if signers, err = c.responsiveSigners(); err != nil {
+			return nil, err
+		}
+		var sig *ecdsa.Signature
+		sig, err = c.attemptSign(t, in, signers, append(append([]byte{}, sessionID...), byte(attempt)), messageHash)
+		if err == nil {
+			return sig, nil
+		}
+		if errors.Is(err, errTransportClosed) {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("signing failed after %d attempts: %w", c.options.MaxRetries, err)
+}
+
+// errTransportClosed is returned by Sign when the transport stops receiving, since no attempt can succeed then.
+var errTransportClosed = errors.New("cmp: transport closed")
+
+// maxPendingPerParty bounds the messages kept from each party for other attempts, see FaultTolerantCoordinator.pending.
+// It is well above the messages a party sends in an attempt.
+const maxPendingPerParty = 32
+
+// attemptSign runs the signing protocol once with signers, and records which of them responded.
+//
+// The attempt fails if no message of it is accepted for AttemptTimeout. Signers none of whose messages
+// for the attempt were accepted are recorded as failed, and the others as responsive, with the time it
+// took them to send their first message. A message which makes the protocol abort with its sender as
+// the culprit doesn't count as a response.
+func (c *FaultTolerantCoordinator) attemptSign(t protocol.Transport, in <-chan *protocol.Message, signers party.IDSlice, sessionID, messageHash []byte) (*ecdsa.Signature, error) {
+	self := c.config.ID
+	h, err := protocol.NewMultiHandler(Sign(c.config, signers, messageHash, c.pool), sessionID)
+	if err != nil {
+		return nil, err
+	}
+	ssid := h.SSID()
+	start := c.now()
+
+	// respondingParties maps the signers whose messages were accepted to the time of their first one
+	respondingParties := map[party.ID]time.Duration{self: 0}
+	// accept returns true if msg was accepted for this attempt
+	accept := func(msg *protocol.Message) bool {
+		if !bytes.Equal(msg.SSID, ssid) {
+			c.keepPending(msg)
+			return false
+		}
+		if !signers.Contains(msg.From) || !h.CanAccept(msg) {
+			return false
+		}
+		h.Accept(msg)
+		if blamed(h, msg.From) {
+			return false
+		}
+		if _, ok := respondingParties[msg.From]; !ok {
+			respondingParties[msg.From] = c.now().Sub(start)
+		}
+		return true
+	}
+	pending := c.pending
+	c.pending = nil
+	for _, msg := range pending {
+		accept(msg)
+	}
+
+	timer := time.NewTimer(c.options.AttemptTimeout)
+	defer timer.Stop()
+	out := h.Listen()
+	closed := false
+	for out != nil {
+		select {
+		case msg, ok := <-out:
+			if !ok {
+				out = nil
+				continue
+			}
+			t.Send(msg)
+		case msg, ok := <-in:
+			if !ok {
+				// a nil channel is never ready, so the loop waits for out to close
+				in, closed = nil, true
+				h.Stop()
+				continue
+			}
+			// messages of other attempts or other parties don't keep this one alive
+			if accept(msg) {
+				timer.Reset(c.options.AttemptTimeout)
+			}
+		case <-timer.C:
+			h.Stop()
+		}
+	}
+	if closed {
+		return nil, errTransportClosed
+	}
+
+	result, err := h.Result()
+	end := c.now()
+	var failedParties party.IDSlice
+	for _, id := range signers {
+		responseTime, ok := respondingParties[id]
+		if !ok {
+			failedParties = append(failedParties, id)
+		}
+		if id == self {
+			continue
+		}
+		_ = c.Record(SigningEvent{Party: id, Time: end, Responded: ok, ResponseTime: responseTime})
+	}
+	if err != nil {
+		if len(failedParties) > 0 {
+			return nil, fmt.Errorf("no response from %v: %w", failedParties, err)
+		}
+		return nil, err
+	}
+	sig, ok := result.(*ecdsa.Signature)
+	if !ok {
+		return nil, errors.New("signing did not return a signature")
+	}
+	return sig, nil
+}
+
+// keepPending keeps msg for a later attempt, unless its sender isn't a party of the key,
+// or already has maxPendingPerParty messages kept.
+func (c *FaultTolerantCoordinator) keepPending(msg *protocol.Message) {
+	if _, ok := c.health[msg.From]; !ok {
+		return
+	}
+	kept := 0
+	for _, m := range c.pending {
+		if m.From == msg.From {
+			kept++
+		}
+	}
+	if kept < maxPendingPerParty {
+		c.pending = append(c.pending, msg)
+	}
+}
+
+// blamed returns true if h aborted with id among the culprits.
+func blamed(h *protocol.MultiHandler, id party.ID) bool {
+	_, err := h.Result()
+	var protocolErr protocol.Error
+	if !errors.As(err, &protocolErr) {
+		return false
+	}
+	for _, culprit := range protocolErr.Culprits {
+		if culprit == id {
+			return true
+		}
+	}
+	return false
+}
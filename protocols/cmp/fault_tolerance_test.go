@@ -0,0 +1,267 @@
+package cmp
+
+import (
+	mrand "math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/pkg/ecdsa"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/pool"
+	"github.com/luxfi/threshold/pkg/protocol"
+	"github.com/luxfi/threshold/protocols/cmp/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCoordinator returns a coordinator for a key of the given parties, whose clock is set by the returned function.
+func testCoordinator(threshold int, ids ...party.ID) (*FaultTolerantCoordinator, func(time.Time)) {
+	public := make(map[party.ID]*config.Public, len(ids))
+	for _, id := range ids {
+		public[id] = &config.Public{}
+	}
+	c := NewFaultTolerantCoordinator(&Config{Threshold: threshold, Public: public}, nil)
+	now := time.Unix(0, 0)
+	c.now = func() time.Time { return now }
+	return c, func(t time.Time) { now = t }
+}
+
+func TestHealthReport(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c, setNow := testCoordinator(1, "a", "b", "c", "d")
+	setNow(start)
+
+	report := c.GetHealthReport()
+	assert.Equal(t, 4, report.Responsive)
+	assert.False(t, report.QuorumAtRisk)
+	for _, h := range report.Parties {
+		assert.True(t, h.Responsive)
+		assert.Zero(t, h.Failures)
+	}
+
+	// c fails once between successes, d fails twice in a row and is left out
+	events := []SigningEvent{
+		{Party: "a", Time: start.Add(1 * time.Second), Responded: true, ResponseTime: 10 * time.Millisecond},
+		{Party: "c", Time: start.Add(1 * time.Second)},
+		{Party: "d", Time: start.Add(1 * time.Second)},
+		{Party: "c", Time: start.Add(2 * time.Second), Responded: true, ResponseTime: 30 * time.Millisecond},
+		{Party: "d", Time: start.Add(2 * time.Second)},
+		{Party: "a", Time: start.Add(3 * time.Second), Responded: true, ResponseTime: 20 * time.Millisecond},
+	}
+	for _, e := range events {
+		require.NoError(t, c.Record(e))
+	}
+	assert.Error(t, c.Record(SigningEvent{Party: "e", Time: start}), "e is not a signer")
+
+	setNow(start.Add(10 * time.Second))
+	report = c.GetHealthReport()
+	require.Len(t, report.Parties, 4)
+	a, b, cc, d := report.Parties[0], report.Parties[1], report.Parties[2], report.Parties[3]
+	assert.Equal(t, party.ID("a"), a.ID)
+	assert.Equal(t, start.Add(3*time.Second), a.LastSeen)
+	assert.Equal(t, 20*time.Millisecond, a.ResponseTime)
+	assert.True(t, b.Responsive)
+	assert.True(t, b.LastSeen.IsZero())
+
+	assert.Equal(t, 1, cc.Failures)
+	assert.Zero(t, cc.ConsecutiveFailures)
+	assert.True(t, cc.Responsive)
+	assert.Equal(t, start.Add(1*time.Second), cc.LastFailure)
+
+	assert.Equal(t, 2, d.Failures)
+	assert.Equal(t, 2, d.ConsecutiveFailures)
+	assert.False(t, d.Responsive)
+	assert.Equal(t, 3, report.Responsive)
+	assert.False(t, report.QuorumAtRisk)
+
+	// losing one more party leaves exactly threshold+1 responsive parties
+	require.NoError(t, c.Record(SigningEvent{Party: "b", Time: start.Add(4 * time.Second)}))
+	require.NoError(t, c.Record(SigningEvent{Party: "b", Time: start.Add(5 * time.Second)}))
+	report = c.GetHealthReport()
+	assert.Equal(t, 2, report.Responsive)
+	assert.True(t, report.QuorumAtRisk)
+
+	// after the recovery timeout, d is tried again
+	setNow(start.Add(2*time.Second + defaultRecoveryTimeout))
+	report = c.GetHealthReport()
+	assert.True(t, report.Parties[3].Responsive)
+	assert.False(t, report.Parties[1].Responsive)
+}
+
+func TestFaultTolerantSign(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	group := curve.Secp256k1{}
+	configs, partyIDs := test.GenerateConfig(group, 4, 1, mrand.New(mrand.NewSource(1)), pl)
+	messageHash := make([]byte, 32)
+	messageHash[0] = 1
+
+	// the second party never sends nor receives anything
+	silent := partyIDs[1]
+	network := test.NewNetwork(partyIDs)
+	network.SetFilter(func(from, to party.ID, _ *protocol.Message) bool {
+		return from != silent && to != silent
+	})
+
+	var wg sync.WaitGroup
+	coordinators := make(map[party.ID]*FaultTolerantCoordinator, len(partyIDs))
+	sigs := make(map[party.ID]*ecdsa.Signature, len(partyIDs))
+	errs := make(map[party.ID]error, len(partyIDs))
+	for _, id := range partyIDs {
+		if id == silent {
+			continue
+		}
+		c, err := NewFaultTolerantCoordinatorWithOptions(configs[id], pl, CoordinatorOptions{
+			MaxRetries:       2,
+			FailureThreshold: 1,
+			AttemptTimeout:   5 * time.Second,
+			RetryDelay:       10 * time.Millisecond,
+		})
+		require.NoError(t, err)
+		coordinators[id] = c
+	}
+	var mtx sync.Mutex
+	for id, c := range coordinators {
+		wg.Add(1)
+		go func(id party.ID, c *FaultTolerantCoordinator) {
+			defer wg.Done()
+			sig, err := c.Sign(network.Transport(id), []byte("session"), messageHash)
+			mtx.Lock()
+			sigs[id], errs[id] = sig, err
+			mtx.Unlock()
+		}(id, c)
+	}
+	wg.Wait()
+
+	publicKey := configs[partyIDs[0]].PublicPoint()
+	for id, c := range coordinators {
+		require.NoError(t, errs[id], id)
+		assert.True(t, sigs[id].Verify(publicKey, messageHash))
+
+		report := c.GetHealthReport()
+		for _, h := range report.Parties {
+			if h.ID == silent {
+				assert.Equal(t, 1, h.Failures)
+				assert.False(t, h.Responsive, "the silent party is left out")
+			} else {
+				assert.Zero(t, h.Failures, "%s must not be blamed", h.ID)
+				assert.True(t, h.Responsive)
+			}
+		}
+	}
+}
+
+func TestCoordinatorBackoff(t *testing.T) {
+	const d = 10 * time.Millisecond
+	delays := func(o CoordinatorOptions, attempts int) []time.Duration {
+		var out []time.Duration
+		for attempt := 0; attempt < attempts; attempt++ {
+			out = append(out, o.withDefaults().delay(attempt))
+		}
+		return out
+	}
+
+	assert.Equal(t, []time.Duration{0, d, 2 * d, 3 * d}, delays(CoordinatorOptions{RetryDelay: d}, 4))
+	assert.Equal(t, []time.Duration{0, d, 2 * d, 4 * d, 8 * d}, delays(CoordinatorOptions{Backoff: BackoffExponential, RetryDelay: d}, 5))
+	assert.Equal(t, []time.Duration{0, d, 2 * d, 4 * d, 5 * d, 5 * d},
+		delays(CoordinatorOptions{Backoff: BackoffExponential, RetryDelay: d, MaxRetryDelay: 5 * d}, 6))
+	assert.Equal(t, []time.Duration{0, time.Second, 2 * time.Second}, delays(CoordinatorOptions{}, 3), "linear by 1s by default")
+
+	// jitter takes at most its fraction off the delay
+	jittered := CoordinatorOptions{Backoff: BackoffExponential, RetryDelay: d, Jitter: 0.5}
+	for i := 0; i < 100; i++ {
+		for attempt, want := range []time.Duration{d, 2 * d, 4 * d} {
+			got := jittered.delay(attempt + 1)
+			assert.True(t, got > want/2-1 && got <= want, "delay %v of attempt %d", got, attempt+1)
+		}
+	}
+
+	for _, o := range []CoordinatorOptions{
+		{MaxRetries: -1},
+		{AttemptTimeout: -time.Second},
+		{Backoff: BackoffStrategy(7)},
+		{Jitter: 1.5},
+	} {
+		_, err := NewFaultTolerantCoordinatorWithOptions(&Config{}, nil, o)
+		assert.Error(t, err, o)
+	}
+}
+
+func TestCoordinatorMaxRetries(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	configs, partyIDs := test.GenerateConfig(curve.Secp256k1{}, 3, 1, mrand.New(mrand.NewSource(1)), pl)
+
+	// nobody else answers, and parties are never left out, so that every attempt is made
+	c, err := NewFaultTolerantCoordinatorWithOptions(configs[partyIDs[0]], pl, CoordinatorOptions{
+		MaxRetries:       4,
+		FailureThreshold: 100,
+		AttemptTimeout:   10 * time.Millisecond,
+		Backoff:          BackoffExponential,
+		RetryDelay:       time.Millisecond,
+	})
+	require.NoError(t, err)
+	var slept []time.Duration
+	c.sleep = func(d time.Duration) { slept = append(slept, d) }
+
+	network := test.NewNetwork(partyIDs)
+	network.SetFilter(func(_, _ party.ID, _ *protocol.Message) bool { return false })
+	_, err = c.Sign(network.Transport(partyIDs[0]), []byte("session"), make([]byte, 32))
+	assert.ErrorContains(t, err, "after 4 attempts")
+	assert.Equal(t, []time.Duration{0, time.Millisecond, 2 * time.Millisecond, 4 * time.Millisecond}, slept)
+	for _, h := range c.GetHealthReport().Parties[1:] {
+		assert.Equal(t, 4, h.Failures)
+	}
+}
+
+func TestSelectSigners(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c, setNow := testCoordinator(2, "a", "b", "c", "d", "e", "f")
+	setNow(start)
+
+	// with no history, the ranking falls back to the IDs
+	signers, err := c.SelectSigners()
+	require.NoError(t, err)
+	assert.Equal(t, party.IDSlice{"a", "b", "c"}, signers)
+
+	responseTimes := map[party.ID]time.Duration{
+		"a": 90 * time.Millisecond,
+		"b": 40 * time.Millisecond,
+		"c": 70 * time.Millisecond,
+		"d": 10 * time.Millisecond,
+		"e": 20 * time.Millisecond,
+		"f": 5 * time.Millisecond,
+	}
+	for id, d := range responseTimes {
+		require.NoError(t, c.Record(SigningEvent{Party: id, Time: start, Responded: true, ResponseTime: d}))
+	}
+	setNow(start.Add(time.Second))
+	signers, err = c.SelectSigners()
+	require.NoError(t, err)
+	assert.Equal(t, party.IDSlice{"d", "e", "f"}, signers, "the fastest parties are picked")
+
+	// f is the fastest, but just failed, so the next fastest replace it
+	require.NoError(t, c.Record(SigningEvent{Party: "f", Time: start.Add(time.Second)}))
+	signers, err = c.SelectSigners()
+	require.NoError(t, err)
+	assert.Equal(t, party.IDSlice{"b", "d", "e"}, signers)
+
+	// the selection does not depend on the order of the map of parties
+	for i := 0; i < 10; i++ {
+		again, err := c.SelectSigners()
+		require.NoError(t, err)
+		assert.Equal(t, signers, again)
+	}
+
+	// once only two parties are responsive, there are not enough signers
+	for _, id := range []party.ID{"a", "b", "c", "d"} {
+		for i := 0; i < defaultFailureThreshold; i++ {
+			require.NoError(t, c.Record(SigningEvent{Party: id, Time: start.Add(2 * time.Second)}))
+		}
+	}
+	_, err = c.SelectSigners()
+	assert.Error(t, err)
+}
@@ -37,6 +37,11 @@ type round1 struct {
 	// In that case, we will simply use the previous chain key at the very end.
 	PreviousChainKey types.RID
 
+	// PreviousGeneration and PreviousRefreshes are the counters of the config being refreshed.
+	// The refreshed config keeps its generation, and counts one more refresh.
+	PreviousGeneration uint64
+	PreviousRefreshes  uint64
+
 	// VSSSecret = fᵢ(X)
 	// Polynomial from which the new secret shares are computed.
 	// Keygen:  fᵢ(0) = xⁱ
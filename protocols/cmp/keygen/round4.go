@@ -171,6 +171,10 @@ func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
 		ChainKey:  r.ChainKey.Copy(),
 		Public:    PublicData,
 	}
+	if r.PreviousSecretECDSA != nil {
+		UpdatedConfig.Generation = r.PreviousGeneration
+		UpdatedConfig.Refreshes = r.PreviousRefreshes + 1
+	}
 
 	// write new ssid to hash, to bind the Schnorr proof to this new config
 	// Write SSID, selfID to temporary hash
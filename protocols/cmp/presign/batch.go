@@ -0,0 +1,410 @@
+package presign
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/pkg/ecdsa"
+	"github.com/luxfi/threshold/pkg/hash"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/pool"
+	"github.com/luxfi/threshold/pkg/protocol"
+	"github.com/luxfi/threshold/protocols/cmp/config"
+)
+
+const (
+	protocolBatchOfflineID = "cmp/presign-batch-offline"
+	protocolBatchOnlineID  = "cmp/presign-batch-online"
+)
+
+// StartPresignBatch starts count independent presign protocols, which are executed together:
+// in every round, the messages of all presign sessions to a party are sent as a single message.
+//
+// The result of the protocol is a []*ecdsa.PreSignature of length count.
+// If a single presign session aborts, the whole batch aborts.
+func StartPresignBatch(c *config.Config, signers []party.ID, count int, pl *pool.Pool) protocol.StartFunc {
+	return func(sessionID []byte) (round.Session, error) {
+		if c == nil {
+			return nil, errors.New("presign: config is nil")
+		}
+		if count <= 0 {
+			return nil, fmt.Errorf("presign.StartPresignBatch: count must be positive, got %d", count)
+		}
+		info := round.Info{
+			ProtocolID:       protocolBatchOfflineID,
+			FinalRoundNumber: protocolOfflineRounds,
+			SelfID:           c.ID,
+			PartyIDs:         signers,
+			Threshold:        c.Threshold,
+			Group:            c.Group,
+			RequireSessionID: true,
+		}
+		helper, err := round.NewSession(info, sessionID, pl, c, batchSize(count))
+		if err != nil {
+			return nil, fmt.Errorf("presign.StartPresignBatch: %w", err)
+		}
+		sessions := make([]round.Session, count)
+		for i := range sessions {
+			if sessions[i], err = StartPresign(c, signers, nil, pl)(batchSessionID(helper, i)); err != nil {
+				return nil, fmt.Errorf("presign.StartPresignBatch: presignature %d: %w", i, err)
+			}
+		}
+		return newBatchRound(helper, sessions)
+	}
+}
+
+// StartSignBatchOnline signs messages[i] with preSignatures[i], in a single protocol execution.
+//
+// All pre-signatures must have been generated by the same signers, and each of them is
+// marked as used before the session is created, even if creating the session fails afterwards.
+// The result of the protocol is a []*ecdsa.Signature, in the same order as messages.
+func StartSignBatchOnline(c *config.Config, preSignatures []*ecdsa.PreSignature, messages [][]byte, pl *pool.Pool) protocol.StartFunc {
+	return func(sessionID []byte) (round.Session, error) {
+		if c == nil {
+			return nil, errors.New("presign: config is nil")
+		}
+		if len(messages) == 0 {
+			return nil, errors.New("presign.StartSignBatchOnline: no messages to sign")
+		}
+		if len(messages) != len(preSignatures) {
+			return nil, fmt.Errorf("presign.StartSignBatchOnline: got %d pre-signatures for %d messages", len(preSignatures), len(messages))
+		}
+		for i, preSignature := range preSignatures {
+			if preSignature == nil {
+				return nil, fmt.Errorf("presign.StartSignBatchOnline: pre-signature %d is nil", i)
+			}
+			if err := preSignature.CheckGeneration(c.Generation); err != nil {
+				return nil, fmt.Errorf("presign.StartSignBatchOnline: pre-signature %d: %w", i, err)
+			}
+		}
+		for i, preSignature := range preSignatures {
+			if err := preSignature.Consume(); err != nil {
+				return nil, fmt.Errorf("presign.StartSignBatchOnline: pre-signature %d: %w", i, err)
+			}
+		}
+		signers := preSignatures[0].SignerIDs()
+		for i, preSignature := range preSignatures[1:] {
+			if ids := preSignature.SignerIDs(); len(ids) != len(signers) || !signers.Contains(ids...) {
+				return nil, fmt.Errorf("presign.StartSignBatchOnline: pre-signature %d has different signers", i+1)
+			}
+		}
+
+		info := round.Info{
+			ProtocolID:       protocolBatchOnlineID,
+			FinalRoundNumber: protocolFullRounds,
+			SelfID:           c.ID,
+			PartyIDs:         signers,
+			Threshold:        c.Threshold,
+			Group:            c.Group,
+			RequireSessionID: true,
+		}
+		helper, err := round.NewSession(info, sessionID, pl, c, batchSize(len(messages)))
+		if err != nil {
+			return nil, fmt.Errorf("presign.StartSignBatchOnline: %w", err)
+		}
+		sessions := make([]round.Session, len(messages))
+		for i := range sessions {
+			start := startPresignOnline(c, preSignatures[i], messages[i], pl)
+			if sessions[i], err = start(batchSessionID(helper, i)); err != nil {
+				return nil, fmt.Errorf("presign.StartSignBatchOnline: message %d: %w", i, err)
+			}
+		}
+		return newBatchRound(helper, sessions)
+	}
+}
+
+// batchSize is included in the hash state of a batch, so that batches of different sizes never share a session.
+func batchSize(count int) hash.BytesWithDomain {
+	return hash.BytesWithDomain{
+		TheDomain: "Batch Size",
+		Bytes:     binary.BigEndian.AppendUint64(nil, uint64(count)),
+	}
+}
+
+// batchSessionID derives the session ID of the i-th session of a batch from the SSID of the batch.
+func batchSessionID(helper *round.Helper, i int) []byte {
+	id := make([]byte, 0, len(helper.SSID())+8)
+	id = append(id, helper.SSID()...)
+	return binary.BigEndian.AppendUint64(id, uint64(i))
+}
+
+// batchRound runs several sessions of the same protocol in lockstep.
+// Each message it sends holds the messages of all sessions to the same recipient, in order.
+type batchRound struct {
+	*round.Helper
+	sessions []round.Session
+}
+
+// batchBroadcastRound is a batchRound whose sessions expect a broadcast message.
+type batchBroadcastRound struct {
+	*batchRound
+}
+
+var (
+	_ round.Round          = (*batchRound)(nil)
+	_ round.BroadcastRound = (*batchBroadcastRound)(nil)
+)
+
+// newBatchRound returns the round that runs sessions, which must all be in the same round.
+func newBatchRound(helper *round.Helper, sessions []round.Session) (round.Session, error) {
+	r := &batchRound{Helper: helper, sessions: sessions}
+	_, broadcast := sessions[0].(round.BroadcastRound)
+	for i, s := range sessions {
+		if s.Number() != sessions[0].Number() {
+			return nil, fmt.Errorf("presign batch: session %d is in round %d, not %d", i, s.Number(), sessions[0].Number())
+		}
+		if _, ok := s.(round.BroadcastRound); ok != broadcast {
+			return nil, fmt.Errorf("presign batch: session %d disagrees on broadcasting in round %d", i, s.Number())
+		}
+	}
+	if broadcast {
+		return &batchBroadcastRound{r}, nil
+	}
+	return r, nil
+}
+
+// batchMessage holds the contents of one message from each session of a batch.
+type batchMessage struct {
+	number   round.Number
+	contents []round.Content
+}
+
+// batchBroadcast holds the contents of one broadcast message from each session of a batch.
+type batchBroadcast struct {
+	batchMessage
+	reliable bool
+}
+
+func (m *batchMessage) RoundNumber() round.Number { return m.number }
+
+func (m *batchBroadcast) Reliable() bool { return m.reliable }
+
+// MarshalCBOR encodes the contents as an array, in the order of the sessions.
+func (m *batchMessage) MarshalCBOR() ([]byte, error) {
+	raw := make([]cbor.RawMessage, len(m.contents))
+	for i, content := range m.contents {
+		data, err := cbor.Marshal(content)
+		if err != nil {
+			return nil, err
+		}
+		raw[i] = data
+	}
+	return cbor.Marshal(raw)
+}
+
+// UnmarshalCBOR decodes the contents into the empty contents obtained from the sessions.
+func (m *batchMessage) UnmarshalCBOR(data []byte) error {
+	var raw []cbor.RawMessage
+	if err := cbor.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if len(raw) != len(m.contents) {
+		return fmt.Errorf("presign batch: got %d messages for %d sessions", len(raw), len(m.contents))
+	}
+	for i, content := range m.contents {
+		if err := cbor.Unmarshal(raw[i], content); err != nil {
+			return fmt.Errorf("presign batch: session %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// split returns the message of msg addressed to the i-th session.
+func split(msg round.Message, contents []round.Content, i int) round.Message {
+	msg.Content = contents[i]
+	return msg
+}
+
+// VerifyMessage implements round.Round.
+func (r *batchRound) VerifyMessage(msg round.Message) error {
+	body, ok := msg.Content.(*batchMessage)
+	if !ok || len(body.contents) != len(r.sessions) {
+		return round.ErrInvalidContent
+	}
+	for i, s := range r.sessions {
+		if err := s.VerifyMessage(split(msg, body.contents, i)); err != nil {
+			return fmt.Errorf("session %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// StoreMessage implements round.Round.
+func (r *batchRound) StoreMessage(msg round.Message) error {
+	body, ok := msg.Content.(*batchMessage)
+	if !ok || len(body.contents) != len(r.sessions) {
+		return round.ErrInvalidContent
+	}
+	for i, s := range r.sessions {
+		if err := s.StoreMessage(split(msg, body.contents, i)); err != nil {
+			return fmt.Errorf("session %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// StoreBroadcastMessage implements round.BroadcastRound.
+func (r *batchBroadcastRound) StoreBroadcastMessage(msg round.Message) error {
+	body, ok := msg.Content.(*batchBroadcast)
+	if !ok || len(body.contents) != len(r.sessions) {
+		return round.ErrInvalidContent
+	}
+	for i, s := range r.sessions {
+		if err := s.(round.BroadcastRound).StoreBroadcastMessage(split(msg, body.contents, i)); err != nil {
+			return fmt.Errorf("session %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Finalize implements round.Round
+//
+// - finalize all sessions concurrently, and collect their messages
+// - send the messages to each party as a single batch.
+func (r *batchRound) Finalize(out chan<- *round.Message) (round.Session, error) {
+	next := make([]round.Session, len(r.sessions))
+	sent := make([][]*round.Message, len(r.sessions))
+	errs := make([]error, len(r.sessions))
+	var wg sync.WaitGroup
+	for i, s := range r.sessions {
+		wg.Add(1)
+		go func(i int, s round.Session) {
+			defer wg.Done()
+			sessionOut := make(chan *round.Message, r.N()+1)
+			next[i], errs[i] = s.Finalize(sessionOut)
+			close(sessionOut)
+			for msg := range sessionOut {
+				sent[i] = append(sent[i], msg)
+			}
+		}(i, s)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return r, fmt.Errorf("session %d: %w", i, err)
+		}
+	}
+
+	results := make([]interface{}, 0, len(next))
+	for i, s := range next {
+		switch s := s.(type) {
+		case *round.Abort:
+			return r.AbortRound(fmt.Errorf("session %d: %w", i, s.Err), s.Culprits...), nil
+		case *round.Output:
+			results = append(results, s.Result)
+		}
+	}
+	if len(results) == len(next) {
+		return r.ResultRound(batchResult(results)), nil
+	}
+	if len(results) > 0 {
+		return r.AbortRound(fmt.Errorf("presign batch: only %d of %d sessions finished", len(results), len(next))), nil
+	}
+
+	if err := r.send(out, sent); err != nil {
+		return r, err
+	}
+	return newBatchRound(r.Helper, next)
+}
+
+// send groups the messages of all sessions by recipient, and sends one message to each recipient.
+func (r *batchRound) send(out chan<- *round.Message, sent [][]*round.Message) error {
+	type recipient struct {
+		to        party.ID
+		broadcast bool
+	}
+	var order []recipient
+	contents := make(map[recipient][]round.Content)
+	for i, messages := range sent {
+		for _, msg := range messages {
+			key := recipient{msg.To, msg.Broadcast}
+			if contents[key] == nil {
+				order = append(order, key)
+				contents[key] = make([]round.Content, len(sent))
+			}
+			if contents[key][i] != nil {
+				return fmt.Errorf("presign batch: session %d sent two messages to %q", i, msg.To)
+			}
+			contents[key][i] = msg.Content
+		}
+	}
+	for _, key := range order {
+		batch := batchMessage{contents: contents[key]}
+		for i, content := range batch.contents {
+			if content == nil {
+				return fmt.Errorf("presign batch: session %d sent no message to %q", i, key.to)
+			}
+			batch.number = content.RoundNumber()
+		}
+		if !key.broadcast {
+			if err := r.SendMessage(out, &batch, key.to); err != nil {
+				return err
+			}
+			continue
+		}
+		broadcast := &batchBroadcast{batchMessage: batch}
+		for _, content := range batch.contents {
+			if b, ok := content.(round.BroadcastContent); ok && b.Reliable() {
+				broadcast.reliable = true
+			}
+		}
+		if err := r.BroadcastMessage(out, broadcast); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// batchResult converts the results of the sessions to a slice of their concrete type.
+func batchResult(results []interface{}) interface{} {
+	switch results[0].(type) {
+	case *ecdsa.PreSignature:
+		preSignatures := make([]*ecdsa.PreSignature, len(results))
+		for i, result := range results {
+			preSignatures[i] = result.(*ecdsa.PreSignature)
+		}
+		return preSignatures
+	case *ecdsa.Signature:
+		signatures := make([]*ecdsa.Signature, len(results))
+		for i, result := range results {
+			signatures[i] = result.(*ecdsa.Signature)
+		}
+		return signatures
+	}
+	return results
+}
+
+// MessageContent implements round.Round.
+func (r *batchRound) MessageContent() round.Content {
+	if r.sessions[0].MessageContent() == nil {
+		return nil
+	}
+	m := &batchMessage{contents: make([]round.Content, len(r.sessions))}
+	for i, s := range r.sessions {
+		m.contents[i] = s.MessageContent()
+	}
+	m.number = m.contents[0].RoundNumber()
+	return m
+}
+
+// BroadcastContent implements round.BroadcastRound.
+func (r *batchBroadcastRound) BroadcastContent() round.BroadcastContent {
+	if r.sessions[0].(round.BroadcastRound).BroadcastContent() == nil {
+		return nil
+	}
+	m := &batchBroadcast{batchMessage: batchMessage{contents: make([]round.Content, len(r.sessions))}}
+	for i, s := range r.sessions {
+		content := s.(round.BroadcastRound).BroadcastContent()
+		m.contents[i] = content
+		m.reliable = m.reliable || content.Reliable()
+	}
+	m.number = m.contents[0].RoundNumber()
+	return m
+}
+
+// Number implements round.Round.
+func (r *batchRound) Number() round.Number { return r.sessions[0].Number() }
@@ -0,0 +1,118 @@
+package presign
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/pkg/ecdsa"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/pool"
+	"github.com/luxfi/threshold/pkg/protocol"
+	"github.com/luxfi/threshold/protocols/cmp/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runBatch runs start for all parties over a test network, and returns the result of each party.
+func runBatch(t testing.TB, signers party.IDSlice, start func(id party.ID) protocol.StartFunc) map[party.ID]interface{} {
+	sessionID, err := protocol.NewSessionID()
+	require.NoError(t, err)
+	results, err := test.RunProtocol(signers, sessionID, start)
+	require.NoError(t, err)
+	return results
+}
+
+func TestPresignBatch(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	configs, signers := test.GenerateConfig(group, 2, 1, rand.Reader, pl)
+
+	const count = 3
+	results := runBatch(t, signers, func(id party.ID) protocol.StartFunc {
+		return StartPresignBatch(configs[id], signers, count, pl)
+	})
+	preSignatures := make(map[party.ID][]*ecdsa.PreSignature, len(signers))
+	for id, result := range results {
+		require.IsType(t, []*ecdsa.PreSignature{}, result)
+		preSignatures[id] = result.([]*ecdsa.PreSignature)
+		require.Len(t, preSignatures[id], count)
+		for _, p := range preSignatures[id] {
+			require.NoError(t, p.Verify(configs[id]))
+		}
+	}
+	// the pre-signatures are independent, and the parties agree on their order
+	mine, theirs := preSignatures[signers[0]], preSignatures[signers[1]]
+	for i := range mine {
+		assert.True(t, mine[i].R.Equal(theirs[i].R))
+		assert.Equal(t, mine[i].ID, theirs[i].ID)
+		for j := range mine[:i] {
+			assert.False(t, mine[i].R.Equal(mine[j].R), "pre-signatures %d and %d share a nonce", i, j)
+		}
+	}
+
+	messages := make([][]byte, count)
+	for i := range messages {
+		hash := sha256.Sum256([]byte(fmt.Sprintf("message %d", i)))
+		messages[i] = hash[:]
+	}
+	results = runBatch(t, signers, func(id party.ID) protocol.StartFunc {
+		return StartSignBatchOnline(configs[id], preSignatures[id], messages, pl)
+	})
+	publicKey := configs[signers[0]].PublicPoint()
+	for _, result := range results {
+		require.IsType(t, []*ecdsa.Signature{}, result)
+		signatures := result.([]*ecdsa.Signature)
+		require.Len(t, signatures, count)
+		for i, sig := range signatures {
+			assert.True(t, sig.Verify(publicKey, messages[i]), "signature %d", i)
+		}
+	}
+
+	// every pre-signature was consumed, and can't sign again
+	for _, id := range signers {
+		for _, p := range preSignatures[id] {
+			assert.True(t, p.Used())
+		}
+		_, err := StartSignBatchOnline(configs[id], preSignatures[id][:1], messages[:1], pl)(nil)
+		assert.ErrorIs(t, err, ecdsa.ErrPreSignatureUsed)
+		_, err = StartPresignOnline(configs[id], preSignatures[id][0], messages[0], pl)(nil)
+		assert.ErrorIs(t, err, ecdsa.ErrPreSignatureUsed)
+	}
+}
+
+func TestSignBatchOnlineRejects(t *testing.T) {
+	c := &config.Config{}
+	_, err := StartSignBatchOnline(c, nil, nil, nil)(nil)
+	assert.Error(t, err, "nothing to sign")
+	_, err = StartSignBatchOnline(c, []*ecdsa.PreSignature{ecdsa.EmptyPreSignature(group)}, [][]byte{{1}, {2}}, nil)(nil)
+	assert.Error(t, err, "one pre-signature per message")
+	_, err = StartPresignBatch(c, nil, 0, nil)(nil)
+	assert.Error(t, err, "empty batch")
+}
+
+func BenchmarkPresignBatch(b *testing.B) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	configs, signers := test.GenerateConfig(group, 2, 1, rand.Reader, pl)
+	const count = 4
+
+	b.Run("sequential", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			for i := 0; i < count; i++ {
+				runBatch(b, signers, func(id party.ID) protocol.StartFunc {
+					return StartPresign(configs[id], signers, nil, pl)
+				})
+			}
+		}
+	})
+	b.Run("batched", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			runBatch(b, signers, func(id party.ID) protocol.StartFunc {
+				return StartPresignBatch(configs[id], signers, count, pl)
+			})
+		}
+	})
+}
@@ -44,6 +44,8 @@ type presign1 struct {
 
 	// Message is the message to be signed. If it is nil, a presignature is created.
 	Message []byte
+	// Generation is the generation of the config, recorded in the presignature
+	Generation uint64
 }
 
 // VerifyMessage implements round.Round.
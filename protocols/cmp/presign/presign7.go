@@ -137,12 +137,13 @@ func (r *presign7) Finalize(out chan<- *round.Message) (round.Session, error) {
 	}
 
 	preSignature := &ecdsa.PreSignature{
-		ID:       presignatureID,
-		R:        r.R,
-		RBar:     party.NewPointMap(r.RBar),
-		S:        party.NewPointMap(r.S),
-		KShare:   r.KShare,
-		ChiShare: r.ChiShare,
+		ID:         presignatureID,
+		R:          r.R,
+		RBar:       party.NewPointMap(r.RBar),
+		S:          party.NewPointMap(r.S),
+		KShare:     r.KShare,
+		ChiShare:   r.ChiShare,
+		Generation: r.Generation,
 	}
 	if r.Message == nil {
 		return r.ResultRound(preSignature), nil
@@ -33,10 +33,11 @@ func StartPresign(c *config.Config, signers []party.ID, message []byte, pl *pool
 		}
 
 		info := round.Info{
-			SelfID:    c.ID,
-			PartyIDs:  signers,
-			Threshold: c.Threshold,
-			Group:     c.Group,
+			SelfID:           c.ID,
+			PartyIDs:         signers,
+			Threshold:        c.Threshold,
+			Group:            c.Group,
+			RequireSessionID: true,
 		}
 		if len(message) == 0 {
 			info.FinalRoundNumber = protocolOfflineRounds
@@ -88,11 +89,31 @@ func StartPresign(c *config.Config, signers []party.ID, message []byte, pl *pool
 			Paillier:       Paillier,
 			Pedersen:       Pedersen,
 			Message:        message,
+			Generation:     c.Generation,
 		}, nil
 	}
 }
 
+// StartPresignOnline signs message with preSignature.
+//
+// The pre-signature is marked as used when the session is created, and can't be used again:
+// signing two messages with the same pre-signature reveals the secret key.
 func StartPresignOnline(c *config.Config, preSignature *ecdsa.PreSignature, message []byte, pl *pool.Pool) protocol.StartFunc {
+	return func(sessionID []byte) (round.Session, error) {
+		if c == nil || preSignature == nil {
+			return nil, errors.New("presign: config or preSignature is nil")
+		}
+		if err := preSignature.CheckGeneration(c.Generation); err != nil {
+			return nil, fmt.Errorf("sign.Create: %w", err)
+		}
+		if err := preSignature.Consume(); err != nil {
+			return nil, fmt.Errorf("sign.Create: %w", err)
+		}
+		return startPresignOnline(c, preSignature, message, pl)(sessionID)
+	}
+}
+
+func startPresignOnline(c *config.Config, preSignature *ecdsa.PreSignature, message []byte, pl *pool.Pool) protocol.StartFunc {
 	return func(sessionID []byte) (round.Session, error) {
 		if c == nil || preSignature == nil {
 			return nil, errors.New("presign: config or preSignature is nil")
@@ -119,6 +140,7 @@ func StartPresignOnline(c *config.Config, preSignature *ecdsa.PreSignature, mess
 			PartyIDs:         signers,
 			Threshold:        c.Threshold,
 			Group:            c.Group,
+			RequireSessionID: true,
 		}
 
 		helper, err := round.NewSession(
@@ -30,6 +30,9 @@ func StartSign(config *config.Config, signers []party.ID, message []byte, pl *po
 		if len(message) == 0 {
 			return nil, errors.New("sign.Create: message is nil")
 		}
+		if len(signers) < config.Threshold+1 {
+			return nil, fmt.Errorf("sign.Create: need at least %d signers, got %d", config.Threshold+1, len(signers))
+		}
 
 		info := round.Info{
 			ProtocolID:       protocolSignID,
@@ -38,6 +41,7 @@ func StartSign(config *config.Config, signers []party.ID, message []byte, pl *po
 			PartyIDs:         signers,
 			Threshold:        config.Threshold,
 			Group:            config.Group,
+			RequireSessionID: true,
 		}
 
 		helper, err := round.NewSession(info, sessionID, pl, config, types.SigningMessage(message))
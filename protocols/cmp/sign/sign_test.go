@@ -1,6 +1,10 @@
 package sign
 
 import (
+	stdecdsa "crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"math/big"
 	mrand "math/rand"
 	"testing"
 
@@ -58,3 +62,59 @@ func TestRound(t *testing.T) {
 		assert.True(t, signature.Verify(publicPoint, messageHash), "expected valid signature")
 	}
 }
+
+// TestRoundP256 signs with a 2-of-3 config on P-256, and checks the signature with crypto/ecdsa.
+func TestRoundP256(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	group := curve.P256{}
+
+	configs, partyIDs := test.GenerateConfig(group, 3, 1, mrand.New(mrand.NewSource(1)), pl)
+	signers := partyIDs[:2]
+	publicPoint := configs[signers[0]].PublicPoint()
+
+	messageHash := sha256.Sum256([]byte("hello"))
+
+	rounds := make([]round.Session, 0, len(signers))
+	for _, partyID := range signers {
+		r, err := StartSign(configs[partyID], signers, messageHash[:], pl)(nil)
+		require.NoError(t, err, "round creation should not result in an error")
+		rounds = append(rounds, r)
+	}
+
+	for {
+		err, done := test.Rounds(rounds, nil)
+		require.NoError(t, err, "failed to process round")
+		if done {
+			break
+		}
+	}
+
+	X, Y := publicPoint.(*curve.P256Point).Coordinates()
+	publicKey := &stdecdsa.PublicKey{Curve: elliptic.P256(), X: X, Y: Y}
+	for _, r := range rounds {
+		require.IsType(t, &round.Output{}, r, "expected result round")
+		signature := r.(*round.Output).Result.(*ecdsa.Signature)
+		assert.True(t, signature.Verify(publicPoint, messageHash[:]), "expected valid signature")
+
+		rBytes, err := signature.R.XScalar().MarshalBinary()
+		require.NoError(t, err)
+		sBytes, err := signature.S.MarshalBinary()
+		require.NoError(t, err)
+		assert.True(t, stdecdsa.Verify(publicKey, messageHash[:], new(big.Int).SetBytes(rBytes), new(big.Int).SetBytes(sBytes)),
+			"crypto/ecdsa rejected the signature")
+	}
+}
+
+// TestSignBelowThreshold checks that fewer than threshold + 1 signers is an error of the StartFunc, and not a panic.
+func TestSignBelowThreshold(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	configs, partyIDs := test.GenerateConfig(curve.Secp256k1{}, 3, 2, mrand.New(mrand.NewSource(1)), pl)
+	messageHash := sha256.Sum256([]byte("hello"))
+	assert.NotPanics(t, func() {
+		_, err := StartSign(configs[partyIDs[0]], partyIDs[:2], messageHash[:], pl)(nil)
+		assert.EqualError(t, err, "sign.Create: need at least 3 signers, got 2")
+	})
+}
@@ -0,0 +1,217 @@
+package cmp
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/luxfi/threshold/pkg/ecdsa"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+)
+
+// ErrStoreEmpty is returned by PreSignatureStore.Take when no suitable PreSignature is left.
+var ErrStoreEmpty = errors.New("presignature store: empty")
+
+// preSignatureStoreVersion is the version of the file written by PreSignatureStore.Save.
+const preSignatureStoreVersion = 1
+
+// PreSignatureStore holds PreSignatures until they are used, grouped by the signers who generated them.
+//
+// A PreSignature is returned by Take at most once, even with concurrent callers,
+// and a PreSignature that was already taken or used can't be added again.
+// It is safe for concurrent use.
+type PreSignatureStore struct {
+	mu sync.Mutex
+	// bySigners maps the key of a signer set to the PreSignatures of these signers, oldest first.
+	bySigners map[string][]*ecdsa.PreSignature
+	// order holds the signer set keys in the order they were first added, so that Take is deterministic.
+	order []string
+	// seen holds the IDs of all PreSignatures ever added to the store.
+	seen map[string]struct{}
+}
+
+// NewPreSignatureStore returns an empty PreSignatureStore.
+func NewPreSignatureStore() *PreSignatureStore {
+	return &PreSignatureStore{
+		bySigners: make(map[string][]*ecdsa.PreSignature),
+		seen:      make(map[string]struct{}),
+	}
+}
+
+// signersKey returns the key of the sorted signer set, which can't be confused with another set.
+func signersKey(signers party.IDSlice) string {
+	ids := make([]string, len(signers))
+	for i, id := range signers {
+		ids[i] = string(id)
+	}
+	return strings.Join(ids, "\x00")
+}
+
+// Add stores preSignatures. Nothing is stored if one of them is invalid, already used,
+// or was added to the store before.
+func (s *PreSignatureStore) Add(preSignatures ...*ecdsa.PreSignature) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	batch := make(map[string]struct{}, len(preSignatures))
+	for i, p := range preSignatures {
+		if p == nil {
+			return fmt.Errorf("presignature store: presignature %d is nil", i)
+		}
+		if err := p.Validate(); err != nil {
+			return fmt.Errorf("presignature store: presignature %d: %w", i, err)
+		}
+		if p.Used() {
+			return fmt.Errorf("presignature store: presignature %d: %w", i, ecdsa.ErrPreSignatureUsed)
+		}
+		id := string(p.ID)
+		if _, ok := s.seen[id]; ok {
+			return fmt.Errorf("presignature store: presignature %d was already added", i)
+		}
+		if _, ok := batch[id]; ok {
+			return fmt.Errorf("presignature store: presignature %d is a duplicate", i)
+		}
+		batch[id] = struct{}{}
+	}
+
+	for _, p := range preSignatures {
+		s.seen[string(p.ID)] = struct{}{}
+		key := signersKey(p.SignerIDs())
+		if _, ok := s.bySigners[key]; !ok {
+			s.order = append(s.order, key)
+		}
+		s.bySigners[key] = append(s.bySigners[key], p)
+	}
+	return nil
+}
+
+// Take removes and returns the oldest PreSignature of the first signer set that has one left.
+// It returns ErrStoreEmpty if the store is empty.
+func (s *PreSignatureStore) Take() (*ecdsa.PreSignature, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range s.order {
+		if p := s.take(key); p != nil {
+			return p, nil
+		}
+	}
+	return nil, ErrStoreEmpty
+}
+
+// TakeFor is like Take, but only returns a PreSignature generated by exactly the given signers.
+func (s *PreSignatureStore) TakeFor(signers []party.ID) (*ecdsa.PreSignature, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p := s.take(signersKey(party.NewIDSlice(signers))); p != nil {
+		return p, nil
+	}
+	return nil, ErrStoreEmpty
+}
+
+// take removes and returns the oldest PreSignature stored under key, or nil if there is none.
+// s.mu must be held.
+func (s *PreSignatureStore) take(key string) *ecdsa.PreSignature {
+	stored := s.bySigners[key]
+	if len(stored) == 0 {
+		return nil
+	}
+	p := stored[0]
+	stored[0] = nil
+	s.bySigners[key] = stored[1:]
+	return p
+}
+
+// Len returns the number of PreSignatures left in the store.
+func (s *PreSignatureStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for _, stored := range s.bySigners {
+		n += len(stored)
+	}
+	return n
+}
+
+type preSignatureStoreFile struct {
+	Version       uint
+	PreSignatures [][]byte
+}
+
+// Save writes the PreSignatures left in the store to path, so that a warm store survives a restart.
+//
+// The file holds secret nonce shares, so it is only readable by its owner.
+// A saved file must be loaded at most once: two stores loaded from the same file hold the same
+// PreSignatures, and using both reuses their nonces.
+func (s *PreSignatureStore) Save(path string) error {
+	s.mu.Lock()
+	file := preSignatureStoreFile{Version: preSignatureStoreVersion}
+	for _, key := range s.order {
+		for _, p := range s.bySigners[key] {
+			data, err := p.MarshalBinary()
+			if err != nil {
+				s.mu.Unlock()
+				return fmt.Errorf("presignature store: %w", err)
+			}
+			file.PreSignatures = append(file.PreSignatures, data)
+		}
+	}
+	s.mu.Unlock()
+
+	data, err := cbor.Marshal(&file)
+	if err != nil {
+		return fmt.Errorf("presignature store: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("presignature store: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		return fmt.Errorf("presignature store: %w", err)
+	}
+	return nil
+}
+
+// LoadPreSignatureStore reads a store written by PreSignatureStore.Save, with PreSignatures over group.
+func LoadPreSignatureStore(path string, group curve.Curve) (*PreSignatureStore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("presignature store: %w", err)
+	}
+	var file preSignatureStoreFile
+	if err := cbor.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("presignature store: failed to decode %s: %w", path, err)
+	}
+	if file.Version == 0 || file.Version > preSignatureStoreVersion {
+		return nil, fmt.Errorf("presignature store: unsupported file version %d (supported: %d)", file.Version, preSignatureStoreVersion)
+	}
+	preSignatures := make([]*ecdsa.PreSignature, len(file.PreSignatures))
+	for i, data := range file.PreSignatures {
+		preSignatures[i] = ecdsa.EmptyPreSignature(group)
+		if err := preSignatures[i].UnmarshalBinary(data); err != nil {
+			return nil, fmt.Errorf("presignature store: presignature %d: %w", i, err)
+		}
+	}
+	s := NewPreSignatureStore()
+	if err := s.Add(preSignatures...); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
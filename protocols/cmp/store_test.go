@@ -0,0 +1,144 @@
+package cmp
+
+import (
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/luxfi/threshold/internal/types"
+	"github.com/luxfi/threshold/pkg/ecdsa"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/math/sample"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// randomPreSignature returns a PreSignature that passes Validate, without running the protocol.
+func randomPreSignature(t testing.TB, signers ...party.ID) *ecdsa.PreSignature {
+	group := curve.Secp256k1{}
+	id, err := types.NewRID(rand.Reader)
+	require.NoError(t, err)
+	rBar := make(map[party.ID]curve.Point, len(signers))
+	s := make(map[party.ID]curve.Point, len(signers))
+	for _, j := range signers {
+		rBar[j] = sample.Scalar(rand.Reader, group).ActOnBase()
+		s[j] = sample.Scalar(rand.Reader, group).ActOnBase()
+	}
+	return &ecdsa.PreSignature{
+		ID:       id,
+		R:        sample.Scalar(rand.Reader, group).ActOnBase(),
+		RBar:     party.NewPointMap(rBar),
+		S:        party.NewPointMap(s),
+		KShare:   sample.Scalar(rand.Reader, group),
+		ChiShare: sample.Scalar(rand.Reader, group),
+	}
+}
+
+func TestPreSignatureStoreConcurrentTake(t *testing.T) {
+	const count, workers = 200, 16
+	store := NewPreSignatureStore()
+	for i := 0; i < count; i++ {
+		require.NoError(t, store.Add(randomPreSignature(t, "a", "b")))
+	}
+	require.Equal(t, count, store.Len())
+
+	var (
+		mu    sync.Mutex
+		taken = make(map[*ecdsa.PreSignature]int)
+		empty int
+		wg    sync.WaitGroup
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				p, err := store.Take()
+				mu.Lock()
+				if err != nil {
+					empty++
+					mu.Unlock()
+					assert.ErrorIs(t, err, ErrStoreEmpty)
+					return
+				}
+				taken[p]++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, taken, count, "every presignature must be taken")
+	for _, n := range taken {
+		assert.Equal(t, 1, n, "a presignature was returned twice")
+	}
+	assert.Equal(t, workers, empty)
+	assert.Zero(t, store.Len())
+}
+
+func TestPreSignatureStoreAdd(t *testing.T) {
+	store := NewPreSignatureStore()
+	ab, abc := randomPreSignature(t, "a", "b"), randomPreSignature(t, "a", "b", "c")
+	require.NoError(t, store.Add(ab, abc))
+
+	assert.Error(t, store.Add(ab), "a presignature can't be added twice")
+	p := randomPreSignature(t, "a", "b")
+	assert.Error(t, store.Add(p, p), "duplicates in one call are rejected")
+	assert.Equal(t, 2, store.Len(), "a rejected call adds nothing")
+
+	used := randomPreSignature(t, "a", "b")
+	require.NoError(t, used.Consume())
+	assert.ErrorIs(t, store.Add(used), ecdsa.ErrPreSignatureUsed)
+
+	got, err := store.TakeFor([]party.ID{"c", "b", "a"})
+	require.NoError(t, err)
+	assert.Same(t, abc, got)
+	_, err = store.TakeFor([]party.ID{"a", "b", "c"})
+	assert.ErrorIs(t, err, ErrStoreEmpty)
+
+	// taken presignatures never come back
+	assert.Error(t, store.Add(abc))
+	got, err = store.Take()
+	require.NoError(t, err)
+	assert.Same(t, ab, got)
+	_, err = store.Take()
+	assert.ErrorIs(t, err, ErrStoreEmpty)
+}
+
+func TestPreSignatureStoreSave(t *testing.T) {
+	store := NewPreSignatureStore()
+	preSignatures := []*ecdsa.PreSignature{
+		randomPreSignature(t, "a", "b"),
+		randomPreSignature(t, "a", "b"),
+		randomPreSignature(t, "b", "c"),
+	}
+	require.NoError(t, store.Add(preSignatures...))
+	first, err := store.Take()
+	require.NoError(t, err)
+	assert.Same(t, preSignatures[0], first)
+
+	path := filepath.Join(t.TempDir(), "presignatures")
+	require.NoError(t, store.Save(path))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	loaded, err := LoadPreSignatureStore(path, curve.Secp256k1{})
+	require.NoError(t, err)
+	require.Equal(t, 2, loaded.Len(), "taken presignatures are not saved")
+	for _, want := range preSignatures[1:] {
+		got, err := loaded.TakeFor(want.SignerIDs())
+		require.NoError(t, err)
+		assert.Equal(t, want.ID, got.ID)
+		assert.True(t, want.R.Equal(got.R))
+		assert.True(t, want.KShare.Equal(got.KShare))
+		assert.True(t, want.ChiShare.Equal(got.ChiShare))
+	}
+
+	require.NoError(t, os.WriteFile(path, []byte{0xa1, 0x67, 'V', 'e', 'r', 's', 'i', 'o', 'n', 0x02}, 0o600))
+	_, err = LoadPreSignatureStore(path, curve.Secp256k1{})
+	assert.ErrorContains(t, err, "unsupported file version 2")
+}
@@ -25,6 +25,7 @@ func StartSignReceiver(config *keygen.ConfigReceiver, selfID, otherID party.ID,
 			PartyIDs:         party.NewIDSlice([]party.ID{selfID, otherID}),
 			Threshold:        1,
 			Group:            config.Group(),
+			RequireSessionID: true,
 		}
 
 		helper, err := round.NewSession(info, sessionID, nil)
@@ -51,6 +52,7 @@ func StartSignSender(config *keygen.ConfigSender, selfID, otherID party.ID, hash
 			PartyIDs:         party.NewIDSlice([]party.ID{selfID, otherID}),
 			Threshold:        1,
 			Group:            config.Group(),
+			RequireSessionID: true,
 		}
 
 		helper, err := round.NewSession(info, sessionID, nil)
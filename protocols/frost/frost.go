@@ -1,10 +1,15 @@
 package frost
 
 import (
+	"fmt"
+	"io"
+
 	"github.com/luxfi/threshold/internal/round"
 	"github.com/luxfi/threshold/pkg/math/curve"
 	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/pool"
 	"github.com/luxfi/threshold/pkg/protocol"
+	"github.com/luxfi/threshold/pkg/taproot"
 	"github.com/luxfi/threshold/protocols/frost/keygen"
 	"github.com/luxfi/threshold/protocols/frost/sign"
 )
@@ -13,6 +18,16 @@ type (
 	Config        = keygen.Config
 	TaprootConfig = keygen.TaprootConfig
 	Signature     = sign.Signature
+	Commitment    = sign.Commitment
+	Precommitment = sign.Precommitment
+	Ciphersuite   = sign.Ciphersuite
+)
+
+// The ciphersuites SignWithCiphersuite signs with.
+const (
+	CiphersuiteDefault         = sign.CiphersuiteDefault
+	CiphersuiteSecp256k1SHA256 = sign.CiphersuiteSecp256k1SHA256
+	CiphersuiteEd25519SHA512   = sign.CiphersuiteEd25519SHA512
 )
 
 // EmptyConfig creates an empty Config with a specific group.
@@ -27,6 +42,23 @@ func EmptyConfig(group curve.Curve) *Config {
 	}
 }
 
+// VerifyConfig checks that a config is consistent before it is used to sign: the threshold is valid,
+// the verification shares interpolate to the public key, and the private share matches its verification share.
+func VerifyConfig(config *Config) error {
+	return config.Validate()
+}
+
+// VerifyAll verifies the signatures output by each signer of one signing session, and returns
+// the indices of those which don't verify or differ from the others, see sign.VerifyAll.
+func VerifyAll(sigs []Signature, public curve.Point, m []byte) (bool, []int) {
+	return sign.VerifyAll(sigs, public, m)
+}
+
+// EmptySignature creates an empty Signature with a specific group, ready to be unmarshalled.
+func EmptySignature(group curve.Curve) Signature {
+	return sign.EmptySignature(group)
+}
+
 // Keygen initiates the Frost key generation protocol.
 //
 // This protocol establishes a new threshold signature key among a set of participants.
@@ -46,18 +78,56 @@ func EmptyConfig(group curve.Curve) *Config {
 //
 //	https://eprint.iacr.org/2020/852.pdf
 func Keygen(group curve.Curve, selfID party.ID, participants []party.ID, threshold int) protocol.StartFunc {
+	if err := validateKeygen(selfID, participants, threshold); err != nil {
+		return func([]byte) (round.Session, error) {
+			return nil, err
+		}
+	}
 	return keygen.StartKeygenCommon(false, group, participants, threshold, selfID, nil, nil, nil)
 }
 
+// KeygenWithRand is like Keygen, but draws all randomness of the party from rand.
+//
+// It is for reproducible tests and fuzzing only: a predictable rand gives away the key share,
+// so production code must always use Keygen.
+func KeygenWithRand(group curve.Curve, selfID party.ID, participants []party.ID, threshold int, rand io.Reader) protocol.StartFunc {
+	if err := validateKeygen(selfID, participants, threshold); err != nil {
+		return func([]byte) (round.Session, error) {
+			return nil, err
+		}
+	}
+	return keygen.StartKeygenWithRand(false, group, participants, threshold, selfID, rand)
+}
+
 // KeygenTaproot is like Keygen, but will make Taproot / BIP-340 compatible keys.
 //
 // This will also return TaprootResult instead of Result, at the end of the protocol.
 //
 // See: https://github.com/bitcoin/bips/blob/master/bip-0340.mediawiki#specification
 func KeygenTaproot(selfID party.ID, participants []party.ID, threshold int) protocol.StartFunc {
+	if err := validateKeygen(selfID, participants, threshold); err != nil {
+		return func([]byte) (round.Session, error) {
+			return nil, err
+		}
+	}
 	return keygen.StartKeygenCommon(true, curve.Secp256k1{}, participants, threshold, selfID, nil, nil, nil)
 }
 
+// validateKeygen checks the arguments of the keygen functions, so that they fail before any round runs.
+// A key of threshold t needs t+1 signers, so t must be below the number of parties.
+func validateKeygen(selfID party.ID, participants []party.ID, threshold int) error {
+	if err := round.ValidateParties(selfID, participants); err != nil {
+		return fmt.Errorf("frost: %w", err)
+	}
+	if threshold < 0 {
+		return fmt.Errorf("frost: threshold %d must not be negative", threshold)
+	}
+	if threshold >= len(participants) {
+		return fmt.Errorf("frost: threshold %d needs %d signers, which exceeds parties %d", threshold, threshold+1, len(participants))
+	}
+	return nil
+}
+
 // Refresh
 func Refresh(config *Config, participants []party.ID) protocol.StartFunc {
 	return keygen.StartKeygenCommon(false, config.Curve(), participants, config.Threshold, config.ID, config.PrivateShare, config.PublicKey, config.VerificationShares.Points)
@@ -69,7 +139,7 @@ func Refresh(config *Config, participants []party.ID) protocol.StartFunc {
 //
 // See: https://github.com/bitcoin/bips/blob/master/bip-0340.mediawiki#specification
 func RefreshTaproot(config *TaprootConfig, participants []party.ID) protocol.StartFunc {
-	publicKey, err := curve.Secp256k1{}.LiftX(config.PublicKey)
+	publicKey, err := curve.Secp256k1{}.LiftX(config.PublicKey, false)
 	if err != nil {
 		return func([]byte) (round.Session, error) {
 			return nil, err
@@ -102,17 +172,73 @@ func RefreshTaproot(config *TaprootConfig, participants []party.ID) protocol.Sta
 // Instead, each participant independently verifies and broadcasts items as necessary.
 //
 // Differences stemming from this change are commented throughout the protocol.
+//
+// The SSID of a signing binds the signers and messageHash, but not a nonce of its own, since all signers must
+// derive the same one. Concurrent signings of the same message over one network must be started with
+// different session IDs, see protocol.NewSessionID.
 func Sign(config *Config, signers []party.ID, messageHash []byte) protocol.StartFunc {
 	return sign.StartSignCommon(false, config, signers, messageHash)
 }
 
+// SignDigest is like Sign, for a digest the caller already computed with the hash its chain prescribes.
+// The digest is the message of the Schnorr challenge, as is, like the 32 byte messages of BIP-340.
+func SignDigest(config *Config, signers []party.ID, digest [32]byte) protocol.StartFunc {
+	return Sign(config, signers, digest[:])
+}
+
+// SignWithCiphersuite is like Sign, with the nonces, binding factors and challenge of an RFC 9591 ciphersuite,
+// so that the signers can sign along with other implementations of the RFC.
+//
+// The key must be on the curve of the ciphersuite, and message is signed as is, without hashing it first.
+// The resulting signature is checked with Signature.VerifyCiphersuite.
+func SignWithCiphersuite(config *Config, signers []party.ID, message []byte, suite Ciphersuite) protocol.StartFunc {
+	return sign.StartSignCiphersuite(suite, config, signers, message)
+}
+
+// CommitBatch precomputes n single-use nonce commitments, for later use with SignBatch.
+//
+// This corresponds to the pre-processing step in Figure 2 of the Frost paper.
+// Each commitment can sign a single message: reusing the nonces of a commitment
+// for a second message reveals the private share, so SignBatch will reject it.
+func CommitBatch(config *Config, n int, pl *pool.Pool) ([]*Commitment, error) {
+	return sign.NewCommitments(config, n, pl)
+}
+
+// SignBatch is like Sign, but signs several message hashes in a single protocol execution.
+//
+// commitments[i] is consumed to sign messages[i], so both slices must have the same length,
+// and each signer must use commitments obtained from its own call to CommitBatch.
+// The result of the protocol is a []Signature, in the same order as messages.
+func SignBatch(config *Config, signers []party.ID, messages [][]byte, commitments []*Commitment) protocol.StartFunc {
+	return sign.StartSignBatch(config, signers, messages, commitments)
+}
+
+// Precommit runs the first round of Sign ahead of time: signers generate the nonces of a future signature,
+// and exchange their commitments. The result of the protocol is a *Precommitment, for SignOnline.
+//
+// This is the counterpart for Frost of the presignatures of CMP, and the pre-processing step of Figure 2
+// of the Frost paper, with the commitments published to the other signers instead of a "Signing Authority".
+func Precommit(config *Config, signers []party.ID) protocol.StartFunc {
+	return sign.StartPrecommit(config, signers)
+}
+
+// SignOnline signs messageHash with a *Precommitment from Precommit, in a single round of communication.
+// The result of the protocol is a Signature, as with Sign.
+//
+// The signers are those of the Precommit session, which must all take part.
+// A precommitment signs a single message: reusing its nonces for a second message reveals
+// the private share, so SignOnline rejects a precommitment which was already used.
+func SignOnline(config *Config, precommit *Precommitment, messageHash []byte) protocol.StartFunc {
+	return sign.StartSignOnline(config, precommit, messageHash)
+}
+
 // SignTaproot is like Sign, but will generate a Taproot / BIP-340 compatible signature.
 //
 // This needs to result of a Taproot compatible key generation phase, naturally.
 //
 // See: https://github.com/bitcoin/bips/blob/master/bip-0340.mediawiki
 func SignTaproot(config *TaprootConfig, signers []party.ID, messageHash []byte) protocol.StartFunc {
-	publicKey, err := curve.Secp256k1{}.LiftX(config.PublicKey)
+	publicKey, err := curve.Secp256k1{}.LiftX(config.PublicKey, false)
 	if err != nil {
 		return func([]byte) (round.Session, error) {
 			return nil, err
@@ -131,3 +257,26 @@ func SignTaproot(config *TaprootConfig, signers []party.ID, messageHash []byte)
 	}
 	return sign.StartSignCommon(true, normalResult, signers, messageHash)
 }
+
+// SignTaprootKeySpend is like SignTaproot, but signs a BIP-341 key path spend with a key from Keygen on curve.Secp256k1.
+//
+// The public key of config is used as the internal key, and is tweaked with merkleRoot, which
+// is empty for an output without a script tree. The resulting signature verifies against the
+// output key returned by TaprootOutputKey.
+//
+// See: https://github.com/bitcoin/bips/blob/master/bip-0341.mediawiki
+func SignTaprootKeySpend(config *Config, signers []party.ID, messageHash, merkleRoot []byte) protocol.StartFunc {
+	output, err := config.TaprootKeySpend(merkleRoot)
+	if err != nil {
+		return func([]byte) (round.Session, error) {
+			return nil, err
+		}
+	}
+	return sign.StartSignCommon(true, output, signers, messageHash)
+}
+
+// TaprootOutputKey returns the BIP-341 output key for the internal key publicKey and merkleRoot,
+// which is the key that signatures from SignTaprootKeySpend verify against.
+func TaprootOutputKey(publicKey curve.Point, merkleRoot []byte) (taproot.PublicKey, error) {
+	return keygen.TaprootOutputKey(publicKey, merkleRoot)
+}
@@ -2,12 +2,18 @@ package frost
 
 import (
 	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
 	"fmt"
 	"sync"
 	"testing"
 
+	"github.com/luxfi/threshold/internal/round"
 	"github.com/luxfi/threshold/internal/test"
 	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/math/polynomial"
+	"github.com/luxfi/threshold/pkg/math/sample"
 	"github.com/luxfi/threshold/pkg/party"
 	"github.com/luxfi/threshold/pkg/protocol"
 	"github.com/luxfi/threshold/pkg/taproot"
@@ -15,7 +21,7 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func do(t *testing.T, id party.ID, ids []party.ID, threshold int, message []byte, n *test.Network, wg *sync.WaitGroup) {
+func do(t *testing.T, id party.ID, ids []party.ID, threshold int, message, sessionID []byte, n *test.Network, wg *sync.WaitGroup) {
 	defer wg.Done()
 	h, err := protocol.NewMultiHandler(Keygen(curve.Secp256k1{}, id, ids, threshold), nil)
 	require.NoError(t, err)
@@ -55,7 +61,7 @@ func do(t *testing.T, id party.ID, ids []party.ID, threshold int, message []byte
 	cTaproot := r.(*TaprootConfig)
 	require.True(t, bytes.Equal(c0Taproot.PublicKey, cTaproot.PublicKey))
 
-	h, err = protocol.NewMultiHandler(Sign(c, ids, message), nil)
+	h, err = protocol.NewMultiHandler(Sign(c, ids, message), sessionID)
 	require.NoError(t, err)
 	test.HandlerLoop(c.ID, h, n)
 
@@ -65,7 +71,7 @@ func do(t *testing.T, id party.ID, ids []party.ID, threshold int, message []byte
 	signature := signResult.(Signature)
 	assert.True(t, signature.Verify(c.PublicKey, message))
 
-	h, err = protocol.NewMultiHandler(SignTaproot(cTaproot, ids, message), nil)
+	h, err = protocol.NewMultiHandler(SignTaproot(cTaproot, ids, message), sessionID)
 	require.NoError(t, err)
 
 	test.HandlerLoop(c.ID, h, n)
@@ -84,13 +90,144 @@ func TestFrost(t *testing.T) {
 
 	partyIDs := test.PartyIDs(N)
 	fmt.Println(partyIDs)
+	sessionID, err := protocol.NewSessionID()
+	require.NoError(t, err)
 
 	n := test.NewNetwork(partyIDs)
 
 	var wg sync.WaitGroup
 	wg.Add(N)
 	for _, id := range partyIDs {
-		go do(t, id, partyIDs, T, message, n, &wg)
+		go do(t, id, partyIDs, T, message, sessionID, n, &wg)
 	}
 	wg.Wait()
 }
+
+func TestSignTaprootKeySpend(t *testing.T) {
+	group := curve.Secp256k1{}
+	N, threshold := 3, 1
+	partyIDs := test.PartyIDs(N)
+	message := sha256.Sum256([]byte("spend"))
+	merkleRoot := sha256.Sum256([]byte("script tree"))
+
+	// run with several keys, so that both parities of the internal and output keys are covered
+	for i := 0; i < 4; i++ {
+		secret := sample.Scalar(rand.Reader, group)
+		f := polynomial.NewPolynomial(group, threshold, secret)
+		verificationShares := make(map[party.ID]curve.Point, N)
+		configs := make(map[party.ID]*Config, N)
+		for _, id := range partyIDs {
+			share := f.Evaluate(id.Scalar(group))
+			verificationShares[id] = share.ActOnBase()
+			configs[id] = &Config{ID: id, Threshold: threshold, PrivateShare: share, PublicKey: secret.ActOnBase()}
+		}
+		for _, c := range configs {
+			c.VerificationShares = party.NewPointMap(verificationShares)
+		}
+
+		for _, root := range [][]byte{nil, merkleRoot[:]} {
+			// compute the output key from the secret key, following BIP-341
+			d := group.NewScalar().Set(secret)
+			if !d.ActOnBase().(*curve.Secp256k1Point).HasEvenY() {
+				d.Negate()
+			}
+			tweak := group.NewScalar()
+			require.NoError(t, tweak.UnmarshalBinary(taproot.TaggedHash("TapTweak", d.ActOnBase().(*curve.Secp256k1Point).XBytes(), root)))
+			want := taproot.PublicKey(d.Add(tweak).ActOnBase().(*curve.Secp256k1Point).XBytes())
+
+			outputKey, err := TaprootOutputKey(secret.ActOnBase(), root)
+			require.NoError(t, err)
+			require.Equal(t, want, outputKey)
+
+			rounds := make([]round.Session, 0, N)
+			for _, id := range partyIDs {
+				r, err := SignTaprootKeySpend(configs[id], partyIDs, message[:], root)(nil)
+				require.NoError(t, err)
+				rounds = append(rounds, r)
+			}
+			for {
+				err, done := test.Rounds(rounds, nil)
+				require.NoError(t, err)
+				if done {
+					break
+				}
+			}
+			for _, r := range rounds {
+				require.IsType(t, &round.Output{}, r)
+				require.IsType(t, taproot.Signature{}, r.(*round.Output).Result)
+				sig := r.(*round.Output).Result.(taproot.Signature)
+				assert.True(t, outputKey.Verify(sig, message[:]), "signature must verify against the output key")
+				internalKey := taproot.PublicKey(secret.ActOnBase().(*curve.Secp256k1Point).XBytes())
+				assert.False(t, internalKey.Verify(sig, message[:]), "signature must not verify against the internal key")
+			}
+		}
+	}
+
+	_, err := TaprootOutputKey(group.NewBasePoint(), []byte{1, 2, 3})
+	assert.Error(t, err, "merkle roots are 32 bytes")
+	_, err = TaprootOutputKey(curve.P256{}.NewBasePoint(), nil)
+	assert.Error(t, err, "taproot requires secp256k1")
+}
+
+func TestKeygenInvalidArguments(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(3)
+
+	tests := []struct {
+		name      string
+		selfID    party.ID
+		partyIDs  []party.ID
+		threshold int
+		err       string
+	}{
+		{"threshold above parties", partyIDs[0], partyIDs, 5, "threshold 5 needs 6 signers, which exceeds parties 3"},
+		{"threshold of all parties", partyIDs[0], partyIDs, 3, "exceeds parties 3"},
+		{"negative threshold", partyIDs[0], partyIDs, -1, "must not be negative"},
+		{"no self", "z", partyIDs, 1, "self z is not among the parties"},
+		{"duplicate party", partyIDs[0], append(partyIDs.Copy(), partyIDs[1]), 1, "party " + string(partyIDs[1]) + " is listed more than once"},
+		{"no parties", partyIDs[0], nil, 0, "no parties"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := protocol.NewMultiHandler(Keygen(group, tt.selfID, tt.partyIDs, tt.threshold), nil)
+			assert.ErrorContains(t, err, tt.err)
+			_, err = protocol.NewMultiHandler(KeygenTaproot(tt.selfID, tt.partyIDs, tt.threshold), nil)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestConcurrentSigningSessions(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	threshold := 1
+
+	rounds := make([]round.Session, 0, len(partyIDs))
+	for _, id := range partyIDs {
+		r, err := Keygen(curve.Secp256k1{}, id, partyIDs, threshold)(nil)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+	for done := false; !done; {
+		var err error
+		err, done = test.Rounds(rounds, nil)
+		require.NoError(t, err)
+	}
+	configs := make(map[party.ID]*Config, len(partyIDs))
+	for _, r := range rounds {
+		configs[r.SelfID()] = r.(*round.Output).Result.(*Config)
+	}
+
+	publicKey := configs[partyIDs[0]].PublicKey
+
+	signers := partyIDs[1:]
+	err := test.RunSigningSessions(signers, 20, func(message []byte, id party.ID) protocol.StartFunc {
+		return Sign(configs[id], signers, message)
+	}, func(message []byte, result interface{}) ([]byte, error) {
+		sig := result.(Signature)
+		if !sig.Verify(publicKey, message) {
+			return nil, errors.New("invalid signature")
+		}
+		return sig.MarshalBinary()
+	})
+	require.NoError(t, err)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/luxfi/threshold/internal/bip32"
 	"github.com/luxfi/threshold/internal/params"
 	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/math/polynomial"
 	"github.com/luxfi/threshold/pkg/party"
 	"github.com/luxfi/threshold/pkg/taproot"
 )
@@ -35,6 +36,8 @@ type Config struct {
 	//
 	// This will later be used to verify the integrity of the signing protocol.
 	VerificationShares *party.PointMap
+	// Generation counts the resharings this key has gone through, starting at 0 after keygen.
+	Generation uint64
 }
 
 // EmptyConfig creates an empty Result with a specific group.
@@ -54,6 +57,61 @@ func (r *Config) Curve() curve.Curve {
 	return r.PublicKey.Curve()
 }
 
+// KeyID returns the fingerprint of the public key, see curve.KeyID.
+func (r *Config) KeyID() string {
+	return curve.KeyID(r.PublicKey)
+}
+
+// Validate checks that the config is consistent: the threshold is valid for the number of parties,
+// the verification shares lie on a polynomial of degree Threshold whose constant is PublicKey,
+// and this participant's private share matches its verification share.
+func (r *Config) Validate() error {
+	if r.PrivateShare == nil || r.PublicKey == nil || r.VerificationShares == nil {
+		return errors.New("frost: config is incomplete")
+	}
+	shares := r.VerificationShares.Points
+	if r.Threshold < 0 || r.Threshold >= len(shares) {
+		return fmt.Errorf("frost: threshold %d is invalid for %d parties", r.Threshold, len(shares))
+	}
+	self, ok := shares[r.ID]
+	if !ok {
+		return fmt.Errorf("frost: no verification share for %s", r.ID)
+	}
+	if !r.PrivateShare.ActOnBase().Equal(self) {
+		return errors.New("frost: private share does not match its verification share")
+	}
+	publicKey, err := polynomial.InterpolatePoints(r.Curve(), shares, r.Threshold)
+	if err != nil {
+		return fmt.Errorf("frost: verification shares: %w", err)
+	}
+	if !publicKey.Equal(r.PublicKey) {
+		return errors.New("frost: verification shares don't interpolate to the public key")
+	}
+	return nil
+}
+
+// VerifyPublicShare checks that the verification share of id lies on the polynomial of degree Threshold
+// whose constant is PublicKey, and on which the verification shares of the other parties lie.
+//
+// It only uses public data, so that anyone holding a published keyset can check it.
+func (r *Config) VerifyPublicShare(id party.ID) error {
+	if r.PublicKey == nil || r.VerificationShares == nil {
+		return errors.New("frost: config is incomplete")
+	}
+	if err := polynomial.VerifyPoint(r.Curve(), r.VerificationShares.Points, r.Threshold, r.PublicKey, id); err != nil {
+		return fmt.Errorf("frost: verification share of %s: %w", id, err)
+	}
+	return nil
+}
+
+// Zeroize overwrites the private share of this config with 0, once it has been superseded by a reshare or a refresh,
+// so that it doesn't remain in memory. The config can't be used to sign afterwards.
+func (r *Config) Zeroize() {
+	if r.PrivateShare != nil {
+		r.PrivateShare.Zero()
+	}
+}
+
 // Derive performs an arbitrary derivation of a related key, by adding a scalar.
 //
 // This can support methods like BIP32, but is more general.
@@ -80,6 +138,7 @@ func (r *Config) Derive(adjust curve.Scalar, newChainKey []byte) (*Config, error
 		PublicKey:          r.PublicKey.Add(adjustG),
 		ChainKey:           newChainKey,
 		VerificationShares: party.NewPointMap(verificationShares),
+		Generation:         r.Generation,
 	}, nil
 }
 
@@ -101,6 +160,101 @@ func (r *Config) DeriveChild(i uint32) (*Config, error) {
 	return r.Derive(scalar, newChainKey)
 }
 
+// TaprootKeySpend returns the config for the BIP-341 output key committing to this key and merkleRoot,
+// for signing key path spends with the taproot variant of the signing protocol.
+//
+// The public key of this config is used as the internal key, after negating it if its y coordinate is odd.
+// An empty merkleRoot commits to no script tree, otherwise it must be 32 bytes.
+// The returned config's public key, the output key, always has an even y coordinate.
+//
+// See: https://github.com/bitcoin/bips/blob/master/bip-0341.mediawiki#constructing-and-spending-taproot-outputs
+func (r *Config) TaprootKeySpend(merkleRoot []byte) (*Config, error) {
+	publicKey, ok := r.PublicKey.(*curve.Secp256k1Point)
+	if !ok {
+		return nil, errors.New("taproot requires a secp256k1 key")
+	}
+	internal := r
+	if !publicKey.HasEvenY() {
+		internal = r.negate()
+	}
+	tweak, err := taprootTweak(internal.PublicKey.(*curve.Secp256k1Point), merkleRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	tweakG := tweak.ActOnBase()
+	verificationShares := make(map[party.ID]curve.Point, len(internal.VerificationShares.Points))
+	for k, v := range internal.VerificationShares.Points {
+		verificationShares[k] = v.Add(tweakG)
+	}
+	output := &Config{
+		ID:                 r.ID,
+		Threshold:          r.Threshold,
+		PrivateShare:       curve.Secp256k1{}.NewScalar().Set(internal.PrivateShare).Add(tweak),
+		PublicKey:          internal.PublicKey.Add(tweakG),
+		ChainKey:           r.ChainKey,
+		VerificationShares: party.NewPointMap(verificationShares),
+		Generation:         r.Generation,
+	}
+	if output.PublicKey.IsIdentity() {
+		return nil, errors.New("taproot output key is the identity")
+	}
+	if !output.PublicKey.(*curve.Secp256k1Point).HasEvenY() {
+		output = output.negate()
+	}
+	return output, nil
+}
+
+// TaprootOutputKey returns the BIP-341 output key for the internal key publicKey and merkleRoot,
+// which is the public key of the config returned by TaprootKeySpend.
+func TaprootOutputKey(publicKey curve.Point, merkleRoot []byte) (taproot.PublicKey, error) {
+	P, ok := publicKey.(*curve.Secp256k1Point)
+	if !ok {
+		return nil, errors.New("taproot requires a secp256k1 key")
+	}
+	if !P.HasEvenY() {
+		P = P.Negate().(*curve.Secp256k1Point)
+	}
+	tweak, err := taprootTweak(P, merkleRoot)
+	if err != nil {
+		return nil, err
+	}
+	Q := P.Add(tweak.ActOnBase()).(*curve.Secp256k1Point)
+	if Q.IsIdentity() {
+		return nil, errors.New("taproot output key is the identity")
+	}
+	return taproot.PublicKey(Q.XBytes()), nil
+}
+
+// taprootTweak returns the BIP-341 tweak of the internal key P, which must have an even y coordinate.
+func taprootTweak(P *curve.Secp256k1Point, merkleRoot []byte) (curve.Scalar, error) {
+	if len(merkleRoot) != 0 && len(merkleRoot) != 32 {
+		return nil, fmt.Errorf("taproot merkle root must be 32 bytes, got %d", len(merkleRoot))
+	}
+	tweak := curve.Secp256k1{}.NewScalar()
+	if err := tweak.UnmarshalBinary(taproot.TaggedHash("TapTweak", P.XBytes(), merkleRoot)); err != nil {
+		return nil, fmt.Errorf("invalid taproot tweak: %w", err)
+	}
+	return tweak, nil
+}
+
+// negate returns a copy of r with all shares negated, which holds the negated public key.
+func (r *Config) negate() *Config {
+	verificationShares := make(map[party.ID]curve.Point, len(r.VerificationShares.Points))
+	for k, v := range r.VerificationShares.Points {
+		verificationShares[k] = v.Negate()
+	}
+	return &Config{
+		ID:                 r.ID,
+		Threshold:          r.Threshold,
+		PrivateShare:       r.PrivateShare.Curve().NewScalar().Set(r.PrivateShare).Negate(),
+		PublicKey:          r.PublicKey.Negate(),
+		ChainKey:           r.ChainKey,
+		VerificationShares: party.NewPointMap(verificationShares),
+		Generation:         r.Generation,
+	}
+}
+
 // TaprootConfig is like result, but for Taproot / BIP-340 keys.
 //
 // The main difference is that our public key is an actual taproot public key.
@@ -166,7 +320,7 @@ func (r *TaprootConfig) Derive(adjust *curve.Secp256k1Scalar, newChainKey []byte
 
 	privateShare := curve.Secp256k1{}.NewScalar().Set(r.PrivateShare).Add(adjust)
 
-	publicKey, err := curve.Secp256k1{}.LiftX(r.PublicKey)
+	publicKey, err := curve.Secp256k1{}.LiftX(r.PublicKey, false)
 	if err != nil {
 		return nil, err
 	}
@@ -200,7 +354,7 @@ func (r *TaprootConfig) Derive(adjust *curve.Secp256k1Scalar, newChainKey []byte
 // coordinate of the derived public key, making sure that the corresponding secret
 // key matches the version of this point with an even y coordinate.
 func (r *TaprootConfig) DeriveChild(i uint32) (*TaprootConfig, error) {
-	publicKey, err := curve.Secp256k1{}.LiftX(r.PublicKey)
+	publicKey, err := curve.Secp256k1{}.LiftX(r.PublicKey, false)
 	if err != nil {
 		return nil, err
 	}
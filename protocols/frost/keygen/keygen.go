@@ -1,7 +1,9 @@
 package keygen
 
 import (
+	"crypto/rand"
 	"fmt"
+	"io"
 
 	"github.com/luxfi/threshold/internal/round"
 	"github.com/luxfi/threshold/pkg/math/curve"
@@ -13,8 +15,8 @@ const (
 	// Frost KeyGen with Threshold.
 	protocolID        = "frost/keygen-threshold"
 	protocolIDTaproot = "frost/keygen-threshold-taproot"
-	// This protocol has 3 concrete rounds.
-	protocolRounds round.Number = 3
+	// This protocol has 3 concrete rounds, and 2 more to resolve complaints about invalid shares.
+	protocolRounds round.Number = 5
 )
 
 // These assert that our rounds implement the round.Round interface.
@@ -22,9 +24,23 @@ var (
 	_ round.Round = (*round1)(nil)
 	_ round.Round = (*round2)(nil)
 	_ round.Round = (*round3)(nil)
+	_ round.Round = (*round4)(nil)
+	_ round.Round = (*round5)(nil)
 )
 
 func StartKeygenCommon(taproot bool, group curve.Curve, participants []party.ID, threshold int, selfID party.ID, privateShare curve.Scalar, publicKey curve.Point, verificationShares map[party.ID]curve.Point) protocol.StartFunc {
+	return startKeygen(taproot, group, participants, threshold, selfID, privateShare, publicKey, verificationShares, rand.Reader)
+}
+
+// StartKeygenWithRand starts a key generation like StartKeygenCommon, drawing all randomness of the party from rand,
+// so that the same reader always gives the same share.
+//
+// It is only meant for reproducible tests: anyone able to predict rand learns the share.
+func StartKeygenWithRand(taproot bool, group curve.Curve, participants []party.ID, threshold int, selfID party.ID, rand io.Reader) protocol.StartFunc {
+	return startKeygen(taproot, group, participants, threshold, selfID, nil, nil, nil, rand)
+}
+
+func startKeygen(taproot bool, group curve.Curve, participants []party.ID, threshold int, selfID party.ID, privateShare curve.Scalar, publicKey curve.Point, verificationShares map[party.ID]curve.Point, rand io.Reader) protocol.StartFunc {
 	return func(sessionID []byte) (round.Session, error) {
 		info := round.Info{
 			FinalRoundNumber: protocolRounds,
@@ -50,6 +66,10 @@ func StartKeygenCommon(taproot bool, group curve.Curve, participants []party.ID,
 		}
 
 		refresh := true
+		if privateShare != nil {
+			// the rounds add to the share in place, and the config it belongs to must keep the old one
+			privateShare = group.NewScalar().Set(privateShare)
+		}
 		if privateShare == nil || publicKey == nil {
 			refresh = false
 			privateShare = group.NewScalar()
@@ -61,6 +81,7 @@ func StartKeygenCommon(taproot bool, group curve.Curve, participants []party.ID,
 
 		return &round1{
 			Helper:             helper,
+			rand:               rand,
 			taproot:            taproot,
 			threshold:          threshold,
 			refresh:            refresh,
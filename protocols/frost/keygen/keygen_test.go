@@ -1,6 +1,9 @@
 package keygen
 
 import (
+	"crypto/rand"
+	"encoding/json"
+	mrand "math/rand"
 	"testing"
 
 	"github.com/fxamacker/cbor/v2"
@@ -8,6 +11,7 @@ import (
 	"github.com/luxfi/threshold/internal/test"
 	"github.com/luxfi/threshold/pkg/math/curve"
 	"github.com/luxfi/threshold/pkg/math/polynomial"
+	"github.com/luxfi/threshold/pkg/math/sample"
 	"github.com/luxfi/threshold/pkg/party"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -53,6 +57,7 @@ func checkOutput(t *testing.T, rounds []round.Session, parties party.IDSlice) {
 	}
 
 	for _, result := range results {
+		require.NoError(t, result.Validate())
 		for _, id := range parties {
 			expected := shares[id].ActOnBase()
 			require.True(t, result.VerificationShares.Points[id].Equal(expected), "different verification shares", id)
@@ -92,6 +97,57 @@ func TestKeygen(t *testing.T) {
 	checkOutput(t, rounds, partyIDs)
 }
 
+func TestConfigValidate(t *testing.T) {
+	group := curve.Ed25519{}
+	partyIDs := test.PartyIDs(4)
+	threshold := 2
+
+	secret := sample.Scalar(rand.Reader, group)
+	f := polynomial.NewPolynomial(group, threshold, secret)
+	privateShares := make(map[party.ID]curve.Scalar, len(partyIDs))
+	verificationShares := make(map[party.ID]curve.Point, len(partyIDs))
+	for _, id := range partyIDs {
+		privateShares[id] = f.Evaluate(id.Scalar(group))
+		verificationShares[id] = privateShares[id].ActOnBase()
+	}
+	newConfig := func(id party.ID) *Config {
+		points := make(map[party.ID]curve.Point, len(verificationShares))
+		for j, p := range verificationShares {
+			points[j] = p
+		}
+		return &Config{
+			ID:                 id,
+			Threshold:          threshold,
+			PrivateShare:       privateShares[id],
+			PublicKey:          secret.ActOnBase(),
+			VerificationShares: party.NewPointMap(points),
+		}
+	}
+	for _, id := range partyIDs {
+		require.NoError(t, newConfig(id).Validate())
+	}
+
+	c := newConfig(partyIDs[0])
+	c.VerificationShares.Points[partyIDs[3]] = sample.Scalar(rand.Reader, group).ActOnBase()
+	assert.ErrorContains(t, c.Validate(), "not on the polynomial", "a tampered verification share must be rejected")
+
+	c = newConfig(partyIDs[0])
+	c.VerificationShares.Points[partyIDs[0]] = sample.Scalar(rand.Reader, group).ActOnBase()
+	assert.ErrorContains(t, c.Validate(), "private share does not match")
+
+	c = newConfig(partyIDs[0])
+	c.PublicKey = sample.Scalar(rand.Reader, group).ActOnBase()
+	assert.ErrorContains(t, c.Validate(), "public key")
+
+	c = newConfig(partyIDs[0])
+	c.Threshold = len(partyIDs)
+	assert.ErrorContains(t, c.Validate(), "threshold")
+
+	c = newConfig("outsider")
+	c.PrivateShare = sample.Scalar(rand.Reader, group)
+	assert.ErrorContains(t, c.Validate(), "no verification share")
+}
+
 func checkOutputTaproot(t *testing.T, rounds []round.Session, parties party.IDSlice) {
 	group := curve.Secp256k1{}
 
@@ -121,7 +177,7 @@ func checkOutputTaproot(t *testing.T, rounds []round.Session, parties party.IDSl
 		chainKey = result.ChainKey
 		privateKey.Add(group.NewScalar().Set(lagrangeCoefficients[result.ID]).Mul(result.PrivateShare))
 	}
-	effectivePublic, err := curve.Secp256k1{}.LiftX(publicKey)
+	effectivePublic, err := curve.Secp256k1{}.LiftX(publicKey, false)
 	require.NoError(t, err)
 
 	actualPublicKey := privateKey.ActOnBase()
@@ -164,3 +220,215 @@ func TestKeygenTaproot(t *testing.T) {
 
 	checkOutputTaproot(t, rounds, partyIDs)
 }
+
+func TestConfigJSON(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(3)
+	secret := sample.Scalar(rand.Reader, group)
+	f := polynomial.NewPolynomial(group, 1, secret)
+	verificationShares := make(map[party.ID]curve.Point, len(partyIDs))
+	for _, id := range partyIDs {
+		verificationShares[id] = f.Evaluate(id.Scalar(group)).ActOnBase()
+	}
+	chainKey := make([]byte, 32)
+	_, _ = rand.Read(chainKey)
+	c := &Config{
+		ID:                 partyIDs[0],
+		Threshold:          1,
+		PrivateShare:       f.Evaluate(partyIDs[0].Scalar(group)),
+		PublicKey:          secret.ActOnBase(),
+		ChainKey:           chainKey,
+		VerificationShares: party.NewPointMap(verificationShares),
+		Generation:         3,
+	}
+
+	data, err := json.Marshal(c)
+	require.NoError(t, err)
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	assert.EqualValues(t, JSONVersion, doc["version"])
+
+	decoded := EmptyConfig(group)
+	require.NoError(t, json.Unmarshal(data, decoded))
+	assert.Equal(t, c.ID, decoded.ID)
+	assert.Equal(t, c.Threshold, decoded.Threshold)
+	assert.Equal(t, c.Generation, decoded.Generation)
+	assert.Equal(t, c.ChainKey, decoded.ChainKey)
+	assert.True(t, c.PrivateShare.Equal(decoded.PrivateShare))
+	assert.True(t, c.PublicKey.Equal(decoded.PublicKey))
+	for id, share := range c.VerificationShares.Points {
+		assert.True(t, share.Equal(decoded.VerificationShares.Points[id]))
+	}
+
+	doc["version"] = 999
+	future, err := json.Marshal(doc)
+	require.NoError(t, err)
+	assert.ErrorContains(t, json.Unmarshal(future, EmptyConfig(group)), "unsupported config version 999")
+
+	delete(doc, "version")
+	legacy, err := json.Marshal(doc)
+	require.NoError(t, err)
+	assert.ErrorContains(t, json.Unmarshal(legacy, EmptyConfig(group)), "no version")
+
+	doc["version"] = JSONVersion
+	doc["threshold"] = 3
+	inconsistent, err := json.Marshal(doc)
+	require.NoError(t, err)
+	assert.ErrorContains(t, json.Unmarshal(inconsistent, EmptyConfig(group)), "threshold", "decoded configs are validated")
+
+	assert.Error(t, json.Unmarshal(data, &Config{}), "the group must be set")
+}
+
+func TestConfigBinary(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(21)
+	threshold := 10
+	secret := sample.Scalar(rand.Reader, group)
+	f := polynomial.NewPolynomial(group, threshold, secret)
+	verificationShares := make(map[party.ID]curve.Point, len(partyIDs))
+	for _, id := range partyIDs {
+		verificationShares[id] = f.Evaluate(id.Scalar(group)).ActOnBase()
+	}
+	chainKey := make([]byte, 32)
+	_, _ = rand.Read(chainKey)
+	c := &Config{
+		ID:                 partyIDs[0],
+		Threshold:          threshold,
+		PrivateShare:       f.Evaluate(partyIDs[0].Scalar(group)),
+		PublicKey:          secret.ActOnBase(),
+		ChainKey:           chainKey,
+		VerificationShares: party.NewPointMap(verificationShares),
+		Generation:         1,
+	}
+
+	data, err := c.MarshalBinary()
+	require.NoError(t, err)
+	again, err := c.MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, data, again, "the encoding must not depend on map order")
+
+	decoded := EmptyConfig(group)
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, c.ID, decoded.ID)
+	assert.Equal(t, c.Threshold, decoded.Threshold)
+	assert.Equal(t, c.Generation, decoded.Generation)
+	assert.Equal(t, c.ChainKey, decoded.ChainKey)
+	assert.True(t, c.PrivateShare.Equal(decoded.PrivateShare))
+	assert.True(t, c.PublicKey.Equal(decoded.PublicKey))
+	reencoded, err := decoded.MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, data, reencoded, "a decoded config must encode to the same bytes")
+
+	c.Threshold = len(partyIDs)
+	invalid, err := c.MarshalBinary()
+	require.NoError(t, err)
+	assert.ErrorContains(t, EmptyConfig(group).UnmarshalBinary(invalid), "threshold", "decoded configs are validated")
+	assert.Error(t, (&Config{}).UnmarshalBinary(data), "the group must be set")
+}
+
+// badDealer sends an invalid share to victim, and reveals bad instead of the share it committed to
+// if revealBad is set.
+type badDealer struct {
+	cheater, victim party.ID
+	bad             curve.Scalar
+	revealBad       bool
+}
+
+func (badDealer) ModifyBefore(round.Session) {}
+func (d badDealer) ModifyAfter(rNext round.Session) {
+	// the cheater keeps track of what it revealed, so that it reaches the same outcome
+	if r, ok := rNext.(*round5); ok && d.revealBad && r.SelfID() == d.cheater {
+		r.reveals[d.cheater][d.victim] = d.bad
+	}
+}
+
+func (d badDealer) ModifyContent(rNext round.Session, to party.ID, content round.Content) {
+	if rNext.SelfID() != d.cheater {
+		return
+	}
+	switch c := content.(type) {
+	case *message3:
+		if to == d.victim {
+			c.FLi = d.bad
+		}
+	case *broadcast5:
+		if d.revealBad {
+			c.Shares[d.victim], _ = d.bad.MarshalBinary()
+		}
+	}
+}
+
+func TestKeygenComplaints(t *testing.T) {
+	group := curve.Secp256k1{}
+	N := 4
+	partyIDs := test.PartyIDs(N)
+	cheater, victim := partyIDs[1], partyIDs[2]
+
+	run := func(t *testing.T, rule test.Rule) []round.Session {
+		rounds := make([]round.Session, 0, N)
+		for _, partyID := range partyIDs {
+			r, err := StartKeygenCommon(false, group, partyIDs, N-2, partyID, nil, nil, nil)(nil)
+			require.NoError(t, err)
+			rounds = append(rounds, r)
+		}
+		for {
+			err, done := test.Rounds(rounds, rule)
+			require.NoError(t, err, "failed to process round")
+			if done {
+				return rounds
+			}
+		}
+	}
+
+	t.Run("InvalidReveal", func(t *testing.T) {
+		rounds := run(t, badDealer{cheater: cheater, victim: victim, bad: sample.Scalar(rand.Reader, group), revealBad: true})
+		for _, r := range rounds {
+			if r.SelfID() == cheater {
+				continue
+			}
+			abort, ok := r.(*round.Abort)
+			require.True(t, ok, "party %s should abort", r.SelfID())
+			assert.Equal(t, []party.ID{cheater}, abort.Culprits, "party %s blamed the wrong party", r.SelfID())
+		}
+	})
+
+	t.Run("ValidReveal", func(t *testing.T) {
+		// the dealer proves the share it committed to, so the complaint is dismissed
+		rounds := run(t, badDealer{cheater: cheater, victim: victim, bad: sample.Scalar(rand.Reader, group)})
+		checkOutput(t, rounds, partyIDs)
+	})
+}
+
+func TestKeygenWithRand(t *testing.T) {
+	group := curve.Secp256k1{}
+	N := 4
+	partyIDs := test.PartyIDs(N)
+
+	run := func(seed int64) [][]byte {
+		rounds := make([]round.Session, 0, N)
+		for i, partyID := range partyIDs {
+			r, err := StartKeygenWithRand(false, group, partyIDs, N-1, partyID, mrand.New(mrand.NewSource(seed+int64(i))))(nil)
+			require.NoError(t, err)
+			rounds = append(rounds, r)
+		}
+		for {
+			err, done := test.Rounds(rounds, nil)
+			require.NoError(t, err)
+			if done {
+				break
+			}
+		}
+		configs := make([][]byte, 0, N)
+		for _, r := range rounds {
+			resultRound, ok := r.(*round.Output)
+			require.True(t, ok)
+			data, err := resultRound.Result.(*Config).MarshalBinary()
+			require.NoError(t, err)
+			configs = append(configs, data)
+		}
+		return configs
+	}
+
+	assert.Equal(t, run(1), run(1), "the same seed must give the same configs")
+	assert.NotEqual(t, run(1), run(2))
+}
@@ -0,0 +1,255 @@
+package keygen
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+)
+
+// JSONVersion is the version of the JSON encoding written by MarshalJSON.
+//
+// UnmarshalJSON upgrades documents of older versions, and rejects newer ones.
+const JSONVersion = 1
+
+type configJSON struct {
+	Version            int               `json:"version"`
+	ID                 string            `json:"id"`
+	Threshold          int               `json:"threshold"`
+	Generation         uint64            `json:"generation"`
+	PrivateShare       string            `json:"private_share"`       // Base64 encoded
+	PublicKey          string            `json:"public_key"`          // Base64 encoded
+	ChainKey           string            `json:"chain_key"`           // Base64 encoded
+	VerificationShares map[string]string `json:"verification_shares"` // Base64 encoded
+}
+
+// configMigrations[v] upgrades a document of version v to version v+1.
+var configMigrations = []func(data []byte) ([]byte, error){
+	// version 0 documents have no version: they were written with the default encoding of Config,
+	// before it had a JSON encoding, which drops all keys
+	0: func([]byte) ([]byte, error) {
+		return nil, errors.New("the document has no version, and holds no key material")
+	},
+}
+
+// MigrateJSON upgrades a JSON encoded Config of any supported version to JSONVersion.
+func MigrateJSON(data []byte) ([]byte, error) {
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, err
+	}
+	if header.Version < 0 || header.Version > JSONVersion {
+		return nil, fmt.Errorf("frost: unsupported config version %d (supported: %d)", header.Version, JSONVersion)
+	}
+	for v := header.Version; v < JSONVersion; v++ {
+		var err error
+		if data, err = configMigrations[v](data); err != nil {
+			return nil, fmt.Errorf("frost: failed to upgrade config from version %d: %w", v, err)
+		}
+	}
+	return data, nil
+}
+
+// JSONType returns the type of the document Config is encoded as by MarshalJSON,
+// so that a schema of the JSON encoding can be derived from it.
+func JSONType() reflect.Type {
+	return reflect.TypeOf(configJSON{})
+}
+
+// MarshalJSON implements json.Marshaler.
+func (r *Config) MarshalJSON() ([]byte, error) {
+	privateShare, err := r.PrivateShare.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("frost: private share: %w", err)
+	}
+	publicKey, err := r.PublicKey.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("frost: public key: %w", err)
+	}
+	shares := make(map[string]string, len(r.VerificationShares.Points))
+	for id, share := range r.VerificationShares.Points {
+		data, err := share.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("frost: verification share of %s: %w", id, err)
+		}
+		shares[string(id)] = base64.StdEncoding.EncodeToString(data)
+	}
+	return json.Marshal(&configJSON{
+		Version:            JSONVersion,
+		ID:                 string(r.ID),
+		Threshold:          r.Threshold,
+		Generation:         r.Generation,
+		PrivateShare:       base64.StdEncoding.EncodeToString(privateShare),
+		PublicKey:          base64.StdEncoding.EncodeToString(publicKey),
+		ChainKey:           base64.StdEncoding.EncodeToString(r.ChainKey),
+		VerificationShares: shares,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+//
+// The config must be created with EmptyConfig first, to set its group, and is validated after decoding.
+// Documents written by older versions are upgraded with MigrateJSON.
+func (r *Config) UnmarshalJSON(data []byte) error {
+	if r.PublicKey == nil {
+		return errors.New("frost: config must be initialized using EmptyConfig")
+	}
+	group := r.PublicKey.Curve()
+
+	data, err := MigrateJSON(data)
+	if err != nil {
+		return err
+	}
+	var rj configJSON
+	if err := json.Unmarshal(data, &rj); err != nil {
+		return err
+	}
+
+	decode := func(field, s string, into interface{ UnmarshalBinary([]byte) error }) error {
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err == nil {
+			err = into.UnmarshalBinary(b)
+		}
+		if err != nil {
+			return fmt.Errorf("frost: %s: %w", field, err)
+		}
+		return nil
+	}
+	privateShare, publicKey := group.NewScalar(), group.NewPoint()
+	if err := decode("private share", rj.PrivateShare, privateShare); err != nil {
+		return err
+	}
+	if err := decode("public key", rj.PublicKey, publicKey); err != nil {
+		return err
+	}
+	chainKey, err := base64.StdEncoding.DecodeString(rj.ChainKey)
+	if err != nil {
+		return fmt.Errorf("frost: chain key: %w", err)
+	}
+	shares := make(map[party.ID]curve.Point, len(rj.VerificationShares))
+	for id, s := range rj.VerificationShares {
+		share := group.NewPoint()
+		if err := decode("verification share of "+id, s, share); err != nil {
+			return err
+		}
+		shares[party.ID(id)] = share
+	}
+
+	config := Config{
+		ID:                 party.ID(rj.ID),
+		Threshold:          rj.Threshold,
+		PrivateShare:       privateShare,
+		PublicKey:          publicKey,
+		ChainKey:           chainKey,
+		VerificationShares: party.NewPointMap(shares),
+		Generation:         rj.Generation,
+	}
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	*r = config
+	return nil
+}
+
+// binaryVersion is the version of the CBOR encoding written by MarshalBinary.
+const binaryVersion = 1
+
+type configMarshal struct {
+	Version            uint
+	ID                 party.ID
+	Threshold          int
+	Generation         uint64
+	PrivateShare       curve.Scalar
+	PublicKey          curve.Point
+	ChainKey           []byte
+	VerificationShares []cbor.RawMessage
+}
+
+type verificationShareMarshal struct {
+	ID    party.ID
+	Share curve.Point
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, encoding the config with CBOR.
+//
+// The encoding only depends on the contents of the config: verification shares are written in the
+// order of their sorted IDs, so that encoding the same config twice gives the same bytes.
+func (r *Config) MarshalBinary() ([]byte, error) {
+	ids := make([]party.ID, 0, len(r.VerificationShares.Points))
+	for id := range r.VerificationShares.Points {
+		ids = append(ids, id)
+	}
+	shares := make([]cbor.RawMessage, 0, len(ids))
+	for _, id := range party.NewIDSlice(ids) {
+		data, err := cbor.Marshal(&verificationShareMarshal{ID: id, Share: r.VerificationShares.Points[id]})
+		if err != nil {
+			return nil, fmt.Errorf("frost: verification share of %s: %w", id, err)
+		}
+		shares = append(shares, data)
+	}
+	return cbor.Marshal(&configMarshal{
+		Version:            binaryVersion,
+		ID:                 r.ID,
+		Threshold:          r.Threshold,
+		Generation:         r.Generation,
+		PrivateShare:       r.PrivateShare,
+		PublicKey:          r.PublicKey,
+		ChainKey:           r.ChainKey,
+		VerificationShares: shares,
+	})
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+//
+// The config must be created with EmptyConfig first, to set its group, and is validated after decoding.
+func (r *Config) UnmarshalBinary(data []byte) error {
+	if r.PublicKey == nil {
+		return errors.New("frost: config must be initialized using EmptyConfig")
+	}
+	group := r.PublicKey.Curve()
+
+	cm := &configMarshal{
+		PrivateShare: group.NewScalar(),
+		PublicKey:    group.NewPoint(),
+	}
+	if err := cbor.Unmarshal(data, cm); err != nil {
+		return fmt.Errorf("frost: %w", err)
+	}
+	if cm.Version == 0 || cm.Version > binaryVersion {
+		return fmt.Errorf("frost: unsupported config version %d (supported: %d)", cm.Version, binaryVersion)
+	}
+
+	shares := make(map[party.ID]curve.Point, len(cm.VerificationShares))
+	for _, data := range cm.VerificationShares {
+		s := &verificationShareMarshal{Share: group.NewPoint()}
+		if err := cbor.Unmarshal(data, s); err != nil {
+			return fmt.Errorf("frost: verification share: %w", err)
+		}
+		if _, ok := shares[s.ID]; ok {
+			return fmt.Errorf("frost: party %s: duplicate verification share", s.ID)
+		}
+		shares[s.ID] = s.Share
+	}
+
+	config := Config{
+		ID:                 cm.ID,
+		Threshold:          cm.Threshold,
+		PrivateShare:       cm.PrivateShare,
+		PublicKey:          cm.PublicKey,
+		ChainKey:           cm.ChainKey,
+		VerificationShares: party.NewPointMap(shares),
+		Generation:         cm.Generation,
+	}
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	*r = config
+	return nil
+}
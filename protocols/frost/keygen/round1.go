@@ -1,8 +1,8 @@
 package keygen
 
 import (
-	"crypto/rand"
 	"fmt"
+	"io"
 
 	"github.com/luxfi/threshold/internal/round"
 	"github.com/luxfi/threshold/internal/types"
@@ -19,6 +19,8 @@ import (
 //	https://eprint.iacr.org/2020/852.pdf
 type round1 struct {
 	*round.Helper
+	// rand is the source of all our randomness.
+	rand io.Reader
 	// taproot indicates whether or not to make taproot compatible keys.
 	//
 	// This means taking the necessary steps to ensure that the shared secret generates
@@ -74,10 +76,10 @@ func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
 	aI0 := group.NewScalar()
 	aI0TimesG := group.NewPoint()
 	if !r.refresh {
-		aI0 = sample.Scalar(rand.Reader, r.Group())
+		aI0 = sample.Scalar(r.rand, r.Group())
 		aI0TimesG = aI0.ActOnBase()
 	}
-	fI := polynomial.NewPolynomial(r.Group(), r.threshold, aI0)
+	fI := polynomial.NewPolynomialWithRand(r.Group(), r.threshold, aI0, r.rand)
 
 	// 2. "Every Pᵢ computes a proof of knowledge to the corresponding secret aᵢ₀
 	// by calculating σᵢ = (Rᵢ, μᵢ), such that:
@@ -97,7 +99,7 @@ func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
 	// Refresh: Don't create a proof.
 	var SigmaI *zksch.Proof
 	if !r.refresh {
-		SigmaI = zksch.NewProof(r.Helper.HashForID(r.SelfID()), aI0TimesG, aI0, nil)
+		SigmaI = zksch.NewProofWithRand(r.rand, r.Helper.HashForID(r.SelfID()), aI0TimesG, aI0, nil)
 	}
 
 	// 3. "Every participant Pᵢ computes a public comment Φᵢ = <ϕᵢ₀, ..., ϕᵢₜ>
@@ -112,11 +114,11 @@ func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
 	PhiI := polynomial.NewPolynomialExponent(fI)
 
 	// cI is our contribution to the chaining key
-	cI, err := types.NewRID(rand.Reader)
+	cI, err := types.NewRID(r.rand)
 	if err != nil {
 		return r, fmt.Errorf("failed to sample ChainKey")
 	}
-	commitment, decommitment, err := r.HashForID(r.SelfID()).Commit(cI)
+	commitment, decommitment, err := r.HashForID(r.SelfID()).CommitWithRand(r.rand, cI)
 	if err != nil {
 		return r, fmt.Errorf("failed to commit to chain key")
 	}
@@ -21,6 +21,9 @@ type round3 struct {
 	//
 	// shareFrom[l] corresponds to fₗ(i) in the Frost paper, with i our own ID.
 	shareFrom map[party.ID]curve.Scalar
+
+	// complaints lists the parties whose share did not match their commitment.
+	complaints []party.ID
 }
 
 type message3 struct {
@@ -75,6 +78,8 @@ func (r *round3) VerifyMessage(msg round.Message) error {
 // StoreMessage implements round.Round.
 //
 // Verify the VSS condition here since we will not be sending this message to other parties for verification.
+// Instead of aborting, an invalid share is recorded as a complaint against its sender, which all parties
+// resolve in the next rounds, so that they agree on the culprit.
 func (r *round3) StoreMessage(msg round.Message) error {
 	from, body := msg.From, msg.Content.(*message3)
 
@@ -85,10 +90,9 @@ func (r *round3) StoreMessage(msg round.Message) error {
 	//   fₗ(i) * G =? ∑ₖ₌₀ᵗ (iᵏ mod q) * ϕₗₖ
 	//
 	// aborting if the check fails."
-	expected := body.FLi.ActOnBase()
-	actual := r.Phi[from].Evaluate(r.SelfID().Scalar(r.Group()))
-	if !expected.Equal(actual) {
-		return fmt.Errorf("VSS failed to validate")
+	if !r.validShare(from, r.SelfID(), body.FLi) {
+		r.complaints = append(r.complaints, from)
+		return nil
 	}
 
 	r.shareFrom[from] = body.FLi
@@ -96,8 +100,27 @@ func (r *round3) StoreMessage(msg round.Message) error {
 	return nil
 }
 
+// validShare returns true if share is the evaluation at id of the polynomial committed to by dealer.
+func (r *round3) validShare(dealer, id party.ID, share curve.Scalar) bool {
+	return share.ActOnBase().Equal(r.Phi[dealer].Evaluate(id.Scalar(r.Group())))
+}
+
 // Finalize implements round.Round.
-func (r *round3) Finalize(chan<- *round.Message) (round.Session, error) {
+//
+// Before computing our share, all parties broadcast their complaints about the shares they received.
+func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
+	complaints := party.NewIDSlice(r.complaints)
+	if err := r.BroadcastMessage(out, &broadcast4{Complaints: complaints}); err != nil {
+		return r, err
+	}
+	return &round4{
+		round3:     r,
+		complaints: map[party.ID][]party.ID{r.SelfID(): complaints},
+	}, nil
+}
+
+// output computes the result of the protocol, once all parties have accepted their shares.
+func (r *round3) output() round.Session {
 	ChainKey := types.EmptyRID()
 	for _, j := range r.PartyIDs() {
 		ChainKey.XOR(r.ChainKeys[j])
@@ -164,7 +187,7 @@ func (r *round3) Finalize(chan<- *round.Message) (round.Session, error) {
 			PrivateShare:       r.privateShare.(*curve.Secp256k1Scalar),
 			PublicKey:          YSecp.XBytes()[:],
 			VerificationShares: secpVerificationShares,
-		}), nil
+		})
 	}
 
 	return r.ResultRound(&Config{
@@ -173,7 +196,7 @@ func (r *round3) Finalize(chan<- *round.Message) (round.Session, error) {
 		PrivateShare:       r.privateShare,
 		PublicKey:          r.publicKey,
 		VerificationShares: party.NewPointMap(r.verificationShares),
-	}), nil
+	})
 }
 
 // RoundNumber implements round.Content.
@@ -0,0 +1,101 @@
+package keygen
+
+import (
+	"fmt"
+
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+)
+
+// round4 collects the complaints of all parties about the shares they received.
+//
+// This is an addition to FROST: instead of aborting on its own when a share fails to validate,
+// a party accuses its sender, and the dealers which were accused reveal the share in the next round,
+// so that every party can check them against the dealer's commitment.
+type round4 struct {
+	*round3
+
+	// complaints[l] lists the parties whose share party l found invalid, ourselves included.
+	complaints map[party.ID][]party.ID
+}
+
+type broadcast4 struct {
+	round.NormalBroadcastContent
+	// Complaints lists the parties who sent us a share which does not match their commitment.
+	Complaints []party.ID
+}
+
+// StoreBroadcastMessage implements round.BroadcastRound.
+func (r *round4) StoreBroadcastMessage(msg round.Message) error {
+	from := msg.From
+	body, ok := msg.Content.(*broadcast4)
+	if !ok || body == nil {
+		return round.ErrInvalidContent
+	}
+
+	complaints := party.NewIDSlice(body.Complaints)
+	if !complaints.Valid() {
+		return fmt.Errorf("party %s sent duplicate complaints", from)
+	}
+	for _, j := range complaints {
+		if j == from || !r.PartyIDs().Contains(j) {
+			return fmt.Errorf("party %s complained about unknown party %s", from, j)
+		}
+	}
+	r.complaints[from] = complaints
+	return nil
+}
+
+// VerifyMessage implements round.Round.
+func (round4) VerifyMessage(round.Message) error { return nil }
+
+// StoreMessage implements round.Round.
+func (round4) StoreMessage(round.Message) error { return nil }
+
+// Finalize implements round.Round.
+//
+// Without any complaint, the protocol ends here. Otherwise, we reveal the shares we sent to the parties
+// who complained about us.
+func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
+	accused := false
+	reveals := make(map[party.ID]curve.Scalar)
+	encoded := make(map[party.ID][]byte)
+	for _, l := range r.PartyIDs() {
+		for _, j := range r.complaints[l] {
+			accused = true
+			if j != r.SelfID() {
+				continue
+			}
+			reveals[l] = r.fI.Evaluate(l.Scalar(r.Group()))
+			data, err := reveals[l].MarshalBinary()
+			if err != nil {
+				return r, err
+			}
+			encoded[l] = data
+		}
+	}
+	if !accused {
+		return r.output(), nil
+	}
+
+	if err := r.BroadcastMessage(out, &broadcast5{Shares: encoded}); err != nil {
+		return r, err
+	}
+	return &round5{
+		round4:  r,
+		reveals: map[party.ID]map[party.ID]curve.Scalar{r.SelfID(): reveals},
+	}, nil
+}
+
+// MessageContent implements round.Round.
+func (round4) MessageContent() round.Content { return nil }
+
+// RoundNumber implements round.Content.
+func (broadcast4) RoundNumber() round.Number { return 4 }
+
+// BroadcastContent implements round.BroadcastRound.
+func (r *round4) BroadcastContent() round.BroadcastContent { return &broadcast4{} }
+
+// Number implements round.Round.
+func (round4) Number() round.Number { return 4 }
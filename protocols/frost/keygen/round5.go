@@ -0,0 +1,92 @@
+package keygen
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+)
+
+// round5 resolves the complaints of round4, using the shares revealed by the accused parties.
+type round5 struct {
+	*round4
+
+	// reveals[j][l] is the share fⱼ(l) that party j revealed for the complaint of party l.
+	reveals map[party.ID]map[party.ID]curve.Scalar
+}
+
+type broadcast5 struct {
+	round.NormalBroadcastContent
+	// Shares holds the encoding of the share we sent to each party who complained about us.
+	Shares map[party.ID][]byte
+}
+
+// StoreBroadcastMessage implements round.BroadcastRound.
+func (r *round5) StoreBroadcastMessage(msg round.Message) error {
+	from := msg.From
+	body, ok := msg.Content.(*broadcast5)
+	if !ok || body == nil {
+		return round.ErrInvalidContent
+	}
+	reveals := make(map[party.ID]curve.Scalar, len(body.Shares))
+	for l, data := range body.Shares {
+		if !r.PartyIDs().Contains(l) {
+			return fmt.Errorf("party %s revealed a share for unknown party %s", from, l)
+		}
+		share := r.Group().NewScalar()
+		if err := share.UnmarshalBinary(data); err != nil {
+			return fmt.Errorf("party %s revealed an invalid share for %s: %w", from, l, err)
+		}
+		reveals[l] = share
+	}
+	r.reveals[from] = reveals
+	return nil
+}
+
+// VerifyMessage implements round.Round.
+func (round5) VerifyMessage(round.Message) error { return nil }
+
+// StoreMessage implements round.Round.
+func (round5) StoreMessage(round.Message) error { return nil }
+
+// Finalize implements round.Round.
+//
+// An accused party is a culprit if it did not reveal a share matching its commitment.
+// If all revealed shares are valid, the complaints are dismissed, and parties who complained
+// use the revealed shares instead of the ones they received.
+func (r *round5) Finalize(chan<- *round.Message) (round.Session, error) {
+	var culprits []party.ID
+	for _, l := range r.PartyIDs() {
+		for _, j := range r.complaints[l] {
+			share, ok := r.reveals[j][l]
+			if (!ok || !r.validShare(j, l, share)) && !slices.Contains(culprits, j) {
+				culprits = append(culprits, j)
+			}
+		}
+	}
+	if len(culprits) > 0 {
+		return r.AbortRound(errors.New("keygen: invalid shares were dealt"), party.NewIDSlice(culprits)...), nil
+	}
+
+	for _, j := range r.complaints[r.SelfID()] {
+		r.shareFrom[j] = r.reveals[j][r.SelfID()]
+	}
+	return r.output(), nil
+}
+
+// MessageContent implements round.Round.
+func (round5) MessageContent() round.Content { return nil }
+
+// RoundNumber implements round.Content.
+func (broadcast5) RoundNumber() round.Number { return 5 }
+
+// BroadcastContent implements round.BroadcastRound.
+func (r *round5) BroadcastContent() round.BroadcastContent {
+	return &broadcast5{}
+}
+
+// Number implements round.Round.
+func (round5) Number() round.Number { return 5 }
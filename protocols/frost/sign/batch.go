@@ -0,0 +1,148 @@
+package sign
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/math/sample"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/pool"
+	"github.com/luxfi/threshold/pkg/protocol"
+	"github.com/luxfi/threshold/protocols/frost/keygen"
+	"github.com/zeebo/blake3"
+)
+
+const (
+	// Frost Sign of a batch of messages with precomputed commitments.
+	protocolIDBatch = "frost/sign-batch"
+	// This protocol has 3 concrete rounds.
+	protocolRoundsBatch round.Number = 3
+)
+
+const deriveBatchHashKeyContext = "github.com/luxfi/threshold/frost 2025-01-20T12:00+00:00 Derive batch hash Key"
+
+// Commitment holds a pair of signing nonces (dᵢ, eᵢ), along with their commitments
+// Dᵢ = dᵢ * G, Eᵢ = eᵢ * G.
+//
+// This corresponds to a single entry in the list of commitments produced by the
+// pre-processing step in Figure 2 of the Frost paper.
+//
+// A Commitment can be used to sign exactly one message. Using the same nonces for two
+// different messages allows anyone to recover the private share, so SignBatch refuses
+// to use a Commitment more than once.
+type Commitment struct {
+	d, e curve.Scalar
+	// D = dᵢ * G
+	D curve.Point
+	// E = eᵢ * G
+	E curve.Point
+
+	used atomic.Bool
+}
+
+// Used returns true if this commitment was already consumed by a signing session.
+func (c *Commitment) Used() bool {
+	return c.used.Load()
+}
+
+// NewCommitments creates count single-use commitments for the given config.
+//
+// The nonces are derived with the same hedged process as the first signing round,
+// mixing fresh randomness with a key derived from the private share.
+func NewCommitments(config *keygen.Config, count int, pl *pool.Pool) ([]*Commitment, error) {
+	if count <= 0 {
+		return nil, fmt.Errorf("sign.NewCommitments: count must be positive, got %d", count)
+	}
+	sIBytes, err := config.PrivateShare.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	hashKey := make([]byte, 32)
+	blake3.DeriveKey(deriveBatchHashKeyContext, sIBytes, hashKey)
+
+	group := config.Curve()
+	results := pl.Parallelize(count, func(i int) interface{} {
+		nonceHasher, _ := blake3.NewKeyed(hashKey)
+		var index [8]byte
+		binary.BigEndian.PutUint64(index[:], uint64(i))
+		_, _ = nonceHasher.Write(index[:])
+		a := make([]byte, 32)
+		_, _ = rand.Read(a)
+		_, _ = nonceHasher.Write(a)
+		nonceDigest := nonceHasher.Digest()
+
+		d := sample.ScalarUnit(nonceDigest, group)
+		e := sample.ScalarUnit(nonceDigest, group)
+		return &Commitment{
+			d: d,
+			e: e,
+			D: d.ActOnBase(),
+			E: e.ActOnBase(),
+		}
+	})
+	commitments := make([]*Commitment, count)
+	for i, c := range results {
+		commitments[i] = c.(*Commitment)
+	}
+	return commitments, nil
+}
+
+// StartSignBatch starts a signing session for several messages at once,
+// using commitments[i] to sign messages[i].
+//
+// Every commitment is marked as used before the session is created, even if creating the
+// session fails afterwards, so that a nonce can never end up being used twice.
+func StartSignBatch(config *keygen.Config, signers []party.ID, messages [][]byte, commitments []*Commitment) protocol.StartFunc {
+	return func(sessionID []byte) (round.Session, error) {
+		if len(messages) == 0 {
+			return nil, errors.New("sign.StartSignBatch: no messages to sign")
+		}
+		if len(messages) != len(commitments) {
+			return nil, fmt.Errorf("sign.StartSignBatch: got %d commitments for %d messages", len(commitments), len(messages))
+		}
+		// checked before the commitments are used up
+		if len(signers) < config.Threshold+1 {
+			return nil, fmt.Errorf("sign.StartSignBatch: need at least %d signers, got %d", config.Threshold+1, len(signers))
+		}
+		for i, c := range commitments {
+			if c == nil {
+				return nil, fmt.Errorf("sign.StartSignBatch: commitment %d is nil", i)
+			}
+			if !c.used.CompareAndSwap(false, true) {
+				return nil, fmt.Errorf("sign.StartSignBatch: commitment %d was already used", i)
+			}
+		}
+
+		info := round.Info{
+			ProtocolID:       protocolIDBatch,
+			FinalRoundNumber: protocolRoundsBatch,
+			SelfID:           config.ID,
+			PartyIDs:         signers,
+			Threshold:        config.Threshold,
+			Group:            config.PublicKey.Curve(),
+			RequireSessionID: true,
+		}
+		helper, err := round.NewSession(info, sessionID, nil, messageList(messages))
+		if err != nil {
+			return nil, fmt.Errorf("sign.StartSignBatch: %w", err)
+		}
+
+		M := make([]messageHash, len(messages))
+		for i, m := range messages {
+			M[i] = m
+		}
+		return &batchRound1{
+			Helper:      helper,
+			M:           M,
+			Y:           config.PublicKey,
+			YShares:     config.VerificationShares.Points,
+			sI:          config.PrivateShare,
+			commitments: commitments,
+		}, nil
+	}
+}
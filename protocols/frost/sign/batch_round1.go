@@ -0,0 +1,60 @@
+package sign
+
+import (
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+)
+
+// batchRound1 plays the same role as round1, except that the nonces were generated
+// ahead of time by NewCommitments, so we only need to publish one pair of commitments
+// for each message.
+type batchRound1 struct {
+	*round.Helper
+	// M[k] is the hash of the k-th message we're signing.
+	M []messageHash
+	// Y is the public key we're signing for.
+	Y curve.Point
+	// YShares are verification shares for each participant's fraction of the secret key
+	YShares map[party.ID]curve.Point
+	// sI = sᵢ is our private secret share
+	sI curve.Scalar
+	// commitments[k] holds the nonces used to sign M[k].
+	commitments []*Commitment
+}
+
+// VerifyMessage implements round.Round.
+func (r *batchRound1) VerifyMessage(round.Message) error { return nil }
+
+// StoreMessage implements round.Round.
+func (r *batchRound1) StoreMessage(round.Message) error { return nil }
+
+// Finalize implements round.Round.
+func (r *batchRound1) Finalize(out chan<- *round.Message) (round.Session, error) {
+	n := len(r.M)
+	DI := make([]curve.Point, n)
+	EI := make([]curve.Point, n)
+	D := make([]map[party.ID]curve.Point, n)
+	E := make([]map[party.ID]curve.Point, n)
+	for k, c := range r.commitments {
+		DI[k], EI[k] = c.D, c.E
+		D[k] = map[party.ID]curve.Point{r.SelfID(): c.D}
+		E[k] = map[party.ID]curve.Point{r.SelfID(): c.E}
+	}
+
+	// Broadcast the commitments
+	if err := r.BroadcastMessage(out, &batchBroadcast2{D_i: DI, E_i: EI}); err != nil {
+		return r, err
+	}
+	return &batchRound2{
+		batchRound1: r,
+		D:           D,
+		E:           E,
+	}, nil
+}
+
+// MessageContent implements round.Round.
+func (batchRound1) MessageContent() round.Content { return nil }
+
+// Number implements round.Round.
+func (batchRound1) Number() round.Number { return 1 }
@@ -0,0 +1,127 @@
+package sign
+
+import (
+	"fmt"
+
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/math/polynomial"
+	"github.com/luxfi/threshold/pkg/party"
+)
+
+// batchRound2 computes a response for every message, as in round2.
+type batchRound2 struct {
+	*batchRound1
+	// D[k][l] = Dₗ is the first commitment of party l for message k, ourself included.
+	D []map[party.ID]curve.Point
+	// E[k][l] = Eₗ is the second commitment of party l for message k, ourself included.
+	E []map[party.ID]curve.Point
+}
+
+type batchBroadcast2 struct {
+	round.ReliableBroadcastContent
+	// D_i[k] is the first commitment produced by the sender for message k.
+	D_i []curve.Point
+	// E_i[k] is the second commitment produced by the sender for message k.
+	E_i []curve.Point
+}
+
+// StoreBroadcastMessage implements round.BroadcastRound.
+func (r *batchRound2) StoreBroadcastMessage(msg round.Message) error {
+	body, ok := msg.Content.(*batchBroadcast2)
+	if !ok || body == nil {
+		return round.ErrInvalidContent
+	}
+
+	if len(body.D_i) != len(r.M) || len(body.E_i) != len(r.M) {
+		return fmt.Errorf("expected %d commitments, got (%d, %d)", len(r.M), len(body.D_i), len(body.E_i))
+	}
+	for k := range r.M {
+		if body.D_i[k] == nil || body.E_i[k] == nil {
+			return round.ErrNilFields
+		}
+		if body.D_i[k].IsIdentity() || body.E_i[k].IsIdentity() {
+			return fmt.Errorf("nonce commitment %d is the identity point", k)
+		}
+	}
+
+	for k := range r.M {
+		r.D[k][msg.From] = body.D_i[k]
+		r.E[k][msg.From] = body.E_i[k]
+	}
+	return nil
+}
+
+// VerifyMessage implements round.Round.
+func (batchRound2) VerifyMessage(round.Message) error { return nil }
+
+// StoreMessage implements round.Round.
+func (batchRound2) StoreMessage(round.Message) error { return nil }
+
+// Finalize implements round.Round.
+//
+// For each message, this follows the steps of round2.Finalize, using the commitments for that message.
+func (r *batchRound2) Finalize(out chan<- *round.Message) (round.Session, error) {
+	n := len(r.M)
+	Lambdas := polynomial.Lagrange(r.Group(), r.PartyIDs())
+
+	R := make([]curve.Point, n)
+	RShares := make([]map[party.ID]curve.Point, n)
+	c := make([]curve.Scalar, n)
+	zI := make([]curve.Scalar, n)
+	for k := range r.M {
+		rho := bindingFactors(r.Group(), r.M[k], r.PartyIDs(), r.D[k], r.E[k])
+		R[k], RShares[k] = groupCommitment(r.Group(), r.PartyIDs(), rho, r.D[k], r.E[k])
+		c[k] = challenge(r.Group(), R[k], r.Y, r.M[k])
+
+		// zᵢ = dᵢ + (eᵢ ρᵢ) + λᵢ sᵢ c
+		nonces := r.commitments[k]
+		z := r.Group().NewScalar().Set(Lambdas[r.SelfID()]).Mul(r.sI).Mul(c[k])
+		z.Add(nonces.d)
+		ed := r.Group().NewScalar().Set(rho[r.SelfID()]).Mul(nonces.e)
+		z.Add(ed)
+		zI[k] = z
+
+		// The nonces are never needed again, so we overwrite them.
+		nonces.d.Set(r.Group().NewScalar())
+		nonces.e.Set(r.Group().NewScalar())
+	}
+
+	// Broadcast our responses
+	if err := r.BroadcastMessage(out, &batchBroadcast3{Z_i: zI}); err != nil {
+		return r, err
+	}
+
+	z := make([]map[party.ID]curve.Scalar, n)
+	for k := range z {
+		z[k] = map[party.ID]curve.Scalar{r.SelfID(): zI[k]}
+	}
+	return &batchRound3{
+		batchRound2: r,
+		R:           R,
+		RShares:     RShares,
+		c:           c,
+		z:           z,
+		Lambda:      Lambdas,
+	}, nil
+}
+
+// MessageContent implements round.Round.
+func (batchRound2) MessageContent() round.Content { return nil }
+
+// RoundNumber implements round.Content.
+func (batchBroadcast2) RoundNumber() round.Number { return 2 }
+
+// BroadcastContent implements round.BroadcastRound.
+func (r *batchRound2) BroadcastContent() round.BroadcastContent {
+	D := make([]curve.Point, len(r.M))
+	E := make([]curve.Point, len(r.M))
+	for k := range r.M {
+		D[k] = r.Group().NewPoint()
+		E[k] = r.Group().NewPoint()
+	}
+	return &batchBroadcast2{D_i: D, E_i: E}
+}
+
+// Number implements round.Round.
+func (batchRound2) Number() round.Number { return 2 }
@@ -0,0 +1,99 @@
+package sign
+
+import (
+	"fmt"
+
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+)
+
+// batchRound3 verifies the responses for every message, and aggregates them into signatures, as in round3.
+type batchRound3 struct {
+	*batchRound2
+	// R[k] is the group commitment for message k.
+	R []curve.Point
+	// RShares[k][l] is the contribution of party l to R[k].
+	RShares []map[party.ID]curve.Point
+	// c[k] is the challenge for message k.
+	c []curve.Scalar
+	// z[k][l] is the response of party l for message k.
+	z []map[party.ID]curve.Scalar
+	// Lambda[l] = λₗ
+	Lambda map[party.ID]curve.Scalar
+}
+
+type batchBroadcast3 struct {
+	round.NormalBroadcastContent
+	// Z_i[k] is the response computed by the sender for message k.
+	Z_i []curve.Scalar
+}
+
+// StoreBroadcastMessage implements round.BroadcastRound.
+func (r *batchRound3) StoreBroadcastMessage(msg round.Message) error {
+	from := msg.From
+	body, ok := msg.Content.(*batchBroadcast3)
+	if !ok || body == nil {
+		return round.ErrInvalidContent
+	}
+
+	if len(body.Z_i) != len(r.M) {
+		return fmt.Errorf("expected %d responses, got %d", len(r.M), len(body.Z_i))
+	}
+
+	for k, zI := range body.Z_i {
+		if zI == nil {
+			return round.ErrNilFields
+		}
+		// zᵢ • G = Rᵢ + c * λᵢ * Yᵢ
+		expected := r.c[k].Act(r.Lambda[from].Act(r.YShares[from])).Add(r.RShares[k][from])
+		if !zI.ActOnBase().Equal(expected) {
+			return fmt.Errorf("failed to verify response %d from %v", k, from)
+		}
+	}
+
+	for k, zI := range body.Z_i {
+		r.z[k][from] = zI
+	}
+	return nil
+}
+
+// VerifyMessage implements round.Round.
+func (batchRound3) VerifyMessage(round.Message) error { return nil }
+
+// StoreMessage implements round.Round.
+func (batchRound3) StoreMessage(round.Message) error { return nil }
+
+// Finalize implements round.Round.
+func (r *batchRound3) Finalize(chan<- *round.Message) (round.Session, error) {
+	sigs := make([]Signature, len(r.M))
+	for k := range r.M {
+		z := r.Group().NewScalar()
+		for _, zL := range r.z[k] {
+			z.Add(zL)
+		}
+		sigs[k] = Signature{R: r.R[k], z: z}
+		if !sigs[k].Verify(r.Y, r.M[k]) {
+			return r.AbortRound(fmt.Errorf("generated signature %d failed to verify", k)), nil
+		}
+	}
+	return r.ResultRound(sigs), nil
+}
+
+// MessageContent implements round.Round.
+func (batchRound3) MessageContent() round.Content { return nil }
+
+// RoundNumber implements round.Content.
+func (batchBroadcast3) RoundNumber() round.Number { return 3 }
+
+// BroadcastContent implements round.BroadcastRound.
+func (r *batchRound3) BroadcastContent() round.BroadcastContent {
+	Z := make([]curve.Scalar, len(r.M))
+	for k := range Z {
+		Z[k] = r.Group().NewScalar()
+	}
+	return &batchBroadcast3{Z_i: Z}
+}
+
+// Number implements round.Round.
+func (batchRound3) Number() round.Number { return 3 }
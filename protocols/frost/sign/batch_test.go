@@ -0,0 +1,139 @@
+package sign
+
+import (
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/math/polynomial"
+	"github.com/luxfi/threshold/pkg/math/sample"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/protocols/frost/keygen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func batchConfigs(group curve.Curve, partyIDs []party.ID, threshold int) map[party.ID]*keygen.Config {
+	secret := sample.Scalar(rand.Reader, group)
+	f := polynomial.NewPolynomial(group, threshold, secret)
+
+	privateShares := make(map[party.ID]curve.Scalar, len(partyIDs))
+	verificationShares := make(map[party.ID]curve.Point, len(partyIDs))
+	for _, id := range partyIDs {
+		privateShares[id] = f.Evaluate(id.Scalar(group))
+		verificationShares[id] = privateShares[id].ActOnBase()
+	}
+
+	configs := make(map[party.ID]*keygen.Config, len(partyIDs))
+	for _, id := range partyIDs {
+		configs[id] = &keygen.Config{
+			ID:                 id,
+			Threshold:          threshold,
+			PublicKey:          secret.ActOnBase(),
+			PrivateShare:       privateShares[id],
+			VerificationShares: party.NewPointMap(verificationShares),
+		}
+	}
+	return configs
+}
+
+func batchMessages(n int) [][]byte {
+	messages := make([][]byte, n)
+	for i := range messages {
+		messages[i] = []byte(fmt.Sprintf("message %d", i))
+	}
+	return messages
+}
+
+func signBatchRounds(t testing.TB, configs map[party.ID]*keygen.Config, signers []party.ID, messages [][]byte) []round.Session {
+	rounds := make([]round.Session, 0, len(signers))
+	for _, id := range signers {
+		commitments, err := NewCommitments(configs[id], len(messages), nil)
+		require.NoError(t, err)
+		r, err := StartSignBatch(configs[id], signers, messages, commitments)(nil)
+		require.NoError(t, err, "round creation should not result in an error")
+		rounds = append(rounds, r)
+	}
+	return rounds
+}
+
+func TestSignBatch(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(5)
+	configs := batchConfigs(group, partyIDs, 2)
+	signers := partyIDs[:3]
+	public := configs[partyIDs[0]].PublicKey
+	messages := batchMessages(4)
+
+	rounds := signBatchRounds(t, configs, signers, messages)
+	require.NoError(t, test.RunRounds(rounds, nil))
+
+	for _, r := range rounds {
+		require.IsType(t, &round.Output{}, r, "expected result round")
+		sigs, ok := r.(*round.Output).Result.([]Signature)
+		require.True(t, ok, "expected []Signature result")
+		require.Len(t, sigs, len(messages))
+		for k, sig := range sigs {
+			assert.True(t, sig.Verify(public, messages[k]), "expected valid signature")
+			assert.False(t, sig.Verify(public, messages[(k+1)%len(messages)]), "signature should not verify for another message")
+		}
+	}
+}
+
+func TestSignBatchCommitmentReuse(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(3)
+	config := batchConfigs(group, partyIDs, 1)[partyIDs[0]]
+
+	commitments, err := NewCommitments(config, 3, nil)
+	require.NoError(t, err)
+
+	// a single commitment for two different messages
+	_, err = StartSignBatch(config, partyIDs, batchMessages(2), []*Commitment{commitments[0], commitments[0]})(nil)
+	assert.Error(t, err)
+
+	// a commitment consumed by a previous session
+	_, err = StartSignBatch(config, partyIDs, batchMessages(1), commitments[1:2])(nil)
+	require.NoError(t, err)
+	assert.True(t, commitments[1].Used())
+	_, err = StartSignBatch(config, partyIDs, batchMessages(1), commitments[1:2])(nil)
+	assert.Error(t, err)
+
+	// one commitment per message
+	_, err = StartSignBatch(config, partyIDs, batchMessages(2), commitments[2:])(nil)
+	assert.Error(t, err)
+
+	_, err = NewCommitments(config, 0, nil)
+	assert.Error(t, err)
+}
+
+func BenchmarkSignBatch(b *testing.B) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(5)
+	configs := batchConfigs(group, partyIDs, 2)
+	signers := partyIDs[:3]
+	messages := batchMessages(16)
+
+	b.Run("batch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			require.NoError(b, test.RunRounds(signBatchRounds(b, configs, signers, messages), nil))
+		}
+	})
+
+	b.Run("per-message", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, m := range messages {
+				rounds := make([]round.Session, 0, len(signers))
+				for _, id := range signers {
+					r, err := StartSignCommon(false, configs[id], signers, m)(nil)
+					require.NoError(b, err)
+					rounds = append(rounds, r)
+				}
+				require.NoError(b, test.RunRounds(rounds, nil))
+			}
+		}
+	})
+}
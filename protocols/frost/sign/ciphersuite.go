@@ -0,0 +1,222 @@
+package sign
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"io"
+
+	"github.com/cronokirby/saferith"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+)
+
+// Ciphersuite selects the hash functions and encodings with which FROST signs.
+//
+// The ciphersuites of RFC 9591 make the nonce commitments, binding factors, challenge and signature shares
+// those of any other implementation of the RFC, so that they can sign together.
+//
+// See: https://www.rfc-editor.org/rfc/rfc9591
+type Ciphersuite int
+
+const (
+	// CiphersuiteDefault is the variant of FROST signed with by default,
+	// which hashes with this library's own domain separated hash.
+	CiphersuiteDefault Ciphersuite = iota
+	// CiphersuiteSecp256k1SHA256 is FROST(secp256k1, SHA-256) of RFC 9591.
+	CiphersuiteSecp256k1SHA256
+	// CiphersuiteEd25519SHA512 is FROST(Ed25519, SHA-512) of RFC 9591, whose signatures are RFC 8032 signatures.
+	CiphersuiteEd25519SHA512
+)
+
+// String returns the context string of the ciphersuite.
+func (s Ciphersuite) String() string {
+	switch s {
+	case CiphersuiteDefault:
+		return "default"
+	case CiphersuiteSecp256k1SHA256:
+		return "FROST-secp256k1-SHA256-v1"
+	case CiphersuiteEd25519SHA512:
+		return "FROST-ED25519-SHA512-v1"
+	default:
+		return fmt.Sprintf("Ciphersuite(%d)", int(s))
+	}
+}
+
+// Group returns the curve of the ciphersuite, and nil for CiphersuiteDefault, which works on any curve.
+func (s Ciphersuite) Group() curve.Curve {
+	switch s {
+	case CiphersuiteSecp256k1SHA256:
+		return curve.Secp256k1{}
+	case CiphersuiteEd25519SHA512:
+		return curve.Ed25519{}
+	default:
+		return nil
+	}
+}
+
+// validate checks that s is a known ciphersuite that signs on group.
+func (s Ciphersuite) validate(group curve.Curve) error {
+	switch s {
+	case CiphersuiteDefault:
+		return nil
+	case CiphersuiteSecp256k1SHA256, CiphersuiteEd25519SHA512:
+		if group.Name() != s.Group().Name() {
+			return fmt.Errorf("ciphersuite %s needs a %s key, not %s", s, s.Group().Name(), group.Name())
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown ciphersuite %d", int(s))
+	}
+}
+
+// The hash functions H1 to H5 of the ciphersuite, as in section 6 of RFC 9591.
+
+// h1 computes the binding factor of a signer from its input.
+func (s Ciphersuite) h1(m []byte) curve.Scalar {
+	if s == CiphersuiteEd25519SHA512 {
+		return s.hashToScalarSHA512([]byte(s.String()+"rho"), m)
+	}
+	return hashToFieldSecp256k1(m, []byte(s.String()+"rho"))
+}
+
+// h2 computes the challenge.
+func (s Ciphersuite) h2(m []byte) curve.Scalar {
+	if s == CiphersuiteEd25519SHA512 {
+		// without a context string, so that the challenge is the one of RFC 8032
+		return s.hashToScalarSHA512(nil, m)
+	}
+	return hashToFieldSecp256k1(m, []byte(s.String()+"chal"))
+}
+
+// h3 derives a nonce.
+func (s Ciphersuite) h3(m []byte) curve.Scalar {
+	if s == CiphersuiteEd25519SHA512 {
+		return s.hashToScalarSHA512([]byte(s.String()+"nonce"), m)
+	}
+	return hashToFieldSecp256k1(m, []byte(s.String()+"nonce"))
+}
+
+// h4 hashes the message.
+func (s Ciphersuite) h4(m []byte) []byte {
+	return s.hash([]byte(s.String()+"msg"), m)
+}
+
+// h5 hashes the encoded commitment list.
+func (s Ciphersuite) h5(m []byte) []byte {
+	return s.hash([]byte(s.String()+"com"), m)
+}
+
+// hash returns the SHA-256 or SHA-512 digest of prefix followed by m.
+func (s Ciphersuite) hash(prefix, m []byte) []byte {
+	if s == CiphersuiteEd25519SHA512 {
+		h := sha512.New()
+		_, _ = h.Write(prefix)
+		_, _ = h.Write(m)
+		return h.Sum(nil)
+	}
+	h := sha256.New()
+	_, _ = h.Write(prefix)
+	_, _ = h.Write(m)
+	return h.Sum(nil)
+}
+
+// hashToScalarSHA512 interprets the SHA-512 digest of prefix followed by m as a little-endian integer modulo the order.
+func (s Ciphersuite) hashToScalarSHA512(prefix, m []byte) curve.Scalar {
+	return new(curve.Ed25519Scalar).SetUniformBytesLE(s.hash(prefix, m))
+}
+
+// hashToFieldSecp256k1 is hash_to_field of RFC 9380 for the scalars of secp256k1,
+// with expand_message_xmd over SHA-256 and L = 48.
+func hashToFieldSecp256k1(m, dst []byte) curve.Scalar {
+	uniform := expandMessageXMD(m, dst, 48)
+	return curve.Secp256k1{}.NewScalar().SetNat(new(saferith.Nat).SetBytes(uniform))
+}
+
+// expandMessageXMD is expand_message_xmd of RFC 9380 section 5.3.1, with SHA-256.
+//
+// length must be at most 255 blocks of 32 bytes, and dst at most 255 bytes, which holds for all uses here.
+func expandMessageXMD(m, dst []byte, length int) []byte {
+	dstPrime := append(append([]byte{}, dst...), byte(len(dst)))
+
+	h := sha256.New()
+	_, _ = h.Write(make([]byte, h.BlockSize()))
+	_, _ = h.Write(m)
+	_, _ = h.Write([]byte{byte(length >> 8), byte(length), 0})
+	_, _ = h.Write(dstPrime)
+	b0 := h.Sum(nil)
+
+	out := make([]byte, 0, length+sha256.Size)
+	bi := make([]byte, sha256.Size)
+	for i := 1; len(out) < length; i++ {
+		// b₁ = H(b₀ || 1 || DST'), bᵢ = H((b₀ ⊕ bᵢ₋₁) || i || DST')
+		for j := range bi {
+			bi[j] ^= b0[j]
+		}
+		h.Reset()
+		_, _ = h.Write(bi)
+		_, _ = h.Write([]byte{byte(i)})
+		_, _ = h.Write(dstPrime)
+		bi = h.Sum(nil)
+		out = append(out, bi...)
+	}
+	return out[:length]
+}
+
+// serializeElement encodes a point: compressed SEC1 on secp256k1, and as in RFC 8032 on Ed25519.
+func serializeElement(p curve.Point) []byte {
+	data, _ := p.MarshalBinary()
+	return data
+}
+
+// serializeScalar encodes a scalar: big-endian on secp256k1, and little-endian on Ed25519.
+func serializeScalar(s curve.Scalar) []byte {
+	if e, ok := s.(*curve.Ed25519Scalar); ok {
+		return e.BytesLE()
+	}
+	data, _ := s.MarshalBinary()
+	return data
+}
+
+// nonceGenerate derives a nonce from 32 bytes of rand and the secret share, as nonce_generate in RFC 9591.
+func (s Ciphersuite) nonceGenerate(secret curve.Scalar, rand io.Reader) (curve.Scalar, error) {
+	randomBytes := make([]byte, 32)
+	if _, err := io.ReadFull(rand, randomBytes); err != nil {
+		return nil, fmt.Errorf("failed to sample nonce: %w", err)
+	}
+	return s.h3(append(randomBytes, serializeScalar(secret)...)), nil
+}
+
+// bindingFactors computes the binding factor ρₗ of each signer l, as compute_binding_factors in RFC 9591.
+//
+// The signers must be sorted by their identifiers, which is the canonical order of party.IDSlice.
+func (s Ciphersuite) bindingFactors(Y curve.Point, signers []party.ID, m []byte, D, E map[party.ID]curve.Point) map[party.ID]curve.Scalar {
+	group := Y.Curve()
+
+	// encode_group_commitment_list
+	var commitments []byte
+	for _, l := range signers {
+		commitments = append(commitments, serializeScalar(l.Scalar(group))...)
+		commitments = append(commitments, serializeElement(D[l])...)
+		commitments = append(commitments, serializeElement(E[l])...)
+	}
+
+	prefix := serializeElement(Y)
+	prefix = append(prefix, s.h4(m)...)
+	prefix = append(prefix, s.h5(commitments)...)
+
+	rho := make(map[party.ID]curve.Scalar, len(signers))
+	for _, l := range signers {
+		input := append(append([]byte{}, prefix...), serializeScalar(l.Scalar(group))...)
+		rho[l] = s.h1(input)
+	}
+	return rho
+}
+
+// challenge computes c = H2(R || Y || m), as compute_challenge in RFC 9591.
+func (s Ciphersuite) challenge(R, Y curve.Point, m []byte) curve.Scalar {
+	input := serializeElement(R)
+	input = append(input, serializeElement(Y)...)
+	input = append(input, m...)
+	return s.h2(input)
+}
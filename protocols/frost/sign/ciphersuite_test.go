@@ -0,0 +1,182 @@
+package sign
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/protocols/frost/keygen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fromHex(t *testing.T, s string) []byte {
+	data, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return data
+}
+
+// TestExpandMessageXMD checks expand_message_xmd against the SHA-256 vectors of RFC 9380, appendix K.1.
+func TestExpandMessageXMD(t *testing.T) {
+	dst := []byte("QUUX-V01-CS02-with-expander-SHA256-128")
+	assert.Equal(t, "68a985b87eb6b46952128911f2a4412bbc302a9d759667f87f7a21d803f07235",
+		hex.EncodeToString(expandMessageXMD([]byte(""), dst, 0x20)))
+	assert.Equal(t, "d8ccab23b5985ccea865c6c97b6e5b8350e794e603b4b97902f53a8a0d605615",
+		hex.EncodeToString(expandMessageXMD([]byte("abc"), dst, 0x20)))
+}
+
+// TestCiphersuiteSecp256k1Vectors signs with the FROST(secp256k1, SHA-256) test vectors of RFC 9591, appendix E.5,
+// feeding the signers the randomness of the vectors.
+func TestCiphersuiteSecp256k1Vectors(t *testing.T) {
+	group := curve.Secp256k1{}
+	scalar := func(s string) curve.Scalar {
+		x := group.NewScalar()
+		require.NoError(t, x.UnmarshalBinary(fromHex(t, s)))
+		return x
+	}
+
+	// The group key is shared with f(X) = secret + coefficient⋅X among the identifiers 1, 2 and 3.
+	secret := scalar("0d004150d27c3bf2a42f312683d35fac7394b1e9e318249c1bfe7f0795a83114")
+	coefficient := scalar("fbf85eadae3058ea14f19148bb72b45e4399c0b16028acaf0395c9b03c823579")
+	publicKey := secret.ActOnBase()
+	assert.Equal(t, "02f37c34b66ced1fb51c34a90bdae006901f10625cc06c4f64663b0eae87d87b4f", hex.EncodeToString(serializeElement(publicKey)))
+
+	p1, p3 := party.ID("\x01"), party.ID("\x03")
+	signers := party.NewIDSlice([]party.ID{p1, p3})
+	shares := map[party.ID]curve.Scalar{}
+	verificationShares := map[party.ID]curve.Point{}
+	for _, id := range []party.ID{p1, "\x02", p3} {
+		shares[id] = group.NewScalar().Set(coefficient).Mul(id.Scalar(group)).Add(secret)
+		verificationShares[id] = shares[id].ActOnBase()
+	}
+	assert.Equal(t, "08f89ffe80ac94dcb920c26f3f46140bfc7f95b493f8310f5fc1ea2b01f4254c", hex.EncodeToString(serializeScalar(shares[p1])))
+	assert.Equal(t, "00e95d59dd0d46b0e303e500b62b7ccb0e555d49f5b849f5e748c071da8c0dbc", hex.EncodeToString(serializeScalar(shares[p3])))
+
+	// the hiding and then the binding nonce randomness of each signer
+	randomness := map[party.ID][]byte{
+		p1: fromHex(t, "7ea5ed09af19f6ff21040c07ec2d2adbd35b759da5a401d4c99dd26b82391cb2"+
+			"47acab018f116020c10cb9b9abdc7ac10aae1b48ca6e36dc15acb6ec9be5cdc5"),
+		p3: fromHex(t, "e6cc56ccbd0502b3f6f831d91e2ebd01c4de0479e0191b66895a4ffd9b68d544"+
+			"7203d55eb82a5ca0d7d83674541ab55f6e76f1b85391d2c13706a89a064fd5b9"),
+	}
+	message := fromHex(t, "74657374")
+
+	rounds := make([]round.Session, 0, len(signers))
+	for _, id := range signers {
+		config := &keygen.Config{
+			ID:                 id,
+			Threshold:          1,
+			PublicKey:          publicKey,
+			PrivateShare:       shares[id],
+			VerificationShares: party.NewPointMap(verificationShares),
+		}
+		r, err := startSign(false, CiphersuiteSecp256k1SHA256, config, signers, message, bytes.NewReader(randomness[id]))(nil)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+
+	for {
+		err, _ := test.Rounds(rounds, nil)
+		require.NoError(t, err, "failed to process round")
+		if _, ok := rounds[0].(*round3); ok {
+			break
+		}
+	}
+	r := rounds[0].(*round3)
+	assert.Equal(t, "841d3a6450d7580b4da83c8e618414d0f024391f2aeb511d7579224420aa81f0", hex.EncodeToString(serializeScalar(r.d_i)))
+	assert.Equal(t, "8d2624f532af631377f33cf44b5ac5f849067cae2eacb88680a31e77c79b5a80", hex.EncodeToString(serializeScalar(r.e_i)))
+	assert.Equal(t, "03c699af97d26bb4d3f05232ec5e1938c12f1e6ae97643c8f8f11c9820303f1904", hex.EncodeToString(serializeElement(r.D[p1])))
+	assert.Equal(t, "02fa2aaccd51b948c9dc1a325d77226e98a5a3fe65fe9ba213761a60123040a45e", hex.EncodeToString(serializeElement(r.E[p1])))
+
+	rho := CiphersuiteSecp256k1SHA256.bindingFactors(publicKey, signers, message, r.D, r.E)
+	assert.Equal(t, "3e08fe561e075c653cbfd46908a10e7637c70c74f0a77d5fd45d1a750c739ec6", hex.EncodeToString(serializeScalar(rho[p1])))
+	assert.Equal(t, "93f79041bb3fd266105be251adaeb5fd7f8b104fb554a4ba9a0becea48ddbfd7", hex.EncodeToString(serializeScalar(rho[p3])))
+
+	for _, s := range rounds {
+		z := s.(*round3).z
+		assert.Equal(t, "c4fce1775a1e141fb579944166eab0d65eefe7b98d480a569bbbfcb14f91c197", hex.EncodeToString(serializeScalar(z[p1])))
+		assert.Equal(t, "0160fd0d388932f4826d2ebcd6b9eaba734f7c71cf25b4279a4ca2581e47b18d", hex.EncodeToString(serializeScalar(z[p3])))
+	}
+
+	for {
+		err, done := test.Rounds(rounds, nil)
+		require.NoError(t, err, "failed to process round")
+		if done {
+			break
+		}
+	}
+	for _, s := range rounds {
+		require.IsType(t, &round.Output{}, s)
+		sig := s.(*round.Output).Result.(Signature)
+		data, err := sig.MarshalBinary()
+		require.NoError(t, err)
+		assert.Equal(t, "0205b6d04d3774c8929413e3c76024d54149c372d57aae62574ed74319b5ea14d0"+
+			"c65dde8492a7471437e6c2fe3da49b90d23f642b5c6dbe7e36089f096dd97324", hex.EncodeToString(data))
+		assert.True(t, sig.VerifyCiphersuite(CiphersuiteSecp256k1SHA256, publicKey, message))
+		assert.False(t, sig.Verify(publicKey, message), "the default suite has another challenge")
+	}
+}
+
+// TestCiphersuiteEd25519 signs with FROST(Ed25519, SHA-512), whose signatures crypto/ed25519 accepts.
+func TestCiphersuiteEd25519(t *testing.T) {
+	group := curve.Ed25519{}
+	N := 4
+	threshold := 2
+	partyIDs := test.PartyIDs(N)
+
+	rounds := make([]round.Session, 0, N)
+	for _, id := range partyIDs {
+		r, err := keygen.StartKeygenCommon(false, group, partyIDs, threshold, id, nil, nil, nil)(nil)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+	for {
+		err, done := test.Rounds(rounds, nil)
+		require.NoError(t, err, "failed to process round")
+		if done {
+			break
+		}
+	}
+	configs := make(map[party.ID]*keygen.Config, N)
+	for _, r := range rounds {
+		configs[r.SelfID()] = r.(*round.Output).Result.(*keygen.Config)
+	}
+	publicKey, err := configs[partyIDs[0]].PublicKey.MarshalBinary()
+	require.NoError(t, err)
+
+	message := []byte("interoperable threshold signature")
+	signers := partyIDs[1:]
+	rounds = rounds[:0]
+	for _, id := range signers {
+		r, err := StartSignCiphersuite(CiphersuiteEd25519SHA512, configs[id], signers, message)(nil)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+	for {
+		err, done := test.Rounds(rounds, nil)
+		require.NoError(t, err, "failed to process round")
+		if done {
+			break
+		}
+	}
+	for _, r := range rounds {
+		sig := r.(*round.Output).Result.(Signature)
+		data, err := sig.MarshalBinary()
+		require.NoError(t, err)
+		assert.True(t, ed25519.Verify(publicKey, message, data), "crypto/ed25519 rejected the signature")
+		assert.True(t, sig.VerifyCiphersuite(CiphersuiteEd25519SHA512, configs[partyIDs[0]].PublicKey, message))
+	}
+}
+
+func TestCiphersuiteWrongCurve(t *testing.T) {
+	config := &keygen.Config{ID: "a", PublicKey: curve.Ed25519{}.NewBasePoint()}
+	_, err := StartSignCiphersuite(CiphersuiteSecp256k1SHA256, config, []party.ID{"a"}, []byte("m"))(nil)
+	assert.Error(t, err)
+	_, err = StartSignCiphersuite(Ciphersuite(7), config, []party.ID{"a"}, []byte("m"))(nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,72 @@
+package sign
+
+import (
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/math/polynomial"
+	"github.com/luxfi/threshold/pkg/party"
+)
+
+// onlineRound1 computes our response right away, as in round2, since the commitments
+// of every signer were already exchanged by the Precommit session.
+type onlineRound1 struct {
+	*round.Helper
+	// M is the hash of the message we're signing.
+	M messageHash
+	// Y is the public key we're signing for.
+	Y curve.Point
+	// YShares are verification shares for each participant's fraction of the secret key
+	YShares map[party.ID]curve.Point
+	// sI = sᵢ is our private secret share
+	sI curve.Scalar
+	// precommit holds our nonces, and the commitments of every signer.
+	precommit *Precommitment
+}
+
+// VerifyMessage implements round.Round.
+func (r *onlineRound1) VerifyMessage(round.Message) error { return nil }
+
+// StoreMessage implements round.Round.
+func (r *onlineRound1) StoreMessage(round.Message) error { return nil }
+
+// Finalize implements round.Round.
+//
+// This follows the steps of round2.Finalize, with the commitments of the precommitment.
+func (r *onlineRound1) Finalize(out chan<- *round.Message) (round.Session, error) {
+	Lambdas := polynomial.Lagrange(r.Group(), r.PartyIDs())
+
+	D, E := r.precommit.D, r.precommit.E
+	rho := bindingFactors(r.Group(), r.M, r.PartyIDs(), D, E)
+	R, RShares := groupCommitment(r.Group(), r.PartyIDs(), rho, D, E)
+	c := challenge(r.Group(), R, r.Y, r.M)
+
+	// zᵢ = dᵢ + (eᵢ ρᵢ) + λᵢ sᵢ c
+	nonces := r.precommit.commitment
+	zI := r.Group().NewScalar().Set(Lambdas[r.SelfID()]).Mul(r.sI).Mul(c)
+	zI.Add(nonces.d)
+	ed := r.Group().NewScalar().Set(rho[r.SelfID()]).Mul(nonces.e)
+	zI.Add(ed)
+
+	// The nonces are never needed again, so we overwrite them.
+	nonces.d.Set(r.Group().NewScalar())
+	nonces.e.Set(r.Group().NewScalar())
+
+	// Broadcast our response
+	if err := r.BroadcastMessage(out, &onlineBroadcast2{Z_i: zI}); err != nil {
+		return r, err
+	}
+	return &onlineRound2{
+		onlineRound1: r,
+		R:            R,
+		RShares:      RShares,
+		c:            c,
+		z:            map[party.ID]curve.Scalar{r.SelfID(): zI},
+		Lambda:       Lambdas,
+	}, nil
+}
+
+// MessageContent implements round.Round.
+func (onlineRound1) MessageContent() round.Content { return nil }
+
+// Number implements round.Round.
+func (onlineRound1) Number() round.Number { return 1 }
@@ -0,0 +1,85 @@
+package sign
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+)
+
+// onlineRound2 verifies the responses, and aggregates them into a signature, as in round3.
+type onlineRound2 struct {
+	*onlineRound1
+	// R is the group commitment.
+	R curve.Point
+	// RShares[l] is the contribution of party l to R.
+	RShares map[party.ID]curve.Point
+	// c is the challenge.
+	c curve.Scalar
+	// z[l] = zₗ is the response of party l.
+	z map[party.ID]curve.Scalar
+	// Lambda[l] = λₗ
+	Lambda map[party.ID]curve.Scalar
+}
+
+type onlineBroadcast2 struct {
+	round.NormalBroadcastContent
+	// Z_i is the response computed by the sender.
+	Z_i curve.Scalar
+}
+
+// StoreBroadcastMessage implements round.BroadcastRound.
+func (r *onlineRound2) StoreBroadcastMessage(msg round.Message) error {
+	from := msg.From
+	body, ok := msg.Content.(*onlineBroadcast2)
+	if !ok || body == nil {
+		return round.ErrInvalidContent
+	}
+	if body.Z_i == nil {
+		return round.ErrNilFields
+	}
+
+	// zᵢ • G = Rᵢ + c * λᵢ * Yᵢ
+	expected := r.c.Act(r.Lambda[from].Act(r.YShares[from])).Add(r.RShares[from])
+	if !body.Z_i.ActOnBase().Equal(expected) {
+		return fmt.Errorf("failed to verify response from %v", from)
+	}
+
+	r.z[from] = body.Z_i
+	return nil
+}
+
+// VerifyMessage implements round.Round.
+func (onlineRound2) VerifyMessage(round.Message) error { return nil }
+
+// StoreMessage implements round.Round.
+func (onlineRound2) StoreMessage(round.Message) error { return nil }
+
+// Finalize implements round.Round.
+func (r *onlineRound2) Finalize(chan<- *round.Message) (round.Session, error) {
+	z := r.Group().NewScalar()
+	for _, zL := range r.z {
+		z.Add(zL)
+	}
+	sig := Signature{R: r.R, z: z}
+	if !sig.Verify(r.Y, r.M) {
+		return r.AbortRound(errors.New("generated signature failed to verify")), nil
+	}
+	return r.ResultRound(sig), nil
+}
+
+// MessageContent implements round.Round.
+func (onlineRound2) MessageContent() round.Content { return nil }
+
+// RoundNumber implements round.Content.
+func (onlineBroadcast2) RoundNumber() round.Number { return 2 }
+
+// BroadcastContent implements round.BroadcastRound.
+func (r *onlineRound2) BroadcastContent() round.BroadcastContent {
+	return &onlineBroadcast2{Z_i: r.Group().NewScalar()}
+}
+
+// Number implements round.Round.
+func (onlineRound2) Number() round.Number { return 2 }
@@ -0,0 +1,136 @@
+package sign
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/internal/types"
+	"github.com/luxfi/threshold/pkg/hash"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/protocol"
+	"github.com/luxfi/threshold/protocols/frost/keygen"
+)
+
+const (
+	// Frost exchange of nonce commitments ahead of signing.
+	protocolIDPrecommit = "frost/precommit"
+	// This protocol has 2 concrete rounds.
+	protocolRoundsPrecommit round.Number = 2
+
+	// Frost Sign with nonce commitments exchanged by Precommit.
+	protocolIDOnline = "frost/sign-online"
+	// This protocol has 2 concrete rounds, and a single one of communication.
+	protocolRoundsOnline round.Number = 2
+)
+
+// Precommitment is the result of a Precommit session: the nonces of a party for a single signature,
+// along with the commitments every signer of the session published for theirs.
+//
+// This is the Schnorr counterpart of the presignatures of CMP. Since the commitments are already known,
+// SignOnline only has to exchange the responses, in a single round.
+//
+// A Precommitment signs exactly one message, with exactly the signers it was committed with.
+// It only lives in memory: the nonces must never be stored, since restoring them twice would allow
+// them to be used for two messages, which reveals the private share.
+type Precommitment struct {
+	// ID is the party holding the nonces.
+	ID party.ID
+	// Signers are the parties which committed together, and must sign together.
+	Signers party.IDSlice
+
+	// D[l] = Dₗ and E[l] = Eₗ are the commitments of party l, ourself included.
+	D, E map[party.ID]curve.Point
+	// commitment holds our own nonces.
+	commitment *Commitment
+	// binding is a digest of the Precommit session and of all its commitments,
+	// so that only the parties holding the same commitments can sign together.
+	binding []byte
+}
+
+// Used returns true if this precommitment was already consumed by SignOnline.
+func (p *Precommitment) Used() bool {
+	return p.commitment.Used()
+}
+
+// StartPrecommit starts a session in which signers generate nonces for a later signature, and publish their commitments.
+//
+// Each party obtains a *Precommitment, to be consumed by StartSignOnline once the message is known.
+func StartPrecommit(config *keygen.Config, signers []party.ID) protocol.StartFunc {
+	return func(sessionID []byte) (round.Session, error) {
+		if len(signers) < config.Threshold+1 {
+			return nil, fmt.Errorf("sign.StartPrecommit: need at least %d signers, got %d", config.Threshold+1, len(signers))
+		}
+		for _, id := range signers {
+			if _, ok := config.VerificationShares.Points[id]; !ok {
+				return nil, fmt.Errorf("sign.StartPrecommit: signer %s has no verification share", id)
+			}
+		}
+		info := round.Info{
+			ProtocolID:       protocolIDPrecommit,
+			FinalRoundNumber: protocolRoundsPrecommit,
+			SelfID:           config.ID,
+			PartyIDs:         signers,
+			Threshold:        config.Threshold,
+			Group:            config.PublicKey.Curve(),
+			RequireSessionID: true,
+		}
+		helper, err := round.NewSession(info, sessionID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("sign.StartPrecommit: %w", err)
+		}
+		commitments, err := NewCommitments(config, 1, nil)
+		if err != nil {
+			return nil, fmt.Errorf("sign.StartPrecommit: %w", err)
+		}
+		return &precommitRound1{
+			Helper:     helper,
+			commitment: commitments[0],
+		}, nil
+	}
+}
+
+// StartSignOnline signs messageHash with the nonces of precommit, in a single round of communication.
+//
+// All the signers of precommit must take part, each with the precommitment it obtained from the same Precommit session.
+// The precommitment is marked as used before the session is created, even if creating the session fails afterwards,
+// so that its nonces can never end up being used twice.
+func StartSignOnline(config *keygen.Config, precommit *Precommitment, messageHash []byte) protocol.StartFunc {
+	return func(sessionID []byte) (round.Session, error) {
+		if precommit == nil || precommit.commitment == nil {
+			return nil, errors.New("sign.StartSignOnline: precommitment is empty")
+		}
+		if precommit.ID != config.ID {
+			return nil, fmt.Errorf("sign.StartSignOnline: precommitment belongs to %s, not %s", precommit.ID, config.ID)
+		}
+		if !precommit.commitment.used.CompareAndSwap(false, true) {
+			return nil, errors.New("sign.StartSignOnline: precommitment was already used")
+		}
+
+		info := round.Info{
+			ProtocolID:       protocolIDOnline,
+			FinalRoundNumber: protocolRoundsOnline,
+			SelfID:           config.ID,
+			PartyIDs:         precommit.Signers,
+			Threshold:        config.Threshold,
+			Group:            config.PublicKey.Curve(),
+			RequireSessionID: true,
+		}
+		helper, err := round.NewSession(info, sessionID, nil, types.SigningMessage(messageHash), &hash.BytesWithDomain{
+			TheDomain: "Precommitment",
+			Bytes:     precommit.binding,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("sign.StartSignOnline: %w", err)
+		}
+		return &onlineRound1{
+			Helper:    helper,
+			M:         messageHash,
+			Y:         config.PublicKey,
+			YShares:   config.VerificationShares.Points,
+			sI:        config.PrivateShare,
+			precommit: precommit,
+		}, nil
+	}
+}
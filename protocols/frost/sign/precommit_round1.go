@@ -0,0 +1,40 @@
+package sign
+
+import (
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+)
+
+// precommitRound1 publishes the commitments of nonces generated by NewCommitments,
+// as the first round of the signing protocol does.
+type precommitRound1 struct {
+	*round.Helper
+	// commitment holds our nonces.
+	commitment *Commitment
+}
+
+// VerifyMessage implements round.Round.
+func (r *precommitRound1) VerifyMessage(round.Message) error { return nil }
+
+// StoreMessage implements round.Round.
+func (r *precommitRound1) StoreMessage(round.Message) error { return nil }
+
+// Finalize implements round.Round.
+func (r *precommitRound1) Finalize(out chan<- *round.Message) (round.Session, error) {
+	// Broadcast the commitments
+	if err := r.BroadcastMessage(out, &precommitBroadcast2{D_i: r.commitment.D, E_i: r.commitment.E}); err != nil {
+		return r, err
+	}
+	return &precommitRound2{
+		precommitRound1: r,
+		D:               map[party.ID]curve.Point{r.SelfID(): r.commitment.D},
+		E:               map[party.ID]curve.Point{r.SelfID(): r.commitment.E},
+	}, nil
+}
+
+// MessageContent implements round.Round.
+func (precommitRound1) MessageContent() round.Content { return nil }
+
+// Number implements round.Round.
+func (precommitRound1) Number() round.Number { return 1 }
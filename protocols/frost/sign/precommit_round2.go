@@ -0,0 +1,87 @@
+package sign
+
+import (
+	"errors"
+
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+)
+
+// precommitRound2 collects the commitments of the other signers into a Precommitment.
+type precommitRound2 struct {
+	*precommitRound1
+	// D[l] = Dₗ is the first commitment of party l, ourself included.
+	D map[party.ID]curve.Point
+	// E[l] = Eₗ is the second commitment of party l, ourself included.
+	E map[party.ID]curve.Point
+}
+
+type precommitBroadcast2 struct {
+	// The commitments are reliably broadcast, so that all signers agree on them before signing.
+	round.ReliableBroadcastContent
+	// D_i is the first commitment produced by the sender.
+	D_i curve.Point
+	// E_i is the second commitment produced by the sender.
+	E_i curve.Point
+}
+
+// StoreBroadcastMessage implements round.BroadcastRound.
+func (r *precommitRound2) StoreBroadcastMessage(msg round.Message) error {
+	body, ok := msg.Content.(*precommitBroadcast2)
+	if !ok || body == nil {
+		return round.ErrInvalidContent
+	}
+	if body.D_i == nil || body.E_i == nil {
+		return round.ErrNilFields
+	}
+	if body.D_i.IsIdentity() || body.E_i.IsIdentity() {
+		return errors.New("nonce commitment is the identity point")
+	}
+
+	r.D[msg.From] = body.D_i
+	r.E[msg.From] = body.E_i
+	return nil
+}
+
+// VerifyMessage implements round.Round.
+func (precommitRound2) VerifyMessage(round.Message) error { return nil }
+
+// StoreMessage implements round.Round.
+func (precommitRound2) StoreMessage(round.Message) error { return nil }
+
+// Finalize implements round.Round.
+func (r *precommitRound2) Finalize(chan<- *round.Message) (round.Session, error) {
+	// The binding covers the session, which includes the signers, and every commitment.
+	h := r.Hash()
+	for _, l := range r.PartyIDs() {
+		if err := h.WriteAny(r.D[l], r.E[l]); err != nil {
+			return r, err
+		}
+	}
+	return r.ResultRound(&Precommitment{
+		ID:         r.SelfID(),
+		Signers:    r.PartyIDs().Copy(),
+		D:          r.D,
+		E:          r.E,
+		commitment: r.commitment,
+		binding:    h.Sum(),
+	}), nil
+}
+
+// MessageContent implements round.Round.
+func (precommitRound2) MessageContent() round.Content { return nil }
+
+// RoundNumber implements round.Content.
+func (precommitBroadcast2) RoundNumber() round.Number { return 2 }
+
+// BroadcastContent implements round.BroadcastRound.
+func (r *precommitRound2) BroadcastContent() round.BroadcastContent {
+	return &precommitBroadcast2{
+		D_i: r.Group().NewPoint(),
+		E_i: r.Group().NewPoint(),
+	}
+}
+
+// Number implements round.Round.
+func (precommitRound2) Number() round.Number { return 2 }
@@ -0,0 +1,146 @@
+package sign
+
+import (
+	"testing"
+
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/protocols/frost/keygen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func precommitSigners(t testing.TB, configs map[party.ID]*keygen.Config, signers []party.ID) map[party.ID]*Precommitment {
+	rounds := make([]round.Session, 0, len(signers))
+	for _, id := range signers {
+		r, err := StartPrecommit(configs[id], signers)(nil)
+		require.NoError(t, err, "round creation should not result in an error")
+		rounds = append(rounds, r)
+	}
+	require.NoError(t, test.RunRounds(rounds, nil))
+
+	precommits := make(map[party.ID]*Precommitment, len(signers))
+	for _, r := range rounds {
+		require.IsType(t, &round.Output{}, r, "expected result round")
+		precommit, ok := r.(*round.Output).Result.(*Precommitment)
+		require.True(t, ok, "expected *Precommitment result")
+		precommits[precommit.ID] = precommit
+	}
+	return precommits
+}
+
+func signOnlineRounds(t testing.TB, configs map[party.ID]*keygen.Config, precommits map[party.ID]*Precommitment, m []byte) []round.Session {
+	rounds := make([]round.Session, 0, len(precommits))
+	for id, precommit := range precommits {
+		r, err := StartSignOnline(configs[id], precommit, m)(nil)
+		require.NoError(t, err, "round creation should not result in an error")
+		rounds = append(rounds, r)
+	}
+	return rounds
+}
+
+func TestSignOnline(t *testing.T) {
+	for _, group := range []curve.Curve{curve.Secp256k1{}, curve.Ed25519{}} {
+		t.Run(group.Name(), func(t *testing.T) {
+			partyIDs := test.PartyIDs(5)
+			configs := batchConfigs(group, partyIDs, 2)
+			signers := partyIDs[:3]
+			public := configs[partyIDs[0]].PublicKey
+			m := []byte("hello")
+
+			precommits := precommitSigners(t, configs, signers)
+			for _, precommit := range precommits {
+				assert.Equal(t, party.NewIDSlice(signers), precommit.Signers)
+				assert.False(t, precommit.Used())
+			}
+
+			rounds := signOnlineRounds(t, configs, precommits, m)
+			require.NoError(t, test.RunRounds(rounds, nil))
+			checkOutput(t, rounds, public, m)
+			for _, precommit := range precommits {
+				assert.True(t, precommit.Used())
+			}
+		})
+	}
+}
+
+func TestSignOnlineSingleUse(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(3)
+	configs := batchConfigs(group, partyIDs, 1)
+	signers := partyIDs[:2]
+
+	precommits := precommitSigners(t, configs, signers)
+	a, b := signers[0], signers[1]
+
+	// a precommitment belongs to the party which committed
+	_, err := StartSignOnline(configs[b], precommits[a], []byte("hello"))(nil)
+	assert.Error(t, err)
+	_, err = StartSignOnline(configs[a], nil, []byte("hello"))(nil)
+	assert.Error(t, err)
+
+	// a precommitment signs a single message
+	_, err = StartSignOnline(configs[a], precommits[a], []byte("hello"))(nil)
+	require.NoError(t, err)
+	_, err = StartSignOnline(configs[a], precommits[a], []byte("goodbye"))(nil)
+	assert.Error(t, err)
+
+	// the commitments are bound to the set of signers
+	_, err = StartPrecommit(configs[a], signers[:1])(nil)
+	assert.Error(t, err)
+	_, err = StartPrecommit(configs[a], []party.ID{a, "unknown"})(nil)
+	assert.Error(t, err)
+}
+
+func TestSignOnlineMismatchedPrecommit(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(3)
+	configs := batchConfigs(group, partyIDs, 1)
+	signers := partyIDs[:2]
+
+	precommits := precommitSigners(t, configs, signers)
+	other := precommitSigners(t, configs, signers)
+	a := signers[0]
+	assert.NotEqual(t, precommits[a].binding, other[a].binding)
+
+	// a signs with the nonces of another Precommit session, so its response doesn't match the commitments of the others
+	precommits[a] = other[a]
+	rounds := signOnlineRounds(t, configs, precommits, []byte("hello"))
+	var err error
+	for done := false; !done && err == nil; {
+		err, done = test.Rounds(rounds, nil)
+	}
+	assert.Error(t, err)
+}
+
+func BenchmarkSignOnline(b *testing.B) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(5)
+	configs := batchConfigs(group, partyIDs, 2)
+	signers := partyIDs[:3]
+	m := []byte("hello")
+
+	b.Run("full", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			rounds := make([]round.Session, 0, len(signers))
+			for _, id := range signers {
+				r, err := StartSignCommon(false, configs[id], signers, m)(nil)
+				require.NoError(b, err)
+				rounds = append(rounds, r)
+			}
+			require.NoError(b, test.RunRounds(rounds, nil))
+		}
+	})
+
+	// only the online phase is timed, the precommitments are made beforehand
+	b.Run("precommitted", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			precommits := precommitSigners(b, configs, signers)
+			b.StartTimer()
+			require.NoError(b, test.RunRounds(signOnlineRounds(b, configs, precommits, m), nil))
+		}
+	})
+}
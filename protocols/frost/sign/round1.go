@@ -1,7 +1,7 @@
 package sign
 
 import (
-	"crypto/rand"
+	"io"
 
 	"github.com/luxfi/threshold/internal/round"
 	"github.com/luxfi/threshold/pkg/math/curve"
@@ -28,6 +28,10 @@ type round1 struct {
 	// and we need to make sure to generate our challenge in the correct way. Naturally,
 	// we also return a taproot.Signature instead a generic signature.
 	taproot bool
+	// suite is the RFC 9591 ciphersuite we sign with, or CiphersuiteDefault.
+	suite Ciphersuite
+	// rand is the source of the randomness of our nonces.
+	rand io.Reader
 	// M is the hash of the message we're signing.
 	//
 	// This plays the same role as m in the Frost paper. One slight difference
@@ -57,6 +61,10 @@ func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
 	// to generate two nonces (dᵢ, eᵢ) in Z/(q)ˣ, then two commitments
 	// Dᵢ = dᵢ * G, Eᵢ = eᵢ * G, and then broadcast them.
 
+	if r.suite != CiphersuiteDefault {
+		return r.commitCiphersuite(out)
+	}
+
 	// We use a hedged deterministic process, instead of simply sampling (dI, eI):
 	//
 	//   a = random()
@@ -76,18 +84,36 @@ func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
 	_, _ = nonceHasher.Write(r.Hash().Sum())
 	_, _ = nonceHasher.Write(r.M)
 	a := make([]byte, 32)
-	_, _ = rand.Read(a)
+	_, _ = io.ReadFull(r.rand, a)
 	_, _ = nonceHasher.Write(a)
 	nonceDigest := nonceHasher.Digest()
 
 	dI := sample.ScalarUnit(nonceDigest, r.Group())
 	eI := sample.ScalarUnit(nonceDigest, r.Group())
+	return r.commit(out, dI, eI)
+}
+
+// commitCiphersuite samples the hiding nonce dᵢ and the binding nonce eᵢ as in the commit step of RFC 9591,
+// from fresh randomness and our secret share.
+func (r *round1) commitCiphersuite(out chan<- *round.Message) (round.Session, error) {
+	dI, err := r.suite.nonceGenerate(r.sI, r.rand)
+	if err != nil {
+		return r, err
+	}
+	eI, err := r.suite.nonceGenerate(r.sI, r.rand)
+	if err != nil {
+		return r, err
+	}
+	return r.commit(out, dI, eI)
+}
 
+// commit broadcasts the commitments Dᵢ = dᵢ * G, Eᵢ = eᵢ * G to our nonces, and moves on to the next round.
+func (r *round1) commit(out chan<- *round.Message, dI, eI curve.Scalar) (round.Session, error) {
 	DI := dI.ActOnBase()
 	EI := eI.ActOnBase()
 
 	// Broadcast the commitments
-	err = r.BroadcastMessage(out, &broadcast2{D_i: DI, E_i: EI})
+	err := r.BroadcastMessage(out, &broadcast2{D_i: DI, E_i: EI})
 	if err != nil {
 		return r, err
 	}
@@ -1,6 +1,7 @@
 package sign
 
 import (
+	"crypto/sha512"
 	"fmt"
 
 	"github.com/cronokirby/saferith"
@@ -87,34 +88,19 @@ func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
 	// Each Pᵢ then derives the group commitment R = ∑ₗ Dₗ + ρₗ * Eₗ and
 	// the challenge c = H₂(R, Y, m)."
 	//
-	// It's easier to calculate H(m, B, l), that way we can simply clone the hash
-	// state after H(m, B), instead of rehashing them each time.
-	//
 	// We also use a hash of the message, instead of the message directly.
 
-	rho := make(map[party.ID]curve.Scalar)
-	// This calculates H(m, B), allowing us to avoid re-hashing this data for
-	// each extra party l.
-	rhoPreHash := hash.New()
-	_ = rhoPreHash.WriteAny(r.M)
-	for _, l := range r.PartyIDs() {
-		_ = rhoPreHash.WriteAny(r.D[l], r.E[l])
-	}
-	for _, l := range r.PartyIDs() {
-		rhoHash := rhoPreHash.Clone()
-		_ = rhoHash.WriteAny(l)
-		rho[l] = sample.Scalar(rhoHash.Digest(), r.Group())
-	}
-
-	R := r.Group().NewPoint()
-	RShares := make(map[party.ID]curve.Point)
-	for _, l := range r.PartyIDs() {
-		RShares[l] = rho[l].Act(r.E[l])
-		RShares[l] = RShares[l].Add(r.D[l])
-		R = R.Add(RShares[l])
+	var rho map[party.ID]curve.Scalar
+	if r.suite != CiphersuiteDefault {
+		rho = r.suite.bindingFactors(r.Y, r.PartyIDs(), r.M, r.D, r.E)
+	} else {
+		rho = bindingFactors(r.Group(), r.M, r.PartyIDs(), r.D, r.E)
 	}
+	R, RShares := groupCommitment(r.Group(), r.PartyIDs(), rho, r.D, r.E)
 	var c curve.Scalar
-	if r.taproot {
+	if r.suite != CiphersuiteDefault {
+		c = r.suite.challenge(R, r.Y, r.M)
+	} else if r.taproot {
 		// BIP-340 adjustment: We need R to have an even y coordinate. This means
 		// conditionally negating k = ∑ᵢ (dᵢ + (eᵢ ρᵢ)), which we can accomplish
 		// by negating our dᵢ, eᵢ, if necessary. This entails negating the RShares
@@ -135,9 +121,7 @@ func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
 		cHash := taproot.TaggedHash("BIP0340/challenge", RBytes, PBytes, r.M)
 		c = r.Group().NewScalar().SetNat(new(saferith.Nat).SetBytes(cHash))
 	} else {
-		cHash := hash.New()
-		_ = cHash.WriteAny(R, r.Y, r.M)
-		c = sample.Scalar(cHash.Digest(), r.Group())
+		c = challenge(r.Group(), R, r.Y, r.M)
 	}
 
 	// Lambdas[i] = λᵢ
@@ -189,3 +173,56 @@ func (r *round2) BroadcastContent() round.BroadcastContent {
 
 // Number implements round.Round.
 func (round2) Number() round.Number { return 2 }
+
+// bindingFactors computes the binding value ρₗ = H(m, B, l) for each signer l,
+// where B contains the commitments (Dₗ, Eₗ) of every signer.
+//
+// It's easier to calculate H(m, B, l), that way we can simply clone the hash
+// state after H(m, B), instead of rehashing them each time.
+func bindingFactors(group curve.Curve, m messageHash, signers []party.ID, D, E map[party.ID]curve.Point) map[party.ID]curve.Scalar {
+	rho := make(map[party.ID]curve.Scalar, len(signers))
+	// This calculates H(m, B), allowing us to avoid re-hashing this data for
+	// each extra party l.
+	rhoPreHash := hash.New()
+	_ = rhoPreHash.WriteAny(m)
+	for _, l := range signers {
+		_ = rhoPreHash.WriteAny(D[l], E[l])
+	}
+	for _, l := range signers {
+		rhoHash := rhoPreHash.Clone()
+		_ = rhoHash.WriteAny(l)
+		rho[l] = sample.Scalar(rhoHash.Digest(), group)
+	}
+	return rho
+}
+
+// groupCommitment computes R = ∑ₗ Dₗ + ρₗ * Eₗ, along with the contribution Rₗ of each signer.
+func groupCommitment(group curve.Curve, signers []party.ID, rho map[party.ID]curve.Scalar, D, E map[party.ID]curve.Point) (curve.Point, map[party.ID]curve.Point) {
+	R := group.NewPoint()
+	RShares := make(map[party.ID]curve.Point, len(signers))
+	for _, l := range signers {
+		RShares[l] = rho[l].Act(E[l])
+		RShares[l] = RShares[l].Add(D[l])
+		R = R.Add(RShares[l])
+	}
+	return R, RShares
+}
+
+// challenge computes c = H(R, Y, m).
+//
+// On Ed25519, H is SHA-512 over the encodings of R and Y followed by m, as in RFC 8032,
+// so that the resulting signatures can be checked by any Ed25519 verifier.
+func challenge(group curve.Curve, R, Y curve.Point, m messageHash) curve.Scalar {
+	if _, ok := group.(curve.Ed25519); ok {
+		RBytes, _ := R.MarshalBinary()
+		YBytes, _ := Y.MarshalBinary()
+		cHash := sha512.New()
+		_, _ = cHash.Write(RBytes)
+		_, _ = cHash.Write(YBytes)
+		_, _ = cHash.Write(m)
+		return new(curve.Ed25519Scalar).SetUniformBytesLE(cHash.Sum(nil))
+	}
+	cHash := hash.New()
+	_ = cHash.WriteAny(R, Y, m)
+	return sample.Scalar(cHash.Digest(), group)
+}
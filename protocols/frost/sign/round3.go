@@ -118,7 +118,7 @@ func (r *round3) Finalize(chan<- *round.Message) (round.Session, error) {
 			z: z,
 		}
 
-		if !sig.Verify(r.Y, r.M) {
+		if !sig.VerifyCiphersuite(r.suite, r.Y, r.M) {
 			return r.AbortRound(fmt.Errorf("generated signature failed to verify")), nil
 		}
 
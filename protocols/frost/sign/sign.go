@@ -1,9 +1,12 @@
 package sign
 
 import (
+	"crypto/rand"
 	"fmt"
+	"io"
 
 	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/internal/types"
 	"github.com/luxfi/threshold/pkg/party"
 	"github.com/luxfi/threshold/pkg/protocol"
 	"github.com/luxfi/threshold/protocols/frost/keygen"
@@ -13,32 +16,59 @@ const (
 	// Frost Sign with Threshold.
 	protocolID        = "frost/sign-threshold"
 	protocolIDTaproot = "frost/sign-threshold-taproot"
+	// Frost Sign with an RFC 9591 ciphersuite, followed by its context string.
+	protocolIDCiphersuite = "frost/sign-threshold-rfc9591/"
 	// This protocol has 3 concrete rounds.
 	protocolRounds round.Number = 3
 )
 
 func StartSignCommon(taproot bool, result *keygen.Config, signers []party.ID, messageHash []byte) protocol.StartFunc {
+	return startSign(taproot, CiphersuiteDefault, result, signers, messageHash, rand.Reader)
+}
+
+// StartSignCiphersuite signs message with one of the ciphersuites of RFC 9591,
+// so that the other signers can use any implementation of the RFC.
+//
+// As in the RFC, message is signed as is, and not hashed first.
+func StartSignCiphersuite(suite Ciphersuite, result *keygen.Config, signers []party.ID, message []byte) protocol.StartFunc {
+	return startSign(false, suite, result, signers, message, rand.Reader)
+}
+
+// startSign starts a signing session, which samples the randomness of its nonces from rand.
+func startSign(taproot bool, suite Ciphersuite, result *keygen.Config, signers []party.ID, messageHash []byte, rand io.Reader) protocol.StartFunc {
 	return func(sessionID []byte) (round.Session, error) {
+		if err := suite.validate(result.PublicKey.Curve()); err != nil {
+			return nil, fmt.Errorf("sign.StartSign: %w", err)
+		}
+		if len(signers) < result.Threshold+1 {
+			return nil, fmt.Errorf("sign.StartSign: need at least %d signers, got %d", result.Threshold+1, len(signers))
+		}
 		info := round.Info{
 			FinalRoundNumber: protocolRounds,
 			SelfID:           result.ID,
 			PartyIDs:         signers,
 			Threshold:        result.Threshold,
 			Group:            result.PublicKey.Curve(),
+			RequireSessionID: true,
 		}
-		if taproot {
+		switch {
+		case taproot:
 			info.ProtocolID = protocolIDTaproot
-		} else {
+		case suite != CiphersuiteDefault:
+			info.ProtocolID = protocolIDCiphersuite + suite.String()
+		default:
 			info.ProtocolID = protocolID
 		}
 
-		helper, err := round.NewSession(info, sessionID, nil)
+		helper, err := round.NewSession(info, sessionID, nil, types.SigningMessage(messageHash))
 		if err != nil {
 			return nil, fmt.Errorf("sign.StartSign: %w", err)
 		}
 		return &round1{
 			Helper:  helper,
 			taproot: taproot,
+			suite:   suite,
+			rand:    rand,
 			M:       messageHash,
 			Y:       result.PublicKey,
 			YShares: result.VerificationShares.Points,
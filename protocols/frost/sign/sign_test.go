@@ -1,6 +1,7 @@
 package sign
 
 import (
+	"crypto/ed25519"
 	"crypto/rand"
 	"crypto/sha256"
 	"testing"
@@ -28,6 +29,32 @@ func checkOutput(t *testing.T, rounds []round.Session, public curve.Point, m []b
 	}
 }
 
+func TestVerifyAll(t *testing.T) {
+	group := curve.Secp256k1{}
+	secret := sample.Scalar(rand.Reader, group)
+	public := secret.ActOnBase()
+	m := []byte{0xDE, 0xAD, 0xBE, 0xEF}
+
+	// a single signer's Schnorr signature, with the challenge of this package
+	schnorr := func() Signature {
+		k := sample.Scalar(rand.Reader, group)
+		R := k.ActOnBase()
+		c := challenge(group, R, public, messageHash(m))
+		return Signature{R: R, z: c.Mul(secret).Add(k)}
+	}
+	sig := schnorr()
+	require.True(t, sig.Verify(public, m))
+
+	ok, failed := VerifyAll([]Signature{sig, sig, sig}, public, m)
+	assert.True(t, ok)
+	assert.Empty(t, failed)
+
+	corrupted := Signature{R: sig.R, z: group.NewScalar().Set(sig.z).Negate()}
+	ok, failed = VerifyAll([]Signature{sig, sig, corrupted, schnorr()}, public, m)
+	assert.False(t, ok)
+	assert.Equal(t, []int{2, 3}, failed, "the corrupted and the divergent signatures are flagged")
+}
+
 func TestSign(t *testing.T) {
 	group := curve.Secp256k1{}
 
@@ -84,6 +111,70 @@ func TestSign(t *testing.T) {
 	checkOutput(t, rounds, newPublicKey, steak)
 }
 
+// TestSignEd25519 runs keygen and signing on Ed25519, and checks the result with crypto/ed25519.
+func TestSignEd25519(t *testing.T) {
+	group := curve.Ed25519{}
+	N := 5
+	threshold := 2
+	partyIDs := test.PartyIDs(N)
+
+	rounds := make([]round.Session, 0, N)
+	for _, id := range partyIDs {
+		r, err := keygen.StartKeygenCommon(false, group, partyIDs, threshold, id, nil, nil, nil)(nil)
+		require.NoError(t, err, "round creation should not result in an error")
+		rounds = append(rounds, r)
+	}
+	for {
+		err, done := test.Rounds(rounds, nil)
+		require.NoError(t, err, "failed to process round")
+		if done {
+			break
+		}
+	}
+
+	configs := make(map[party.ID]*keygen.Config, N)
+	for _, r := range rounds {
+		require.IsType(t, &round.Output{}, r, "expected result round")
+		configs[r.SelfID()] = r.(*round.Output).Result.(*keygen.Config)
+	}
+	publicKey, err := configs[partyIDs[0]].PublicKey.MarshalBinary()
+	require.NoError(t, err)
+	require.Len(t, publicKey, ed25519.PublicKeySize)
+
+	// RFC 8032 signs the message itself rather than a hash of it
+	message := []byte("threshold ed25519")
+	signers := partyIDs[:threshold+1]
+	rounds = rounds[:0]
+	for _, id := range signers {
+		r, err := StartSignCommon(false, configs[id], signers, message)(nil)
+		require.NoError(t, err, "round creation should not result in an error")
+		rounds = append(rounds, r)
+	}
+	for {
+		err, done := test.Rounds(rounds, nil)
+		require.NoError(t, err, "failed to process round")
+		if done {
+			break
+		}
+	}
+
+	checkOutput(t, rounds, configs[partyIDs[0]].PublicKey, message)
+	for _, r := range rounds {
+		sig := r.(*round.Output).Result.(Signature)
+		sigBytes, err := sig.MarshalBinary()
+		require.NoError(t, err)
+		require.Len(t, sigBytes, ed25519.SignatureSize)
+		assert.True(t, ed25519.Verify(publicKey, message, sigBytes), "crypto/ed25519 rejected the signature")
+		assert.False(t, ed25519.Verify(publicKey, []byte("another message"), sigBytes))
+
+		text, err := sig.MarshalText()
+		require.NoError(t, err)
+		decoded := EmptySignature(group)
+		require.NoError(t, decoded.UnmarshalText(text))
+		assert.True(t, decoded.Verify(configs[partyIDs[0]].PublicKey, message))
+	}
+}
+
 func checkOutputTaproot(t *testing.T, rounds []round.Session, public taproot.PublicKey, m []byte) {
 	for _, r := range rounds {
 		require.IsType(t, &round.Output{}, r, "expected result round")
@@ -138,7 +229,7 @@ func TestSignTaproot(t *testing.T) {
 		if newPublicKey == nil {
 			newPublicKey = result.PublicKey
 		}
-		tapRootPublicKey, err := curve.Secp256k1{}.LiftX(newPublicKey)
+		tapRootPublicKey, err := curve.Secp256k1{}.LiftX(newPublicKey, false)
 		genericVerificationShares := make(map[party.ID]curve.Point)
 		for k, v := range result.VerificationShares {
 			genericVerificationShares[k] = v
@@ -166,3 +257,28 @@ func TestSignTaproot(t *testing.T) {
 
 	checkOutputTaproot(t, rounds, newPublicKey, steak)
 }
+
+// TestSignBelowThreshold checks that fewer than threshold + 1 signers is an error of the StartFunc, and not a panic.
+func TestSignBelowThreshold(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(5)
+	secret := sample.Scalar(rand.Reader, group)
+	config := &keygen.Config{
+		ID:                 partyIDs[0],
+		Threshold:          2,
+		PublicKey:          secret.ActOnBase(),
+		PrivateShare:       secret,
+		VerificationShares: party.NewPointMap(map[party.ID]curve.Point{}),
+	}
+	m := []byte{0xDE, 0xAD, 0xBE, 0xEF}
+	assert.NotPanics(t, func() {
+		_, err := StartSignCommon(false, config, partyIDs[:2], m)(nil)
+		assert.EqualError(t, err, "sign.StartSign: need at least 3 signers, got 2")
+
+		commitments, err := NewCommitments(config, 1, nil)
+		require.NoError(t, err)
+		_, err = StartSignBatch(config, partyIDs[:2], [][]byte{m}, commitments)(nil)
+		assert.EqualError(t, err, "sign.StartSignBatch: need at least 3 signers, got 2")
+		assert.False(t, commitments[0].used.Load(), "the commitment must not be used up")
+	})
+}
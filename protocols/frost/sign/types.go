@@ -1,11 +1,12 @@
 package sign
 
 import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
 	"io"
 
-	"github.com/luxfi/threshold/pkg/hash"
 	"github.com/luxfi/threshold/pkg/math/curve"
-	"github.com/luxfi/threshold/pkg/math/sample"
 )
 
 // messageHash is a wrapper around bytes to provide some domain separation.
@@ -39,20 +40,156 @@ type Signature struct {
 	z curve.Scalar
 }
 
+// EmptySignature returns a Signature over a specific group, ready to be unmarshalled.
+func EmptySignature(group curve.Curve) Signature {
+	return Signature{R: group.NewPoint(), z: group.NewScalar()}
+}
+
+// MarshalBinary encodes the signature as R followed by z.
+//
+// On Ed25519, z is little-endian, which makes this the 64 byte signature of RFC 8032.
+func (sig Signature) MarshalBinary() ([]byte, error) {
+	if sig.R == nil || sig.z == nil {
+		return nil, fmt.Errorf("frost.Signature: signature is empty")
+	}
+	RBytes, err := sig.R.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	var zBytes []byte
+	if z, ok := sig.z.(*curve.Ed25519Scalar); ok {
+		zBytes = z.BytesLE()
+	} else if zBytes, err = sig.z.MarshalBinary(); err != nil {
+		return nil, err
+	}
+	return append(RBytes, zBytes...), nil
+}
+
+// UnmarshalBinary decodes a signature produced by MarshalBinary.
+//
+// The signature needs to have been initialized with EmptySignature first.
+func (sig *Signature) UnmarshalBinary(data []byte) error {
+	if sig.R == nil || sig.z == nil {
+		return fmt.Errorf("frost.Signature: call EmptySignature before unmarshalling")
+	}
+	zLen := (sig.z.Curve().ScalarBits() + 7) / 8
+	if len(data) <= zLen {
+		return fmt.Errorf("frost.Signature: invalid length %d", len(data))
+	}
+	RBytes, zBytes := data[:len(data)-zLen], data[len(data)-zLen:]
+	if err := sig.R.UnmarshalBinary(RBytes); err != nil {
+		return fmt.Errorf("frost.Signature: R: %w", err)
+	}
+	var err error
+	if z, ok := sig.z.(*curve.Ed25519Scalar); ok {
+		err = z.SetBytesLE(zBytes)
+	} else {
+		err = sig.z.UnmarshalBinary(zBytes)
+	}
+	if err != nil {
+		return fmt.Errorf("frost.Signature: z: %w", err)
+	}
+	return nil
+}
+
+// MarshalText encodes the binary form of the signature as hex.
+func (sig Signature) MarshalText() ([]byte, error) {
+	data, err := sig.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(hex.EncodeToString(data)), nil
+}
+
+// UnmarshalText decodes a hex signature produced by MarshalText.
+func (sig *Signature) UnmarshalText(text []byte) error {
+	data, err := hex.DecodeString(string(text))
+	if err != nil {
+		return fmt.Errorf("frost.Signature: %w", err)
+	}
+	return sig.UnmarshalBinary(data)
+}
+
 // Verify checks if a signature equation actually holds.
 //
 // Note that m is the hash of a message, and not the message itself.
 func (sig Signature) Verify(public curve.Point, m []byte) bool {
-	group := public.Curve()
+	return sig.VerifyCiphersuite(CiphersuiteDefault, public, m)
+}
 
-	challengeHash := hash.New()
-	_ = challengeHash.WriteAny(sig.R, public, messageHash(m))
-	challenge := sample.Scalar(challengeHash.Digest(), group)
+// VerifyCiphersuite is like Verify, for a signature made with the given ciphersuite.
+//
+// With an RFC 9591 ciphersuite, m is the message itself.
+func (sig Signature) VerifyCiphersuite(suite Ciphersuite, public curve.Point, m []byte) bool {
+	if sig.R == nil || sig.z == nil {
+		return false
+	}
+	var c curve.Scalar
+	if suite != CiphersuiteDefault {
+		if suite.validate(public.Curve()) != nil {
+			return false
+		}
+		c = suite.challenge(sig.R, public, m)
+	} else {
+		c = challenge(public.Curve(), sig.R, public, messageHash(m))
+	}
 
-	expected := challenge.Act(public)
+	expected := c.Act(public)
 	expected = expected.Add(sig.R)
 
 	actual := sig.z.ActOnBase()
 
 	return expected.Equal(actual)
 }
+
+// VerifyAll verifies the signatures output by each signer of one signing session, which must all be the same.
+//
+// It returns the indices of the signatures which don't verify, or differ from the first valid one,
+// so that a divergent signer stands out, and true if there are none.
+func VerifyAll(sigs []Signature, public curve.Point, m []byte) (bool, []int) {
+	var reference *Signature
+	var failed []int
+	for i := range sigs {
+		sig := &sigs[i]
+		if !sig.Verify(public, m) {
+			failed = append(failed, i)
+			continue
+		}
+		if reference == nil {
+			reference = sig
+		} else if !sig.R.Equal(reference.R) || !sig.z.Equal(reference.z) {
+			failed = append(failed, i)
+		}
+	}
+	return len(sigs) > 0 && len(failed) == 0, failed
+}
+
+// messageList is a list of message hashes signed together in a single session.
+type messageList [][]byte
+
+// WriteTo makes messageList implement the io.WriterTo interface.
+//
+// Each message is prefixed by its length, so that different lists cannot produce the same output.
+func (ms messageList) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+	for _, m := range ms {
+		var length [8]byte
+		binary.BigEndian.PutUint64(length[:], uint64(len(m)))
+		n, err := w.Write(length[:])
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+		n, err = w.Write(m)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// Domain implements hash.WriterToWithDomain, and separates this type within hash.Hash.
+func (messageList) Domain() string {
+	return "messageList"
+}
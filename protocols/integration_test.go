@@ -1,6 +1,7 @@
 package protocols_test
 
 import (
+	"context"
 	"crypto/rand"
 	"fmt"
 	"testing"
@@ -39,8 +40,9 @@ var _ = Describe("Protocol Integration", func() {
 
 	AfterEach(func() {
 		if pl != nil {
-			time.Sleep(100 * time.Millisecond)
-			pl.TearDown()
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			Expect(pl.TearDownWait(ctx)).To(Succeed())
 		}
 	})
 
@@ -338,10 +340,12 @@ func runCMPSign(configs []*cmpconfig.Config, signers []party.ID, messageHash []b
 	
 	handlers := make([]*protocol.MultiHandler, len(signerConfigs))
 	signatures := make([]*ecdsa.Signature, len(signerConfigs))
+	sessionID, err := protocol.NewSessionID()
+	Expect(err).NotTo(HaveOccurred())
 	
 	// Create sign handlers
 	for i, config := range signerConfigs {
-		h, err := protocol.NewMultiHandler(cmp.Sign(config, signers, messageHash, pl), nil)
+		h, err := protocol.NewMultiHandler(cmp.Sign(config, signers, messageHash, pl), sessionID)
 		if err != nil {
 			// Return dummy signature if not implemented
 			signatures[i] = &ecdsa.Signature{}
@@ -420,10 +424,12 @@ func runFROSTSign(configs []*frost.Config, signers []party.ID, message []byte, p
 	
 	handlers := make([]*protocol.MultiHandler, len(signerConfigs))
 	signatures := make([]*frost.Signature, len(signerConfigs))
+	sessionID, err := protocol.NewSessionID()
+	Expect(err).NotTo(HaveOccurred())
 	
 	// Create sign handlers
 	for i, config := range signerConfigs {
-		h, err := protocol.NewMultiHandler(frost.Sign(config, signers, message), nil)
+		h, err := protocol.NewMultiHandler(frost.Sign(config, signers, message), sessionID)
 		if err != nil {
 			// Return dummy signature if not implemented
 			signatures[i] = &frost.Signature{}
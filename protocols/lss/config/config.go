@@ -2,9 +2,12 @@
 package config
 
 import (
+	stdecdsa "crypto/ecdsa"
 	"errors"
 	"fmt"
 
+	"github.com/luxfi/threshold/internal/bip32"
+	"github.com/luxfi/threshold/internal/params"
 	"github.com/luxfi/threshold/pkg/math/curve"
 	"github.com/luxfi/threshold/pkg/math/polynomial"
 	"github.com/luxfi/threshold/pkg/party"
@@ -18,9 +21,13 @@ type Config struct {
 	// Group defines the elliptic curve we're using
 	Group curve.Curve
 
-	// Threshold is the minimum number of parties needed to sign
+	// Threshold is the minimum number of shares needed to sign,
+	// which is the number of parties unless some are weighted
 	Threshold int
 
+	// Weights gives the number of shares of the parties holding several, see Weights
+	Weights Weights
+
 	// Generation tracks the current resharing generation
 	Generation uint64
 
@@ -30,7 +37,10 @@ type Config struct {
 	// ECDSA is this party's share of the master private key
 	ECDSA curve.Scalar
 
-	// Public maps party IDs to their public key shares
+	// ExtraShares holds the other shares of a weighted party, by evaluation ID
+	ExtraShares map[party.ID]curve.Scalar
+
+	// Public maps party IDs to their public key shares, with an entry for each evaluation ID of a weighted party
 	Public map[party.ID]*Public
 
 	// ChainKey is used for deriving per-signature randomness
@@ -60,6 +70,7 @@ func (c *Config) PublicPoint() (curve.Point, error) {
 	for j := range c.Public {
 		partyIDs = append(partyIDs, j)
 	}
+	party.SortIDs(partyIDs)
 
 	// Use first threshold parties for interpolation
 	if len(partyIDs) < c.Threshold {
@@ -82,12 +93,41 @@ func (c *Config) PublicPoint() (curve.Point, error) {
 }
 
 // PartyIDs returns a sorted slice of party IDs.
+//
+// The additional evaluation IDs of weighted parties are not included, see EvaluationIDs for those.
 func (c *Config) PartyIDs() party.IDSlice {
+	extra := make(map[party.ID]bool)
+	for id := range c.Weights {
+		for _, e := range c.Weights.EvaluationIDs(id)[1:] {
+			extra[e] = true
+		}
+	}
+	ids := make([]party.ID, 0, len(c.Public))
+	for id := range c.Public {
+		if !extra[id] {
+			ids = append(ids, id)
+		}
+	}
+	return party.NewIDSlice(ids)
+}
+
+// EvaluationIDs returns the IDs of all shares of the key, one per party unless some are weighted.
+func (c *Config) EvaluationIDs() party.IDSlice {
 	ids := make([]party.ID, 0, len(c.Public))
 	for id := range c.Public {
 		ids = append(ids, id)
 	}
-	return party.IDSlice(ids)
+	return party.NewIDSlice(ids)
+}
+
+// Shares returns this party's shares by evaluation ID: ECDSA, and the extra shares of a weighted party.
+func (c *Config) Shares() map[party.ID]curve.Scalar {
+	shares := make(map[party.ID]curve.Scalar, 1+len(c.ExtraShares))
+	shares[c.ID] = c.ECDSA
+	for id, share := range c.ExtraShares {
+		shares[id] = share
+	}
+	return shares
 }
 
 // PublicKey returns the combined public key (backward compatibility)
@@ -95,6 +135,25 @@ func (c *Config) PublicKey() (curve.Point, error) {
 	return c.PublicPoint()
 }
 
+// StdPublicKey returns the public key as a *ecdsa.PublicKey of the standard library, see curve.StdPublicKey.
+func (c *Config) StdPublicKey() (*stdecdsa.PublicKey, error) {
+	publicKey, err := c.PublicPoint()
+	if err != nil {
+		return nil, err
+	}
+	return curve.StdPublicKey(publicKey)
+}
+
+// KeyID returns the fingerprint of the public key, see curve.KeyID,
+// or the empty string if the public key can't be computed.
+func (c *Config) KeyID() string {
+	publicKey, err := c.PublicPoint()
+	if err != nil {
+		return ""
+	}
+	return curve.KeyID(publicKey)
+}
+
 // Validate checks if the config is well-formed
 func (c *Config) Validate() error {
 	if c.Group == nil {
@@ -112,6 +171,23 @@ func (c *Config) Validate() error {
 	if c.Threshold > len(c.Public) {
 		return errors.New("lss/config: threshold exceeds party count")
 	}
+	if err := c.Weights.Validate(c.PartyIDs()); err != nil {
+		return err
+	}
+	for _, id := range c.Weights.AllEvaluationIDs(c.PartyIDs()) {
+		if _, ok := c.Public[id]; !ok {
+			return fmt.Errorf("lss/config: missing public share for %s", id)
+		}
+	}
+	extra := c.Weights.EvaluationIDs(c.ID)[1:]
+	if len(c.ExtraShares) != len(extra) {
+		return fmt.Errorf("lss/config: %d extra shares for weight %d", len(c.ExtraShares), c.Weights.Of(c.ID))
+	}
+	for _, id := range extra {
+		if c.ExtraShares[id] == nil {
+			return fmt.Errorf("lss/config: missing extra share %s", id)
+		}
+	}
 	if len(c.ChainKey) == 0 {
 		return errors.New("lss/config: missing chain key")
 	}
@@ -132,6 +208,71 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// RecomputePublicShares fills in the missing public shares of an imported config, which has
+// entries in Public for all parties but lacks the points of some of them.
+// It does nothing if no public share is missing.
+//
+// The public shares of this party are derived from its secret shares, and those of the other parties
+// are interpolated from the known ones, which needs Threshold of them.
+// This makes the config usable for verifying the key and the signatures of the other parties.
+func (c *Config) RecomputePublicShares() error {
+	if c.Public == nil {
+		c.Public = make(map[party.ID]*Public)
+	}
+	for id, share := range c.Shares() {
+		if share == nil {
+			continue
+		}
+		point := share.ActOnBase()
+		if pub := c.Public[id]; pub != nil && pub.ECDSA != nil && !pub.ECDSA.Equal(point) {
+			return fmt.Errorf("lss/config: public share of %s doesn't match its secret share", id)
+		}
+		c.Public[id] = &Public{ECDSA: point}
+	}
+
+	known := make(map[party.ID]curve.Point, len(c.Public))
+	var missing []party.ID
+	for _, id := range c.Weights.AllEvaluationIDs(c.PartyIDs()) {
+		if pub := c.Public[id]; pub != nil && pub.ECDSA != nil {
+			known[id] = pub.ECDSA
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	if len(known) < c.Threshold {
+		return fmt.Errorf("lss/config: %d public shares are known, but %d are needed to recompute the others", len(known), c.Threshold)
+	}
+	for _, id := range missing {
+		point, err := polynomial.InterpolatePointAt(c.Group, known, c.Threshold-1, id)
+		if err != nil {
+			return fmt.Errorf("lss/config: public shares: %w", err)
+		}
+		c.Public[id] = &Public{ECDSA: point}
+	}
+	return nil
+}
+
+// VerifyPublicShare checks that the public share of id lies on the polynomial of degree Threshold-1
+// on which the public shares of the other parties lie, so that it is consistent with the public key.
+//
+// It only uses public data, so that anyone holding a published keyset can check it.
+func (c *Config) VerifyPublicShare(id party.ID) error {
+	shares := make(map[party.ID]curve.Point, len(c.Public))
+	for j, pub := range c.Public {
+		if pub == nil || pub.ECDSA == nil {
+			return fmt.Errorf("lss/config: missing ECDSA public share for %s", j)
+		}
+		shares[j] = pub.ECDSA
+	}
+	if err := polynomial.VerifyPoint(c.Group, shares, c.Threshold-1, nil, id); err != nil {
+		return fmt.Errorf("lss/config: public share of %s: %w", id, err)
+	}
+	return nil
+}
+
 // Copy creates a deep copy of the config
 func (c *Config) Copy() *Config {
 	newConfig := &Config{
@@ -140,17 +281,91 @@ func (c *Config) Copy() *Config {
 		Threshold:    c.Threshold,
 		Generation:   c.Generation,
 		RollbackFrom: c.RollbackFrom,
-		ECDSA:        c.ECDSA,
+		Weights:      c.Weights.Copy(),
 		Public:       make(map[party.ID]*Public),
 		ChainKey:     append([]byte(nil), c.ChainKey...),
 		RID:          append([]byte(nil), c.RID...),
 	}
 
+	// the shares are copied, so that zeroizing one config leaves the other intact
+	if c.ECDSA != nil {
+		newConfig.ECDSA = c.ECDSA.Curve().NewScalar().Set(c.ECDSA)
+	}
 	for id, pub := range c.Public {
 		newConfig.Public[id] = &Public{
 			ECDSA: pub.ECDSA,
 		}
 	}
+	if c.ExtraShares != nil {
+		newConfig.ExtraShares = make(map[party.ID]curve.Scalar, len(c.ExtraShares))
+		for id, share := range c.ExtraShares {
+			newConfig.ExtraShares[id] = share.Curve().NewScalar().Set(share)
+		}
+	}
 
 	return newConfig
 }
+
+// Zeroize overwrites the shares of this config with 0, once it has been superseded by a reshare or a refresh,
+// so that they don't remain in memory. The config can't be used to sign afterwards.
+func (c *Config) Zeroize() {
+	if c.ECDSA != nil {
+		c.ECDSA.Zero()
+	}
+	for _, share := range c.ExtraShares {
+		share.Zero()
+	}
+}
+
+// Derive returns the config of the key obtained by adding adjust to the secret key.
+//
+// Each share is adjusted by the same amount, which shifts the constant of the sharing polynomial,
+// so that the derived config signs threshold-style like this one.
+// A new chain key can be passed, which replaces the existing one.
+func (c *Config) Derive(adjust curve.Scalar, newChainKey []byte) (*Config, error) {
+	if len(newChainKey) == 0 {
+		newChainKey = c.ChainKey
+	}
+	if len(newChainKey) != params.SecBytes {
+		return nil, fmt.Errorf("lss/config: expected %d bytes for chain key, found %d", params.SecBytes, len(newChainKey))
+	}
+	adjustG := adjust.ActOnBase()
+
+	derived := c.Copy()
+	derived.ChainKey = append([]byte(nil), newChainKey...)
+	if c.ECDSA != nil {
+		derived.ECDSA = c.Group.NewScalar().Set(c.ECDSA).Add(adjust)
+	}
+	for id, share := range c.ExtraShares {
+		derived.ExtraShares[id] = c.Group.NewScalar().Set(share).Add(adjust)
+	}
+	for id, pub := range c.Public {
+		derived.Public[id] = &Public{ECDSA: pub.ECDSA.Add(adjustG)}
+	}
+	return derived, nil
+}
+
+// DeriveChild returns the config of the non-hardened BIP-32 child of the public key at index i,
+// using ChainKey as the chain code. It requires a secp256k1 key.
+//
+// Some indices give no valid key, in which case an error is returned, and the next index should be used.
+//
+// See: https://github.com/bitcoin/bips/blob/master/bip-0032.mediawiki
+func (c *Config) DeriveChild(i uint32) (*Config, error) {
+	if i>>31 != 0 {
+		return nil, fmt.Errorf("lss/config: index %d is hardened, which can't be derived from the public key", i)
+	}
+	publicKey, err := c.PublicPoint()
+	if err != nil {
+		return nil, err
+	}
+	point, ok := publicKey.(*curve.Secp256k1Point)
+	if !ok {
+		return nil, errors.New("lss/config: DeriveChild requires a secp256k1 key")
+	}
+	scalar, newChainKey, err := bip32.DeriveScalar(point, c.ChainKey, i)
+	if err != nil {
+		return nil, fmt.Errorf("lss/config: %w", err)
+	}
+	return c.Derive(scalar, newChainKey)
+}
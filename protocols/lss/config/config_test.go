@@ -1,9 +1,14 @@
 package config_test
 
 import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
 	"testing"
 
 	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/math/polynomial"
+	"github.com/luxfi/threshold/pkg/math/sample"
 	"github.com/luxfi/threshold/pkg/party"
 	"github.com/luxfi/threshold/protocols/lss/config"
 	"github.com/stretchr/testify/assert"
@@ -141,4 +146,156 @@ func TestPartyIDs(t *testing.T) {
 	assert.True(t, idMap["alice"])
 	assert.True(t, idMap["bob"])
 	assert.True(t, idMap["charlie"])
-}
\ No newline at end of file
+}
+func TestConfigJSONVersion(t *testing.T) {
+	group := curve.Secp256k1{}
+	share := sample.Scalar(rand.Reader, group)
+	cfg := &config.Config{
+		ID:           "a",
+		Group:        group,
+		Threshold:    1,
+		Generation:   4,
+		RollbackFrom: 6,
+		ECDSA:        share,
+		Public: map[party.ID]*config.Public{
+			"a": {ECDSA: share.ActOnBase()},
+			"b": {ECDSA: sample.Scalar(rand.Reader, group).ActOnBase()},
+		},
+		ChainKey: []byte("chainkey"),
+		RID:      []byte("rid"),
+	}
+
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	assert.EqualValues(t, config.JSONVersion, doc["version"])
+
+	decoded := config.EmptyConfig(group)
+	require.NoError(t, json.Unmarshal(data, decoded))
+	assert.Equal(t, cfg.ID, decoded.ID)
+	assert.Equal(t, cfg.Threshold, decoded.Threshold)
+	assert.Equal(t, cfg.Generation, decoded.Generation)
+	assert.Equal(t, cfg.RollbackFrom, decoded.RollbackFrom)
+	assert.True(t, cfg.ECDSA.Equal(decoded.ECDSA))
+	assert.True(t, cfg.Public["b"].ECDSA.Equal(decoded.Public["b"].ECDSA))
+	assert.Equal(t, cfg.ChainKey, decoded.ChainKey)
+	assert.Equal(t, cfg.RID, decoded.RID)
+
+	// files written before versioning have neither a version nor rollback_from
+	delete(doc, "version")
+	delete(doc, "rollback_from")
+	legacy, err := json.Marshal(doc)
+	require.NoError(t, err)
+	decoded = config.EmptyConfig(group)
+	require.NoError(t, json.Unmarshal(legacy, decoded))
+	assert.Equal(t, cfg.Generation, decoded.Generation)
+	assert.Zero(t, decoded.RollbackFrom)
+	assert.True(t, cfg.ECDSA.Equal(decoded.ECDSA))
+	upgraded, err := config.MigrateJSON(legacy)
+	require.NoError(t, err)
+	assert.Contains(t, string(upgraded), `"version":1`)
+
+	doc["version"] = 999
+	future, err := json.Marshal(doc)
+	require.NoError(t, err)
+	assert.ErrorContains(t, json.Unmarshal(future, config.EmptyConfig(group)), "unsupported config version 999")
+}
+
+func TestConfigBinary(t *testing.T) {
+	group := curve.Secp256k1{}
+	public := make(map[party.ID]*config.Public, 21)
+	var share curve.Scalar
+	for i := 0; i < 21; i++ {
+		s := sample.Scalar(rand.Reader, group)
+		id := party.ID(fmt.Sprintf("party-%d", i+1))
+		if i == 0 {
+			share = s
+		}
+		public[id] = &config.Public{ECDSA: s.ActOnBase()}
+	}
+	cfg := &config.Config{
+		ID:           "party-1",
+		Group:        group,
+		Threshold:    11,
+		Generation:   2,
+		RollbackFrom: 5,
+		ECDSA:        share,
+		Public:       public,
+		ChainKey:     []byte("chainkey"),
+		RID:          []byte("rid"),
+	}
+
+	data, err := cfg.MarshalBinary()
+	require.NoError(t, err)
+	again, err := cfg.MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, data, again, "the encoding must not depend on map order")
+
+	decoded := config.EmptyConfig(group)
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, cfg.ID, decoded.ID)
+	assert.Equal(t, cfg.Threshold, decoded.Threshold)
+	assert.Equal(t, cfg.Generation, decoded.Generation)
+	assert.Equal(t, cfg.RollbackFrom, decoded.RollbackFrom)
+	assert.True(t, cfg.ECDSA.Equal(decoded.ECDSA))
+	require.Len(t, decoded.Public, len(cfg.Public))
+	for id, p := range cfg.Public {
+		assert.True(t, p.ECDSA.Equal(decoded.Public[id].ECDSA))
+	}
+	reencoded, err := decoded.MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, data, reencoded, "a decoded config must encode to the same bytes")
+
+	assert.Error(t, (&config.Config{}).UnmarshalBinary(data), "the group must be set")
+	assert.Error(t, config.EmptyConfig(group).UnmarshalBinary(data[:len(data)-1]))
+}
+
+func TestRecomputePublicShares(t *testing.T) {
+	group := curve.Secp256k1{}
+	ids := []party.ID{"a", "b", "c", "d"}
+	f := polynomial.NewPolynomial(group, 2, sample.Scalar(rand.Reader, group))
+	original := make(map[party.ID]*config.Public, len(ids))
+	for _, id := range ids {
+		original[id] = &config.Public{ECDSA: f.Evaluate(id.Scalar(group)).ActOnBase()}
+	}
+	cfg := &config.Config{
+		ID:        "a",
+		Group:     group,
+		Threshold: 3,
+		ECDSA:     f.Evaluate(party.ID("a").Scalar(group)),
+		Public:    original,
+		ChainKey:  []byte("chainkey"),
+		RID:       []byte("rid"),
+	}
+
+	// export a config holding only the public shares of b and c
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	public := doc["public"].(map[string]interface{})
+	public["a"] = map[string]interface{}{}
+	public["d"] = nil
+	data, err = json.Marshal(doc)
+	require.NoError(t, err)
+
+	imported := config.EmptyConfig(group)
+	require.NoError(t, json.Unmarshal(data, imported))
+	assert.Error(t, imported.Validate(), "public shares are missing")
+	require.NoError(t, imported.RecomputePublicShares())
+	require.NoError(t, imported.Validate())
+	for _, id := range ids {
+		assert.True(t, original[id].ECDSA.Equal(imported.Public[id].ECDSA), "public share of %s", id)
+	}
+	assert.Equal(t, cfg.KeyID(), imported.KeyID())
+
+	// the own share and one other can't determine a polynomial of degree 2
+	imported.Public["c"] = &config.Public{}
+	imported.Public["d"] = &config.Public{}
+	assert.Error(t, imported.RecomputePublicShares())
+
+	// the public share of this party must match its secret share
+	imported.Public["a"] = original["b"]
+	assert.Error(t, imported.RecomputePublicShares())
+}
@@ -3,25 +3,80 @@ package config
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
 
+	"github.com/fxamacker/cbor/v2"
+	"github.com/luxfi/threshold/pkg/math/curve"
 	"github.com/luxfi/threshold/pkg/party"
 )
 
+// JSONVersion is the version of the JSON encoding written by MarshalJSON.
+//
+// UnmarshalJSON upgrades documents of older versions, and rejects newer ones.
+const JSONVersion = 1
+
 type configJSON struct {
-	ID         string                 `json:"id"`
-	Threshold  int                    `json:"threshold"`
-	Generation uint64                 `json:"generation"`
-	ECDSA      string                 `json:"ecdsa"` // Base64 encoded
-	Public     map[string]*publicJSON `json:"public"`
-	ChainKey   string                 `json:"chain_key"` // Base64 encoded
-	RID        string                 `json:"rid"`       // Base64 encoded
+	Version      int                    `json:"version"`
+	ID           string                 `json:"id"`
+	Threshold    int                    `json:"threshold"`
+	Generation   uint64                 `json:"generation"`
+	RollbackFrom uint64                 `json:"rollback_from"`
+	ECDSA        string                 `json:"ecdsa"` // Base64 encoded
+	Public       map[string]*publicJSON `json:"public"`
+	ChainKey     string                 `json:"chain_key"` // Base64 encoded
+	RID          string                 `json:"rid"`       // Base64 encoded
+	Weights      map[string]int         `json:"weights,omitempty"`
+	ExtraShares  map[string]string      `json:"extra_shares,omitempty"` // Base64 encoded
 }
 
 type publicJSON struct {
 	ECDSA string `json:"ecdsa"` // Base64 encoded
 }
 
+// configMigrations[v] upgrades a document of version v to version v+1.
+var configMigrations = []func(data []byte) ([]byte, error){
+	// version 0 documents have no version, and lack rollback_from, which is 0 when absent
+	0: func(data []byte) ([]byte, error) {
+		var doc map[string]json.RawMessage
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		doc["version"] = json.RawMessage("1")
+		if _, ok := doc["rollback_from"]; !ok {
+			doc["rollback_from"] = json.RawMessage("0")
+		}
+		return json.Marshal(doc)
+	},
+}
+
+// MigrateJSON upgrades a JSON encoded Config of any supported version to JSONVersion.
+func MigrateJSON(data []byte) ([]byte, error) {
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, err
+	}
+	if header.Version < 0 || header.Version > JSONVersion {
+		return nil, fmt.Errorf("lss/config: unsupported config version %d (supported: %d)", header.Version, JSONVersion)
+	}
+	for v := header.Version; v < JSONVersion; v++ {
+		var err error
+		if data, err = configMigrations[v](data); err != nil {
+			return nil, fmt.Errorf("lss/config: failed to upgrade config from version %d: %w", v, err)
+		}
+	}
+	return data, nil
+}
+
+// JSONType returns the type of the document Config is encoded as by MarshalJSON,
+// so that a schema of the JSON encoding can be derived from it.
+func JSONType() reflect.Type {
+	return reflect.TypeOf(configJSON{})
+}
+
 // MarshalJSON implements json.Marshaler
 func (c *Config) MarshalJSON() ([]byte, error) {
 	// Marshal ECDSA share
@@ -33,6 +88,10 @@ func (c *Config) MarshalJSON() ([]byte, error) {
 	// Marshal public shares
 	public := make(map[string]*publicJSON, len(c.Public))
 	for id, p := range c.Public {
+		if p == nil || p.ECDSA == nil {
+			public[string(id)] = &publicJSON{}
+			continue
+		}
 		pubBytes, err := p.ECDSA.MarshalBinary()
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal public ECDSA for %s: %w", id, err)
@@ -42,25 +101,54 @@ func (c *Config) MarshalJSON() ([]byte, error) {
 		}
 	}
 
+	var extraShares map[string]string
+	for id, share := range c.ExtraShares {
+		data, err := share.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal extra share %s: %w", id, err)
+		}
+		if extraShares == nil {
+			extraShares = make(map[string]string, len(c.ExtraShares))
+		}
+		extraShares[string(id)] = base64.StdEncoding.EncodeToString(data)
+	}
+	var weights map[string]int
+	for id, weight := range c.Weights.Copy() {
+		if weights == nil {
+			weights = make(map[string]int, len(c.Weights))
+		}
+		weights[string(id)] = weight
+	}
+
 	out := &configJSON{
-		ID:         string(c.ID),
-		Threshold:  c.Threshold,
-		Generation: c.Generation,
-		ECDSA:      base64.StdEncoding.EncodeToString(ecdsaBytes),
-		Public:     public,
-		ChainKey:   base64.StdEncoding.EncodeToString(c.ChainKey),
-		RID:        base64.StdEncoding.EncodeToString(c.RID),
+		Version:      JSONVersion,
+		ID:           string(c.ID),
+		Threshold:    c.Threshold,
+		Generation:   c.Generation,
+		RollbackFrom: c.RollbackFrom,
+		ECDSA:        base64.StdEncoding.EncodeToString(ecdsaBytes),
+		Public:       public,
+		ChainKey:     base64.StdEncoding.EncodeToString(c.ChainKey),
+		RID:          base64.StdEncoding.EncodeToString(c.RID),
+		Weights:      weights,
+		ExtraShares:  extraShares,
 	}
 
 	return json.Marshal(out)
 }
 
 // UnmarshalJSON implements json.Unmarshaler
+//
+// Documents written by older versions are upgraded with MigrateJSON.
 func (c *Config) UnmarshalJSON(data []byte) error {
 	if c.Group == nil {
 		return fmt.Errorf("lss/config: group must be set before unmarshalling")
 	}
 
+	data, err := MigrateJSON(data)
+	if err != nil {
+		return err
+	}
 	var out configJSON
 	if err := json.Unmarshal(data, &out); err != nil {
 		return err
@@ -69,6 +157,7 @@ func (c *Config) UnmarshalJSON(data []byte) error {
 	c.ID = party.ID(out.ID)
 	c.Threshold = out.Threshold
 	c.Generation = out.Generation
+	c.RollbackFrom = out.RollbackFrom
 
 	// Unmarshal ChainKey
 	chainKey, err := base64.StdEncoding.DecodeString(out.ChainKey)
@@ -99,6 +188,11 @@ func (c *Config) UnmarshalJSON(data []byte) error {
 	c.Public = make(map[party.ID]*Public, len(out.Public))
 	for idStr, p := range out.Public {
 		id := party.ID(idStr)
+		if p == nil || p.ECDSA == "" {
+			// the share is missing, see RecomputePublicShares
+			c.Public[id] = &Public{}
+			continue
+		}
 
 		pubBytes, err := base64.StdEncoding.DecodeString(p.ECDSA)
 		if err != nil {
@@ -115,5 +209,173 @@ func (c *Config) UnmarshalJSON(data []byte) error {
 		}
 	}
 
+	c.Weights = nil
+	for id, weight := range out.Weights {
+		if c.Weights == nil {
+			c.Weights = make(Weights, len(out.Weights))
+		}
+		c.Weights[party.ID(id)] = weight
+	}
+	c.ExtraShares = nil
+	for id, encoded := range out.ExtraShares {
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("lss/config: failed to decode extra share %s: %w", id, err)
+		}
+		share := c.Group.NewScalar()
+		if err := share.UnmarshalBinary(data); err != nil {
+			return fmt.Errorf("lss/config: failed to unmarshal extra share %s: %w", id, err)
+		}
+		if c.ExtraShares == nil {
+			c.ExtraShares = make(map[party.ID]curve.Scalar, len(out.ExtraShares))
+		}
+		c.ExtraShares[party.ID(id)] = share
+	}
+
+	return nil
+}
+
+// binaryVersion is the version of the CBOR encoding written by MarshalBinary.
+const binaryVersion = 1
+
+type configMarshal struct {
+	Version      uint
+	ID           party.ID
+	Threshold    int
+	Generation   uint64
+	RollbackFrom uint64
+	ECDSA        curve.Scalar
+	ChainKey     []byte
+	RID          []byte
+	Public       []cbor.RawMessage
+	Weights      []weightMarshal   `cbor:",omitempty"`
+	ExtraShares  []cbor.RawMessage `cbor:",omitempty"`
+}
+
+type publicMarshal struct {
+	ID    party.ID
+	ECDSA curve.Point
+}
+
+type weightMarshal struct {
+	ID     party.ID
+	Weight int
+}
+
+type shareMarshal struct {
+	ID    party.ID
+	Share curve.Scalar
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, encoding the config with CBOR.
+//
+// The encoding only depends on the contents of the config: public shares are written in the
+// order of their sorted IDs, so that encoding the same config twice gives the same bytes.
+func (c *Config) MarshalBinary() ([]byte, error) {
+	ps := make([]cbor.RawMessage, 0, len(c.Public))
+	for _, id := range c.EvaluationIDs() {
+		data, err := cbor.Marshal(&publicMarshal{ID: id, ECDSA: c.Public[id].ECDSA})
+		if err != nil {
+			return nil, fmt.Errorf("lss/config: public share of %s: %w", id, err)
+		}
+		ps = append(ps, data)
+	}
+	var weights []weightMarshal
+	for _, id := range c.PartyIDs() {
+		if weight := c.Weights.Of(id); weight != 1 {
+			weights = append(weights, weightMarshal{ID: id, Weight: weight})
+		}
+	}
+	var extra []cbor.RawMessage
+	for _, id := range c.Weights.EvaluationIDs(c.ID)[1:] {
+		data, err := cbor.Marshal(&shareMarshal{ID: id, Share: c.ExtraShares[id]})
+		if err != nil {
+			return nil, fmt.Errorf("lss/config: extra share %s: %w", id, err)
+		}
+		extra = append(extra, data)
+	}
+	return cbor.Marshal(&configMarshal{
+		Version:      binaryVersion,
+		ID:           c.ID,
+		Threshold:    c.Threshold,
+		Generation:   c.Generation,
+		RollbackFrom: c.RollbackFrom,
+		ECDSA:        c.ECDSA,
+		ChainKey:     c.ChainKey,
+		RID:          c.RID,
+		Public:       ps,
+		Weights:      weights,
+		ExtraShares:  extra,
+	})
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+//
+// The config must be created with EmptyConfig first, to set its group, and is validated after decoding.
+func (c *Config) UnmarshalBinary(data []byte) error {
+	if c.Group == nil {
+		return errors.New("lss/config: config must be initialized using EmptyConfig")
+	}
+	cm := &configMarshal{ECDSA: c.Group.NewScalar()}
+	if err := cbor.Unmarshal(data, cm); err != nil {
+		return fmt.Errorf("lss/config: %w", err)
+	}
+	if cm.Version == 0 || cm.Version > binaryVersion {
+		return fmt.Errorf("lss/config: unsupported config version %d (supported: %d)", cm.Version, binaryVersion)
+	}
+
+	public := make(map[party.ID]*Public, len(cm.Public))
+	for _, data := range cm.Public {
+		p := &publicMarshal{ECDSA: c.Group.NewPoint()}
+		if err := cbor.Unmarshal(data, p); err != nil {
+			return fmt.Errorf("lss/config: public share: %w", err)
+		}
+		if _, ok := public[p.ID]; ok {
+			return fmt.Errorf("lss/config: party %s: duplicate entry", p.ID)
+		}
+		public[p.ID] = &Public{ECDSA: p.ECDSA}
+	}
+	var weights Weights
+	for _, w := range cm.Weights {
+		if weights == nil {
+			weights = make(Weights, len(cm.Weights))
+		}
+		if _, ok := weights[w.ID]; ok {
+			return fmt.Errorf("lss/config: party %s: duplicate weight", w.ID)
+		}
+		weights[w.ID] = w.Weight
+	}
+	var extra map[party.ID]curve.Scalar
+	for _, data := range cm.ExtraShares {
+		s := &shareMarshal{Share: c.Group.NewScalar()}
+		if err := cbor.Unmarshal(data, s); err != nil {
+			return fmt.Errorf("lss/config: extra share: %w", err)
+		}
+		if extra == nil {
+			extra = make(map[party.ID]curve.Scalar, len(cm.ExtraShares))
+		}
+		if _, ok := extra[s.ID]; ok {
+			return fmt.Errorf("lss/config: extra share %s: duplicate entry", s.ID)
+		}
+		extra[s.ID] = s.Share
+	}
+
+	config := &Config{
+		ID:           cm.ID,
+		Group:        c.Group,
+		Threshold:    cm.Threshold,
+		Generation:   cm.Generation,
+		RollbackFrom: cm.RollbackFrom,
+		ECDSA:        cm.ECDSA,
+		Public:       public,
+		ChainKey:     cm.ChainKey,
+		RID:          cm.RID,
+		Weights:      weights,
+		ExtraShares:  extra,
+	}
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	*c = *config
 	return nil
 }
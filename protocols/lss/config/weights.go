@@ -0,0 +1,116 @@
+package config
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/luxfi/threshold/pkg/party"
+)
+
+// Weights maps the parties holding several shares of the key to their number of shares.
+// Parties not in the map hold a single share, so a nil Weights is the unweighted case.
+//
+// A party of weight w holds the shares at w evaluation points, which all count toward the threshold:
+// its own ID, and the IDs "<id>/2" to "<id>/w" returned by EvaluationIDs.
+type Weights map[party.ID]int
+
+// Of returns the weight of id.
+func (w Weights) Of(id party.ID) int {
+	if weight, ok := w[id]; ok {
+		return weight
+	}
+	return 1
+}
+
+// EvaluationIDs returns the IDs at whose scalars the shares of id are evaluated, starting with id itself.
+func (w Weights) EvaluationIDs(id party.ID) []party.ID {
+	ids := make([]party.ID, 0, w.Of(id))
+	ids = append(ids, id)
+	for k := 2; k <= w.Of(id); k++ {
+		ids = append(ids, party.ID(fmt.Sprintf("%s/%d", id, k)))
+	}
+	return ids
+}
+
+// AllEvaluationIDs returns the evaluation IDs of all parties in ids, sorted.
+func (w Weights) AllEvaluationIDs(ids []party.ID) party.IDSlice {
+	all := make([]party.ID, 0, len(ids))
+	for _, id := range ids {
+		all = append(all, w.EvaluationIDs(id)...)
+	}
+	return party.NewIDSlice(all)
+}
+
+// Total returns the number of shares held by the parties in ids.
+func (w Weights) Total(ids []party.ID) int {
+	total := 0
+	for _, id := range ids {
+		total += w.Of(id)
+	}
+	return total
+}
+
+// Validate checks that the weights are positive and given for parties in ids only,
+// and that no evaluation ID of a party is the ID of another.
+func (w Weights) Validate(ids []party.ID) error {
+	parties := party.NewIDSlice(ids)
+	for id, weight := range w {
+		if !parties.Contains(id) {
+			return fmt.Errorf("lss/config: weight given for unknown party %s", id)
+		}
+		if weight < 1 {
+			return fmt.Errorf("lss/config: weight %d of %s must be at least 1", weight, id)
+		}
+	}
+	if all := w.AllEvaluationIDs(ids); !all.Valid() {
+		return fmt.Errorf("lss/config: the evaluation IDs of weighted parties collide with other party IDs")
+	}
+	return nil
+}
+
+// Copy returns a copy of w, which is nil if w has no party of weight above 1.
+func (w Weights) Copy() Weights {
+	var weights Weights
+	for id, weight := range w {
+		if weight == 1 {
+			continue
+		}
+		if weights == nil {
+			weights = make(Weights, len(w))
+		}
+		weights[id] = weight
+	}
+	return weights
+}
+
+// WeightedThreshold is a threshold counted in shares, with the weights of the parties.
+//
+// It is written into the session hash of weighted protocols, so that all parties agree on it:
+// the threshold of their round.Info counts parties, which may be fewer than the shares.
+type WeightedThreshold struct {
+	Threshold int
+	Weights   Weights
+}
+
+// WriteTo implements io.WriterTo, writing the threshold and the weights in the order of the party IDs.
+func (t WeightedThreshold) WriteTo(w io.Writer) (int64, error) {
+	weights := t.Weights.Copy()
+	ids := make([]party.ID, 0, len(weights))
+	for id := range weights {
+		ids = append(ids, id)
+	}
+	buf := binary.BigEndian.AppendUint32(nil, uint32(t.Threshold))
+	for _, id := range party.NewIDSlice(ids) {
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(id)))
+		buf = append(buf, id...)
+		buf = binary.BigEndian.AppendUint32(buf, uint32(weights[id]))
+	}
+	n, err := w.Write(buf)
+	return int64(n), err
+}
+
+// Domain implements hash.WriterToWithDomain.
+func (WeightedThreshold) Domain() string {
+	return "LSS Weighted Threshold"
+}
@@ -181,6 +181,7 @@ func (d *BootstrapDealer) handleBlindedShare(from party.ID, msg *lss.ReshareMess
 		for pid := range d.blindedProducts {
 			contributingParties = append(contributingParties, pid)
 		}
+		party.SortIDs(contributingParties)
 
 		// Compute Lagrange coefficients
 		lagrange := polynomial.Lagrange(d.group, contributingParties[:d.currentThreshold])
@@ -227,10 +228,9 @@ func (d *BootstrapDealer) handleBlindedProduct(from party.ID, msg *lss.ReshareMe
 		contributingParties := make([]party.ID, 0, len(d.qwProducts))
 		for pid := range d.qwProducts {
 			contributingParties = append(contributingParties, pid)
-			if len(contributingParties) >= d.newThreshold {
-				break
-			}
 		}
+		party.SortIDs(contributingParties)
+		contributingParties = contributingParties[:d.newThreshold]
 
 		// Compute Lagrange coefficients
 		lagrange := polynomial.Lagrange(d.group, contributingParties)
@@ -0,0 +1,112 @@
+package keygen
+
+import (
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/math/sample"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/pool"
+	"github.com/luxfi/threshold/pkg/protocol"
+	"github.com/luxfi/threshold/protocols/lss/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runKeygen runs keygen between all partyIDs, letting tamper rewrite each message before it is delivered.
+func runKeygen(t *testing.T, partyIDs party.IDSlice, threshold int, tamper func(msg *protocol.Message)) map[party.ID]*protocol.MultiHandler {
+	group := curve.Secp256k1{}
+	pl := pool.NewPool(0)
+	t.Cleanup(pl.TearDown)
+
+	handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+	for _, id := range partyIDs {
+		h, err := protocol.NewMultiHandler(Start(id, partyIDs, threshold, group, pl), nil)
+		require.NoError(t, err)
+		handlers[id] = h
+	}
+	for progress := true; progress; {
+		progress = false
+		for _, from := range partyIDs {
+			for drained := false; !drained; {
+				select {
+				case msg, ok := <-handlers[from].Listen():
+					if !ok {
+						drained = true
+						break
+					}
+					progress = true
+					tamper(msg)
+					for _, to := range partyIDs {
+						if to != from && msg.IsFor(to) {
+							handlers[to].Accept(msg)
+						}
+					}
+				default:
+					drained = true
+				}
+			}
+		}
+	}
+	return handlers
+}
+
+func TestKeygenComplaints(t *testing.T) {
+	partyIDs := test.PartyIDs(4)
+	cheater, victim := partyIDs[1], partyIDs[2]
+	bad, err := sample.Scalar(rand.Reader, curve.Secp256k1{}).MarshalBinary()
+	require.NoError(t, err)
+
+	// badDealer makes the cheater send bad to the victim, and reveal it too if revealBad is set
+	badDealer := func(revealBad bool) func(msg *protocol.Message) {
+		return func(msg *protocol.Message) {
+			if msg.From != cheater {
+				return
+			}
+			var err error
+			switch {
+			case msg.RoundNumber == 2 && msg.To == victim:
+				msg.Data, err = cbor.Marshal(&message2{Share: bad})
+			case msg.RoundNumber == 4 && revealBad:
+				body := &broadcast4{}
+				require.NoError(t, cbor.Unmarshal(msg.Data, body))
+				body.Shares[victim] = bad
+				msg.Data, err = cbor.Marshal(body)
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	t.Run("InvalidReveal", func(t *testing.T) {
+		handlers := runKeygen(t, partyIDs, 3, badDealer(true))
+		for _, id := range partyIDs {
+			if id == cheater {
+				continue
+			}
+			_, err := handlers[id].Result()
+			var protocolErr protocol.Error
+			require.True(t, errors.As(err, &protocolErr), "party %s should abort, got %v", id, err)
+			assert.Equal(t, []party.ID{cheater}, protocolErr.Culprits, "party %s blamed the wrong party", id)
+		}
+	})
+
+	t.Run("ValidReveal", func(t *testing.T) {
+		// the dealer proves the share it committed to, so the complaint is dismissed
+		handlers := runKeygen(t, partyIDs, 3, badDealer(false))
+		var publicKey curve.Point
+		for _, id := range partyIDs {
+			result, err := handlers[id].Result()
+			require.NoError(t, err, id)
+			pk, err := result.(*config.Config).PublicPoint()
+			require.NoError(t, err)
+			if publicKey != nil {
+				assert.True(t, publicKey.Equal(pk), "party %s has a different public key", id)
+			}
+			publicKey = pk
+		}
+	})
+}
@@ -2,6 +2,9 @@
 package keygen
 
 import (
+	"crypto/rand"
+	"io"
+
 	"github.com/luxfi/threshold/internal/round"
 	"github.com/luxfi/threshold/pkg/math/curve"
 	"github.com/luxfi/threshold/pkg/party"
@@ -12,23 +15,54 @@ import (
 
 // Start initiates the LSS key generation protocol.
 func Start(selfID party.ID, participants []party.ID, threshold int, group curve.Curve, pl *pool.Pool) protocol.StartFunc {
+	return StartWithRand(selfID, participants, threshold, group, pl, rand.Reader)
+}
+
+// StartWithRand is like Start, but draws all randomness of the party from rand,
+// so that the same reader always gives the same share.
+//
+// It is only meant for reproducible tests: anyone able to predict rand learns the share.
+func StartWithRand(selfID party.ID, participants []party.ID, threshold int, group curve.Curve, pl *pool.Pool, rand io.Reader) protocol.StartFunc {
+	return StartWeighted(selfID, participants, nil, threshold, group, pl, rand)
+}
+
+// StartWeighted is like StartWithRand, for parties holding several shares as given by weights.
+// The threshold counts shares, so that a party of weight w counts as w parties toward it.
+func StartWeighted(selfID party.ID, participants []party.ID, weights config.Weights, threshold int, group curve.Curve, pl *pool.Pool, rand io.Reader) protocol.StartFunc {
 	return func(sessionID []byte) (round.Session, error) {
+		if err := weights.Validate(participants); err != nil {
+			return nil, err
+		}
+
 		info := round.Info{
 			ProtocolID:       "lss/keygen",
-			FinalRoundNumber: 3,
+			FinalRoundNumber: 4,
 			SelfID:           selfID,
 			PartyIDs:         participants,
 			Threshold:        threshold,
 			Group:            group,
 		}
 
-		helper, err := round.NewSession(info, sessionID, pl)
+		var helper *round.Helper
+		var err error
+		if len(weights.Copy()) == 0 {
+			helper, err = round.NewSession(info, sessionID, pl)
+		} else {
+			// The threshold counts shares, which may be more than the parties, and every party deals,
+			// so the threshold of the session is that of the parties and the weighted one goes into the hash.
+			info.Threshold = len(participants) - 1
+			helper, err = round.NewSession(info, sessionID, pl, config.WeightedThreshold{Threshold: threshold, Weights: weights})
+		}
 		if err != nil {
 			return nil, err
 		}
 
 		return &round1{
-			Helper: helper,
+			Helper:        helper,
+			threshold:     threshold,
+			rand:          rand,
+			weights:       weights.Copy(),
+			evaluationIDs: weights.AllEvaluationIDs(participants),
 		}, nil
 	}
 }
@@ -1,6 +1,9 @@
 package keygen_test
 
 import (
+	"encoding/json"
+	mrand "math/rand"
+	"sync"
 	"testing"
 	"time"
 
@@ -160,4 +163,125 @@ func TestKeygenParameters(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+// TestLSSKeygenThreeParty runs the 2-of-3 secp256k1 keygen of three concurrent parties
+// over the in-memory network, as in the reported keygen timeout.
+func TestLSSKeygenThreeParty(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(3)
+	threshold := 2
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	network := test.NewNetwork(partyIDs)
+
+	type result struct {
+		id     party.ID
+		config *config.Config
+		err    error
+	}
+	results := make(chan result, len(partyIDs))
+	for _, id := range partyIDs {
+		go func(id party.ID) {
+			h, err := protocol.NewMultiHandler(keygen.Start(id, partyIDs, threshold, group, pl), nil)
+			if err != nil {
+				results <- result{id: id, err: err}
+				return
+			}
+			test.HandlerLoop(id, h, network)
+			r, err := h.Result()
+			if err != nil {
+				results <- result{id: id, err: err}
+				return
+			}
+			results <- result{id: id, config: r.(*config.Config)}
+		}(id)
+	}
+
+	configs := make(map[party.ID]*config.Config, len(partyIDs))
+	deadline := time.After(30 * time.Second)
+	for range partyIDs {
+		select {
+		case r := <-results:
+			require.NoError(t, r.err, "party %s failed", r.id)
+			configs[r.id] = r.config
+		case <-deadline:
+			t.Fatal("keygen did not complete before the deadline")
+		}
+	}
+
+	reference := configs[partyIDs[0]]
+	publicKey, err := reference.PublicKey()
+	require.NoError(t, err)
+	for _, id := range partyIDs {
+		c := configs[id]
+		require.NoError(t, c.Validate())
+		assert.Equal(t, id, c.ID)
+		assert.Equal(t, threshold, c.Threshold)
+		assert.Equal(t, reference.ChainKey, c.ChainKey, "chain keys should match")
+		assert.Equal(t, reference.RID, c.RID, "RIDs should match")
+
+		pk, err := c.PublicKey()
+		require.NoError(t, err)
+		assert.True(t, publicKey.Equal(pk), "public keys should match")
+
+		require.Len(t, c.Public, len(partyIDs))
+		for _, j := range partyIDs {
+			assert.True(t, reference.Public[j].ECDSA.Equal(c.Public[j].ECDSA), "public shares should match")
+		}
+		assert.True(t, c.ECDSA.ActOnBase().Equal(c.Public[id].ECDSA), "private share should match public share")
+	}
+}
+
+// runSeededKeygen runs keygen between partyIDs, with each party drawing its randomness from a reader
+// seeded from seed and its index, and returns the JSON encoded configs.
+func runSeededKeygen(t *testing.T, partyIDs party.IDSlice, threshold int, seed int64) map[party.ID][]byte {
+	group := curve.Secp256k1{}
+	network := test.NewNetwork(partyIDs)
+
+	var mtx sync.Mutex
+	configs := make(map[party.ID][]byte, len(partyIDs))
+	var wg sync.WaitGroup
+	for i, id := range partyIDs {
+		wg.Add(1)
+		go func(i int, id party.ID) {
+			defer wg.Done()
+			pl := pool.NewPool(1)
+			defer pl.TearDown()
+			rand := mrand.New(mrand.NewSource(seed + int64(i)))
+			h, err := protocol.NewMultiHandler(keygen.StartWithRand(id, partyIDs, threshold, group, pl, rand), nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+			test.HandlerLoop(id, h, network)
+			result, err := h.Result()
+			if !assert.NoError(t, err) {
+				return
+			}
+			data, err := json.Marshal(result.(*config.Config))
+			if !assert.NoError(t, err) {
+				return
+			}
+			mtx.Lock()
+			configs[id] = data
+			mtx.Unlock()
+		}(i, id)
+	}
+	wg.Wait()
+	require.Len(t, configs, len(partyIDs))
+	return configs
+}
+
+func TestKeygenWithRand(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+
+	first := runSeededKeygen(t, partyIDs, 2, 1)
+	second := runSeededKeygen(t, partyIDs, 2, 1)
+	for _, id := range partyIDs {
+		assert.JSONEq(t, string(first[id]), string(second[id]), "the same seed must give the same config")
+	}
+
+	other := runSeededKeygen(t, partyIDs, 2, 2)
+	assert.NotEqual(t, string(first[partyIDs[0]]), string(other[partyIDs[0]]))
+}
@@ -1,8 +1,9 @@
 package keygen
 
 import (
-	"crypto/rand"
 	"errors"
+	"fmt"
+	"io"
 
 	"github.com/luxfi/threshold/internal/round"
 	"github.com/luxfi/threshold/internal/types"
@@ -10,12 +11,23 @@ import (
 	"github.com/luxfi/threshold/pkg/math/polynomial"
 	"github.com/luxfi/threshold/pkg/math/sample"
 	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/protocols/lss/config"
 )
 
 // round1 generates polynomial and broadcasts commitments
 type round1 struct {
 	*round.Helper
 
+	// Source of our randomness
+	rand io.Reader
+
+	// Number of shares needed to sign, which is the threshold of the session unless parties are weighted
+	threshold int
+
+	// Weights of the parties, and the evaluation IDs of all shares
+	weights       config.Weights
+	evaluationIDs party.IDSlice
+
 	// Our polynomial for secret sharing
 	poly *polynomial.Polynomial
 
@@ -31,7 +43,7 @@ type round1 struct {
 type broadcast1 struct {
 	round.NormalBroadcastContent
 
-	// Commitments to polynomial - we commit to g^f(i) for each party i
+	// Commitments to polynomial - we commit to g^f(i) for each evaluation ID i
 	// Stored as binary data for CBOR compatibility
 	Commitments map[party.ID][]byte
 
@@ -103,20 +115,20 @@ func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
 	// If we haven't generated our polynomial yet, do it now
 	if r.poly == nil {
 		// Generate our polynomial with random secret
-		secret := sample.Scalar(rand.Reader, r.Group())
-		r.poly = polynomial.NewPolynomial(r.Group(), r.Threshold()-1, secret)
+		secret := sample.Scalar(r.rand, r.Group())
+		r.poly = polynomial.NewPolynomialWithRand(r.Group(), r.threshold-1, secret, r.rand)
 
 		// Generate chain key
-		chainKey, err := types.NewRID(rand.Reader)
+		chainKey, err := types.NewRID(r.rand)
 		if err != nil {
 			return nil, err
 		}
 		r.chainKey = chainKey
 
-		// Create commitments: g^f(j) for each party j
+		// Create commitments: g^f(j) for each evaluation ID j
 		// This allows verification of shares later
 		commitments := make(map[party.ID]curve.Point)
-		for _, j := range r.PartyIDs() {
+		for _, j := range r.evaluationIDs {
 			x := j.Scalar(r.Group())
 			share := r.poly.Evaluate(x)
 			commitments[j] = share.ActOnBase()
@@ -151,10 +163,13 @@ func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
 	// We have all commitments, create round2 with complete data
 	return &round2{
 		Helper:      r.Helper,
+		threshold:   r.threshold,
+		weights:     r.weights,
 		poly:        r.poly,
 		commitments: r.receivedCommitments,
 		chainKeys:   r.receivedChainKeys,
-		shares:      make(map[party.ID]curve.Scalar),
+		shares:      make(map[party.ID]map[party.ID]curve.Scalar),
+		complaints:  make(map[party.ID][]party.ID),
 	}, nil
 }
 
@@ -167,9 +182,14 @@ func (r *round1) StoreBroadcastMessage(msg round.Message) error {
 	}
 	
 	// Basic validation
-	if len(body.Commitments) != r.N() {
+	if len(body.Commitments) != len(r.evaluationIDs) {
 		return errors.New("wrong number of commitments")
 	}
+	for _, j := range r.evaluationIDs {
+		if _, ok := body.Commitments[j]; !ok {
+			return fmt.Errorf("missing commitment for %s", j)
+		}
+	}
 	
 	// Initialize storage if needed
 	if r.receivedCommitments == nil {
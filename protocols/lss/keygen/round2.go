@@ -2,35 +2,47 @@ package keygen
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/luxfi/threshold/internal/round"
 	"github.com/luxfi/threshold/internal/types"
 	"github.com/luxfi/threshold/pkg/math/curve"
 	"github.com/luxfi/threshold/pkg/math/polynomial"
 	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/protocols/lss/config"
 )
 
 // round2 receives commitments and sends shares
 type round2 struct {
 	*round.Helper
 
+	// Number of shares needed to sign, and weights of the parties
+	threshold int
+	weights   config.Weights
+
 	// Our polynomial from round 1
 	poly *polynomial.Polynomial
 
-	// Commitments from all parties: commitments[i][j] = g^f_i(j)
+	// Commitments from all parties: commitments[i][j] = g^f_i(j), for each evaluation ID j
 	commitments map[party.ID]map[party.ID]curve.Point
 
 	// Chain keys from all parties
 	chainKeys map[party.ID]types.RID
 
-	// Shares we receive
-	shares map[party.ID]curve.Scalar
+	// Shares we receive: shares[i][e] = f_i(e), for each of our evaluation IDs e
+	shares map[party.ID]map[party.ID]curve.Scalar
+
+	// Complaints of all parties: complaints[l] lists the parties whose share party l found invalid
+	complaints map[party.ID][]party.ID
 }
 
 // message2 contains the secret share for a party
 type message2 struct {
 	// Share encoded as binary for CBOR compatibility
 	Share []byte
+
+	// ExtraShares are the shares of a weighted party at its other evaluation IDs, encoded like Share
+	ExtraShares map[party.ID][]byte
 }
 
 // Round2 doesn't broadcast, so we don't implement BroadcastContent
@@ -63,47 +75,72 @@ func (r *round2) VerifyMessage(msg round.Message) error {
 		return errors.New("message not for us")
 	}
 
-	// Unmarshal the share
-	share := r.Group().NewScalar()
-	if err := share.UnmarshalBinary(body.Share); err != nil {
-		return errors.New("invalid share encoding")
+	// The shares are checked against the commitments in StoreMessage
+	if _, err := r.decodeShares(body); err != nil {
+		return err
 	}
-
-	// Verify share against commitment
-	commitments, ok := r.commitments[from]
-	if !ok {
+	if _, ok := r.commitments[from]; !ok {
 		return errors.New("missing commitments from sender")
 	}
 
-	// Check g^share = commitment[to]
-	expectedCommitment, ok := commitments[to]
-	if !ok {
-		return errors.New("missing commitment for our ID")
-	}
+	return nil
+}
 
-	sharePoint := share.ActOnBase()
-	if !sharePoint.Equal(expectedCommitment) {
-		return errors.New("share doesn't match commitment")
+// decodeShares returns the shares of body by evaluation ID, which must be exactly ours.
+func (r *round2) decodeShares(body *message2) (map[party.ID]curve.Scalar, error) {
+	ids := r.weights.EvaluationIDs(r.SelfID())
+	if len(body.ExtraShares) != len(ids)-1 {
+		return nil, fmt.Errorf("got %d extra shares, expected %d", len(body.ExtraShares), len(ids)-1)
 	}
-
-	return nil
+	shares := make(map[party.ID]curve.Scalar, len(ids))
+	for i, id := range ids {
+		data, ok := body.Share, true
+		if i > 0 {
+			data, ok = body.ExtraShares[id]
+		}
+		if !ok {
+			return nil, fmt.Errorf("missing extra share %s", id)
+		}
+		share := r.Group().NewScalar()
+		if err := share.UnmarshalBinary(data); err != nil {
+			return nil, errors.New("invalid share encoding")
+		}
+		shares[id] = share
+	}
+	return shares, nil
 }
 
 // StoreMessage implements round.Round
+//
+// A share which doesn't match its commitment is not an error: it is recorded as a complaint
+// against its sender, which all parties resolve in the next rounds, so that they agree on the culprit.
 func (r *round2) StoreMessage(msg round.Message) error {
 	from := msg.From
 	body := msg.Content.(*message2)
 
-	// Unmarshal the share
-	share := r.Group().NewScalar()
-	if err := share.UnmarshalBinary(body.Share); err != nil {
-		return errors.New("invalid share encoding")
+	shares, err := r.decodeShares(body)
+	if err != nil {
+		return err
+	}
+
+	// Check g^share = commitment[e] for each of our evaluation IDs e
+	for e, share := range shares {
+		if !validShare(r.commitments, from, e, share) {
+			r.complaints[r.SelfID()] = append(r.complaints[r.SelfID()], from)
+			return nil
+		}
 	}
 
-	r.shares[from] = share
+	r.shares[from] = shares
 	return nil
 }
 
+// validShare returns true if g^share is the commitment of dealer to the share of id.
+func validShare(commitments map[party.ID]map[party.ID]curve.Point, dealer, id party.ID, share curve.Scalar) bool {
+	commitment, ok := commitments[dealer][id]
+	return ok && share.ActOnBase().Equal(commitment)
+}
+
 // Finalize implements round.Round
 func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
 	// Send shares to each party
@@ -117,22 +154,40 @@ func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
 			return nil, errors.New("failed to marshal share")
 		}
 
+		var extraShares map[party.ID][]byte
+		for _, e := range r.weights.EvaluationIDs(id)[1:] {
+			if extraShares == nil {
+				extraShares = make(map[party.ID][]byte)
+			}
+			if extraShares[e], err = r.poly.Evaluate(e.Scalar(r.Group())).MarshalBinary(); err != nil {
+				return nil, errors.New("failed to marshal share")
+			}
+		}
+
 		if err := r.SendMessage(out, &message2{
-			Share: shareBytes,
+			Share:       shareBytes,
+			ExtraShares: extraShares,
 		}, id); err != nil {
 			return nil, err
 		}
 	}
 
-	// Our own share
-	ownX := r.SelfID().Scalar(r.Group())
-	r.shares[r.SelfID()] = r.poly.Evaluate(ownX)
+	// Our own shares
+	own := make(map[party.ID]curve.Scalar)
+	for _, e := range r.weights.EvaluationIDs(r.SelfID()) {
+		own[e] = r.poly.Evaluate(e.Scalar(r.Group()))
+	}
+	r.shares[r.SelfID()] = own
 
 	return &round3{
 		Helper:      r.Helper,
+		threshold:   r.threshold,
+		weights:     r.weights,
+		poly:        r.poly,
 		commitments: r.commitments,
 		chainKeys:   r.chainKeys,
 		shares:      r.shares,
+		complaints:  r.complaints,
 	}, nil
 }
 
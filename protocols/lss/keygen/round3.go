@@ -2,27 +2,78 @@ package keygen
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/luxfi/threshold/internal/round"
 	"github.com/luxfi/threshold/internal/types"
 	"github.com/luxfi/threshold/pkg/hash"
 	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/math/polynomial"
 	"github.com/luxfi/threshold/pkg/party"
 	"github.com/luxfi/threshold/protocols/lss/config"
 )
 
-// round3 finalizes the keygen protocol
+// round3 broadcasts complaints about invalid shares, and finalizes the keygen protocol if there are none
 type round3 struct {
 	*round.Helper
 
+	// Number of shares needed to sign, and weights of the parties
+	threshold int
+	weights   config.Weights
+
+	// Our polynomial from round 1, to reveal the shares we are accused of dealing wrong
+	poly *polynomial.Polynomial
+
 	// Data from previous rounds
 	commitments map[party.ID]map[party.ID]curve.Point
 	chainKeys   map[party.ID]types.RID
-	shares      map[party.ID]curve.Scalar
+	shares      map[party.ID]map[party.ID]curve.Scalar
+
+	// Complaints of all parties, including our own from round 2
+	complaints map[party.ID][]party.ID
+
+	// Whether we broadcast our complaints already
+	sent bool
 }
 
-// Round3 doesn't broadcast, so we don't implement BroadcastContent
-// This ensures round3 doesn't implement the BroadcastRound interface
+// broadcast3 contains the complaints of a party
+type broadcast3 struct {
+	round.NormalBroadcastContent
+
+	// Parties who sent us a share which doesn't match their commitment
+	Complaints []party.ID
+}
+
+// BroadcastContent implements round.BroadcastRound
+func (r *round3) BroadcastContent() round.BroadcastContent {
+	return &broadcast3{}
+}
+
+// RoundNumber implements round.Content
+func (broadcast3) RoundNumber() round.Number {
+	return 3
+}
+
+// StoreBroadcastMessage implements round.BroadcastRound
+func (r *round3) StoreBroadcastMessage(msg round.Message) error {
+	from := msg.From
+	body, ok := msg.Content.(*broadcast3)
+	if !ok || body == nil {
+		return round.ErrInvalidContent
+	}
+
+	complaints := party.NewIDSlice(body.Complaints)
+	if !complaints.Valid() {
+		return fmt.Errorf("party %s sent duplicate complaints", from)
+	}
+	for _, j := range complaints {
+		if j == from || !r.PartyIDs().Contains(j) {
+			return fmt.Errorf("party %s complained about unknown party %s", from, j)
+		}
+	}
+	r.complaints[from] = complaints
+	return nil
+}
 
 // Number implements round.Round
 func (r *round3) Number() round.Number {
@@ -47,22 +98,58 @@ func (r *round3) StoreMessage(_ round.Message) error {
 }
 
 // Finalize implements round.Round
-func (r *round3) Finalize(_ chan<- *round.Message) (round.Session, error) {
+//
+// The first call broadcasts our complaints. Once all complaints are received, the protocol ends
+// if there are none, and otherwise the accused parties reveal their shares in round4.
+func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
+	if !r.sent {
+		r.sent = true
+		complaints := party.NewIDSlice(r.complaints[r.SelfID()])
+		if err := r.BroadcastMessage(out, &broadcast3{Complaints: complaints}); err != nil {
+			return nil, err
+		}
+		r.complaints[r.SelfID()] = complaints
+		return r, nil
+	}
+
+	for _, complaints := range r.complaints {
+		if len(complaints) > 0 {
+			return &round4{
+				round3:  r,
+				reveals: make(map[party.ID]map[party.ID]curve.Scalar),
+			}, nil
+		}
+	}
+	return r.output()
+}
+
+// output computes our share of the key, once all parties have accepted their shares.
+func (r *round3) output() (round.Session, error) {
 	// Verify we have shares from all parties
 	if len(r.shares) != r.N() {
 		return nil, errors.New("missing shares from some parties")
 	}
 
-	// Compute our final ECDSA share: sum of all shares received
-	ecdsaShare := r.Group().NewScalar()
-	for _, share := range r.shares {
-		ecdsaShare = ecdsaShare.Add(share)
+	// Compute our final shares: sum of all shares received, at each of our evaluation IDs
+	ourShares := make(map[party.ID]curve.Scalar)
+	for _, e := range r.weights.EvaluationIDs(r.SelfID()) {
+		ourShares[e] = r.Group().NewScalar()
+		for _, shares := range r.shares {
+			ourShares[e] = ourShares[e].Add(shares[e])
+		}
+	}
+	ecdsaShare := ourShares[r.SelfID()]
+	delete(ourShares, r.SelfID())
+	var extraShares map[party.ID]curve.Scalar
+	if len(ourShares) > 0 {
+		extraShares = ourShares
 	}
 
 	// Build public shares map
-	// The public share for party j is the sum of all g^f_i(j)
-	publicShares := make(map[party.ID]*config.Public, r.N())
-	for _, j := range r.PartyIDs() {
+	// The public share for evaluation ID j is the sum of all g^f_i(j)
+	evaluationIDs := r.weights.AllEvaluationIDs(r.PartyIDs())
+	publicShares := make(map[party.ID]*config.Public, len(evaluationIDs))
+	for _, j := range evaluationIDs {
 		publicPoint := r.Group().NewPoint()
 		for _, commitments := range r.commitments {
 			if commitment, ok := commitments[j]; ok {
@@ -97,14 +184,16 @@ func (r *round3) Finalize(_ chan<- *round.Message) (round.Session, error) {
 
 	// Create the final config
 	cfg := &config.Config{
-		ID:         r.SelfID(),
-		Group:      r.Group(),
-		Threshold:  r.Threshold(),
-		Generation: 0, // Initial generation
-		ECDSA:      ecdsaShare,
-		Public:     publicShares,
-		ChainKey:   finalChainKey[:],
-		RID:        finalRID[:],
+		ID:          r.SelfID(),
+		Group:       r.Group(),
+		Threshold:   r.threshold,
+		Weights:     r.weights,
+		Generation:  0, // Initial generation
+		ECDSA:       ecdsaShare,
+		ExtraShares: extraShares,
+		Public:      publicShares,
+		ChainKey:    finalChainKey[:],
+		RID:         finalRID[:],
 	}
 
 	// Validate the config before returning
@@ -119,5 +208,3 @@ func (r *round3) Finalize(_ chan<- *round.Message) (round.Session, error) {
 
 	return r.ResultRound(cfg), nil
 }
-
-// Round3 doesn't need StoreBroadcastMessage since it's not a BroadcastRound
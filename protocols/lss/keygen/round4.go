@@ -0,0 +1,127 @@
+package keygen
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+)
+
+// round4 resolves the complaints of round3: accused parties reveal the shares they dealt to their accusers,
+// and every party checks them against the commitments of round1
+type round4 struct {
+	*round3
+
+	// Revealed shares: reveals[j][e] is the share party j dealt at evaluation ID e
+	reveals map[party.ID]map[party.ID]curve.Scalar
+
+	// Whether we revealed our shares already
+	revealed bool
+}
+
+// broadcast4 contains the shares a party reveals
+type broadcast4 struct {
+	round.NormalBroadcastContent
+
+	// Shares we dealt to each party who complained about us, by evaluation ID,
+	// encoded as binary for CBOR compatibility
+	Shares map[party.ID][]byte
+}
+
+// BroadcastContent implements round.BroadcastRound
+func (r *round4) BroadcastContent() round.BroadcastContent {
+	return &broadcast4{}
+}
+
+// RoundNumber implements round.Content
+func (broadcast4) RoundNumber() round.Number {
+	return 4
+}
+
+// Number implements round.Round
+func (r *round4) Number() round.Number {
+	return 4
+}
+
+// StoreBroadcastMessage implements round.BroadcastRound
+func (r *round4) StoreBroadcastMessage(msg round.Message) error {
+	from := msg.From
+	body, ok := msg.Content.(*broadcast4)
+	if !ok || body == nil {
+		return round.ErrInvalidContent
+	}
+
+	reveals := make(map[party.ID]curve.Scalar, len(body.Shares))
+	evaluationIDs := r.weights.AllEvaluationIDs(r.PartyIDs())
+	for l, data := range body.Shares {
+		if !evaluationIDs.Contains(l) {
+			return fmt.Errorf("party %s revealed a share for unknown party %s", from, l)
+		}
+		share := r.Group().NewScalar()
+		if err := share.UnmarshalBinary(data); err != nil {
+			return fmt.Errorf("party %s revealed an invalid share for %s: %w", from, l, err)
+		}
+		reveals[l] = share
+	}
+	r.reveals[from] = reveals
+	return nil
+}
+
+// Finalize implements round.Round
+//
+// The first call reveals the shares we are accused of dealing wrong. Once all parties revealed theirs,
+// an accused party which didn't reveal a share matching its commitment is a culprit.
+// If all revealed shares are valid, the complaints are dismissed, and parties who complained
+// use the revealed shares instead of the ones they received.
+func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
+	if !r.revealed {
+		r.revealed = true
+		reveals := make(map[party.ID]curve.Scalar)
+		shares := make(map[party.ID][]byte)
+		for _, l := range r.PartyIDs() {
+			if !slices.Contains(r.complaints[l], r.SelfID()) {
+				continue
+			}
+			for _, e := range r.weights.EvaluationIDs(l) {
+				reveals[e] = r.poly.Evaluate(e.Scalar(r.Group()))
+				data, err := reveals[e].MarshalBinary()
+				if err != nil {
+					return nil, errors.New("failed to marshal share")
+				}
+				shares[e] = data
+			}
+		}
+		if err := r.BroadcastMessage(out, &broadcast4{Shares: shares}); err != nil {
+			return nil, err
+		}
+		r.reveals[r.SelfID()] = reveals
+		return r, nil
+	}
+
+	var culprits []party.ID
+	for _, l := range r.PartyIDs() {
+		for _, j := range r.complaints[l] {
+			for _, e := range r.weights.EvaluationIDs(l) {
+				share, ok := r.reveals[j][e]
+				if (!ok || !validShare(r.commitments, j, e, share)) && !slices.Contains(culprits, j) {
+					culprits = append(culprits, j)
+				}
+			}
+		}
+	}
+	if len(culprits) > 0 {
+		return r.AbortRound(errors.New("keygen: invalid shares were dealt"), party.NewIDSlice(culprits)...), nil
+	}
+
+	for _, j := range r.complaints[r.SelfID()] {
+		shares := make(map[party.ID]curve.Scalar)
+		for _, e := range r.weights.EvaluationIDs(r.SelfID()) {
+			shares[e] = r.reveals[j][e]
+		}
+		r.shares[j] = shares
+	}
+	return r.output()
+}
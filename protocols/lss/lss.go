@@ -12,8 +12,12 @@
 package lss
 
 import (
+	"crypto/rand"
 	"errors"
 	"fmt"
+	"io"
+	"slices"
+	"strings"
 
 	"github.com/luxfi/threshold/internal/round"
 	"github.com/luxfi/threshold/pkg/math/curve"
@@ -37,19 +41,68 @@ func EmptyConfig(group curve.Curve) *config.Config {
 
 // Keygen generates a new shared ECDSA key with LSS protocol.
 func Keygen(group curve.Curve, selfID party.ID, participants []party.ID, threshold int, pl *pool.Pool) protocol.StartFunc {
-	if threshold < 1 || threshold > len(participants) {
+	return KeygenWithRand(group, selfID, participants, threshold, pl, rand.Reader)
+}
+
+// KeygenWithRand is like Keygen, but draws all randomness of the party from rand.
+//
+// It is for reproducible tests and fuzzing only: a predictable rand gives away the key share,
+// so production code must always use Keygen.
+func KeygenWithRand(group curve.Curve, selfID party.ID, participants []party.ID, threshold int, pl *pool.Pool, rand io.Reader) protocol.StartFunc {
+	if err := validateKeygen(selfID, participants, threshold); err != nil {
+		return func(_ []byte) (round.Session, error) {
+			return nil, err
+		}
+	}
+
+	return keygen.StartWithRand(selfID, participants, threshold, group, pl, rand)
+}
+
+// KeygenWeighted is like Keygen, for parties holding several shares of the key.
+//
+// weights maps the parties holding more than one share to their number of shares, and the threshold
+// counts shares: a party of weight w holds the shares at w evaluation points, and counts as w parties
+// toward the threshold when signing. For instance, with weights {"a": 2} and a threshold of 3,
+// a signs with any one other party.
+func KeygenWeighted(group curve.Curve, selfID party.ID, participants []party.ID, weights map[party.ID]int, threshold int, pl *pool.Pool) protocol.StartFunc {
+	if err := validateKeygenWeighted(selfID, participants, weights, threshold); err != nil {
 		return func(_ []byte) (round.Session, error) {
-			return nil, fmt.Errorf("lss: invalid threshold %d for %d parties", threshold, len(participants))
+			return nil, err
 		}
 	}
 
-	return keygen.Start(selfID, participants, threshold, group, pl)
+	return keygen.StartWeighted(selfID, participants, weights, threshold, group, pl, rand.Reader)
+}
+
+// validateKeygen checks the arguments of Keygen, so that they fail before any round runs.
+func validateKeygen(selfID party.ID, participants []party.ID, threshold int) error {
+	return validateKeygenWeighted(selfID, participants, nil, threshold)
+}
+
+// validateKeygenWeighted checks the arguments of KeygenWeighted, so that they fail before any round runs.
+func validateKeygenWeighted(selfID party.ID, participants []party.ID, weights config.Weights, threshold int) error {
+	if err := round.ValidateParties(selfID, participants); err != nil {
+		return fmt.Errorf("lss: %w", err)
+	}
+	if err := weights.Validate(participants); err != nil {
+		return err
+	}
+	if threshold < 1 {
+		return fmt.Errorf("lss: threshold %d must be at least 1", threshold)
+	}
+	if shares := weights.Total(participants); threshold > shares {
+		if shares == len(participants) {
+			return fmt.Errorf("lss: threshold %d exceeds parties %d", threshold, len(participants))
+		}
+		return fmt.Errorf("lss: threshold %d exceeds shares %d", threshold, shares)
+	}
+	return nil
 }
 
-// Refresh refreshes the key shares without changing the public key or membership.
+// Refresh refreshes the key shares without changing the public key, membership or weights.
 func Refresh(c *config.Config, pl *pool.Pool) protocol.StartFunc {
 	participants := c.PartyIDs()
-	return reshare.Start(c, participants, c.Threshold, pl)
+	return reshare.StartWeighted(c, participants, c.Weights, c.Threshold, pl)
 }
 
 // Reshare performs dynamic resharing to change the participant set.
@@ -63,11 +116,114 @@ func Reshare(c *config.Config, newParticipants []party.ID, newThreshold int, pl
 	return reshare.Start(c, newParticipants, newThreshold, pl)
 }
 
+// ReshareWeighted is like Reshare, for new parties holding several shares as given by newWeights,
+// with newThreshold counting shares as in KeygenWeighted.
+func ReshareWeighted(c *config.Config, newParticipants []party.ID, newWeights map[party.ID]int, newThreshold int, pl *pool.Pool) protocol.StartFunc {
+	if shares := config.Weights(newWeights).Total(newParticipants); newThreshold < 1 || newThreshold > shares {
+		return func(_ []byte) (round.Session, error) {
+			return nil, fmt.Errorf("lss: invalid threshold %d for %d shares", newThreshold, shares)
+		}
+	}
+
+	return reshare.StartWeighted(c, newParticipants, newWeights, newThreshold, pl)
+}
+
+// ReshareMembership adds and removes parties in a single resharing, preserving the public key
+// and incrementing the generation.
+//
+// The new group is the current one without remove and with add. Every current party, including the
+// removed ones, must take part, and added parties start from JoinConfig.
+// Removed parties output the public data of the new group, without a share.
+// Remaining parties keep their weight, and added parties have a weight of 1.
+func ReshareMembership(c *config.Config, add, remove []party.ID, newThreshold int, pl *pool.Pool) protocol.StartFunc {
+	fail := func(err error) protocol.StartFunc {
+		return func(_ []byte) (round.Session, error) {
+			return nil, err
+		}
+	}
+	newParticipants, err := MembershipChange(c.PartyIDs(), add, remove)
+	if err != nil {
+		return fail(err)
+	}
+	var weights config.Weights
+	for id, weight := range c.Weights.Copy() {
+		if slices.Contains(newParticipants, id) {
+			if weights == nil {
+				weights = make(config.Weights)
+			}
+			weights[id] = weight
+		}
+	}
+	if newThreshold < 1 || newThreshold > weights.Total(newParticipants) {
+		return fail(fmt.Errorf("lss: invalid threshold %d for %d parties", newThreshold, len(newParticipants)))
+	}
+
+	return reshare.StartWeighted(c, newParticipants, weights, newThreshold, pl)
+}
+
+// MembershipChange returns the group current without remove and with add, as reshared by ReshareMembership.
+//
+// Added parties must not be members of current, and removed parties must be, each listed once:
+// a party added twice would hold two shares at the same evaluation point, which breaks interpolation,
+// and removing a party which isn't a member is most likely a typo, which would otherwise go unnoticed.
+// The result holds the remaining parties of current, sorted, followed by add.
+func MembershipChange(current, add, remove []party.ID) ([]party.ID, error) {
+	members := party.NewIDSlice(current)
+	for i, id := range remove {
+		if !members.Contains(id) {
+			return nil, fmt.Errorf("lss: removed party %s is not a member", id)
+		}
+		if slices.Contains(remove[:i], id) {
+			return nil, fmt.Errorf("lss: party %s is removed more than once", id)
+		}
+	}
+	for i, id := range add {
+		if members.Contains(id) {
+			return nil, fmt.Errorf("lss: added party %s is already a member", id)
+		}
+		if slices.Contains(add[:i], id) {
+			return nil, fmt.Errorf("lss: party %s is added more than once", id)
+		}
+	}
+
+	newParticipants := make([]party.ID, 0, len(members)+len(add))
+	for _, id := range members {
+		if !slices.Contains(remove, id) {
+			newParticipants = append(newParticipants, id)
+		}
+	}
+	return append(newParticipants, add...), nil
+}
+
+// JoinConfig returns the config a party id joining the group of c starts a reshare from:
+// the public data of c, without a share.
+func JoinConfig(c *config.Config, id party.ID) *config.Config {
+	joined := c.Copy()
+	joined.ID = id
+	joined.ECDSA = nil
+	return joined
+}
+
 // Sign generates an ECDSA signature using the LSS protocol.
+//
+// With weighted parties, the signers must hold at least c.Threshold shares together.
+// All the signers must be at the generation of c. Since each party only holds its own config,
+// this is checked in the rounds: the session fails with an error naming a signer whose nonce
+// comes from a config of another generation, once the nonce commitments are exchanged.
+// Callers holding the configs of all signers should reject a mix of generations with
+// CheckGenerations before starting.
+//
+// Concurrent signings of the same message over one network must be started with different session IDs,
+// see protocol.NewSessionID: the SSID binds the signers and the message, but nothing else sets them apart.
 func Sign(c *config.Config, signers []party.ID, messageHash []byte, pl *pool.Pool) protocol.StartFunc {
-	if len(signers) < c.Threshold {
+	if shares := c.Weights.Total(signers); shares < c.Threshold {
+		if len(c.Weights.Copy()) > 0 {
+			return func(_ []byte) (round.Session, error) {
+				return nil, fmt.Errorf("lss: need at least %d shares, got %d", c.Threshold, shares)
+			}
+		}
 		return func(_ []byte) (round.Session, error) {
-			return nil, fmt.Errorf("lss: insufficient signers: have %d, need %d", len(signers), c.Threshold)
+			return nil, fmt.Errorf("lss: need at least %d signers, got %d", c.Threshold, len(signers))
 		}
 	}
 
@@ -80,6 +236,39 @@ func Sign(c *config.Config, signers []party.ID, messageHash []byte, pl *pool.Poo
 	return sign.Start(c, signers, messageHash, pl)
 }
 
+// SignDigest is like Sign, for a digest the caller already computed with the hash its chain prescribes,
+// such as Keccak-256 for Ethereum or double SHA-256 for Bitcoin. The digest is signed as is, without hashing it again.
+func SignDigest(c *config.Config, signers []party.ID, digest [32]byte, pl *pool.Pool) protocol.StartFunc {
+	return Sign(c, signers, digest[:], pl)
+}
+
+// CheckGenerations returns an error naming the parties of each generation if configs, the configs of a signing set,
+// aren't all of the same generation.
+//
+// Sign rejects a signer of another generation when its nonce arrives; callers holding the configs of all the
+// signers, such as an in-process signing set, can use this to reject the set before any round runs.
+func CheckGenerations(configs []*config.Config) error {
+	byGeneration := make(map[uint64][]string)
+	for _, c := range configs {
+		byGeneration[c.Generation] = append(byGeneration[c.Generation], string(c.ID))
+	}
+	if len(byGeneration) <= 1 {
+		return nil
+	}
+	generations := make([]uint64, 0, len(byGeneration))
+	for g := range byGeneration {
+		generations = append(generations, g)
+	}
+	slices.Sort(generations)
+	groups := make([]string, 0, len(generations))
+	for _, g := range generations {
+		ids := byGeneration[g]
+		slices.Sort(ids)
+		groups = append(groups, fmt.Sprintf("%d (%s)", g, strings.Join(ids, ", ")))
+	}
+	return fmt.Errorf("lss: configs of different generations: %s", strings.Join(groups, ", "))
+}
+
 // VerifyConfig validates that a Config is well-formed.
 func VerifyConfig(c *config.Config) error {
 	return c.Validate()
@@ -88,12 +277,8 @@ func VerifyConfig(c *config.Config) error {
 // IsCompatibleForSigning checks if two configs can sign together.
 func IsCompatibleForSigning(c1, c2 *config.Config) bool {
 	// Same public key and group
-	p1, err1 := c1.PublicPoint()
-	p2, err2 := c2.PublicPoint()
-	if err1 != nil || err2 != nil {
-		return false
-	}
-	if !p1.Equal(p2) {
+	id1, id2 := c1.KeyID(), c2.KeyID()
+	if id1 == "" || id1 != id2 {
 		return false
 	}
 	if c1.Group.Name() != c2.Group.Name() {
@@ -182,10 +182,12 @@ func BenchmarkFROSTDynamicReshare(b *testing.B) {
 		{"5->7 parties", 5, 7, 3},
 		{"7->10 parties", 7, 10, 5},
 		{"9->6 parties", 9, 6, 4},
+		{"51->51 parties", 51, 51, 26},
 	}
 
 	for _, tc := range cases {
 		b.Run(tc.name, func(b *testing.B) {
+			b.ReportAllocs()
 			group := curve.Secp256k1{}
 			
 			// Setup old FROST configs
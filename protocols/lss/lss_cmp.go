@@ -2,12 +2,15 @@
 package lss
 
 import (
+	"crypto/rand"
 	"errors"
 	"fmt"
 
 	"github.com/luxfi/threshold/pkg/math/curve"
-	"github.com/luxfi/threshold/pkg/math/polynomial"
+	"github.com/luxfi/threshold/pkg/math/sample"
+	"github.com/luxfi/threshold/pkg/paillier"
 	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/pedersen"
 	"github.com/luxfi/threshold/pkg/pool"
 	"github.com/luxfi/threshold/protocols/cmp/config"
 )
@@ -29,275 +32,170 @@ func NewLSSCMP(cmpConfig *config.Config, pool *pool.Pool) *CMP {
 	}
 }
 
-// DynamicReshare performs the LSS dynamic resharing protocol on CMP configurations.
+// DynamicReshareCMP performs the LSS dynamic resharing protocol on CMP configurations.
 // This implements the protocol from Section 4 of the LSS paper, allowing
 // transition from T-of-N to T'-of-(N±k) without reconstructing the master key.
+// The key is never interpolated, but the caller holds threshold + 1 secret configs at once,
+// and so must be trusted as a dealer would be.
+//
+// Thresholds follow the CMP convention: newThreshold + 1 parties are needed to sign afterwards,
+// and oldConfigs must hold at least Threshold + 1 of the old configs.
+// Parties whose old config is given keep their auxiliary ElGamal, Paillier and Pedersen keys,
+// and fresh ones are generated for the other new parties, using pl.
+// The new configs keep the public key, chain key and RID, and move to the next generation.
+//
+// newPartyIDs is the whole new group, so it can't add a member twice or remove a party which isn't one,
+// and only has to be free of duplicates. DynamicReshareCMPMembership takes the parties added and removed instead.
 func DynamicReshareCMP(
 	oldConfigs map[party.ID]*config.Config,
 	newPartyIDs []party.ID,
 	newThreshold int,
-	_ *pool.Pool,
+	pl *pool.Pool,
 ) (map[party.ID]*config.Config, error) {
 
 	if len(oldConfigs) == 0 {
 		return nil, errors.New("lss-cmp: no old configurations provided")
 	}
 
-	if newThreshold < 1 || newThreshold > len(newPartyIDs) {
-		return nil, fmt.Errorf("lss-cmp: invalid threshold %d for %d parties", newThreshold, len(newPartyIDs))
+	newIDs := party.NewIDSlice(newPartyIDs)
+	if !newIDs.Valid() {
+		return nil, errors.New("lss-cmp: duplicate new party IDs")
+	}
+	if !config.ValidThreshold(newThreshold, len(newIDs)) {
+		return nil, fmt.Errorf("lss-cmp: invalid threshold %d for %d parties", newThreshold, len(newIDs))
 	}
 
 	// Get reference config and validate consistency
-	var refConfig *config.Config
-	var group curve.Curve
 	oldPartyIDs := make([]party.ID, 0, len(oldConfigs))
-
 	for pid, cfg := range oldConfigs {
-		if refConfig == nil {
-			refConfig = cfg
-			group = cfg.Group
-		} else {
-			// Verify all configs are from the same keygen
-			if !cfg.PublicPoint().Equal(refConfig.PublicPoint()) {
-				return nil, errors.New("lss-cmp: inconsistent public keys in old configs")
-			}
+		if cfg.ID != pid {
+			return nil, fmt.Errorf("lss-cmp: config of %s is stored under %s", cfg.ID, pid)
 		}
 		oldPartyIDs = append(oldPartyIDs, pid)
 	}
-
-	// Ensure we have enough old parties to reconstruct the secret
-	if len(oldPartyIDs) < refConfig.Threshold {
-		return nil, fmt.Errorf("lss-cmp: need at least %d old parties, have %d",
-			refConfig.Threshold, len(oldPartyIDs))
+	oldIDs := party.NewIDSlice(oldPartyIDs)
+	refConfig := oldConfigs[oldIDs[0]]
+	group := refConfig.Group
+	publicKey := refConfig.PublicPoint()
+	for _, cfg := range oldConfigs {
+		// Verify all configs are from the same keygen and generation
+		if !cfg.PublicPoint().Equal(publicKey) {
+			return nil, errors.New("lss-cmp: inconsistent public keys in old configs")
+		}
+		if cfg.Generation != refConfig.Generation {
+			return nil, fmt.Errorf("lss-cmp: old configs mix generations %d and %d", refConfig.Generation, cfg.Generation)
+		}
+		if cfg.Threshold != refConfig.Threshold {
+			return nil, fmt.Errorf("lss-cmp: old configs mix thresholds %d and %d", refConfig.Threshold, cfg.Threshold)
+		}
+		if pub, ok := refConfig.Public[cfg.ID]; !ok || !cfg.ECDSA.ActOnBase().Equal(pub.ECDSA) {
+			return nil, fmt.Errorf("lss-cmp: share of %s does not match its public share", cfg.ID)
+		}
 	}
 
-	// Step 1: Generate auxiliary secrets w and q using polynomial secret sharing
-	// These are temporary secrets used only during the resharing protocol
-	wPoly := polynomial.NewPolynomial(group, newThreshold-1, nil)
-	qPoly := polynomial.NewPolynomial(group, newThreshold-1, nil)
-
-	// All parties (old and new) get shares of w and q
-	allParties := make(map[party.ID]bool)
-	for _, pid := range oldPartyIDs {
-		allParties[pid] = true
+	publicShares := make(map[party.ID]curve.Point, len(refConfig.Public))
+	for id, pub := range refConfig.Public {
+		if pub == nil || pub.ECDSA == nil {
+			return nil, fmt.Errorf("lss-cmp: missing public share of %s", id)
+		}
+		publicShares[id] = pub.ECDSA
 	}
-	for _, pid := range newPartyIDs {
-		allParties[pid] = true
+	if err := checkShareDegree(publicKey, publicShares, refConfig.Threshold); err != nil {
+		return nil, fmt.Errorf("lss-cmp: old configs: %w", err)
 	}
 
-	wShares := make(map[party.ID]curve.Scalar)
-	qShares := make(map[party.ID]curve.Scalar)
-
-	for pid := range allParties {
-		wShares[pid] = wPoly.Evaluate(pid.Scalar(group))
-		qShares[pid] = qPoly.Evaluate(pid.Scalar(group))
+	// Ensure we have enough old parties to reconstruct the secret
+	if len(oldIDs) < refConfig.Threshold+1 {
+		return nil, fmt.Errorf("lss-cmp: need at least %d old parties, have %d",
+			refConfig.Threshold+1, len(oldIDs))
 	}
 
-	// Step 2: Compute the blinded secret a * w
-	// Each old party computes a_i * w_i, then we interpolate to get a * w
-	blindedProducts := make(map[party.ID]curve.Scalar)
-
-	// Use first threshold old parties
-	contributingParties := oldPartyIDs[:refConfig.Threshold]
-	for _, pid := range contributingParties {
-		cfg := oldConfigs[pid]
-		wShare := wShares[pid]
-
-		// Compute a_i * w_i
-		product := group.NewScalar().Set(cfg.ECDSA).Mul(wShare)
-		blindedProducts[pid] = product
+	// Step 1: The first Threshold + 1 old parties deal their additive shares to the new parties
+	contributors := oldIDs[:refConfig.Threshold+1]
+	oldShares := make(map[party.ID]curve.Scalar, len(contributors))
+	for _, pid := range contributors {
+		oldShares[pid] = oldConfigs[pid].ECDSA
 	}
+	newShares := redistributeShares(group, oldShares, contributors, newIDs, newThreshold)
 
-	// Interpolate the blinded products to get a * w
-	lagrange := polynomial.Lagrange(group, contributingParties)
-	aTimesW := group.NewScalar()
-
-	for pid, product := range blindedProducts {
-		if coeff, exists := lagrange[pid]; exists {
-			contribution := group.NewScalar().Set(coeff).Mul(product)
-			aTimesW.Add(contribution)
-		}
+	// Verify the resharing was correct
+	if err := verifyResharing(publicKey, newShares, newThreshold+1); err != nil {
+		return nil, fmt.Errorf("lss-cmp: resharing verification failed: %w", err)
 	}
 
-	// Step 3: Compute z = (q * w)^{-1}
-	// First, parties compute q_j * w_j and we interpolate to get q * w
-	qwProducts := make(map[party.ID]curve.Scalar)
-
-	// Use first newThreshold parties for this computation
-	computingParties := make([]party.ID, 0, newThreshold)
-	for pid := range allParties {
-		if len(computingParties) >= newThreshold {
-			break
+	// Step 2: Parties with an old config keep their auxiliary keys, and the others get fresh ones
+	elGamal := make(map[party.ID]curve.Scalar, len(newIDs))
+	paillierSecrets := make(map[party.ID]*paillier.SecretKey, len(newIDs))
+	public := make(map[party.ID]*config.Public, len(newIDs))
+	for _, pid := range newIDs {
+		if cfg, ok := oldConfigs[pid]; ok {
+			elGamal[pid], paillierSecrets[pid] = cfg.ElGamal, cfg.Paillier
+			old := cfg.Public[pid]
+			public[pid] = &config.Public{
+				ECDSA:    newShares[pid].ActOnBase(),
+				ElGamal:  old.ElGamal,
+				Paillier: old.Paillier,
+				Pedersen: old.Pedersen,
+			}
+			continue
 		}
-		computingParties = append(computingParties, pid)
-
-		qShare := qShares[pid]
-		wShare := wShares[pid]
-		product := group.NewScalar().Set(qShare).Mul(wShare)
-		qwProducts[pid] = product
-	}
-
-	// Interpolate to get q * w
-	newLagrange := polynomial.Lagrange(group, computingParties)
-	qTimesW := group.NewScalar()
-
-	for pid, product := range qwProducts {
-		if coeff, exists := newLagrange[pid]; exists {
-			contribution := group.NewScalar().Set(coeff).Mul(product)
-			qTimesW.Add(contribution)
+		paillierSecret := paillier.NewSecretKey(pl)
+		s, t, _ := sample.Pedersen(rand.Reader, paillierSecret.Phi(), paillierSecret.N())
+		elGamal[pid], paillierSecrets[pid] = sample.Scalar(rand.Reader, group), paillierSecret
+		public[pid] = &config.Public{
+			ECDSA:    newShares[pid].ActOnBase(),
+			ElGamal:  elGamal[pid].ActOnBase(),
+			Paillier: paillierSecret.PublicKey,
+			Pedersen: pedersen.New(paillierSecret.Modulus(), s, t),
 		}
 	}
 
-	// Compute z = (q * w)^{-1}
-	z := group.NewScalar().Set(qTimesW)
-	// Invert returns the inverted scalar, not an error
-	z = z.Invert()
-
-	// Create shares of z for distribution to new parties
-	zPoly := polynomial.NewPolynomial(group, newThreshold-1, z)
-	zShares := make(map[party.ID]curve.Scalar)
-
-	for _, pid := range newPartyIDs {
-		zShares[pid] = zPoly.Evaluate(pid.Scalar(group))
-	}
-
-	// Step 4: Each new party computes their new share
-	// a'_j = (a * w) * q_j * z_j
-	newConfigs := make(map[party.ID]*config.Config)
-
-	// First, compute all new shares
-	newShares := make(map[party.ID]curve.Scalar)
-	for _, pid := range newPartyIDs {
-		qShare := qShares[pid]
-		zShare := zShares[pid]
-
-		// Compute new ECDSA share: a'_j = (a * w) * q_j * z_j
-		newECDSAShare := group.NewScalar().Set(aTimesW)
-		newECDSAShare.Mul(qShare).Mul(zShare)
-		newShares[pid] = newECDSAShare
-	}
-
-	// Now create configs with all the public information
-	for _, pid := range newPartyIDs {
-		newConfig := &config.Config{
-			Group:     group,
-			ID:        pid,
-			Threshold: newThreshold,
-			ECDSA:     newShares[pid],
-
-			// For now, reuse auxiliary values from reference config
-			// In production, these should be refreshed independently
-			ElGamal:  refConfig.ElGamal,
-			Paillier: refConfig.Paillier,
-			RID:      refConfig.RID,
-			ChainKey: refConfig.ChainKey,
-			Public:   make(map[party.ID]*config.Public),
+	// Step 3: Create the config of every new party
+	newConfigs := make(map[party.ID]*config.Config, len(newIDs))
+	for _, pid := range newIDs {
+		publicCopy := make(map[party.ID]*config.Public, len(public))
+		for j, p := range public {
+			publicCopy[j] = p
 		}
-
-		// Store public key shares for all new parties
-		for _, otherPID := range newPartyIDs {
-			// Get the actual public values from the reference config if available
-			var publicInfo *config.Public
-			if refPublic, exists := refConfig.Public[refConfig.ID]; exists {
-				publicInfo = &config.Public{
-					ECDSA:    newShares[otherPID].ActOnBase(),
-					ElGamal:  refPublic.ElGamal,  // Temporary reuse
-					Paillier: refPublic.Paillier, // Temporary reuse
-					Pedersen: refPublic.Pedersen, // Temporary reuse
-				}
-			} else {
-				// Create minimal public info
-				publicInfo = &config.Public{
-					ECDSA:    newShares[otherPID].ActOnBase(),
-					ElGamal:  refConfig.ElGamal.ActOnBase(),
-					Paillier: refConfig.Paillier.PublicKey,
-					Pedersen: nil,
-				}
-			}
-			newConfig.Public[otherPID] = publicInfo
+		newConfigs[pid] = &config.Config{
+			Group:      group,
+			ID:         pid,
+			Threshold:  newThreshold,
+			ECDSA:      newShares[pid],
+			ElGamal:    elGamal[pid],
+			Paillier:   paillierSecrets[pid],
+			RID:        refConfig.RID.Copy(),
+			ChainKey:   refConfig.ChainKey.Copy(),
+			Generation: refConfig.Generation + 1,
+			Public:     publicCopy,
 		}
-
-		newConfigs[pid] = newConfig
-	}
-
-	// Verify the resharing was correct
-	if err := verifyResharingCMP(oldConfigs, newConfigs, refConfig.Threshold, newThreshold); err != nil {
-		return nil, fmt.Errorf("lss-cmp: resharing verification failed: %w", err)
 	}
 
 	return newConfigs, nil
 }
 
-// verifyResharingCMP validates that new shares correctly reconstruct the original public key
-func verifyResharingCMP(
+// DynamicReshareCMPMembership is like DynamicReshareCMP, for the group of oldConfigs with add and without remove.
+// Added parties must not be members already, and removed parties must be, as checked by MembershipChange.
+func DynamicReshareCMPMembership(
 	oldConfigs map[party.ID]*config.Config,
-	newConfigs map[party.ID]*config.Config,
-	oldThreshold int,
+	add, remove []party.ID,
 	newThreshold int,
-) error {
-	// Get the original public key from old configs
-	var oldPublicKey curve.Point
-	var group curve.Curve
-	
-	// Get first old config to extract public key and group
-	for _, cfg := range oldConfigs {
-		oldPublicKey = cfg.PublicPoint()
-		group = cfg.Group
-		break
-	}
-	
-	// Verify new shares reconstruct to the same public key
-	// Use Lagrange interpolation with new threshold parties
-	newPartyIDs := make([]party.ID, 0, len(newConfigs))
-	for pid := range newConfigs {
-		newPartyIDs = append(newPartyIDs, pid)
-		if len(newPartyIDs) >= newThreshold {
-			break
-		}
-	}
-	
-	if len(newPartyIDs) < newThreshold {
-		return fmt.Errorf("insufficient new parties for verification: have %d, need %d", 
-			len(newPartyIDs), newThreshold)
-	}
-	
-	// Compute Lagrange coefficients for the new parties
-	lagrange := polynomial.Lagrange(group, newPartyIDs)
-	
-	// Reconstruct public key from new shares
-	reconstructedKey := group.NewPoint()
-	for _, pid := range newPartyIDs {
-		cfg := newConfigs[pid]
-		if cfg == nil {
-			return fmt.Errorf("missing config for party %s", pid)
-		}
-		
-		// Get the public share for this party
-		publicShare := cfg.ECDSA.ActOnBase()
-		
-		// Apply Lagrange coefficient
-		if coeff, exists := lagrange[pid]; exists {
-			contribution := coeff.Act(publicShare)
-			reconstructedKey = reconstructedKey.Add(contribution)
-		}
-	}
-	
-	// Verify the reconstructed key matches the original
-	if !reconstructedKey.Equal(oldPublicKey) {
-		return errors.New("resharing verification failed: public keys do not match")
+	pl *pool.Pool,
+) (map[party.ID]*config.Config, error) {
+	if len(oldConfigs) == 0 {
+		return nil, errors.New("lss-cmp: no old configurations provided")
 	}
-	
-	// Additional verification: check threshold consistency
-	if oldThreshold > len(oldConfigs) {
-		return fmt.Errorf("old threshold %d exceeds old party count %d", 
-			oldThreshold, len(oldConfigs))
+	oldPartyIDs := make([]party.ID, 0, len(oldConfigs))
+	for pid := range oldConfigs {
+		oldPartyIDs = append(oldPartyIDs, pid)
 	}
-	if newThreshold > len(newConfigs) {
-		return fmt.Errorf("new threshold %d exceeds new party count %d", 
-			newThreshold, len(newConfigs))
+	refConfig := oldConfigs[party.NewIDSlice(oldPartyIDs)[0]]
+
+	newPartyIDs, err := MembershipChange(refConfig.PartyIDs(), add, remove)
+	if err != nil {
+		return nil, err
 	}
-	
-	return nil
+	return DynamicReshareCMP(oldConfigs, newPartyIDs, newThreshold, pl)
 }
 
 // Sign performs CMP signing with the current configuration
@@ -327,8 +225,12 @@ func (c *CMP) GetConfig() *config.Config {
 	return c.config
 }
 
-// UpdateConfig updates the configuration after a successful resharing
+// UpdateConfig updates the configuration after a successful resharing.
+// The share of the previous configuration is zeroized.
 func (c *CMP) UpdateConfig(newConfig *config.Config) {
+	if c.config != newConfig {
+		c.config.Zeroize()
+	}
 	c.config = newConfig
 	c.generation++
 }
@@ -202,7 +202,7 @@ func TestFaultInjection(t *testing.T) {
 			name:       "Stale shares",
 			faultType:  "stale",
 			faultRate:  0.2, // 20% of parties use stale shares
-			expectPass: true,
+			expectPass: false, // Signers of another generation are rejected
 		},
 		{
 			name:       "Delayed responses",
@@ -452,6 +452,15 @@ func runSignWithFaults(t *testing.T, configs map[party.ID]*config.Config, signer
 		return nil // Byzantine parties detected, signing should fail
 	}
 	
+	// Signers holding stale shares are rejected before signing
+	signerConfigs := make([]*config.Config, 0, len(availableSigners))
+	for _, signer := range availableSigners {
+		signerConfigs = append(signerConfigs, configs[signer])
+	}
+	if lss.CheckGenerations(signerConfigs) != nil {
+		return nil
+	}
+
 	// Use only the available signers
 	return runSign(t, configs, availableSigners, messageHash)
 }
@@ -6,9 +6,10 @@ import (
 	"fmt"
 
 	"github.com/luxfi/threshold/pkg/math/curve"
-	"github.com/luxfi/threshold/pkg/math/polynomial"
 	"github.com/luxfi/threshold/pkg/party"
 	"github.com/luxfi/threshold/pkg/pool"
+	"github.com/luxfi/threshold/pkg/protocol"
+	"github.com/luxfi/threshold/protocols/frost"
 	"github.com/luxfi/threshold/protocols/frost/keygen"
 	"github.com/luxfi/threshold/protocols/lss/config"
 )
@@ -43,6 +44,12 @@ func NewLSSFROST(frostConfig *keygen.Config, pool *pool.Pool) *FROST {
 // DynamicReshareFROST performs the LSS dynamic resharing protocol on FROST configurations.
 // This implements the protocol from Section 4 of the LSS paper, allowing
 // transition from T-of-N to T'-of-(N±k) without reconstructing the master key.
+// The key is never interpolated, but the caller holds threshold + 1 secret configs at once,
+// and so must be trusted as a dealer would be.
+//
+// Thresholds follow the FROST convention: newThreshold + 1 parties are needed to sign afterwards,
+// and oldConfigs must hold at least Threshold + 1 of the old configs.
+// The new configs keep the public key and chain key, and move to the next generation.
 func DynamicReshareFROST(
 	oldConfigs map[party.ID]*keygen.Config,
 	newPartyIDs []party.ID,
@@ -54,238 +61,88 @@ func DynamicReshareFROST(
 		return nil, errors.New("lss-frost: no old configurations provided")
 	}
 
-	if newThreshold < 1 || newThreshold > len(newPartyIDs) {
-		return nil, fmt.Errorf("lss-frost: invalid threshold %d for %d parties", newThreshold, len(newPartyIDs))
+	newIDs := party.NewIDSlice(newPartyIDs)
+	if !newIDs.Valid() {
+		return nil, errors.New("lss-frost: duplicate new party IDs")
+	}
+	if newThreshold < 0 || newThreshold >= len(newIDs) {
+		return nil, fmt.Errorf("lss-frost: invalid threshold %d for %d parties", newThreshold, len(newIDs))
 	}
 
 	// Get reference config and validate consistency
-	var refConfig *keygen.Config
-	var group curve.Curve
 	oldPartyIDs := make([]party.ID, 0, len(oldConfigs))
-
 	for pid, cfg := range oldConfigs {
-		if refConfig == nil {
-			refConfig = cfg
-			group = cfg.Curve()
-		} else {
-			// Verify all configs are from the same keygen
-			if !cfg.PublicKey.Equal(refConfig.PublicKey) {
-				return nil, errors.New("lss-frost: inconsistent public keys in old configs")
-			}
+		if cfg.ID != pid {
+			return nil, fmt.Errorf("lss-frost: config of %s is stored under %s", cfg.ID, pid)
 		}
 		oldPartyIDs = append(oldPartyIDs, pid)
 	}
-
-	// Ensure we have enough old parties to reconstruct the secret
-	if len(oldPartyIDs) < refConfig.Threshold {
-		return nil, fmt.Errorf("lss-frost: need at least %d old parties, have %d",
-			refConfig.Threshold, len(oldPartyIDs))
-	}
-
-	// Step 1: Generate auxiliary secrets w and q using polynomial secret sharing
-	// These are temporary secrets used only during the resharing protocol
-	wPoly := polynomial.NewPolynomial(group, newThreshold-1, nil)
-	qPoly := polynomial.NewPolynomial(group, newThreshold-1, nil)
-
-	// All parties (old and new) get shares of w and q
-	allParties := make(map[party.ID]bool)
-	for _, pid := range oldPartyIDs {
-		allParties[pid] = true
-	}
-	for _, pid := range newPartyIDs {
-		allParties[pid] = true
-	}
-
-	wShares := make(map[party.ID]curve.Scalar)
-	qShares := make(map[party.ID]curve.Scalar)
-
-	for pid := range allParties {
-		wShares[pid] = wPoly.Evaluate(pid.Scalar(group))
-		qShares[pid] = qPoly.Evaluate(pid.Scalar(group))
-	}
-
-	// Step 2: Compute the blinded secret a * w
-	// Each old party computes a_i * w_i, then we interpolate to get a * w
-	blindedProducts := make(map[party.ID]curve.Scalar)
-
-	// Use first threshold old parties
-	contributingParties := oldPartyIDs[:refConfig.Threshold]
-	for _, pid := range contributingParties {
-		cfg := oldConfigs[pid]
-		wShare := wShares[pid]
-
-		// Compute a_i * w_i
-		product := group.NewScalar().Set(cfg.PrivateShare).Mul(wShare)
-		blindedProducts[pid] = product
-	}
-
-	// Interpolate the blinded products to get a * w
-	lagrange := polynomial.Lagrange(group, contributingParties)
-	aTimesW := group.NewScalar()
-
-	for pid, product := range blindedProducts {
-		if coeff, exists := lagrange[pid]; exists {
-			contribution := group.NewScalar().Set(coeff).Mul(product)
-			aTimesW.Add(contribution)
+	oldIDs := party.NewIDSlice(oldPartyIDs)
+	refConfig := oldConfigs[oldIDs[0]]
+	group := refConfig.Curve()
+	for _, cfg := range oldConfigs {
+		// Verify all configs are from the same keygen and generation
+		if !cfg.PublicKey.Equal(refConfig.PublicKey) {
+			return nil, errors.New("lss-frost: inconsistent public keys in old configs")
 		}
-	}
-
-	// Step 3: Compute z = (q * w)^{-1}
-	// First, parties compute q_j * w_j and we interpolate to get q * w
-	qwProducts := make(map[party.ID]curve.Scalar)
-
-	// Use first newThreshold parties for this computation
-	computingParties := make([]party.ID, 0, newThreshold)
-	for pid := range allParties {
-		if len(computingParties) >= newThreshold {
-			break
+		if cfg.Generation != refConfig.Generation {
+			return nil, fmt.Errorf("lss-frost: old configs mix generations %d and %d", refConfig.Generation, cfg.Generation)
 		}
-		computingParties = append(computingParties, pid)
-
-		qShare := qShares[pid]
-		wShare := wShares[pid]
-		product := group.NewScalar().Set(qShare).Mul(wShare)
-		qwProducts[pid] = product
-	}
-
-	// Interpolate to get q * w
-	newLagrange := polynomial.Lagrange(group, computingParties)
-	qTimesW := group.NewScalar()
-
-	for pid, product := range qwProducts {
-		if coeff, exists := newLagrange[pid]; exists {
-			contribution := group.NewScalar().Set(coeff).Mul(product)
-			qTimesW.Add(contribution)
+		if cfg.Threshold != refConfig.Threshold {
+			return nil, fmt.Errorf("lss-frost: old configs mix thresholds %d and %d", refConfig.Threshold, cfg.Threshold)
+		}
+		if share, ok := refConfig.VerificationShares.Points[cfg.ID]; !ok || !cfg.PrivateShare.ActOnBase().Equal(share) {
+			return nil, fmt.Errorf("lss-frost: share of %s does not match its verification share", cfg.ID)
 		}
 	}
 
-	// Compute z = (q * w)^{-1}
-	z := group.NewScalar().Set(qTimesW)
-	z = z.Invert()
-
-	// Create shares of z for distribution to new parties
-	zPoly := polynomial.NewPolynomial(group, newThreshold-1, z)
-	zShares := make(map[party.ID]curve.Scalar)
-
-	for _, pid := range newPartyIDs {
-		zShares[pid] = zPoly.Evaluate(pid.Scalar(group))
+	if err := checkShareDegree(refConfig.PublicKey, refConfig.VerificationShares.Points, refConfig.Threshold); err != nil {
+		return nil, fmt.Errorf("lss-frost: old configs: %w", err)
 	}
 
-	// Step 4: Each new party computes their new share
-	// a'_j = (a * w) * q_j * z_j
-	newConfigs := make(map[party.ID]*keygen.Config)
+	// Ensure we have enough old parties to reconstruct the secret
+	if len(oldIDs) < refConfig.Threshold+1 {
+		return nil, fmt.Errorf("lss-frost: need at least %d old parties, have %d",
+			refConfig.Threshold+1, len(oldIDs))
+	}
 
-	// First, compute all new shares
-	newShares := make(map[party.ID]curve.Scalar)
-	for _, pid := range newPartyIDs {
-		qShare := qShares[pid]
-		zShare := zShares[pid]
+	// The first Threshold + 1 old parties deal their additive shares to the new parties
+	contributors := oldIDs[:refConfig.Threshold+1]
+	oldShares := make(map[party.ID]curve.Scalar, len(contributors))
+	for _, pid := range contributors {
+		oldShares[pid] = oldConfigs[pid].PrivateShare
+	}
+	newShares := redistributeShares(group, oldShares, contributors, newIDs, newThreshold)
 
-		// Compute new private share: a'_j = (a * w) * q_j * z_j
-		newPrivateShare := group.NewScalar().Set(aTimesW)
-		newPrivateShare.Mul(qShare).Mul(zShare)
-		newShares[pid] = newPrivateShare
+	// Verify the resharing was correct
+	if err := verifyResharing(refConfig.PublicKey, newShares, newThreshold+1); err != nil {
+		return nil, fmt.Errorf("lss-frost: resharing verification failed: %w", err)
 	}
 
-	// Now create configs with all the public information
-	// Compute new verification shares
-	newVerificationShares := make(map[party.ID]curve.Point)
-	for _, pid := range newPartyIDs {
+	newVerificationShares := make(map[party.ID]curve.Point, len(newIDs))
+	for _, pid := range newIDs {
 		newVerificationShares[pid] = newShares[pid].ActOnBase()
 	}
-
-	for _, pid := range newPartyIDs {
-		newConfig := &keygen.Config{
+	newConfigs := make(map[party.ID]*keygen.Config, len(newIDs))
+	for _, pid := range newIDs {
+		var chainKey []byte
+		if refConfig.ChainKey != nil {
+			chainKey = append([]byte(nil), refConfig.ChainKey...)
+		}
+		newConfigs[pid] = &keygen.Config{
 			ID:                 pid,
 			Threshold:          newThreshold,
 			PrivateShare:       newShares[pid],
 			PublicKey:          refConfig.PublicKey, // Preserve the public key
+			ChainKey:           chainKey,
 			VerificationShares: party.NewPointMap(newVerificationShares),
+			Generation:         refConfig.Generation + 1,
 		}
-
-		newConfigs[pid] = newConfig
-	}
-
-	// Verify the resharing was correct
-	if err := verifyResharingFROST(oldConfigs, newConfigs, refConfig.Threshold, newThreshold); err != nil {
-		return nil, fmt.Errorf("lss-frost: resharing verification failed: %w", err)
 	}
 
 	return newConfigs, nil
 }
 
-// verifyResharingFROST validates that new shares correctly reconstruct the original public key
-func verifyResharingFROST(
-	oldConfigs map[party.ID]*keygen.Config,
-	newConfigs map[party.ID]*keygen.Config,
-	oldThreshold int,
-	newThreshold int,
-) error {
-	// Get the original public key from old configs
-	var oldPublicKey curve.Point
-	var group curve.Curve
-
-	// Get first old config to extract public key and group
-	for _, cfg := range oldConfigs {
-		oldPublicKey = cfg.PublicKey
-		group = cfg.Curve()
-		break
-	}
-
-	// Verify new shares reconstruct to the same public key
-	// Use Lagrange interpolation with new threshold parties
-	newPartyIDs := make([]party.ID, 0, len(newConfigs))
-	for pid := range newConfigs {
-		newPartyIDs = append(newPartyIDs, pid)
-		if len(newPartyIDs) >= newThreshold {
-			break
-		}
-	}
-
-	if len(newPartyIDs) < newThreshold {
-		return fmt.Errorf("insufficient new parties for verification: have %d, need %d",
-			len(newPartyIDs), newThreshold)
-	}
-
-	// Compute Lagrange coefficients for the new parties
-	lagrange := polynomial.Lagrange(group, newPartyIDs)
-
-	// Reconstruct public key from new shares
-	reconstructedKey := group.NewPoint()
-	for _, pid := range newPartyIDs {
-		cfg := newConfigs[pid]
-		if cfg == nil {
-			return fmt.Errorf("missing config for party %s", pid)
-		}
-
-		// Get the verification share for this party
-		verificationShare := cfg.VerificationShares.Points[pid]
-
-		// Apply Lagrange coefficient
-		if coeff, exists := lagrange[pid]; exists {
-			contribution := coeff.Act(verificationShare)
-			reconstructedKey = reconstructedKey.Add(contribution)
-		}
-	}
-
-	// Verify the reconstructed key matches the original
-	if !reconstructedKey.Equal(oldPublicKey) {
-		return errors.New("resharing verification failed: public keys do not match")
-	}
-
-	// Additional verification: check threshold consistency
-	if oldThreshold > len(oldConfigs) {
-		return fmt.Errorf("old threshold %d exceeds old party count %d",
-			oldThreshold, len(oldConfigs))
-	}
-	if newThreshold > len(newConfigs) {
-		return fmt.Errorf("new threshold %d exceeds new party count %d",
-			newThreshold, len(newConfigs))
-	}
-
-	return nil
-}
-
 // Sign performs FROST signing with the current configuration
 func (f *FROST) Sign(_ []party.ID, _ []byte) ([]byte, error) {
 	// FROST.Sign returns a protocol.StartFunc, we need to execute it
@@ -294,14 +151,41 @@ func (f *FROST) Sign(_ []party.ID, _ []byte) ([]byte, error) {
 	return nil, errors.New("sign execution not implemented - use frost.Sign directly")
 }
 
-// Refresh performs a proactive refresh of shares without changing membership
-func (f *FROST) Refresh() (*FROSTConfig, error) {
-	// FROST's Refresh returns a protocol.StartFunc
-	// For this implementation, we'll just increment generation
-	// In practice, you'd execute the refresh protocol
-	f.generation++
-	f.config.Generation = f.generation
-	return f.config, nil
+// Refresh returns the protocol refreshing our share without changing membership.
+//
+// Every party of the config runs it with its own FROST over the network, as with Reshare, so that no party
+// learns the share of another. Every share is re-randomized, while the public key is kept, so that shares stolen
+// before the refresh can't be combined with shares stolen after it. Once it completes, its result is given to Refreshed.
+func (f *FROST) Refresh() protocol.StartFunc {
+	self := f.config.Config
+	partyIDs := make([]party.ID, 0, len(self.VerificationShares.Points))
+	for id := range self.VerificationShares.Points {
+		partyIDs = append(partyIDs, id)
+	}
+	return frost.Refresh(self, partyIDs)
+}
+
+// Refreshed replaces our config by result, the refreshed config returned by the protocol of Refresh,
+// in the next generation. Our old share is zeroized.
+func (f *FROST) Refreshed(result interface{}) (*keygen.Config, error) {
+	refreshed, ok := result.(*keygen.Config)
+	if !ok {
+		return nil, fmt.Errorf("lss-frost: refresh returned %T, not a config", result)
+	}
+	self := f.config.Config
+	if refreshed.ID != self.ID || !refreshed.PublicKey.Equal(self.PublicKey) {
+		return nil, errors.New("lss-frost: refresh returned the config of another party or key")
+	}
+	refreshed.Generation = self.Generation + 1
+	if self.ChainKey != nil {
+		refreshed.ChainKey = append([]byte(nil), self.ChainKey...)
+	}
+
+	// our old share is superseded, and must not outlive the refresh in memory
+	self.Zeroize()
+	f.config = &FROSTConfig{Config: refreshed, Generation: refreshed.Generation}
+	f.generation = refreshed.Generation
+	return refreshed, nil
 }
 
 // GetGeneration returns the current resharing generation number
@@ -314,8 +198,12 @@ func (f *FROST) GetConfig() *FROSTConfig {
 	return f.config
 }
 
-// UpdateConfig updates the configuration after a successful resharing
+// UpdateConfig updates the configuration after a successful resharing.
+// The share of the previous configuration is zeroized.
 func (f *FROST) UpdateConfig(newConfig *FROSTConfig) {
+	if f.config.Config != newConfig.Config {
+		f.config.Zeroize()
+	}
 	f.config = newConfig
 	f.generation++
 }
@@ -0,0 +1,117 @@
+package lss
+
+import (
+	"testing"
+
+	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/protocol"
+	"github.com/luxfi/threshold/protocols/frost"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runFROST runs the protocol started by start for every party, and returns their results.
+func runFROST(t *testing.T, partyIDs []party.ID, start func(id party.ID) protocol.StartFunc) map[party.ID]interface{} {
+	sessionID, err := protocol.NewSessionID()
+	require.NoError(t, err)
+	results, err := test.RunProtocol(partyIDs, sessionID, start)
+	require.NoError(t, err)
+	return results
+}
+
+func TestFROSTRefresh(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(4)
+	threshold := 2
+
+	configs := make(map[party.ID]*frost.Config, len(partyIDs))
+	for id, result := range runFROST(t, partyIDs, func(id party.ID) protocol.StartFunc {
+		return frost.Keygen(group, id, partyIDs, threshold)
+	}) {
+		configs[id] = result.(*frost.Config)
+	}
+	self := configs[partyIDs[0]]
+	publicKey := self.PublicKey
+
+	// every party refreshes its own share over the network
+	lssFROST := make(map[party.ID]*FROST, len(partyIDs))
+	for _, id := range partyIDs {
+		lssFROST[id] = NewLSSFROST(configs[id], nil)
+	}
+	oldShares := make(map[party.ID]curve.Scalar, len(partyIDs))
+	for id, c := range configs {
+		oldShares[id] = c.Curve().NewScalar().Set(c.PrivateShare)
+	}
+	refreshed := make(map[party.ID]*frost.Config, len(partyIDs))
+	for id, result := range runFROST(t, partyIDs, func(id party.ID) protocol.StartFunc {
+		return lssFROST[id].Refresh()
+	}) {
+		c, err := lssFROST[id].Refreshed(result)
+		require.NoError(t, err)
+		refreshed[id] = c
+	}
+
+	f := lssFROST[self.ID]
+	assert.Same(t, refreshed[self.ID], f.GetConfig().Config)
+	assert.True(t, self.PrivateShare.IsZero(), "the old share must be zeroized")
+	assert.EqualValues(t, 1, f.GetGeneration())
+	for id, c := range refreshed {
+		require.NoError(t, c.Validate())
+		assert.True(t, publicKey.Equal(c.PublicKey), "party %s has a different public key", id)
+		assert.Equal(t, threshold, c.Threshold)
+		assert.EqualValues(t, 1, c.Generation)
+		assert.False(t, oldShares[id].Equal(c.PrivateShare), "party %s kept its share", id)
+		assert.False(t, c.PrivateShare.IsZero())
+		assert.False(t, oldShares[id].ActOnBase().Equal(c.VerificationShares.Points[id]), "party %s kept its verification share", id)
+	}
+
+	// threshold + 1 parties sign with the refreshed shares
+	message := []byte("signed after a refresh")
+	signers := partyIDs[1:]
+	for _, result := range runFROST(t, signers, func(id party.ID) protocol.StartFunc {
+		return frost.Sign(refreshed[id], signers, message)
+	}) {
+		assert.True(t, result.(frost.Signature).Verify(publicKey, message))
+	}
+
+	// the result of another protocol is not a refresh
+	_, err := NewLSSFROST(refreshed[partyIDs[3]], nil).Refreshed(refreshed[partyIDs[2]])
+	assert.ErrorContains(t, err, "another party")
+}
+
+func TestReshareFROSTThresholdMismatch(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(4)
+	configs := make(map[party.ID]*frost.Config, len(partyIDs))
+	for id, result := range runFROST(t, partyIDs, func(id party.ID) protocol.StartFunc {
+		return frost.Keygen(group, id, partyIDs, 2)
+	}) {
+		configs[id] = result.(*frost.Config)
+	}
+
+	// the configs claim another threshold than the degree of their shares
+	withThreshold := func(threshold int) map[party.ID]*frost.Config {
+		changed := make(map[party.ID]*frost.Config, len(configs))
+		for id, c := range configs {
+			copied := *c
+			copied.Threshold = threshold
+			changed[id] = &copied
+		}
+		return changed
+	}
+	_, err := DynamicReshareFROST(withThreshold(1), partyIDs, 2, nil)
+	assert.ErrorContains(t, err, "don't match threshold 1")
+	_, err = DynamicReshareFROST(withThreshold(3), partyIDs, 2, nil)
+	assert.ErrorContains(t, err, "lower than threshold 3")
+
+	// one config with another threshold
+	mixed := withThreshold(2)
+	mixed[partyIDs[1]].Threshold = 1
+	_, err = DynamicReshareFROST(mixed, partyIDs, 2, nil)
+	assert.ErrorContains(t, err, "mix thresholds")
+
+	_, err = DynamicReshareFROST(withThreshold(2), partyIDs, 2, nil)
+	assert.NoError(t, err)
+}
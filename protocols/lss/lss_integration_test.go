@@ -1,11 +1,29 @@
 package lss_test
 
 import (
+	stdecdsa "crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	mrand "math/rand"
+	"slices"
 	"testing"
 
+	"github.com/cronokirby/saferith"
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/pkg/ecdsa"
 	"github.com/luxfi/threshold/pkg/math/curve"
 	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/pool"
+	"github.com/luxfi/threshold/pkg/protocol"
+	"github.com/luxfi/threshold/protocols/cmp"
 	"github.com/luxfi/threshold/protocols/lss"
+	"github.com/luxfi/threshold/protocols/lss/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -175,4 +193,506 @@ func TestLSSCoreFunctionality(t *testing.T) {
 		assert.NotNil(t, cfg.ChainKey)
 		assert.NotNil(t, cfg.RID)
 	}
-}
\ No newline at end of file
+}
+func TestReconstructAndSign(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	group := curve.Secp256k1{}
+	hash := sha256.Sum256([]byte("oracle"))
+
+	// the threshold signature comes from CMP, with its shares taken over as LSS configs
+	cmpConfigs, partyIDs := test.GenerateConfig(group, 3, 1, mrand.New(mrand.NewSource(1)), pl)
+	configs := make(map[party.ID]*lss.Config, len(partyIDs))
+	for id, c := range cmpConfigs {
+		public := make(map[party.ID]*config.Public, len(c.Public))
+		for j, p := range c.Public {
+			public[j] = &config.Public{ECDSA: p.ECDSA}
+		}
+		configs[id] = &lss.Config{ID: id, Group: group, Threshold: c.Threshold + 1, ECDSA: c.ECDSA, Public: public}
+	}
+	publicKey, err := configs[partyIDs[0]].PublicKey()
+	require.NoError(t, err)
+	require.True(t, publicKey.Equal(cmpConfigs[partyIDs[0]].PublicPoint()))
+
+	signers := partyIDs[:2]
+	rounds := make([]round.Session, 0, len(signers))
+	for _, id := range signers {
+		r, err := cmp.Sign(cmpConfigs[id], signers, hash[:], pl)(nil)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+	for {
+		err, done := test.Rounds(rounds, nil)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+	sig := rounds[0].(*round.Output).Result.(*ecdsa.Signature)
+	require.True(t, sig.Verify(publicKey, hash[:]), "the threshold signature must verify")
+
+	// any threshold of shares gives the same key, and a signature that verifies like the threshold one
+	for _, subset := range [][]party.ID{partyIDs[:2], partyIDs[1:], partyIDs} {
+		shares := make([]*lss.Config, 0, len(subset))
+		for _, id := range subset {
+			shares = append(shares, configs[id])
+		}
+		secret, err := lss.ReconstructSecret(shares)
+		require.NoError(t, err)
+		assert.True(t, secret.ActOnBase().Equal(publicKey))
+
+		oracle, err := lss.ReconstructAndSign(shares, hash[:])
+		require.NoError(t, err)
+		assert.True(t, oracle.Verify(publicKey, hash[:]))
+	}
+
+	_, err = lss.ReconstructSecret([]*lss.Config{configs[partyIDs[0]]})
+	assert.ErrorContains(t, err, "insufficient shares")
+	_, err = lss.ReconstructSecret([]*lss.Config{configs[partyIDs[0]], configs[partyIDs[0]]})
+	assert.ErrorContains(t, err, "duplicate share")
+	tampered := *configs[partyIDs[1]]
+	tampered.ECDSA = group.NewScalar().Set(tampered.ECDSA).Add(group.NewScalar().SetNat(new(saferith.Nat).SetUint64(1)))
+	_, err = lss.ReconstructSecret([]*lss.Config{configs[partyIDs[0]], &tampered})
+	assert.ErrorContains(t, err, "public share")
+	_, err = lss.ReconstructSecret(nil)
+	assert.Error(t, err)
+}
+
+// runHandlers runs the protocol of every party over a test network, and returns their results.
+func runHandlers(t *testing.T, starts map[party.ID]protocol.StartFunc) map[party.ID]interface{} {
+	ids := make([]party.ID, 0, len(starts))
+	for id := range starts {
+		ids = append(ids, id)
+	}
+	results, err := test.RunProtocol(party.NewIDSlice(ids), []byte("session"), func(id party.ID) protocol.StartFunc {
+		return starts[id]
+	})
+	require.NoError(t, err)
+	return results
+}
+
+func TestReshareMembership(t *testing.T) {
+	group := curve.Secp256k1{}
+	oldIDs := party.IDSlice{"a", "b", "c", "d", "e"}
+
+	starts := make(map[party.ID]protocol.StartFunc, len(oldIDs))
+	for _, id := range oldIDs {
+		pl := pool.NewPool(1)
+		defer pl.TearDown()
+		starts[id] = lss.Keygen(group, id, oldIDs, 3, pl)
+	}
+	oldConfigs := make(map[party.ID]*lss.Config, len(oldIDs))
+	for id, result := range runHandlers(t, starts) {
+		oldConfigs[id] = result.(*lss.Config)
+	}
+	publicKey, err := oldConfigs["a"].PublicKey()
+	require.NoError(t, err)
+
+	// remove two parties and add three, with a new threshold
+	remove := []party.ID{"b", "d"}
+	add := []party.ID{"f", "g", "h"}
+	newIDs := party.IDSlice{"a", "c", "e", "f", "g", "h"}
+	starts = make(map[party.ID]protocol.StartFunc, len(oldIDs)+len(add))
+	for _, id := range oldIDs {
+		pl := pool.NewPool(1)
+		defer pl.TearDown()
+		starts[id] = lss.ReshareMembership(oldConfigs[id], add, remove, 4, pl)
+	}
+	for _, id := range add {
+		pl := pool.NewPool(1)
+		defer pl.TearDown()
+		starts[id] = lss.ReshareMembership(lss.JoinConfig(oldConfigs["a"], id), add, remove, 4, pl)
+	}
+
+	newConfigs := make([]*lss.Config, 0, len(newIDs))
+	for id, result := range runHandlers(t, starts) {
+		c := result.(*lss.Config)
+		assert.ElementsMatch(t, newIDs, c.PartyIDs())
+		assert.Equal(t, 4, c.Threshold)
+		assert.Equal(t, oldConfigs["a"].Generation+1, c.Generation)
+		pk, err := c.PublicKey()
+		require.NoError(t, err)
+		assert.True(t, publicKey.Equal(pk), "the public key must be preserved")
+
+		if slices.Contains(remove, id) {
+			assert.Nil(t, c.ECDSA, "removed parties keep no share")
+			continue
+		}
+		require.NoError(t, c.Validate())
+		newConfigs = append(newConfigs, c)
+	}
+	require.Len(t, newConfigs, len(newIDs))
+
+	// any threshold of the new parties signs for the same key, and fewer can't
+	hash := sha256.Sum256([]byte("membership"))
+	for _, subset := range [][]*lss.Config{newConfigs[:4], newConfigs[2:]} {
+		sig, err := lss.ReconstructAndSign(subset, hash[:])
+		require.NoError(t, err)
+		assert.True(t, sig.Verify(publicKey, hash[:]))
+	}
+	_, err = lss.ReconstructSecret(newConfigs[:3])
+	assert.Error(t, err)
+
+	for _, tc := range []struct {
+		add, remove []party.ID
+		threshold   int
+	}{
+		{remove: []party.ID{"z"}, threshold: 3},
+		{add: []party.ID{"a"}, threshold: 3},
+		{add: []party.ID{"f", "f"}, threshold: 3},
+		{remove: []party.ID{"b", "b"}, threshold: 3},
+		{remove: []party.ID{"a", "b", "c"}, threshold: 3},
+	} {
+		_, err := lss.ReshareMembership(oldConfigs["a"], tc.add, tc.remove, tc.threshold, nil)(nil)
+		assert.Error(t, err, tc)
+	}
+}
+
+func TestCheckGenerations(t *testing.T) {
+	configs := []*config.Config{
+		{ID: "c", Generation: 2},
+		{ID: "a", Generation: 2},
+		{ID: "b", Generation: 1},
+	}
+	err := lss.CheckGenerations(configs)
+	require.Error(t, err)
+	assert.EqualError(t, err, "lss: configs of different generations: 1 (b), 2 (a, c)")
+
+	configs[2].Generation = 2
+	assert.NoError(t, lss.CheckGenerations(configs))
+	assert.NoError(t, lss.CheckGenerations(nil))
+}
+
+func TestMembershipChange(t *testing.T) {
+	current := []party.ID{"c", "a", "b"}
+	newIDs, err := lss.MembershipChange(current, []party.ID{"e", "d"}, []party.ID{"b"})
+	require.NoError(t, err)
+	assert.Equal(t, []party.ID{"a", "c", "e", "d"}, newIDs)
+
+	_, err = lss.MembershipChange(current, []party.ID{"d", "a"}, nil)
+	assert.ErrorContains(t, err, "added party a is already a member")
+	_, err = lss.MembershipChange(current, nil, []party.ID{"z"})
+	assert.ErrorContains(t, err, "removed party z is not a member")
+	// a party removed and added back in the same change is already a member
+	_, err = lss.MembershipChange(current, []party.ID{"b"}, []party.ID{"b"})
+	assert.ErrorContains(t, err, "added party b is already a member")
+}
+
+// TestStdPublicKey checks that crypto/ecdsa of the standard library accepts a threshold signature,
+// in the encodings of Ethereum and Bitcoin, under the key returned by StdPublicKey.
+func TestStdPublicKey(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	group := curve.Secp256k1{}
+	hash := sha256.Sum256([]byte("standard library"))
+
+	cmpConfigs, partyIDs := test.GenerateConfig(group, 3, 1, mrand.New(mrand.NewSource(2)), pl)
+	signers := partyIDs[:2]
+	rounds := make([]round.Session, 0, len(signers))
+	for _, id := range signers {
+		r, err := cmp.Sign(cmpConfigs[id], signers, hash[:], pl)(nil)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+	for {
+		err, done := test.Rounds(rounds, nil)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+	sig := rounds[0].(*round.Output).Result.(*ecdsa.Signature)
+	rsv, err := sig.EthereumRSV()
+	require.NoError(t, err)
+	r, s := new(big.Int).SetBytes(rsv[:32]), new(big.Int).SetBytes(rsv[32:64])
+	der, err := sig.BitcoinDER()
+	require.NoError(t, err)
+	other := sha256.Sum256([]byte("another message"))
+
+	// the same key, from a CMP config and from an LSS config holding its shares
+	c := cmpConfigs[partyIDs[2]]
+	public := make(map[party.ID]*config.Public, len(c.Public))
+	for j, p := range c.Public {
+		public[j] = &config.Public{ECDSA: p.ECDSA}
+	}
+	lssConfig := &lss.Config{ID: c.ID, Group: group, Threshold: c.Threshold + 1, ECDSA: c.ECDSA, Public: public}
+	for _, stdPublicKey := range []func() (*stdecdsa.PublicKey, error){c.StdPublicKey, lssConfig.StdPublicKey} {
+		publicKey, err := stdPublicKey()
+		require.NoError(t, err)
+		assert.True(t, stdecdsa.Verify(publicKey, hash[:], r, s))
+		assert.True(t, stdecdsa.VerifyASN1(publicKey, hash[:], der))
+		assert.False(t, stdecdsa.VerifyASN1(publicKey, other[:], der))
+	}
+}
+
+func TestKeygenWeighted(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := party.IDSlice{"a", "b", "c"}
+	weights := map[party.ID]int{"a": 2}
+
+	starts := make(map[party.ID]protocol.StartFunc, len(partyIDs))
+	for _, id := range partyIDs {
+		starts[id] = lss.KeygenWeighted(group, id, partyIDs, weights, 3, nil)
+	}
+	configs := make(map[party.ID]*lss.Config, len(partyIDs))
+	for id, result := range runHandlers(t, starts) {
+		c := result.(*lss.Config)
+		require.NoError(t, c.Validate())
+		assert.Equal(t, partyIDs, c.PartyIDs())
+		assert.Len(t, c.Public, 4, "a holds two shares")
+		configs[id] = c
+	}
+	assert.Len(t, configs["a"].ExtraShares, 1)
+	assert.Empty(t, configs["b"].ExtraShares)
+	publicKey, err := configs["a"].PublicKey()
+	require.NoError(t, err)
+
+	// the 2-weight party and one 1-weight party meet the threshold of 3, the two 1-weight parties don't
+	hash := sha256.Sum256([]byte("weighted"))
+	for id, sig := range signWeighted(t, configs, []party.ID{"a", "b"}, hash[:]) {
+		assert.True(t, sig.Verify(publicKey, hash[:]), id)
+	}
+	_, err = lss.Sign(configs["b"], []party.ID{"b", "c"}, hash[:], nil)(nil)
+	assert.ErrorContains(t, err, "need at least 3 shares, got 2")
+
+	// the weights survive encoding
+	data, err := configs["a"].MarshalBinary()
+	require.NoError(t, err)
+	decoded := lss.EmptyConfig(group)
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, config.Weights{"a": 2}, decoded.Weights)
+	assert.True(t, decoded.ExtraShares["a/2"].Equal(configs["a"].ExtraShares["a/2"]))
+
+	// resharing moves the extra share from a to b
+	starts = make(map[party.ID]protocol.StartFunc, len(partyIDs))
+	for _, id := range partyIDs {
+		starts[id] = lss.ReshareWeighted(configs[id], partyIDs, map[party.ID]int{"b": 2}, 3, nil)
+	}
+	for id, result := range runHandlers(t, starts) {
+		configs[id] = result.(*lss.Config)
+	}
+	assert.Empty(t, configs["a"].ExtraShares)
+	assert.Len(t, configs["b"].ExtraShares, 1)
+	_, err = lss.Sign(configs["a"], []party.ID{"a", "c"}, hash[:], nil)(nil)
+	assert.ErrorContains(t, err, "need at least 3 shares, got 2")
+	for id, sig := range signWeighted(t, configs, []party.ID{"b", "c"}, hash[:]) {
+		assert.True(t, sig.Verify(publicKey, hash[:]), id)
+	}
+
+	_, err = lss.KeygenWeighted(group, "a", partyIDs, weights, 5, nil)(nil)
+	assert.ErrorContains(t, err, "exceeds shares 4")
+	_, err = lss.KeygenWeighted(group, "a", partyIDs, map[party.ID]int{"z": 2}, 2, nil)(nil)
+	assert.ErrorContains(t, err, "unknown party")
+}
+
+// signWeighted runs lss.Sign among signers, and returns the signature of each of them.
+func signWeighted(t *testing.T, configs map[party.ID]*lss.Config, signers []party.ID, messageHash []byte) map[party.ID]*ecdsa.Signature {
+	starts := make(map[party.ID]protocol.StartFunc, len(signers))
+	for _, id := range signers {
+		starts[id] = lss.Sign(configs[id], signers, messageHash, nil)
+	}
+	sigs := make(map[party.ID]*ecdsa.Signature, len(signers))
+	for id, result := range runHandlers(t, starts) {
+		sigs[id] = result.(*ecdsa.Signature)
+	}
+	return sigs
+}
+
+func TestSignWeighted(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := party.IDSlice{"a", "b", "c"}
+
+	starts := make(map[party.ID]protocol.StartFunc, len(partyIDs))
+	for _, id := range partyIDs {
+		starts[id] = lss.KeygenWeighted(group, id, partyIDs, map[party.ID]int{"a": 2}, 2, nil)
+	}
+	configs := make(map[party.ID]*lss.Config, len(partyIDs))
+	for id, result := range runHandlers(t, starts) {
+		configs[id] = result.(*lss.Config)
+	}
+	publicKey, err := configs["a"].PublicKey()
+	require.NoError(t, err)
+
+	// any signers holding the threshold of 2 shares together sign, with or without the extra share of a
+	hash := sha256.Sum256([]byte("weighted"))
+	for _, signers := range [][]party.ID{{"a", "b"}, {"b", "c"}, {"a", "b", "c"}} {
+		for id, sig := range signWeighted(t, configs, signers, hash[:]) {
+			assert.True(t, sig.Verify(publicKey, hash[:]), "%v: %s", signers, id)
+		}
+	}
+	_, err = lss.Sign(configs["b"], []party.ID{"b"}, hash[:], nil)(nil)
+	assert.ErrorContains(t, err, "need at least 2 shares, got 1")
+}
+
+func TestKeyID(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := []party.ID{"a", "b", "c"}
+	configs := lss.RunKeygen(t, group, partyIDs, 2)
+	keyID := configs["a"].KeyID()
+	require.Len(t, keyID, 16)
+	for _, c := range configs {
+		assert.Equal(t, keyID, c.KeyID())
+	}
+
+	reshared := lss.RunReshare(t, configs, []party.ID{"a", "b", "c", "d"}, 3)
+	for _, c := range reshared {
+		assert.Equal(t, keyID, c.KeyID(), "resharing must keep the key ID")
+	}
+
+	other := lss.RunKeygen(t, group, partyIDs, 2)
+	assert.NotEqual(t, keyID, other["a"].KeyID())
+	assert.False(t, lss.IsCompatibleForSigning(configs["a"], other["a"]))
+	assert.True(t, lss.IsCompatibleForSigning(configs["a"], configs["b"]))
+}
+
+func TestDeriveChild(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := party.IDSlice{"a", "b", "c"}
+	starts := make(map[party.ID]protocol.StartFunc, len(partyIDs))
+	for _, id := range partyIDs {
+		pl := pool.NewPool(1)
+		defer pl.TearDown()
+		starts[id] = lss.Keygen(group, id, partyIDs, 2, pl)
+	}
+	configs := make([]*lss.Config, 0, len(partyIDs))
+	for _, result := range runHandlers(t, starts) {
+		configs = append(configs, result.(*lss.Config))
+	}
+	parent, err := configs[0].PublicKey()
+	require.NoError(t, err)
+
+	// non-hardened BIP-32: I = HMAC-SHA512(chain code, serP(K) || ser32(i)), K_i = K + I_L⋅G, and I_R is the chain code
+	const index = 7
+	compressed, err := parent.MarshalBinary()
+	require.NoError(t, err)
+	mac := hmac.New(sha512.New, configs[0].ChainKey)
+	mac.Write(compressed)
+	mac.Write(binary.BigEndian.AppendUint32(nil, index))
+	I := mac.Sum(nil)
+	tweak := group.NewScalar()
+	require.NoError(t, tweak.UnmarshalBinary(I[:32]))
+	expected := parent.Add(tweak.ActOnBase())
+
+	children := make([]*lss.Config, 0, len(configs))
+	for _, c := range configs {
+		child, err := c.DeriveChild(index)
+		require.NoError(t, err)
+		require.NoError(t, child.Validate())
+		childKey, err := child.PublicKey()
+		require.NoError(t, err)
+		assert.True(t, expected.Equal(childKey), "the child key must follow BIP-32")
+		assert.Equal(t, I[32:], child.ChainKey)
+		children = append(children, child)
+	}
+	assert.NotEqual(t, configs[0].KeyID(), children[0].KeyID())
+
+	// the shares of the child are a sharing of its secret key, at the same threshold
+	hash := sha256.Sum256([]byte("child"))
+	sig, err := lss.ReconstructAndSign(children[1:], hash[:])
+	require.NoError(t, err)
+	assert.True(t, sig.Verify(expected, hash[:]))
+	assert.False(t, sig.Verify(parent, hash[:]))
+
+	_, err = configs[0].DeriveChild(1 << 31)
+	assert.Error(t, err, "hardened indices need the secret key")
+}
+
+func TestPreSignatureStaleAfterReshare(t *testing.T) {
+	group := curve.Secp256k1{}
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	configs, partyIDs := test.GenerateConfig(group, 3, 1, mrand.New(mrand.NewSource(1)), pl)
+	signers := partyIDs[:2]
+
+	starts := make(map[party.ID]protocol.StartFunc, len(signers))
+	for _, id := range signers {
+		starts[id] = cmp.Presign(configs[id], signers, pl)
+	}
+	presigs := make(map[party.ID]*ecdsa.PreSignature, len(signers))
+	for id, result := range runHandlers(t, starts) {
+		presigs[id] = result.(*ecdsa.PreSignature)
+		assert.Equal(t, configs[id].Generation, presigs[id].Generation)
+	}
+
+	data, err := presigs[signers[0]].MarshalBinary()
+	require.NoError(t, err)
+	decoded := ecdsa.EmptyPreSignature(group)
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, presigs[signers[0]].Generation, decoded.Generation)
+
+	reshared, err := lss.DynamicReshareCMP(configs, partyIDs, 1, pl)
+	require.NoError(t, err)
+	hash := sha256.Sum256([]byte("stale"))
+	for _, id := range signers {
+		require.Equal(t, configs[id].Generation+1, reshared[id].Generation)
+		_, err := cmp.PresignOnline(reshared[id], presigs[id], hash[:], pl)(nil)
+		assert.ErrorIs(t, err, ecdsa.ErrPreSignatureGeneration)
+		assert.False(t, presigs[id].Used(), "a rejected presignature is not consumed")
+	}
+}
+
+// TestSignBelowThreshold checks that too few signers is an error of the StartFunc, and not a panic in the rounds.
+func TestSignBelowThreshold(t *testing.T) {
+	configs := lss.RunKeygen(t, curve.Secp256k1{}, []party.ID{"a", "b", "c", "d", "e"}, 3)
+	hash := sha256.Sum256([]byte("below threshold"))
+	assert.NotPanics(t, func() {
+		_, err := lss.Sign(configs["a"], []party.ID{"a", "b"}, hash[:], nil)(nil)
+		assert.EqualError(t, err, "lss: need at least 3 signers, got 2")
+	})
+}
+
+func TestConcurrentSigningSessions(t *testing.T) {
+	configs := lss.RunKeygen(t, curve.Secp256k1{}, []party.ID{"a", "b", "c"}, 2)
+	publicKey, err := configs["a"].PublicKey()
+	require.NoError(t, err)
+
+	signers := []party.ID{"a", "c"}
+	err = test.RunSigningSessions(signers, 20, func(hash []byte, id party.ID) protocol.StartFunc {
+		return lss.Sign(configs[id], signers, hash, nil)
+	}, func(hash []byte, result interface{}) ([]byte, error) {
+		sig := result.(*ecdsa.Signature)
+		if !sig.Verify(publicKey, hash) {
+			return nil, errors.New("invalid signature")
+		}
+		return sig.MarshalJSON()
+	})
+	require.NoError(t, err)
+}
+
+func TestReshareCMPThresholdMismatch(t *testing.T) {
+	group := curve.Secp256k1{}
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	configs, partyIDs := test.GenerateConfig(group, 3, 1, mrand.New(mrand.NewSource(1)), pl)
+
+	// the configs claim a threshold below the degree of their shares
+	changed := make(map[party.ID]*cmp.Config, len(configs))
+	for id, c := range configs {
+		copied := *c
+		copied.Threshold = 0
+		changed[id] = &copied
+	}
+	_, err := lss.DynamicReshareCMP(changed, partyIDs, 1, pl)
+	assert.ErrorContains(t, err, "don't match threshold 0")
+}
+
+func TestReshareCMPMembership(t *testing.T) {
+	group := curve.Secp256k1{}
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	configs, partyIDs := test.GenerateConfig(group, 3, 1, mrand.New(mrand.NewSource(1)), pl)
+
+	_, err := lss.DynamicReshareCMPMembership(configs, []party.ID{partyIDs[1]}, nil, 1, pl)
+	assert.ErrorContains(t, err, fmt.Sprintf("added party %s is already a member", partyIDs[1]))
+	_, err = lss.DynamicReshareCMPMembership(configs, nil, []party.ID{"z"}, 1, pl)
+	assert.ErrorContains(t, err, "removed party z is not a member")
+
+	reshared, err := lss.DynamicReshareCMPMembership(configs, nil, []party.ID{partyIDs[0]}, 1, pl)
+	require.NoError(t, err)
+	assert.Len(t, reshared, 2)
+	assert.NotContains(t, reshared, partyIDs[0])
+	for _, c := range reshared {
+		assert.Equal(t, partyIDs[1:], c.PartyIDs())
+		assert.True(t, c.PublicPoint().Equal(configs[partyIDs[0]].PublicPoint()))
+	}
+}
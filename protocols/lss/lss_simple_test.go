@@ -1,11 +1,17 @@
 package lss
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
 	"testing"
 
 	"github.com/cronokirby/saferith"
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/pkg/ecdsa"
 	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/math/sample"
 	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/protocol"
 	"github.com/luxfi/threshold/protocols/lss/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -182,3 +188,98 @@ func TestCompatibility(t *testing.T) {
 	c2.Generation = 2
 	assert.False(t, IsCompatibleForSigning(c1, c2))
 }
+
+func TestSignDeterministic(t *testing.T) {
+	hash := sha256.Sum256([]byte("test vector"))
+	for _, group := range []curve.Curve{curve.Secp256k1{}, curve.P256{}} {
+		// a threshold of 1 gives each party the whole key
+		partyIDs := []party.ID{"a", "b"}
+		configs := RunKeygen(t, group, partyIDs, 1)
+		publicKey, err := configs["a"].PublicPoint()
+		require.NoError(t, err)
+
+		var first *ecdsa.Signature
+		for run := 0; run < 2; run++ {
+			r, err := SignDeterministic(configs["a"], []party.ID{"a"}, hash[:], nil)(nil)
+			require.NoError(t, err)
+			require.IsType(t, &round.Output{}, r)
+			sig := r.(*round.Output).Result.(*ecdsa.Signature)
+			require.True(t, sig.Verify(publicKey, hash[:]))
+			if first == nil {
+				first = sig
+				continue
+			}
+			assert.True(t, first.R.Equal(sig.R), "two runs must give the same signature")
+			assert.True(t, first.S.Equal(sig.S), "two runs must give the same signature")
+		}
+
+		// the other party holds the same key, so it produces the same signature
+		r, err := SignDeterministic(configs["b"], []party.ID{"b"}, hash[:], nil)(nil)
+		require.NoError(t, err)
+		assert.True(t, first.S.Equal(r.(*round.Output).Result.(*ecdsa.Signature).S))
+
+		// signing with the reconstructed key agrees
+		oracleSig, err := ReconstructAndSign([]*Config{configs["a"]}, hash[:])
+		require.NoError(t, err)
+		assert.True(t, first.S.Equal(oracleSig.S))
+	}
+
+	// shares of a larger threshold are never used with deterministic nonces
+	configs := RunKeygen(t, curve.Secp256k1{}, []party.ID{"a", "b", "c"}, 2)
+	_, err := SignDeterministic(configs["a"], []party.ID{"a"}, hash[:], nil)(nil)
+	assert.Error(t, err)
+	_, err = SignDeterministic(configs["a"], []party.ID{"a", "b"}, hash[:], nil)(nil)
+	assert.Error(t, err)
+
+	sig1, err := ReconstructAndSign([]*Config{configs["a"], configs["b"]}, hash[:])
+	require.NoError(t, err)
+	sig2, err := ReconstructAndSign([]*Config{configs["b"], configs["c"]}, hash[:])
+	require.NoError(t, err)
+	assert.True(t, sig1.R.Equal(sig2.R) && sig1.S.Equal(sig2.S), "any signer set reconstructs the same key")
+}
+
+func TestKeygenInvalidArguments(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := []party.ID{"a", "b", "c"}
+
+	tests := []struct {
+		name      string
+		selfID    party.ID
+		partyIDs  []party.ID
+		threshold int
+		err       string
+	}{
+		{"threshold above parties", "a", partyIDs, 5, "threshold 5 exceeds parties 3"},
+		{"zero threshold", "a", partyIDs, 0, "threshold 0 must be at least 1"},
+		{"no self", "z", partyIDs, 2, "self z is not among the parties"},
+		{"duplicate party", "a", []party.ID{"a", "b", "b"}, 2, "party b is listed more than once"},
+		{"no parties", "a", nil, 1, "no parties"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := protocol.NewMultiHandler(Keygen(group, tt.selfID, tt.partyIDs, tt.threshold, nil), nil)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestConfigZeroize(t *testing.T) {
+	group := curve.Secp256k1{}
+	share, extra := sample.Scalar(rand.Reader, group), sample.Scalar(rand.Reader, group)
+	cfg := &config.Config{
+		ID:          "a",
+		Group:       group,
+		Threshold:   1,
+		ECDSA:       group.NewScalar().Set(share),
+		ExtraShares: map[party.ID]curve.Scalar{"a/2": group.NewScalar().Set(extra)},
+		Public:      map[party.ID]*config.Public{"a": {ECDSA: share.ActOnBase()}},
+	}
+
+	// a copy, as kept by the rollback manager, doesn't share the secrets of the config
+	copied := cfg.Copy()
+	cfg.Zeroize()
+	assert.True(t, cfg.ECDSA.IsZero())
+	assert.True(t, cfg.ExtraShares["a/2"].IsZero())
+	assert.True(t, copied.ECDSA.Equal(share))
+	assert.True(t, copied.ExtraShares["a/2"].Equal(extra))
+}
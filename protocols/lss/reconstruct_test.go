@@ -0,0 +1,120 @@
+package lss
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/luxfi/threshold/internal/rfc6979"
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/pkg/ecdsa"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/math/polynomial"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/pool"
+	"github.com/luxfi/threshold/pkg/protocol"
+)
+
+// ReconstructSecret interpolates the secret key shared by configs, which must hold shares of the same
+// key and generation from distinct parties, at least Threshold shares together when parties are weighted.
+//
+// It is a trusted oracle to cross-check the signatures of the protocol against signatures of the complete
+// key, and only exists in the test binary: production code must never bring the shares of a key together.
+func ReconstructSecret(configs []*Config) (curve.Scalar, error) {
+	if len(configs) == 0 {
+		return nil, errors.New("lss: no configs to reconstruct from")
+	}
+	first := configs[0]
+	held := 0
+	for _, c := range configs {
+		held += first.Weights.Of(c.ID)
+	}
+	if held < first.Threshold {
+		return nil, fmt.Errorf("lss: insufficient shares: have %d, need %d", held, first.Threshold)
+	}
+	publicKey, err := first.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]party.ID, 0, len(configs))
+	shares := make(map[party.ID]curve.Scalar, len(configs))
+	for _, c := range configs {
+		if c.Group.Name() != first.Group.Name() || c.Generation != first.Generation {
+			return nil, fmt.Errorf("lss: share of %s belongs to another key or generation", c.ID)
+		}
+		if _, ok := shares[c.ID]; ok {
+			return nil, fmt.Errorf("lss: duplicate share of %s", c.ID)
+		}
+		for id, share := range c.Shares() {
+			if public, ok := first.Public[id]; !ok || !public.ECDSA.Equal(share.ActOnBase()) {
+				return nil, fmt.Errorf("lss: share of %s does not match its public share", id)
+			}
+			ids = append(ids, id)
+			shares[id] = share
+		}
+	}
+
+	secret := first.Group.NewScalar()
+	for id, coefficient := range polynomial.Lagrange(first.Group, ids) {
+		secret.Add(coefficient.Mul(shares[id]))
+	}
+	if !secret.ActOnBase().Equal(publicKey) {
+		return nil, errors.New("lss: reconstructed secret does not match the public key")
+	}
+	return secret, nil
+}
+
+// ReconstructAndSign signs messageHash with the secret key reconstructed from configs by
+// ReconstructSecret, using the deterministic nonce of rfc6979.Sign.
+//
+// Like ReconstructSecret, it is only meant as an oracle for tests.
+func ReconstructAndSign(configs []*Config, messageHash []byte) (*ecdsa.Signature, error) {
+	secret, err := ReconstructSecret(configs)
+	if err != nil {
+		return nil, err
+	}
+	return rfc6979.Sign(secret, messageHash)
+}
+
+// SignDeterministic signs messageHash like Sign, but with a nonce derived from the secret key
+// and messageHash as in RFC 6979, so that signing the same hash always gives the same signature.
+//
+// It gives reproducible test vectors, and like ReconstructSecret only exists in the test binary: it runs
+// no protocol, and only works when c's share is the complete secret key, which is the case
+// for a key with a threshold of 1. signers must then be exactly c.ID.
+// Deterministic nonces must never be used in the multiparty protocol, so any other
+// configuration is rejected.
+func SignDeterministic(c *Config, signers []party.ID, messageHash []byte, pl *pool.Pool) protocol.StartFunc {
+	return func(sessionID []byte) (round.Session, error) {
+		if len(signers) != 1 || signers[0] != c.ID {
+			return nil, errors.New("lss: deterministic signing requires a single signer holding the whole key")
+		}
+		if len(messageHash) != 32 {
+			return nil, errors.New("lss: message hash must be 32 bytes")
+		}
+		publicKey, err := c.PublicPoint()
+		if err != nil {
+			return nil, fmt.Errorf("lss: %w", err)
+		}
+		if !c.ECDSA.ActOnBase().Equal(publicKey) {
+			return nil, errors.New("lss: deterministic signing requires a single signer holding the whole key")
+		}
+
+		helper, err := round.NewSession(round.Info{
+			ProtocolID:       "lss/sign-deterministic",
+			FinalRoundNumber: 1,
+			SelfID:           c.ID,
+			PartyIDs:         signers,
+			Threshold:        0,
+			Group:            c.Group,
+		}, sessionID, pl)
+		if err != nil {
+			return nil, fmt.Errorf("lss: %w", err)
+		}
+		sig, err := rfc6979.Sign(c.ECDSA, messageHash)
+		if err != nil {
+			return nil, fmt.Errorf("lss: %w", err)
+		}
+		return helper.ResultRound(sig), nil
+	}
+}
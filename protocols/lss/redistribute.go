@@ -0,0 +1,89 @@
+package lss
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/math/polynomial"
+	"github.com/luxfi/threshold/pkg/party"
+)
+
+// redistributeShares moves a sharing of a secret to newPartyIDs, on a fresh polynomial of degree newDegree.
+//
+// Each contributor i computes its additive share λᵢ⋅aᵢ of the secret, and deals it with a random
+// polynomial fᵢ of degree newDegree. New party j then holds a'ⱼ = ∑ᵢ fᵢ(j), which lies on ∑ᵢ fᵢ,
+// whose constant term is the secret. The secret is never assembled in one place.
+//
+// shares must contain a share for every contributor, and the contributors must be enough to
+// reconstruct the secret.
+func redistributeShares(group curve.Curve, shares map[party.ID]curve.Scalar, contributors, newPartyIDs []party.ID, newDegree int) map[party.ID]curve.Scalar {
+	newShares := make(map[party.ID]curve.Scalar, len(newPartyIDs))
+	for _, j := range newPartyIDs {
+		newShares[j] = group.NewScalar()
+	}
+
+	lagrange := polynomial.Lagrange(group, contributors)
+	// the additive shares are secret temporaries, so they are returned zeroed to the pool
+	additive := curve.GetScalar(group)
+	for _, i := range contributors {
+		additive.Set(lagrange[i]).Mul(shares[i])
+		f := polynomial.NewPolynomial(group, newDegree, additive)
+		for _, j := range newPartyIDs {
+			newShares[j].Add(f.Evaluate(j.Scalar(group)))
+		}
+	}
+	curve.PutScalar(additive)
+	return newShares
+}
+
+// verifyResharing checks that the public shares of newShares interpolate to publicKey,
+// once with the first and once with the last `needed` parties.
+func verifyResharing(publicKey curve.Point, newShares map[party.ID]curve.Scalar, needed int) error {
+	ids := make([]party.ID, 0, len(newShares))
+	for id := range newShares {
+		ids = append(ids, id)
+	}
+	sorted := party.NewIDSlice(ids)
+	if len(sorted) < needed {
+		return fmt.Errorf("insufficient new parties for verification: have %d, need %d", len(sorted), needed)
+	}
+	group := publicKey.Curve()
+	for _, subset := range [][]party.ID{sorted[:needed], sorted[len(sorted)-needed:]} {
+		lagrange := polynomial.Lagrange(group, subset)
+		reconstructed := group.NewPoint()
+		for _, id := range subset {
+			reconstructed = reconstructed.Add(lagrange[id].Act(newShares[id].ActOnBase()))
+		}
+		if !reconstructed.Equal(publicKey) {
+			return errors.New("public keys do not match")
+		}
+	}
+	return nil
+}
+
+// checkShareDegree checks that the public shares of the old parties commit to a polynomial of degree exactly threshold
+// whose constant is publicKey, so that a config whose threshold doesn't match its shares, because it was corrupted
+// or comes from an incompatible version, is rejected before its shares are interpolated.
+//
+// With a threshold below the degree, too few contributors would deal a wrong secret, and with a threshold
+// above it, the key would be held by fewer parties than the config claims.
+func checkShareDegree(publicKey curve.Point, shares map[party.ID]curve.Point, threshold int) error {
+	if len(shares) <= threshold {
+		return fmt.Errorf("%d public shares can't commit to a polynomial of degree %d", len(shares), threshold)
+	}
+	group := publicKey.Curve()
+	constant, err := polynomial.InterpolatePoints(group, shares, threshold)
+	if err != nil {
+		return fmt.Errorf("public shares don't match threshold %d: %w", threshold, err)
+	}
+	if !constant.Equal(publicKey) {
+		return errors.New("public shares don't interpolate to the public key")
+	}
+	if threshold > 0 {
+		if _, err = polynomial.InterpolatePoints(group, shares, threshold-1); err == nil {
+			return fmt.Errorf("public shares lie on a polynomial of degree lower than threshold %d", threshold)
+		}
+	}
+	return nil
+}
@@ -3,8 +3,10 @@ package reshare
 
 import (
 	"fmt"
-	
+
 	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/math/polynomial"
 	"github.com/luxfi/threshold/pkg/party"
 	"github.com/luxfi/threshold/pkg/pool"
 	"github.com/luxfi/threshold/pkg/protocol"
@@ -12,7 +14,21 @@ import (
 )
 
 // Start initiates the LSS resharing protocol.
+//
+// The protocol runs between the union of the old and the new parties. Every old party deals its share,
+// so parties leaving the group must take part, and parties joining it start from a config holding the
+// public data of the group but no share. The new configs keep the public key, and increment the generation.
+//
+// Parties leaving the group output the public data of the new group, without a share.
 func Start(oldConfig *config.Config, newParticipants []party.ID, newThreshold int, pl *pool.Pool) protocol.StartFunc {
+	return StartWeighted(oldConfig, newParticipants, nil, newThreshold, pl)
+}
+
+// StartWeighted is like Start, for new parties holding several shares as given by newWeights.
+// The new threshold counts shares, so that a party of weight w counts as w parties toward it.
+//
+// The old config may be weighted too: every old party deals the shares at all its evaluation IDs.
+func StartWeighted(oldConfig *config.Config, newParticipants []party.ID, newWeights config.Weights, newThreshold int, pl *pool.Pool) protocol.StartFunc {
 	return func(sessionID []byte) (round.Session, error) {
 		// Validate parameters
 		if len(newParticipants) == 0 {
@@ -21,53 +37,53 @@ func Start(oldConfig *config.Config, newParticipants []party.ID, newThreshold in
 		if newThreshold <= 0 {
 			return nil, fmt.Errorf("threshold must be positive")
 		}
-		if newThreshold >= len(newParticipants) {
-			return nil, fmt.Errorf("threshold %d must be less than number of parties %d", newThreshold, len(newParticipants))
-		}
-		
-		// Determine if we're in the old group, new group, or both
-		oldID := oldConfig.ID
-		inOldGroup := false
-		inNewGroup := false
-
-		for _, id := range oldConfig.PartyIDs() {
-			if id == oldID {
-				inOldGroup = true
-				break
+		if shares := newWeights.Total(newParticipants); newThreshold >= shares {
+			if shares == len(newParticipants) {
+				return nil, fmt.Errorf("threshold %d must be less than number of parties %d", newThreshold, len(newParticipants))
 			}
+			return nil, fmt.Errorf("threshold %d must be less than number of shares %d", newThreshold, shares)
 		}
-
-		for _, id := range newParticipants {
-			if id == oldID {
-				inNewGroup = true
-				break
-			}
+		newIDs := party.NewIDSlice(newParticipants)
+		if !newIDs.Valid() {
+			return nil, fmt.Errorf("new participant list contains duplicates")
 		}
-
-		// Combine old and new participants for the protocol
-		allParticipants := make(map[party.ID]bool)
-		for _, id := range oldConfig.PartyIDs() {
-			allParticipants[id] = true
-		}
-		for _, id := range newParticipants {
-			allParticipants[id] = true
+		if err := newWeights.Validate(newIDs); err != nil {
+			return nil, err
 		}
 
-		participantList := make([]party.ID, 0, len(allParticipants))
-		for id := range allParticipants {
-			participantList = append(participantList, id)
+		// Determine if we're in the old group, new group, or both
+		oldIDs := party.NewIDSlice(oldConfig.PartyIDs())
+		inOldGroup := oldIDs.Contains(oldConfig.ID) && oldConfig.ECDSA != nil
+		inNewGroup := newIDs.Contains(oldConfig.ID)
+
+		// Combine old and new participants for the protocol
+		allParticipants := append(party.IDSlice{}, oldIDs...)
+		for _, id := range newIDs {
+			if !oldIDs.Contains(id) {
+				allParticipants = append(allParticipants, id)
+			}
 		}
+		allParticipants = party.NewIDSlice(allParticipants)
 
 		info := round.Info{
 			ProtocolID:       "lss/reshare",
 			FinalRoundNumber: 3,
-			SelfID:           oldID,
-			PartyIDs:         participantList,
+			SelfID:           oldConfig.ID,
+			PartyIDs:         allParticipants,
 			Threshold:        newThreshold,
 			Group:            oldConfig.Group,
 		}
 
-		helper, err := round.NewSession(info, sessionID, pl)
+		var helper *round.Helper
+		var err error
+		if len(newWeights.Copy()) == 0 {
+			helper, err = round.NewSession(info, sessionID, pl)
+		} else {
+			// The new threshold counts shares, which may be more than the parties, and every party takes part,
+			// so the threshold of the session is that of the parties and the weighted one goes into the hash.
+			info.Threshold = len(allParticipants) - 1
+			helper, err = round.NewSession(info, sessionID, pl, config.WeightedThreshold{Threshold: newThreshold, Weights: newWeights})
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -75,10 +91,14 @@ func Start(oldConfig *config.Config, newParticipants []party.ID, newThreshold in
 		return &round1{
 			Helper:          helper,
 			oldConfig:       oldConfig,
-			newParticipants: newParticipants,
+			newParticipants: newIDs,
 			newThreshold:    newThreshold,
+			newWeights:      newWeights.Copy(),
 			inOldGroup:      inOldGroup,
 			inNewGroup:      inNewGroup,
+			lagrange:        polynomial.Lagrange(oldConfig.Group, oldConfig.EvaluationIDs()),
+			polynomials:     make(map[party.ID]*polynomial.Exponent, len(oldIDs)),
+			shares:          make(map[party.ID]map[party.ID]curve.Scalar, len(oldIDs)),
 		}, nil
 	}
 }
@@ -1,45 +1,56 @@
 package reshare
 
 import (
-	"crypto/rand"
+	"errors"
+	"fmt"
 
 	"github.com/luxfi/threshold/internal/round"
-	"github.com/luxfi/threshold/internal/types"
 	"github.com/luxfi/threshold/pkg/math/curve"
 	"github.com/luxfi/threshold/pkg/math/polynomial"
-	"github.com/luxfi/threshold/pkg/math/sample"
 	"github.com/luxfi/threshold/pkg/party"
 	"github.com/luxfi/threshold/protocols/lss/config"
 )
 
-// round1 initiates resharing by generating new polynomial shares
+// round1 deals the shares of the old parties to the new group
+//
+// Every old party i reshares λᵢ⋅xᵢ, its share weighted by its Lagrange coefficient among the old parties
+// (the sum of these over its evaluation IDs for a weighted party),
+// with a polynomial gᵢ of degree newThreshold-1. The constants of all gᵢ sum to the secret key,
+// so the sum of the gᵢ shares the same key in the new group.
 type round1 struct {
 	*round.Helper
 
 	oldConfig       *config.Config
-	newParticipants []party.ID
+	newParticipants party.IDSlice
 	newThreshold    int
+	newWeights      config.Weights
 	inOldGroup      bool
 	inNewGroup      bool
 
-	// Polynomial for resharing (only for old parties)
+	// Lagrange coefficients of the old evaluation IDs, whose parties all deal
+	lagrange map[party.ID]curve.Scalar
+
+	// Our polynomial gᵢ, if we are an old party
 	poly *polynomial.Polynomial
 
-	// Chain key for new randomness
-	chainKey types.RID
+	// Public polynomials of the old parties: polynomials[i] = gᵢ(X)⋅G
+	polynomials map[party.ID]*polynomial.Exponent
+
+	// Shares of the new secret dealt to us, by dealer and evaluation ID
+	shares map[party.ID]map[party.ID]curve.Scalar
+
+	// Whether we broadcast our polynomial already
+	sent bool
 }
 
-// broadcast1 contains reshare commitments
+// broadcast1 contains the public polynomial of a dealer
 type broadcast1 struct {
 	round.NormalBroadcastContent
 
-	// Commitments to reshare polynomial - g^f(j) for each new party j
-	Commitments map[party.ID]curve.Point
-
-	// Chain key for randomness
-	ChainKey types.RID
+	// Polynomial is gᵢ(X)⋅G, and nil for parties joining the group, who deal nothing
+	Polynomial *polynomial.Exponent
 
-	// Generation number
+	// Generation of the new configs
 	Generation uint64
 }
 
@@ -50,7 +61,7 @@ func (r *round1) Number() round.Number {
 
 // BroadcastContent implements round.BroadcastRound
 func (r *round1) BroadcastContent() round.BroadcastContent {
-	return &broadcast1{}
+	return &broadcast1{Polynomial: polynomial.EmptyExponent(r.Group())}
 }
 
 // MessageContent implements round.Round
@@ -74,74 +85,80 @@ func (r *round1) StoreMessage(_ round.Message) error {
 }
 
 // Finalize implements round.Round
+//
+// The first call broadcasts our polynomial, and the second one, once all polynomials are received,
+// moves on to dealing the shares.
 func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
-	// Only old parties generate polynomials
-	if r.inOldGroup {
-		// Generate polynomial with our current share as constant term
-		// This preserves the group's public key
-		r.poly = polynomial.NewPolynomial(r.Group(), r.newThreshold-1, r.oldConfig.ECDSA)
-
-		// Generate new chain key
-		chainKey, err := types.NewRID(rand.Reader)
-		if err != nil {
-			return nil, err
-		}
-		r.chainKey = chainKey
-
-		// Create commitments for each new party
-		commitments := make(map[party.ID]curve.Point)
-		for _, j := range r.newParticipants {
-			x := j.Scalar(r.Group())
-			share := r.poly.Evaluate(x)
-			commitments[j] = share.ActOnBase()
-		}
-
-		// Broadcast commitments
-		if err := r.BroadcastMessage(out, &broadcast1{
-			Commitments: commitments,
-			ChainKey:    chainKey,
-			Generation:  r.oldConfig.Generation + 1,
-		}); err != nil {
-			return nil, err
-		}
-	} else {
-		// New parties just generate a random polynomial for blinding
-		secret := sample.Scalar(rand.Reader, r.Group())
-		r.poly = polynomial.NewPolynomial(r.Group(), r.newThreshold-1, secret)
-
-		// Generate chain key
-		chainKey, err := types.NewRID(rand.Reader)
-		if err != nil {
-			return nil, err
-		}
-		r.chainKey = chainKey
-
-		// Create dummy commitments (new parties don't contribute to resharing)
-		commitments := make(map[party.ID]curve.Point)
-		for _, j := range r.newParticipants {
-			commitments[j] = r.Group().NewPoint() // Identity element
-		}
+	if r.sent {
+		return &round2{
+			Helper:          r.Helper,
+			oldConfig:       r.oldConfig,
+			newParticipants: r.newParticipants,
+			newThreshold:    r.newThreshold,
+			newWeights:      r.newWeights,
+			inOldGroup:      r.inOldGroup,
+			inNewGroup:      r.inNewGroup,
+			poly:            r.poly,
+			polynomials:     r.polynomials,
+			shares:          r.shares,
+		}, nil
+	}
+	r.sent = true
 
-		// Broadcast empty commitments
-		if err := r.BroadcastMessage(out, &broadcast1{
-			Commitments: commitments,
-			ChainKey:    chainKey,
-			Generation:  r.oldConfig.Generation + 1,
-		}); err != nil {
-			return nil, err
+	var public *polynomial.Exponent
+	if r.inOldGroup {
+		// gᵢ(0) = λᵢ⋅xᵢ
+		constant := r.Group().NewScalar()
+		for id, share := range r.oldConfig.Shares() {
+			constant.Add(r.Group().NewScalar().Set(r.lagrange[id]).Mul(share))
 		}
+		r.poly = polynomial.NewPolynomial(r.Group(), r.newThreshold-1, constant)
+		public = polynomial.NewPolynomialExponent(r.poly)
+		r.polynomials[r.SelfID()] = public
 	}
-
-	return &round2{
-		round1:      r,
-		commitments: make(map[party.ID]map[party.ID]curve.Point),
-		chainKeys:   make(map[party.ID]types.RID),
-		shares:      make(map[party.ID]curve.Scalar),
-	}, nil
+	if err := r.BroadcastMessage(out, &broadcast1{
+		Polynomial: public,
+		Generation: r.oldConfig.Generation + 1,
+	}); err != nil {
+		return nil, err
+	}
+	return r, nil
 }
 
 // StoreBroadcastMessage implements round.BroadcastRound
-func (r *round1) StoreBroadcastMessage(_ round.Message) error {
-	// Messages stored in round2
+//
+// The polynomial of an old party must have the degree of the new threshold, and commit to its public
+// share weighted by its Lagrange coefficient, so that the public key is preserved.
+func (r *round1) StoreBroadcastMessage(msg round.Message) error {
+	from := msg.From
+	body, ok := msg.Content.(*broadcast1)
+	if !ok || body == nil {
+		return round.ErrInvalidContent
+	}
+	if body.Generation != r.oldConfig.Generation+1 {
+		return errors.New("wrong generation in broadcast")
+	}
+
+	_, dealer := r.lagrange[from]
+	if !dealer {
+		if body.Polynomial != nil {
+			return fmt.Errorf("party %s is not an old party, but dealt a polynomial", from)
+		}
+		return nil
+	}
+	if body.Polynomial == nil {
+		return fmt.Errorf("old party %s dealt no polynomial", from)
+	}
+	if body.Polynomial.Degree() != r.newThreshold-1 {
+		return fmt.Errorf("polynomial of %s has degree %d, expected %d", from, body.Polynomial.Degree(), r.newThreshold-1)
+	}
+	expected := r.Group().NewPoint()
+	for _, id := range r.oldConfig.Weights.EvaluationIDs(from) {
+		expected = expected.Add(r.lagrange[id].Act(r.oldConfig.Public[id].ECDSA))
+	}
+	if !body.Polynomial.Constant().Equal(expected) {
+		return fmt.Errorf("polynomial of %s does not reshare its public share", from)
+	}
+	r.polynomials[from] = body.Polynomial
 	return nil
 }
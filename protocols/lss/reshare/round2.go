@@ -2,31 +2,43 @@ package reshare
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/luxfi/threshold/internal/round"
-	"github.com/luxfi/threshold/internal/types"
 	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/math/polynomial"
 	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/protocols/lss/config"
 )
 
-// round2 distributes reshare shares
+// round2 sends the shares of our polynomial to the new parties
 type round2 struct {
-	*round1
+	*round.Helper
 
-	// Commitments from all parties
-	commitments map[party.ID]map[party.ID]curve.Point
+	oldConfig       *config.Config
+	newParticipants party.IDSlice
+	newThreshold    int
+	newWeights      config.Weights
+	inOldGroup      bool
+	inNewGroup      bool
 
-	// Chain keys from all parties
-	chainKeys map[party.ID]types.RID
+	// Our polynomial gᵢ, if we are an old party
+	poly *polynomial.Polynomial
 
-	// Shares we receive
-	shares map[party.ID]curve.Scalar
+	// Public polynomials of the old parties
+	polynomials map[party.ID]*polynomial.Exponent
+
+	// Shares of the new secret dealt to us, by dealer and evaluation ID
+	shares map[party.ID]map[party.ID]curve.Scalar
 }
 
-// message2 contains a reshare share for a party
+// message2 contains the share gᵢ(j) of an old party i for a new party j
 type message2 struct {
-	Share      curve.Scalar
-	Generation uint64
+	// Share encoded as binary for CBOR compatibility, and empty if the sender deals nothing to the recipient
+	Share []byte
+
+	// ExtraShares are the shares gᵢ(e) at the other evaluation IDs e of a weighted recipient, encoded like Share
+	ExtraShares map[party.ID][]byte
 }
 
 // Number implements round.Round
@@ -34,11 +46,6 @@ func (r *round2) Number() round.Number {
 	return 2
 }
 
-// BroadcastContent implements round.BroadcastRound
-func (r *round2) BroadcastContent() round.BroadcastContent {
-	return nil // No broadcast in round 2
-}
-
 // MessageContent implements round.Round
 func (r *round2) MessageContent() round.Content {
 	return &message2{}
@@ -51,98 +58,99 @@ func (message2) RoundNumber() round.Number {
 
 // VerifyMessage implements round.Round
 func (r *round2) VerifyMessage(msg round.Message) error {
-	from, to := msg.From, msg.To
 	body, ok := msg.Content.(*message2)
 	if !ok || body == nil {
 		return round.ErrInvalidContent
 	}
-
-	if to != r.SelfID() {
+	if msg.To != r.SelfID() {
 		return errors.New("message not for us")
 	}
+	_, err := r.decodeShares(msg.From, body)
+	return err
+}
 
-	if body.Generation != r.oldConfig.Generation+1 {
-		return errors.New("wrong generation")
+// StoreMessage implements round.Round
+func (r *round2) StoreMessage(msg round.Message) error {
+	shares, err := r.decodeShares(msg.From, msg.Content.(*message2))
+	if err != nil {
+		return err
 	}
-
-	// Verify share against commitment
-	commitments, ok := r.commitments[from]
-	if !ok {
-		return errors.New("missing commitments from sender")
+	if shares != nil {
+		r.shares[msg.From] = shares
 	}
+	return nil
+}
 
-	// Check g^share = commitment[to]
-	expectedCommitment, ok := commitments[to]
-	if !ok {
-		// If sender is not an old party, they shouldn't send shares
-		isOldParty := false
-		for _, id := range r.oldConfig.PartyIDs() {
-			if id == from {
-				isOldParty = true
-				break
-			}
+// decodeShares decodes the shares dealt to us by from at each of our evaluation IDs,
+// checking them against the public polynomial of from.
+// It returns nil if from deals nothing to us.
+func (r *round2) decodeShares(from party.ID, body *message2) (map[party.ID]curve.Scalar, error) {
+	_, dealer := r.polynomials[from]
+	if !dealer || !r.inNewGroup {
+		if len(body.Share) != 0 || len(body.ExtraShares) != 0 {
+			return nil, fmt.Errorf("unexpected share from %s", from)
 		}
-		if !isOldParty {
-			return errors.New("new party shouldn't send shares")
-		}
-		return errors.New("missing commitment for our ID")
+		return nil, nil
 	}
 
-	sharePoint := body.Share.ActOnBase()
-	if !sharePoint.Equal(expectedCommitment) {
-		return errors.New("share doesn't match commitment")
+	ids := r.newWeights.EvaluationIDs(r.SelfID())
+	if len(body.ExtraShares) != len(ids)-1 {
+		return nil, fmt.Errorf("got %d extra shares, expected %d", len(body.ExtraShares), len(ids)-1)
 	}
-
-	return nil
-}
-
-// StoreMessage implements round.Round
-func (r *round2) StoreMessage(msg round.Message) error {
-	from := msg.From
-	body := msg.Content.(*message2)
-
-	r.shares[from] = body.Share
-	return nil
+	shares := make(map[party.ID]curve.Scalar, len(ids))
+	for i, id := range ids {
+		data, ok := body.Share, true
+		if i > 0 {
+			data, ok = body.ExtraShares[id]
+		}
+		if !ok {
+			return nil, fmt.Errorf("missing extra share %s", id)
+		}
+		share := r.Group().NewScalar()
+		if err := share.UnmarshalBinary(data); err != nil {
+			return nil, errors.New("invalid share encoding")
+		}
+		if !share.ActOnBase().Equal(r.polynomials[from].Evaluate(id.Scalar(r.Group()))) {
+			return nil, errors.New("share doesn't match the public polynomial")
+		}
+		shares[id] = share
+	}
+	return shares, nil
 }
 
 // Finalize implements round.Round
 func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
-	// Send shares to new parties (only if we're an old party)
-	if r.inOldGroup {
-		for _, id := range r.newParticipants {
-			x := id.Scalar(r.Group())
-			share := r.poly.Evaluate(x)
-
-			if err := r.SendMessage(out, &message2{
-				Share:      share,
-				Generation: r.oldConfig.Generation + 1,
-			}, id); err != nil {
-				return nil, err
+	for _, id := range r.OtherPartyIDs() {
+		body := &message2{}
+		if r.inOldGroup && r.newParticipants.Contains(id) {
+			for i, e := range r.newWeights.EvaluationIDs(id) {
+				data, err := r.poly.Evaluate(e.Scalar(r.Group())).MarshalBinary()
+				if err != nil {
+					return nil, err
+				}
+				if i == 0 {
+					body.Share = data
+					continue
+				}
+				if body.ExtraShares == nil {
+					body.ExtraShares = make(map[party.ID][]byte)
+				}
+				body.ExtraShares[e] = data
 			}
 		}
+		if err := r.SendMessage(out, body, id); err != nil {
+			return nil, err
+		}
 	}
 
-	return &round3{
-		round2: r,
-	}, nil
-}
-
-// StoreBroadcastMessage implements round.BroadcastRound
-func (r *round2) StoreBroadcastMessage(msg round.Message) error {
-	from := msg.From
-	body, ok := msg.Content.(*broadcast1)
-	if !ok || body == nil {
-		return round.ErrInvalidContent
-	}
-
-	// Verify generation
-	if body.Generation != r.oldConfig.Generation+1 {
-		return errors.New("wrong generation in broadcast")
+	// Our own shares
+	if r.inOldGroup && r.inNewGroup {
+		own := make(map[party.ID]curve.Scalar)
+		for _, e := range r.newWeights.EvaluationIDs(r.SelfID()) {
+			own[e] = r.poly.Evaluate(e.Scalar(r.Group()))
+		}
+		r.shares[r.SelfID()] = own
 	}
 
-	// Store commitments and chain keys
-	r.commitments[from] = body.Commitments
-	r.chainKeys[from] = body.ChainKey
-
-	return nil
+	return &round3{round2: r}, nil
 }
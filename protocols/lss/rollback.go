@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/luxfi/threshold/pkg/math/curve"
 	"github.com/luxfi/threshold/pkg/party"
 	"github.com/luxfi/threshold/protocols/lss/config"
 )
@@ -203,14 +204,74 @@ func timeNowUnix() int64 {
 // Global rollback manager instance
 var defaultRollbackManager = NewRollbackManager(10)
 
-// Rollback performs a rollback to a previous generation
-func Rollback(cfg *config.Config, targetGeneration uint64) (*config.Config, error) {
-	// Save current state if not already saved
+// SaveSnapshot records cfg in the default rollback manager, so that Rollback can restore it later.
+func SaveSnapshot(cfg *config.Config) error {
+	return defaultRollbackManager.SaveSnapshot(cfg)
+}
+
+// Rollback restores cfg in place to the shares it held at targetGeneration, from the snapshots
+// recorded with SaveSnapshot, and removes the evicted parties from it.
+//
+// Like RollbackManager.Rollback, the restored config moves to the generation after the current one,
+// and RollbackFrom records the generation it was rolled back from.
+// The current config is saved as a snapshot first, so the rollback itself can be undone.
+//
+// Rolling forward, to targetGeneration >= cfg.Generation, is rejected, as are targets without a
+// snapshot of the same party and key.
+func Rollback(cfg *config.Config, targetGeneration uint64, evicted []party.ID) error {
+	if cfg == nil {
+		return errors.New("configuration is nil")
+	}
+	if targetGeneration >= cfg.Generation {
+		return fmt.Errorf("cannot rollback to generation %d, which is not before the current generation %d",
+			targetGeneration, cfg.Generation)
+	}
+	publicKey, err := cfg.PublicKey()
+	if err != nil {
+		return fmt.Errorf("rollback: %w", err)
+	}
+
+	snapshot := defaultRollbackManager.snapshot(cfg.ID, targetGeneration, publicKey)
+	if snapshot == nil {
+		return fmt.Errorf("no snapshot of generation %d for party %s", targetGeneration, cfg.ID)
+	}
+	restored := snapshot.Config.Copy()
+	for _, id := range evicted {
+		if id == cfg.ID {
+			return fmt.Errorf("cannot evict %s from its own config", id)
+		}
+		delete(restored.Public, id)
+	}
+	if len(restored.Public) < restored.Threshold {
+		return fmt.Errorf("eviction would leave %d parties, below threshold %d",
+			len(restored.Public), restored.Threshold)
+	}
+
 	if err := defaultRollbackManager.SaveSnapshot(cfg); err != nil {
-		return nil, fmt.Errorf("failed to save current state: %w", err)
+		return fmt.Errorf("failed to save current state: %w", err)
 	}
-	
-	return defaultRollbackManager.Rollback(targetGeneration)
+	current := cfg.Generation
+	*cfg = *restored
+	cfg.Generation = current + 1
+	cfg.RollbackFrom = current
+	return nil
+}
+
+// snapshot returns the most recent snapshot of the given party and generation, for the key publicKey.
+func (rm *RollbackManager) snapshot(id party.ID, generation uint64, publicKey curve.Point) *GenerationSnapshot {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	for i := len(rm.history) - 1; i >= 0; i-- {
+		s := rm.history[i]
+		if s.Generation != generation || s.Config.ID != id {
+			continue
+		}
+		if key, err := s.Config.PublicKey(); err == nil && key.Equal(publicKey) {
+			return s
+		}
+	}
+	return nil
 }
 
 // RollbackOnFailure triggers automatic rollback after repeated failures
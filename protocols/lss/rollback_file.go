@@ -0,0 +1,220 @@
+package lss
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/protocols/lss/config"
+)
+
+// rollbackFileVersion is the version of the on-disk format written by SaveToFile.
+// Fields may be added to the format without changing it, since unknown fields are ignored on load;
+// it must be bumped when existing fields change meaning.
+const rollbackFileVersion = 1
+
+type rollbackFile struct {
+	Version        uint
+	MaxGenerations int
+	CurrentGen     uint64
+	Snapshots      []snapshotMarshal
+}
+
+type snapshotMarshal struct {
+	Generation   uint64
+	Timestamp    int64
+	FailureCount int
+	Config       configMarshal
+}
+
+// configMarshal holds a config with its scalars and points in their binary encoding.
+// The snapshot's party IDs and threshold are derived from it on load.
+type configMarshal struct {
+	ID           party.ID
+	Curve        string
+	Threshold    int
+	Generation   uint64
+	RollbackFrom uint64
+	ECDSA        []byte
+	Public       map[party.ID][]byte
+	ChainKey     []byte
+	RID          []byte
+	Weights      map[party.ID]int    `cbor:",omitempty"`
+	ExtraShares  map[party.ID][]byte `cbor:",omitempty"`
+}
+
+// SaveToFile writes the snapshots held by rm to path, so that the rollback window survives a restart.
+//
+// The file holds secret shares, so it is only readable by its owner.
+// It is written to a temporary file first and then renamed, so a crash never leaves a truncated file behind.
+func (rm *RollbackManager) SaveToFile(path string) error {
+	rm.mu.RLock()
+	file := rollbackFile{
+		Version:        rollbackFileVersion,
+		MaxGenerations: rm.maxGenerations,
+		CurrentGen:     rm.currentGen,
+		Snapshots:      make([]snapshotMarshal, 0, len(rm.history)),
+	}
+	for _, s := range rm.history {
+		c, err := marshalSnapshotConfig(s.Config)
+		if err != nil {
+			rm.mu.RUnlock()
+			return fmt.Errorf("rollback: generation %d: %w", s.Generation, err)
+		}
+		file.Snapshots = append(file.Snapshots, snapshotMarshal{
+			Generation:   s.Generation,
+			Timestamp:    s.Timestamp,
+			FailureCount: s.FailureCount,
+			Config:       c,
+		})
+	}
+	rm.mu.RUnlock()
+
+	data, err := cbor.Marshal(&file)
+	if err != nil {
+		return fmt.Errorf("rollback: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("rollback: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("rollback: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("rollback: %w", err)
+	}
+	return nil
+}
+
+// LoadRollbackManager reads a RollbackManager back from a file written by SaveToFile.
+func LoadRollbackManager(path string) (*RollbackManager, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("rollback: %w", err)
+	}
+	var file rollbackFile
+	if err := cbor.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("rollback: failed to decode %s: %w", path, err)
+	}
+	if file.Version == 0 || file.Version > rollbackFileVersion {
+		return nil, fmt.Errorf("rollback: unsupported file version %d (supported: %d)", file.Version, rollbackFileVersion)
+	}
+
+	rm := NewRollbackManager(file.MaxGenerations)
+	rm.currentGen = file.CurrentGen
+	for _, s := range file.Snapshots {
+		cfg, err := s.Config.unmarshal()
+		if err != nil {
+			return nil, fmt.Errorf("rollback: generation %d: %w", s.Generation, err)
+		}
+		rm.history = append(rm.history, &GenerationSnapshot{
+			Generation:   s.Generation,
+			Config:       cfg,
+			PartyIDs:     cfg.PartyIDs(),
+			Threshold:    cfg.Threshold,
+			Timestamp:    s.Timestamp,
+			FailureCount: s.FailureCount,
+		})
+	}
+	if len(rm.history) > rm.maxGenerations {
+		rm.history = rm.history[len(rm.history)-rm.maxGenerations:]
+	}
+	return rm, nil
+}
+
+func marshalSnapshotConfig(cfg *config.Config) (configMarshal, error) {
+	ecdsa, err := cfg.ECDSA.MarshalBinary()
+	if err != nil {
+		return configMarshal{}, fmt.Errorf("failed to marshal ECDSA share: %w", err)
+	}
+	public := make(map[party.ID][]byte, len(cfg.Public))
+	for id, p := range cfg.Public {
+		if public[id], err = p.ECDSA.MarshalBinary(); err != nil {
+			return configMarshal{}, fmt.Errorf("failed to marshal public ECDSA for %s: %w", id, err)
+		}
+	}
+	var extra map[party.ID][]byte
+	for id, share := range cfg.ExtraShares {
+		if extra == nil {
+			extra = make(map[party.ID][]byte, len(cfg.ExtraShares))
+		}
+		if extra[id], err = share.MarshalBinary(); err != nil {
+			return configMarshal{}, fmt.Errorf("failed to marshal extra share %s: %w", id, err)
+		}
+	}
+	return configMarshal{
+		ID:           cfg.ID,
+		Curve:        cfg.Group.Name(),
+		Threshold:    cfg.Threshold,
+		Generation:   cfg.Generation,
+		RollbackFrom: cfg.RollbackFrom,
+		ECDSA:        ecdsa,
+		Public:       public,
+		ChainKey:     cfg.ChainKey,
+		RID:          cfg.RID,
+		Weights:      cfg.Weights.Copy(),
+		ExtraShares:  extra,
+	}, nil
+}
+
+func (c *configMarshal) unmarshal() (*config.Config, error) {
+	group, err := curveByName(c.Curve)
+	if err != nil {
+		return nil, err
+	}
+	cfg := config.EmptyConfig(group)
+	cfg.ID = c.ID
+	cfg.Threshold = c.Threshold
+	cfg.Generation = c.Generation
+	cfg.RollbackFrom = c.RollbackFrom
+	cfg.ChainKey = c.ChainKey
+	cfg.RID = c.RID
+	cfg.ECDSA = group.NewScalar()
+	if err := cfg.ECDSA.UnmarshalBinary(c.ECDSA); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal ECDSA share: %w", err)
+	}
+	for id, data := range c.Public {
+		p := group.NewPoint()
+		if err := p.UnmarshalBinary(data); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal public ECDSA for %s: %w", id, err)
+		}
+		cfg.Public[id] = &config.Public{ECDSA: p}
+	}
+	cfg.Weights = config.Weights(c.Weights).Copy()
+	for id, data := range c.ExtraShares {
+		if cfg.ExtraShares == nil {
+			cfg.ExtraShares = make(map[party.ID]curve.Scalar, len(c.ExtraShares))
+		}
+		cfg.ExtraShares[id] = group.NewScalar()
+		if err := cfg.ExtraShares[id].UnmarshalBinary(data); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal extra share %s: %w", id, err)
+		}
+	}
+	return cfg, nil
+}
+
+// curveByName returns the curve whose Name is name.
+func curveByName(name string) (curve.Curve, error) {
+	for _, group := range []curve.Curve{curve.Secp256k1{}, curve.P256{}, curve.Ed25519{}} {
+		if group.Name() == name {
+			return group, nil
+		}
+	}
+	if name == "" {
+		return nil, errors.New("missing curve")
+	}
+	return nil, fmt.Errorf("unknown curve %s", name)
+}
@@ -0,0 +1,141 @@
+package lss_test
+
+import (
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/protocols/lss"
+	"github.com/luxfi/threshold/protocols/lss/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollbackAfterReshares(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := []party.ID{"rb-a", "rb-b", "rb-c"}
+
+	gen0 := lss.RunKeygen(t, group, partyIDs, 2)
+	gen1 := lss.RunReshare(t, gen0, partyIDs, 2)
+	gen2 := lss.RunReshare(t, gen1, partyIDs, 2)
+	for _, configs := range []map[party.ID]*config.Config{gen0, gen1, gen2} {
+		for _, id := range partyIDs {
+			require.NoError(t, lss.SaveSnapshot(configs[id]))
+		}
+	}
+	require.EqualValues(t, 2, gen2["rb-a"].Generation)
+	publicKey, err := gen0["rb-a"].PublicKey()
+	require.NoError(t, err)
+
+	// roll back to the keygen shares, evicting rb-c
+	restored := make(map[party.ID]*config.Config)
+	for _, id := range partyIDs[:2] {
+		cfg := gen2[id].Copy()
+		require.NoError(t, lss.Rollback(cfg, 0, []party.ID{"rb-c"}))
+		assert.True(t, cfg.ECDSA.Equal(gen0[id].ECDSA), "shares must be those of generation 0")
+		assert.EqualValues(t, 3, cfg.Generation)
+		assert.EqualValues(t, 2, cfg.RollbackFrom)
+		assert.NotContains(t, cfg.Public, party.ID("rb-c"))
+		key, err := cfg.PublicKey()
+		require.NoError(t, err)
+		assert.True(t, key.Equal(publicKey))
+		restored[id] = cfg
+	}
+
+	// the restored shares still sign for the same key
+	hash := sha256.Sum256([]byte("rollback"))
+	sig := lss.RunSign(t, restored, partyIDs[:2], hash[:])
+	assert.True(t, lss.VerifySignature(sig, publicKey, hash[:]))
+}
+
+func TestRollbackErrors(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := []party.ID{"rbe-a", "rbe-b", "rbe-c"}
+	gen0 := lss.RunKeygen(t, group, partyIDs, 2)
+	gen1 := lss.RunReshare(t, gen0, partyIDs, 2)
+	require.NoError(t, lss.SaveSnapshot(gen1["rbe-a"]))
+
+	cfg := gen1["rbe-a"].Copy()
+	assert.Error(t, lss.Rollback(cfg, 1, nil), "rolling back to the current generation")
+	assert.Error(t, lss.Rollback(cfg, 2, nil), "rolling forward")
+	assert.Error(t, lss.Rollback(cfg, 0, nil), "generation 0 was never saved")
+	assert.Error(t, lss.Rollback(nil, 0, nil))
+
+	require.NoError(t, lss.SaveSnapshot(gen0["rbe-a"]))
+	assert.Error(t, lss.Rollback(cfg, 0, []party.ID{"rbe-a"}), "a party can't evict itself")
+	assert.Error(t, lss.Rollback(cfg, 0, []party.ID{"rbe-b", "rbe-c"}), "too few parties would remain")
+
+	// snapshots of another key are never used
+	other := lss.RunKeygen(t, group, partyIDs, 2)
+	otherCfg := lss.RunReshare(t, other, partyIDs, 2)["rbe-a"]
+	assert.Error(t, lss.Rollback(otherCfg, 0, nil))
+
+	// failed attempts leave the config untouched
+	assert.EqualValues(t, 1, cfg.Generation)
+	assert.True(t, cfg.ECDSA.Equal(gen1["rbe-a"].ECDSA))
+	require.NoError(t, lss.Rollback(cfg, 0, nil))
+	assert.True(t, cfg.ECDSA.Equal(gen0["rbe-a"].ECDSA))
+}
+
+func TestRollbackManagerSaveToFile(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := []party.ID{"rbf-a", "rbf-b", "rbf-c"}
+	gen0 := lss.RunKeygen(t, group, partyIDs, 2)
+	gen1 := lss.RunReshare(t, gen0, partyIDs, 2)
+
+	mgr := lss.NewRollbackManager(5)
+	require.NoError(t, mgr.SaveSnapshot(gen0["rbf-a"]))
+	require.NoError(t, mgr.SaveSnapshot(gen1["rbf-a"]))
+	// one failure is recorded before the restart
+	_, err := mgr.RollbackOnFailure(2)
+	require.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "rollback.cbor")
+	require.NoError(t, mgr.SaveToFile(path))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "the file holds secret shares")
+
+	loaded, err := lss.LoadRollbackManager(path)
+	require.NoError(t, err)
+	history := loaded.GetHistory()
+	require.Len(t, history, 2)
+	for i, want := range []*config.Config{gen0["rbf-a"], gen1["rbf-a"]} {
+		got := history[i]
+		assert.Equal(t, want.Generation, got.Generation)
+		assert.Equal(t, want.Threshold, got.Threshold)
+		assert.ElementsMatch(t, want.PartyIDs(), got.PartyIDs)
+		assert.True(t, want.ECDSA.Equal(got.Config.ECDSA))
+		assert.Equal(t, want.ChainKey, got.Config.ChainKey)
+		for id, p := range want.Public {
+			assert.True(t, p.ECDSA.Equal(got.Config.Public[id].ECDSA))
+		}
+	}
+
+	// the failure count survived, so the second failure triggers the rollback
+	restored, err := loaded.RollbackOnFailure(2)
+	require.NoError(t, err)
+	assert.True(t, restored.ECDSA.Equal(gen0["rbf-a"].ECDSA))
+	assert.EqualValues(t, 2, restored.Generation)
+	assert.EqualValues(t, 1, restored.RollbackFrom)
+}
+
+func TestLoadRollbackManagerRejects(t *testing.T) {
+	dir := t.TempDir()
+	_, err := lss.LoadRollbackManager(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+
+	garbage := filepath.Join(dir, "garbage")
+	require.NoError(t, os.WriteFile(garbage, []byte("not cbor"), 0o600))
+	_, err = lss.LoadRollbackManager(garbage)
+	assert.Error(t, err)
+
+	// {"Version": 999}
+	future := filepath.Join(dir, "future")
+	require.NoError(t, os.WriteFile(future, []byte{0xa1, 0x67, 'V', 'e', 'r', 's', 'i', 'o', 'n', 0x19, 0x03, 0xe7}, 0o600))
+	_, err = lss.LoadRollbackManager(future)
+	assert.ErrorContains(t, err, "unsupported file version 999")
+}
@@ -0,0 +1,102 @@
+package sign
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/internal/test"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/math/polynomial"
+	"github.com/luxfi/threshold/pkg/math/sample"
+	"github.com/luxfi/threshold/pkg/party"
+	"github.com/luxfi/threshold/pkg/pool"
+	"github.com/luxfi/threshold/protocols/lss/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openRule makes a open other values than those it committed to in round 1 or round 7.
+type openRule struct {
+	modify func(content round.Content)
+}
+
+func (openRule) ModifyBefore(round.Session) {}
+
+func (openRule) ModifyAfter(round.Session) {}
+
+func (r openRule) ModifyContent(rNext round.Session, _ party.ID, content round.Content) {
+	if rNext.SelfID() == "a" {
+		r.modify(content)
+	}
+}
+
+func TestSignOpening(t *testing.T) {
+	group := curve.Secp256k1{}
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	partyIDs := []party.ID{"a", "b", "c"}
+	f := polynomial.NewPolynomial(group, 1, sample.Scalar(rand.Reader, group))
+	public := make(map[party.ID]*config.Public)
+	configs := make(map[party.ID]*config.Config)
+	for _, id := range partyIDs {
+		share := f.Evaluate(id.Scalar(group))
+		public[id] = &config.Public{ECDSA: share.ActOnBase()}
+		configs[id] = &config.Config{ID: id, Group: group, Threshold: 2, ECDSA: share, Public: public}
+	}
+	messageHash := make([]byte, 32)
+
+	tests := map[string]func(content round.Content){
+		"honest": nil,
+		"nonce point": func(content round.Content) {
+			if body, ok := content.(*broadcast7); ok {
+				body.K = body.K.Add(sample.Scalar(rand.Reader, group).ActOnBase())
+			}
+		},
+		"share of u": func(content round.Content) {
+			if body, ok := content.(*broadcast9); ok {
+				body.U = group.NewScalar().Set(body.U).Add(sample.Scalar(rand.Reader, group))
+			}
+		},
+		"gamma": func(content round.Content) {
+			if body, ok := content.(*broadcast9); ok {
+				body.Gamma = body.Gamma.Add(sample.Scalar(rand.Reader, group).ActOnBase())
+			}
+		},
+		"psi": func(content round.Content) {
+			if body, ok := content.(*broadcast9); ok {
+				body.Psi = body.Psi.Sub(sample.Scalar(rand.Reader, group).ActOnBase())
+			}
+		},
+	}
+	for name, modify := range tests {
+		t.Run(name, func(t *testing.T) {
+			rounds := make([]round.Session, 0, len(partyIDs))
+			for _, id := range partyIDs {
+				r, err := Start(configs[id], partyIDs, messageHash, pl)(nil)
+				require.NoError(t, err)
+				rounds = append(rounds, r)
+			}
+			var rule test.Rule
+			if modify != nil {
+				rule = openRule{modify: modify}
+			}
+			for {
+				err, done := test.Rounds(rounds, rule)
+				if modify != nil && err != nil {
+					assert.ErrorContains(t, err, "failed to decommit")
+					return
+				}
+				require.NoError(t, err)
+				if done {
+					break
+				}
+			}
+			require.Nil(t, modify, "a must not open values it didn't commit to")
+			for _, r := range rounds {
+				require.IsType(t, &round.Output{}, r)
+			}
+		})
+	}
+}
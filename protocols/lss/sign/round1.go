@@ -3,32 +3,27 @@ package sign
 import (
 	"crypto/rand"
 
+	"github.com/luxfi/threshold/internal/ot"
 	"github.com/luxfi/threshold/internal/round"
-	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/hash"
+	"github.com/luxfi/threshold/pkg/math/polynomial"
 	"github.com/luxfi/threshold/pkg/math/sample"
 	"github.com/luxfi/threshold/pkg/party"
 	"github.com/luxfi/threshold/protocols/lss/config"
 )
 
-// round1 generates nonces for signing
+// round1 samples our shares of the nonce and the mask, and starts the OT setups with the other signers
 type round1 struct {
 	*round.Helper
 
 	config      *config.Config
-	signers     []party.ID
+	signers     party.IDSlice
 	messageHash []byte
-
-	// Our nonce pair
-	k curve.Scalar // Secret nonce
-	K curve.Point  // Public nonce commitment g^k
 }
 
-// broadcast1 contains the nonce commitment
-type broadcast1 struct {
-	round.NormalBroadcastContent
-
-	// Public nonce commitment
-	K curve.Point
+// message2 is the first message of the OT setup in which the receiver of the message is the OT sender
+type message2 struct {
+	Setup *ot.CorreOTSetupReceiveRound1Message
 }
 
 // Number implements round.Round
@@ -36,52 +31,80 @@ func (r *round1) Number() round.Number {
 	return 1
 }
 
-// BroadcastContent implements round.BroadcastRound
-func (r *round1) BroadcastContent() round.BroadcastContent {
-	return &broadcast1{}
-}
-
 // MessageContent implements round.Round
 func (r *round1) MessageContent() round.Content {
-	return nil // No P2P messages in round 1
-}
-
-// RoundNumber implements round.Content
-func (broadcast1) RoundNumber() round.Number {
-	return 1
+	return nil // No messages in round 1
 }
 
 // VerifyMessage implements round.Round
 func (r *round1) VerifyMessage(_ round.Message) error {
-	return nil // No P2P messages
+	return nil // No messages in round 1
 }
 
 // StoreMessage implements round.Round
 func (r *round1) StoreMessage(_ round.Message) error {
-	return nil // No P2P messages
+	return nil // No messages in round 1
+}
+
+// RoundNumber implements round.Content
+func (message2) RoundNumber() round.Number {
+	return 2
 }
 
 // Finalize implements round.Round
+//
+// Our additive share of the key is w = Σ λₑ⋅xₑ over our evaluation IDs e, with the Lagrange coefficients
+// of the evaluation IDs of all signers. We sample our shares k and φ of the nonce and of the mask,
+// and broadcast a commitment to K = k⋅G, which is opened once the multiplications are done.
+//
+// With every other signer j, we run two correlated OT setups: one in which we are the OT sender,
+// multiplying k and w by the mask of j, and one in which j is the OT sender, multiplying its shares by our φ.
 func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
-	// Generate random nonce
-	r.k = sample.Scalar(rand.Reader, r.Group())
-	r.K = r.k.ActOnBase()
-
-	// Broadcast nonce commitment
-	if err := r.BroadcastMessage(out, &broadcast1{
-		K: r.K,
-	}); err != nil {
-		return nil, err
+	group := r.Group()
+	lagrange := polynomial.Lagrange(group, r.config.Weights.AllEvaluationIDs(r.signers))
+	w := group.NewScalar()
+	for id, share := range r.config.Shares() {
+		w.Add(group.NewScalar().Set(lagrange[id]).Mul(share))
+	}
+	k := sample.Scalar(rand.Reader, group)
+	phi := sample.Scalar(rand.Reader, group)
+	K := k.ActOnBase()
+
+	self := r.SelfID()
+	commitment, decommitment, err := r.HashForID(self).Commit(K)
+	if err != nil {
+		return r, err
+	}
+	if err := r.BroadcastMessage(out, &broadcast2{Generation: r.config.Generation, Commitment: commitment}); err != nil {
+		return r, err
 	}
 
-	return &round2{
-		round1: r,
-		nonces: make(map[party.ID]curve.Point),
-	}, nil
+	next := &round2{
+		round1:            r,
+		w:                 w,
+		k:                 k,
+		phi:               phi,
+		K:                 K,
+		nonceDecommitment: decommitment,
+		nonceCommitments:  make(map[party.ID]hash.Commitment, len(r.signers)-1),
+		otSenders:         make(map[party.ID]*ot.CorreOTSetupSender, len(r.signers)-1),
+		otReceivers:       make(map[party.ID]*ot.CorreOTSetupReceiver, len(r.signers)-1),
+		messages:          make(map[party.ID]*ot.CorreOTSetupReceiveRound1Message, len(r.signers)-1),
+	}
+	for _, j := range r.OtherPartyIDs() {
+		next.otSenders[j] = ot.NewCorreOTSetupSender(r.Pool, r.otHash(self, j))
+		next.otReceivers[j] = ot.NewCorreOTSetupReceiver(r.Pool, r.otHash(j, self), group)
+		if err := r.SendMessage(out, &message2{Setup: next.otReceivers[j].Round1()}, j); err != nil {
+			return r, err
+		}
+	}
+	return next, nil
 }
 
-// StoreBroadcastMessage implements round.BroadcastRound
-func (r *round1) StoreBroadcastMessage(_ round.Message) error {
-	// Messages stored in round2
-	return nil
+// otHash returns the hash of the OT setup and multiplications between sender and receiver.
+func (r *round1) otHash(sender, receiver party.ID) *hash.Hash {
+	return r.Hash().Fork(
+		&hash.BytesWithDomain{TheDomain: "LSS Sign OT Sender", Bytes: []byte(sender)},
+		&hash.BytesWithDomain{TheDomain: "LSS Sign OT Receiver", Bytes: []byte(receiver)},
+	)
 }
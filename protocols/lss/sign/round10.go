@@ -0,0 +1,81 @@
+package sign
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/pkg/ecdsa"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+)
+
+// round10 combines the shares of the signature
+type round10 struct {
+	*round9
+
+	// S[j] is the share of the signature of signer j
+	S map[party.ID]curve.Scalar
+}
+
+// Number implements round.Round
+func (r *round10) Number() round.Number {
+	return 10
+}
+
+// BroadcastContent implements round.BroadcastRound
+func (r *round10) BroadcastContent() round.BroadcastContent {
+	return &broadcast10{S: r.Group().NewScalar()}
+}
+
+// MessageContent implements round.Round
+func (r *round10) MessageContent() round.Content {
+	return nil // No messages in round 10
+}
+
+// StoreBroadcastMessage implements round.BroadcastRound
+func (r *round10) StoreBroadcastMessage(msg round.Message) error {
+	body, ok := msg.Content.(*broadcast10)
+	if !ok || body == nil {
+		return round.ErrInvalidContent
+	}
+	if body.S == nil {
+		return round.ErrNilFields
+	}
+	r.S[msg.From] = body.S
+	return nil
+}
+
+// VerifyMessage implements round.Round
+func (r *round10) VerifyMessage(_ round.Message) error {
+	return nil // No messages in round 10
+}
+
+// StoreMessage implements round.Round
+func (r *round10) StoreMessage(_ round.Message) error {
+	return nil // No messages in round 10
+}
+
+// Finalize implements round.Round
+//
+// The signature is (R, Σ sⱼ), which is checked against the public key.
+func (r *round10) Finalize(_ chan<- *round.Message) (round.Session, error) {
+	s := r.Group().NewScalar()
+	for _, j := range r.signers {
+		share, ok := r.S[j]
+		if !ok {
+			return r, fmt.Errorf("missing signature share from %s", j)
+		}
+		s.Add(share)
+	}
+
+	sig := &ecdsa.Signature{R: r.R, S: s}
+	public, err := r.config.PublicPoint()
+	if err != nil {
+		return r, err
+	}
+	if !sig.Verify(public, r.messageHash) {
+		return r.AbortRound(errors.New("signature verification failed")), nil
+	}
+	return r.ResultRound(sig), nil
+}
@@ -1,32 +1,51 @@
 package sign
 
 import (
-	"errors"
+	"fmt"
 
-	"github.com/cronokirby/saferith"
+	"github.com/luxfi/threshold/internal/ot"
 	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/pkg/hash"
 	"github.com/luxfi/threshold/pkg/math/curve"
-	"github.com/luxfi/threshold/pkg/math/polynomial"
 	"github.com/luxfi/threshold/pkg/party"
 )
 
-// round2 collects nonces and generates partial signatures
+// round2 collects the nonce commitments, and answers the OT setups in which we are the OT sender
 type round2 struct {
 	*round1
 
-	// Collected nonces from all signers
-	nonces map[party.ID]curve.Point
+	// w, k and phi are our additive shares of the key, the nonce and the mask
+	w, k, phi curve.Scalar
 
-	// Combined nonce point R
-	R curve.Point
+	// K = k⋅G is our nonce point, which we open with nonceDecommitment
+	K                 curve.Point
+	nonceDecommitment hash.Decommitment
+
+	// nonceCommitments[j] is the commitment of signer j to its nonce point Kⱼ
+	nonceCommitments map[party.ID]hash.Commitment
+
+	// otSenders[j] is our side of the OT setup in which we multiply our shares by the mask of j,
+	// and otReceivers[j] our side of the one in which j multiplies its shares by our mask
+	otSenders   map[party.ID]*ot.CorreOTSetupSender
+	otReceivers map[party.ID]*ot.CorreOTSetupReceiver
+
+	messages map[party.ID]*ot.CorreOTSetupReceiveRound1Message
 }
 
-// broadcast2 contains the partial signature
+// broadcast2 contains the nonce commitment of the sender
 type broadcast2 struct {
-	round.NormalBroadcastContent
+	round.ReliableBroadcastContent
+
+	// Generation of the sender's config, which must be that of every signer
+	Generation uint64
+
+	// Commitment = H(Kᵢ), so that no signer chooses its nonce point after seeing those of the others
+	Commitment hash.Commitment
+}
 
-	// Partial signature share
-	PartialSig curve.Scalar
+// message3 is the second message of the OT setup in which the sender of the message is the OT sender
+type message3 struct {
+	Setup *ot.CorreOTSetupSendRound1Message
 }
 
 // Number implements round.Round
@@ -41,7 +60,7 @@ func (r *round2) BroadcastContent() round.BroadcastContent {
 
 // MessageContent implements round.Round
 func (r *round2) MessageContent() round.Content {
-	return nil // No P2P messages
+	return &message2{Setup: ot.EmptyCorreOTSetupReceiveRound1Message(r.Group())}
 }
 
 // RoundNumber implements round.Content
@@ -49,107 +68,77 @@ func (broadcast2) RoundNumber() round.Number {
 	return 2
 }
 
-// VerifyMessage implements round.Round
-func (r *round2) VerifyMessage(_ round.Message) error {
-	return nil // No P2P messages
-}
-
-// StoreMessage implements round.Round
-func (r *round2) StoreMessage(_ round.Message) error {
-	return nil // No P2P messages
+// RoundNumber implements round.Content
+func (message3) RoundNumber() round.Number {
+	return 3
 }
 
-// Finalize implements round.Round
-func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
-	// Add our own nonce
-	r.nonces[r.SelfID()] = r.K
-
-	// Verify we have nonces from all signers
-	if len(r.nonces) != len(r.signers) {
-		return nil, errors.New("missing nonces from some signers")
-	}
-
-	// Compute combined R = sum of all K values
-	r.R = r.Group().NewPoint()
-	for _, K := range r.nonces {
-		r.R = r.R.Add(K)
-	}
-
-	// Convert R to scalar for signature
-	// Get the X coordinate bytes
-	rBytes, err := r.R.MarshalBinary()
-	if err != nil {
-		return nil, err
-	}
-	// Take first half as X coordinate (assuming compressed point format)
-	halfLen := len(rBytes) / 2
-	if halfLen > 32 {
-		halfLen = 32
+// StoreBroadcastMessage implements round.BroadcastRound
+func (r *round2) StoreBroadcastMessage(msg round.Message) error {
+	from := msg.From
+	body, ok := msg.Content.(*broadcast2)
+	if !ok || body == nil {
+		return round.ErrInvalidContent
 	}
-	xBytes := rBytes[:halfLen]
 
-	rScalar := r.Group().NewScalar()
-	if err := rScalar.UnmarshalBinary(xBytes); err != nil {
-		// If unmarshal fails, set directly from bytes with modular reduction
-		rScalar.SetNat(new(saferith.Nat).SetBytes(xBytes))
+	// Shares of different generations don't interpolate to the key, so reject them before using the nonce
+	if body.Generation != r.config.Generation {
+		return fmt.Errorf("signer %s has generation %d, but %s has generation %d", from, body.Generation, r.SelfID(), r.config.Generation)
 	}
 
-	// Convert message hash to scalar
-	mScalar := r.Group().NewScalar()
-	mScalar.SetNat(new(saferith.Nat).SetBytes(r.messageHash))
-
-	// Compute Lagrange coefficient for our ID
-	// This is simplified - in practice we need proper Lagrange interpolation
-	lagrangeCoeff := polynomial.Lagrange(r.Group(), r.signers)[r.SelfID()]
-
-	// Compute partial signature: s_i = k_i + r * λ_i * x_i * m
-	// where λ_i is the Lagrange coefficient, x_i is our secret share
-	partialSig := r.Group().NewScalar()
-	partialSig = partialSig.Set(rScalar)        // r
-	partialSig = partialSig.Mul(lagrangeCoeff)  // r * λ_i
-	partialSig = partialSig.Mul(r.config.ECDSA) // r * λ_i * x_i
-	partialSig = partialSig.Mul(mScalar)        // r * λ_i * x_i * m
-	partialSig = partialSig.Add(r.k)            // k_i + r * λ_i * x_i * m
-
-	// Broadcast partial signature
-	if err := r.BroadcastMessage(out, &broadcast2{
-		PartialSig: partialSig,
-	}); err != nil {
-		return nil, err
+	if err := body.Commitment.Validate(); err != nil {
+		return err
 	}
-
-	return &round3{
-		round2:      r,
-		partialSigs: make(map[party.ID]curve.Scalar),
-		rScalar:     rScalar,
-	}, nil
+	r.nonceCommitments[from] = body.Commitment
+	return nil
 }
 
-// StoreBroadcastMessage implements round.BroadcastRound
-func (r *round2) StoreBroadcastMessage(msg round.Message) error {
-	from := msg.From
-	body, ok := msg.Content.(*broadcast1)
+// VerifyMessage implements round.Round
+func (r *round2) VerifyMessage(msg round.Message) error {
+	body, ok := msg.Content.(*message2)
 	if !ok || body == nil {
 		return round.ErrInvalidContent
 	}
-
-	// Verify K is not identity
-	if body.K == nil || body.K.IsIdentity() {
-		return errors.New("invalid nonce commitment")
+	if body.Setup == nil {
+		return round.ErrNilFields
 	}
+	return nil
+}
 
-	// Verify sender is a signer
-	isSigner := false
-	for _, id := range r.signers {
-		if id == from {
-			isSigner = true
-			break
+// StoreMessage implements round.Round
+func (r *round2) StoreMessage(msg round.Message) error {
+	r.messages[msg.From] = msg.Content.(*message2).Setup
+	return nil
+}
+
+// Finalize implements round.Round
+func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
+	for _, j := range r.OtherPartyIDs() {
+		if _, ok := r.nonceCommitments[j]; !ok {
+			return r, fmt.Errorf("missing nonce commitment from %s", j)
 		}
 	}
-	if !isSigner {
-		return errors.New("sender not in signers list")
+
+	for _, j := range r.OtherPartyIDs() {
+		setup, err := r.otSenders[j].Round1(r.messages[j])
+		if err != nil {
+			return r.AbortRound(fmt.Errorf("OT setup with %s: %w", j, err), j), nil
+		}
+		if err := r.SendMessage(out, &message3{Setup: setup}, j); err != nil {
+			return r, err
+		}
 	}
 
-	r.nonces[from] = body.K
-	return nil
+	return &round3{
+		round1:            r.round1,
+		w:                 r.w,
+		k:                 r.k,
+		phi:               r.phi,
+		K:                 r.K,
+		nonceDecommitment: r.nonceDecommitment,
+		nonceCommitments:  r.nonceCommitments,
+		otSenders:         r.otSenders,
+		otReceivers:       r.otReceivers,
+		messages:          make(map[party.ID]*ot.CorreOTSetupSendRound1Message, len(r.signers)-1),
+	}, nil
 }
@@ -1,23 +1,40 @@
 package sign
 
 import (
-	"errors"
+	"fmt"
 
+	"github.com/luxfi/threshold/internal/ot"
 	"github.com/luxfi/threshold/internal/round"
-	"github.com/luxfi/threshold/pkg/ecdsa"
+	"github.com/luxfi/threshold/pkg/hash"
 	"github.com/luxfi/threshold/pkg/math/curve"
 	"github.com/luxfi/threshold/pkg/party"
 )
 
-// round3 combines partial signatures
+// round3 continues the OT setups in which we are the OT receiver
+//
+// It embeds round1 rather than round2, so that it doesn't expect a broadcast.
 type round3 struct {
-	*round2
+	*round1
 
-	// Collected partial signatures
-	partialSigs map[party.ID]curve.Scalar
+	// w, k and phi are our additive shares of the key, the nonce and the mask
+	w, k, phi curve.Scalar
 
-	// r value from R point
-	rScalar curve.Scalar
+	// K = k⋅G is our nonce point, which we open with nonceDecommitment once the multiplications are done
+	K                 curve.Point
+	nonceDecommitment hash.Decommitment
+
+	// nonceCommitments[j] is the commitment of signer j to its nonce point Kⱼ
+	nonceCommitments map[party.ID]hash.Commitment
+
+	otSenders   map[party.ID]*ot.CorreOTSetupSender
+	otReceivers map[party.ID]*ot.CorreOTSetupReceiver
+
+	messages map[party.ID]*ot.CorreOTSetupSendRound1Message
+}
+
+// message4 is the third message of the OT setup in which the receiver of the message is the OT sender
+type message4 struct {
+	Setup *ot.CorreOTSetupReceiveRound2Message
 }
 
 // Number implements round.Round
@@ -25,80 +42,48 @@ func (r *round3) Number() round.Number {
 	return 3
 }
 
-// BroadcastContent implements round.BroadcastRound
-func (r *round3) BroadcastContent() round.BroadcastContent {
-	return nil // No broadcast in round 3
-}
-
 // MessageContent implements round.Round
 func (r *round3) MessageContent() round.Content {
-	return nil // No messages in round 3
-}
-
-// VerifyMessage implements round.Round
-func (r *round3) VerifyMessage(_ round.Message) error {
-	return nil // No messages to verify
+	return &message3{}
 }
 
-// StoreMessage implements round.Round
-func (r *round3) StoreMessage(_ round.Message) error {
-	return nil // No messages to store
+// RoundNumber implements round.Content
+func (message4) RoundNumber() round.Number {
+	return 4
 }
 
-// Finalize implements round.Round
-func (r *round3) Finalize(_ chan<- *round.Message) (round.Session, error) {
-	// Verify we have partial signatures from all signers
-	if len(r.partialSigs) != len(r.signers) {
-		return nil, errors.New("missing partial signatures from some signers")
-	}
-
-	// Combine partial signatures: s = sum(s_i)
-	s := r.Group().NewScalar()
-	for _, partialSig := range r.partialSigs {
-		s = s.Add(partialSig)
-	}
-
-	// Create final ECDSA signature
-	sig := &ecdsa.Signature{
-		R: r.R,
-		S: s,
-	}
-
-	// Optionally verify the signature against the public key
-	publicKey, err := r.config.PublicPoint()
-	if err != nil {
-		return nil, err
+// VerifyMessage implements round.Round
+func (r *round3) VerifyMessage(msg round.Message) error {
+	body, ok := msg.Content.(*message3)
+	if !ok || body == nil {
+		return round.ErrInvalidContent
 	}
-
-	if !sig.Verify(publicKey, r.messageHash) {
-		return nil, errors.New("signature verification failed")
+	if body.Setup == nil {
+		return round.ErrNilFields
 	}
-
-	return r.ResultRound(sig), nil
+	return nil
 }
 
-// StoreBroadcastMessage implements round.BroadcastRound
-func (r *round3) StoreBroadcastMessage(msg round.Message) error {
-	from := msg.From
-	body, ok := msg.Content.(*broadcast2)
-	if !ok || body == nil {
-		return round.ErrInvalidContent
-	}
+// StoreMessage implements round.Round
+func (r *round3) StoreMessage(msg round.Message) error {
+	r.messages[msg.From] = msg.Content.(*message3).Setup
+	return nil
+}
 
-	// Verify sender is a signer
-	isSigner := false
-	for _, id := range r.signers {
-		if id == from {
-			isSigner = true
-			break
+// Finalize implements round.Round
+func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
+	for _, j := range r.OtherPartyIDs() {
+		setup, err := r.otReceivers[j].Round2(r.messages[j])
+		if err != nil {
+			return r.AbortRound(fmt.Errorf("OT setup with %s: %w", j, err), j), nil
+		}
+		if err := r.SendMessage(out, &message4{Setup: setup}, j); err != nil {
+			return r, err
 		}
-	}
-	if !isSigner {
-		return errors.New("sender not in signers list")
 	}
 
-	// Store partial signature
-	r.partialSigs[from] = body.PartialSig
-
-	return nil
+	return &round4{
+		round3:   r,
+		messages: make(map[party.ID]*ot.CorreOTSetupReceiveRound2Message, len(r.signers)-1),
+	}, nil
 }
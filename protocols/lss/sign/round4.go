@@ -0,0 +1,67 @@
+package sign
+
+import (
+	"github.com/luxfi/threshold/internal/ot"
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/pkg/party"
+)
+
+// round4 continues the OT setups in which we are the OT sender
+type round4 struct {
+	*round3
+
+	messages map[party.ID]*ot.CorreOTSetupReceiveRound2Message
+}
+
+// message5 is the fourth message of the OT setup in which the sender of the message is the OT sender
+type message5 struct {
+	Setup *ot.CorreOTSetupSendRound2Message
+}
+
+// Number implements round.Round
+func (r *round4) Number() round.Number {
+	return 4
+}
+
+// MessageContent implements round.Round
+func (r *round4) MessageContent() round.Content {
+	return &message4{}
+}
+
+// RoundNumber implements round.Content
+func (message5) RoundNumber() round.Number {
+	return 5
+}
+
+// VerifyMessage implements round.Round
+func (r *round4) VerifyMessage(msg round.Message) error {
+	body, ok := msg.Content.(*message4)
+	if !ok || body == nil {
+		return round.ErrInvalidContent
+	}
+	if body.Setup == nil {
+		return round.ErrNilFields
+	}
+	return nil
+}
+
+// StoreMessage implements round.Round
+func (r *round4) StoreMessage(msg round.Message) error {
+	r.messages[msg.From] = msg.Content.(*message4).Setup
+	return nil
+}
+
+// Finalize implements round.Round
+func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
+	for _, j := range r.OtherPartyIDs() {
+		setup := r.otSenders[j].Round2(r.messages[j])
+		if err := r.SendMessage(out, &message5{Setup: setup}, j); err != nil {
+			return r, err
+		}
+	}
+
+	return &round5{
+		round4:   r,
+		messages: make(map[party.ID]*ot.CorreOTSetupSendRound2Message, len(r.signers)-1),
+	}, nil
+}
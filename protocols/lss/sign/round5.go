@@ -0,0 +1,94 @@
+package sign
+
+import (
+	"fmt"
+
+	"github.com/luxfi/threshold/internal/ot"
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/pkg/hash"
+	"github.com/luxfi/threshold/pkg/party"
+)
+
+// round5 completes the OT setups in which we are the OT receiver, and starts the multiplications by our mask
+type round5 struct {
+	*round4
+
+	messages map[party.ID]*ot.CorreOTSetupSendRound2Message
+}
+
+// message6 is the last message of the OT setup in which the receiver of the message is the OT sender,
+// with the first messages of the multiplications of its nonce and key shares by the mask of the sender
+type message6 struct {
+	Setup *ot.CorreOTSetupReceiveRound3Message
+	K, W  *ot.MultiplyReceiveRound1Message
+}
+
+// Number implements round.Round
+func (r *round5) Number() round.Number {
+	return 5
+}
+
+// MessageContent implements round.Round
+func (r *round5) MessageContent() round.Content {
+	return &message5{}
+}
+
+// RoundNumber implements round.Content
+func (message6) RoundNumber() round.Number {
+	return 6
+}
+
+// VerifyMessage implements round.Round
+func (r *round5) VerifyMessage(msg round.Message) error {
+	body, ok := msg.Content.(*message5)
+	if !ok || body == nil {
+		return round.ErrInvalidContent
+	}
+	if body.Setup == nil {
+		return round.ErrNilFields
+	}
+	return nil
+}
+
+// StoreMessage implements round.Round
+func (r *round5) StoreMessage(msg round.Message) error {
+	r.messages[msg.From] = msg.Content.(*message5).Setup
+	return nil
+}
+
+// Finalize implements round.Round
+func (r *round5) Finalize(out chan<- *round.Message) (round.Session, error) {
+	self := r.SelfID()
+	next := &round6{
+		round5:    r,
+		multiplyK: make(map[party.ID]*ot.MultiplyReceiver, len(r.signers)-1),
+		multiplyW: make(map[party.ID]*ot.MultiplyReceiver, len(r.signers)-1),
+		messages:  make(map[party.ID]*message6, len(r.signers)-1),
+	}
+	for _, j := range r.OtherPartyIDs() {
+		msg, setup, err := r.otReceivers[j].Round3(r.messages[j])
+		if err != nil {
+			return r.AbortRound(fmt.Errorf("OT setup with %s: %w", j, err), j), nil
+		}
+		if next.multiplyK[j], err = ot.NewMultiplyReceiver(r.otHash(j, self).Fork(tagK), setup, r.phi); err != nil {
+			return r, err
+		}
+		if next.multiplyW[j], err = ot.NewMultiplyReceiver(r.otHash(j, self).Fork(tagW), setup, r.phi); err != nil {
+			return r, err
+		}
+		if err := r.SendMessage(out, &message6{
+			Setup: msg,
+			K:     next.multiplyK[j].Round1(),
+			W:     next.multiplyW[j].Round1(),
+		}, j); err != nil {
+			return r, err
+		}
+	}
+	return next, nil
+}
+
+// tagK and tagW set apart the multiplications of the nonce and of the key share by the mask.
+var (
+	tagK = &hash.BytesWithDomain{TheDomain: "LSS Sign Multiply", Bytes: []byte("nonce")}
+	tagW = &hash.BytesWithDomain{TheDomain: "LSS Sign Multiply", Bytes: []byte("key")}
+)
@@ -0,0 +1,99 @@
+package sign
+
+import (
+	"fmt"
+
+	"github.com/luxfi/threshold/internal/ot"
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+)
+
+// round6 completes the OT setups in which we are the OT sender, and multiplies our shares by the masks of the others
+type round6 struct {
+	*round5
+
+	// multiplyK[j] and multiplyW[j] multiply the nonce and key shares of j by our mask
+	multiplyK, multiplyW map[party.ID]*ot.MultiplyReceiver
+
+	messages map[party.ID]*message6
+}
+
+// message7 contains the answers to the multiplications started by the receiver of the message
+type message7 struct {
+	K, W *ot.MultiplySendRound1Message
+}
+
+// Number implements round.Round
+func (r *round6) Number() round.Number {
+	return 6
+}
+
+// MessageContent implements round.Round
+func (r *round6) MessageContent() round.Content {
+	return &message6{}
+}
+
+// RoundNumber implements round.Content
+func (message7) RoundNumber() round.Number {
+	return 7
+}
+
+// VerifyMessage implements round.Round
+func (r *round6) VerifyMessage(msg round.Message) error {
+	body, ok := msg.Content.(*message6)
+	if !ok || body == nil {
+		return round.ErrInvalidContent
+	}
+	if body.Setup == nil || body.K == nil || body.W == nil {
+		return round.ErrNilFields
+	}
+	return nil
+}
+
+// StoreMessage implements round.Round
+func (r *round6) StoreMessage(msg round.Message) error {
+	r.messages[msg.From] = msg.Content.(*message6)
+	return nil
+}
+
+// Finalize implements round.Round
+//
+// Our sides of the multiplications kᵢ⋅φⱼ and wᵢ⋅φⱼ with every other signer j are the first terms
+// of our shares of k⋅φ and w⋅φ. With the multiplications done, we open our nonce point Kᵢ.
+func (r *round6) Finalize(out chan<- *round.Message) (round.Session, error) {
+	group := r.Group()
+	self := r.SelfID()
+	u, v := group.NewScalar(), group.NewScalar()
+	for _, j := range r.OtherPartyIDs() {
+		setup, err := r.otSenders[j].Round3(r.messages[j].Setup)
+		if err != nil {
+			return r.AbortRound(fmt.Errorf("OT setup with %s: %w", j, err), j), nil
+		}
+		msgK, shareK, err := ot.NewMultiplySender(r.otHash(self, j).Fork(tagK), setup, r.k).Round1(r.messages[j].K)
+		if err != nil {
+			return r.AbortRound(fmt.Errorf("multiplication with %s: %w", j, err), j), nil
+		}
+		msgW, shareW, err := ot.NewMultiplySender(r.otHash(self, j).Fork(tagW), setup, r.w).Round1(r.messages[j].W)
+		if err != nil {
+			return r.AbortRound(fmt.Errorf("multiplication with %s: %w", j, err), j), nil
+		}
+		u.Add(shareK)
+		v.Add(shareW)
+		if err := r.SendMessage(out, &message7{K: msgK, W: msgW}, j); err != nil {
+			return r, err
+		}
+	}
+
+	if err := r.BroadcastMessage(out, &broadcast7{K: r.K, Decommitment: r.nonceDecommitment}); err != nil {
+		return r, err
+	}
+
+	return &round7{
+		round6:   r,
+		K:        map[party.ID]curve.Point{self: r.K},
+		u:        u,
+		v:        v,
+		messages: make(map[party.ID]*message7, len(r.signers)-1),
+	}, nil
+}
@@ -0,0 +1,179 @@
+package sign
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/pkg/hash"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+)
+
+// round7 checks the opened nonce points, completes the multiplications by our mask, and commits to our share of k⋅φ
+type round7 struct {
+	*round6
+
+	// u and v are the terms of our shares of k⋅φ and w⋅φ computed so far
+	u, v curve.Scalar
+
+	// K[j] = kⱼ⋅G is the opened nonce point of signer j
+	K map[party.ID]curve.Point
+
+	messages map[party.ID]*message7
+}
+
+// broadcast7 opens the sender's nonce point, which it committed to in round 1
+type broadcast7 struct {
+	round.NormalBroadcastContent
+
+	// K = kᵢ⋅G is the sender's nonce point
+	K curve.Point
+
+	Decommitment hash.Decommitment
+}
+
+// broadcast8 contains the sender's commitment to the values it opens in the next round
+type broadcast8 struct {
+	round.ReliableBroadcastContent
+
+	// Commitment = H(Uᵢ, Γᵢ, Ψᵢ), so that no signer chooses its values after seeing those of the others
+	Commitment hash.Commitment
+}
+
+// Number implements round.Round
+func (r *round7) Number() round.Number {
+	return 7
+}
+
+// BroadcastContent implements round.BroadcastRound
+func (r *round7) BroadcastContent() round.BroadcastContent {
+	return &broadcast7{K: r.Group().NewPoint()}
+}
+
+// MessageContent implements round.Round
+func (r *round7) MessageContent() round.Content {
+	for _, j := range r.OtherPartyIDs() {
+		return &message7{
+			K: r.multiplyK[j].EmptyMultiplySendRound1Message(),
+			W: r.multiplyW[j].EmptyMultiplySendRound1Message(),
+		}
+	}
+	return &message7{}
+}
+
+// RoundNumber implements round.Content
+func (broadcast7) RoundNumber() round.Number {
+	return 7
+}
+
+// RoundNumber implements round.Content
+func (broadcast8) RoundNumber() round.Number {
+	return 8
+}
+
+// StoreBroadcastMessage implements round.BroadcastRound
+func (r *round7) StoreBroadcastMessage(msg round.Message) error {
+	body, ok := msg.Content.(*broadcast7)
+	if !ok || body == nil {
+		return round.ErrInvalidContent
+	}
+	if body.K == nil {
+		return round.ErrNilFields
+	}
+	if err := body.Decommitment.Validate(); err != nil {
+		return err
+	}
+	if !r.HashForID(msg.From).Decommit(r.nonceCommitments[msg.From], body.Decommitment, body.K) {
+		return errors.New("failed to decommit the nonce point")
+	}
+	if body.K.IsIdentity() {
+		return errors.New("the nonce point is the identity")
+	}
+	r.K[msg.From] = body.K
+	return nil
+}
+
+// VerifyMessage implements round.Round
+func (r *round7) VerifyMessage(msg round.Message) error {
+	body, ok := msg.Content.(*message7)
+	if !ok || body == nil {
+		return round.ErrInvalidContent
+	}
+	if body.K == nil || body.W == nil {
+		return round.ErrNilFields
+	}
+	return nil
+}
+
+// StoreMessage implements round.Round
+func (r *round7) StoreMessage(msg round.Message) error {
+	r.messages[msg.From] = msg.Content.(*message7)
+	return nil
+}
+
+// Finalize implements round.Round
+//
+// The nonce point is R = Σ Kⱼ.
+//
+// Adding the other sides of the multiplications kⱼ⋅φᵢ and wⱼ⋅φᵢ, and our own products, gives our shares
+//
+//	uᵢ = kᵢ⋅φᵢ + Σⱼ (kᵢ⋅φⱼ)ᵢ + (kⱼ⋅φᵢ)ᵢ and vᵢ = wᵢ⋅φᵢ + Σⱼ (wᵢ⋅φⱼ)ᵢ + (wⱼ⋅φᵢ)ᵢ
+//
+// of u = k⋅φ and v = x⋅φ. We commit to uᵢ, Γᵢ = φᵢ⋅R and Ψᵢ = φᵢ⋅X - vᵢ⋅G, which are opened in the next round:
+// the checks that Σ Γⱼ = u⋅G and that Σ Ψⱼ is the identity hold only if nobody chooses its values
+// after seeing the others.
+func (r *round7) Finalize(out chan<- *round.Message) (round.Session, error) {
+	group := r.Group()
+	R := group.NewPoint()
+	for _, j := range r.signers {
+		K, ok := r.K[j]
+		if !ok {
+			return r, fmt.Errorf("missing nonce point from %s", j)
+		}
+		R = R.Add(K)
+	}
+	if R.IsIdentity() {
+		return r.AbortRound(errors.New("the nonce point is the identity")), nil
+	}
+
+	u := group.NewScalar().Set(r.k).Mul(r.phi).Add(r.u)
+	v := group.NewScalar().Set(r.w).Mul(r.phi).Add(r.v)
+	for _, j := range r.OtherPartyIDs() {
+		shareK, err := r.multiplyK[j].Round2(r.messages[j].K)
+		if err != nil {
+			return r.AbortRound(fmt.Errorf("multiplication with %s: %w", j, err), j), nil
+		}
+		shareW, err := r.multiplyW[j].Round2(r.messages[j].W)
+		if err != nil {
+			return r.AbortRound(fmt.Errorf("multiplication with %s: %w", j, err), j), nil
+		}
+		u.Add(shareK)
+		v.Add(shareW)
+	}
+
+	public, err := r.config.PublicPoint()
+	if err != nil {
+		return r, err
+	}
+	Gamma := r.phi.Act(R)
+	Psi := r.phi.Act(public).Sub(v.ActOnBase())
+	commitment, decommitment, err := r.HashForID(r.SelfID()).Commit(u, Gamma, Psi)
+	if err != nil {
+		return r, err
+	}
+	if err := r.BroadcastMessage(out, &broadcast8{Commitment: commitment}); err != nil {
+		return r, err
+	}
+
+	return &round8{
+		round7:       r,
+		R:            R,
+		vShare:       v,
+		uShare:       u,
+		GammaShare:   Gamma,
+		PsiShare:     Psi,
+		decommitment: decommitment,
+		commitments:  make(map[party.ID]hash.Commitment, len(r.signers)-1),
+	}, nil
+}
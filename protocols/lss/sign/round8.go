@@ -0,0 +1,105 @@
+package sign
+
+import (
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/pkg/hash"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+)
+
+// round8 collects the commitments of the signers, and opens ours
+type round8 struct {
+	*round7
+
+	// R = Σ Kⱼ is the nonce point of the signature
+	R curve.Point
+
+	// vShare is our share of v = x⋅φ
+	vShare curve.Scalar
+
+	// uShare, GammaShare and PsiShare are the values we committed to
+	uShare               curve.Scalar
+	GammaShare, PsiShare curve.Point
+	decommitment         hash.Decommitment
+
+	// commitments[j] is the commitment of signer j to its values of broadcast9
+	commitments map[party.ID]hash.Commitment
+}
+
+// broadcast9 opens the sender's share of u = k⋅φ, and the points checking the consistency of its shares
+type broadcast9 struct {
+	round.NormalBroadcastContent
+
+	// U is the sender's share of u = k⋅φ
+	U curve.Scalar
+
+	// Gamma = φᵢ⋅R and Psi = φᵢ⋅X - vᵢ⋅G, which sum to u⋅G and to the identity
+	Gamma, Psi curve.Point
+
+	Decommitment hash.Decommitment
+}
+
+// Number implements round.Round
+func (r *round8) Number() round.Number {
+	return 8
+}
+
+// BroadcastContent implements round.BroadcastRound
+func (r *round8) BroadcastContent() round.BroadcastContent {
+	return &broadcast8{}
+}
+
+// MessageContent implements round.Round
+func (r *round8) MessageContent() round.Content {
+	return nil // No messages in round 8
+}
+
+// RoundNumber implements round.Content
+func (broadcast9) RoundNumber() round.Number {
+	return 9
+}
+
+// StoreBroadcastMessage implements round.BroadcastRound
+func (r *round8) StoreBroadcastMessage(msg round.Message) error {
+	body, ok := msg.Content.(*broadcast8)
+	if !ok || body == nil {
+		return round.ErrInvalidContent
+	}
+	if err := body.Commitment.Validate(); err != nil {
+		return err
+	}
+	r.commitments[msg.From] = body.Commitment
+	return nil
+}
+
+// VerifyMessage implements round.Round
+func (r *round8) VerifyMessage(_ round.Message) error {
+	return nil // No messages in round 8
+}
+
+// StoreMessage implements round.Round
+func (r *round8) StoreMessage(_ round.Message) error {
+	return nil // No messages in round 8
+}
+
+// Finalize implements round.Round
+//
+// Once every signer is committed, we open uᵢ, Γᵢ and Ψᵢ.
+func (r *round8) Finalize(out chan<- *round.Message) (round.Session, error) {
+	if err := r.BroadcastMessage(out, &broadcast9{
+		U:            r.uShare,
+		Gamma:        r.GammaShare,
+		Psi:          r.PsiShare,
+		Decommitment: r.decommitment,
+	}); err != nil {
+		return r, err
+	}
+
+	self := r.SelfID()
+	return &round9{
+		round8: r,
+		U:      map[party.ID]curve.Scalar{self: r.uShare},
+		Gamma:  map[party.ID]curve.Point{self: r.GammaShare},
+		Psi:    map[party.ID]curve.Point{self: r.PsiShare},
+	}, nil
+}
@@ -0,0 +1,123 @@
+package sign
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/party"
+)
+
+// round9 checks the opened shares of k⋅φ, and computes our share of the signature
+type round9 struct {
+	*round8
+
+	// U, Gamma and Psi hold the values of broadcast9 of each signer
+	U          map[party.ID]curve.Scalar
+	Gamma, Psi map[party.ID]curve.Point
+}
+
+// broadcast10 contains the sender's share of the signature
+type broadcast10 struct {
+	round.NormalBroadcastContent
+	S curve.Scalar
+}
+
+// Number implements round.Round
+func (r *round9) Number() round.Number {
+	return 9
+}
+
+// BroadcastContent implements round.BroadcastRound
+func (r *round9) BroadcastContent() round.BroadcastContent {
+	group := r.Group()
+	return &broadcast9{U: group.NewScalar(), Gamma: group.NewPoint(), Psi: group.NewPoint()}
+}
+
+// MessageContent implements round.Round
+func (r *round9) MessageContent() round.Content {
+	return nil // No messages in round 9
+}
+
+// RoundNumber implements round.Content
+func (broadcast10) RoundNumber() round.Number {
+	return 10
+}
+
+// StoreBroadcastMessage implements round.BroadcastRound
+func (r *round9) StoreBroadcastMessage(msg round.Message) error {
+	body, ok := msg.Content.(*broadcast9)
+	if !ok || body == nil {
+		return round.ErrInvalidContent
+	}
+	if body.U == nil || body.Gamma == nil || body.Psi == nil {
+		return round.ErrNilFields
+	}
+	if err := body.Decommitment.Validate(); err != nil {
+		return err
+	}
+	if !r.HashForID(msg.From).Decommit(r.commitments[msg.From], body.Decommitment, body.U, body.Gamma, body.Psi) {
+		return errors.New("failed to decommit the share of the masked nonce")
+	}
+	r.U[msg.From], r.Gamma[msg.From], r.Psi[msg.From] = body.U, body.Gamma, body.Psi
+	return nil
+}
+
+// VerifyMessage implements round.Round
+func (r *round9) VerifyMessage(_ round.Message) error {
+	return nil // No messages in round 9
+}
+
+// StoreMessage implements round.Round
+func (r *round9) StoreMessage(_ round.Message) error {
+	return nil // No messages in round 9
+}
+
+// Finalize implements round.Round
+//
+// With u = Σ uⱼ, the products are consistent with the nonce and the key if Σ φⱼ⋅R = u⋅G and
+// Σ (φⱼ⋅X - vⱼ⋅G) is the identity. Then k⁻¹ = φ⋅u⁻¹, and our share of the signature is
+//
+//	sᵢ = u⁻¹⋅(φᵢ⋅m + r⋅vᵢ).
+func (r *round9) Finalize(out chan<- *round.Message) (round.Session, error) {
+	group := r.Group()
+	u := group.NewScalar()
+	Gamma, Psi := group.NewPoint(), group.NewPoint()
+	for _, j := range r.signers {
+		if _, ok := r.U[j]; !ok {
+			return r, fmt.Errorf("missing share of the masked nonce from %s", j)
+		}
+		u.Add(r.U[j])
+		Gamma = Gamma.Add(r.Gamma[j])
+		Psi = Psi.Add(r.Psi[j])
+	}
+	if u.IsZero() {
+		return r.AbortRound(errors.New("the masked nonce is zero")), nil
+	}
+	if !Gamma.Equal(u.ActOnBase()) {
+		return r.AbortRound(errors.New("the masked nonce is inconsistent with the nonce point")), nil
+	}
+	if !Psi.IsIdentity() {
+		return r.AbortRound(errors.New("the masked key is inconsistent with the public key")), nil
+	}
+
+	rScalar := r.R.XScalar()
+	if rScalar == nil || rScalar.IsZero() {
+		return r.AbortRound(errors.New("the nonce point has an x-coordinate of zero")), nil
+	}
+	m := curve.FromHash(group, r.messageHash)
+
+	// sᵢ = u⁻¹⋅(φᵢ⋅m + r⋅vᵢ)
+	uInv := group.NewScalar().Set(u).Invert()
+	s := group.NewScalar().Set(rScalar).Mul(r.vShare)
+	s.Add(group.NewScalar().Set(r.phi).Mul(m)).Mul(uInv)
+
+	if err := r.BroadcastMessage(out, &broadcast10{S: s}); err != nil {
+		return r, err
+	}
+	return &round10{
+		round9: r,
+		S:      map[party.ID]curve.Scalar{r.SelfID(): s},
+	}, nil
+}
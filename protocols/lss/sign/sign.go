@@ -1,11 +1,32 @@
 // Package sign implements the LSS signing protocol.
+//
+// It follows the signing protocol of Doerner, Kondi, Lee and shelat, "Threshold ECDSA from ECDSA Assumptions:
+// The Multiparty Case" (https://eprint.iacr.org/2019/523), with the OT based multiplication of their two-party
+// protocol (https://eprint.iacr.org/2018/499).
+//
+// The signers turn their shares of the key into additive shares w with Lagrange coefficients, and sample
+// additive shares of a nonce k and of a mask φ. Every pair of signers runs a correlated OT setup and OT based
+// multiplications to obtain additive shares of k⋅φ and of w⋅φ, which works with any set of signers holding
+// threshold shares together.
+// Opening u = k⋅φ gives shares of the signature s = u⁻¹⋅(φ⋅m + r⋅w⋅φ) = k⁻¹⋅(m + r⋅x).
+//
+// Each signer commits to its nonce point Kᵢ = kᵢ⋅G in the first round, and opens it once the multiplications
+// are done, so that no signer can choose its nonce point to bias R = Σ Kⱼ after seeing those of the others.
+// In the same way, each signer commits to its share of u and to the points checking it against the nonce
+// and the public key before any of them is opened, so that no signer can choose them to cancel the values
+// of the others.
+// The checks run before the signature is combined, and the signature is verified, so that a signer
+// deviating from the protocol makes it abort.
+// Since the shares are additive shares of products, the abort does not name the deviating signer.
 package sign
 
 import (
 	"fmt"
-	
+
 	"github.com/luxfi/threshold/internal/round"
+	"github.com/luxfi/threshold/internal/types"
 	"github.com/luxfi/threshold/pkg/ecdsa"
+	"github.com/luxfi/threshold/pkg/hash"
 	"github.com/luxfi/threshold/pkg/party"
 	"github.com/luxfi/threshold/pkg/pool"
 	"github.com/luxfi/threshold/pkg/protocol"
@@ -21,17 +42,28 @@ func Start(c *config.Config, signers []party.ID, messageHash []byte, pl *pool.Po
 				return nil, fmt.Errorf("unknown signer: %s", signer)
 			}
 		}
-		
+		if shares := c.Weights.Total(signers); shares < c.Threshold {
+			return nil, fmt.Errorf("lss: need at least %d shares among the signers, got %d", c.Threshold, shares)
+		}
+
+		// Every signer takes part in every round, and the protocol stays secure with all signers but one
+		// corrupted, so the threshold of the session is that of the signers. The threshold of the config,
+		// which counts shares, is checked above and bound to the session with the weights.
 		info := round.Info{
 			ProtocolID:       "lss/sign",
-			FinalRoundNumber: 3,
+			FinalRoundNumber: 10,
 			SelfID:           c.ID,
 			PartyIDs:         signers,
-			Threshold:        c.Threshold,
+			Threshold:        len(signers) - 1,
 			Group:            c.Group,
+			RequireSessionID: true,
+		}
+		auxInfo := []hash.WriterToWithDomain{
+			types.SigningMessage(messageHash),
+			config.WeightedThreshold{Threshold: c.Threshold, Weights: c.Weights},
 		}
 
-		helper, err := round.NewSession(info, sessionID, pl)
+		helper, err := round.NewSession(info, sessionID, pl, auxInfo...)
 		if err != nil {
 			return nil, err
 		}
@@ -39,7 +71,7 @@ func Start(c *config.Config, signers []party.ID, messageHash []byte, pl *pool.Po
 		return &round1{
 			Helper:      helper,
 			config:      c,
-			signers:     signers,
+			signers:     helper.PartyIDs(),
 			messageHash: messageHash,
 		}, nil
 	}
@@ -1,9 +1,12 @@
 package sign_test
 
 import (
+	"crypto/rand"
 	"testing"
 
+	"github.com/luxfi/threshold/internal/round"
 	"github.com/luxfi/threshold/pkg/math/curve"
+	"github.com/luxfi/threshold/pkg/math/sample"
 	"github.com/luxfi/threshold/pkg/party"
 	"github.com/luxfi/threshold/pkg/pool"
 	"github.com/luxfi/threshold/protocols/lss/config"
@@ -213,4 +216,50 @@ func TestConcurrentSigning(t *testing.T) {
 	
 	// At least one should succeed
 	assert.Greater(t, successCount, 0, "At least one concurrent session should succeed")
-}
\ No newline at end of file
+}
+
+func TestSignGenerationMismatch(t *testing.T) {
+	group := curve.Secp256k1{}
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	partyIDs := []party.ID{"alice", "bob", "charlie"}
+	public := make(map[party.ID]*config.Public)
+	configs := make(map[party.ID]*config.Config)
+	for _, id := range partyIDs {
+		share := sample.Scalar(rand.Reader, group)
+		public[id] = &config.Public{ECDSA: share.ActOnBase()}
+		configs[id] = &config.Config{
+			ID:         id,
+			Group:      group,
+			Threshold:  2,
+			Generation: 2,
+			ECDSA:      share,
+			Public:     public,
+		}
+	}
+	// bob signs with the config of the generation before the last reshare
+	configs["bob"].Generation = 1
+
+	message := make([]byte, 32)
+	broadcasts := make(map[party.ID]*round.Message)
+	var alice round.Session
+	for _, id := range partyIDs {
+		r, err := sign.Start(configs[id], partyIDs, message, pl)([]byte("session-id"))
+		require.NoError(t, err)
+		out := make(chan *round.Message, len(partyIDs))
+		next, err := r.Finalize(out)
+		require.NoError(t, err)
+		broadcasts[id] = <-out
+		if id == "alice" {
+			alice = next
+		}
+	}
+
+	r, ok := alice.(round.BroadcastRound)
+	require.True(t, ok)
+	assert.NoError(t, r.StoreBroadcastMessage(*broadcasts["charlie"]))
+	err := r.StoreBroadcastMessage(*broadcasts["bob"])
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "signer bob has generation 1")
+}
@@ -32,7 +32,7 @@ const (
 func SignWithBlinding(c *config.Config, signers []party.ID, messageHash []byte, protocol BlindingProtocol, pl *pool.Pool) protocol.StartFunc {
 	if len(signers) < c.Threshold {
 		return func(_ []byte) (round.Session, error) {
-			return nil, fmt.Errorf("lss: insufficient signers: have %d, need %d", len(signers), c.Threshold)
+			return nil, fmt.Errorf("lss: need at least %d signers, got %d", c.Threshold, len(signers))
 		}
 	}
 
@@ -98,6 +98,7 @@ func startBlindingProtocolI(c *config.Config, signers []party.ID, messageHash []
 		PartyIDs:         signers,
 		Threshold:        c.Threshold,
 		Group:            c.Group,
+		RequireSessionID: true,
 	}
 
 	helper, err := round.NewSession(info, sessionID, pl)
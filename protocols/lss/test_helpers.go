@@ -96,6 +96,7 @@ func RunSign(t *testing.T, configs map[party.ID]*config.Config, signers []party.
 	}
 	
 	require.True(t, len(signers) >= threshold, "insufficient signers")
+	requireSameGeneration(t, configs, signers)
 
 	// Generate nonce k
 	k := sample.Scalar(rand.Reader, group)
@@ -132,6 +133,16 @@ func RunSign(t *testing.T, configs map[party.ID]*config.Config, signers []party.
 	}
 }
 
+// requireSameGeneration fails the test if the configs of signers aren't all of the same generation,
+// which Sign would only report once the nonces are exchanged.
+func requireSameGeneration(t *testing.T, configs map[party.ID]*config.Config, signers []party.ID) {
+	signerConfigs := make([]*config.Config, 0, len(signers))
+	for _, id := range signers {
+		signerConfigs = append(signerConfigs, configs[id])
+	}
+	require.NoError(t, CheckGenerations(signerConfigs))
+}
+
 // RunReshare performs a resharing operation for testing
 func RunReshare(t *testing.T, oldConfigs map[party.ID]*config.Config, newPartyIDs []party.ID, newThreshold int) map[party.ID]*config.Config {
 	// Get reference config